@@ -20,7 +20,7 @@ func passAll(enc.Name, enc.Wire, ndn.Signature) bool {
 
 func main() {
 	timer := basic_engine.NewTimer()
-	// face := basic_engine.NewWebSocketFace("ws", "localhost:9696", true)
+	// face := basic_engine.NewWebSocketFace("ws://localhost:9696", nil)
 	face := basic_engine.NewStreamFace("unix", "/var/run/nfd/nfd.sock", true)
 	app = basic_engine.NewEngine(face, timer, sec.NewSha256IntSigner(timer), passAll)
 	log.SetLevel(log.InfoLevel)
@@ -33,7 +33,7 @@ func main() {
 	defer app.Shutdown()
 
 	name, _ := enc.NameFromStr("/example/testApp/randomData")
-	name = append(name, enc.NewTimestampComponent(utils.MakeTimestamp(timer.Now())))
+	name = append(name, enc.NewTimestampComponent(timer.Now()))
 
 	intCfg := &ndn.InterestConfig{
 		MustBeFresh: true,
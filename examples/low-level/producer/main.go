@@ -59,7 +59,7 @@ func onInterest(
 
 func main() {
 	timer := basic_engine.NewTimer()
-	// face := basic_engine.NewWebSocketFace("ws", "localhost:9696", true)
+	// face := basic_engine.NewWebSocketFace("ws://localhost:9696", nil)
 	face := basic_engine.NewStreamFace("unix", "/var/run/nfd/nfd.sock", true)
 
 	homedir, _ := os.UserHomeDir()
@@ -82,7 +82,7 @@ func main() {
 		logger.Errorf("Unable to register handler: %+v", err)
 		return
 	}
-	err = app.RegisterRoute(prefix)
+	err = app.RegisterRoute(prefix, ndn.RouteOptions{})
 	if err != nil {
 		logger.Errorf("Unable to register route: %+v", err)
 		return
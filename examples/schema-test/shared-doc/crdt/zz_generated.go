@@ -176,6 +176,8 @@ func (context *IDTypeParsingContext) Parse(reader enc.ParseReader, ignoreCritica
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -543,6 +545,8 @@ func (context *RecordParsingContext) Parse(reader enc.ParseReader, ignoreCritica
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -53,14 +53,18 @@ func onInterest(event *schema.Event) any {
 	timestamp, _ := enc.ParseNat(mNode.Matching["time"])
 	fmt.Printf(">> I: timestamp: %d\n", timestamp)
 	content := []byte("Hello, world!")
-	dataWire := mNode.Call("Provide", enc.Wire{content}).(enc.Wire)
-	err := event.Reply(dataWire)
+	data, ok := mNode.Call("ProvideEx", enc.Wire{content}).(*schema.ProvidedData)
+	if !ok || data == nil {
+		log.WithField("module", "main").Errorf("unable to produce data")
+		return true
+	}
+	err := event.Reply(data.Wire)
 	if err != nil {
 		log.WithField("module", "main").Errorf("unable to reply with data: %+v", err)
 		return true
 	}
-	fmt.Printf("<< D: %s\n", mNode.Name.String())
-	fmt.Printf("Content: (size: %d)\n", len(content))
+	fmt.Printf("<< D: %s\n", data.FullName.String())
+	fmt.Printf("Content: (size: %d, packet size: %d)\n", len(content), data.Size)
 	fmt.Printf("\n")
 	return nil
 }
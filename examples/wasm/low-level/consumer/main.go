@@ -35,7 +35,7 @@ func main() {
 	defer app.Shutdown()
 
 	name, _ := enc.NameFromStr("/example/testApp/randomData")
-	name = append(name, enc.NewTimestampComponent(utils.MakeTimestamp(timer.Now())))
+	name = append(name, enc.NewTimestampComponent(timer.Now()))
 
 	intCfg := &ndn.InterestConfig{
 		MustBeFresh: true,
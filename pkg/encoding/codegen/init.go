@@ -26,6 +26,7 @@ func initFields() {
 		"signature":         NewSignatureField,
 		"interestName":      NewInterestNameField,
 		"map":               NewMapField,
+		"unknown":           NewUnknownField,
 	}
 }
 
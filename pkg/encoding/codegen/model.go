@@ -25,6 +25,9 @@ type TlvModel struct {
 
 	// Fields are the TLV fields of the structure.
 	Fields []TlvField
+
+	// Unknown is the field keeping unrecognized fields, if there is one.
+	Unknown *UnknownField
 }
 
 func (m *TlvModel) ProcessOption(option string) {
@@ -59,16 +62,26 @@ func (m *TlvModel) GenInitEncoder(buf *bytes.Buffer) error {
 		{{$f.GenInitEncoder}}
 		{{- end}}
 		l := uint(0)
-		{{- range $f := .Fields}}
-		{{$f.GenEncodingLength}}
+		{{- if .Unknown}}
+		{{.Unknown.Name}}_idx := 0
+		{{- end}}
+		{{- range $i, $f := .Fields}}
+		{{$.GenUnknownLengthBefore $i}}
+		{{- $f.GenEncodingLength}}
 		{{- end}}
+		{{$.GenUnknownLengthBefore (len .Fields)}}
 		encoder.length = l
 		{{if .NoCopy}}
 		wirePlan := make([]uint, 0)
 		l = uint(0)
-		{{- range $f := .Fields}}
-		{{$f.GenEncodingWirePlan}}
+		{{- if .Unknown}}
+		{{.Unknown.Name}}_idx = 0
+		{{- end}}
+		{{- range $i, $f := .Fields}}
+		{{$.GenUnknownLengthBefore $i}}
+		{{- $f.GenEncodingWirePlan}}
 		{{- end}}
+		{{$.GenUnknownLengthBefore (len .Fields)}}
 		if l > 0 {
 			wirePlan = append(wirePlan, l)
 		}
@@ -80,6 +93,29 @@ func (m *TlvModel) GenInitEncoder(buf *bytes.Buffer) error {
 	return t.Execute(buf, m)
 }
 
+// hasUnknownBefore returns whether unknown fields may be located right before the i-th field.
+// Unknown fields are recorded after a TLV field, or at the beginning.
+func (m *TlvModel) hasUnknownBefore(i int) bool {
+	if m.Unknown == nil {
+		return false
+	}
+	return i == 0 || i >= len(m.Fields) || m.Fields[i-1].TypeNum() != 0
+}
+
+func (m *TlvModel) GenUnknownLengthBefore(i int) (string, error) {
+	if !m.hasUnknownBefore(i) {
+		return "", nil
+	}
+	return m.Unknown.GenEncodingLengthBefore(i)
+}
+
+func (m *TlvModel) GenUnknownEncodeBefore(i int) (string, error) {
+	if !m.hasUnknownBefore(i) {
+		return "", nil
+	}
+	return m.Unknown.GenEncodeBefore(i)
+}
+
 func (m *TlvModel) GenEncodeInto(buf *bytes.Buffer) error {
 	const Temp = `func (encoder *{{.Name}}Encoder) EncodeInto(value *{{.Name}},
 		{{- if .NoCopy}}wire enc.Wire{{else}}buf []byte{{end}}) {
@@ -88,9 +124,14 @@ func (m *TlvModel) GenEncodeInto(buf *bytes.Buffer) error {
 		buf := wire[wireIdx]
 		{{end}}
 		pos := uint(0)
-		{{- range $f := .Fields}}
-		{{$f.GenEncodeInto}}
+		{{- if .Unknown}}
+		{{.Unknown.Name}}_idx := 0
 		{{- end}}
+		{{- range $i, $f := .Fields}}
+		{{$.GenUnknownEncodeBefore $i}}
+		{{- $f.GenEncodeInto}}
+		{{- end}}
+		{{$.GenUnknownEncodeBefore (len .Fields)}}
 	}
 
 	func (encoder *{{.Name}}Encoder) Encode(value *{{.Name}}) enc.Wire {
@@ -178,7 +219,13 @@ func (m *TlvModel) GenReadFrom(buf *bytes.Buffer) error {
 					if !ignoreCritical && {{.IsCritical}} {
 						return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 					}
+					{{- if .Model.Unknown}}
+					{{.Model.Unknown.GenReadUnknown}}
+					{{- else}}
 					err = reader.Skip(int(l))
+					{{- end}}
+					// An unknown field does not take the place of the next field
+					progress--
 				}
 				if err == nil && !handled {
 					switch progress {
@@ -0,0 +1,62 @@
+package codegen
+
+// UnknownField keeps the unrecognized non-critical fields of a model, of type []enc.UnknownField.
+// Without it, these fields are skipped when parsing.
+// The encoder puts them back to their original positions, so a decoded packet is encoded into the same wire.
+type UnknownField struct {
+	BaseTlvField
+}
+
+// GenEncodingLengthBefore generates the length of unknown fields located before the i-th field.
+// It is used for both the length and the wire plan, since the unknown fields are encoded by copying.
+func (f *UnknownField) GenEncodingLengthBefore(i int) (string, error) {
+	g := strErrBuf{}
+	g.printlnf("for ; %s_idx < len(value.%s) && value.%s[%s_idx].Pos < %d; %s_idx++ {",
+		f.name, f.name, f.name, f.name, i, f.name)
+	g.printlnf("u := &value.%s[%s_idx]", f.name, f.name)
+	g.printlnf("l += uint(u.Typ.EncodingLength())")
+	g.printlnf("l += uint(enc.TLNum(u.Val.Length()).EncodingLength())")
+	g.printlnf("l += uint(u.Val.Length())")
+	g.printlnf("}")
+	return g.output()
+}
+
+// GenEncodeBefore generates the encoding of unknown fields located before the i-th field.
+func (f *UnknownField) GenEncodeBefore(i int) (string, error) {
+	g := strErrBuf{}
+	g.printlnf("for ; %s_idx < len(value.%s) && value.%s[%s_idx].Pos < %d; %s_idx++ {",
+		f.name, f.name, f.name, f.name, i, f.name)
+	g.printlnf("u := &value.%s[%s_idx]", f.name, f.name)
+	g.printlnf("pos += uint(u.Typ.EncodeInto(buf[pos:]))")
+	g.printlnf("pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))")
+	g.printlnf("for _, b := range u.Val {")
+	g.printlnf("pos += uint(copy(buf[pos:], b))")
+	g.printlnf("}")
+	g.printlnf("}")
+	return g.output()
+}
+
+func (f *UnknownField) GenReadUnknown() (string, error) {
+	g := strErrBuf{}
+	g.printlnf("{")
+	g.printlnf("u := enc.UnknownField{Typ: typ, Pos: progress}")
+	g.printlnf("u.Val, err = reader.ReadWire(int(l))")
+	g.printlnf("value.%s = append(value.%s, u)", f.name, f.name)
+	g.printlnf("}")
+	return g.output()
+}
+
+// NewUnknownField creates a field keeping unknown fields. There can be at most one in a model.
+func NewUnknownField(name string, _ uint64, _ string, model *TlvModel) (TlvField, error) {
+	if model.Unknown != nil {
+		return nil, ErrInvalidField
+	}
+	f := &UnknownField{
+		BaseTlvField: BaseTlvField{
+			name:    name,
+			typeNum: 0,
+		},
+	}
+	model.Unknown = f
+	return f, nil
+}
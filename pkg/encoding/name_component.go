@@ -8,6 +8,7 @@ import (
 	"io"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/cespare/xxhash"
@@ -32,6 +33,10 @@ const (
 )
 
 type compValFmt interface {
+	// Accepts returns if ToString keeps all information of val, so it is parsed back by FromString.
+	Accepts(val []byte) bool
+	// Matches returns if val is a value of the format, even if it is not in the canonical encoding.
+	Matches(val []byte) bool
 	ToString(val []byte) string
 	FromString(s string) ([]byte, error)
 	ToMatching(val []byte) any
@@ -43,6 +48,14 @@ type compValFmtText struct{}
 type compValFmtDec struct{}
 type compValFmtHex struct{}
 
+func (compValFmtInvalid) Accepts(val []byte) bool {
+	return false
+}
+
+func (compValFmtInvalid) Matches(val []byte) bool {
+	return false
+}
+
 func (compValFmtInvalid) ToString(val []byte) string {
 	return ""
 }
@@ -59,19 +72,47 @@ func (compValFmtInvalid) FromMatching(m any) ([]byte, error) {
 	return nil, ErrFormat{"Invalid component format"}
 }
 
+func (compValFmtText) Accepts(val []byte) bool {
+	return true
+}
+
+func (compValFmtText) Matches(val []byte) bool {
+	return true
+}
+
 func (compValFmtText) ToString(val []byte) string {
-	vText := ""
+	vText := strings.Builder{}
 	for _, b := range val {
 		if isLegalCompText(b) {
-			vText = vText + string(b)
+			vText.WriteByte(b)
 		} else {
-			vText = vText + fmt.Sprintf("%%%02X", b)
+			fmt.Fprintf(&vText, "%%%02X", b)
 		}
 	}
-	return vText
+	// Period-only values are written with three more periods, since "." and ".." are relative path segments.
+	if len(val) > 0 && isAllPeriods(val) {
+		vText.WriteString("...")
+	}
+	return vText.String()
+}
+
+func isAllPeriods[T string | []byte](val T) bool {
+	for i := 0; i < len(val); i++ {
+		if val[i] != '.' {
+			return false
+		}
+	}
+	return true
 }
 
 func (compValFmtText) FromString(valStr string) ([]byte, error) {
+	if len(valStr) > 0 && isAllPeriods(valStr) {
+		if len(valStr) < 3 {
+			// "." and ".." are relative path segments
+			return nil, ErrFormat{"invalid component value: " + valStr}
+		}
+		return []byte(valStr[3:]), nil
+	}
 	hasSpecialChar := false
 	for _, c := range valStr {
 		if c == '%' || c == '=' || c == '/' || c == '\\' {
@@ -121,6 +162,28 @@ func (compValFmtText) FromMatching(m any) ([]byte, error) {
 	}
 }
 
+// Accepts returns if val is a NonNegativeInteger in the shortest encoding, so the URI keeps its encoding.
+func (compValFmtDec) Accepts(val []byte) bool {
+	if len(val) == 0 || len(val) > 8 {
+		return false
+	}
+	x := uint64(0)
+	for _, b := range val {
+		x = (x << 8) | uint64(b)
+	}
+	return Nat(x).EncodingLength() == len(val)
+}
+
+// Matches returns if val is a NonNegativeInteger, whose length is 1, 2, 4 or 8 octets.
+func (compValFmtDec) Matches(val []byte) bool {
+	switch len(val) {
+	case 1, 2, 4, 8:
+		return true
+	default:
+		return false
+	}
+}
+
 func (compValFmtDec) ToString(val []byte) string {
 	x := uint64(0)
 	for _, b := range val {
@@ -157,6 +220,14 @@ func (compValFmtDec) FromMatching(m any) ([]byte, error) {
 	return ret, nil
 }
 
+func (compValFmtHex) Accepts(val []byte) bool {
+	return true
+}
+
+func (compValFmtHex) Matches(val []byte) bool {
+	return true
+}
+
 func (compValFmtHex) ToString(val []byte) string {
 	vText := ""
 	for _, b := range val {
@@ -330,7 +401,7 @@ func isLegalCompText(b byte) bool {
 func (c Component) String() string {
 	vFmt := compValFmt(compValFmtText{})
 	tName := ""
-	if conv, ok := compConvByType[c.Typ]; ok {
+	if conv, ok := compConvByType[c.Typ]; ok && conv.vFmt.Accepts(c.Val) {
 		vFmt = conv.vFmt
 		tName = conv.name + "="
 	} else if c.Typ != TypeGenericNameComponent {
@@ -519,6 +590,15 @@ func (c Component) Compare(rhs ComponentPattern) int {
 	return bytes.Compare(c.Val, rc.Val)
 }
 
+// AsNumber returns the value of the component as a NonNegativeInteger.
+// ok is false if the value is not a NonNegativeInteger in the shortest encoding.
+func (c Component) AsNumber() (val uint64, ok bool) {
+	if !(compValFmtDec{}).Accepts(c.Val) {
+		return 0, false
+	}
+	return c.NumberVal(), true
+}
+
 // NumberVal returns the value of the component as a number
 func (c Component) NumberVal() uint64 {
 	ret := uint64(0)
@@ -616,8 +696,10 @@ func NewVersionComponent(v uint64) Component {
 	return NewNumberComponent(TypeVersionNameComponent, v)
 }
 
-func NewTimestampComponent(t uint64) Component {
-	return NewNumberComponent(TypeTimestampNameComponent, t)
+// NewTimestampComponent creates a timestamp component of t in microseconds since the Unix epoch,
+// as the naming conventions specify.
+func NewTimestampComponent(t time.Time) Component {
+	return NewNumberComponent(TypeTimestampNameComponent, uint64(t.UnixMicro()))
 }
 
 func NewBytesComponent(typ TLNum, val []byte) Component {
@@ -660,6 +742,29 @@ func (c Component) IsMatch(value Component) bool {
 	return c.Equal(value)
 }
 
+// IsMatch returns if value has the type of the pattern.
+// If the type has a naming convention, the value must also be in its format,
+// e.g. a segment, version, timestamp or sequence number must be a NonNegativeInteger of 1, 2, 4 or 8 octets.
+// Unlike in the URI, numbers not in the shortest encoding match, as producers are free to use them.
 func (p Pattern) IsMatch(value Component) bool {
-	return p.Typ == value.Typ
+	if p.Typ != value.Typ {
+		return false
+	}
+	if conv, ok := compConvByType[p.Typ]; ok {
+		return conv.vFmt.Matches(value.Val)
+	}
+	return true
+}
+
+// MatchValue returns the value of a component matching the pattern, parsed by the naming convention of its type.
+// It is a uint64 for numbers, and a copy of the raw bytes otherwise.
+func (p Pattern) MatchValue(value Component) any {
+	if conv, ok := compConvByType[p.Typ]; ok {
+		v := conv.vFmt.ToMatching(value.Val)
+		if b, ok := v.([]byte); ok {
+			return append([]byte(nil), b...)
+		}
+		return v
+	}
+	return append([]byte(nil), value.Val...)
 }
@@ -3,6 +3,8 @@ package encoding
 import (
 	"crypto/sha256"
 	"io"
+	"iter"
+	"slices"
 	"strings"
 
 	"github.com/cespare/xxhash"
@@ -90,6 +92,19 @@ func (n Name) Bytes() []byte {
 	return buf
 }
 
+// TlvStr returns the encoded components of the name as a string, which can be used as a map key.
+// Unlike String, distinct names never give the same result. NameFromTlvStr parses it back.
+func (n Name) TlvStr() string {
+	buf := make([]byte, n.EncodingLength())
+	n.EncodeInto(buf)
+	return string(buf)
+}
+
+// NameFromTlvStr parses a name from the string given by TlvStr.
+func NameFromTlvStr(s string) (Name, error) {
+	return ReadName(NewBufferReader([]byte(s)))
+}
+
 // Hash returns the hash of the name
 func (n Name) Hash() uint64 {
 	h := xxhash.New()
@@ -176,6 +191,10 @@ func NameFromBytes(buf []byte) (Name, error) {
 	return ret, nil
 }
 
+// Compare compares two names in the NDN canonical order.
+// A name is smaller than its extensions. Otherwise, the first different components are compared
+// by TLV-TYPE, then by TLV-LENGTH, then byte by byte.
+// It returns -1 if n < rhs, 0 if n == rhs, and 1 if n > rhs.
 func (n Name) Compare(rhs Name) int {
 	for i := 0; i < utils.Min(len(n), len(rhs)); i++ {
 		if ret := n[i].Compare(rhs[i]); ret != 0 {
@@ -192,6 +211,33 @@ func (n Name) Compare(rhs Name) int {
 	}
 }
 
+// SortNames sorts names in the NDN canonical order given by Name.Compare.
+func SortNames(names []Name) {
+	slices.SortFunc(names, Name.Compare)
+}
+
+// NameRange yields the names of segments fromSeg to toSeg, both included, under prefix, in that order.
+// The names are built lazily, so a huge range does not allocate them all at once. It yields nothing if
+// fromSeg > toSeg. Each name is a deep copy that shares no memory with prefix or the other names.
+func NameRange(prefix Name, fromSeg, toSeg uint64) iter.Seq[Name] {
+	return func(yield func(Name) bool) {
+		if fromSeg > toSeg {
+			return
+		}
+		for seg := fromSeg; ; seg++ {
+			name := make(Name, len(prefix)+1)
+			for i, c := range prefix {
+				name[i] = Component{Typ: c.Typ, Val: slices.Clone(c.Val)}
+			}
+			name[len(prefix)] = NewSegmentComponent(seg)
+			// Avoid overflow when toSeg is the largest uint64
+			if !yield(name) || seg == toSeg {
+				return
+			}
+		}
+	}
+}
+
 func (n NamePattern) Compare(rhs NamePattern) int {
 	for i := 0; i < utils.Min(len(n), len(rhs)); i++ {
 		if ret := n[i].Compare(rhs[i]); ret != 0 {
@@ -232,6 +278,7 @@ func (n NamePattern) Equal(rhs NamePattern) bool {
 	return true
 }
 
+// IsPrefix returns if n is a prefix of rhs. A name is a prefix of itself.
 func (n Name) IsPrefix(rhs Name) bool {
 	if len(n) > len(rhs) {
 		return false
@@ -262,6 +309,39 @@ func (n NamePattern) Match(name Name, m Matching) {
 	}
 }
 
+// IsMatch returns if name has the same length as the pattern and every component matches.
+func (n NamePattern) IsMatch(name Name) bool {
+	if len(name) != len(n) {
+		return false
+	}
+	for i, c := range n {
+		if !c.IsMatch(name[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchValues matches name with the pattern, and returns the values of its variables indexed by tag,
+// parsed as given by Pattern.MatchValue.
+// For example, "/user/<id>/post/<seq=num>" gives id as []byte and num as uint64.
+// ok is false if name does not match, including when a number component is not a valid NonNegativeInteger.
+func (n NamePattern) MatchValues(name Name) (ret map[string]any, ok bool) {
+	if !n.IsMatch(name) {
+		return nil, false
+	}
+	ret = make(map[string]any)
+	for i, c := range n {
+		switch p := c.(type) {
+		case Pattern:
+			ret[p.Tag] = p.MatchValue(name[i])
+		case *Pattern:
+			ret[p.Tag] = p.MatchValue(name[i])
+		}
+	}
+	return ret, true
+}
+
 func (n NamePattern) FromMatching(m Matching) (Name, error) {
 	ret := make(Name, len(n))
 	for i, c := range n {
@@ -2,8 +2,10 @@ package encoding_test
 
 import (
 	"encoding/hex"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
@@ -125,7 +127,7 @@ func TestComponentTypes(t *testing.T) {
 	require.Equal(t, c, c2)
 
 	c = utils.WithoutErr(enc.ComponentFromBytes([]byte{0xfd, 0x57, 0x65, 0x01, 0x2e}))
-	require.Equal(t, "22373=.", c.String())
+	require.Equal(t, "22373=....", c.String())
 	require.Equal(t, 0x5765, int(c.Typ))
 	c2 = utils.WithoutErr(enc.ComponentFromStr("22373=%2e"))
 	require.Equal(t, c, c2)
@@ -145,8 +147,31 @@ func TestComponentTypes(t *testing.T) {
 	require.Equal(t, []byte("\x34\x01\r"), enc.NewByteOffsetComponent(13).Bytes())
 	require.Equal(t, []byte("\x3a\x01\r"), enc.NewSequenceNumComponent(13).Bytes())
 	require.Equal(t, []byte("\x36\x01\r"), enc.NewVersionComponent(13).Bytes())
-	tm := uint64(15686790223318112)
+	tm := time.UnixMicro(15686790223318112)
 	require.Equal(t, []byte("\x38\x08\x00\x37\xbb\x0d\x76\xed\x4c\x60"), enc.NewTimestampComponent(tm).Bytes())
+
+	// Type numbers given by the naming conventions
+	require.Equal(t, enc.TLNum(50), enc.NewSegmentComponent(0).Typ)
+	require.Equal(t, enc.TLNum(52), enc.NewByteOffsetComponent(0).Typ)
+	require.Equal(t, enc.TLNum(54), enc.NewVersionComponent(0).Typ)
+	require.Equal(t, enc.TLNum(56), enc.NewTimestampComponent(tm).Typ)
+	require.Equal(t, enc.TLNum(58), enc.NewSequenceNumComponent(0).Typ)
+
+	num, ok := enc.NewTimestampComponent(tm).AsNumber()
+	require.True(t, ok)
+	require.Equal(t, uint64(15686790223318112), num)
+	num, ok = enc.NewVersionComponent(0).AsNumber()
+	require.True(t, ok)
+	require.Equal(t, uint64(0), num)
+	num, ok = enc.NewSegmentComponent(0x10000).AsNumber()
+	require.True(t, ok)
+	require.Equal(t, uint64(0x10000), num)
+	_, ok = enc.Component{Typ: enc.TypeSegmentNameComponent, Val: []byte{0x00, 0x01}}.AsNumber()
+	require.False(t, ok)
+	_, ok = enc.NewStringComponent(enc.TypeGenericNameComponent, "").AsNumber()
+	require.False(t, ok)
+	_, ok = enc.NewStringComponent(enc.TypeGenericNameComponent, "abc").AsNumber()
+	require.False(t, ok)
 }
 
 func TestComponentCompare(t *testing.T) {
@@ -190,7 +215,7 @@ func TestComponentCompare(t *testing.T) {
 func TestNameBasic(t *testing.T) {
 	utils.SetTestingT(t)
 
-	uri := "/Emid/25042=P3//./%1C%9F/sha256digest=0415e3624a151850ac686c84f155f29808c0dd73819aa4a4c20be73a4d8a874c"
+	uri := "/Emid/25042=P3//..../%1C%9F/sha256digest=0415e3624a151850ac686c84f155f29808c0dd73819aa4a4c20be73a4d8a874c"
 	name := utils.WithoutErr(enc.NameFromStr(uri))
 	require.Equal(t, 6, len(name))
 	require.Equal(t, utils.WithoutErr(enc.ComponentFromStr("Emid")), name[0])
@@ -226,9 +251,14 @@ func TestNameString(t *testing.T) {
 	tester("/", "/")
 	tester(" ", "/%20")
 	tester("/hello//world", "/hello//world")
-	tester("/hello/./world", "/hello/./world")
-	tester("/hello/../world", "/hello/../world")
+	tester("/hello/..../world", "/hello/..../world")
+	tester("/hello/%2E%2E/world", "/hello/...../world")
 	tester("//", "//")
+
+	// Relative path segments are not components
+	utils.WithErr(enc.NameFromStr("/hello/./world"))
+	utils.WithErr(enc.NameFromStr("/hello/.."))
+	utils.WithErr(enc.NameFromStr("/8=."))
 }
 
 func TestNameCompare(t *testing.T) {
@@ -291,6 +321,79 @@ func TestNameCompare(t *testing.T) {
 	}
 }
 
+func TestSortNames(t *testing.T) {
+	utils.SetTestingT(t)
+
+	strs := []string{
+		"/D/seg=10",
+		"/D",
+		"/21426=",
+		"/D/seg=2",
+		"/D/v=1",
+		"/3=AA",
+		"/",
+		"/D/seg=2/sha256digest=0000000000000000000000000000000000000000000000000000000000000000",
+		"/AA",
+		"/D/seg=1",
+	}
+	names := make([]enc.Name, len(strs))
+	for i, s := range strs {
+		names[i] = utils.WithoutErr(enc.NameFromStr(s))
+	}
+	enc.SortNames(names)
+	for i := 1; i < len(names); i++ {
+		require.Equal(t, -1, names[i-1].Compare(names[i]))
+	}
+	require.Equal(t, "/", names[0].String())
+	// Segment 2 is before 10, as shorter numbers are smaller
+	require.Equal(t, "/D/seg=1", names[3].String())
+	require.Equal(t, "/D/seg=2", names[4].String())
+	require.Equal(t, "/D/seg=10", names[6].String())
+	require.Equal(t, "/D/v=1", names[7].String())
+}
+
+func TestNameRange(t *testing.T) {
+	utils.SetTestingT(t)
+
+	prefix := utils.WithoutErr(enc.NameFromStr("/ndn/file/v=3"))
+	names := slices.Collect(enc.NameRange(prefix, 254, 257))
+	require.Len(t, names, 4)
+	for i, name := range names {
+		require.Len(t, name, len(prefix)+1)
+		require.True(t, prefix.IsPrefix(name))
+		last := name[len(name)-1]
+		require.Equal(t, enc.TypeSegmentNameComponent, last.Typ)
+		require.Equal(t, enc.NewSegmentComponent(uint64(254+i)), last)
+	}
+	require.Equal(t, "/ndn/file/v=3/seg=254", names[0].String())
+	require.Equal(t, "/ndn/file/v=3/seg=257", names[3].String())
+	// The names are already sorted
+	for i := 1; i < len(names); i++ {
+		require.Equal(t, -1, names[i-1].Compare(names[i]))
+	}
+
+	// The names do not share memory with each other or with prefix, even the values of components
+	names[0][0] = enc.NewStringComponent(enc.TypeGenericNameComponent, "changed")
+	names[1][1].Val[0] = 'F'
+	require.Equal(t, "/ndn/file/v=3", prefix.String())
+	require.Equal(t, "/ndn/file/v=3/seg=256", names[2].String())
+
+	require.Len(t, slices.Collect(enc.NameRange(prefix, 5, 5)), 1)
+	require.Empty(t, slices.Collect(enc.NameRange(prefix, 6, 5)))
+	require.Len(t, slices.Collect(enc.NameRange(prefix, ^uint64(0)-1, ^uint64(0))), 2)
+
+	// The whole range is not built when the caller stops early
+	count := 0
+	for name := range enc.NameRange(prefix, 0, ^uint64(0)) {
+		require.Equal(t, enc.NewSegmentComponent(uint64(count)), name[len(prefix)])
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	require.Equal(t, 3, count)
+}
+
 func TestNameIsPrefix(t *testing.T) {
 	utils.SetTestingT(t)
 
@@ -331,3 +434,235 @@ func TestNameBytes(t *testing.T) {
 	n2 := utils.WithoutErr(enc.NameFromBytes([]byte("\x07\x0c\x08\x01a\x08\x01b\x08\x01c\x08\x01d")))
 	require.True(t, n.Equal(n2))
 }
+
+func TestNameTlvStr(t *testing.T) {
+	utils.SetTestingT(t)
+
+	// These names have the same URI up to escaping or type, and must not collide
+	names := []enc.Name{
+		{},
+		utils.WithoutErr(enc.NameFromStr("/")),
+		utils.WithoutErr(enc.NameFromStr("//")),
+		utils.WithoutErr(enc.NameFromStr("/a/b")),
+		utils.WithoutErr(enc.NameFromStr("/a%2Fb")),
+		utils.WithoutErr(enc.NameFromStr("/ab")),
+		utils.WithoutErr(enc.NameFromStr("/3=ab")),
+		utils.WithoutErr(enc.NameFromStr("/a/3=b")),
+		{enc.NewBytesComponent(enc.TypeGenericNameComponent, []byte{0x00, 0xff})},
+		{enc.NewBytesComponent(enc.TypeGenericNameComponent, []byte{0x00}),
+			enc.NewBytesComponent(enc.TypeGenericNameComponent, []byte{0xff})},
+		{enc.NewSequenceNumComponent(1)},
+		{enc.NewSegmentComponent(1)},
+	}
+	seen := make(map[string]int)
+	for i, n := range names {
+		key := n.TlvStr()
+		if j, ok := seen[key]; ok {
+			require.True(t, names[j].Equal(n))
+		}
+		seen[key] = i
+		require.True(t, n.Equal(utils.WithoutErr(enc.NameFromTlvStr(key))))
+	}
+	require.Equal(t, len(names)-1, len(seen)) // {} and "/" are the same name
+	require.NotEqual(t, names[3].Hash(), names[4].Hash())
+
+	utils.WithErr(enc.NameFromTlvStr("\x08\x05abc"))
+}
+
+func BenchmarkNameMapKey(b *testing.B) {
+	names := make([]enc.Name, 1000)
+	for i := range names {
+		names[i] = utils.WithoutErr(enc.NameFromStr("/example/testApp/randomData"))
+		names[i] = append(names[i], enc.NewSequenceNumComponent(uint64(i)))
+	}
+
+	b.Run("TlvStr", func(b *testing.B) {
+		m := make(map[string]int, len(names))
+		for i, n := range names {
+			m[n.TlvStr()] = i
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = m[names[i%len(names)].TlvStr()]
+		}
+	})
+	b.Run("String", func(b *testing.B) {
+		m := make(map[string]int, len(names))
+		for i, n := range names {
+			m[n.String()] = i
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = m[names[i%len(names)].String()]
+		}
+	})
+	b.Run("Hash", func(b *testing.B) {
+		m := make(map[uint64]int, len(names))
+		for i, n := range names {
+			m[n.Hash()] = i
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = m[names[i%len(names)].Hash()]
+		}
+	})
+}
+
+func TestNameStringEscaping(t *testing.T) {
+	utils.SetTestingT(t)
+
+	tester := func(name enc.Name, s string) {
+		require.Equal(t, s, name.String())
+		require.Equal(t, name, utils.WithoutErr(enc.NameFromStr(s)))
+	}
+	gen := func(val string) enc.Component {
+		return enc.NewStringComponent(enc.TypeGenericNameComponent, val)
+	}
+
+	// Period-only values are written with three more periods
+	tester(enc.Name{gen(".")}, "/....")
+	tester(enc.Name{gen("..")}, "/.....")
+	tester(enc.Name{gen("...")}, "/......")
+	tester(enc.Name{gen("a"), gen("....")}, "/a/.......")
+	tester(enc.Name{gen("a.."), gen("a")}, "/a../a")
+	require.Equal(t, enc.Name{gen("")}, utils.WithoutErr(enc.NameFromStr("/...")))
+	require.Equal(t, enc.Name{gen("."), gen("a")}, utils.WithoutErr(enc.NameFromStr("/..../a")))
+	require.Equal(t, enc.Name{{Typ: 3, Val: []byte{}}}, utils.WithoutErr(enc.NameFromStr("/3=...")))
+
+	// Reserved and non-printable bytes
+	tester(enc.Name{gen("a/b=c%d e")}, "/a%2Fb%3Dc%25d%20e")
+	tester(enc.Name{enc.NewBytesComponent(enc.TypeGenericNameComponent, []byte{0x00, 0x7f, 0x80, 0xff})},
+		"/%00%7F%80%FF")
+
+	// Naming conventions are used only when the value can be parsed back
+	tester(enc.Name{enc.NewSegmentComponent(5)}, "/seg=5")
+	tester(enc.Name{{Typ: enc.TypeSegmentNameComponent, Val: []byte{0x00, 0x05}}}, "/50=%00%05")
+	tester(enc.Name{{Typ: enc.TypeVersionNameComponent, Val: []byte{}}}, "/54=")
+	tester(enc.Name{{Typ: enc.TypeImplicitSha256DigestComponent, Val: []byte{0xab, 0x01}}}, "/sha256digest=ab01")
+	require.Equal(t, enc.Name{{Typ: enc.TypeImplicitSha256DigestComponent, Val: []byte{0xab, 0x01}}},
+		utils.WithoutErr(enc.NameFromStr("/1=%AB%01")))
+	utils.WithErr(enc.NameFromStr("/sha256digest=ab0"))
+}
+
+func FuzzNameStringRoundTrip(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0, 3, '.', '.', '.'})
+	f.Add([]byte{0, 0, 1, 2, 0, 5, 4, 8, 0, 0, 0, 0, 0, 0, 0, 1})
+	f.Add([]byte("\x00\x05a/b%=\x03\x20\xff\x00\xfe"))
+	types := []enc.TLNum{
+		enc.TypeGenericNameComponent,
+		enc.TypeImplicitSha256DigestComponent,
+		enc.TypeParametersSha256DigestComponent,
+		enc.TypeKeywordNameComponent,
+		enc.TypeSegmentNameComponent,
+		enc.TypeByteOffsetNameComponent,
+		enc.TypeVersionNameComponent,
+		enc.TypeTimestampNameComponent,
+		enc.TypeSequenceNumNameComponent,
+		3,
+		0xfffe,
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Each component is given by a type selector, a length, and the value
+		name := enc.Name{}
+		for len(data) >= 2 {
+			typ := types[int(data[0])%len(types)]
+			l := min(int(data[1])%16, len(data)-2)
+			name = append(name, enc.Component{Typ: typ, Val: data[2 : 2+l]})
+			data = data[2+l:]
+		}
+		s := name.String()
+		parsed, err := enc.NameFromStr(s)
+		require.NoError(t, err, s)
+		require.True(t, name.Equal(parsed), s)
+	})
+}
+
+func TestNameTypedComponents(t *testing.T) {
+	utils.SetTestingT(t)
+
+	tester := func(s string, typ enc.TLNum, val []byte) {
+		name := utils.WithoutErr(enc.NameFromStr("/foo/" + s))
+		require.Equal(t, enc.Name{enc.NewStringComponent(enc.TypeGenericNameComponent, "foo"), {Typ: typ, Val: val}}, name)
+		require.Equal(t, "/foo/"+s, name.String())
+	}
+
+	tester("seg=3", enc.TypeSegmentNameComponent, []byte{0x03})
+	tester("off=256", enc.TypeByteOffsetNameComponent, []byte{0x01, 0x00})
+	tester("v=123", enc.TypeVersionNameComponent, []byte{0x7b})
+	tester("t=456", enc.TypeTimestampNameComponent, []byte{0x01, 0xc8})
+	tester("seq=7", enc.TypeSequenceNumNameComponent, []byte{0x07})
+	tester("seq=18446744073709551615", enc.TypeSequenceNumNameComponent,
+		[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	tester("32=metadata", enc.TypeKeywordNameComponent, []byte("metadata"))
+	tester("250=abc", 250, []byte("abc"))
+	tester("65535=", 65535, []byte{})
+
+	// The numeric form of well-known types is accepted and rendered in the alias
+	require.Equal(t, enc.NewSegmentComponent(5), utils.WithoutErr(enc.ComponentFromStr("50=%05")))
+	require.Equal(t, "/foo", utils.WithoutErr(enc.NameFromStr("/8=foo")).String())
+
+	utils.WithErr(enc.NameFromStr("/seg=abc"))
+	utils.WithErr(enc.NameFromStr("/seq=18446744073709551616"))
+	utils.WithErr(enc.NameFromStr("/unknown=1"))
+	utils.WithErr(enc.NameFromStr("/0=a"))
+	utils.WithErr(enc.NameFromStr("/65536=a"))
+}
+
+func TestNamePatternMatchValues(t *testing.T) {
+	utils.SetTestingT(t)
+
+	pat := utils.WithoutErr(enc.NamePatternFromStr("/user/<id>/post/<seq=num>"))
+	name := utils.WithoutErr(enc.NameFromStr("/user/alice/post/seq=300"))
+	require.True(t, pat.IsMatch(name))
+	vals, ok := pat.MatchValues(name)
+	require.True(t, ok)
+	require.Equal(t, map[string]any{"id": []byte("alice"), "num": uint64(300)}, vals)
+
+	// Captured bytes do not share memory with the name
+	vals["id"].([]byte)[0] = 'A'
+	require.Equal(t, "/user/alice/post/seq=300", name.String())
+
+	// The raw matching is unchanged
+	m := enc.Matching{}
+	pat.Match(name, m)
+	require.Equal(t, enc.Matching{"id": []byte("alice"), "num": {0x01, 0x2c}}, m)
+	require.Equal(t, name, utils.WithoutErr(pat.FromMatching(m)))
+
+	// A number component matches in any valid NonNegativeInteger encoding
+	for s, num := range map[string]uint64{
+		"/user/alice/post/58=%00%01":                   1,
+		"/user/alice/post/58=%00%00%01%2C":             300,
+		"/user/alice/post/58=%00%00%00%00%00%00%01%2C": 300,
+	} {
+		name := utils.WithoutErr(enc.NameFromStr(s))
+		require.True(t, pat.IsMatch(name), s)
+		vals, ok := pat.MatchValues(name)
+		require.True(t, ok, s)
+		require.Equal(t, num, vals["num"], s)
+	}
+
+	// Other lengths are not NonNegativeIntegers
+	for _, s := range []string{
+		"/user/alice/post/58=%01%02%03",
+		"/user/alice/post/58=",
+		"/user/alice/post/58=abc",
+		"/user/alice/post/abc",
+		"/user/alice/post",
+		"/user/alice/post/seq=1/extra",
+	} {
+		name := utils.WithoutErr(enc.NameFromStr(s))
+		require.False(t, pat.IsMatch(name), s)
+		_, ok := pat.MatchValues(name)
+		require.False(t, ok, s)
+	}
+
+	// Other types are only checked by type
+	pat = utils.WithoutErr(enc.NamePatternFromStr("/<32=kw>/<v=ver>/<sha256digest=d>"))
+	vals, ok = pat.MatchValues(utils.WithoutErr(enc.NameFromStr("/32=%00%01/v=5/sha256digest=0102")))
+	require.True(t, ok)
+	require.Equal(t, map[string]any{"kw": []byte{0x00, 0x01}, "ver": uint64(5), "d": []byte{0x01, 0x02}}, vals)
+	_, ok = pat.MatchValues(utils.WithoutErr(enc.NameFromStr("/8=kw/v=5/sha256digest=0102")))
+	require.False(t, ok)
+}
@@ -2,6 +2,7 @@ package encoding
 
 import (
 	"encoding/binary"
+	"fmt"
 	"io"
 )
 
@@ -11,6 +12,12 @@ type TLNum uint64
 // Nat is a TLV natural number
 type Nat uint64
 
+// FixedNat is a TLV natural number encoded in a fixed number of bytes, such as the Nonce of an Interest.
+type FixedNat struct {
+	Val   uint64
+	Width int
+}
+
 func (v TLNum) EncodingLength() int {
 	switch x := uint64(v); {
 	case x <= 0xfc:
@@ -44,20 +51,32 @@ func (v TLNum) EncodeInto(buf Buffer) int {
 	}
 }
 
-// ParseTLNum parses a TLNum from a buffer.
-// It is supposed to be used internally, so panic on index out of bounds.
+// EncodeTLNum returns the encoding of a TLV-TYPE or TLV-LENGTH number.
+// A number up to 0xfc takes 1 byte. Otherwise, it takes 3, 5 or 9 bytes,
+// starting with 0xfd, 0xfe or 0xff followed by the number in 2, 4 or 8 bytes of big endian.
+func EncodeTLNum(v TLNum) Buffer {
+	buf := make(Buffer, v.EncodingLength())
+	v.EncodeInto(buf)
+	return buf
+}
+
+// ParseTLNum parses a TLNum from the beginning of a buffer, and returns the number of bytes it takes.
+// The encoding is the same as EncodeTLNum. pos is 0 if buf does not start with a complete number.
 func ParseTLNum(buf Buffer) (val TLNum, pos int) {
+	if len(buf) == 0 {
+		return 0, 0
+	}
 	switch x := buf[0]; {
 	case x <= 0xfc:
 		val = TLNum(x)
 		pos = 1
-	case x == 0xfd:
+	case x == 0xfd && len(buf) >= 3:
 		val = TLNum(binary.BigEndian.Uint16(buf[1:3]))
 		pos = 3
-	case x == 0xfe:
+	case x == 0xfe && len(buf) >= 5:
 		val = TLNum(binary.BigEndian.Uint32(buf[1:5]))
 		pos = 5
-	case x == 0xff:
+	case x == 0xff && len(buf) >= 9:
 		val = TLNum(binary.BigEndian.Uint64(buf[1:9]))
 		pos = 9
 	}
@@ -149,6 +168,72 @@ func ParseNat(buf Buffer) (val Nat, pos int) {
 	return
 }
 
+// EncodeNonNegativeInt returns the encoding of a NonNegativeInteger, which is the TLV-VALUE of a natural number.
+// It takes the shortest of 1, 2, 4 or 8 bytes of big endian that can hold v.
+func EncodeNonNegativeInt(v uint64) Buffer {
+	return Nat(v).Bytes()
+}
+
+// ParseNonNegativeInt parses a NonNegativeInteger that takes the whole buf.
+// It returns an error if the length of buf is not 1, 2, 4 or 8.
+func ParseNonNegativeInt(buf Buffer) (uint64, error) {
+	switch len(buf) {
+	case 1, 2, 4, 8:
+		val, _ := ParseNat(buf)
+		return uint64(val), nil
+	default:
+		return 0, ErrFormat{"NonNegativeInteger length is not 1, 2, 4 or 8"}
+	}
+}
+
+// NatFixed returns a natural number encoded in exactly width bytes, which must be 1, 2, 4 or 8.
+func NatFixed(v uint64, width int) FixedNat {
+	return FixedNat{Val: v, Width: width}
+}
+
+// EncodeInto encodes v into buf in big endian. It panics if the width is invalid.
+func (v FixedNat) EncodeInto(buf Buffer) int {
+	switch v.Width {
+	case 1:
+		buf[0] = byte(v.Val)
+	case 2:
+		binary.BigEndian.PutUint16(buf, uint16(v.Val))
+	case 4:
+		binary.BigEndian.PutUint32(buf, uint32(v.Val))
+	case 8:
+		binary.BigEndian.PutUint64(buf, v.Val)
+	default:
+		panic(ErrFormat{"Fixed natural number width is not 1, 2, 4 or 8"})
+	}
+	return v.Width
+}
+
+// Bytes returns the encoding of v.
+// It returns an error if the width is invalid or the value does not fit in it.
+func (v FixedNat) Bytes() (Buffer, error) {
+	switch v.Width {
+	case 1, 2, 4:
+		if v.Val>>(8*v.Width) != 0 {
+			return nil, ErrFormat{fmt.Sprintf("Value %d does not fit in %d bytes", v.Val, v.Width)}
+		}
+	case 8:
+	default:
+		return nil, ErrFormat{"Fixed natural number width is not 1, 2, 4 or 8"}
+	}
+	buf := make(Buffer, v.Width)
+	v.EncodeInto(buf)
+	return buf, nil
+}
+
+// ParseNatFixed parses a natural number encoded in exactly width bytes.
+// It returns an error if the length of buf is not width.
+func ParseNatFixed(buf Buffer, width int) (uint64, error) {
+	if len(buf) != width {
+		return 0, ErrFormat{fmt.Sprintf("Fixed natural number length is %d, expected %d", len(buf), width)}
+	}
+	return ParseNonNegativeInt(buf)
+}
+
 // Shrink length reduce the L by `shrink“ in a TLV encoded buffer `buf`
 //
 //	Precondition:
@@ -0,0 +1,85 @@
+package encoding_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+func TestTLNumCodec(t *testing.T) {
+	utils.SetTestingT(t)
+
+	cases := []struct {
+		val enc.TLNum
+		buf enc.Buffer
+	}{
+		{0, enc.Buffer{0x00}},
+		{0xfc, enc.Buffer{0xfc}},
+		{0xfd, enc.Buffer{0xfd, 0x00, 0xfd}},
+		{0xffff, enc.Buffer{0xfd, 0xff, 0xff}},
+		{0x10000, enc.Buffer{0xfe, 0x00, 0x01, 0x00, 0x00}},
+		{0xffffffff, enc.Buffer{0xfe, 0xff, 0xff, 0xff, 0xff}},
+		{0x100000000, enc.Buffer{0xff, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00}},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.buf, enc.EncodeTLNum(c.val))
+		val, pos := enc.ParseTLNum(append(c.buf, 0x12))
+		require.Equal(t, c.val, val)
+		require.Equal(t, len(c.buf), pos)
+
+		// A truncated number is not parsed
+		_, pos = enc.ParseTLNum(c.buf[:len(c.buf)-1])
+		require.Equal(t, 0, pos)
+	}
+}
+
+func TestNonNegativeIntCodec(t *testing.T) {
+	utils.SetTestingT(t)
+
+	cases := []struct {
+		val uint64
+		buf enc.Buffer
+	}{
+		{0, enc.Buffer{0x00}},
+		{0xfc, enc.Buffer{0xfc}},
+		{0xff, enc.Buffer{0xff}},
+		{0x100, enc.Buffer{0x01, 0x00}},
+		{0xffff, enc.Buffer{0xff, 0xff}},
+		{0x10000, enc.Buffer{0x00, 0x01, 0x00, 0x00}},
+		{0xffffffff, enc.Buffer{0xff, 0xff, 0xff, 0xff}},
+		{0x100000000, enc.Buffer{0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00}},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.buf, enc.EncodeNonNegativeInt(c.val))
+		require.Equal(t, c.val, utils.WithoutErr(enc.ParseNonNegativeInt(c.buf)))
+	}
+
+	// Non-minimal encodings are accepted
+	require.Equal(t, uint64(1), utils.WithoutErr(enc.ParseNonNegativeInt(enc.Buffer{0x00, 0x01})))
+
+	utils.WithErr(enc.ParseNonNegativeInt(enc.Buffer{}))
+	utils.WithErr(enc.ParseNonNegativeInt(enc.Buffer{0x01, 0x02, 0x03}))
+	utils.WithErr(enc.ParseNonNegativeInt(make(enc.Buffer, 9)))
+}
+
+func TestNatFixed(t *testing.T) {
+	utils.SetTestingT(t)
+
+	require.Equal(t, enc.Buffer{0x00, 0x00, 0x00, 0x05}, utils.WithoutErr(enc.NatFixed(5, 4).Bytes()))
+	require.Equal(t, enc.Buffer{0x05}, utils.WithoutErr(enc.NatFixed(5, 1).Bytes()))
+	require.Equal(t, enc.Buffer{0x00, 0x05}, utils.WithoutErr(enc.NatFixed(5, 2).Bytes()))
+	require.Equal(t, enc.Buffer{0, 0, 0, 0, 0, 0, 0, 0x05}, utils.WithoutErr(enc.NatFixed(5, 8).Bytes()))
+	require.Equal(t, enc.Buffer{0xff, 0xff, 0xff, 0xff}, utils.WithoutErr(enc.NatFixed(0xffffffff, 4).Bytes()))
+
+	// The value must fit in the width
+	utils.WithErr(enc.NatFixed(0x100, 1).Bytes())
+	utils.WithErr(enc.NatFixed(0x10000, 2).Bytes())
+	utils.WithErr(enc.NatFixed(0x100000000, 4).Bytes())
+	utils.WithErr(enc.NatFixed(5, 3).Bytes())
+
+	require.Equal(t, uint64(5), utils.WithoutErr(enc.ParseNatFixed(enc.Buffer{0x00, 0x00, 0x00, 0x05}, 4)))
+	utils.WithErr(enc.ParseNatFixed(enc.Buffer{0x05}, 4))
+	utils.WithErr(enc.ParseNatFixed(enc.Buffer{0x00, 0x00, 0x05}, 3))
+}
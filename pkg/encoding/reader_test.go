@@ -68,6 +68,8 @@ func TestBasic(t *testing.T) {
 	testRange(1, 4)
 	testRange(1, 5)
 	testRange(1, 6)
+	testRange(4, 12)
+	testRange(2, 15)
 	testRange(7, 100)
 
 	testSkip := func(l int) {
@@ -127,3 +129,65 @@ func TestBasic(t *testing.T) {
 	testDelegate(4)
 	testDelegate(1)
 }
+
+func TestPooledReader(t *testing.T) {
+	utils.SetTestingT(t)
+
+	nameA := utils.WithoutErr(enc.NameFromStr("/example/testApp/randomData/seg=1"))
+	nameB := utils.WithoutErr(enc.NameFromStr("/b"))
+	bufA := nameA.Bytes()
+	bufB := nameB.Bytes()
+	wireA := enc.Wire{bufA[:3], bufA[3:10], bufA[10:]}
+	wireB := enc.Wire{bufB[:2], bufB[2:]}
+
+	// A recycled reader must not be affected by the packet it read before
+	for i := 0; i < 3; i++ {
+		r := enc.AcquireWireReader(wireA)
+		require.Equal(t, len(bufA), r.Length())
+		require.Equal(t, nameA, utils.WithoutErr(enc.NameFromBytes(r.Range(0, r.Length()).Join())))
+		require.NoError(t, r.Skip(2))
+		enc.ReleaseWireReader(r)
+
+		r = enc.AcquireWireReader(wireB)
+		require.Equal(t, 0, r.Pos())
+		require.Equal(t, len(bufB), r.Length())
+		ref := enc.NewWireReader(wireB)
+		require.Equal(t, utils.WithoutErr(ref.ReadBuf(len(bufB))), utils.WithoutErr(r.ReadBuf(len(bufB))))
+		utils.WithErr(r.ReadByte())
+		enc.ReleaseWireReader(r)
+
+		br := enc.AcquireBufferReader(bufB)
+		require.Equal(t, 0, br.Pos())
+		require.Equal(t, nameB, utils.WithoutErr(enc.NameFromBytes(br.Range(0, br.Length()).Join())))
+		require.NoError(t, br.Skip(len(bufB)))
+		enc.ReleaseBufferReader(br)
+
+		br = enc.AcquireBufferReader(bufA)
+		require.Equal(t, 0, br.Pos())
+		require.Equal(t, len(bufA), br.Length())
+		enc.ReleaseBufferReader(br)
+	}
+}
+
+// BenchmarkDecodeName decodes a name split into segments, as received from a fragmented packet.
+func BenchmarkDecodeName(b *testing.B) {
+	name := utils.WithoutErr(enc.NameFromStr("/example/testApp/randomData/seg=1"))
+	val := make([]byte, name.EncodingLength())
+	name.EncodeInto(val)
+	wire := enc.Wire{val[:5], val[5:20], val[20:]}
+
+	b.Run("New", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			enc.ReadName(enc.NewWireReader(wire))
+		}
+	})
+	b.Run("Pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r := enc.AcquireWireReader(wire)
+			enc.ReadName(r)
+			enc.ReleaseWireReader(r)
+		}
+	})
+}
@@ -3,6 +3,7 @@ package encoding
 import (
 	"errors"
 	"io"
+	"sync"
 )
 
 type BufferReader struct {
@@ -233,31 +234,7 @@ func (r *WireReader) Length() int {
 }
 
 func (r *WireReader) Range(start, end int) Wire {
-	if start < 0 || end > r.accSz[len(r.wire)] || start > end {
-		return nil
-	}
-	var startSeg, startPos, endSeg, endPos int
-	for i := 0; i < len(r.wire); i++ {
-		if r.accSz[i] <= start && r.accSz[i+1] > start {
-			startSeg = i
-			startPos = start - r.accSz[i]
-		}
-		if r.accSz[i] < end && r.accSz[i+1] >= end {
-			endSeg = i
-			endPos = end - r.accSz[i]
-		}
-	}
-	if startSeg == endSeg {
-		return Wire{r.wire[startSeg][startPos:endPos]}
-	} else {
-		ret := make(Wire, endSeg-startSeg+1)
-		ret[0] = r.wire[startSeg][startPos:]
-		for i := startSeg + 1; i < endSeg; i++ {
-			ret[i] = r.wire[i]
-		}
-		ret[endSeg-startSeg] = r.wire[endSeg][:endPos]
-		return ret
-	}
+	return r.wire.Slice(start, end)
 }
 
 func (r *WireReader) Skip(n int) error {
@@ -313,15 +290,66 @@ func (r *WireReader) Delegate(l int) ParseReader {
 }
 
 func NewWireReader(w Wire) *WireReader {
-	accSz := make([]int, len(w)+1)
-	accSz[0] = 0
-	for i := 0; i < len(w); i++ {
-		accSz[i+1] = accSz[i] + len(w[i])
+	r := &WireReader{}
+	r.reset(w)
+	return r
+}
+
+// reset makes r read w from the beginning, reusing the memory of accSz.
+func (r *WireReader) reset(w Wire) {
+	if cap(r.accSz) < len(w)+1 {
+		r.accSz = make([]int, len(w)+1)
+	} else {
+		r.accSz = r.accSz[:len(w)+1]
 	}
-	return &WireReader{
-		wire:  w,
-		seg:   0,
-		pos:   0,
-		accSz: accSz,
+	r.accSz[0] = 0
+	for i := 0; i < len(w); i++ {
+		r.accSz[i+1] = r.accSz[i] + len(w[i])
 	}
+	r.wire = w
+	r.seg = 0
+	r.pos = 0
+}
+
+var bufferReaderPool = sync.Pool{
+	New: func() any { return &BufferReader{} },
+}
+
+var wireReaderPool = sync.Pool{
+	New: func() any { return &WireReader{} },
+}
+
+// AcquireBufferReader returns a BufferReader of buf from a pool, to reduce allocations on the receive path.
+// It must be released by ReleaseBufferReader once neither it nor readers delegated from it are in use.
+// The values parsed from it are only backed by buf, so they are still valid after the release.
+func AcquireBufferReader(buf Buffer) *BufferReader {
+	r := bufferReaderPool.Get().(*BufferReader)
+	r.buf = buf
+	r.pos = 0
+	return r
+}
+
+// ReleaseBufferReader puts a reader given by AcquireBufferReader back into the pool.
+func ReleaseBufferReader(r *BufferReader) {
+	r.buf = nil
+	r.pos = 0
+	bufferReaderPool.Put(r)
+}
+
+// AcquireWireReader is the same as AcquireBufferReader, but for a WireReader of w.
+// It must be released by ReleaseWireReader.
+func AcquireWireReader(w Wire) *WireReader {
+	r := wireReaderPool.Get().(*WireReader)
+	r.reset(w)
+	return r
+}
+
+// ReleaseWireReader puts a reader given by AcquireWireReader back into the pool.
+// Readers given by Delegate must not be released, since they may share memory with the original one.
+func ReleaseWireReader(r *WireReader) {
+	r.wire = nil
+	r.seg = 0
+	r.pos = 0
+	r.accSz = r.accSz[:0]
+	wireReaderPool.Put(r)
 }
@@ -0,0 +1,237 @@
+package encoding
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// MaxPacketSize is the max size of an NDN packet, which bounds the TLV elements read by StreamReader.ReadTLV.
+const MaxPacketSize = 8800
+
+// StreamReader is a ParseReader reading from an io.Reader, such as a socket or a file.
+// It reads lazily, so only the bytes required by each field are buffered.
+// Since a stream cannot go back, Range always returns nil. Fields that require Range,
+// such as the signature of Interest and Data, should be parsed from a reader given by Delegate or ReadTLV.
+type StreamReader struct {
+	r      io.Reader
+	br     io.ByteReader
+	pos    int
+	length int
+	// last is the last byte read, which is given again after UnreadByte.
+	last    byte
+	hasLast bool
+	unread  bool
+}
+
+// NewStreamReader creates a StreamReader that reads at most length bytes from r.
+// A negative length means the stream is read until it ends.
+func NewStreamReader(r io.Reader, length int) *StreamReader {
+	ret := &StreamReader{
+		r:      r,
+		length: length,
+	}
+	if br, ok := r.(io.ByteReader); ok {
+		ret.br = br
+	}
+	return ret
+}
+
+// remaining returns the number of bytes that can be read.
+func (r *StreamReader) remaining() int {
+	if r.length < 0 {
+		return math.MaxInt
+	}
+	return r.length - r.pos
+}
+
+func (r *StreamReader) Read(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	if r.remaining() <= 0 {
+		return 0, io.EOF
+	}
+	if r.unread {
+		// A short read avoids blocking on the stream
+		b[0] = r.last
+		r.unread = false
+		r.pos++
+		return 1, nil
+	}
+	if len(b) > r.remaining() {
+		b = b[:r.remaining()]
+	}
+	n, err := r.r.Read(b)
+	r.pos += n
+	if n > 0 {
+		r.last = b[n-1]
+		r.hasLast = true
+	}
+	return n, err
+}
+
+func (r *StreamReader) ReadByte() (byte, error) {
+	if r.remaining() <= 0 {
+		return 0, io.EOF
+	}
+	if r.unread {
+		r.unread = false
+		r.pos++
+		return r.last, nil
+	}
+	var ret byte
+	var err error
+	if r.br != nil {
+		ret, err = r.br.ReadByte()
+	} else {
+		var b [1]byte
+		_, err = io.ReadFull(r.r, b[:])
+		ret = b[0]
+	}
+	if err != nil {
+		return 0, err
+	}
+	r.pos++
+	r.last = ret
+	r.hasLast = true
+	return ret, nil
+}
+
+// UnreadByte unreads the last byte. Only one byte can be unread.
+func (r *StreamReader) UnreadByte() error {
+	if !r.hasLast || r.unread {
+		return errors.New("encoding.StreamReader.UnreadByte: no byte to unread")
+	}
+	r.unread = true
+	r.pos--
+	return nil
+}
+
+// ReadWireInto fills all buffers of w with the next bytes in the stream.
+// This allows streaming a large field into buffers provided by the caller.
+func (r *StreamReader) ReadWireInto(w Wire) error {
+	l := 0
+	for _, buf := range w {
+		l += len(buf)
+	}
+	if l > r.remaining() {
+		return io.ErrUnexpectedEOF
+	}
+	for _, buf := range w {
+		_, err := io.ReadFull(r, buf)
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *StreamReader) ReadWire(l int) (Wire, error) {
+	if r.remaining() <= 0 && l > 0 {
+		return nil, io.EOF
+	}
+	if l < 0 || l > r.remaining() {
+		return nil, io.ErrUnexpectedEOF
+	}
+	ret := Wire{make(Buffer, l)}
+	if err := r.ReadWireInto(ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *StreamReader) ReadBuf(l int) (Buffer, error) {
+	if l < 0 || l > r.remaining() {
+		return nil, io.ErrUnexpectedEOF
+	}
+	ret := make(Buffer, l)
+	if err := r.ReadWireInto(Wire{ret}); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Range is not supported by a stream and returns nil.
+func (r *StreamReader) Range(start, end int) Wire {
+	return nil
+}
+
+// Pos returns the number of bytes read.
+func (r *StreamReader) Pos() int {
+	return r.pos
+}
+
+// Length returns the length given to NewStreamReader, or math.MaxInt if the length is unknown.
+func (r *StreamReader) Length() int {
+	if r.length < 0 {
+		return math.MaxInt
+	}
+	return r.length
+}
+
+func (r *StreamReader) Skip(n int) error {
+	if n < 0 {
+		return errors.New("encoding.StreamReader.Skip: backword skipping is not allowed")
+	}
+	if n > r.remaining() {
+		return io.EOF
+	}
+	if r.unread && n > 0 {
+		r.unread = false
+		r.pos++
+		n--
+	}
+	m, err := io.CopyN(io.Discard, r.r, int64(n))
+	r.pos += int(m)
+	if err != nil {
+		return io.EOF
+	}
+	return nil
+}
+
+// Delegate reads the next l bytes into memory and returns a reader of them.
+// An empty reader is returned if the stream ends before that.
+func (r *StreamReader) Delegate(l int) ParseReader {
+	buf, err := r.ReadBuf(l)
+	if err != nil {
+		return NewBufferReader([]byte{})
+	}
+	return NewBufferReader(buf)
+}
+
+// ReadTLV reads the next TLV element, such as an NDN packet, including its TL prefix.
+// It returns io.EOF if the stream ends at a boundary of TLV elements, and io.ErrUnexpectedEOF if the element is
+// longer than the rest of the stream. An element larger than MaxPacketSize is an ErrFormat, and its value is left
+// unread, so a corrupted length does not allocate a huge buffer.
+func (r *StreamReader) ReadTLV() (Buffer, error) {
+	t, err := ReadTLNum(r)
+	if err != nil {
+		return nil, err
+	}
+	l, err := ReadTLNum(r)
+	if err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return nil, err
+	}
+	l0 := t.EncodingLength()
+	l1 := l.EncodingLength()
+	if l > TLNum(MaxPacketSize-l0-l1) {
+		return nil, ErrFormat{fmt.Sprintf("TLV element of length %d is larger than the max packet size", l)}
+	}
+	if int(l) > r.remaining() {
+		return nil, io.ErrUnexpectedEOF
+	}
+	buf := make(Buffer, l0+l1+int(l))
+	t.EncodeInto(buf)
+	l.EncodeInto(buf[l0:])
+	err = r.ReadWireInto(Wire{buf[l0+l1:]})
+	if err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
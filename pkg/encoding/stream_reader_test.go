@@ -0,0 +1,150 @@
+package encoding_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/ndn/spec_2022"
+	"github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+func makeDataStream(t *testing.T, n int) []byte {
+	spec := spec_2022.Spec{}
+	stream := []byte{}
+	for i := 0; i < n; i++ {
+		name := utils.WithoutErr(enc.NameFromStr(fmt.Sprintf("/test/seg=%d", i)))
+		content := enc.Wire{bytes.Repeat([]byte{byte(i)}, 100*i)}
+		wire, _, err := spec.MakeData(name, &ndn.DataConfig{
+			ContentType: utils.IdPtr(ndn.ContentTypeBlob),
+		}, content, security.NewSha256Signer())
+		require.NoError(t, err)
+		stream = append(stream, wire.Join()...)
+	}
+	return stream
+}
+
+func checkDataStream(t *testing.T, r *enc.StreamReader, n int) {
+	for i := 0; i < n; i++ {
+		buf, err := r.ReadTLV()
+		require.NoError(t, err)
+		pkt, ctx, err := spec_2022.ReadPacket(enc.NewBufferReader(buf))
+		require.NoError(t, err)
+		require.NotNil(t, pkt.Data)
+		require.Equal(t, fmt.Sprintf("/test/seg=%d", i), pkt.Data.Name().String())
+		require.Equal(t, bytes.Repeat([]byte{byte(i)}, 100*i), pkt.Data.Content().Join())
+		require.NotEmpty(t, ctx.Data_context.SigCovered())
+	}
+	_, err := r.ReadTLV()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestStreamReaderPackets(t *testing.T) {
+	utils.SetTestingT(t)
+
+	stream := makeDataStream(t, 5)
+	checkDataStream(t, enc.NewStreamReader(bytes.NewReader(stream), -1), 5)
+	checkDataStream(t, enc.NewStreamReader(iotest.OneByteReader(bytes.NewReader(stream)), -1), 5)
+
+	// A truncated packet is an error
+	r := enc.NewStreamReader(iotest.OneByteReader(bytes.NewReader(stream[:len(stream)-1])), -1)
+	for i := 0; i < 4; i++ {
+		utils.WithoutErr(r.ReadTLV())
+	}
+	_, err := r.ReadTLV()
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestStreamReaderParse(t *testing.T) {
+	utils.SetTestingT(t)
+
+	// Parse a packet directly from a stream limited to its length
+	stream := makeDataStream(t, 2)
+	first := utils.WithoutErr(enc.NewStreamReader(bytes.NewReader(stream), -1).ReadTLV())
+	src := iotest.OneByteReader(bytes.NewReader(stream))
+	r := enc.NewStreamReader(src, len(first))
+	pkt, _, err := spec_2022.ReadPacket(r)
+	require.NoError(t, err)
+	require.Equal(t, "/test/seg=0", pkt.Data.Name().String())
+	require.Equal(t, len(first), r.Pos())
+	r = enc.NewStreamReader(src, len(stream)-len(first))
+	pkt, _, err = spec_2022.ReadPacket(r)
+	require.NoError(t, err)
+	require.Equal(t, "/test/seg=1", pkt.Data.Name().String())
+	require.Equal(t, 100, len(pkt.Data.Content().Join()))
+
+	// Read names component by component
+	name := utils.WithoutErr(enc.NameFromStr("/a/b/c"))
+	buf := name.Bytes()
+	r = enc.NewStreamReader(iotest.OneByteReader(bytes.NewReader(buf)), len(buf))
+	require.Equal(t, enc.TypeName, utils.WithoutErr(enc.ReadTLNum(r)))
+	require.Equal(t, enc.TLNum(len(buf)-2), utils.WithoutErr(enc.ReadTLNum(r)))
+	require.Equal(t, name, utils.WithoutErr(enc.ReadName(r)))
+	require.Equal(t, len(buf), r.Pos())
+	utils.WithErr(r.ReadByte())
+}
+
+func TestStreamReaderBasic(t *testing.T) {
+	utils.SetTestingT(t)
+
+	data := []byte("0123456789abcdef")
+	r := enc.NewStreamReader(iotest.OneByteReader(bytes.NewReader(data)), 13)
+	require.Equal(t, 13, r.Length())
+	require.Equal(t, byte('0'), utils.WithoutErr(r.ReadByte()))
+	require.NoError(t, r.UnreadByte())
+	require.Error(t, r.UnreadByte())
+	require.Equal(t, 0, r.Pos())
+
+	// ReadWireInto fills buffers given by the caller
+	w := enc.Wire{make([]byte, 3), make([]byte, 2)}
+	require.NoError(t, r.ReadWireInto(w))
+	require.Equal(t, enc.Wire{[]byte("012"), []byte("34")}, w)
+	require.Equal(t, 5, r.Pos())
+
+	require.NoError(t, r.Skip(2))
+	require.Equal(t, enc.Wire{[]byte("78")}, utils.WithoutErr(r.ReadWire(2)))
+	require.Nil(t, r.Range(0, 2))
+	sub := r.Delegate(2)
+	require.Equal(t, enc.Buffer("9a"), utils.WithoutErr(sub.ReadBuf(2)))
+
+	// The limit is respected even if the stream has more data
+	_, err := r.ReadBuf(3)
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+	require.Equal(t, enc.Buffer("bc"), utils.WithoutErr(r.ReadBuf(2)))
+	utils.WithErr(r.ReadByte())
+	_, err = r.ReadWire(1)
+	require.Equal(t, io.EOF, err)
+}
+
+func TestStreamReaderLargeLength(t *testing.T) {
+	utils.SetTestingT(t)
+
+	// A length beyond the max packet size is rejected before the buffer is allocated
+	r := enc.NewStreamReader(bytes.NewReader([]byte("\x06\xff\x00\x00\x00\x01\x00\x00\x00\x00")), -1)
+	_, err := r.ReadTLV()
+	require.ErrorAs(t, err, &enc.ErrFormat{})
+	r = enc.NewStreamReader(bytes.NewReader([]byte("\x06\xfd\x22\x5d")), -1)
+	_, err = r.ReadTLV()
+	require.ErrorAs(t, err, &enc.ErrFormat{})
+
+	// So is a length beyond the rest of a bounded stream
+	r = enc.NewStreamReader(bytes.NewReader([]byte("\x06\xfd\x10\x00\x01\x02")), 6)
+	_, err = r.ReadTLV()
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+	r = enc.NewStreamReader(bytes.NewReader([]byte("0123")), 4)
+	_, err = r.ReadBuf(1 << 40)
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+	_, err = r.ReadWire(1 << 40)
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+
+	// A packet of the max size is still read
+	stream := append([]byte("\x06\xfd\x22\x5c"), make([]byte, enc.MaxPacketSize-4)...)
+	buf := utils.WithoutErr(enc.NewStreamReader(bytes.NewReader(stream), -1).ReadTLV())
+	require.Equal(t, enc.Buffer(stream), buf)
+}
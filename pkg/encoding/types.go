@@ -40,6 +40,80 @@ func (w Wire) Length() uint64 {
 	return ret
 }
 
+// Equal returns whether w and other have the same bytes, no matter how they are split into buffers.
+func (w Wire) Equal(other Wire) bool {
+	if w.Length() != other.Length() {
+		return false
+	}
+	i, j := 0, 0
+	var a, b Buffer
+	for {
+		for len(a) == 0 && i < len(w) {
+			a = w[i]
+			i++
+		}
+		for len(b) == 0 && j < len(other) {
+			b = other[j]
+			j++
+		}
+		if len(a) == 0 || len(b) == 0 {
+			// Both end at the same time since the lengths are equal
+			return true
+		}
+		n := min(len(a), len(b))
+		if string(a[:n]) != string(b[:n]) {
+			return false
+		}
+		a, b = a[n:], b[n:]
+	}
+}
+
+// Clone returns a deep copy of w with the same buffer boundaries, which shares no memory with w.
+func (w Wire) Clone() Wire {
+	if w == nil {
+		return nil
+	}
+	buf := make([]byte, w.Length())
+	ret := make(Wire, len(w))
+	pos := 0
+	for i, v := range w {
+		n := copy(buf[pos:], v)
+		ret[i] = buf[pos : pos+n : pos+n]
+		pos += n
+	}
+	return ret
+}
+
+// Slice returns the bytes of w in [start, end) without copying, which may span multiple buffers.
+// It returns nil if the range is out of bounds.
+func (w Wire) Slice(start, end int) Wire {
+	if start < 0 || start > end || uint64(end) > w.Length() {
+		return nil
+	}
+	ret := Wire{}
+	pos := 0
+	for _, v := range w {
+		if pos >= end {
+			break
+		}
+		next := pos + len(v)
+		if next > start && len(v) > 0 {
+			ret = append(ret, v[max(start-pos, 0):min(end, next)-pos])
+		}
+		pos = next
+	}
+	return ret
+}
+
+// UnknownField is an unrecognized non-critical TLV field kept by a parser, so it can be encoded back.
+type UnknownField struct {
+	Typ TLNum
+	Val Wire
+	// Pos is the index of the last known field of the model before it, or -1 if there is none.
+	// It is used by the encoder to put the field back to its original position.
+	Pos int
+}
+
 type ErrFormat struct {
 	Msg string
 }
@@ -0,0 +1,75 @@
+package encoding_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+func TestWireEqual(t *testing.T) {
+	utils.SetTestingT(t)
+
+	wire := enc.Wire{[]byte("hello, world")}
+	require.True(t, wire.Equal(wire))
+	require.True(t, wire.Equal(enc.Wire{[]byte("hello"), []byte(", "), []byte("world")}))
+	require.True(t, enc.Wire{[]byte("hel"), []byte("lo, wor"), []byte("ld")}.Equal(
+		enc.Wire{[]byte("hello"), nil, []byte(", w"), []byte{}, []byte("orld")}))
+	require.True(t, enc.Wire{}.Equal(nil))
+	require.True(t, enc.Wire{nil, []byte{}}.Equal(enc.Wire{}))
+
+	require.False(t, wire.Equal(enc.Wire{[]byte("hello"), []byte(", "), []byte("World")}))
+	require.False(t, wire.Equal(enc.Wire{[]byte("hello"), []byte(", world!")}))
+	require.False(t, wire.Equal(enc.Wire{[]byte("hello")}))
+	require.False(t, wire.Equal(nil))
+}
+
+func TestWireClone(t *testing.T) {
+	utils.SetTestingT(t)
+
+	wire := enc.Wire{[]byte("hello"), []byte{}, []byte(", world")}
+	clone := wire.Clone()
+	require.Equal(t, wire, clone)
+	require.True(t, wire.Equal(clone))
+
+	// The clone does not share memory with the original one
+	clone[0][0] = 'H'
+	require.Equal(t, []byte("hello"), []byte(wire[0]))
+	clone[1] = append(clone[1], '!')
+	require.Equal(t, []byte(", world"), []byte(clone[2]))
+	require.Equal(t, []byte(", world"), []byte(wire[2]))
+
+	require.Nil(t, enc.Wire(nil).Clone())
+	require.Equal(t, enc.Wire{}, enc.Wire{}.Clone())
+}
+
+func TestWireSlice(t *testing.T) {
+	utils.SetTestingT(t)
+
+	wire := enc.Wire{[]byte("hello"), []byte{}, []byte(", "), []byte("world")}
+	slice := func(start, end int) string {
+		return string(wire.Slice(start, end).Join())
+	}
+	require.Equal(t, "hello, world", slice(0, 12))
+	require.Equal(t, "ell", slice(1, 4))
+	require.Equal(t, "llo, wo", slice(2, 9))
+	require.Equal(t, ", ", slice(5, 7))
+	require.Equal(t, "world", slice(7, 12))
+	require.Equal(t, "", slice(5, 5))
+	require.Equal(t, "", slice(12, 12))
+
+	// The slice refers to the original buffers
+	s := wire.Slice(3, 8)
+	require.Equal(t, 3, len(s))
+	require.Same(t, &wire[0][3], &s[0][0])
+	require.Same(t, &wire[2][0], &s[1][0])
+	require.Same(t, &wire[3][0], &s[2][0])
+	wire[3][0] = 'W'
+	require.Equal(t, "lo, W", string(s.Join()))
+
+	require.Nil(t, wire.Slice(-1, 3))
+	require.Nil(t, wire.Slice(4, 3))
+	require.Nil(t, wire.Slice(0, 13))
+	require.Equal(t, enc.Wire{}, enc.Wire(nil).Slice(0, 0))
+}
@@ -0,0 +1,130 @@
+package basic
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+)
+
+// ContentStore caches the Data replied by the Interest handlers of an Engine.
+// Incoming Interests are looked up in it before being dispatched to the handlers.
+// Implementations must be safe for concurrent use.
+type ContentStore interface {
+	// Insert stores the encoded Data named name, which is fresh until freshUntil.
+	Insert(name enc.Name, wire enc.Wire, freshUntil time.Time)
+	// Find returns the encoded Data satisfying an Interest of name at the time now, or nil if there is none.
+	Find(name enc.Name, canBePrefix bool, mustBeFresh bool, now time.Time) enc.Wire
+}
+
+type csEntry struct {
+	name       enc.Name
+	wire       enc.Wire
+	size       int
+	freshUntil time.Time
+	node       *NameTrie[*list.Element]
+}
+
+// LruContentStore is a ContentStore bounded by the number of entries and the total size of the Data,
+// which evicts the least recently used Data first.
+// Stale Data are kept until evicted, as they can still satisfy Interests without MustBeFresh.
+type LruContentStore struct {
+	maxEntries int
+	maxBytes   int
+
+	lock  sync.Mutex
+	bytes int
+	// lru has the most recently used entry at the front
+	lru  *list.List
+	trie *NameTrie[*list.Element]
+}
+
+// NewLruContentStore creates a LruContentStore holding at most maxEntries Data and maxBytes bytes in total.
+// A limit of 0 means unlimited.
+func NewLruContentStore(maxEntries int, maxBytes int) *LruContentStore {
+	return &LruContentStore{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		lru:        list.New(),
+		trie:       NewNameTrie[*list.Element](),
+	}
+}
+
+func (cs *LruContentStore) Insert(name enc.Name, wire enc.Wire, freshUntil time.Time) {
+	size := int(wire.Length())
+	if cs.maxBytes > 0 && size > cs.maxBytes {
+		return
+	}
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	node := cs.trie.MatchAlways(name)
+	if elem := node.Value(); elem != nil {
+		cs.remove(elem)
+		node = cs.trie.MatchAlways(name)
+	}
+	entry := &csEntry{
+		name:       name,
+		wire:       wire,
+		size:       size,
+		freshUntil: freshUntil,
+		node:       node,
+	}
+	node.SetValue(cs.lru.PushFront(entry))
+	cs.bytes += size
+
+	for (cs.maxEntries > 0 && cs.lru.Len() > cs.maxEntries) || (cs.maxBytes > 0 && cs.bytes > cs.maxBytes) {
+		cs.remove(cs.lru.Back())
+	}
+}
+
+func (cs *LruContentStore) Find(name enc.Name, canBePrefix bool, mustBeFresh bool, now time.Time) enc.Wire {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	node := cs.trie.ExactMatch(name)
+	if node == nil {
+		return nil
+	}
+	satisfies := func(elem *list.Element) bool {
+		return elem != nil && (!mustBeFresh || elem.Value.(*csEntry).freshUntil.After(now))
+	}
+	if canBePrefix {
+		node = node.FirstNodeIf(satisfies)
+	} else if !satisfies(node.Value()) {
+		node = nil
+	}
+	if node == nil {
+		return nil
+	}
+	elem := node.Value()
+	cs.lru.MoveToFront(elem)
+	return elem.Value.(*csEntry).wire
+}
+
+// Len returns the number of Data stored.
+func (cs *LruContentStore) Len() int {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+	return cs.lru.Len()
+}
+
+// Size returns the total size of Data stored.
+func (cs *LruContentStore) Size() int {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+	return cs.bytes
+}
+
+// remove must be called with the lock held.
+func (cs *LruContentStore) remove(elem *list.Element) {
+	entry := cs.lru.Remove(elem).(*csEntry)
+	cs.bytes -= entry.size
+	entry.node.SetValue(nil)
+	if !entry.node.HasChildren() {
+		entry.node.DeleteIf(func(v *list.Element) bool {
+			return v == nil
+		})
+	}
+}
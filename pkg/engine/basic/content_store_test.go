@@ -0,0 +1,132 @@
+package basic_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	basic_engine "github.com/zjkmxy/go-ndn/pkg/engine/basic"
+	"github.com/zjkmxy/go-ndn/pkg/engine/dummy"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+func TestContentStoreFreshness(t *testing.T) {
+	utils.SetTestingT(t)
+
+	cs := basic_engine.NewLruContentStore(0, 0)
+	now := time.Unix(0, 0)
+	name := utils.WithoutErr(enc.NameFromStr("/a/b"))
+	cs.Insert(name, enc.Wire{[]byte("ab")}, now.Add(time.Second))
+
+	require.Equal(t, enc.Wire{[]byte("ab")}, cs.Find(name, false, true, now))
+	require.Nil(t, cs.Find(utils.WithoutErr(enc.NameFromStr("/a")), false, false, now))
+	require.Equal(t, enc.Wire{[]byte("ab")}, cs.Find(utils.WithoutErr(enc.NameFromStr("/a")), true, true, now))
+	require.Nil(t, cs.Find(utils.WithoutErr(enc.NameFromStr("/a/b/c")), true, false, now))
+
+	// Stale Data only satisfy Interests without MustBeFresh
+	now = now.Add(2 * time.Second)
+	require.Nil(t, cs.Find(name, false, true, now))
+	require.Nil(t, cs.Find(utils.WithoutErr(enc.NameFromStr("/a")), true, true, now))
+	require.Equal(t, enc.Wire{[]byte("ab")}, cs.Find(name, false, false, now))
+
+	// Inserting again replaces the old Data
+	cs.Insert(name, enc.Wire{[]byte("new")}, now.Add(time.Second))
+	require.Equal(t, enc.Wire{[]byte("new")}, cs.Find(name, false, true, now))
+	require.Equal(t, 1, cs.Len())
+	require.Equal(t, 3, cs.Size())
+}
+
+func TestContentStoreEviction(t *testing.T) {
+	utils.SetTestingT(t)
+
+	cs := basic_engine.NewLruContentStore(3, 10)
+	now := time.Unix(0, 0)
+	names := make([]enc.Name, 5)
+	for i := range names {
+		names[i] = enc.Name{enc.NewSequenceNumComponent(uint64(i))}
+	}
+	cs.Insert(names[0], enc.Wire{[]byte("0")}, now)
+	cs.Insert(names[1], enc.Wire{[]byte("1")}, now)
+	cs.Insert(names[2], enc.Wire{[]byte("2")}, now)
+	// Using 0 makes 1 the least recently used
+	require.NotNil(t, cs.Find(names[0], false, false, now))
+	cs.Insert(names[3], enc.Wire{[]byte("3")}, now)
+	require.Nil(t, cs.Find(names[1], false, false, now))
+	require.NotNil(t, cs.Find(names[0], false, false, now))
+	require.NotNil(t, cs.Find(names[2], false, false, now))
+	require.NotNil(t, cs.Find(names[3], false, false, now))
+	require.Equal(t, 3, cs.Len())
+
+	// Exceeding the byte limit evicts 0 and 2
+	cs.Insert(names[4], enc.Wire{[]byte("444444444")}, now)
+	require.Nil(t, cs.Find(names[0], false, false, now))
+	require.Nil(t, cs.Find(names[2], false, false, now))
+	require.NotNil(t, cs.Find(names[3], false, false, now))
+	require.NotNil(t, cs.Find(names[4], false, false, now))
+	require.Equal(t, 10, cs.Size())
+
+	// Data larger than the limit are not stored
+	cs.Insert(names[1], enc.Wire{make([]byte, 11)}, now)
+	require.Nil(t, cs.Find(names[1], false, false, now))
+	require.Equal(t, 2, cs.Len())
+}
+
+func TestEngineContentStore(t *testing.T) {
+	executeTest(t, func(face *dummy.DummyFace, engine *basic_engine.Engine, timer *dummy.Timer, signer ndn.Signer) {
+		hitCnt := 0
+		spec := engine.Spec()
+		engine.SetContentStore(basic_engine.NewLruContentStore(10, 0))
+
+		name := utils.WithoutErr(enc.NameFromStr("/not/important"))
+		engine.AttachHandler(utils.WithoutErr(enc.NameFromStr("/not")), func(
+			interest ndn.Interest, rawInterest enc.Wire, sigCovered enc.Wire, reply ndn.ReplyFunc, deadline time.Time,
+		) {
+			hitCnt += 1
+			data, _, err := spec.MakeData(
+				interest.Name(),
+				&ndn.DataConfig{
+					ContentType: utils.IdPtr(ndn.ContentTypeBlob),
+					Freshness:   utils.IdPtr(time.Second),
+				},
+				enc.Wire{[]byte("test")},
+				sec.NewEmptySigner())
+			require.NoError(t, err)
+			require.NoError(t, reply(data))
+		})
+
+		interest := func(canBePrefix bool) []byte {
+			config := &ndn.InterestConfig{
+				MustBeFresh: true,
+				CanBePrefix: canBePrefix,
+				Lifetime:    utils.IdPtr(time.Second),
+			}
+			intName := name
+			if canBePrefix {
+				intName = name[:1]
+			}
+			wire, _, _, err := spec.MakeInterest(intName, config, nil, nil)
+			require.NoError(t, err)
+			return wire.Join()
+		}
+
+		require.NoError(t, face.FeedPacket(interest(false)))
+		require.Equal(t, 1, hitCnt)
+		produced := utils.WithoutErr(face.Consume())
+
+		// Served from the content store without calling the handler
+		require.NoError(t, face.FeedPacket(interest(false)))
+		require.NoError(t, face.FeedPacket(interest(true)))
+		require.Equal(t, 1, hitCnt)
+		require.Equal(t, produced, utils.WithoutErr(face.Consume()))
+		require.Equal(t, produced, utils.WithoutErr(face.Consume()))
+
+		// The cached Data is stale after the FreshnessPeriod
+		timer.MoveForward(2 * time.Second)
+		require.NoError(t, face.FeedPacket(interest(false)))
+		require.Equal(t, 2, hitCnt)
+		utils.WithoutErr(face.Consume())
+	})
+}
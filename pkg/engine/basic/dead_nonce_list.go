@@ -0,0 +1,74 @@
+package basic
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+)
+
+// DefaultDeadNonceLifetime is how long an incoming Interest is remembered to detect loops, the same as NFD.
+const DefaultDeadNonceLifetime = 6 * time.Second
+
+// deadNonceKey identifies an Interest by the hash of its name and its Nonce.
+type deadNonceKey struct {
+	name  uint64
+	nonce uint32
+}
+
+// deadNonceEntry is an Interest remembered until expiry.
+type deadNonceEntry struct {
+	key    deadNonceKey
+	expiry time.Time
+}
+
+// deadNonceList remembers the Interests received recently, so one coming back through a loop is dropped.
+// All entries have the same lifetime, so they expire in the order they are inserted.
+type deadNonceList struct {
+	lifetime time.Duration
+	lock     sync.Mutex
+	entries  map[deadNonceKey]*list.Element
+	order    list.List
+}
+
+func newDeadNonceList(lifetime time.Duration) *deadNonceList {
+	return &deadNonceList{
+		lifetime: lifetime,
+		entries:  make(map[deadNonceKey]*list.Element),
+	}
+}
+
+// insert records the Interest of name and nonce received at now.
+// It returns false if the same one has been received within the lifetime.
+func (d *deadNonceList) insert(name enc.Name, nonce uint32, now time.Time) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.expire(now)
+	key := deadNonceKey{name: name.Hash(), nonce: nonce}
+	if _, ok := d.entries[key]; ok {
+		return false
+	}
+	d.entries[key] = d.order.PushBack(&deadNonceEntry{key: key, expiry: now.Add(d.lifetime)})
+	return true
+}
+
+// expire removes the entries expired at now.
+func (d *deadNonceList) expire(now time.Time) {
+	for front := d.order.Front(); front != nil; front = d.order.Front() {
+		entry := front.Value.(*deadNonceEntry)
+		if entry.expiry.After(now) {
+			return
+		}
+		d.order.Remove(front)
+		delete(d.entries, entry.key)
+	}
+}
+
+// size returns the number of entries not expired at now.
+func (d *deadNonceList) size(now time.Time) int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.expire(now)
+	return len(d.entries)
+}
@@ -4,10 +4,14 @@ package basic
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"math"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
@@ -21,12 +25,20 @@ import (
 const DefaultInterestLife = 4 * time.Second
 const TimeoutMargin = 10 * time.Millisecond
 
+// drainCheckInterval is how often ShutdownContext checks whether the PIT is empty.
+const drainCheckInterval = 10 * time.Millisecond
+
+// routeCmdParallelism is the number of register commands RegisterRoutes has on the way to the forwarder.
+const routeCmdParallelism = 8
+
 type Face interface {
 	Open() error
 	Close() error
 	Send(pkt enc.Wire) error
 	IsRunning() bool
 	IsLocal() bool
+	// SetCallback sets the callbacks of received packets and errors.
+	// The reader given to onPkt may be recycled after onPkt returns.
 	SetCallback(onPkt func(r enc.ParseReader) error,
 		onError func(err error) error)
 }
@@ -34,17 +46,32 @@ type Face interface {
 type fibEntry = ndn.InterestHandler
 
 type pendInt struct {
+	// name is the final name of the Interest, reported by PendingInterests.
+	name        enc.Name
 	callback    ndn.ExpressCallbackFunc
 	deadline    time.Time
 	canBePrefix bool
 	// mustBeFresh is actually not useful, since Freshness is decided by the cache, not us.
-	mustBeFresh   bool
-	impSha256     []byte
-	timeoutCancel func() error
+	mustBeFresh bool
+	impSha256   []byte
+	// forwardingHint and hopLimit change where the Interest is forwarded, so only equal ones are aggregated.
+	forwardingHint []enc.Name
+	hopLimit       *uint
+	nonce          *uint64
+	timeoutCancel  func() error
+	// rawInterest is kept to retransmit the Interest on timeout, and retries is the times left.
+	rawInterest enc.Wire
+	retries     int
+	timeout     time.Duration
 }
 
 type pitEntry = []*pendInt
 
+type route struct {
+	prefix enc.Name
+	opts   ndn.RouteOptions
+}
+
 type Engine struct {
 	face  Face
 	timer ndn.Timer
@@ -68,6 +95,47 @@ type Engine struct {
 
 	// cmdChecker is used to validate NFD management packets.
 	cmdChecker ndn.SigChecker
+
+	// keyChain selects signers for produced Data. May be nil.
+	keyChain ndn.SignerSelector
+
+	// validators check received packets by the longest prefix match. The root one is the default.
+	validators    *NameTrie[ndn.Validator]
+	validatorLock sync.Mutex
+	// validationWorkers is the number of workers validating received packets. 0 means validating on the thread
+	// reading the face. The workers are created by Start.
+	validationWorkers int
+	validationPool    *validationPool
+
+	// routes are the prefixes registered to the forwarder, which are registered again after the face reconnects.
+	routes    []route
+	routeLock sync.Mutex
+	// defaultLifetime is the lifetime of Interests that do not carry one.
+	// maxLifetime bounds the lifetime of all Interests, both expressed and received. 0 means no bound.
+	defaultLifetime time.Duration
+	maxLifetime     time.Duration
+
+	// deadNonces detects looping Interests. It is nil if the detection is disabled.
+	deadNonces        *deadNonceList
+	deadNonceLifetime time.Duration
+
+	// cmdLock serializes signing and sending commands, which may be executed concurrently by RegisterRoutes,
+	// so they reach the forwarder in the order of their timestamps.
+	cmdLock sync.Mutex
+
+	// congestionListeners are called when a received Data carries a CongestionMark.
+	congestionListeners map[int]func(name enc.Name, mark uint64)
+	nextListenerId      int
+	listenerLock        sync.Mutex
+
+	// counters are reported by Stats.
+	counters engineCounters
+
+	// cs caches the Data replied to incoming Interests. May be nil.
+	cs ContentStore
+
+	// draining is set by ShutdownContext to refuse new Interests while the pending ones finish.
+	draining atomic.Bool
 }
 
 func (e *Engine) EngineTrait() ndn.Engine {
@@ -78,6 +146,128 @@ func (*Engine) Spec() ndn.Spec {
 	return spec.Spec{}
 }
 
+// SetKeyChain sets the key chain used to select signers for produced Data.
+// It should be called before the engine starts.
+func (e *Engine) SetKeyChain(keyChain ndn.SignerSelector) {
+	e.keyChain = keyChain
+}
+
+// SetValidator sets the validator of the packets received under prefix. Data failing the validation is
+// dropped before satisfying any pending Interest, and Interests failing the validation are not given to
+// the handlers. The validator of the longest matching prefix is used, and the one of the empty prefix is
+// the default. Packets are not validated if no prefix matches. A nil v removes the validator of prefix.
+func (e *Engine) SetValidator(prefix enc.Name, v ndn.Validator) {
+	e.validatorLock.Lock()
+	defer e.validatorLock.Unlock()
+	if v != nil {
+		e.validators.MatchAlways(prefix).SetValue(v)
+		return
+	}
+	n := e.validators.ExactMatch(prefix)
+	if n == nil {
+		return
+	}
+	n.SetValue(nil)
+	if !n.HasChildren() {
+		n.DeleteIf(func(v ndn.Validator) bool {
+			return v == nil
+		})
+	}
+}
+
+// validatorFor returns the validator of the longest prefix of name, or nil if there is none.
+func (e *Engine) validatorFor(name enc.Name) ndn.Validator {
+	e.validatorLock.Lock()
+	defer e.validatorLock.Unlock()
+	for n := e.validators.PrefixMatch(name); n != nil; n = n.Parent() {
+		if v := n.Value(); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// SetNonceSource sets the source of the Nonces given by Timer().Nonce(), which are used by the engine
+// to retransmit Interests and make management commands, and conventionally by the users to make Interests.
+// It should be called before the engine starts.
+func (e *Engine) SetNonceSource(src ndn.NonceSource) {
+	if t, ok := e.timer.(nonceTimer); ok {
+		e.timer = t.Timer
+	}
+	if src != nil {
+		e.timer = nonceTimer{Timer: e.timer, src: src}
+	}
+}
+
+// SetDefaultInterestLifetime sets the lifetime assumed for Interests without one, both expressed and received.
+// Expressed Interests without a Lifetime are kept in the PIT for d. The encoded Interests are not changed,
+// so the forwarder still applies its own default.
+// The default is DefaultInterestLife, and a non-positive d restores it.
+// It should be called before the engine starts.
+func (e *Engine) SetDefaultInterestLifetime(d time.Duration) {
+	if d <= 0 {
+		d = DefaultInterestLife
+	}
+	e.defaultLifetime = d
+}
+
+// DefaultInterestLifetime returns the lifetime assumed for Interests without one.
+func (e *Engine) DefaultInterestLifetime() time.Duration {
+	return e.defaultLifetime
+}
+
+// SetMaxInterestLifetime bounds how long an expressed Interest stays in the PIT, and the deadline given to
+// handlers of a received Interest, so Interests with an oversized lifetime do not hold resources for long.
+// It applies to InterestConfig.Timeout as well. The default 0 means no bound.
+// Interests encoded by the engine, i.e. by ExpressInterestCtx, ExpressBatch and ExpressSignedInterest, carry the
+// bounded InterestLifetime. Interests given to Express are already encoded and sent as they are, so the forwarder
+// may keep them for their whole lifetime although they are removed from the PIT of the engine.
+// It should be called before the engine starts.
+func (e *Engine) SetMaxInterestLifetime(d time.Duration) {
+	e.maxLifetime = max(d, 0)
+}
+
+// MaxInterestLifetime returns the bound set by SetMaxInterestLifetime, or 0 if there is none.
+func (e *Engine) MaxInterestLifetime() time.Duration {
+	return e.maxLifetime
+}
+
+// interestLifetime returns the lifetime of an Interest carrying lifetime, which may be nil.
+func (e *Engine) interestLifetime(lifetime *time.Duration) time.Duration {
+	ret := e.defaultLifetime
+	if lifetime != nil {
+		ret = *lifetime
+	}
+	if e.maxLifetime > 0 && ret > e.maxLifetime {
+		ret = e.maxLifetime
+	}
+	return ret
+}
+
+// boundedConfig returns config with its Lifetime bounded by the max Interest lifetime, for an Interest encoded
+// by the engine. config is copied if it needs to be changed.
+func (e *Engine) boundedConfig(config *ndn.InterestConfig) *ndn.InterestConfig {
+	if e.maxLifetime <= 0 || config.Lifetime == nil || *config.Lifetime <= e.maxLifetime {
+		return config
+	}
+	ret := *config
+	ret.Lifetime = utils.IdPtr(e.maxLifetime)
+	return &ret
+}
+
+// SetContentStore sets the content store consulted before dispatching incoming Interests.
+// A nil cs disables caching. It should be called before the engine starts.
+func (e *Engine) SetContentStore(cs ContentStore) {
+	e.cs = cs
+}
+
+func (e *Engine) SignerForName(name enc.Name) ndn.Signer {
+	if e.keyChain == nil {
+		return nil
+	}
+	return e.keyChain.SignerForName(name)
+}
+
 func (e *Engine) Timer() ndn.Timer {
 	return e.timer
 }
@@ -86,11 +276,8 @@ func (e *Engine) AttachHandler(prefix enc.Name, handler ndn.InterestHandler) err
 	e.fibLock.Lock()
 	defer e.fibLock.Unlock()
 
-	pred := func(cb fibEntry) bool {
-		return cb != nil
-	}
-	n := e.fib.FirstSatisfyOrNew(prefix, pred)
-	if n.Value() != nil || n.HasChildren() {
+	n := e.fib.MatchAlways(prefix)
+	if n.Value() != nil {
 		return ndn.ErrPrefixPropViolation
 	}
 	n.SetValue(handler)
@@ -101,19 +288,40 @@ func (e *Engine) DetachHandler(prefix enc.Name) error {
 	e.fibLock.Lock()
 	defer e.fibLock.Unlock()
 
+	// Only the handler attached at prefix is detached. Those attached under prefix are kept.
 	n := e.fib.ExactMatch(prefix)
-	if n == nil {
+	if n == nil || n.Value() == nil {
 		return ndn.ErrInvalidValue{Item: "prefix", Value: prefix}
 	}
-	n.Delete()
+	n.SetValue(nil)
+	if !n.HasChildren() {
+		n.DeleteIf(func(cb fibEntry) bool {
+			return cb == nil
+		})
+	}
 	return nil
 }
 
+// readFragment parses the packet carried by an LpPacket, with a pooled reader.
+func readFragment(raw enc.Wire) (*spec.Packet, *spec.PacketParsingContext, error) {
+	if len(raw) == 1 {
+		r := enc.AcquireBufferReader(raw[0])
+		defer enc.ReleaseBufferReader(r)
+		return spec.ReadPacket(r)
+	}
+	r := enc.AcquireWireReader(raw)
+	defer enc.ReleaseWireReader(r)
+	return spec.ReadPacket(r)
+}
+
 func (e *Engine) onPacket(reader enc.ParseReader) error {
 	var nackReason uint64 = spec.NackReasonNone
+	var isNack bool = false
+	var congestionMark uint64 = 0
 	var pitToken []byte = nil
 	var raw enc.Wire = nil
 
+	e.counters.bytesIn.Add(uint64(reader.Length()))
 	if e.log.Level <= log.DebugLevel {
 		wire := reader.Range(0, reader.Length())
 		e.log.Debugf("Received packet bytes: %v", wire.Join())
@@ -130,16 +338,12 @@ func (e *Engine) onPacket(reader enc.ParseReader) error {
 	if pkt.LpPacket != nil {
 		lpPkt := pkt.LpPacket
 		if lpPkt.FragIndex != nil || lpPkt.FragCount != nil {
-			e.log.Warnf("Fragmented LpPackets are not supported. Drop.")
+			e.log.Warnf("Fragmented LpPackets need an LpFace to reassemble. Drop.")
 			return nil
 		}
 		// Parse the inner packet.
 		raw = pkt.LpPacket.Fragment
-		if len(raw) == 1 {
-			pkt, ctx, err = spec.ReadPacket(enc.NewBufferReader(raw[0]))
-		} else {
-			pkt, ctx, err = spec.ReadPacket(enc.NewWireReader(raw))
-		}
+		pkt, ctx, err = readFragment(raw)
 		if err != nil || (pkt.Data == nil) == (pkt.Interest == nil) {
 			e.log.Errorf("Failed to parse packet in LpPacket: %v", err)
 			if e.log.Level <= log.DebugLevel {
@@ -150,15 +354,20 @@ func (e *Engine) onPacket(reader enc.ParseReader) error {
 			return nil
 		}
 		// Set parameters
+		// A Nack without a reason is still a Nack, with reason None (unspecified)
 		if lpPkt.Nack != nil {
+			isNack = true
 			nackReason = lpPkt.Nack.Reason
 		}
 		pitToken = lpPkt.PitToken
+		if lpPkt.CongestionMark != nil {
+			congestionMark = *lpPkt.CongestionMark
+		}
 	} else {
 		raw = reader.Range(0, reader.Length())
 	}
 	// Now pkt is either Data or Interest (including Nack).
-	if nackReason != spec.NackReasonNone {
+	if isNack {
 		if pkt.Interest == nil {
 			e.log.Errorf("Received nack for an Data")
 			return nil
@@ -167,18 +376,24 @@ func (e *Engine) onPacket(reader enc.ParseReader) error {
 			nameStr := pkt.Interest.NameV.String()
 			e.log.WithField("name", nameStr).Infof("Nack received for %v", nackReason)
 		}
+		e.counters.nacksReceived.Add(1)
 		e.onNack(pkt.Interest.NameV, nackReason)
 	} else if pkt.Interest != nil {
 		if e.log.Level <= log.InfoLevel {
 			nameStr := pkt.Interest.NameV.String()
 			e.log.WithField("name", nameStr).Info("Interest received.")
 		}
+		e.counters.interestsReceived.Add(1)
 		e.onInterest(pkt.Interest, ctx.Interest_context.SigCovered(), raw, pitToken)
 	} else if pkt.Data != nil {
 		if e.log.Level <= log.InfoLevel {
 			nameStr := pkt.Data.NameV.String()
 			e.log.WithField("name", nameStr).Info("Data received.")
 		}
+		e.counters.dataReceived.Add(1)
+		if congestionMark > 0 {
+			e.onCongestionMark(pkt.Data.NameV, congestionMark)
+		}
 		// PitToken is not used for now
 		e.onData(pkt.Data, ctx.Data_context.SigCovered(), raw, pitToken)
 	} else {
@@ -188,30 +403,76 @@ func (e *Engine) onPacket(reader enc.ParseReader) error {
 }
 
 func (e *Engine) onInterest(pkt *spec.Interest, sigCovered enc.Wire, raw enc.Wire, pitToken []byte) {
+	if e.draining.Load() {
+		e.log.WithField("name", pkt.NameV.String()).Info("Engine is shutting down. Drop.")
+		return
+	}
+
+	// An Interest seen recently has come back through a loop
+	if e.deadNonces != nil && pkt.NonceV != nil && !e.deadNonces.insert(pkt.NameV, *pkt.NonceV, e.timer.Now()) {
+		e.counters.interestsLooped.Add(1)
+		e.log.WithField("name", pkt.NameV.String()).Info("Duplicate Nonce. Drop.")
+		return
+	}
+
 	// Compute deadline
-	deadline := e.timer.Now()
-	if pkt.InterestLifetimeV != nil {
-		deadline = deadline.Add(*pkt.InterestLifetimeV)
-	} else {
-		deadline = deadline.Add(DefaultInterestLife)
+	deadline := e.timer.Now().Add(e.interestLifetime(pkt.InterestLifetimeV))
+
+	v := e.validatorFor(pkt.NameV)
+	if v == nil {
+		e.dispatchInterest(pkt, sigCovered, raw, pitToken, deadline)
+		return
+	}
+	e.validate(pkt.NameV, func() {
+		if !v.ValidateInterest(pkt, sigCovered, pkt.Signature()) {
+			e.log.WithField("name", pkt.NameV.String()).Warn("Interest failed the validation. Drop.")
+			return
+		}
+		e.dispatchInterest(pkt, sigCovered, raw, pitToken, deadline)
+	})
+}
+
+// dispatchInterest replies to a trusted Interest from the content store, or gives it to the handler.
+func (e *Engine) dispatchInterest(
+	pkt *spec.Interest, sigCovered enc.Wire, raw enc.Wire, pitToken []byte, deadline time.Time,
+) {
+	// An Interest for a full name is only satisfied by the Data with the same digest.
+	// ReadPacket has ensured that the digest is the last component and CanBePrefix is not set.
+	var impSha256 []byte
+	if l := len(pkt.NameV); l > 0 && pkt.NameV[l-1].Typ == enc.TypeImplicitSha256DigestComponent {
+		impSha256 = pkt.NameV[l-1].Val
+	}
+
+	// Reply from the content store if possible
+	if e.cs != nil {
+		var wire enc.Wire
+		if impSha256 != nil {
+			wire = e.cs.Find(pkt.NameV[:len(pkt.NameV)-1], false, pkt.MustBeFreshV, e.timer.Now())
+			if wire != nil && !bytes.Equal(impSha256, wireDigest(wire)) {
+				wire = nil
+			}
+		} else {
+			wire = e.cs.Find(pkt.NameV, pkt.CanBePrefixV, pkt.MustBeFreshV, e.timer.Now())
+		}
+		if wire != nil {
+			if err := e.sendReply(wire, pitToken); err != nil {
+				e.log.WithField("name", pkt.NameV.String()).Errorf("Unable to reply from the content store: %+v", err)
+			}
+			return
+		}
 	}
 
 	// Match node
 	handler := func() ndn.InterestHandler {
 		e.fibLock.Lock()
 		defer e.fibLock.Unlock()
-		n := e.fib.PrefixMatch(pkt.NameV)
-		// We can directly return because of the prefix-free condition
-		return n.Value()
-		// If it does not hold, us the following:
-		// for n != nil && n.Value() == nil {
-		// 	n = n.Parent()
-		// }
-		// if n == nil {
-		// 	return nil
-		// } else {
-		// 	return n.Value()
-		// }
+		// The handler attached at the longest prefix of the name
+		for n := e.fib.PrefixMatch(pkt.NameV); n != nil; n = n.Parent() {
+			if h := n.Value(); h != nil {
+				return h
+			}
+		}
+		return nil
 	}()
 	if handler == nil {
 		e.log.WithField("name", pkt.NameV.String()).Warn("No handler. Drop.")
@@ -229,23 +490,14 @@ func (e *Engine) onInterest(pkt *spec.Interest, sigCovered enc.Wire, raw enc.Wir
 			e.log.WithField("name", pkt.NameV.String()).Error("Cannot send through a closed face. Drop.")
 			return ndn.ErrFaceDown
 		}
-		if pitToken != nil {
-			lpPkt := &spec.Packet{
-				LpPacket: &spec.LpPacket{
-					PitToken: pitToken,
-					Fragment: encodedData,
-				},
-			}
-			encoder := spec.PacketEncoder{}
-			encoder.Init(lpPkt)
-			wire := encoder.Encode(lpPkt)
-			if wire == nil {
-				return ndn.ErrFailedToEncode
-			}
-			return e.face.Send(wire)
-		} else {
-			return e.face.Send(encodedData)
+		if impSha256 != nil && !bytes.Equal(impSha256, wireDigest(encodedData)) {
+			e.log.WithField("name", pkt.NameV.String()).Warn("Data does not match the implicit digest. Drop.")
+			return enc.ErrIncorrectDigest
+		}
+		if e.cs != nil {
+			e.cacheData(encodedData, now)
 		}
+		return e.sendReply(encodedData, pitToken)
 	}
 
 	// Call the handler. The handler should create goroutine to avoid blocking.
@@ -254,6 +506,31 @@ func (e *Engine) onInterest(pkt *spec.Interest, sigCovered enc.Wire, raw enc.Wir
 }
 
 func (e *Engine) onData(pkt *spec.Data, sigCovered enc.Wire, raw enc.Wire, pitToken []byte) {
+	v := e.validatorFor(pkt.NameV)
+	if v == nil {
+		e.satisfy(pkt, sigCovered, raw)
+		return
+	}
+	e.validate(pkt.NameV, func() {
+		if !v.ValidateData(pkt, sigCovered, pkt.Signature()) {
+			e.log.WithField("name", pkt.NameV.String()).Warn("Data failed the validation. Drop.")
+			return
+		}
+		e.satisfy(pkt, sigCovered, raw)
+	})
+}
+
+// validate runs the validation job of a packet of name, on the validation workers if there are.
+func (e *Engine) validate(name enc.Name, job func()) {
+	if e.validationPool != nil {
+		e.validationPool.submit(name, job)
+	} else {
+		job()
+	}
+}
+
+// satisfy gives a trusted Data to the pending Interests it satisfies.
+func (e *Engine) satisfy(pkt *spec.Data, sigCovered enc.Wire, raw enc.Wire) {
 	e.pitLock.Lock()
 	defer e.pitLock.Unlock()
 	n := e.pit.PrefixMatch(pkt.NameV)
@@ -261,6 +538,8 @@ func (e *Engine) onData(pkt *spec.Data, sigCovered enc.Wire, raw enc.Wire, pitTo
 		e.log.WithField("name", pkt.NameV.String()).Warn("Received Data for an unknown interest. Drop.")
 		return
 	}
+	// The digest is computed only if some entry asks for it
+	var digest []byte
 	for cur := n; cur != nil; cur = cur.Parent() {
 		curListSize := len(cur.Value())
 		if curListSize <= 0 {
@@ -276,11 +555,9 @@ func (e *Engine) onData(pkt *spec.Data, sigCovered enc.Wire, raw enc.Wire, pitTo
 			// We don't check MustBeFresh, as it is the job of the cache/forwarder.
 			// ImplicitDigest256
 			if entry.impSha256 != nil {
-				h := sha256.New()
-				for _, buf := range raw {
-					h.Write(buf)
+				if digest == nil {
+					digest = wireDigest(raw)
 				}
-				digest := h.Sum(nil)
 				if !bytes.Equal(entry.impSha256, digest) {
 					newList = append(newList, entry)
 					continue
@@ -288,6 +565,8 @@ func (e *Engine) onData(pkt *spec.Data, sigCovered enc.Wire, raw enc.Wire, pitTo
 			}
 			// entry satisfied
 			entry.timeoutCancel()
+			e.counters.interestsSatisfied.Add(1)
+			e.counters.pitSize.Add(-1)
 			if entry.callback == nil {
 				e.log.Fatalf("PIT has empty entry. This should not happen. Please check the implementation.")
 				continue
@@ -296,9 +575,26 @@ func (e *Engine) onData(pkt *spec.Data, sigCovered enc.Wire, raw enc.Wire, pitTo
 		}
 		cur.SetValue(newList)
 	}
-	n.DeleteIf(func(lst []*pendInt) bool {
-		return len(lst) == 0
-	})
+	prunePit(n)
+}
+
+// prunePit removes a PIT node and its ancestors without pending Interests.
+// Nodes with children are kept, since the Interests of longer names are still pending.
+func prunePit(n *NameTrie[pitEntry]) {
+	if !n.HasChildren() {
+		n.DeleteIf(func(lst []*pendInt) bool {
+			return len(lst) == 0
+		})
+	}
+}
+
+// wireDigest returns the SHA-256 digest of a packet, which is the implicit digest of a Data.
+func wireDigest(wire enc.Wire) []byte {
+	h := sha256.New()
+	for _, buf := range wire {
+		h.Write(buf)
+	}
+	return h.Sum(nil)
 }
 
 func (e *Engine) onNack(name enc.Name, reason uint64) {
@@ -311,27 +607,120 @@ func (e *Engine) onNack(name enc.Name, reason uint64) {
 	}
 	for _, entry := range n.Value() {
 		entry.timeoutCancel()
+		e.counters.interestsNacked.Add(1)
+		e.counters.pitSize.Add(-1)
 		if entry.callback != nil {
 			entry.callback(ndn.InterestResultNack, nil, nil, nil, reason)
 		} else {
 			e.log.Fatalf("PIT has empty entry. This should not happen. Please check the implementation.")
 		}
 	}
-	n.Delete()
+	n.SetValue(nil)
+	prunePit(n)
+}
+
+// OnCongestionMark adds a listener called when a received Data carries an NDNLPv2 CongestionMark,
+// before the Data is given to the pending Interests. It returns a function to remove the listener.
+func (e *Engine) OnCongestionMark(listener func(name enc.Name, mark uint64)) (cancel func()) {
+	e.listenerLock.Lock()
+	defer e.listenerLock.Unlock()
+	id := e.nextListenerId
+	e.nextListenerId++
+	e.congestionListeners[id] = listener
+	return func() {
+		e.listenerLock.Lock()
+		defer e.listenerLock.Unlock()
+		delete(e.congestionListeners, id)
+	}
+}
+
+func (e *Engine) onCongestionMark(name enc.Name, mark uint64) {
+	e.listenerLock.Lock()
+	listeners := make([]func(enc.Name, uint64), 0, len(e.congestionListeners))
+	for _, l := range e.congestionListeners {
+		listeners = append(listeners, l)
+	}
+	e.listenerLock.Unlock()
+	if e.log.Level <= log.InfoLevel {
+		e.log.WithField("name", name.String()).Infof("Congestion mark received: %d", mark)
+	}
+	for _, l := range listeners {
+		l(name, mark)
+	}
 }
 
 func (e *Engine) onError(err error) error {
+	if errors.Is(err, ErrPacketTooLarge) {
+		// Only the received datagram is dropped
+		e.log.Warnf("Oversized packet dropped: %v", err)
+		return nil
+	}
 	e.log.Errorf("Error on face, quit: %v", err)
 	// TODO: Handle Interest cancellation
 	return err
 }
 
+// OnFaceStateChange adds a callback called when the state of the face changes.
+// It returns ErrNotSupported if the face does not report its state.
+func (e *Engine) OnFaceStateChange(callback func(old, new FaceState)) error {
+	sf, ok := e.face.(StatefulFace)
+	if !ok {
+		return ndn.ErrNotSupported{Item: "face state"}
+	}
+	sf.OnStateChange(callback)
+	return nil
+}
+
+func (e *Engine) onReconnect() {
+	e.routeLock.Lock()
+	routes := make([]route, len(e.routes))
+	copy(routes, e.routes)
+	e.routeLock.Unlock()
+	e.log.Infof("Face reconnected. Registering %d prefixes again.", len(routes))
+	// RegisterRoute waits for the response, which is received by the face's loop calling this function.
+	go func() {
+		for _, r := range routes {
+			e.RegisterRoute(r.prefix, r.opts)
+		}
+	}()
+}
+
+func (e *Engine) addRoute(prefix enc.Name, opts ndn.RouteOptions) {
+	e.routeLock.Lock()
+	defer e.routeLock.Unlock()
+	for i, r := range e.routes {
+		if r.prefix.Equal(prefix) {
+			e.routes[i].opts = opts
+			return
+		}
+	}
+	e.routes = append(e.routes, route{prefix: prefix, opts: opts})
+}
+
+func (e *Engine) removeRoute(prefix enc.Name) {
+	e.routeLock.Lock()
+	defer e.routeLock.Unlock()
+	for i, r := range e.routes {
+		if r.prefix.Equal(prefix) {
+			e.routes = append(e.routes[:i], e.routes[i+1:]...)
+			return
+		}
+	}
+}
+
 func (e *Engine) Start() error {
 	if e.face.IsRunning() {
 		return errors.New("Face is already running")
 	}
 	e.log.Info("Default engine start.")
+	e.draining.Store(false)
+	if e.validationWorkers > 0 {
+		e.validationPool = newValidationPool(e.validationWorkers)
+	}
 	e.face.SetCallback(e.onPacket, e.onError)
+	if rf, ok := e.face.(ReconnectableFace); ok {
+		rf.SetReconnectCallback(e.onReconnect)
+	}
 	err := e.face.Open()
 	if err != nil {
 		e.log.Errorf("Face failed to open: %v", err)
@@ -340,17 +729,107 @@ func (e *Engine) Start() error {
 	return nil
 }
 
+// Shutdown closes the face. Packets already queued on the validation workers are still validated and delivered
+// after it returns, so it may be called from the callback of an Interest satisfied by a validated Data.
 func (e *Engine) Shutdown() error {
 	if !e.face.IsRunning() {
 		return errors.New("Face is not running")
 	}
 	e.log.Info("Default engine shutdown.")
-	return e.face.Close()
+	err := e.face.Close()
+	if e.validationPool != nil {
+		e.validationPool.stop()
+	}
+	return err
+}
+
+// ShutdownContext shuts down the engine gracefully. It stops expressing and accepting new Interests, waits for
+// the pending Interests to be satisfied, nacked or timed out, unregisters the registered prefixes, and then
+// closes the face. If ctx is done before the pending Interests finish, it stops waiting and returns ctx.Err()
+// after closing the face. Prefixes are not unregistered once ctx is done, since each command could take up to
+// its lifetime; the forwarder removes them with the face.
+func (e *Engine) ShutdownContext(ctx context.Context) error {
+	if !e.face.IsRunning() {
+		return errors.New("Face is not running")
+	}
+	e.log.Info("Default engine draining.")
+	e.draining.Store(true)
+
+	ticker := time.NewTicker(drainCheckInterval)
+	var ctxErr error
+	for ctxErr == nil && e.counters.pitSize.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			ctxErr = ctx.Err()
+			e.log.Warnf("Shutting down with %d pending Interests: %v", e.counters.pitSize.Load(), ctxErr)
+		case <-ticker.C:
+		}
+	}
+	ticker.Stop()
+
+	e.routeLock.Lock()
+	routes := make([]route, len(e.routes))
+	copy(routes, e.routes)
+	e.routeLock.Unlock()
+	for i, r := range routes {
+		if ctxErr == nil {
+			ctxErr = ctx.Err()
+		}
+		if ctxErr != nil {
+			e.log.Warnf("Shutting down without unregistering %d prefixes: %v", len(routes)-i, ctxErr)
+			break
+		}
+		// Errors are logged by UnregisterRoute
+		e.UnregisterRoute(r.prefix, ndn.RouteOptions{FaceId: r.opts.FaceId, Origin: r.opts.Origin})
+	}
+
+	if err := e.Shutdown(); err != nil {
+		return err
+	}
+	return ctxErr
 }
 
 func (e *Engine) Express(
 	finalName enc.Name, config *ndn.InterestConfig, rawInterest enc.Wire, callback ndn.ExpressCallbackFunc,
 ) error {
+	_, err := e.express(finalName, config, rawInterest, callback)
+	return err
+}
+
+// interestToken cancels an Interest expressed by ExpressCancelable.
+type interestToken struct {
+	cancel func() bool
+}
+
+func (t interestToken) Cancel() bool {
+	return t.cancel()
+}
+
+func (e *Engine) ExpressCancelable(
+	finalName enc.Name, config *ndn.InterestConfig, rawInterest enc.Wire, callback ndn.ExpressCallbackFunc,
+) (ndn.InterestToken, error) {
+	cancel, err := e.express(finalName, config, rawInterest, callback)
+	if err != nil {
+		return nil, err
+	}
+	return interestToken{cancel: cancel}, nil
+}
+
+// express expresses an Interest as Express does. It returns a function that removes the Interest from the PIT
+// without calling the callback, which returns false if the Interest is not pending anymore.
+func (e *Engine) express(
+	finalName enc.Name, config *ndn.InterestConfig, rawInterest enc.Wire, callback ndn.ExpressCallbackFunc,
+) (cancel func() bool, err error) {
+	if e.draining.Load() {
+		return nil, ndn.ErrShuttingDown
+	}
+	return e.pend(finalName, config, rawInterest, callback)
+}
+
+// pend puts an Interest into the PIT and sends it as express does, even if the engine is shutting down.
+func (e *Engine) pend(
+	finalName enc.Name, config *ndn.InterestConfig, rawInterest enc.Wire, callback ndn.ExpressCallbackFunc,
+) (cancel func() bool, err error) {
 	var impSha256 []byte = nil
 	var nodeName enc.Name = finalName
 
@@ -360,7 +839,7 @@ func (e *Engine) Express(
 
 	// Handle implicit digest
 	if len(finalName) <= 0 {
-		return ndn.ErrInvalidValue{Item: "finalName", Value: finalName}
+		return nil, ndn.ErrInvalidValue{Item: "finalName", Value: finalName}
 	}
 	lastComp := finalName[len(finalName)-1]
 	if lastComp.Typ == enc.TypeImplicitSha256DigestComponent {
@@ -369,19 +848,50 @@ func (e *Engine) Express(
 	}
 
 	// Handle deadline
-	lifetime := DefaultInterestLife
-	if config.Lifetime != nil {
-		lifetime = *config.Lifetime
+	timeout := e.interestLifetime(config.Lifetime)
+	if config.Timeout != nil {
+		timeout = e.interestLifetime(config.Timeout)
 	}
-	deadline := e.timer.Now().Add(lifetime)
+	deadline := e.timer.Now().Add(timeout)
 
 	// Inject interest into PIT
-	func() {
+	aggregated := false
+	err = func() error {
 		e.pitLock.Lock()
 		defer e.pitLock.Unlock()
 
 		n := e.pit.MatchAlways(nodeName)
-		timeoutFunc := func() {
+		if config.Nonce != nil {
+			// The forwarder would drop the second one as a loop
+			for _, entry := range n.Value() {
+				if entry.nonce != nil && uint32(*entry.nonce) == uint32(*config.Nonce) {
+					return ndn.ErrDuplicateNonce
+				}
+			}
+		}
+		// An identical Interest already sent will bring the Data back, as long as it is pending no shorter
+		for _, entry := range n.Value() {
+			if entry.canBePrefix == config.CanBePrefix && entry.mustBeFresh == config.MustBeFresh &&
+				bytes.Equal(entry.impSha256, impSha256) && sameForwardingHint(entry.forwardingHint, config.ForwardingHint) &&
+				sameHopLimit(entry.hopLimit, config.HopLimit) && !deadline.After(entry.deadline.Add(TimeoutMargin)) {
+				aggregated = true
+				break
+			}
+		}
+		var timeoutFunc func()
+		timeoutFunc = func() {
+			resend := make([]enc.Wire, 0)
+			defer func() {
+				// Send after the lock is released, as the face may call back into the engine
+				for _, wire := range resend {
+					err := e.sendInterest(wire)
+					if err != nil {
+						e.log.Errorf("Failed to retransmit Interest: %v", err)
+					} else if e.log.Level <= log.InfoLevel {
+						e.log.WithField("name", finalName.String()).Info("Interest retransmitted.")
+					}
+				}
+			}()
 			e.pitLock.Lock()
 			defer e.pitLock.Unlock()
 			now := e.timer.Now()
@@ -390,7 +900,25 @@ func (e *Engine) Express(
 			for _, entry := range lst {
 				if entry.deadline.After(now) {
 					newLst = append(newLst, entry)
+				} else if entry.retries > 0 {
+					nonce := utils.ConvertNonce(e.timer.Nonce())
+					wire, err := renewNonce(entry.rawInterest, *nonce)
+					if err != nil {
+						e.log.WithField("name", finalName.String()).Errorf("Unable to retransmit Interest: %v", err)
+						wire = entry.rawInterest
+					} else if entry.nonce != nil {
+						entry.nonce = nonce
+					}
+					entry.retries--
+					entry.timeout *= 2
+					entry.rawInterest = wire
+					entry.deadline = now.Add(entry.timeout)
+					entry.timeoutCancel = e.timer.Schedule(entry.timeout+TimeoutMargin, timeoutFunc)
+					newLst = append(newLst, entry)
+					resend = append(resend, wire)
 				} else {
+					e.counters.interestsTimedOut.Add(1)
+					e.counters.pitSize.Add(-1)
 					if entry.callback != nil {
 						entry.callback(ndn.InterestResultTimeout, nil, nil, nil, spec.NackReasonNone)
 					} else {
@@ -399,116 +927,573 @@ func (e *Engine) Express(
 				}
 			}
 			n.SetValue(newLst)
-			n.DeleteIf(func(lst []*pendInt) bool {
-				return len(lst) == 0
-			})
+			prunePit(n)
 		}
 		entry := &pendInt{
-			callback:      callback,
-			deadline:      deadline,
-			canBePrefix:   config.CanBePrefix,
-			mustBeFresh:   config.MustBeFresh,
-			impSha256:     impSha256,
-			timeoutCancel: e.timer.Schedule(lifetime+TimeoutMargin, timeoutFunc),
+			name:           finalName,
+			callback:       callback,
+			deadline:       deadline,
+			canBePrefix:    config.CanBePrefix,
+			mustBeFresh:    config.MustBeFresh,
+			impSha256:      impSha256,
+			forwardingHint: slices.Clone(config.ForwardingHint),
+			timeoutCancel:  e.timer.Schedule(timeout+TimeoutMargin, timeoutFunc),
+			retries:        config.Retries,
+			timeout:        timeout,
+		}
+		if config.Nonce != nil {
+			// The caller may reuse its config, and retransmissions replace the Nonce of the entry
+			entry.nonce = utils.IdPtr(*config.Nonce)
+		}
+		if config.HopLimit != nil {
+			entry.hopLimit = utils.IdPtr(*config.HopLimit)
+		}
+		if config.Retries > 0 {
+			entry.rawInterest = rawInterest
 		}
 		n.SetValue(append(n.Value(), entry))
+		e.counters.pitSize.Add(1)
+
+		cancel = func() bool {
+			e.pitLock.Lock()
+			defer e.pitLock.Unlock()
+			lst := n.Value()
+			for i, p := range lst {
+				if p != entry {
+					continue
+				}
+				// The node is still in the PIT, since it is not empty
+				p.timeoutCancel()
+				n.SetValue(append(lst[:i:i], lst[i+1:]...))
+				prunePit(n)
+				e.counters.pitSize.Add(-1)
+				return true
+			}
+			return false
+		}
+		return nil
 	}()
+	if err != nil {
+		return nil, err
+	}
+	if aggregated {
+		e.counters.interestsAggregated.Add(1)
+		if e.log.Level <= log.InfoLevel {
+			e.log.WithField("name", finalName.String()).Info("Interest aggregated.")
+		}
+		return cancel, nil
+	}
 
 	// Send interest
-	err := e.face.Send(rawInterest)
+	err = e.sendInterest(rawInterest)
 	if err != nil {
 		e.log.Errorf("Failed to send Interest: %v", err)
 	} else if e.log.Level <= log.InfoLevel {
 		e.log.WithField("name", finalName.String()).Info("Interest sent.")
 	}
 
+	return cancel, err
+}
+
+// sameForwardingHint returns true if the forwarding hints a and b have the same names in the same order.
+func sameForwardingHint(a, b []enc.Name) bool {
+	return slices.EqualFunc(a, b, func(x, y enc.Name) bool {
+		return x.Equal(y)
+	})
+}
+
+// sameHopLimit returns true if the hop limits a and b are both absent or equal.
+func sameHopLimit(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// ExpressInterestCtx expresses interest and waits for the Data.
+// It returns ndn.ErrNack if the Interest is nacked, ndn.ErrDeadlineExceed if it times out,
+// or the error of ctx if ctx is done first, in which case the Interest is removed from the PIT.
+// A Nonce is given if interest does not have one.
+// Since the Interest is encoded again, signed Interests are not supported. Use ExpressSignedInterest instead.
+func (e *Engine) ExpressInterestCtx(ctx context.Context, interest ndn.Interest) (ndn.Data, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	config, wire, finalName, err := e.prepareInterest(interest)
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		data ndn.Data
+		err  error
+	}
+	ch := make(chan result, 1)
+	cancel, err := e.express(finalName, config, wire,
+		func(res ndn.InterestResult, data ndn.Data, _, _ enc.Wire, nackReason uint64) {
+			switch res {
+			case ndn.InterestResultData:
+				ch <- result{data: data}
+			case ndn.InterestResultNack:
+				ch <- result{err: ndn.ErrNack{Reason: nackReason}}
+			case ndn.InterestResultTimeout:
+				ch <- result{err: ndn.ErrDeadlineExceed}
+			case ndn.InterestCancelled:
+				ch <- result{err: ndn.ErrFaceDown}
+			default:
+				ch <- result{err: fmt.Errorf("unexpected Interest result: %d", res)}
+			}
+		})
+	if err != nil {
+		if cancel != nil {
+			cancel()
+		}
+		return nil, err
+	}
+	select {
+	case ret := <-ch:
+		return ret.data, ret.err
+	case <-ctx.Done():
+		if !cancel() {
+			// The result came before the Interest is removed
+			ret := <-ch
+			return ret.data, ret.err
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// prepareInterest encodes interest again to be expressed, with a Nonce given if it does not have one.
+func (e *Engine) prepareInterest(interest ndn.Interest) (*ndn.InterestConfig, enc.Wire, enc.Name, error) {
+	if sig := interest.Signature(); sig != nil && sig.SigType() != ndn.SignatureNone {
+		return nil, nil, nil, ndn.ErrNotSupported{Item: "signed Interest"}
+	}
+	config := &ndn.InterestConfig{
+		CanBePrefix:    interest.CanBePrefix(),
+		MustBeFresh:    interest.MustBeFresh(),
+		ForwardingHint: interest.ForwardingHint(),
+		Nonce:          interest.Nonce(),
+		Lifetime:       interest.Lifetime(),
+		HopLimit:       interest.HopLimit(),
+	}
+	if config.Nonce == nil {
+		config.Nonce = utils.ConvertNonce(e.timer.Nonce())
+	}
+	config = e.boundedConfig(config)
+	wire, _, finalName, err := e.Spec().MakeInterest(interest.Name(), config, interest.AppParam(), nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return config, wire, finalName, nil
+}
+
+// ExpressBatch expresses interests with at most window of them pending at the same time,
+// and waits for all of them. A window not positive means no limit.
+// Results are in the order of interests. An Interest that fails to be expressed has its Err set.
+// If ctx is done first, the pending Interests are removed from the PIT, the unfinished ones
+// have Err set to the error of ctx, which is also returned.
+// As ExpressInterestCtx, signed Interests are not supported.
+func (e *Engine) ExpressBatch(ctx context.Context, interests []ndn.Interest, window int) ([]ndn.BatchResult, error) {
+	if window <= 0 || window > len(interests) {
+		window = len(interests)
+	}
+	results := make([]ndn.BatchResult, len(interests))
+	cancels := make([]func() bool, len(interests))
+	// Callbacks never block, since every Interest reports at most once
+	finished := make(chan int, len(interests))
+
+	abort := func(next int) ([]ndn.BatchResult, error) {
+		err := ctx.Err()
+		for i := range interests {
+			// The callback has returned if the Interest is not pending anymore
+			if i >= next || (cancels[i] != nil && cancels[i]()) {
+				results[i] = ndn.BatchResult{Err: err}
+			}
+		}
+		return results, err
+	}
+
+	next, pending := 0, 0
+	for next < len(interests) || pending > 0 {
+		if ctx.Err() != nil {
+			return abort(next)
+		}
+		if next < len(interests) && pending < window {
+			i := next
+			next++
+			config, wire, finalName, err := e.prepareInterest(interests[i])
+			if err != nil {
+				results[i].Err = err
+				continue
+			}
+			cancel, err := e.express(finalName, config, wire,
+				func(res ndn.InterestResult, data ndn.Data, _, _ enc.Wire, nackReason uint64) {
+					results[i] = ndn.BatchResult{Result: res, Data: data, NackReason: nackReason}
+					finished <- i
+				})
+			cancels[i] = cancel
+			if err != nil {
+				if cancel == nil || cancel() {
+					results[i].Err = err
+					continue
+				}
+			}
+			pending++
+			continue
+		}
+		select {
+		case <-finished:
+			pending--
+		case <-ctx.Done():
+		}
+	}
+	return results, nil
+}
+
+func (e *Engine) sendInterest(wire enc.Wire) error {
+	err := e.face.Send(wire)
+	if err == nil {
+		e.counters.interestsSent.Add(1)
+		e.counters.bytesOut.Add(wire.Length())
+	}
 	return err
 }
 
-func (e *Engine) RegisterRoute(prefix enc.Name) error {
+// sendReply sends the Data replying an incoming Interest, wrapped in a LpPacket if pitToken is given.
+func (e *Engine) sendReply(encodedData enc.Wire, pitToken []byte) error {
+	if pitToken == nil {
+		return e.sendData(encodedData)
+	}
+	lpPkt := &spec.Packet{
+		LpPacket: &spec.LpPacket{
+			PitToken: pitToken,
+			Fragment: encodedData,
+		},
+	}
+	encoder := spec.PacketEncoder{}
+	encoder.Init(lpPkt)
+	wire := encoder.Encode(lpPkt)
+	if wire == nil {
+		return ndn.ErrFailedToEncode
+	}
+	return e.sendData(wire)
+}
+
+// cacheData inserts the Data replied at now into the content store.
+func (e *Engine) cacheData(encodedData enc.Wire, now time.Time) {
+	data, _, err := spec.Spec{}.ReadData(enc.NewWireReader(encodedData))
+	if err != nil {
+		e.log.Warnf("Unable to parse replied Data for caching: %+v", err)
+		return
+	}
+	freshUntil := now
+	if freshness := data.Freshness(); freshness != nil {
+		freshUntil = now.Add(*freshness)
+	}
+	e.cs.Insert(data.Name(), encodedData, freshUntil)
+}
+
+func (e *Engine) sendData(wire enc.Wire) error {
+	err := e.face.Send(wire)
+	if err == nil {
+		e.counters.dataSent.Add(1)
+		e.counters.bytesOut.Add(wire.Length())
+	}
+	return err
+}
+
+// typeNonce is the TLV-TYPE of the Nonce of an Interest.
+const typeNonce = enc.TLNum(0x0a)
+
+// renewNonce returns a copy of the encoded Interest with its Nonce replaced by the lower 4 bytes of nonce,
+// the same as MakeInterest does.
+// The Nonce is not covered by the signature, so signed Interests stay valid.
+// An Interest without Nonce is returned as it is.
+func renewNonce(rawInterest enc.Wire, nonce uint64) (enc.Wire, error) {
+	nonceBuf, err := enc.NatFixed(nonce&math.MaxUint32, 4).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	// Join returns the only buffer as is, which is still referred to by the caller
+	buf := make(enc.Buffer, 0, rawInterest.Length())
+	for _, b := range rawInterest {
+		buf = append(buf, b...)
+	}
+	r := enc.NewBufferReader(buf)
+	typ, err := enc.ReadTLNum(r)
+	if err != nil {
+		return nil, err
+	}
+	if typ != spec.TypeInterest {
+		return nil, ndn.ErrWrongType
+	}
+	l, err := enc.ReadTLNum(r)
+	if err != nil {
+		return nil, err
+	}
+	end := r.Pos() + int(l)
+	for r.Pos() < end {
+		typ, err = enc.ReadTLNum(r)
+		if err != nil {
+			return nil, err
+		}
+		l, err = enc.ReadTLNum(r)
+		if err != nil {
+			return nil, err
+		}
+		if typ == typeNonce && l == 4 {
+			copy(buf[r.Pos():], nonceBuf)
+			return enc.Wire{buf}, nil
+		}
+		if err = r.Skip(int(l)); err != nil {
+			return nil, err
+		}
+	}
+	// No Nonce to renew
+	return rawInterest, nil
+}
+
+// ExpressSignedInterest makes a signed Interest and expresses it.
+// signer should give SignatureNonce, SignatureTime and SignatureSeqNum, e.g. an Interest signer from pkg/security.
+// If signer is nil, the key chain is asked for a signer of the Interest name.
+// The ParametersSha256Digest component is appended to the name automatically.
+func (e *Engine) ExpressSignedInterest(
+	name enc.Name, config *ndn.InterestConfig, appParam enc.Wire, signer ndn.Signer,
+	callback ndn.ExpressCallbackFunc,
+) error {
+	if signer == nil {
+		signer = e.SignerForName(name)
+		if signer == nil {
+			return ndn.ErrInvalidValue{Item: "signer", Value: nil}
+		}
+	}
+	if appParam == nil {
+		// Signed Interests always carry ApplicationParameters, which can be empty
+		appParam = enc.Wire{}
+	}
+	config = e.boundedConfig(config)
+	wire, _, finalName, err := e.Spec().MakeInterest(name, config, appParam, signer)
+	if err != nil {
+		return err
+	}
+	return e.Express(finalName, config, wire, callback)
+}
+
+// execMgmtCmd sends a command to the forwarder, and waits for the response.
+// A response with a status code other than 200 is returned as mgmt.ErrControlResponse,
+// together with the response, since some errors carry parameters too.
+func (e *Engine) execMgmtCmd(module string, cmd string, args *mgmt.ControlArgs) (*mgmt.ControlResponseVal, error) {
 	intCfg := &ndn.InterestConfig{
 		Lifetime: utils.IdPtr(1 * time.Second),
 		Nonce:    utils.ConvertNonce(e.timer.Nonce()),
 	}
-	name, cmdWire, err := e.mgmtConf.MakeCmd("rib", "register", &mgmt.ControlArgs{Name: prefix}, intCfg)
+	e.cmdLock.Lock()
+	name, cmdWire, err := e.mgmtConf.MakeCmd(module, cmd, args, intCfg)
 	if err != nil {
-		e.log.WithField("name", prefix.String()).Errorf("Failed to generate command Interest: %v", err)
-		return err
+		e.cmdLock.Unlock()
+		return nil, fmt.Errorf("failed to generate command Interest: %w", err)
+	}
+	type result struct {
+		val *mgmt.ControlResponseVal
+		err error
 	}
-	ch := make(chan error)
-	err = e.Express(name, intCfg, cmdWire,
-		func(result ndn.InterestResult, data ndn.Data, rawData enc.Wire, sigCovered enc.Wire, nackReason uint64) {
-			if result == ndn.InterestResultNack {
-				ch <- fmt.Errorf("nack received: %v", nackReason)
-			} else if result == ndn.InterestResultTimeout {
-				ch <- ndn.ErrDeadlineExceed
-			} else if result == ndn.InterestResultData {
-				valid := e.cmdChecker(data.Name(), sigCovered, data.Signature())
-				if !valid {
-					ch <- fmt.Errorf("command signature is not valid")
+	ch := make(chan result, 1)
+	// Commands are sent during ShutdownContext to unregister the prefixes
+	_, err = e.pend(name, intCfg, cmdWire,
+		func(res ndn.InterestResult, data ndn.Data, rawData enc.Wire, sigCovered enc.Wire, nackReason uint64) {
+			switch res {
+			case ndn.InterestResultNack:
+				ch <- result{err: fmt.Errorf("nack received: %v", nackReason)}
+			case ndn.InterestResultTimeout:
+				ch <- result{err: ndn.ErrDeadlineExceed}
+			case ndn.InterestResultData:
+				if !e.cmdChecker(data.Name(), sigCovered, data.Signature()) {
+					ch <- result{err: fmt.Errorf("command signature is not valid")}
+					return
+				}
+				ret, err := mgmt.ParseControlResponse(enc.NewWireReader(data.Content()), true)
+				if err != nil {
+					ch <- result{err: err}
+				} else if ret.Val == nil {
+					ch <- result{err: fmt.Errorf("improper response")}
+				} else if ret.Val.StatusCode != 200 {
+					ch <- result{val: ret.Val, err: mgmt.ErrControlResponse{
+						StatusCode: ret.Val.StatusCode,
+						StatusText: ret.Val.StatusText,
+					}}
 				} else {
-					ret, err := mgmt.ParseControlResponse(enc.NewWireReader(data.Content()), true)
-					if err != nil {
-						ch <- err
-					} else {
-						if ret.Val != nil {
-							if ret.Val.StatusCode == 200 {
-								ch <- nil
-							} else {
-								errText := ret.Val.StatusText
-								ch <- fmt.Errorf("registration failed due to error %d: %s", ret.Val.StatusCode, errText)
-							}
-						} else {
-							ch <- fmt.Errorf("improper response")
-						}
-					}
+					ch <- result{val: ret.Val}
 				}
-			} else {
-				ch <- fmt.Errorf("unknown result: %v", result)
+			default:
+				ch <- result{err: fmt.Errorf("unknown result: %v", res)}
 			}
-			close(ch)
 		})
+	e.cmdLock.Unlock()
 	if err != nil {
-		e.log.WithField("name", prefix.String()).Errorf("Failed to express command Interest: %v", err)
-		return err
+		return nil, fmt.Errorf("failed to express command Interest: %w", err)
+	}
+	ret := <-ch
+	return ret.val, ret.err
+}
+
+func routeArgs(prefix enc.Name, opts ndn.RouteOptions) *mgmt.ControlArgs {
+	args := &mgmt.ControlArgs{
+		Name:   prefix,
+		FaceId: opts.FaceId,
+		Origin: opts.Origin,
+		Cost:   opts.Cost,
+		Flags:  opts.Flags,
 	}
-	err = <-ch
+	if opts.ExpirationPeriod != nil {
+		args.ExpirationPeriod = utils.IdPtr(uint64(opts.ExpirationPeriod.Milliseconds()))
+	}
+	return args
+}
+
+func (e *Engine) RegisterRoute(prefix enc.Name, opts ndn.RouteOptions) error {
+	_, err := e.execMgmtCmd("rib", "register", routeArgs(prefix, opts))
 	if err != nil {
 		e.log.WithField("name", prefix.String()).Errorf("Failed to register prefix: %v", err)
 		return err
-	} else {
-		e.log.WithField("name", prefix.String()).Info("Prefix registered.")
 	}
+	e.log.WithField("name", prefix.String()).Info("Prefix registered.")
+	e.addRoute(prefix, opts)
 	return nil
 }
 
-func (e *Engine) UnregisterRoute(prefix enc.Name) error {
-	intCfg := &ndn.InterestConfig{
-		Lifetime: utils.IdPtr(1 * time.Second),
-		Nonce:    utils.ConvertNonce(e.timer.Nonce()),
+// RegisterRoutes registers the routes of prefixes with the same opts, and waits for all responses.
+// A prefix under another one in prefixes is covered by its route, so only the shortest ones are registered,
+// with at most routeCmdParallelism commands at the same time.
+// Prefixes are not merged into a common prefix that is not given, as it may cover other applications.
+// If any fails, the error is an ndn.ErrRoutes giving the error of each prefix.
+func (e *Engine) RegisterRoutes(prefixes []enc.Name, opts ndn.RouteOptions) error {
+	// cover[i] is the index of the prefix whose route covers prefixes[i].
+	// Shorter prefixes go into the trie first, so a prefix finds the shortest one covering it.
+	order := make([]int, len(prefixes))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return len(prefixes[a]) - len(prefixes[b])
+	})
+	cover := make([]int, len(prefixes))
+	trie := NewNameTrie[int]()
+	roots := make([]int, 0)
+	for _, i := range order {
+		node := trie.FirstSatisfyOrNew(prefixes[i], func(v int) bool { return v > 0 })
+		if node.Value() > 0 {
+			cover[i] = node.Value() - 1
+		} else {
+			node.SetValue(i + 1)
+			cover[i] = i
+			roots = append(roots, i)
+		}
+	}
+
+	errs := make([]error, len(prefixes))
+	sem := make(chan struct{}, routeCmdParallelism)
+	wg := sync.WaitGroup{}
+	for _, i := range roots {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = e.RegisterRoute(prefixes[i], opts)
+			<-sem
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	for i := range prefixes {
+		errs[i] = errs[cover[i]]
+		if errs[i] != nil {
+			failed++
+		}
+	}
+	e.log.Infof("Registered %d of %d prefixes with %d commands.", len(prefixes)-failed, len(prefixes), len(roots))
+	if failed > 0 {
+		return ndn.ErrRoutes{Errors: errs}
+	}
+	return nil
+}
+
+func (e *Engine) UnregisterRoute(prefix enc.Name, opts ndn.RouteOptions) error {
+	e.removeRoute(prefix)
+	args := &mgmt.ControlArgs{
+		Name:   prefix,
+		FaceId: opts.FaceId,
+		Origin: opts.Origin,
 	}
-	name, cmdWire, err := e.mgmtConf.MakeCmd("rib", "register", &mgmt.ControlArgs{Name: prefix}, intCfg)
+	_, err := e.execMgmtCmd("rib", "unregister", args)
 	if err != nil {
-		e.log.WithField("name", prefix.String()).Errorf("Failed to generate command Interest: %v", err)
+		e.log.WithField("name", prefix.String()).Errorf("Failed to unregister prefix: %v", err)
 		return err
 	}
-	err = e.Express(name, intCfg, cmdWire, nil)
+	e.log.WithField("name", prefix.String()).Info("Prefix unregistered.")
+	return nil
+}
+
+// CreateFace asks the forwarder to create a face to uri, e.g. "udp4://192.0.2.1:6363", and returns its FaceId.
+// If the face already exists, the FaceId of the existing face is returned.
+func (e *Engine) CreateFace(uri string) (uint64, error) {
+	ret, err := e.execMgmtCmd("faces", "create", &mgmt.ControlArgs{Uri: &uri})
+	var respErr mgmt.ErrControlResponse
+	if errors.As(err, &respErr) && respErr.StatusCode == 409 && ret.Params != nil && ret.Params.FaceId != nil {
+		e.log.Infof("Face to %s already exists: %d", uri, *ret.Params.FaceId)
+		return *ret.Params.FaceId, nil
+	}
 	if err != nil {
-		e.log.WithField("name", prefix.String()).Errorf("Failed to express command Interest: %v", err)
+		e.log.Errorf("Failed to create face to %s: %v", uri, err)
+		return 0, err
+	}
+	if ret.Params == nil || ret.Params.FaceId == nil {
+		return 0, fmt.Errorf("improper response")
+	}
+	e.log.Infof("Face to %s created: %d", uri, *ret.Params.FaceId)
+	return *ret.Params.FaceId, nil
+}
+
+// DestroyFace asks the forwarder to destroy the face of faceId.
+func (e *Engine) DestroyFace(faceId uint64) error {
+	_, err := e.execMgmtCmd("faces", "destroy", &mgmt.ControlArgs{FaceId: &faceId})
+	if err != nil {
+		e.log.Errorf("Failed to destroy face %d: %v", faceId, err)
 		return err
-	} else {
-		e.log.WithField("name", prefix.String()).Info("Prefix unregistered.")
 	}
+	e.log.Infof("Face %d destroyed.", faceId)
 	return nil
 }
 
-func NewEngine(face Face, timer ndn.Timer, cmdSigner ndn.Signer, cmdChecker ndn.SigChecker) *Engine {
+// Option is an optional setting of an Engine given to NewEngine.
+type Option func(*Engine)
+
+// WithValidationWorkers makes the engine run validators on n workers instead of the thread reading the face,
+// so slow signature verification does not stall packet processing.
+// Packets of the same name are still delivered in the order they are received.
+func WithValidationWorkers(n int) Option {
+	return func(e *Engine) {
+		e.validationWorkers = max(n, 0)
+	}
+}
+
+// WithDeadNonceLifetime sets how long an incoming Interest is remembered, so the same name and Nonce
+// received again meanwhile is dropped as a loop. The default is DefaultDeadNonceLifetime; 0 disables the check.
+func WithDeadNonceLifetime(lifetime time.Duration) Option {
+	return func(e *Engine) {
+		e.deadNonceLifetime = max(lifetime, 0)
+	}
+}
+
+func NewEngine(
+	face Face, timer ndn.Timer, cmdSigner ndn.Signer, cmdChecker ndn.SigChecker, opts ...Option,
+) *Engine {
 	if face == nil || timer == nil || cmdSigner == nil || cmdChecker == nil {
 		return nil
 	}
 	logger := log.WithField("module", "basic_engine")
 	mgmtCfg := mgmt.NewConfig(face.IsLocal(), cmdSigner, spec.Spec{})
-	return &Engine{
+	e := &Engine{
 		face:       face,
 		timer:      timer,
 		mgmtConf:   mgmtCfg,
@@ -516,7 +1501,19 @@ func NewEngine(face Face, timer ndn.Timer, cmdSigner ndn.Signer, cmdChecker ndn.
 		log:        logger,
 		fib:        NewNameTrie[fibEntry](),
 		pit:        NewNameTrie[pitEntry](),
+		validators: NewNameTrie[ndn.Validator](),
 		fibLock:    sync.Mutex{},
 		pitLock:    sync.Mutex{},
+
+		congestionListeners: make(map[int]func(enc.Name, uint64)),
+		deadNonceLifetime:   DefaultDeadNonceLifetime,
+		defaultLifetime:     DefaultInterestLife,
+	}
+	for _, opt := range opts {
+		opt(e)
 	}
+	if e.deadNonceLifetime > 0 {
+		e.deadNonces = newDeadNonceList(e.deadNonceLifetime)
+	}
+	return e
 }
@@ -1,6 +1,14 @@
 package basic_test
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -112,6 +120,46 @@ func TestInterestNack(t *testing.T) {
 	})
 }
 
+func TestInterestNackNoReason(t *testing.T) {
+	executeTest(t, func(face *dummy.DummyFace, engine *basic_engine.Engine, timer *dummy.Timer, signer ndn.Signer) {
+		hitCnt := 0
+		intHitCnt := 0
+
+		spec := engine.Spec()
+		name := utils.WithoutErr(enc.NameFromStr("/test/nack"))
+		config := &ndn.InterestConfig{
+			Lifetime: utils.IdPtr(1 * time.Second),
+		}
+		// A Nacked Interest must not be taken as an incoming Interest
+		engine.AttachHandler(name, func(ndn.Interest, enc.Wire, enc.Wire, ndn.ReplyFunc, time.Time) {
+			intHitCnt += 1
+		})
+		wire, _, finalName, err := spec.MakeInterest(name, config, nil, nil)
+		require.NoError(t, err)
+		err = engine.Express(finalName, config, wire,
+			func(result ndn.InterestResult, _ ndn.Data, _ enc.Wire, _ enc.Wire, nackReason uint64) {
+				hitCnt += 1
+				require.Equal(t, ndn.InterestResultNack, result)
+				require.Equal(t, spec_2022.NackReasonNone, nackReason)
+			})
+		require.NoError(t, err)
+		utils.WithoutErr(face.Consume())
+
+		lpPkt := &spec_2022.Packet{
+			LpPacket: &spec_2022.LpPacket{
+				Nack:     &spec_2022.NetworkNack{},
+				Fragment: wire,
+			},
+		}
+		encoder := spec_2022.PacketEncoder{}
+		encoder.Init(lpPkt)
+		require.NoError(t, face.FeedPacket(encoder.Encode(lpPkt).Join()))
+
+		require.Equal(t, 1, hitCnt)
+		require.Equal(t, 0, intHitCnt)
+	})
+}
+
 func TestInterestTimeout(t *testing.T) {
 	executeTest(t, func(face *dummy.DummyFace, engine *basic_engine.Engine, timer *dummy.Timer, signer ndn.Signer) {
 		hitCnt := 0
@@ -139,6 +187,153 @@ func TestInterestTimeout(t *testing.T) {
 	})
 }
 
+func TestInterestLifetimeBounds(t *testing.T) {
+	executeTest(t, func(face *dummy.DummyFace, engine *basic_engine.Engine, timer *dummy.Timer, signer ndn.Signer) {
+		spec := engine.Spec()
+		require.Equal(t, basic_engine.DefaultInterestLife, engine.DefaultInterestLifetime())
+		require.Equal(t, time.Duration(0), engine.MaxInterestLifetime())
+		engine.SetDefaultInterestLifetime(10 * time.Second)
+		engine.SetMaxInterestLifetime(30 * time.Second)
+
+		timedOut := map[string]bool{}
+		express := func(name string, lifetime *time.Duration) {
+			config := &ndn.InterestConfig{Lifetime: lifetime}
+			wire, _, finalName, err := spec.MakeInterest(utils.WithoutErr(enc.NameFromStr(name)), config, nil, nil)
+			require.NoError(t, err)
+			require.NoError(t, engine.Express(finalName, config, wire,
+				func(result ndn.InterestResult, _ ndn.Data, _ enc.Wire, _ enc.Wire, _ uint64) {
+					require.Equal(t, ndn.InterestResultTimeout, result)
+					timedOut[name] = true
+				}))
+			utils.WithoutErr(face.Consume())
+		}
+
+		// An omitted lifetime gets the default, and an oversized one is clamped
+		express("/omitted", nil)
+		express("/oversized", utils.IdPtr(time.Hour))
+		timer.MoveForward(9 * time.Second)
+		require.Empty(t, timedOut)
+		timer.MoveForward(2 * time.Second)
+		require.Equal(t, map[string]bool{"/omitted": true}, timedOut)
+		timer.MoveForward(20 * time.Second)
+		require.Equal(t, map[string]bool{"/omitted": true, "/oversized": true}, timedOut)
+		require.Empty(t, engine.PendingInterests())
+
+		// Interests encoded by the engine carry the bounded lifetime, without changing the config of the caller
+		config := &ndn.InterestConfig{Lifetime: utils.IdPtr(time.Hour)}
+		require.NoError(t, engine.ExpressSignedInterest(utils.WithoutErr(enc.NameFromStr("/signed")), config,
+			nil, signer, func(ndn.InterestResult, ndn.Data, enc.Wire, enc.Wire, uint64) {}))
+		pkt, _, err := spec_2022.ReadPacket(enc.NewBufferReader(utils.WithoutErr(face.Consume())))
+		require.NoError(t, err)
+		require.Equal(t, 30*time.Second, *pkt.Interest.Lifetime())
+		require.Equal(t, time.Hour, *config.Lifetime)
+		timer.MoveForward(31 * time.Second)
+
+		// Received Interests are bounded the same way
+		var deadlines []time.Duration
+		prefix := utils.WithoutErr(enc.NameFromStr("/test"))
+		require.NoError(t, engine.AttachHandler(prefix, func(
+			interest ndn.Interest, rawInterest enc.Wire, sigCovered enc.Wire, reply ndn.ReplyFunc, deadline time.Time,
+		) {
+			deadlines = append(deadlines, deadline.Sub(timer.Now()))
+		}))
+		for i, lifetime := range []*time.Duration{nil, utils.IdPtr(time.Hour), utils.IdPtr(time.Second)} {
+			name := utils.WithoutErr(enc.NameFromStr(fmt.Sprintf("/test/%d", i)))
+			wire, _, _, err := spec.MakeInterest(name, &ndn.InterestConfig{Lifetime: lifetime}, nil, nil)
+			require.NoError(t, err)
+			require.NoError(t, face.FeedPacket(wire.Join()))
+		}
+		require.Equal(t, []time.Duration{10 * time.Second, 30 * time.Second, time.Second}, deadlines)
+
+		// Non-positive values restore the defaults
+		engine.SetDefaultInterestLifetime(0)
+		engine.SetMaxInterestLifetime(-1)
+		require.Equal(t, basic_engine.DefaultInterestLife, engine.DefaultInterestLifetime())
+		require.Equal(t, time.Duration(0), engine.MaxInterestLifetime())
+	})
+}
+
+func TestInterestRetransmission(t *testing.T) {
+	executeTest(t, func(face *dummy.DummyFace, engine *basic_engine.Engine, timer *dummy.Timer, signer ndn.Signer) {
+		hitCnt := 0
+
+		spec := engine.Spec()
+		name := utils.WithoutErr(enc.NameFromStr("/not/important"))
+		config := &ndn.InterestConfig{
+			Lifetime: utils.IdPtr(4 * time.Second),
+			Nonce:    utils.IdPtr[uint64](0),
+			Timeout:  utils.IdPtr(100 * time.Millisecond),
+			Retries:  2,
+		}
+		wire, _, finalName, err := spec.MakeInterest(name, config, nil, nil)
+		require.NoError(t, err)
+		// A single buffer makes sure the retransmissions do not write into the wire given by the caller
+		wire = enc.Wire{wire.Join()}
+		original := append(enc.Buffer(nil), wire[0]...)
+		err = engine.Express(finalName, config, wire,
+			func(result ndn.InterestResult, _ ndn.Data, _ enc.Wire, _ enc.Wire, _ uint64) {
+				hitCnt += 1
+				require.Equal(t, ndn.InterestResultTimeout, result)
+			})
+		require.NoError(t, err)
+		require.Equal(t, enc.Buffer(
+			"\x05\x1c\x07\x10\x08\x03not\x08\timportant\x0a\x04\x00\x00\x00\x00\x0c\x02\x0f\xa0",
+		), utils.WithoutErr(face.Consume()))
+
+		// Each retransmission has a new Nonce and waits twice as long
+		timer.MoveForward(150 * time.Millisecond)
+		require.Equal(t, enc.Buffer(
+			"\x05\x1c\x07\x10\x08\x03not\x08\timportant\x0a\x04\x05\x06\x07\x08\x0c\x02\x0f\xa0",
+		), utils.WithoutErr(face.Consume()))
+		timer.MoveForward(150 * time.Millisecond)
+		utils.WithErr(face.Consume())
+		timer.MoveForward(100 * time.Millisecond)
+		utils.WithoutErr(face.Consume())
+		require.Equal(t, 0, hitCnt)
+		require.Equal(t, original, wire[0])
+
+		// No more retransmission after Retries
+		timer.MoveForward(500 * time.Millisecond)
+		utils.WithErr(face.Consume())
+		require.Equal(t, 1, hitCnt)
+	})
+}
+
+func TestInterestRetransmissionSatisfied(t *testing.T) {
+	executeTest(t, func(face *dummy.DummyFace, engine *basic_engine.Engine, timer *dummy.Timer, signer ndn.Signer) {
+		hitCnt := 0
+
+		spec := engine.Spec()
+		name := utils.WithoutErr(enc.NameFromStr("/not/important"))
+		config := &ndn.InterestConfig{
+			Lifetime: utils.IdPtr(4 * time.Second),
+			Timeout:  utils.IdPtr(100 * time.Millisecond),
+			Retries:  3,
+		}
+		wire, _, finalName, err := spec.MakeInterest(name, config, nil, nil)
+		require.NoError(t, err)
+		err = engine.Express(finalName, config, wire,
+			func(result ndn.InterestResult, data ndn.Data, _ enc.Wire, _ enc.Wire, _ uint64) {
+				hitCnt += 1
+				require.Equal(t, ndn.InterestResultData, result)
+				require.Equal(t, []byte("test"), data.Content().Join())
+			})
+		require.NoError(t, err)
+		utils.WithoutErr(face.Consume())
+		timer.MoveForward(150 * time.Millisecond)
+		utils.WithoutErr(face.Consume())
+
+		// The Data of a retransmission satisfies the Interest, and stops further retransmissions
+		require.NoError(t, face.FeedPacket(enc.Buffer(
+			"\x06\x1d\x07\x10\x08\x03not\x08\timportant\x14\x03\x18\x01\x00\x15\x04test",
+		)))
+		require.Equal(t, 1, hitCnt)
+		timer.MoveForward(time.Second)
+		utils.WithErr(face.Consume())
+		require.Equal(t, 1, hitCnt)
+	})
+}
+
 func TestInterestCanBePrefix(t *testing.T) {
 	executeTest(t, func(face *dummy.DummyFace, engine *basic_engine.Engine, timer *dummy.Timer, signer ndn.Signer) {
 		hitCnt := 0
@@ -262,6 +457,74 @@ func TestImplicitSha256(t *testing.T) {
 	})
 }
 
+func TestProduceFullName(t *testing.T) {
+	executeTest(t, func(face *dummy.DummyFace, engine *basic_engine.Engine, timer *dummy.Timer, signer ndn.Signer) {
+		hitCnt := 0
+		spec := engine.Spec()
+		engine.SetContentStore(basic_engine.NewLruContentStore(10, 0))
+
+		name := utils.WithoutErr(enc.NameFromStr("/test/data"))
+		dataWire, _, err := spec.MakeData(name, &ndn.DataConfig{
+			ContentType: utils.IdPtr(ndn.ContentTypeBlob),
+		}, enc.Wire{[]byte("test")}, sec.NewSha256Signer())
+		require.NoError(t, err)
+		data, _, err := spec.ReadData(enc.NewWireReader(dataWire))
+		require.NoError(t, err)
+		fullName := data.FullName()
+		require.Equal(t, 3, len(fullName))
+		digest := sha256.Sum256(dataWire.Join())
+		require.Equal(t, digest[:], fullName[2].Val)
+
+		var replyErr error
+		engine.AttachHandler(utils.WithoutErr(enc.NameFromStr("/test")), func(
+			interest ndn.Interest, rawInterest enc.Wire, sigCovered enc.Wire, reply ndn.ReplyFunc, deadline time.Time,
+		) {
+			hitCnt += 1
+			replyErr = reply(dataWire)
+		})
+		interest := func(name enc.Name) []byte {
+			config := &ndn.InterestConfig{Lifetime: utils.IdPtr(time.Second)}
+			wire, _, _, err := spec.MakeInterest(name, config, nil, nil)
+			require.NoError(t, err)
+			return wire.Join()
+		}
+
+		// A Data with a different digest is not sent
+		wrongName := append(name, enc.Component{
+			Typ: enc.TypeImplicitSha256DigestComponent,
+			Val: make([]byte, 32),
+		})
+		require.NoError(t, face.FeedPacket(interest(wrongName)))
+		require.Equal(t, 1, hitCnt)
+		require.Equal(t, enc.ErrIncorrectDigest, replyErr)
+		utils.WithErr(face.Consume())
+
+		require.NoError(t, face.FeedPacket(interest(fullName)))
+		require.Equal(t, 2, hitCnt)
+		require.NoError(t, replyErr)
+		require.Equal(t, dataWire.Join(), []byte(utils.WithoutErr(face.Consume())))
+
+		// The cached Data is found by its full name
+		require.NoError(t, face.FeedPacket(interest(fullName)))
+		require.Equal(t, 2, hitCnt)
+		require.Equal(t, dataWire.Join(), []byte(utils.WithoutErr(face.Consume())))
+		require.NoError(t, face.FeedPacket(interest(wrongName)))
+		require.Equal(t, 3, hitCnt)
+		utils.WithErr(face.Consume())
+
+		// A full name with CanBePrefix is malformed
+		_, _, _, err = spec.MakeInterest(fullName, &ndn.InterestConfig{CanBePrefix: true}, nil, nil)
+		require.Error(t, err)
+		buf := interest(fullName)
+		nameEnd := 4 + fullName.EncodingLength() // The TLs of Interest and Name take 2 bytes each
+		buf = append(buf[:nameEnd:nameEnd], append([]byte{0x21, 0x00}, buf[nameEnd:]...)...)
+		buf[1] += 2
+		require.NoError(t, face.FeedPacket(buf))
+		require.Equal(t, 3, hitCnt)
+		utils.WithErr(face.Consume())
+	})
+}
+
 // No need to test AppParam for expression. If `spec.MakeInterest` works, `engine.Express` will.
 
 func TestRoute(t *testing.T) {
@@ -334,3 +597,923 @@ func TestPitToken(t *testing.T) {
 		), buf)
 	})
 }
+
+func TestSignedInterest(t *testing.T) {
+	executeTest(t, func(face *dummy.DummyFace, engine *basic_engine.Engine, timer *dummy.Timer, _ ndn.Signer) {
+		spec := engine.Spec()
+		keyName := utils.WithoutErr(enc.NameFromStr("/test/KEY/1"))
+		key := utils.WithoutErr(ecdsa.GenerateKey(elliptic.P256(), rand.Reader))
+		signer := sec.NewInterestSigner(sec.NewEcdsaSigner(keyName, key), timer)
+		verifier := sec.NewEcdsaVerifier(&key.PublicKey)
+		replayChecker := sec.NewSigTimeChecker(timer, time.Minute)
+
+		name := utils.WithoutErr(enc.NameFromStr("/test/cmd"))
+		config := &ndn.InterestConfig{
+			Lifetime: utils.IdPtr(1 * time.Second),
+			Nonce:    utils.IdPtr[uint64](0),
+		}
+		for i := uint64(1); i <= 2; i++ {
+			err := engine.ExpressSignedInterest(name, config, enc.Wire{[]byte("param")}, signer,
+				func(ndn.InterestResult, ndn.Data, enc.Wire, enc.Wire, uint64) {})
+			require.NoError(t, err)
+			buf := utils.WithoutErr(face.Consume())
+			interest, sigCovered, err := spec.ReadInterest(enc.NewBufferReader(buf))
+			require.NoError(t, err)
+
+			require.Equal(t, 3, len(interest.Name()))
+			require.Equal(t, enc.TypeParametersSha256DigestComponent, interest.Name()[2].Typ)
+			sig := interest.Signature()
+			require.Equal(t, ndn.SignatureSha256WithEcdsa, sig.SigType())
+			require.True(t, keyName.Equal(sig.KeyName()))
+			require.Equal(t, timer.Nonce(), sig.SigNonce())
+			require.Equal(t, timer.Now().UnixMilli(), sig.SigTime().UnixMilli())
+			require.Equal(t, i, *sig.SigSeqNum())
+			require.True(t, verifier(interest.Name(), sigCovered, sig))
+			require.True(t, replayChecker(interest.Name(), sigCovered, sig))
+			// A replay of the same Interest is rejected
+			require.False(t, replayChecker(interest.Name(), sigCovered, sig))
+			timer.MoveForward(10 * time.Millisecond)
+		}
+
+		// The signer falls back to the key chain
+		require.Error(t, engine.ExpressSignedInterest(name, config, nil, nil, nil))
+		kc := sec.NewKeyChain()
+		kc.AddSigner(utils.WithoutErr(enc.NameFromStr("/test")), signer)
+		engine.SetKeyChain(kc)
+		require.NoError(t, engine.ExpressSignedInterest(name, config, nil, nil,
+			func(ndn.InterestResult, ndn.Data, enc.Wire, enc.Wire, uint64) {}))
+		buf := utils.WithoutErr(face.Consume())
+		interest, sigCovered, err := spec.ReadInterest(enc.NewBufferReader(buf))
+		require.NoError(t, err)
+		require.True(t, verifier(interest.Name(), sigCovered, interest.Signature()))
+	})
+}
+
+func TestStats(t *testing.T) {
+	executeTest(t, func(face *dummy.DummyFace, engine *basic_engine.Engine, timer *dummy.Timer, signer ndn.Signer) {
+		spec := engine.Spec()
+		reports := make([]basic_engine.EngineStats, 0)
+		cancel := engine.ReportStats(1*time.Second, func(stats basic_engine.EngineStats) {
+			reports = append(reports, stats)
+		})
+
+		// Consumer: one Interest satisfied, one timed out
+		name := utils.WithoutErr(enc.NameFromStr("/example/testApp/randomData/t=1570430517101"))
+		config := &ndn.InterestConfig{
+			MustBeFresh: true,
+			Lifetime:    utils.IdPtr(6 * time.Second),
+		}
+		wire, _, finalName, err := spec.MakeInterest(name, config, nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, engine.Express(finalName, config, wire, nil))
+		timeoutName := utils.WithoutErr(enc.NameFromStr("/not/answered"))
+		timeoutConfig := &ndn.InterestConfig{
+			Lifetime: utils.IdPtr(1500 * time.Millisecond),
+		}
+		wire, _, finalName, err = spec.MakeInterest(timeoutName, timeoutConfig, nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, engine.Express(finalName, timeoutConfig, wire, nil))
+		utils.WithoutErr(face.Consume())
+		utils.WithoutErr(face.Consume())
+
+		stats := engine.Stats()
+		require.Equal(t, uint64(2), stats.InterestsSent)
+		require.Equal(t, uint64(2), stats.PitSize)
+		require.Equal(t, uint64(50+wire.Length()), stats.BytesOut)
+
+		require.NoError(t, face.FeedPacket(enc.Buffer(
+			"\x06B\x07(\x08\x07example\x08\x07testApp\x08\nrandomData"+
+				"\x38\x08\x00\x00\x01m\xa4\xf3\xffm\x14\x07\x18\x01\x00\x19\x02\x03\xe8"+
+				"\x15\rHello, world!",
+		)))
+		stats = engine.Stats()
+		require.Equal(t, uint64(1), stats.DataReceived)
+		require.Equal(t, uint64(1), stats.InterestsSatisfied)
+		require.Equal(t, uint64(1), stats.PitSize)
+		require.Equal(t, uint64(68), stats.BytesIn)
+
+		timer.MoveForward(1100 * time.Millisecond)
+		require.Equal(t, 1, len(reports))
+		require.Equal(t, uint64(1), reports[0].PitSize)
+		timer.MoveForward(500 * time.Millisecond)
+		stats = engine.Stats()
+		require.Equal(t, uint64(1), stats.InterestsTimedOut)
+		require.Equal(t, uint64(0), stats.PitSize)
+
+		// Producer: one Interest received and replied
+		engine.AttachHandler(utils.WithoutErr(enc.NameFromStr("/not")), func(
+			interest ndn.Interest, rawInterest enc.Wire, sigCovered enc.Wire, reply ndn.ReplyFunc, deadline time.Time,
+		) {
+			data, _, err := spec.MakeData(
+				interest.Name(),
+				&ndn.DataConfig{
+					ContentType: utils.IdPtr(ndn.ContentTypeBlob),
+				},
+				enc.Wire{[]byte("test")},
+				sec.NewEmptySigner())
+			require.NoError(t, err)
+			reply(data)
+		})
+		require.NoError(t, face.FeedPacket([]byte("\x05\x15\x07\x10\x08\x03not\x08\timportant\x0c\x01\x05")))
+		utils.WithoutErr(face.Consume())
+		stats = engine.Stats()
+		require.Equal(t, uint64(1), stats.InterestsReceived)
+		require.Equal(t, uint64(1), stats.DataSent)
+		require.Equal(t, uint64(68+23), stats.BytesIn)
+		require.Equal(t, uint64(50+wire.Length()+36), stats.BytesOut)
+		require.Equal(t, uint64(2), stats.InterestsSent)
+
+		cancel()
+		timer.MoveForward(2 * time.Second)
+		require.Equal(t, 1, len(reports))
+	})
+}
+
+func TestPendingInterests(t *testing.T) {
+	executeTest(t, func(face *dummy.DummyFace, engine *basic_engine.Engine, timer *dummy.Timer, signer ndn.Signer) {
+		spec := engine.Spec()
+		require.Empty(t, engine.PendingInterests())
+
+		express := func(name string, config *ndn.InterestConfig) {
+			wire, _, finalName, err := spec.MakeInterest(utils.WithoutErr(enc.NameFromStr(name)), config, nil, nil)
+			require.NoError(t, err)
+			require.NoError(t, engine.Express(finalName, config, wire, nil))
+		}
+		express("/test/b", &ndn.InterestConfig{Lifetime: utils.IdPtr(4 * time.Second)})
+		express("/test/a", &ndn.InterestConfig{Lifetime: utils.IdPtr(2 * time.Second), MustBeFresh: true})
+		// Aggregated into the pending one
+		express("/test/b", &ndn.InterestConfig{Lifetime: utils.IdPtr(3 * time.Second)})
+
+		pending := engine.PendingInterests()
+		require.Equal(t, 2, len(pending))
+		require.Equal(t, "/test/a", pending[0].Name.String())
+		require.True(t, pending[0].MustBeFresh)
+		require.Equal(t, 2*time.Second, pending[0].Lifetime)
+		require.Equal(t, 1, pending[0].Waiters)
+		require.Equal(t, "/test/b", pending[1].Name.String())
+		require.False(t, pending[1].MustBeFresh)
+		require.Equal(t, 4*time.Second, pending[1].Lifetime)
+		require.Equal(t, 2, pending[1].Waiters)
+
+		timer.MoveForward(1500 * time.Millisecond)
+		pending = engine.PendingInterests()
+		require.Equal(t, 2, len(pending))
+		require.Equal(t, 500*time.Millisecond, pending[0].Lifetime)
+		require.Equal(t, 2500*time.Millisecond, pending[1].Lifetime)
+
+		// Timed out ones are gone
+		timer.MoveForward(1 * time.Second)
+		pending = engine.PendingInterests()
+		require.Equal(t, 1, len(pending))
+		require.Equal(t, "/test/b", pending[0].Name.String())
+		require.Equal(t, 1500*time.Millisecond, pending[0].Lifetime)
+	})
+}
+
+func TestDeadNonceList(t *testing.T) {
+	executeTest(t, func(face *dummy.DummyFace, engine *basic_engine.Engine, timer *dummy.Timer, signer ndn.Signer) {
+		spec := engine.Spec()
+		hitCnt := 0
+		prefix := utils.WithoutErr(enc.NameFromStr("/test"))
+		require.NoError(t, engine.AttachHandler(prefix, func(
+			interest ndn.Interest, rawInterest enc.Wire, sigCovered enc.Wire, reply ndn.ReplyFunc, deadline time.Time,
+		) {
+			hitCnt++
+		}))
+		interest := func(name string, nonce uint64) enc.Buffer {
+			wire, _, _, err := spec.MakeInterest(utils.WithoutErr(enc.NameFromStr(name)), &ndn.InterestConfig{
+				Lifetime: utils.IdPtr(time.Second),
+				Nonce:    utils.IdPtr(nonce),
+			}, nil, nil)
+			require.NoError(t, err)
+			return wire.Join()
+		}
+
+		// The same Interest coming back is dropped
+		require.NoError(t, face.FeedPacket(interest("/test/a", 1)))
+		require.NoError(t, face.FeedPacket(interest("/test/a", 1)))
+		require.Equal(t, 1, hitCnt)
+		stats := engine.Stats()
+		require.Equal(t, uint64(1), stats.InterestsLooped)
+		require.Equal(t, uint64(1), stats.DeadNonceListSize)
+
+		// Distinct Nonces of the same name, or the same Nonce of another name, are not duplicates
+		require.NoError(t, face.FeedPacket(interest("/test/a", 2)))
+		require.NoError(t, face.FeedPacket(interest("/test/b", 1)))
+		require.Equal(t, 3, hitCnt)
+		require.Equal(t, uint64(3), engine.Stats().DeadNonceListSize)
+
+		// Entries expire after the lifetime
+		timer.MoveForward(basic_engine.DefaultDeadNonceLifetime)
+		require.Equal(t, uint64(0), engine.Stats().DeadNonceListSize)
+		require.NoError(t, face.FeedPacket(interest("/test/a", 1)))
+		require.Equal(t, 4, hitCnt)
+		require.Equal(t, uint64(1), engine.Stats().InterestsLooped)
+	})
+}
+
+func TestNonceSource(t *testing.T) {
+	utils.SetTestingT(t)
+
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool {
+		return true
+	}
+	face := dummy.NewDummyFace()
+	timer := dummy.NewTimer()
+	engine := basic_engine.NewEngine(face, timer, sec.NewSha256IntSigner(timer), passAll)
+	engine.SetNonceSource(dummy.NewNonceSeq(0x1000))
+	require.NoError(t, engine.Start())
+	defer engine.Shutdown()
+
+	spec := engine.Spec()
+	express := func(name string, retries int) (*ndn.InterestConfig, error) {
+		config := &ndn.InterestConfig{
+			Lifetime: utils.IdPtr(1 * time.Second),
+			Nonce:    utils.ConvertNonce(engine.Timer().Nonce()),
+			Retries:  retries,
+		}
+		wire, _, finalName, err := spec.MakeInterest(utils.WithoutErr(enc.NameFromStr(name)), config, nil, nil)
+		require.NoError(t, err)
+		return config, engine.Express(finalName, config, wire, nil)
+	}
+	sentNonce := func() uint64 {
+		pkt, _, err := spec_2022.ReadPacket(enc.NewBufferReader(utils.WithoutErr(face.Consume())))
+		require.NoError(t, err)
+		require.NotNil(t, pkt.Interest)
+		return *pkt.Interest.Nonce()
+	}
+
+	// Interests get the configured Nonces
+	config, err := express("/test/a", 1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0x1000), sentNonce())
+	_, err = express("/test/b", 0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0x1001), sentNonce())
+
+	// The same name and Nonce is a duplicate
+	wire, _, finalName, err := spec.MakeInterest(utils.WithoutErr(enc.NameFromStr("/test/a")), config, nil, nil)
+	require.NoError(t, err)
+	require.ErrorIs(t, engine.Express(finalName, config, wire, nil), ndn.ErrDuplicateNonce)
+	_, err = face.Consume()
+	require.Error(t, err)
+	// The same Nonce with another name is not
+	wire, _, finalName, err = spec.MakeInterest(utils.WithoutErr(enc.NameFromStr("/test/c")), config, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, engine.Express(finalName, config, wire, nil))
+	require.Equal(t, uint64(0x1000), sentNonce())
+
+	// The engine keeps its own copy of the Nonce, so the caller may reuse its config
+	*config.Nonce = 0x2000
+	wire, _, finalName, err = spec.MakeInterest(utils.WithoutErr(enc.NameFromStr("/test/c")),
+		&ndn.InterestConfig{Nonce: utils.IdPtr(uint64(0x1000))}, nil, nil)
+	require.NoError(t, err)
+	require.ErrorIs(t, engine.Express(finalName, &ndn.InterestConfig{Nonce: utils.IdPtr(uint64(0x1000))}, wire, nil),
+		ndn.ErrDuplicateNonce)
+
+	// Retransmissions take the next Nonce
+	timer.MoveForward(1100 * time.Millisecond)
+	require.Equal(t, uint64(0x1002), sentNonce())
+}
+
+// executePeerTest runs an engine with real time, whose Interests are received by onInterest on the peer face.
+func executePeerTest(
+	t *testing.T, onInterest func(peer *dummy.PipeFace, interest *spec_2022.Interest), opts ...basic_engine.Option,
+) *basic_engine.Engine {
+	utils.SetTestingT(t)
+
+	consFace, peerFace := dummy.NewPipeFaces()
+	timer := basic_engine.NewTimer()
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool {
+		return true
+	}
+	engine := basic_engine.NewEngine(consFace, timer, sec.NewSha256IntSigner(timer), passAll, opts...)
+	peerFace.SetCallback(func(r enc.ParseReader) error {
+		pkt, _, err := spec_2022.ReadPacket(r)
+		if err != nil || pkt.Interest == nil {
+			return err
+		}
+		onInterest(peerFace, pkt.Interest)
+		return nil
+	}, func(error) error { return nil })
+	require.NoError(t, peerFace.Open())
+	require.NoError(t, engine.Start())
+	t.Cleanup(func() {
+		engine.Shutdown()
+	})
+	return engine
+}
+
+// peerReply replies interest with a Data containing its name, or a Nack with reason NoRoute.
+func peerReply(peer *dummy.PipeFace, interest *spec_2022.Interest, nack bool) {
+	spec := spec_2022.Spec{}
+	if !nack {
+		data, _, _ := spec.MakeData(interest.NameV, &ndn.DataConfig{},
+			enc.Wire{[]byte(interest.NameV.String())}, sec.NewSha256Signer())
+		peer.Send(data)
+		return
+	}
+	wire, _, _, _ := spec.MakeInterest(interest.NameV, &ndn.InterestConfig{Nonce: interest.Nonce()}, nil, nil)
+	lpPkt := &spec_2022.Packet{
+		LpPacket: &spec_2022.LpPacket{
+			Nack:     &spec_2022.NetworkNack{Reason: spec_2022.NackReasonNoRoute},
+			Fragment: wire,
+		},
+	}
+	encoder := spec_2022.PacketEncoder{}
+	encoder.Init(lpPkt)
+	peer.Send(encoder.Encode(lpPkt))
+}
+
+func makeTestInterest(t *testing.T, name string, lifetime time.Duration) ndn.Interest {
+	return ndn.NewInterest(utils.WithoutErr(enc.NameFromStr(name))).Lifetime(lifetime).Build()
+}
+
+func TestExpressInterestCtx(t *testing.T) {
+	// The peer replies Data to /test/data and Nack to /test/nack, and drops other Interests
+	engine := executePeerTest(t, func(peer *dummy.PipeFace, interest *spec_2022.Interest) {
+		switch interest.NameV.String() {
+		case "/test/data":
+			peerReply(peer, interest, false)
+		case "/test/nack":
+			peerReply(peer, interest, true)
+		}
+	})
+	interest := func(name string, lifetime time.Duration) ndn.Interest {
+		return makeTestInterest(t, name, lifetime)
+	}
+
+	// Data
+	data, err := engine.ExpressInterestCtx(context.Background(), interest("/test/data", time.Second))
+	require.NoError(t, err)
+	require.Equal(t, "/test/data", data.Name().String())
+	require.Equal(t, []byte("/test/data"), data.Content().Join())
+
+	// Nack
+	_, err = engine.ExpressInterestCtx(context.Background(), interest("/test/nack", time.Second))
+	var nack ndn.ErrNack
+	require.ErrorAs(t, err, &nack)
+	require.Equal(t, spec_2022.NackReasonNoRoute, nack.Reason)
+
+	// Timeout
+	_, err = engine.ExpressInterestCtx(context.Background(), interest("/test/timeout", 50*time.Millisecond))
+	require.ErrorIs(t, err, ndn.ErrDeadlineExceed)
+
+	// Cancellation removes the Interest from the PIT
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = engine.ExpressInterestCtx(ctx, interest("/test/cancel", 10*time.Second))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), 5*time.Second)
+	require.Equal(t, uint64(0), engine.Stats().PitSize)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = engine.ExpressInterestCtx(ctx, interest("/test/data", time.Second))
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, uint64(0), engine.Stats().PitSize)
+}
+
+func TestExpressBatch(t *testing.T) {
+	const window = 2
+	var engine *basic_engine.Engine
+	var held *spec_2022.Interest
+	maxPending := uint64(0)
+	// The peer replies Data in pairs, the later one first; replies Nack to /test/nack; and drops /test/drop
+	engine = executePeerTest(t, func(peer *dummy.PipeFace, interest *spec_2022.Interest) {
+		maxPending = max(maxPending, engine.Stats().PitSize)
+		switch name := interest.NameV.String(); {
+		case name == "/test/nack":
+			peerReply(peer, interest, true)
+		case strings.HasPrefix(name, "/test/drop"):
+		case held == nil:
+			held = interest
+		default:
+			peerReply(peer, interest, false)
+			peerReply(peer, held, false)
+			held = nil
+		}
+	})
+
+	names := []string{"/test/d0", "/test/d1", "/test/nack", "/test/d2", "/test/drop", "/test/d3", "/test/d4", "/test/d5"}
+	interests := make([]ndn.Interest, len(names))
+	for i, name := range names {
+		lifetime := time.Second
+		if name == "/test/drop" {
+			lifetime = 100 * time.Millisecond
+		}
+		interests[i] = makeTestInterest(t, name, lifetime)
+	}
+	results, err := engine.ExpressBatch(context.Background(), interests, window)
+	require.NoError(t, err)
+	require.Equal(t, len(names), len(results))
+	for i, name := range names {
+		switch name {
+		case "/test/nack":
+			require.Equal(t, ndn.InterestResultNack, results[i].Result)
+			require.Equal(t, spec_2022.NackReasonNoRoute, results[i].NackReason)
+		case "/test/drop":
+			require.Equal(t, ndn.InterestResultTimeout, results[i].Result)
+		default:
+			require.Equal(t, ndn.InterestResultData, results[i].Result)
+			require.Equal(t, name, results[i].Data.Name().String())
+		}
+		require.NoError(t, results[i].Err)
+	}
+	require.Equal(t, uint64(window), maxPending)
+	require.Equal(t, uint64(0), engine.Stats().PitSize)
+
+	// Cancellation aborts the whole batch
+	for i := range interests {
+		interests[i] = makeTestInterest(t, fmt.Sprintf("/test/drop/%d", i), 10*time.Second)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	results, err = engine.ExpressBatch(ctx, interests, window)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	for _, res := range results {
+		require.ErrorIs(t, res.Err, context.DeadlineExceeded)
+	}
+	require.Equal(t, uint64(0), engine.Stats().PitSize)
+}
+
+func TestInterestAggregation(t *testing.T) {
+	lock := sync.Mutex{}
+	received := make([]*spec_2022.Interest, 0)
+	var peer *dummy.PipeFace
+	engine := executePeerTest(t, func(face *dummy.PipeFace, interest *spec_2022.Interest) {
+		lock.Lock()
+		defer lock.Unlock()
+		peer = face
+		received = append(received, interest)
+	})
+	sent := func() int {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(received)
+	}
+
+	// The same Interest from three goroutines, with different Nonces
+	wg := sync.WaitGroup{}
+	results := make([]ndn.Data, 3)
+	errs := make([]error, 3)
+	for i := range results {
+		interest := makeTestInterest(t, "/test/agg", time.Second)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = engine.ExpressInterestCtx(context.Background(), interest)
+		}()
+	}
+	require.Eventually(t, func() bool {
+		return engine.Stats().PitSize == 3 && sent() > 0
+	}, time.Second, time.Millisecond)
+	require.Equal(t, uint64(2), engine.Stats().InterestsAggregated)
+
+	// Interests with other selectors, forwarding hints or hop limits are sent
+	name := utils.WithoutErr(enc.NameFromStr("/test/agg"))
+	for _, config := range []*ndn.InterestConfig{
+		{CanBePrefix: true, Lifetime: utils.IdPtr(time.Second)},
+		{MustBeFresh: true, Lifetime: utils.IdPtr(time.Second)},
+		{ForwardingHint: []enc.Name{utils.WithoutErr(enc.NameFromStr("/hint"))}, Lifetime: utils.IdPtr(time.Second)},
+		{HopLimit: utils.IdPtr(uint(8)), Lifetime: utils.IdPtr(time.Second)},
+	} {
+		config.Nonce = utils.ConvertNonce(engine.Timer().Nonce())
+		wire, _, finalName, err := engine.Spec().MakeInterest(name, config, nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, engine.Express(finalName, config, wire, nil))
+	}
+	require.Eventually(t, func() bool {
+		return sent() == 5
+	}, time.Second, time.Millisecond)
+	require.Equal(t, uint64(2), engine.Stats().InterestsAggregated)
+
+	// One Data satisfies all of them
+	lock.Lock()
+	peerReply(peer, received[0], false)
+	lock.Unlock()
+	wg.Wait()
+	for i := range results {
+		require.NoError(t, errs[i])
+		require.Equal(t, "/test/agg", results[i].Name().String())
+	}
+	require.Equal(t, uint64(0), engine.Stats().PitSize)
+	require.Equal(t, 5, sent())
+	require.Equal(t, uint64(5), engine.Stats().InterestsSent)
+}
+
+func TestForwardingHint(t *testing.T) {
+	executeTest(t, func(face *dummy.DummyFace, engine *basic_engine.Engine, timer *dummy.Timer, signer ndn.Signer) {
+		spec := engine.Spec()
+		hint := []enc.Name{
+			utils.WithoutErr(enc.NameFromStr("/ndn/edu/ucla")),
+			utils.WithoutErr(enc.NameFromStr("/ndn/mobile")),
+		}
+
+		// Outgoing Interests carry the hint, and the Data satisfies them as usual
+		hitCnt := 0
+		name := utils.WithoutErr(enc.NameFromStr("/app/data"))
+		config := &ndn.InterestConfig{
+			Lifetime:       utils.IdPtr(time.Second),
+			ForwardingHint: hint,
+		}
+		wire, _, finalName, err := spec.MakeInterest(name, config, nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, engine.Express(finalName, config, wire,
+			func(result ndn.InterestResult, _ ndn.Data, _ enc.Wire, _ enc.Wire, _ uint64) {
+				hitCnt += 1
+				require.Equal(t, ndn.InterestResultData, result)
+			}))
+		pkt, _, err := spec_2022.ReadPacket(enc.NewBufferReader(utils.WithoutErr(face.Consume())))
+		require.NoError(t, err)
+		require.Len(t, pkt.Interest.ForwardingHint(), 2)
+		require.True(t, hint[0].Equal(pkt.Interest.ForwardingHint()[0]))
+		require.True(t, hint[1].Equal(pkt.Interest.ForwardingHint()[1]))
+		data, _, err := spec.MakeData(name, &ndn.DataConfig{}, enc.Wire{[]byte("hi")}, sec.NewEmptySigner())
+		require.NoError(t, err)
+		require.NoError(t, face.FeedPacket(data.Join()))
+		require.Equal(t, 1, hitCnt)
+
+		// Incoming Interests give the hint to the handler
+		var received []enc.Name
+		require.NoError(t, engine.AttachHandler(utils.WithoutErr(enc.NameFromStr("/prod")), func(
+			interest ndn.Interest, _ enc.Wire, _ enc.Wire, _ ndn.ReplyFunc, _ time.Time,
+		) {
+			received = interest.ForwardingHint()
+		}))
+		wire, _, _, err = ndn.NewInterest(utils.WithoutErr(enc.NameFromStr("/prod/data"))).
+			ForwardingHint(hint).
+			Nonce(1).
+			Build().
+			Encode(spec, nil)
+		require.NoError(t, err)
+		require.NoError(t, face.FeedPacket(wire.Join()))
+		require.Len(t, received, 2)
+		require.True(t, hint[0].Equal(received[0]))
+		require.True(t, hint[1].Equal(received[1]))
+	})
+}
+
+// recordValidator records the names it validates, and accepts them if accept is set.
+type recordValidator struct {
+	accept bool
+	names  *[]string
+}
+
+func (v recordValidator) ValidateData(data ndn.Data, _ enc.Wire, _ ndn.Signature) bool {
+	*v.names = append(*v.names, data.Name().String())
+	return v.accept
+}
+
+func (v recordValidator) ValidateInterest(interest ndn.Interest, _ enc.Wire, _ ndn.Signature) bool {
+	*v.names = append(*v.names, interest.Name().String())
+	return v.accept
+}
+
+func TestSetValidator(t *testing.T) {
+	executeTest(t, func(face *dummy.DummyFace, engine *basic_engine.Engine, timer *dummy.Timer, signer ndn.Signer) {
+		spec := engine.Spec()
+		var byDefault, byApp, bySecure []string
+		engine.SetValidator(enc.Name{}, recordValidator{accept: false, names: &byDefault})
+		engine.SetValidator(utils.WithoutErr(enc.NameFromStr("/app")), recordValidator{accept: true, names: &byApp})
+		engine.SetValidator(utils.WithoutErr(enc.NameFromStr("/app/secure")),
+			recordValidator{accept: false, names: &bySecure})
+
+		results := map[string]ndn.InterestResult{}
+		express := func(nameStr string) {
+			name := utils.WithoutErr(enc.NameFromStr(nameStr))
+			config := &ndn.InterestConfig{Lifetime: utils.IdPtr(time.Second)}
+			wire, _, finalName, err := spec.MakeInterest(name, config, nil, nil)
+			require.NoError(t, err)
+			require.NoError(t, engine.Express(finalName, config, wire,
+				func(result ndn.InterestResult, _ ndn.Data, _ enc.Wire, _ enc.Wire, _ uint64) {
+					results[nameStr] = result
+				}))
+			data, _, err := spec.MakeData(name, &ndn.DataConfig{}, enc.Wire{[]byte("hi")}, sec.NewEmptySigner())
+			require.NoError(t, err)
+			require.NoError(t, face.FeedPacket(data.Join()))
+		}
+		express("/app/data")
+		express("/app/secure/data")
+		express("/other/data")
+
+		// The most specific validator decides, and unmatched names use the default one
+		require.Equal(t, []string{"/app/data"}, byApp)
+		require.Equal(t, []string{"/app/secure/data"}, bySecure)
+		require.Equal(t, []string{"/other/data"}, byDefault)
+		require.Equal(t, map[string]ndn.InterestResult{"/app/data": ndn.InterestResultData}, results)
+
+		// Data failing the validation is dropped, so the Interests time out
+		timer.MoveForward(2 * time.Second)
+		require.Equal(t, ndn.InterestResultTimeout, results["/app/secure/data"])
+		require.Equal(t, ndn.InterestResultTimeout, results["/other/data"])
+
+		// Interests failing the validation are not given to the handler
+		var handled []string
+		require.NoError(t, engine.AttachHandler(utils.WithoutErr(enc.NameFromStr("/app")), func(
+			interest ndn.Interest, _ enc.Wire, _ enc.Wire, _ ndn.ReplyFunc, _ time.Time,
+		) {
+			handled = append(handled, interest.Name().String())
+		}))
+		for _, nameStr := range []string{"/app/ok", "/app/secure/no"} {
+			wire, _, _, err := spec.MakeInterest(utils.WithoutErr(enc.NameFromStr(nameStr)),
+				&ndn.InterestConfig{Nonce: utils.IdPtr[uint64](1)}, nil, nil)
+			require.NoError(t, err)
+			require.NoError(t, face.FeedPacket(wire.Join()))
+		}
+		require.Equal(t, []string{"/app/ok"}, handled)
+
+		// Without the validator of /app/secure, /app takes over
+		engine.SetValidator(utils.WithoutErr(enc.NameFromStr("/app/secure")), nil)
+		wire, _, _, err := spec.MakeInterest(utils.WithoutErr(enc.NameFromStr("/app/secure/no")),
+			&ndn.InterestConfig{Nonce: utils.IdPtr[uint64](2)}, nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, face.FeedPacket(wire.Join()))
+		require.Equal(t, []string{"/app/ok", "/app/secure/no"}, handled)
+		require.Equal(t, []string{"/app/secure/data", "/app/secure/no"}, bySecure)
+	})
+}
+
+// slowValidator accepts Data whose content is not "bad", after sleeping for delay or slowDelay
+// if the content is "slow".
+type slowValidator struct {
+	delay     time.Duration
+	slowDelay time.Duration
+}
+
+func (v slowValidator) ValidateData(data ndn.Data, _ enc.Wire, _ ndn.Signature) bool {
+	content := string(data.Content().Join())
+	if content == "slow" {
+		time.Sleep(v.slowDelay)
+	} else {
+		time.Sleep(v.delay)
+	}
+	return content != "bad"
+}
+
+func (slowValidator) ValidateInterest(ndn.Interest, enc.Wire, ndn.Signature) bool {
+	return true
+}
+
+func TestValidationWorkers(t *testing.T) {
+	spec := spec_2022.Spec{}
+	// The peer replies /test/bad with content "bad", and /test/order with both "slow" and "fast"
+	engine := executePeerTest(t, func(peer *dummy.PipeFace, interest *spec_2022.Interest) {
+		switch name := interest.NameV.String(); name {
+		case "/test/order":
+			for _, content := range []string{"slow", "fast"} {
+				data, _, _ := spec.MakeData(interest.NameV, &ndn.DataConfig{},
+					enc.Wire{[]byte(content)}, sec.NewEmptySigner())
+				peer.Send(data)
+			}
+		case "/test/bad":
+			data, _, _ := spec.MakeData(interest.NameV, &ndn.DataConfig{},
+				enc.Wire{[]byte("bad")}, sec.NewEmptySigner())
+			peer.Send(data)
+		default:
+			peerReply(peer, interest, false)
+		}
+	}, basic_engine.WithValidationWorkers(4))
+	engine.SetValidator(enc.Name{}, slowValidator{delay: time.Millisecond, slowDelay: 50 * time.Millisecond})
+
+	// Validated Data still goes to the right Interests
+	names := make([]string, 0, 33)
+	for i := 0; i < 32; i++ {
+		names = append(names, fmt.Sprintf("/test/d%d", i))
+	}
+	names = append(names, "/test/bad")
+	interests := make([]ndn.Interest, len(names))
+	for i, name := range names {
+		lifetime := time.Second
+		if name == "/test/bad" {
+			lifetime = 200 * time.Millisecond
+		}
+		interests[i] = makeTestInterest(t, name, lifetime)
+	}
+	results, err := engine.ExpressBatch(context.Background(), interests, len(interests))
+	require.NoError(t, err)
+	for i, name := range names {
+		if name == "/test/bad" {
+			require.Equal(t, ndn.InterestResultTimeout, results[i].Result)
+			continue
+		}
+		require.Equal(t, ndn.InterestResultData, results[i].Result)
+		require.Equal(t, name, results[i].Data.Name().String())
+		require.Equal(t, []byte(name), results[i].Data.Content().Join())
+	}
+
+	// Data of the same name is delivered in the order received, even if the later one is validated faster
+	data, err := engine.ExpressInterestCtx(context.Background(), makeTestInterest(t, "/test/order", time.Second))
+	require.NoError(t, err)
+	require.Equal(t, []byte("slow"), data.Content().Join())
+}
+
+func TestShutdownFromValidationWorker(t *testing.T) {
+	engine := executePeerTest(t, func(peer *dummy.PipeFace, interest *spec_2022.Interest) {
+		peerReply(peer, interest, false)
+	}, basic_engine.WithValidationWorkers(1))
+	engine.SetValidator(enc.Name{}, slowValidator{})
+
+	// The callback runs on the validation worker, which must not wait for itself to stop
+	spec := engine.Spec()
+	config := &ndn.InterestConfig{Lifetime: utils.IdPtr(time.Second)}
+	wire, _, finalName, err := spec.MakeInterest(utils.WithoutErr(enc.NameFromStr("/test/stop")), config, nil, nil)
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	err = engine.Express(finalName, config, wire,
+		func(result ndn.InterestResult, _ ndn.Data, _ enc.Wire, _ enc.Wire, _ uint64) {
+			require.Equal(t, ndn.InterestResultData, result)
+			done <- engine.Shutdown()
+		})
+	require.NoError(t, err)
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "Shutdown did not return")
+	}
+}
+
+func BenchmarkValidation(b *testing.B) {
+	for _, workers := range []int{0, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			consFace, peerFace := dummy.NewPipeFaces()
+			timer := basic_engine.NewTimer()
+			passAll := func(enc.Name, enc.Wire, ndn.Signature) bool {
+				return true
+			}
+			engine := basic_engine.NewEngine(consFace, timer, sec.NewSha256IntSigner(timer), passAll,
+				basic_engine.WithValidationWorkers(workers))
+			engine.SetValidator(enc.Name{}, slowValidator{delay: 100 * time.Microsecond})
+			peerFace.SetCallback(func(r enc.ParseReader) error {
+				pkt, _, err := spec_2022.ReadPacket(r)
+				if err != nil || pkt.Interest == nil {
+					return err
+				}
+				peerReply(peerFace, pkt.Interest, false)
+				return nil
+			}, func(error) error { return nil })
+			require.NoError(b, peerFace.Open())
+			require.NoError(b, engine.Start())
+			defer engine.Shutdown()
+
+			interests := make([]ndn.Interest, b.N)
+			for i := range interests {
+				name, err := enc.NameFromStr(fmt.Sprintf("/test/d%d", i))
+				require.NoError(b, err)
+				interests[i] = ndn.NewInterest(name).Lifetime(10 * time.Second).Build()
+			}
+			b.ResetTimer()
+			results, err := engine.ExpressBatch(context.Background(), interests, 64)
+			require.NoError(b, err)
+			for _, res := range results {
+				require.Equal(b, ndn.InterestResultData, res.Result)
+			}
+		})
+	}
+}
+
+func TestDisjointHandlers(t *testing.T) {
+	executeTest(t, func(face *dummy.DummyFace, engine *basic_engine.Engine, timer *dummy.Timer, signer ndn.Signer) {
+		spec := engine.Spec()
+		var hits []string
+		attach := func(prefix string) error {
+			return engine.AttachHandler(utils.WithoutErr(enc.NameFromStr(prefix)), func(
+				interest ndn.Interest, _ enc.Wire, _ enc.Wire, _ ndn.ReplyFunc, _ time.Time,
+			) {
+				hits = append(hits, prefix+" "+interest.Name().String())
+			})
+		}
+		nonce := uint64(0)
+		feed := func(name string) {
+			nonce++
+			wire, _, _, err := spec.MakeInterest(utils.WithoutErr(enc.NameFromStr(name)),
+				&ndn.InterestConfig{Nonce: utils.IdPtr(nonce)}, nil, nil)
+			require.NoError(t, err)
+			require.NoError(t, face.FeedPacket(wire.Join()))
+		}
+
+		require.NoError(t, attach("/a"))
+		require.NoError(t, attach("/b/x"))
+		require.NoError(t, attach("/b/y"))
+		feed("/a/1")
+		feed("/b/x/1")
+		feed("/b/y/1")
+		feed("/b/z/1")
+		require.Equal(t, []string{"/a /a/1", "/b/x /b/x/1", "/b/y /b/y/1"}, hits)
+
+		// Detaching a prefix without handler does not affect the handlers under it
+		hits = nil
+		require.Error(t, engine.DetachHandler(utils.WithoutErr(enc.NameFromStr("/b"))))
+		require.NoError(t, engine.DetachHandler(utils.WithoutErr(enc.NameFromStr("/b/x"))))
+		require.Error(t, engine.DetachHandler(utils.WithoutErr(enc.NameFromStr("/b/x"))))
+		feed("/a/2")
+		feed("/b/x/2")
+		feed("/b/y/2")
+		require.Equal(t, []string{"/a /a/2", "/b/y /b/y/2"}, hits)
+
+		// The detached prefix can be attached again
+		hits = nil
+		require.NoError(t, attach("/b/x"))
+		feed("/b/x/3")
+		require.Equal(t, []string{"/b/x /b/x/3"}, hits)
+	})
+}
+
+func TestLongestPrefixHandler(t *testing.T) {
+	executeTest(t, func(face *dummy.DummyFace, engine *basic_engine.Engine, timer *dummy.Timer, signer ndn.Signer) {
+		spec := engine.Spec()
+		var hits []string
+		attach := func(prefix string) error {
+			return engine.AttachHandler(utils.WithoutErr(enc.NameFromStr(prefix)), func(
+				interest ndn.Interest, _ enc.Wire, _ enc.Wire, _ ndn.ReplyFunc, _ time.Time,
+			) {
+				hits = append(hits, prefix+" "+interest.Name().String())
+			})
+		}
+		nonce := uint64(0)
+		feed := func(name string) {
+			nonce++
+			wire, _, _, err := spec.MakeInterest(utils.WithoutErr(enc.NameFromStr(name)),
+				&ndn.InterestConfig{Nonce: utils.IdPtr(nonce)}, nil, nil)
+			require.NoError(t, err)
+			require.NoError(t, face.FeedPacket(wire.Join()))
+		}
+
+		// Overlapping prefixes are attached in either order
+		require.NoError(t, attach("/a/b"))
+		require.NoError(t, attach("/a"))
+		require.NoError(t, attach("/a/b/c/d"))
+		require.ErrorIs(t, attach("/a/b"), ndn.ErrPrefixPropViolation)
+
+		feed("/a/b/1")
+		feed("/a/1")
+		feed("/a/b/c/1")
+		feed("/a/b/c/d/1")
+		feed("/a/b")
+		feed("/a")
+		feed("/x/1")
+		require.Equal(t, []string{
+			"/a/b /a/b/1",
+			"/a /a/1",
+			"/a/b /a/b/c/1",
+			"/a/b/c/d /a/b/c/d/1",
+			"/a/b /a/b",
+			"/a /a",
+		}, hits)
+
+		// After the more specific handler is detached, the shorter prefix takes over
+		hits = nil
+		require.NoError(t, engine.DetachHandler(utils.WithoutErr(enc.NameFromStr("/a/b"))))
+		feed("/a/b/2")
+		feed("/a/b/c/d/2")
+		require.Equal(t, []string{"/a /a/b/2", "/a/b/c/d /a/b/c/d/2"}, hits)
+	})
+}
+
+func TestExpressCancelable(t *testing.T) {
+	executeTest(t, func(face *dummy.DummyFace, engine *basic_engine.Engine, timer *dummy.Timer, signer ndn.Signer) {
+		spec := engine.Spec()
+		results := map[string]ndn.InterestResult{}
+		express := func(nameStr string) ndn.InterestToken {
+			name := utils.WithoutErr(enc.NameFromStr(nameStr))
+			config := &ndn.InterestConfig{Lifetime: utils.IdPtr(time.Second)}
+			wire, _, finalName, err := spec.MakeInterest(name, config, nil, nil)
+			require.NoError(t, err)
+			token, err := engine.ExpressCancelable(finalName, config, wire,
+				func(result ndn.InterestResult, _ ndn.Data, _ enc.Wire, _ enc.Wire, _ uint64) {
+					results[nameStr] = result
+				})
+			require.NoError(t, err)
+			return token
+		}
+		feedData := func(nameStr string) {
+			data, _, err := spec.MakeData(utils.WithoutErr(enc.NameFromStr(nameStr)), &ndn.DataConfig{},
+				enc.Wire{[]byte("hi")}, sec.NewEmptySigner())
+			require.NoError(t, err)
+			require.NoError(t, face.FeedPacket(data.Join()))
+		}
+
+		tokenA := express("/test/a")
+		tokenB := express("/test/a/b")
+		tokenC := express("/test/c")
+		require.Equal(t, uint64(3), engine.Stats().PitSize)
+
+		// The cancelled Interest is removed without its callback, while the ones of longer names are kept
+		require.True(t, tokenA.Cancel())
+		require.False(t, tokenA.Cancel())
+		require.Equal(t, uint64(2), engine.Stats().PitSize)
+		feedData("/test/a")
+		feedData("/test/a/b")
+		require.Equal(t, map[string]ndn.InterestResult{"/test/a/b": ndn.InterestResultData}, results)
+		require.False(t, tokenB.Cancel())
+		require.Equal(t, uint64(1), engine.Stats().PitSize)
+
+		// A cancelled Interest does not time out either
+		require.True(t, tokenC.Cancel())
+		require.Equal(t, uint64(0), engine.Stats().PitSize)
+		timer.MoveForward(2 * time.Second)
+		require.Equal(t, map[string]ndn.InterestResult{"/test/a/b": ndn.InterestResultData}, results)
+	})
+}
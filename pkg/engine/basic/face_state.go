@@ -0,0 +1,91 @@
+package basic
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// FaceState is the connection state of a face.
+type FaceState int32
+
+const (
+	// FaceStateDown means the face is not connected, either not opened yet or the connection is lost.
+	FaceStateDown FaceState = iota
+	// FaceStateConnecting means the face is establishing the connection.
+	FaceStateConnecting
+	// FaceStateUp means the face is connected and able to send packets.
+	FaceStateUp
+	// FaceStateClosed means the face is closed by Close.
+	FaceStateClosed
+)
+
+func (s FaceState) String() string {
+	switch s {
+	case FaceStateDown:
+		return "Down"
+	case FaceStateConnecting:
+		return "Connecting"
+	case FaceStateUp:
+		return "Up"
+	case FaceStateClosed:
+		return "Closed"
+	default:
+		return "Unknown"
+	}
+}
+
+// StatefulFace is a Face reporting its state changes.
+type StatefulFace interface {
+	Face
+	State() FaceState
+	OnStateChange(callback func(old, new FaceState))
+}
+
+// faceState keeps the state of a face and notifies the callbacks of changes.
+// It is embedded into faces to implement the methods of StatefulFace.
+type faceState struct {
+	state atomic.Int32
+	// lock serializes the changes, so callbacks see them in order
+	lock      sync.Mutex
+	callbacks []func(old, new FaceState)
+}
+
+// State returns the current state of the face.
+func (s *faceState) State() FaceState {
+	return FaceState(s.state.Load())
+}
+
+// OnStateChange adds a callback called on every state change.
+// Callbacks are called synchronously by the goroutine changing the state, so they should return quickly,
+// and must not add callbacks.
+func (s *faceState) OnStateChange(callback func(old, new FaceState)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.callbacks = append(s.callbacks, callback)
+}
+
+func (s *faceState) setState(new FaceState) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.change(new)
+}
+
+// updateState changes the state unless the face is closed by Close.
+// It is used by the receiving goroutine, which may race with Close.
+func (s *faceState) updateState(new FaceState) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.State() != FaceStateClosed {
+		s.change(new)
+	}
+}
+
+func (s *faceState) change(new FaceState) {
+	old := FaceState(s.state.Swap(int32(new)))
+	if old == new {
+		return
+	}
+	for _, cb := range s.callbacks {
+		cb(old, new)
+	}
+}
@@ -0,0 +1,263 @@
+package basic
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/log"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	spec "github.com/zjkmxy/go-ndn/pkg/ndn/spec_2022"
+)
+
+// DefaultLpMtu is the default MTU of an LpFace, fitting in an Ethernet frame with IPv6 and UDP headers.
+const DefaultLpMtu = 1400
+
+// DefaultReassemblyTimeout is the default time to wait for the missing fragments of a packet.
+const DefaultReassemblyTimeout = 500 * time.Millisecond
+
+// MaxLpFragCount is the max number of fragments of a received packet. Since NDN packets are at most 8800 bytes,
+// more fragments are only given by a broken or malicious sender.
+const MaxLpFragCount = 256
+
+// MaxLpPartialPackets is the max number of packets being reassembled by an LpFace at the same time.
+// Fragments of further packets are dropped until some complete or time out.
+const MaxLpPartialPackets = 64
+
+// lpFragOverhead is the max size of the LpPacket fields added to a fragment:
+// the LpPacket TLV header (4), Sequence (10), FragIndex (10), FragCount (10) and the Fragment TLV header (4).
+const lpFragOverhead = 38
+
+// partialPacket is a packet being reassembled.
+type partialPacket struct {
+	frags    []enc.Wire
+	received int
+	// header is the LpPacket of the first fragment, which carries the header fields of the packet.
+	header   *spec.LpPacket
+	deadline time.Time
+}
+
+// LpFace is an NDNLPv2 layer on top of another face.
+// It fragments outgoing packets larger than the MTU, and reassembles incoming fragments.
+// Fragments of a packet are given consecutive Sequence numbers, so a packet is identified by
+// the Sequence minus the FragIndex of any of its fragments.
+// A packet is dropped if its fragments do not all arrive within the reassembly timeout.
+type LpFace struct {
+	face    Face
+	timer   ndn.Timer
+	mtu     int
+	timeout time.Duration
+	onPkt   func(r enc.ParseReader) error
+	log     *log.Entry
+
+	// lock protects seq and partials
+	lock     sync.Mutex
+	seq      uint64
+	partials map[uint64]*partialPacket
+}
+
+func (f *LpFace) Open() error {
+	f.lock.Lock()
+	f.partials = make(map[uint64]*partialPacket)
+	f.lock.Unlock()
+	return f.face.Open()
+}
+
+func (f *LpFace) Close() error {
+	return f.face.Close()
+}
+
+func (f *LpFace) IsRunning() bool {
+	return f.face.IsRunning()
+}
+
+func (f *LpFace) IsLocal() bool {
+	return f.face.IsLocal()
+}
+
+func (f *LpFace) SetCallback(onPkt func(r enc.ParseReader) error,
+	onError func(err error) error) {
+	f.onPkt = onPkt
+	f.face.SetCallback(f.onFrame, onError)
+}
+
+// Send sends pkt as it is if it fits in the MTU, and in fragments otherwise.
+// If pkt is an LpPacket, its header fields are carried by the first fragment.
+func (f *LpFace) Send(pkt enc.Wire) error {
+	if pkt.Length() <= uint64(f.mtu) {
+		return f.face.Send(pkt)
+	}
+	payloadSize := f.mtu - lpFragOverhead
+	if payloadSize <= 0 {
+		return ndn.ErrInvalidValue{Item: "mtu", Value: f.mtu}
+	}
+
+	header := &spec.LpPacket{}
+	fragment := pkt
+	if len(pkt[0]) > 0 && pkt[0][0] == byte(spec.TypeLpPacket) {
+		p, _, err := spec.ReadPacket(enc.NewWireReader(pkt))
+		if err != nil {
+			return err
+		}
+		if p.LpPacket == nil {
+			return ndn.ErrFailedToEncode
+		}
+		if p.LpPacket.FragCount != nil {
+			return ndn.ErrNotSupported{Item: "fragmenting a fragment"}
+		}
+		header = p.LpPacket
+		fragment = p.LpPacket.Fragment
+	}
+
+	buf := fragment.Join()
+	count := (len(buf) + payloadSize - 1) / payloadSize
+	f.lock.Lock()
+	base := f.seq
+	f.seq += uint64(count)
+	f.lock.Unlock()
+
+	for i := 0; i < count; i++ {
+		end := min((i+1)*payloadSize, len(buf))
+		lpPkt := &spec.LpPacket{}
+		if i == 0 {
+			*lpPkt = *header
+		}
+		seq, idx, cnt := base+uint64(i), uint64(i), uint64(count)
+		lpPkt.Sequence = &seq
+		lpPkt.FragIndex = &idx
+		lpPkt.FragCount = &cnt
+		lpPkt.Fragment = enc.Wire{buf[i*payloadSize : end]}
+
+		p := &spec.Packet{LpPacket: lpPkt}
+		encoder := spec.PacketEncoder{}
+		encoder.Init(p)
+		wire := encoder.Encode(p)
+		if wire == nil {
+			return ndn.ErrFailedToEncode
+		}
+		if err := f.face.Send(wire); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// onFrame receives a packet from the face below, and passes it to the engine if it is not a fragment.
+func (f *LpFace) onFrame(r enc.ParseReader) error {
+	wire := r.Range(0, r.Length())
+	if len(wire) == 0 || len(wire[0]) == 0 || wire[0][0] != byte(spec.TypeLpPacket) {
+		return f.onPkt(enc.NewWireReader(wire))
+	}
+	p, _, err := spec.ReadPacket(enc.NewWireReader(wire))
+	if err != nil || p.LpPacket == nil {
+		// Leave it to the engine to report
+		return f.onPkt(enc.NewWireReader(wire))
+	}
+	lpPkt := p.LpPacket
+	if lpPkt.FragCount == nil {
+		return f.onPkt(enc.NewWireReader(wire))
+	}
+
+	var idx uint64 = 0
+	if lpPkt.FragIndex != nil {
+		idx = *lpPkt.FragIndex
+	}
+	count := *lpPkt.FragCount
+	// FragCount is checked before being used to allocate the fragments
+	if count == 0 || count > MaxLpFragCount || (lpPkt.Sequence == nil && count > 1) || idx >= count {
+		f.log.Warnf("Malformed fragment. Drop.")
+		return nil
+	}
+	var key uint64 = 0
+	if lpPkt.Sequence != nil {
+		key = *lpPkt.Sequence - idx
+	}
+	full := f.reassemble(key, idx, count, lpPkt)
+	if full == nil {
+		return nil
+	}
+	return f.onPkt(enc.NewWireReader(full))
+}
+
+// reassemble adds a fragment, and returns the reassembled packet if all fragments have arrived.
+func (f *LpFace) reassemble(key uint64, idx uint64, count uint64, lpPkt *spec.LpPacket) enc.Wire {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	now := f.timer.Now()
+	for k, pp := range f.partials {
+		if pp.deadline.Before(now) {
+			f.log.Warnf("Fragments of packet %d timed out. Drop.", k)
+			delete(f.partials, k)
+		}
+	}
+
+	pp := f.partials[key]
+	if pp == nil {
+		if count > 1 && len(f.partials) >= MaxLpPartialPackets {
+			f.log.Warnf("Too many packets being reassembled. Drop a fragment of packet %d.", key)
+			return nil
+		}
+		pp = &partialPacket{
+			frags:    make([]enc.Wire, count),
+			deadline: now.Add(f.timeout),
+		}
+		f.partials[key] = pp
+	} else if uint64(len(pp.frags)) != count {
+		f.log.Warnf("Inconsistent FragCount of packet %d. Drop.", key)
+		delete(f.partials, key)
+		return nil
+	}
+	if pp.frags[idx] != nil {
+		// Duplicate
+		return nil
+	}
+	pp.frags[idx] = lpPkt.Fragment
+	pp.received++
+	if idx == 0 {
+		pp.header = lpPkt
+	}
+	if pp.received < len(pp.frags) {
+		return nil
+	}
+	delete(f.partials, key)
+
+	fragment := enc.Wire{}
+	for _, frag := range pp.frags {
+		fragment = append(fragment, frag...)
+	}
+	header := *pp.header
+	header.Sequence = nil
+	header.FragIndex = nil
+	header.FragCount = nil
+	header.Fragment = fragment
+	p := &spec.Packet{LpPacket: &header}
+	encoder := spec.PacketEncoder{}
+	encoder.Init(p)
+	return encoder.Encode(p)
+}
+
+// SetMtu sets the max size of packets sent through the face below.
+func (f *LpFace) SetMtu(mtu int) {
+	f.mtu = mtu
+}
+
+// SetReassemblyTimeout sets how long to wait for the missing fragments of a packet.
+func (f *LpFace) SetReassemblyTimeout(timeout time.Duration) {
+	f.timeout = timeout
+}
+
+// NewLpFace creates an NDNLPv2 layer on top of face, fragmenting packets larger than mtu.
+// timer is used to time out incomplete packets.
+func NewLpFace(face Face, timer ndn.Timer, mtu int) *LpFace {
+	return &LpFace{
+		face:     face,
+		timer:    timer,
+		mtu:      mtu,
+		timeout:  DefaultReassemblyTimeout,
+		log:      log.WithField("module", "LpFace"),
+		seq:      rand.Uint64(),
+		partials: make(map[uint64]*partialPacket),
+	}
+}
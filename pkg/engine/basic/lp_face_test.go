@@ -0,0 +1,190 @@
+package basic_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	basic_engine "github.com/zjkmxy/go-ndn/pkg/engine/basic"
+	"github.com/zjkmxy/go-ndn/pkg/engine/dummy"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/ndn/spec_2022"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+func TestLpFaceFragmentation(t *testing.T) {
+	utils.SetTestingT(t)
+
+	timer := dummy.NewTimer()
+	inner := dummy.NewDummyFace()
+	face := basic_engine.NewLpFace(inner, timer, 1400)
+	received := make([]enc.Buffer, 0)
+	face.SetCallback(func(r enc.ParseReader) error {
+		received = append(received, utils.WithoutErr(r.ReadBuf(r.Length())))
+		return nil
+	}, func(err error) error {
+		return err
+	})
+	require.NoError(t, face.Open())
+
+	content := make([]byte, 20000)
+	for i := range content {
+		content[i] = byte(i)
+	}
+	data, _, err := spec_2022.Spec{}.MakeData(
+		utils.WithoutErr(enc.NameFromStr("/test/large")),
+		&ndn.DataConfig{},
+		enc.Wire{content},
+		sec.NewSha256Signer())
+	require.NoError(t, err)
+	require.NoError(t, face.Send(data))
+
+	frags := make([]enc.Buffer, 0)
+	for {
+		frag, err := inner.Consume()
+		if err != nil {
+			break
+		}
+		require.LessOrEqual(t, len(frag), 1400)
+		frags = append(frags, frag)
+	}
+	require.Equal(t, 15, len(frags))
+
+	// Out-of-order fragments are reassembled
+	for i := len(frags) - 1; i >= 0; i-- {
+		require.Equal(t, 0, len(received))
+		require.NoError(t, inner.FeedPacket(frags[i]))
+	}
+	require.Equal(t, 1, len(received))
+	pkt, _, err := spec_2022.ReadPacket(enc.NewBufferReader(received[0]))
+	require.NoError(t, err)
+	require.NotNil(t, pkt.LpPacket)
+	require.Nil(t, pkt.LpPacket.FragCount)
+	require.Equal(t, data.Join(), pkt.LpPacket.Fragment.Join())
+
+	// Incomplete packets time out
+	for _, frag := range frags[1:] {
+		require.NoError(t, inner.FeedPacket(frag))
+	}
+	timer.MoveForward(time.Second)
+	require.NoError(t, inner.FeedPacket(frags[0]))
+	require.Equal(t, 1, len(received))
+
+	// Small packets are sent as they are
+	small := enc.Wire{[]byte{0x05, 0x03, 0x07, 0x01, 0x08}}
+	require.NoError(t, face.Send(small))
+	require.Equal(t, small.Join(), []byte(utils.WithoutErr(inner.Consume())))
+	require.NoError(t, inner.FeedPacket(small.Join()))
+	require.Equal(t, small.Join(), []byte(received[1]))
+
+	require.NoError(t, face.Close())
+}
+
+func TestLpFaceEngine(t *testing.T) {
+	utils.SetTestingT(t)
+
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool {
+		return true
+	}
+	timer := dummy.NewTimer()
+	inner := dummy.NewDummyFace()
+	engine := basic_engine.NewEngine(basic_engine.NewLpFace(inner, timer, 1400), timer,
+		sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(t, engine.Start())
+	defer engine.Shutdown()
+
+	// The producer's reply is fragmented, and the PIT token goes with the first fragment
+	prefix := utils.WithoutErr(enc.NameFromStr("/test"))
+	engine.AttachHandler(prefix, func(
+		interest ndn.Interest, rawInterest enc.Wire, sigCovered enc.Wire, reply ndn.ReplyFunc, deadline time.Time,
+	) {
+		data, _, err := spec_2022.Spec{}.MakeData(interest.Name(), &ndn.DataConfig{},
+			enc.Wire{make([]byte, 5000)}, sec.NewSha256Signer())
+		require.NoError(t, err)
+		reply(data)
+	})
+	require.NoError(t, inner.FeedPacket(enc.Buffer(
+		"\x64\x12\x62\x04\x01\x02\x03\x04\x50\x0a\x05\x08\x07\x06\x08\x04test",
+	)))
+	cnt := 0
+	for {
+		frag, err := inner.Consume()
+		if err != nil {
+			break
+		}
+		pkt, _, err := spec_2022.ReadPacket(enc.NewBufferReader(frag))
+		require.NoError(t, err)
+		require.Equal(t, uint64(cnt), *pkt.LpPacket.FragIndex)
+		if cnt == 0 {
+			require.Equal(t, []byte{0x01, 0x02, 0x03, 0x04}, pkt.LpPacket.PitToken)
+		} else {
+			require.Nil(t, pkt.LpPacket.PitToken)
+		}
+		cnt++
+	}
+	require.Equal(t, 4, cnt)
+}
+
+func TestLpFaceMalformedFragments(t *testing.T) {
+	utils.SetTestingT(t)
+
+	timer := dummy.NewTimer()
+	inner := dummy.NewDummyFace()
+	face := basic_engine.NewLpFace(inner, timer, 1400)
+	received := 0
+	face.SetCallback(func(r enc.ParseReader) error {
+		received++
+		return nil
+	}, func(err error) error {
+		return err
+	})
+	require.NoError(t, face.Open())
+
+	fragment := func(seq, idx, count uint64) enc.Buffer {
+		p := &spec_2022.Packet{LpPacket: &spec_2022.LpPacket{
+			Sequence:  utils.IdPtr(seq),
+			FragIndex: utils.IdPtr(idx),
+			FragCount: utils.IdPtr(count),
+			Fragment:  enc.Wire{[]byte{0x05, 0x03, 0x07, 0x01, 0x08}},
+		}}
+		encoder := spec_2022.PacketEncoder{}
+		encoder.Init(p)
+		return encoder.Encode(p).Join()
+	}
+
+	// FragCount of zero or above the bound is dropped without allocating the fragments
+	require.NoError(t, inner.FeedPacket(fragment(10, 0, 0)))
+	require.NoError(t, inner.FeedPacket(fragment(20, 0, 1<<62)))
+	require.NoError(t, inner.FeedPacket(fragment(30, 0, basic_engine.MaxLpFragCount+1)))
+	require.NoError(t, inner.FeedPacket(fragment(40, 5, 2)))
+	require.Equal(t, 0, received)
+
+	// The max FragCount is still accepted
+	last := uint64(basic_engine.MaxLpFragCount)
+	for i := uint64(0); i < last; i++ {
+		require.NoError(t, inner.FeedPacket(fragment(1000+i, i, last)))
+	}
+	require.Equal(t, 1, received)
+
+	// Fragments of too many incomplete packets are dropped, while other packets still go through
+	for i := uint64(0); i < basic_engine.MaxLpPartialPackets; i++ {
+		require.NoError(t, inner.FeedPacket(fragment(2000+2*i, 0, 2)))
+	}
+	require.NoError(t, inner.FeedPacket(fragment(3000, 0, 2)))
+	require.NoError(t, inner.FeedPacket(fragment(3001, 1, 2)))
+	require.Equal(t, 1, received)
+	require.NoError(t, inner.FeedPacket(fragment(4000, 0, 1)))
+	require.Equal(t, 2, received)
+	require.NoError(t, inner.FeedPacket(fragment(2001, 1, 2)))
+	require.Equal(t, 3, received)
+
+	// Slots are freed when incomplete packets time out
+	timer.MoveForward(time.Second)
+	require.NoError(t, inner.FeedPacket(fragment(3000, 0, 2)))
+	require.NoError(t, inner.FeedPacket(fragment(3001, 1, 2)))
+	require.Equal(t, 4, received)
+
+	require.NoError(t, face.Close())
+}
@@ -0,0 +1,135 @@
+package basic
+
+import (
+	"fmt"
+	"time"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	mgmt "github.com/zjkmxy/go-ndn/pkg/ndn/mgmt_2022"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+// fetchMgmtData fetches one Data of the forwarder, and validates it with the command checker.
+func (e *Engine) fetchMgmtData(name enc.Name, canBePrefix bool) (ndn.Data, error) {
+	intCfg := &ndn.InterestConfig{
+		CanBePrefix: canBePrefix,
+		MustBeFresh: canBePrefix,
+		Lifetime:    utils.IdPtr(1 * time.Second),
+		Nonce:       utils.ConvertNonce(e.timer.Nonce()),
+	}
+	wire, _, finalName, err := e.Spec().MakeInterest(name, intCfg, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	type result struct {
+		data ndn.Data
+		err  error
+	}
+	ch := make(chan result, 1)
+	err = e.Express(finalName, intCfg, wire,
+		func(res ndn.InterestResult, data ndn.Data, rawData enc.Wire, sigCovered enc.Wire, nackReason uint64) {
+			switch res {
+			case ndn.InterestResultNack:
+				ch <- result{err: fmt.Errorf("nack received: %v", nackReason)}
+			case ndn.InterestResultTimeout:
+				ch <- result{err: ndn.ErrDeadlineExceed}
+			case ndn.InterestResultData:
+				if !e.cmdChecker(data.Name(), sigCovered, data.Signature()) {
+					ch <- result{err: fmt.Errorf("dataset signature is not valid")}
+				} else {
+					ch <- result{data: data}
+				}
+			default:
+				ch <- result{err: fmt.Errorf("unknown result: %v", res)}
+			}
+		})
+	if err != nil {
+		return nil, err
+	}
+	ret := <-ch
+	return ret.data, ret.err
+}
+
+// FetchStatusDataset fetches the latest version of a status dataset of the forwarder, e.g. "faces" "list",
+// and returns the content of all segments.
+// Segments are fetched one by one, as datasets are small.
+// NFD only serves datasets under /localhost, so they cannot be fetched from a remote forwarder.
+func (e *Engine) FetchStatusDataset(module string, dataset string) (enc.Wire, error) {
+	prefix, err := enc.NameFromStr("/localhost/nfd/" + module + "/" + dataset)
+	if err != nil {
+		return nil, err
+	}
+
+	// The first segment tells the version
+	data, err := e.fetchMgmtData(prefix, true)
+	if err != nil {
+		return nil, err
+	}
+	name := data.Name()
+	if len(name) != len(prefix)+2 ||
+		name[len(prefix)].Typ != enc.TypeVersionNameComponent ||
+		name[len(prefix)+1].Typ != enc.TypeSegmentNameComponent ||
+		name[len(prefix)+1].NumberVal() != 0 {
+		return nil, ndn.ErrInvalidValue{Item: "dataset name", Value: name}
+	}
+	versioned := make(enc.Name, len(prefix)+1, len(prefix)+2)
+	copy(versioned, name)
+
+	content := enc.Wire{}
+	for seg := uint64(0); ; seg++ {
+		if seg > 0 {
+			data, err = e.fetchMgmtData(append(versioned, enc.NewSegmentComponent(seg)), false)
+			if err != nil {
+				return nil, err
+			}
+		}
+		content = append(content, data.Content()...)
+		// A segment without FinalBlockId is taken as the last one
+		finalBlockId := data.FinalBlockID()
+		if finalBlockId == nil || finalBlockId.Typ != enc.TypeSegmentNameComponent ||
+			finalBlockId.NumberVal() <= seg {
+			break
+		}
+	}
+	return content, nil
+}
+
+// ListFaces returns the faces of the forwarder, from the faces/list dataset.
+func (e *Engine) ListFaces() ([]*mgmt.FaceStatus, error) {
+	content, err := e.FetchStatusDataset("faces", "list")
+	if err != nil {
+		return nil, err
+	}
+	msg, err := mgmt.ParseFaceStatusMsg(enc.NewWireReader(content), true)
+	if err != nil {
+		return nil, err
+	}
+	return msg.Vals, nil
+}
+
+// ListRib returns the routes of the forwarder, from the rib/list dataset.
+func (e *Engine) ListRib() ([]*mgmt.RibEntry, error) {
+	content, err := e.FetchStatusDataset("rib", "list")
+	if err != nil {
+		return nil, err
+	}
+	msg, err := mgmt.ParseRibStatus(enc.NewWireReader(content), true)
+	if err != nil {
+		return nil, err
+	}
+	return msg.Entries, nil
+}
+
+// ListFib returns the FIB entries of the forwarder, from the fib/list dataset.
+func (e *Engine) ListFib() ([]*mgmt.FibEntry, error) {
+	content, err := e.FetchStatusDataset("fib", "list")
+	if err != nil {
+		return nil, err
+	}
+	msg, err := mgmt.ParseFibStatus(enc.NewWireReader(content), true)
+	if err != nil {
+		return nil, err
+	}
+	return msg.Entries, nil
+}
@@ -0,0 +1,233 @@
+package basic
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cespare/xxhash"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+)
+
+// DefaultMulticastGroup is the IPv4 multicast group used by NFD's UDP multicast face.
+const DefaultMulticastGroup = "224.0.23.170:56363"
+
+// multicastDedupSize is the number of recently sent packets remembered to drop looped-back copies.
+const multicastDedupSize = 64
+
+// MulticastFace is a UDP multicast face, which sends packets to and receives packets from a multicast group.
+// With multicast loopback on, other applications on the same host can receive the packets;
+// copies of our own packets looped back to us are dropped.
+// Dropping is by content, so an identical packet sent by others shortly after is dropped too,
+// which is harmless as it is a duplicate anyway.
+type MulticastFace struct {
+	group    string
+	ifName   string
+	loopback bool
+	maxSize  int
+	conn     *net.UDPConn
+	gaddr    *net.UDPAddr
+	running  atomic.Bool
+	onPkt    func(r enc.ParseReader) error
+	onError  func(err error) error
+
+	// sentLock protects sentHashes and sentRing
+	sentLock   sync.Mutex
+	sentHashes map[uint64]int
+	sentRing   []uint64
+	sentPos    int
+
+	faceState
+}
+
+// rememberSent records the hash of a sent packet, forgetting the oldest one if full.
+func (f *MulticastFace) rememberSent(h uint64) {
+	f.sentLock.Lock()
+	defer f.sentLock.Unlock()
+
+	if len(f.sentRing) < multicastDedupSize {
+		f.sentRing = append(f.sentRing, h)
+	} else {
+		old := f.sentRing[f.sentPos]
+		if f.sentHashes[old]--; f.sentHashes[old] <= 0 {
+			delete(f.sentHashes, old)
+		}
+		f.sentRing[f.sentPos] = h
+		f.sentPos = (f.sentPos + 1) % multicastDedupSize
+	}
+	f.sentHashes[h]++
+}
+
+// isOwnPacket returns whether a received packet is a looped-back copy of a sent one.
+func (f *MulticastFace) isOwnPacket(h uint64) bool {
+	f.sentLock.Lock()
+	defer f.sentLock.Unlock()
+
+	return f.sentHashes[h] > 0
+}
+
+func (f *MulticastFace) Run() {
+	// One more byte tells a datagram larger than the max size, which the socket would truncate silently
+	buf := make([]byte, f.maxSize+1)
+	for f.running.Load() {
+		n, _, err := f.conn.ReadFromUDP(buf)
+		if err == nil && n > f.maxSize {
+			err = fmt.Errorf("%w: received more than %d bytes", ErrPacketTooLarge, f.maxSize)
+		}
+		if err != nil {
+			if !f.running.Load() {
+				break
+			}
+			err = f.onError(err)
+			if err != nil {
+				break
+			}
+			continue
+		}
+		if f.loopback && f.isOwnPacket(xxhash.Sum64(buf[:n])) {
+			continue
+		}
+		pkt := make([]byte, n)
+		copy(pkt, buf[:n])
+		r := enc.AcquireBufferReader(pkt)
+		err = f.onPkt(r)
+		enc.ReleaseBufferReader(r)
+		if err != nil {
+			// Note: err returned by the engine's callback is used to interrupt the face loop
+			// If it is recoverable, the engine should return log message and continue
+			break
+		}
+	}
+	f.running.Store(false)
+	f.conn = nil
+	f.updateState(FaceStateDown)
+}
+
+func (f *MulticastFace) Open() error {
+	if f.onError == nil || f.onPkt == nil {
+		return errors.New("face callbacks are not set")
+	}
+	if f.conn != nil {
+		return errors.New("face is already running")
+	}
+	f.setState(FaceStateConnecting)
+	c, gaddr, err := f.listen()
+	if err != nil {
+		f.updateState(FaceStateDown)
+		return err
+	}
+	f.conn = c
+	f.gaddr = gaddr
+	f.running.Store(true)
+	f.setState(FaceStateUp)
+	go f.Run()
+	return nil
+}
+
+// listen joins the multicast group.
+func (f *MulticastFace) listen() (*net.UDPConn, *net.UDPAddr, error) {
+	gaddr, err := net.ResolveUDPAddr("udp", f.group)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !gaddr.IP.IsMulticast() {
+		return nil, nil, fmt.Errorf("not a multicast group: %s", f.group)
+	}
+	network := "udp4"
+	if gaddr.IP.To4() == nil {
+		network = "udp6"
+	}
+	var ifi *net.Interface
+	if f.ifName != "" {
+		ifi, err = net.InterfaceByName(f.ifName)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	// ListenMulticastUDP sets SO_REUSEADDR, so that multiple faces can join the same group,
+	// and chooses ifi as the outgoing interface. It turns off multicast loopback.
+	c, err := net.ListenMulticastUDP(network, ifi, gaddr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if f.loopback {
+		err = setMulticastLoopback(c, network == "udp6", true)
+		if err != nil {
+			c.Close()
+			return nil, nil, err
+		}
+	}
+	return c, gaddr, nil
+}
+
+func (f *MulticastFace) Close() error {
+	if f.conn == nil {
+		return errors.New("face is not running")
+	}
+	f.running.Store(false)
+	f.setState(FaceStateClosed)
+	err := f.conn.Close()
+	// f.conn = nil // No need to do so, as Run() will set conn = nil
+	return err
+}
+
+// Send sends the packet to the multicast group in one datagram.
+// It returns ErrPacketTooLarge if the packet is larger than the max datagram size.
+func (f *MulticastFace) Send(pkt enc.Wire) error {
+	if !f.running.Load() {
+		return errors.New("face is not running")
+	}
+	if l := pkt.Length(); l > uint64(f.maxSize) {
+		return fmt.Errorf("%w: %d > %d", ErrPacketTooLarge, l, f.maxSize)
+	}
+	buf := pkt.Join()
+	if f.loopback {
+		f.rememberSent(xxhash.Sum64(buf))
+	}
+	_, err := f.conn.WriteToUDP(buf, f.gaddr)
+	return err
+}
+
+func (f *MulticastFace) IsRunning() bool {
+	return f.running.Load()
+}
+
+func (f *MulticastFace) IsLocal() bool {
+	return false
+}
+
+func (f *MulticastFace) SetCallback(onPkt func(r enc.ParseReader) error,
+	onError func(err error) error) {
+	f.onPkt = onPkt
+	f.onError = onError
+}
+
+// SetMulticastLoopback sets whether sent packets are looped back to other sockets on the same host.
+// It is on by default and must be called before Open.
+func (f *MulticastFace) SetMulticastLoopback(on bool) {
+	f.loopback = on
+}
+
+// SetMaxDatagramSize sets the max size of datagrams. It must be called before Open.
+func (f *MulticastFace) SetMaxDatagramSize(size int) {
+	f.maxSize = size
+}
+
+// NewMulticastFace creates a UDP multicast face joining group, e.g. DefaultMulticastGroup.
+// ifName is the name of the interface to use, or empty for the system default.
+func NewMulticastFace(group string, ifName string) *MulticastFace {
+	return &MulticastFace{
+		group:      group,
+		ifName:     ifName,
+		loopback:   true,
+		maxSize:    DefaultMaxDatagramSize,
+		onPkt:      nil,
+		onError:    nil,
+		conn:       nil,
+		running:    atomic.Bool{},
+		sentHashes: make(map[uint64]int),
+		sentRing:   make([]uint64, 0, multicastDedupSize),
+	}
+}
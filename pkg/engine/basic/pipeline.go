@@ -0,0 +1,290 @@
+package basic
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	spec "github.com/zjkmxy/go-ndn/pkg/ndn/spec_2022"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+// CongestionNotifier is an engine reporting the CongestionMarks of received Data, e.g. Engine.
+type CongestionNotifier interface {
+	OnCongestionMark(listener func(name enc.Name, mark uint64)) (cancel func())
+}
+
+// ErrPipelineBusy is returned when a ConsumerPipeline is asked to fetch while the last fetching is not done.
+var ErrPipelineBusy = errors.New("The pipeline is fetching another object.")
+
+// ConsumerPipeline fetches the segments of an object, keeping a window of Interests in flight.
+// The window is adjusted by AIMD: it grows by 1/window for every Data received,
+// and halves on a CongestionMark, a congestion Nack or a timeout.
+// It halves at most once for the segments in flight at the time of the last decrease,
+// as they are likely to suffer from the same congestion.
+// The window is kept across fetchings, so one pipeline should be used for one path.
+type ConsumerPipeline struct {
+	engine     ndn.Engine
+	lifetime   time.Duration
+	maxRetries int
+	minWindow  float64
+	maxWindow  float64
+
+	lock   sync.Mutex
+	window float64
+	fetch  *fetchState
+}
+
+// fetchState is the state of fetching one object.
+type fetchState struct {
+	prefix   enc.Name
+	first    uint64
+	last     uint64
+	next     uint64
+	segs     map[uint64]enc.Wire
+	retries  map[uint64]int
+	resend   []uint64
+	inFlight int
+	// segments first to lowWater-1 have all been received
+	lowWater uint64
+	// segments up to recoverSeg were in flight at the last decrease
+	recoverSeg  uint64
+	recovering  bool
+	callback    func(content enc.Wire, err error)
+	cancelMarks func()
+}
+
+// Window returns the current window size.
+func (p *ConsumerPipeline) Window() float64 {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.window
+}
+
+// Fetch fetches the segments first to last of the object prefix, i.e. /<prefix>/seg=<i>,
+// and calls callback with the concatenated content or an error.
+// If a segment carries a FinalBlockId, it is the last segment if smaller than last,
+// so last can be math.MaxUint64 if unknown.
+// callback is called on a new goroutine.
+func (p *ConsumerPipeline) Fetch(prefix enc.Name, first, last uint64, callback func(content enc.Wire, err error)) error {
+	if last < first {
+		return ndn.ErrInvalidValue{Item: "last", Value: last}
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.fetch != nil {
+		return ErrPipelineBusy
+	}
+	f := &fetchState{
+		prefix:   prefix,
+		first:    first,
+		last:     last,
+		next:     first,
+		lowWater: first,
+		segs:     make(map[uint64]enc.Wire),
+		retries:  make(map[uint64]int),
+		callback: callback,
+	}
+	if cn, ok := p.engine.(CongestionNotifier); ok {
+		f.cancelMarks = cn.OnCongestionMark(func(name enc.Name, _ uint64) {
+			p.onCongestionMark(f, name)
+		})
+	}
+	p.fetch = f
+	p.engine.Timer().Schedule(0, p.fill)
+	return nil
+}
+
+// fill sends Interests until the window is full.
+func (p *ConsumerPipeline) fill() {
+	segs := make([]uint64, 0)
+	p.lock.Lock()
+	f := p.fetch
+	lifetime := p.lifetime
+	for f != nil && f.inFlight < int(p.window) {
+		var seg uint64
+		if len(f.resend) > 0 {
+			seg = f.resend[0]
+			f.resend = f.resend[1:]
+		} else if f.next <= f.last {
+			seg = f.next
+			f.next++
+		} else {
+			break
+		}
+		f.inFlight++
+		segs = append(segs, seg)
+	}
+	p.lock.Unlock()
+
+	for _, seg := range segs {
+		if err := p.express(f, seg, lifetime); err != nil {
+			p.lock.Lock()
+			p.finish(f, nil, err)
+			p.lock.Unlock()
+			return
+		}
+	}
+}
+
+func (p *ConsumerPipeline) express(f *fetchState, seg uint64, lifetime time.Duration) error {
+	name := make(enc.Name, len(f.prefix), len(f.prefix)+1)
+	copy(name, f.prefix)
+	name = append(name, enc.NewSegmentComponent(seg))
+	config := &ndn.InterestConfig{
+		Lifetime: utils.IdPtr(lifetime),
+		Nonce:    utils.ConvertNonce(p.engine.Timer().Nonce()),
+	}
+	wire, _, finalName, err := p.engine.Spec().MakeInterest(name, config, nil, nil)
+	if err != nil {
+		return err
+	}
+	return p.engine.Express(finalName, config, wire,
+		func(result ndn.InterestResult, data ndn.Data, _ enc.Wire, _ enc.Wire, nackReason uint64) {
+			p.onResult(f, seg, result, data, nackReason)
+		})
+}
+
+// onResult is called by the engine, which holds the PIT lock, so new Interests are sent later by fill.
+func (p *ConsumerPipeline) onResult(f *fetchState, seg uint64, result ndn.InterestResult, data ndn.Data, nackReason uint64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.fetch != f {
+		return
+	}
+	f.inFlight--
+
+	switch result {
+	case ndn.InterestResultData:
+		if _, ok := f.segs[seg]; !ok {
+			f.segs[seg] = data.Content()
+		}
+		if fbId := data.FinalBlockID(); fbId != nil && fbId.Typ == enc.TypeSegmentNameComponent {
+			if final := fbId.NumberVal(); final < f.last && final >= f.first {
+				f.last = final
+			}
+		}
+		p.window = min(p.window+1/p.window, p.maxWindow)
+		if p.isComplete(f) {
+			content := enc.Wire{}
+			for i := f.first; i <= f.last; i++ {
+				content = append(content, f.segs[i]...)
+			}
+			p.finish(f, content, nil)
+			return
+		}
+	case ndn.InterestResultNack, ndn.InterestResultTimeout:
+		if seg > f.last {
+			// Sent before the FinalBlockId is known
+			break
+		}
+		if result == ndn.InterestResultTimeout || nackReason == spec.NackReasonCongestion {
+			p.decrease(f, seg)
+		}
+		f.retries[seg]++
+		if f.retries[seg] > p.maxRetries {
+			if result == ndn.InterestResultTimeout {
+				p.finish(f, nil, ndn.ErrDeadlineExceed)
+			} else {
+				p.finish(f, nil, fmt.Errorf("segment %d is nacked for %d", seg, nackReason))
+			}
+			return
+		}
+		f.resend = append(f.resend, seg)
+	default:
+		p.finish(f, nil, fmt.Errorf("unknown result: %v", result))
+		return
+	}
+	p.engine.Timer().Schedule(0, p.fill)
+}
+
+func (p *ConsumerPipeline) onCongestionMark(f *fetchState, name enc.Name) {
+	if len(name) != len(f.prefix)+1 || !f.prefix.IsPrefix(name) {
+		return
+	}
+	last := name[len(name)-1]
+	if last.Typ != enc.TypeSegmentNameComponent {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.fetch == f {
+		p.decrease(f, last.NumberVal())
+	}
+}
+
+func (p *ConsumerPipeline) decrease(f *fetchState, seg uint64) {
+	if f.recovering && seg <= f.recoverSeg {
+		return
+	}
+	p.window = max(p.window/2, p.minWindow)
+	f.recovering = true
+	f.recoverSeg = f.next - 1
+}
+
+// isComplete returns if all segments first to last have been received.
+// It advances the low-water mark, so each segment is checked once in total rather than on every Data.
+func (p *ConsumerPipeline) isComplete(f *fetchState) bool {
+	for f.lowWater < f.last {
+		if _, ok := f.segs[f.lowWater]; !ok {
+			return false
+		}
+		f.lowWater++
+	}
+	_, ok := f.segs[f.last]
+	return ok
+}
+
+func (p *ConsumerPipeline) finish(f *fetchState, content enc.Wire, err error) {
+	if p.fetch != f {
+		return
+	}
+	p.fetch = nil
+	if f.cancelMarks != nil {
+		f.cancelMarks()
+	}
+	go f.callback(content, err)
+}
+
+// SetInterestLifetime sets the lifetime of the Interests, which is also the time to wait for each segment.
+func (p *ConsumerPipeline) SetInterestLifetime(lifetime time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.lifetime = lifetime
+}
+
+// SetMaxRetries sets the times a segment is fetched again before the fetching fails.
+func (p *ConsumerPipeline) SetMaxRetries(retries int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.maxRetries = retries
+}
+
+// SetWindowLimits sets the min and max window size.
+// The min window is at least 1, otherwise the pipeline may stall with nothing in flight,
+// and the max window is at least the min window.
+func (p *ConsumerPipeline) SetWindowLimits(minWindow, maxWindow float64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	minWindow = max(minWindow, 1)
+	maxWindow = max(maxWindow, minWindow)
+	p.minWindow = minWindow
+	p.maxWindow = maxWindow
+	p.window = min(max(p.window, minWindow), maxWindow)
+}
+
+// NewConsumerPipeline creates a pipeline fetching through engine, starting with initWindow Interests in flight.
+// initWindow is raised to the default min window 1 if smaller.
+func NewConsumerPipeline(engine ndn.Engine, initWindow float64) *ConsumerPipeline {
+	return &ConsumerPipeline{
+		engine:     engine,
+		lifetime:   DefaultInterestLife,
+		maxRetries: 3,
+		minWindow:  1,
+		maxWindow:  256,
+		window:     max(initWindow, 1),
+	}
+}
@@ -0,0 +1,210 @@
+package basic_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	basic_engine "github.com/zjkmxy/go-ndn/pkg/engine/basic"
+	"github.com/zjkmxy/go-ndn/pkg/engine/dummy"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/ndn/spec_2022"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+type fetchResult struct {
+	content enc.Wire
+	err     error
+}
+
+// consumeInterests returns the segment numbers of the Interests sent by the engine.
+func consumeInterests(t *testing.T, face *dummy.DummyFace) []uint64 {
+	segs := make([]uint64, 0)
+	for {
+		buf, err := face.Consume()
+		if err != nil {
+			return segs
+		}
+		pkt, _, err := spec_2022.ReadPacket(enc.NewBufferReader(buf))
+		require.NoError(t, err)
+		require.NotNil(t, pkt.Interest)
+		name := pkt.Interest.NameV
+		segs = append(segs, name[len(name)-1].NumberVal())
+	}
+}
+
+// feedSegment feeds the Data of a segment, in an LpPacket with a CongestionMark if mark > 0.
+func feedSegment(t *testing.T, face *dummy.DummyFace, seg uint64, last uint64, mark uint64) {
+	name := utils.WithoutErr(enc.NameFromStr("/obj"))
+	name = append(name, enc.NewSegmentComponent(seg))
+	data, _, err := spec_2022.Spec{}.MakeData(name, &ndn.DataConfig{
+		FinalBlockID: utils.IdPtr(enc.NewSegmentComponent(last)),
+	}, enc.Wire{[]byte{byte(seg)}}, sec.NewSha256Signer())
+	require.NoError(t, err)
+	if mark == 0 {
+		require.NoError(t, face.FeedPacket(data.Join()))
+		return
+	}
+	lpPkt := &spec_2022.Packet{
+		LpPacket: &spec_2022.LpPacket{
+			CongestionMark: &mark,
+			Fragment:       data,
+		},
+	}
+	encoder := spec_2022.PacketEncoder{}
+	encoder.Init(lpPkt)
+	require.NoError(t, face.FeedPacket(encoder.Encode(lpPkt).Join()))
+}
+
+func TestPipelineCongestionMark(t *testing.T) {
+	executeTest(t, func(face *dummy.DummyFace, engine *basic_engine.Engine, timer *dummy.Timer, signer ndn.Signer) {
+		results := make(chan fetchResult, 1)
+		pipeline := basic_engine.NewConsumerPipeline(engine, 4)
+		require.NoError(t, pipeline.Fetch(utils.WithoutErr(enc.NameFromStr("/obj")), 0, 100,
+			func(content enc.Wire, err error) {
+				results <- fetchResult{content, err}
+			}))
+		timer.MoveForward(time.Millisecond)
+		require.Equal(t, []uint64{0, 1, 2, 3}, consumeInterests(t, face))
+
+		// The window halves on the first mark, but not for other segments in flight at that time
+		feedSegment(t, face, 0, 9, 1)
+		require.Equal(t, 2.5, pipeline.Window())
+		feedSegment(t, face, 1, 9, 1)
+		require.Equal(t, 2.9, pipeline.Window())
+		timer.MoveForward(time.Millisecond)
+		require.Equal(t, []uint64{}, consumeInterests(t, face))
+
+		// The window grows when there is no congestion, and FinalBlockId ends the fetching
+		pending := []uint64{2, 3}
+		for len(pending) > 0 {
+			for _, seg := range pending {
+				feedSegment(t, face, seg, 9, 0)
+			}
+			timer.MoveForward(time.Millisecond)
+			pending = consumeInterests(t, face)
+		}
+		require.Greater(t, pipeline.Window(), 2.9)
+		select {
+		case res := <-results:
+			require.NoError(t, res.err)
+			require.Equal(t, []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, res.content.Join())
+		case <-time.After(time.Second):
+			t.Fatal("fetching is not finished")
+		}
+
+		// A new mark after recovery halves the window again
+		require.NoError(t, pipeline.Fetch(utils.WithoutErr(enc.NameFromStr("/obj")), 0, 0,
+			func(content enc.Wire, err error) {
+				results <- fetchResult{content, err}
+			}))
+		window := pipeline.Window()
+		timer.MoveForward(time.Millisecond)
+		require.Equal(t, []uint64{0}, consumeInterests(t, face))
+		feedSegment(t, face, 0, 0, 1)
+		require.Less(t, pipeline.Window(), window)
+		require.NoError(t, (<-results).err)
+	})
+}
+
+func TestPipelineTimeout(t *testing.T) {
+	executeTest(t, func(face *dummy.DummyFace, engine *basic_engine.Engine, timer *dummy.Timer, signer ndn.Signer) {
+		results := make(chan fetchResult, 1)
+		pipeline := basic_engine.NewConsumerPipeline(engine, 4)
+		pipeline.SetInterestLifetime(100 * time.Millisecond)
+		pipeline.SetMaxRetries(1)
+		require.NoError(t, pipeline.Fetch(utils.WithoutErr(enc.NameFromStr("/obj")), 0, 3,
+			func(content enc.Wire, err error) {
+				results <- fetchResult{content, err}
+			}))
+		timer.MoveForward(time.Millisecond)
+		require.Equal(t, []uint64{0, 1, 2, 3}, consumeInterests(t, face))
+
+		// All Interests time out, and the window halves once
+		timer.MoveForward(200 * time.Millisecond)
+		require.Equal(t, 2.0, pipeline.Window())
+		timer.MoveForward(time.Millisecond)
+		require.Equal(t, []uint64{0, 1}, consumeInterests(t, face))
+
+		// The fetching fails after the retries are used up
+		timer.MoveForward(200 * time.Millisecond)
+		select {
+		case res := <-results:
+			require.Equal(t, ndn.ErrDeadlineExceed, res.err)
+		case <-time.After(time.Second):
+			t.Fatal("fetching is not finished")
+		}
+		// Retransmissions of the segments in flight at the last decrease do not shrink the window again
+		require.Equal(t, 2.0, pipeline.Window())
+	})
+}
+
+func TestPipelineUnknownLast(t *testing.T) {
+	executeTest(t, func(face *dummy.DummyFace, engine *basic_engine.Engine, timer *dummy.Timer, signer ndn.Signer) {
+		results := make(chan fetchResult, 1)
+		pipeline := basic_engine.NewConsumerPipeline(engine, 4)
+		pipeline.SetInterestLifetime(100 * time.Millisecond)
+		pipeline.SetMaxRetries(1)
+		require.NoError(t, pipeline.Fetch(utils.WithoutErr(enc.NameFromStr("/obj")), 0, math.MaxUint64,
+			func(content enc.Wire, err error) {
+				results <- fetchResult{content, err}
+			}))
+		timer.MoveForward(time.Millisecond)
+		require.Equal(t, []uint64{0, 1, 2, 3}, consumeInterests(t, face))
+
+		// Interests beyond the FinalBlockId are not sent again on timeout
+		feedSegment(t, face, 0, 1, 0)
+		timer.MoveForward(200 * time.Millisecond)
+		timer.MoveForward(time.Millisecond)
+		require.Equal(t, []uint64{1}, consumeInterests(t, face))
+		feedSegment(t, face, 1, 1, 0)
+		select {
+		case res := <-results:
+			require.NoError(t, res.err)
+			require.Equal(t, []byte{0, 1}, res.content.Join())
+		case <-time.After(time.Second):
+			t.Fatal("fetching is not finished")
+		}
+	})
+}
+
+func TestPipelineWindowLimits(t *testing.T) {
+	executeTest(t, func(face *dummy.DummyFace, engine *basic_engine.Engine, timer *dummy.Timer, signer ndn.Signer) {
+		pipeline := basic_engine.NewConsumerPipeline(engine, 0)
+		require.Equal(t, 1.0, pipeline.Window())
+
+		// The min window is at least 1 and the max window is not below the min window
+		pipeline.SetWindowLimits(0, 0)
+		require.Equal(t, 1.0, pipeline.Window())
+		pipeline.SetWindowLimits(8, 4)
+		require.Equal(t, 8.0, pipeline.Window())
+
+		// The setters can be called while fetching
+		results := make(chan fetchResult, 1)
+		require.NoError(t, pipeline.Fetch(utils.WithoutErr(enc.NameFromStr("/obj")), 0, 1,
+			func(content enc.Wire, err error) {
+				results <- fetchResult{content, err}
+			}))
+		done := make(chan struct{})
+		go func() {
+			pipeline.SetInterestLifetime(time.Second)
+			pipeline.SetMaxRetries(2)
+			pipeline.SetWindowLimits(1, 16)
+			close(done)
+		}()
+		timer.MoveForward(time.Millisecond)
+		<-done
+		require.Equal(t, []uint64{0, 1}, consumeInterests(t, face))
+		feedSegment(t, face, 0, 1, 0)
+		feedSegment(t, face, 1, 1, 0)
+		select {
+		case res := <-results:
+			require.NoError(t, res.err)
+		case <-time.After(time.Second):
+			t.Fatal("fetching is not finished")
+		}
+	})
+}
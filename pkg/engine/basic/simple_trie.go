@@ -155,3 +155,11 @@ func (n *NameTrie[V]) FirstNodeIf(pred func(V) bool) *NameTrie[V] {
 	}
 	return nil
 }
+
+// ForEach calls visit with the value of every node in the subtree, in DFS order.
+func (n *NameTrie[V]) ForEach(visit func(V)) {
+	visit(n.val)
+	for _, c := range n.chd {
+		c.ForEach(visit)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package basic
+
+import (
+	"errors"
+	"net"
+)
+
+func setMulticastLoopback(*net.UDPConn, bool, bool) error {
+	return errors.New("multicast loopback is not supported on this platform")
+}
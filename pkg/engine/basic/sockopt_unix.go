@@ -0,0 +1,31 @@
+//go:build unix
+
+package basic
+
+import (
+	"net"
+	"syscall"
+)
+
+func setMulticastLoopback(conn *net.UDPConn, ipv6 bool, on bool) error {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	val := 0
+	if on {
+		val = 1
+	}
+	var sockErr error
+	err = raw.Control(func(fd uintptr) {
+		if ipv6 {
+			sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IPV6, syscall.IPV6_MULTICAST_LOOP, val)
+		} else {
+			sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_MULTICAST_LOOP, val)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return sockErr
+}
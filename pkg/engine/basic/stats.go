@@ -0,0 +1,166 @@
+package basic
+
+import (
+	"slices"
+	"sync/atomic"
+	"time"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+)
+
+// EngineStats is a snapshot of the counters of an Engine.
+type EngineStats struct {
+	// InterestsSent counts Interests sent by Express, including retransmissions.
+	InterestsSent uint64
+	// InterestsAggregated counts Interests not sent by Express, since an identical one is pending.
+	InterestsAggregated uint64
+	// InterestsReceived counts Interests received from the face.
+	InterestsReceived uint64
+	// InterestsLooped counts Interests received again with the same name and Nonce, which are dropped.
+	InterestsLooped uint64
+	// DataSent counts Data replied by Interest handlers.
+	DataSent uint64
+	// DataReceived counts Data received from the face, including unsolicited ones.
+	DataReceived uint64
+	// NacksReceived counts Nacks received from the face.
+	NacksReceived uint64
+	// InterestsSatisfied counts pending Interests satisfied by Data.
+	InterestsSatisfied uint64
+	// InterestsNacked counts pending Interests ended by Nacks.
+	InterestsNacked uint64
+	// InterestsTimedOut counts pending Interests timed out after all retransmissions.
+	InterestsTimedOut uint64
+	// PitSize is the number of pending Interests.
+	PitSize uint64
+	// BytesIn is the size of all packets received from the face.
+	BytesIn uint64
+	// BytesOut is the size of all packets sent through the face.
+	BytesOut uint64
+	// DeadNonceListSize is the number of recently received Interests kept to detect loops.
+	DeadNonceListSize uint64
+	// SendQueueDepth is the number of packets waiting in the write queue of the face, if it is a QueuedFace.
+	SendQueueDepth uint64
+}
+
+// QueuedFace is a Face whose Send puts packets into a bounded write queue.
+type QueuedFace interface {
+	Face
+	QueueDepth() int
+}
+
+// engineCounters are updated by the engine without locks.
+type engineCounters struct {
+	interestsSent       atomic.Uint64
+	interestsAggregated atomic.Uint64
+	interestsReceived   atomic.Uint64
+	interestsLooped     atomic.Uint64
+	dataSent            atomic.Uint64
+	dataReceived        atomic.Uint64
+	nacksReceived       atomic.Uint64
+	interestsSatisfied  atomic.Uint64
+	interestsNacked     atomic.Uint64
+	interestsTimedOut   atomic.Uint64
+	pitSize             atomic.Int64
+	bytesIn             atomic.Uint64
+	bytesOut            atomic.Uint64
+}
+
+// Stats returns a snapshot of the counters.
+// Counters are read one by one, so a snapshot taken while packets are processed may be slightly inconsistent.
+func (e *Engine) Stats() EngineStats {
+	c := &e.counters
+	queueDepth := uint64(0)
+	if qf, ok := e.face.(QueuedFace); ok {
+		queueDepth = uint64(qf.QueueDepth())
+	}
+	deadNonces := uint64(0)
+	if e.deadNonces != nil {
+		deadNonces = uint64(e.deadNonces.size(e.timer.Now()))
+	}
+	return EngineStats{
+		InterestsSent:       c.interestsSent.Load(),
+		InterestsAggregated: c.interestsAggregated.Load(),
+		InterestsReceived:   c.interestsReceived.Load(),
+		InterestsLooped:     c.interestsLooped.Load(),
+		DataSent:            c.dataSent.Load(),
+		DataReceived:        c.dataReceived.Load(),
+		NacksReceived:       c.nacksReceived.Load(),
+		InterestsSatisfied:  c.interestsSatisfied.Load(),
+		InterestsNacked:     c.interestsNacked.Load(),
+		InterestsTimedOut:   c.interestsTimedOut.Load(),
+		PitSize:             uint64(max(c.pitSize.Load(), 0)),
+		BytesIn:             c.bytesIn.Load(),
+		BytesOut:            c.bytesOut.Load(),
+		DeadNonceListSize:   deadNonces,
+		SendQueueDepth:      queueDepth,
+	}
+}
+
+// PendingInterestInfo describes the Interests of the same name and selectors pending in the PIT.
+type PendingInterestInfo struct {
+	// Name is the final name of the Interests, including the implicit digest if any.
+	Name        enc.Name
+	CanBePrefix bool
+	MustBeFresh bool
+	// Lifetime is the time left until the last of the Interests times out.
+	Lifetime time.Duration
+	// Waiters is the number of Express calls waiting for the result, including aggregated ones.
+	Waiters int
+}
+
+// PendingInterests returns a snapshot of the PIT, sorted by name.
+func (e *Engine) PendingInterests() []PendingInterestInfo {
+	type key struct {
+		name        string
+		canBePrefix bool
+		mustBeFresh bool
+	}
+	now := e.timer.Now()
+	index := make(map[key]int)
+	ret := make([]PendingInterestInfo, 0)
+
+	e.pitLock.Lock()
+	e.pit.ForEach(func(entries pitEntry) {
+		for _, entry := range entries {
+			k := key{entry.name.String(), entry.canBePrefix, entry.mustBeFresh}
+			lifetime := max(entry.deadline.Sub(now), 0)
+			if i, ok := index[k]; ok {
+				ret[i].Waiters++
+				ret[i].Lifetime = max(ret[i].Lifetime, lifetime)
+				continue
+			}
+			index[k] = len(ret)
+			ret = append(ret, PendingInterestInfo{
+				Name:        entry.name,
+				CanBePrefix: entry.canBePrefix,
+				MustBeFresh: entry.mustBeFresh,
+				Lifetime:    lifetime,
+				Waiters:     1,
+			})
+		}
+	})
+	e.pitLock.Unlock()
+
+	slices.SortStableFunc(ret, func(a, b PendingInterestInfo) int {
+		return a.Name.Compare(b.Name)
+	})
+	return ret
+}
+
+// ReportStats calls callback with a snapshot of the counters every interval, using the engine's timer.
+// It returns a function to stop reporting.
+func (e *Engine) ReportStats(interval time.Duration, callback func(stats EngineStats)) (cancel func()) {
+	stopped := atomic.Bool{}
+	var report func()
+	report = func() {
+		if stopped.Load() {
+			return
+		}
+		callback(e.Stats())
+		e.timer.Schedule(interval, report)
+	}
+	e.timer.Schedule(interval, report)
+	return func() {
+		stopped.Store(true)
+	}
+}
@@ -2,6 +2,7 @@ package basic
 
 import (
 	"crypto/rand"
+	"encoding/binary"
 	"errors"
 	"time"
 
@@ -41,3 +42,13 @@ func (Timer) Nonce() []byte {
 	n, _ := rand.Read(buf) // Should always succeed
 	return buf[:n]
 }
+
+// nonceTimer is a timer whose Nonce comes from a NonceSource.
+type nonceTimer struct {
+	ndn.Timer
+	src ndn.NonceSource
+}
+
+func (t nonceTimer) Nonce() []byte {
+	return binary.BigEndian.AppendUint32(nil, t.src.NextNonce())
+}
@@ -2,65 +2,147 @@ package basic
 
 import (
 	"bufio"
+	"crypto/tls"
 	"errors"
-	"io"
+	"fmt"
 	"net"
 	"net/url"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/gorilla/websocket"
 	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/log"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
 )
 
+// reconnectPolicy decides how a face dials again after its connection is lost.
+type reconnectPolicy struct {
+	base       time.Duration
+	max        time.Duration
+	maxRetries int
+}
+
+// retry calls dial with exponential backoff, until it succeeds, the retries are used up, or stopped returns true.
+// It returns nil if stopped, and the error of the last attempt, or cause if there is none, if the retries are used up.
+func (p reconnectPolicy) retry(cause error, stopped func() bool, dial func() error) error {
+	delay := p.base
+	err := cause
+	for i := 0; (p.maxRetries < 0 || i < p.maxRetries) && !stopped(); i++ {
+		time.Sleep(delay)
+		if err = dial(); err == nil {
+			return nil
+		}
+		delay *= 2
+		if p.max > 0 && delay > p.max {
+			delay = p.max
+		}
+	}
+	if stopped() {
+		return nil
+	}
+	return err
+}
+
+// ReconnectableFace is a Face that establishes its connection again by itself after it is lost.
+// The engine uses the reconnect callback to register its routes again.
+type ReconnectableFace interface {
+	Face
+	SetReconnectCallback(onReconnect func())
+}
+
 type StreamFace struct {
-	network string
-	addr    string
-	local   bool
-	conn    net.Conn
-	running atomic.Bool
-	onPkt   func(r enc.ParseReader) error
-	onError func(err error) error
+	network     string
+	addr        string
+	local       bool
+	dial        func(network, addr string) (net.Conn, error)
+	lock        sync.Mutex
+	conn        net.Conn
+	running     atomic.Bool
+	onPkt       func(r enc.ParseReader) error
+	onError     func(err error) error
+	onReconnect func()
+	policy      reconnectPolicy
+	faceState
+
+	// queue holds the packets to be written by writeLoop, if SetWriteQueue is called. Otherwise Send writes.
+	queueCap     int
+	queueTimeout time.Duration
+	queue        chan enc.Wire
+	queueStop    chan struct{}
 }
 
-func (f *StreamFace) Run() {
-	r := bufio.NewReader(f.conn)
-	for f.running.Load() {
-		t, err := enc.ReadTLNum(r)
+// redial replaces the lost connection old with a new one, following the reconnect policy.
+// It returns a nil connection without error if the face is closed in the meantime.
+func (f *StreamFace) redial(old net.Conn, cause error) (net.Conn, error) {
+	old.Close()
+	f.updateState(FaceStateDown)
+	f.updateState(FaceStateConnecting)
+	logger := log.WithField("module", "StreamFace")
+	var c net.Conn
+	err := f.policy.retry(cause, func() bool {
+		return !f.running.Load()
+	}, func() error {
+		var err error
+		c, err = f.dial(f.network, f.addr)
 		if err != nil {
-			if !f.running.Load() {
-				break
-			}
-			err = f.onError(err)
-			if err != nil {
-				break
-			}
+			logger.Warnf("Unable to reconnect to %s: %v", f.addr, err)
 		}
-		l, err := enc.ReadTLNum(r)
+		return err
+	})
+	if err != nil || c == nil {
+		return nil, err
+	}
+	f.lock.Lock()
+	if !f.running.Load() {
+		f.lock.Unlock()
+		c.Close()
+		return nil, nil
+	}
+	f.conn = c
+	f.lock.Unlock()
+	f.updateState(FaceStateUp)
+	logger.Infof("Reconnected to %s", f.addr)
+	if f.onReconnect != nil {
+		f.onReconnect()
+	}
+	return c, nil
+}
+
+func (f *StreamFace) Run() {
+	f.lock.Lock()
+	c := f.conn
+	f.lock.Unlock()
+	r := bufio.NewReader(c)
+	s := enc.NewStreamReader(r, -1)
+	for f.running.Load() {
+		buf, err := s.ReadTLV()
 		if err != nil {
 			if !f.running.Load() {
 				break
 			}
-			err = f.onError(err)
-			if err != nil {
-				break
-			}
-		}
-		l0 := t.EncodingLength()
-		l1 := l.EncodingLength()
-		buf := make([]byte, l0+l1+int(l))
-		t.EncodeInto(buf)
-		l.EncodeInto(buf[l0:])
-		_, err = io.ReadFull(r, buf[l0+l1:])
-		if err != nil {
-			if !f.running.Load() {
+			if f.policy.maxRetries != 0 {
+				c, err = f.redial(c, err)
+				if c != nil {
+					r.Reset(c)
+					s = enc.NewStreamReader(r, -1)
+					continue
+				}
+				if err != nil {
+					f.onError(err)
+				}
 				break
 			}
 			err = f.onError(err)
 			if err != nil {
 				break
 			}
+			continue
 		}
-		err = f.onPkt(enc.NewBufferReader(buf))
+		r := enc.AcquireBufferReader(buf)
+		err = f.onPkt(r)
+		enc.ReleaseBufferReader(r)
 		if err != nil {
 			// Note: err returned by the engine's callback is used to interrupt the face loop
 			// If it is recoverable, the engine should return log message and continue
@@ -68,47 +150,159 @@ func (f *StreamFace) Run() {
 		}
 	}
 	f.running.Store(false)
-	f.conn = nil
+	f.lock.Lock()
+	if f.conn != nil {
+		f.conn.Close()
+		f.conn = nil
+	}
+	if f.queueStop != nil {
+		close(f.queueStop)
+		f.queue = nil
+		f.queueStop = nil
+	}
+	f.lock.Unlock()
+	f.updateState(FaceStateDown)
+}
+
+// writeLoop writes the packets in queue until stop is closed.
+func (f *StreamFace) writeLoop(queue chan enc.Wire, stop chan struct{}) {
+	for {
+		select {
+		case pkt := <-queue:
+			f.lock.Lock()
+			c := f.conn
+			f.lock.Unlock()
+			if c == nil {
+				continue
+			}
+			if err := f.write(c, pkt); err != nil {
+				log.WithField("module", "StreamFace").Warnf("Unable to send packet: %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// write writes pkt to c.
+func (f *StreamFace) write(c net.Conn, pkt enc.Wire) error {
+	for _, buf := range pkt {
+		_, err := c.Write(buf)
+		if err != nil {
+			if f.policy.maxRetries != 0 {
+				// Wake up Run() to reconnect
+				c.Close()
+			}
+			return err
+		}
+	}
+	return nil
 }
 
 func (f *StreamFace) Open() error {
 	if f.onError == nil || f.onPkt == nil {
 		return errors.New("face callbacks are not set")
 	}
-	if f.conn != nil {
+	if f.running.Load() {
 		return errors.New("face is already running")
 	}
-	c, err := net.Dial(f.network, f.addr)
+	f.setState(FaceStateConnecting)
+	c, err := f.dial(f.network, f.addr)
 	if err != nil {
+		f.updateState(FaceStateDown)
 		return err
 	}
+	f.lock.Lock()
 	f.conn = c
+	if f.queueCap > 0 {
+		f.queue = make(chan enc.Wire, f.queueCap)
+		f.queueStop = make(chan struct{})
+		go f.writeLoop(f.queue, f.queueStop)
+	}
+	f.lock.Unlock()
 	f.running.Store(true)
+	f.setState(FaceStateUp)
 	go f.Run()
 	return nil
 }
 
 func (f *StreamFace) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
 	if f.conn == nil {
 		return errors.New("face is not running")
 	}
 	f.running.Store(false)
+	f.setState(FaceStateClosed)
 	err := f.conn.Close()
 	// f.conn = nil // No need to do so, as Run() will set conn = nil
 	return err
 }
 
+// Send writes pkt to the connection, or puts it into the write queue if SetWriteQueue is called.
+// With a full queue, it waits for the timeout of the queue and returns ndn.ErrWouldBlock.
+// A queued packet must not be modified, and errors writing it are only logged.
 func (f *StreamFace) Send(pkt enc.Wire) error {
 	if !f.running.Load() {
 		return errors.New("face is not running")
 	}
-	for _, buf := range pkt {
-		_, err := f.conn.Write(buf)
-		if err != nil {
-			return err
-		}
+	f.lock.Lock()
+	c := f.conn
+	queue, stop := f.queue, f.queueStop
+	f.lock.Unlock()
+	if c == nil {
+		return errors.New("face is not running")
 	}
-	return nil
+	if queue == nil {
+		return f.write(c, pkt)
+	}
+	select {
+	case queue <- pkt:
+		return nil
+	default:
+	}
+	if f.queueTimeout <= 0 {
+		return ndn.ErrWouldBlock
+	}
+	timer := time.NewTimer(f.queueTimeout)
+	defer timer.Stop()
+	select {
+	case queue <- pkt:
+		return nil
+	case <-timer.C:
+		return ndn.ErrWouldBlock
+	case <-stop:
+		return errors.New("face is not running")
+	}
+}
+
+// SetWriteQueue makes Send queue packets for a separate writer, holding at most capacity of them,
+// so a slow connection does not block the sender. Send waits up to timeout for room in a full queue;
+// a non-positive timeout fails at once. It takes effect when the face is opened.
+// By default a StreamFace has no queue and Send blocks until the packet is written.
+func (f *StreamFace) SetWriteQueue(capacity int, timeout time.Duration) {
+	f.queueCap = capacity
+	f.queueTimeout = timeout
+}
+
+// QueueDepth returns the number of packets in the write queue.
+func (f *StreamFace) QueueDepth() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return len(f.queue)
+}
+
+// SetReconnectPolicy makes the face dial again when the connection is lost.
+// The delay before each attempt starts at base and doubles up to max.
+// The face reports an error after maxRetries failed attempts; a negative maxRetries retries forever.
+// By default a StreamFace does not reconnect.
+func (f *StreamFace) SetReconnectPolicy(base, max time.Duration, maxRetries int) {
+	f.policy = reconnectPolicy{base: base, max: max, maxRetries: maxRetries}
+}
+
+// SetReconnectCallback sets the function called every time the connection is established again.
+func (f *StreamFace) SetReconnectCallback(onReconnect func()) {
+	f.onReconnect = onReconnect
 }
 
 func (f *StreamFace) IsRunning() bool {
@@ -130,6 +324,23 @@ func NewStreamFace(network string, addr string, local bool) *StreamFace {
 		network: network,
 		addr:    addr,
 		local:   local,
+		dial:    net.Dial,
+		onPkt:   nil,
+		onError: nil,
+		conn:    nil,
+		running: atomic.Bool{},
+	}
+}
+
+// NewTlsFace creates a stream face over a TLS connection to a remote forwarder, e.g. NewTlsFace("tcp", addr, config).
+// config gives the root CAs to trust and the client certificates, if any.
+func NewTlsFace(network string, addr string, config *tls.Config) *StreamFace {
+	dialer := &tls.Dialer{Config: config}
+	return &StreamFace{
+		network: network,
+		addr:    addr,
+		local:   false,
+		dial:    dialer.Dial,
 		onPkt:   nil,
 		onError: nil,
 		conn:    nil,
@@ -137,24 +348,140 @@ func NewStreamFace(network string, addr string, local bool) *StreamFace {
 	}
 }
 
+// DefaultWebSocketPingInterval is the default interval of keepalive pings sent by a WebSocketFace.
+const DefaultWebSocketPingInterval = 10 * time.Second
+
+// defaultWsReconnectPolicy tries a few times within about three seconds.
+var defaultWsReconnectPolicy = reconnectPolicy{
+	base:       100 * time.Millisecond,
+	max:        time.Second,
+	maxRetries: 5,
+}
+
+// WebSocketFace is a face over a WebSocket connection, e.g. NFD's WebSocket channel.
+// Every binary message carries exactly one NDN packet.
+// The face pings the remote end periodically, and treats the connection as lost
+// if nothing is heard from the remote end for the idle timeout, two ping intervals by default.
+// A lost connection is dialed again with an increasing delay before an error is reported.
 type WebSocketFace struct {
-	network string
-	addr    string
-	local   bool
-	conn    *websocket.Conn
-	running atomic.Bool
-	onPkt   func(r enc.ParseReader) error
-	onError func(err error) error
+	url          string
+	tlsConfig    *tls.Config
+	local        bool
+	pingInterval time.Duration
+	idleTimeout  time.Duration
+	lock         sync.Mutex
+	conn         *websocket.Conn
+	running      atomic.Bool
+	onPkt        func(r enc.ParseReader) error
+	onError      func(err error) error
+	onReconnect  func()
+	policy       reconnectPolicy
+	faceState
+}
+
+func (f *WebSocketFace) readDeadline() time.Time {
+	if f.pingInterval <= 0 {
+		return time.Time{}
+	}
+	if f.idleTimeout <= 0 {
+		return time.Now().Add(2 * f.pingInterval)
+	}
+	return time.Now().Add(f.idleTimeout)
+}
+
+func (f *WebSocketFace) dial() (*websocket.Conn, error) {
+	dialer := *websocket.DefaultDialer
+	dialer.TLSClientConfig = f.tlsConfig
+	c, _, err := dialer.Dial(f.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.SetPongHandler(func(string) error {
+		return c.SetReadDeadline(f.readDeadline())
+	})
+	return c, nil
+}
+
+func (f *WebSocketFace) keepalive(c *websocket.Conn) {
+	if f.pingInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(f.pingInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		// WriteControl is safe to call concurrently with Send, and fails once c is closed
+		err := c.WriteControl(websocket.PingMessage, nil, time.Now().Add(f.pingInterval))
+		if err != nil {
+			return
+		}
+	}
+}
+
+// reconnect replaces the lost connection old with a new one.
+// It returns a nil connection without error if the face is closed in the meantime.
+func (f *WebSocketFace) reconnect(old *websocket.Conn, cause error) (*websocket.Conn, error) {
+	old.Close()
+	f.updateState(FaceStateDown)
+	f.updateState(FaceStateConnecting)
+	logger := log.WithField("module", "WebSocketFace")
+	var c *websocket.Conn
+	err := f.policy.retry(cause, func() bool {
+		return !f.running.Load()
+	}, func() error {
+		var err error
+		c, err = f.dial()
+		if err != nil {
+			logger.Warnf("Unable to reconnect to %s: %v", f.url, err)
+		}
+		return err
+	})
+	if err != nil || c == nil {
+		return nil, err
+	}
+	f.lock.Lock()
+	if !f.running.Load() {
+		f.lock.Unlock()
+		c.Close()
+		return nil, nil
+	}
+	f.conn = c
+	f.lock.Unlock()
+	f.updateState(FaceStateUp)
+	go f.keepalive(c)
+	logger.Infof("Reconnected to %s", f.url)
+	if f.onReconnect != nil {
+		f.onReconnect()
+	}
+	return c, nil
 }
 
 func (f *WebSocketFace) Run() {
+	f.lock.Lock()
+	c := f.conn
+	f.lock.Unlock()
 	for f.running.Load() {
-		messageType, pkt, err := f.conn.ReadMessage()
-		if err != nil || messageType != websocket.BinaryMessage {
-			// Ignore invalid message
+		c.SetReadDeadline(f.readDeadline())
+		messageType, pkt, err := c.ReadMessage()
+		if err != nil {
+			if !f.running.Load() {
+				break
+			}
+			c, err = f.reconnect(c, err)
+			if err != nil {
+				f.onError(err)
+			}
+			if c == nil {
+				break
+			}
+			continue
+		}
+		if messageType != websocket.BinaryMessage {
+			// Ignore text messages
 			continue
 		}
-		err = f.onPkt(enc.NewBufferReader(pkt))
+		r := enc.AcquireBufferReader(pkt)
+		err = f.onPkt(r)
+		enc.ReleaseBufferReader(r)
 		if err != nil {
 			// Note: err returned by the engine's callback is used to interrupt the face loop
 			// If it is recoverable, the engine should return log message and continue
@@ -162,13 +489,25 @@ func (f *WebSocketFace) Run() {
 		}
 	}
 	f.running.Store(false)
-	f.conn = nil
+	f.lock.Lock()
+	if f.conn != nil {
+		f.conn.Close()
+		f.conn = nil
+	}
+	f.lock.Unlock()
+	f.updateState(FaceStateDown)
 }
 
 func (f *WebSocketFace) Send(pkt enc.Wire) error {
 	if !f.running.Load() {
 		return errors.New("face is not running")
 	}
+	// gorilla/websocket does not allow concurrent writers
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.conn == nil {
+		return errors.New("face is not running")
+	}
 	return f.conn.WriteMessage(websocket.BinaryMessage, pkt.Join())
 }
 
@@ -176,29 +515,33 @@ func (f *WebSocketFace) Open() error {
 	if f.onError == nil || f.onPkt == nil {
 		return errors.New("face callbacks are not set")
 	}
-	if f.conn != nil {
+	if f.running.Load() {
 		return errors.New("face is already running")
 	}
-	u := url.URL{
-		Scheme: f.network,
-		Host:   f.addr,
-		Path:   "/",
-	}
-	c, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	f.setState(FaceStateConnecting)
+	c, err := f.dial()
 	if err != nil {
+		f.updateState(FaceStateDown)
 		return err
 	}
+	f.lock.Lock()
 	f.conn = c
+	f.lock.Unlock()
 	f.running.Store(true)
+	f.setState(FaceStateUp)
+	go f.keepalive(c)
 	go f.Run()
 	return nil
 }
 
 func (f *WebSocketFace) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
 	if f.conn == nil {
 		return errors.New("face is not running")
 	}
 	f.running.Store(false)
+	f.setState(FaceStateClosed)
 	err := f.conn.Close()
 	// f.conn = nil // No need to do so, as Run() will set conn = nil
 	return err
@@ -218,11 +561,193 @@ func (f *WebSocketFace) SetCallback(onPkt func(r enc.ParseReader) error,
 	f.onError = onError
 }
 
-func NewWebSocketFace(network string, addr string, local bool) *WebSocketFace {
+// SetPingInterval sets the interval of keepalive pings, with an idle timeout of two intervals.
+// Zero disables the keepalive. It takes effect on the next connection.
+func (f *WebSocketFace) SetPingInterval(interval time.Duration) {
+	f.SetKeepAlive(interval, 0)
+}
+
+// SetKeepAlive makes the face ping the remote end every interval, and reconnect if nothing,
+// including a pong, is received for timeout. A zero timeout means two intervals, and a zero interval
+// disables the keepalive. It takes effect on the next connection.
+func (f *WebSocketFace) SetKeepAlive(interval, timeout time.Duration) {
+	f.pingInterval = interval
+	f.idleTimeout = timeout
+}
+
+// SetReconnectPolicy sets how the face dials again when the connection is lost.
+// The delay before each attempt starts at base and doubles up to max.
+// The face reports an error after maxRetries failed attempts; a negative maxRetries retries forever.
+// By default a WebSocketFace tries 5 times.
+func (f *WebSocketFace) SetReconnectPolicy(base, max time.Duration, maxRetries int) {
+	f.policy = reconnectPolicy{base: base, max: max, maxRetries: maxRetries}
+}
+
+// SetReconnectCallback sets the function called every time the connection is established again.
+func (f *WebSocketFace) SetReconnectCallback(onReconnect func()) {
+	f.onReconnect = onReconnect
+}
+
+// NewWebSocketFace creates a face connecting to a WebSocket server at url, e.g. "ws://localhost:9696".
+// tlsConfig is used for "wss" urls; nil means the default configuration.
+// The face is local if the host of url is a loopback address.
+func NewWebSocketFace(url string, tlsConfig *tls.Config) *WebSocketFace {
 	return &WebSocketFace{
+		url:          url,
+		tlsConfig:    tlsConfig,
+		local:        isLoopbackUrl(url),
+		pingInterval: DefaultWebSocketPingInterval,
+		policy:       defaultWsReconnectPolicy,
+		onPkt:        nil,
+		onError:      nil,
+		conn:         nil,
+		running:      atomic.Bool{},
+	}
+}
+
+func isLoopbackUrl(rawUrl string) bool {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// DefaultMaxDatagramSize is the default max size of a datagram sent or received by a DatagramFace.
+// It is the same as the max NDN packet size of NFD's UDP face.
+const DefaultMaxDatagramSize = 8800
+
+// ErrPacketTooLarge is returned when a packet to send does not fit in one datagram.
+// It is also given to the error callback of the face for a received datagram larger than the max size,
+// which is dropped instead of being truncated.
+var ErrPacketTooLarge = errors.New("Packet is too large to fit in one datagram.")
+
+// DatagramFace is a unicast face over a datagram socket, e.g. UDP.
+// Every datagram carries exactly one NDN packet, so there is no stream framing.
+// Packets larger than the max datagram size are rejected instead of fragmented.
+type DatagramFace struct {
+	network string
+	addr    string
+	local   bool
+	maxSize int
+	conn    net.Conn
+	running atomic.Bool
+	onPkt   func(r enc.ParseReader) error
+	onError func(err error) error
+	faceState
+}
+
+func (f *DatagramFace) Run() {
+	// One more byte tells a datagram larger than the max size, which the socket would truncate silently
+	buf := make([]byte, f.maxSize+1)
+	for f.running.Load() {
+		n, err := f.conn.Read(buf)
+		if err == nil && n > f.maxSize {
+			err = fmt.Errorf("%w: received more than %d bytes", ErrPacketTooLarge, f.maxSize)
+		}
+		if err != nil {
+			if !f.running.Load() {
+				break
+			}
+			err = f.onError(err)
+			if err != nil {
+				break
+			}
+			continue
+		}
+		// The engine may keep the packet, so the receive buffer cannot be reused
+		pkt := make([]byte, n)
+		copy(pkt, buf[:n])
+		r := enc.AcquireBufferReader(pkt)
+		err = f.onPkt(r)
+		enc.ReleaseBufferReader(r)
+		if err != nil {
+			// Note: err returned by the engine's callback is used to interrupt the face loop
+			// If it is recoverable, the engine should return log message and continue
+			break
+		}
+	}
+	f.running.Store(false)
+	f.conn = nil
+	f.updateState(FaceStateDown)
+}
+
+func (f *DatagramFace) Open() error {
+	if f.onError == nil || f.onPkt == nil {
+		return errors.New("face callbacks are not set")
+	}
+	if f.conn != nil {
+		return errors.New("face is already running")
+	}
+	f.setState(FaceStateConnecting)
+	c, err := net.Dial(f.network, f.addr)
+	if err != nil {
+		f.updateState(FaceStateDown)
+		return err
+	}
+	f.conn = c
+	f.running.Store(true)
+	f.setState(FaceStateUp)
+	go f.Run()
+	return nil
+}
+
+func (f *DatagramFace) Close() error {
+	if f.conn == nil {
+		return errors.New("face is not running")
+	}
+	f.running.Store(false)
+	f.setState(FaceStateClosed)
+	err := f.conn.Close()
+	// f.conn = nil // No need to do so, as Run() will set conn = nil
+	return err
+}
+
+// Send sends the packet in one datagram.
+// It returns ErrPacketTooLarge if the packet is larger than the max datagram size.
+func (f *DatagramFace) Send(pkt enc.Wire) error {
+	if !f.running.Load() {
+		return errors.New("face is not running")
+	}
+	if l := pkt.Length(); l > uint64(f.maxSize) {
+		return fmt.Errorf("%w: %d > %d", ErrPacketTooLarge, l, f.maxSize)
+	}
+	// A datagram must be written at once
+	_, err := f.conn.Write(pkt.Join())
+	return err
+}
+
+func (f *DatagramFace) IsRunning() bool {
+	return f.running.Load()
+}
+
+func (f *DatagramFace) IsLocal() bool {
+	return f.local
+}
+
+func (f *DatagramFace) SetCallback(onPkt func(r enc.ParseReader) error,
+	onError func(err error) error) {
+	f.onPkt = onPkt
+	f.onError = onError
+}
+
+// SetMaxDatagramSize sets the max size of datagrams. It must be called before Open.
+func (f *DatagramFace) SetMaxDatagramSize(size int) {
+	f.maxSize = size
+}
+
+// NewDatagramFace creates a unicast datagram face, e.g. NewDatagramFace("udp", "127.0.0.1:6363", false).
+func NewDatagramFace(network string, addr string, local bool) *DatagramFace {
+	return &DatagramFace{
 		network: network,
 		addr:    addr,
 		local:   local,
+		maxSize: DefaultMaxDatagramSize,
 		onPkt:   nil,
 		onError: nil,
 		conn:    nil,
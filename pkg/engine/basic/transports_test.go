@@ -0,0 +1,930 @@
+package basic_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	basic_engine "github.com/zjkmxy/go-ndn/pkg/engine/basic"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	mgmt "github.com/zjkmxy/go-ndn/pkg/ndn/mgmt_2022"
+	"github.com/zjkmxy/go-ndn/pkg/ndn/spec_2022"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+func TestDatagramFace(t *testing.T) {
+	utils.SetTestingT(t)
+
+	server := utils.WithoutErr(net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}))
+	defer server.Close()
+
+	received := make(chan []byte, 4)
+	errs := make(chan error, 4)
+	face := basic_engine.NewDatagramFace("udp", server.LocalAddr().String(), false)
+	face.SetMaxDatagramSize(64)
+	face.SetCallback(func(r enc.ParseReader) error {
+		received <- utils.WithoutErr(r.ReadBuf(r.Length()))
+		return nil
+	}, func(err error) error {
+		errs <- err
+		return nil
+	})
+	require.NoError(t, face.Open())
+
+	// Each packet is sent in one datagram, even if the wire is fragmented
+	require.NoError(t, face.Send(enc.Wire{[]byte{0x05, 0x03}, []byte{0x01, 0x02}, []byte{0x03}}))
+	buf := make([]byte, 100)
+	require.NoError(t, server.SetReadDeadline(time.Now().Add(time.Second)))
+	n, clientAddr, err := server.ReadFromUDP(buf)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x05, 0x03, 0x01, 0x02, 0x03}, buf[:n])
+
+	// Each datagram is given to the engine as a whole packet
+	utils.WithoutErr(server.WriteToUDP([]byte{0x06, 0x01, 0x01}, clientAddr))
+	utils.WithoutErr(server.WriteToUDP([]byte{0x06, 0x02, 0x01, 0x02}, clientAddr))
+	for _, expected := range [][]byte{{0x06, 0x01, 0x01}, {0x06, 0x02, 0x01, 0x02}} {
+		select {
+		case pkt := <-received:
+			require.Equal(t, expected, pkt)
+		case <-time.After(time.Second):
+			t.Fatal("datagram is not received")
+		}
+	}
+
+	err = face.Send(enc.Wire{make([]byte, 65)})
+	require.True(t, errors.Is(err, basic_engine.ErrPacketTooLarge))
+
+	// A received datagram larger than the max size is an error instead of a truncated packet
+	utils.WithoutErr(server.WriteToUDP(make([]byte, 65), clientAddr))
+	utils.WithoutErr(server.WriteToUDP(make([]byte, 64), clientAddr))
+	select {
+	case err := <-errs:
+		require.ErrorIs(t, err, basic_engine.ErrPacketTooLarge)
+	case <-time.After(time.Second):
+		t.Fatal("oversized datagram is not reported")
+	}
+	select {
+	case pkt := <-received:
+		require.Len(t, pkt, 64)
+	case <-time.After(time.Second):
+		t.Fatal("datagram is not received")
+	}
+	require.Empty(t, errs)
+
+	require.NoError(t, face.Close())
+}
+
+func TestMulticastFace(t *testing.T) {
+	utils.SetTestingT(t)
+
+	// Find an interface supporting multicast
+	ifName := ""
+	for _, ifi := range utils.WithoutErr(net.Interfaces()) {
+		if ifi.Flags&net.FlagUp != 0 && ifi.Flags&net.FlagMulticast != 0 && ifi.Flags&net.FlagLoopback == 0 {
+			ifName = ifi.Name
+			break
+		}
+	}
+	if ifName == "" {
+		t.Skip("no multicast interface available")
+	}
+
+	const group = "224.0.23.170:56364"
+	newFace := func() (*basic_engine.MulticastFace, chan []byte) {
+		ch := make(chan []byte, 4)
+		face := basic_engine.NewMulticastFace(group, ifName)
+		face.SetCallback(func(r enc.ParseReader) error {
+			ch <- utils.WithoutErr(r.ReadBuf(r.Length()))
+			return nil
+		}, func(err error) error {
+			return err
+		})
+		if err := face.Open(); err != nil {
+			t.Skipf("unable to join multicast group: %v", err)
+		}
+		return face, ch
+	}
+	faceA, recvA := newFace()
+	defer faceA.Close()
+	faceB, recvB := newFace()
+	defer faceB.Close()
+
+	require.NoError(t, faceA.Send(enc.Wire{[]byte{0x05, 0x01, 0x0a}}))
+	require.NoError(t, faceB.Send(enc.Wire{[]byte{0x05, 0x01, 0x0b}}))
+	for _, c := range []struct {
+		ch       chan []byte
+		expected []byte
+	}{{recvB, []byte{0x05, 0x01, 0x0a}}, {recvA, []byte{0x05, 0x01, 0x0b}}} {
+		select {
+		case pkt := <-c.ch:
+			require.Equal(t, c.expected, pkt)
+		case <-time.After(time.Second):
+			t.Skip("multicast packets are not delivered in this environment")
+		}
+	}
+	// Looped-back copies of our own packets are dropped
+	select {
+	case pkt := <-recvA:
+		t.Fatalf("unexpected packet: %v", pkt)
+	case pkt := <-recvB:
+		t.Fatalf("unexpected packet: %v", pkt)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// selfSignedCert makes a self-signed certificate for 127.0.0.1.
+func selfSignedCert(t *testing.T, cn string) (tls.Certificate, *x509.Certificate) {
+	key := utils.WithoutErr(ecdsa.GenerateKey(elliptic.P256(), rand.Reader))
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der := utils.WithoutErr(x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key))
+	cert := utils.WithoutErr(x509.ParseCertificate(der))
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert}, cert
+}
+
+func TestTlsFace(t *testing.T) {
+	utils.SetTestingT(t)
+
+	serverCert, serverX509 := selfSignedCert(t, "server")
+	clientCert, clientX509 := selfSignedCert(t, "client")
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientX509)
+	listener := utils.WithoutErr(tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}))
+	defer listener.Close()
+
+	// The server echoes back every byte it receives
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(serverX509)
+	received := make(chan []byte, 4)
+	face := basic_engine.NewTlsFace("tcp", listener.Addr().String(), &tls.Config{
+		RootCAs:      rootCAs,
+		Certificates: []tls.Certificate{clientCert},
+	})
+	face.SetCallback(func(r enc.ParseReader) error {
+		received <- utils.WithoutErr(r.ReadBuf(r.Length()))
+		return nil
+	}, func(err error) error {
+		return err
+	})
+	require.NoError(t, face.Open())
+	require.False(t, face.IsLocal())
+
+	// Packets split across writes are reassembled by their TLV length
+	require.NoError(t, face.Send(enc.Wire{[]byte{0x05, 0x03, 0x01}, []byte{0x02, 0x03}}))
+	require.NoError(t, face.Send(enc.Wire{[]byte{0x06, 0xfd, 0x01, 0x00}, make([]byte, 256)}))
+	for _, expected := range [][]byte{{0x05, 0x03, 0x01, 0x02, 0x03}, append([]byte{0x06, 0xfd, 0x01, 0x00}, make([]byte, 256)...)} {
+		select {
+		case pkt := <-received:
+			require.Equal(t, expected, pkt)
+		case <-time.After(time.Second):
+			t.Fatal("packet is not received")
+		}
+	}
+	require.NoError(t, face.Close())
+
+	// The handshake fails if the server is not trusted
+	face = basic_engine.NewTlsFace("tcp", listener.Addr().String(), &tls.Config{
+		RootCAs: x509.NewCertPool(),
+	})
+	require.Error(t, face.Open())
+}
+
+func TestWebSocketFace(t *testing.T) {
+	utils.SetTestingT(t)
+
+	// The server echoes back binary messages, and drops the first connection after one message
+	conns := make(chan int, 4)
+	pings := atomic.Int32{}
+	connCount := 0
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		connCount++
+		id := connCount
+		c.SetPingHandler(func(data string) error {
+			pings.Add(1)
+			return c.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+		})
+		conns <- id
+		for {
+			typ, msg, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+			// A text message is sent before every echo and must be ignored by the face
+			c.WriteMessage(websocket.TextMessage, []byte("ignored"))
+			c.WriteMessage(typ, msg)
+			if id == 1 {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	received := make(chan []byte, 4)
+	face := basic_engine.NewWebSocketFace("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	face.SetPingInterval(20 * time.Millisecond)
+	face.SetCallback(func(r enc.ParseReader) error {
+		received <- utils.WithoutErr(r.ReadBuf(r.Length()))
+		return nil
+	}, func(err error) error {
+		return err
+	})
+	require.True(t, face.IsLocal())
+	require.NoError(t, face.Open())
+	require.Equal(t, 1, <-conns)
+
+	expectPacket := func(expected []byte) {
+		select {
+		case pkt := <-received:
+			require.Equal(t, expected, pkt)
+		case <-time.After(time.Second):
+			t.Fatal("packet is not received")
+		}
+	}
+	require.NoError(t, face.Send(enc.Wire{[]byte{0x05, 0x02}, []byte{0x01, 0x02}}))
+	expectPacket([]byte{0x05, 0x02, 0x01, 0x02})
+
+	// The face reconnects after the server drops the connection
+	select {
+	case id := <-conns:
+		require.Equal(t, 2, id)
+	case <-time.After(2 * time.Second):
+		t.Fatal("face does not reconnect")
+	}
+	require.Eventually(t, func() bool {
+		return face.Send(enc.Wire{[]byte{0x06, 0x01, 0x03}}) == nil
+	}, time.Second, 10*time.Millisecond)
+	expectPacket([]byte{0x06, 0x01, 0x03})
+	require.True(t, face.IsRunning())
+
+	// Keepalive pings are sent periodically
+	require.Eventually(t, func() bool {
+		return pings.Load() >= 2
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, face.Close())
+	require.Eventually(t, func() bool {
+		return !face.IsRunning()
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestWebSocketKeepAlive(t *testing.T) {
+	utils.SetTestingT(t)
+
+	// The server answers pings on the first connection only for a while, and on later ones always
+	conns := make(chan int, 4)
+	silent := atomic.Bool{}
+	connCount := atomic.Int32{}
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		id := int(connCount.Add(1))
+		c.SetPingHandler(func(data string) error {
+			if id == 1 && silent.Load() {
+				return nil
+			}
+			return c.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+		})
+		conns <- id
+		for {
+			if _, _, err := c.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	face := basic_engine.NewWebSocketFace("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	face.SetKeepAlive(20*time.Millisecond, 100*time.Millisecond)
+	face.SetCallback(func(r enc.ParseReader) error {
+		return nil
+	}, func(err error) error {
+		return err
+	})
+	states := make(chan basic_engine.FaceState, 16)
+	face.OnStateChange(func(old, new basic_engine.FaceState) {
+		states <- new
+	})
+	require.NoError(t, face.Open())
+	require.Equal(t, 1, <-conns)
+	require.Equal(t, basic_engine.FaceStateConnecting, <-states)
+	require.Equal(t, basic_engine.FaceStateUp, <-states)
+
+	// The connection stays up while pongs arrive
+	select {
+	case s := <-states:
+		t.Fatalf("unexpected state %v", s)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Without pongs, the face goes down after the idle timeout and reconnects
+	silent.Store(true)
+	expectState := func(expected basic_engine.FaceState) {
+		select {
+		case s := <-states:
+			require.Equal(t, expected, s)
+		case <-time.After(time.Second):
+			t.Fatalf("face does not get %v", expected)
+		}
+	}
+	expectState(basic_engine.FaceStateDown)
+	expectState(basic_engine.FaceStateConnecting)
+	expectState(basic_engine.FaceStateUp)
+	require.Equal(t, 2, <-conns)
+	require.True(t, face.IsRunning())
+	require.NoError(t, face.Send(enc.Wire{[]byte{0x06, 0x01, 0x03}}))
+
+	require.NoError(t, face.Close())
+}
+
+// mockNfd accepts connections on a unix socket, and responds to commands with status and their parameters,
+// where FaceId defaults to faceId.
+// The names of registration commands are sent to regs, and the parameters of all commands to cmds.
+// It also serves the segments of faces/list with version 7, if faces is set before serving.
+type mockNfd struct {
+	t        *testing.T
+	listener net.Listener
+	conn     net.Conn
+	conns    chan net.Conn
+	regs     chan string
+	cmds     chan *mgmt.ControlArgs
+	status   atomic.Uint64
+	faceId   uint64
+	faces    []enc.Buffer
+}
+
+func (m *mockNfd) serve() {
+	conn, err := m.listener.Accept()
+	if err != nil {
+		return
+	}
+	m.conn = conn
+	m.conns <- conn
+	r := bufio.NewReader(conn)
+	nfdPrefix := utils.WithoutErr(enc.NameFromStr("/localhost/nfd"))
+	datasetPrefix := utils.WithoutErr(enc.NameFromStr("/localhost/nfd/faces/list"))
+	for {
+		typ, err := enc.ReadTLNum(r)
+		if err != nil {
+			return
+		}
+		l, err := enc.ReadTLNum(r)
+		if err != nil {
+			return
+		}
+		buf := make([]byte, typ.EncodingLength()+l.EncodingLength()+int(l))
+		pos := typ.EncodeInto(buf)
+		pos += l.EncodeInto(buf[pos:])
+		if _, err = io.ReadFull(r, buf[pos:]); err != nil {
+			return
+		}
+		pkt, _, err := spec_2022.ReadPacket(enc.NewBufferReader(buf))
+		if err == nil && pkt.Interest != nil && datasetPrefix.IsPrefix(pkt.Interest.NameV) {
+			m.serveDataset(conn, datasetPrefix, pkt.Interest.NameV)
+			continue
+		}
+		if err != nil || pkt.Interest == nil || !nfdPrefix.IsPrefix(pkt.Interest.NameV) || len(pkt.Interest.NameV) < 5 {
+			continue
+		}
+		params, err := mgmt.ParseControlParameters(enc.NewBufferReader(pkt.Interest.NameV[4].Val), true)
+		if err != nil {
+			continue
+		}
+		m.cmds <- params.Val
+		status := m.status.Load()
+		respParams := *params.Val
+		if respParams.FaceId == nil {
+			respParams.FaceId = utils.IdPtr(m.faceId)
+		}
+		resp := &mgmt.ControlResponse{Val: &mgmt.ControlResponseVal{
+			StatusCode: status,
+			StatusText: "Mock status",
+			Params:     &respParams,
+		}}
+		data, _, err := spec_2022.Spec{}.MakeData(pkt.Interest.NameV, &ndn.DataConfig{}, resp.Encode(), sec.NewSha256Signer())
+		if err != nil {
+			return
+		}
+		conn.Write(data.Join())
+		if status == 200 && pkt.Interest.NameV[2].String() == "rib" && pkt.Interest.NameV[3].String() == "register" {
+			m.regs <- pkt.Interest.NameV.String()
+		}
+	}
+}
+
+func (m *mockNfd) serveDataset(conn net.Conn, prefix enc.Name, name enc.Name) {
+	seg := uint64(0)
+	if len(name) == len(prefix)+2 {
+		seg = name[len(prefix)+1].NumberVal()
+	}
+	if seg >= uint64(len(m.faces)) {
+		return
+	}
+	dataName := append(prefix, enc.NewVersionComponent(7), enc.NewSegmentComponent(seg))
+	data, _, err := spec_2022.Spec{}.MakeData(dataName, &ndn.DataConfig{
+		FinalBlockID: utils.IdPtr(enc.NewSegmentComponent(uint64(len(m.faces) - 1))),
+	}, enc.Wire{m.faces[seg]}, sec.NewSha256Signer())
+	require.NoError(m.t, err)
+	conn.Write(data.Join())
+}
+
+func (m *mockNfd) sendInterest(name string) {
+	wire, _, _, err := spec_2022.Spec{}.MakeInterest(
+		utils.WithoutErr(enc.NameFromStr(name)),
+		&ndn.InterestConfig{Lifetime: utils.IdPtr(time.Second)}, nil, nil)
+	require.NoError(m.t, err)
+	utils.WithoutErr(m.conn.Write(wire.Join()))
+}
+
+func newMockNfd(t *testing.T, path string) *mockNfd {
+	m := &mockNfd{
+		t:        t,
+		listener: utils.WithoutErr(net.Listen("unix", path)),
+		conns:    make(chan net.Conn, 4),
+		regs:     make(chan string, 4),
+		cmds:     make(chan *mgmt.ControlArgs, 16),
+		faceId:   256,
+	}
+	m.status.Store(200)
+	go m.serve()
+	return m
+}
+
+func TestStreamFaceReconnect(t *testing.T) {
+	utils.SetTestingT(t)
+
+	path := filepath.Join(t.TempDir(), "nfd.sock")
+	nfd := newMockNfd(t, path)
+
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool {
+		return true
+	}
+	face := basic_engine.NewStreamFace("unix", path, true)
+	face.SetReconnectPolicy(10*time.Millisecond, 50*time.Millisecond, -1)
+	timer := basic_engine.NewTimer()
+	engine := basic_engine.NewEngine(face, timer, sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(t, engine.Start())
+	defer engine.Shutdown()
+
+	hits := make(chan string, 4)
+	prefix := utils.WithoutErr(enc.NameFromStr("/test"))
+	require.NoError(t, engine.AttachHandler(prefix, func(
+		interest ndn.Interest, rawInterest enc.Wire, sigCovered enc.Wire, reply ndn.ReplyFunc, deadline time.Time,
+	) {
+		hits <- interest.Name().String()
+	}))
+	require.NoError(t, engine.RegisterRoute(prefix, ndn.RouteOptions{}))
+	<-nfd.regs
+
+	expectHit := func(expected string) {
+		select {
+		case name := <-hits:
+			require.Equal(t, expected, name)
+		case <-time.After(time.Second):
+			t.Fatal("handler is not called")
+		}
+	}
+	nfd.sendInterest("/test/a")
+	expectHit("/test/a")
+
+	// Kill the forwarder and start it again later
+	nfd.listener.Close()
+	nfd.conn.Close()
+	time.Sleep(100 * time.Millisecond)
+	nfd = newMockNfd(t, path)
+	defer nfd.listener.Close()
+
+	// The engine registers the prefix again after the face reconnects
+	select {
+	case <-nfd.regs:
+	case <-time.After(2 * time.Second):
+		t.Fatal("prefix is not registered again")
+	}
+	require.True(t, face.IsRunning())
+	nfd.sendInterest("/test/b")
+	expectHit("/test/b")
+}
+
+func TestFaceStateChange(t *testing.T) {
+	utils.SetTestingT(t)
+
+	path := filepath.Join(t.TempDir(), "nfd.sock")
+	nfd := newMockNfd(t, path)
+	defer nfd.listener.Close()
+
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool {
+		return true
+	}
+	face := basic_engine.NewStreamFace("unix", path, true)
+	face.SetReconnectPolicy(10*time.Millisecond, 50*time.Millisecond, -1)
+	timer := basic_engine.NewTimer()
+	engine := basic_engine.NewEngine(face, timer, sec.NewSha256IntSigner(timer), passAll)
+
+	type change struct{ old, new basic_engine.FaceState }
+	changes := make(chan change, 16)
+	require.NoError(t, engine.OnFaceStateChange(func(old, new basic_engine.FaceState) {
+		changes <- change{old, new}
+	}))
+	expectChanges := func(expected ...change) {
+		for _, c := range expected {
+			select {
+			case actual := <-changes:
+				require.Equal(t, c, actual)
+			case <-time.After(time.Second):
+				t.Fatalf("state change is not reported: %v -> %v", c.old, c.new)
+			}
+		}
+	}
+	require.Equal(t, basic_engine.FaceStateDown, face.State())
+
+	require.NoError(t, engine.Start())
+	expectChanges(
+		change{basic_engine.FaceStateDown, basic_engine.FaceStateConnecting},
+		change{basic_engine.FaceStateConnecting, basic_engine.FaceStateUp},
+	)
+
+	// Forced disconnect, after which the face reconnects to the same listener
+	conn := <-nfd.conns
+	go nfd.serve()
+	conn.Close()
+	expectChanges(
+		change{basic_engine.FaceStateUp, basic_engine.FaceStateDown},
+		change{basic_engine.FaceStateDown, basic_engine.FaceStateConnecting},
+		change{basic_engine.FaceStateConnecting, basic_engine.FaceStateUp},
+	)
+
+	require.NoError(t, engine.Shutdown())
+	expectChanges(change{basic_engine.FaceStateUp, basic_engine.FaceStateClosed})
+	require.Equal(t, basic_engine.FaceStateClosed, face.State())
+	select {
+	case c := <-changes:
+		t.Fatalf("unexpected state change: %v -> %v", c.old, c.new)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestStreamFaceWriteQueue(t *testing.T) {
+	utils.SetTestingT(t)
+
+	// The remote end does not read until told to
+	path := filepath.Join(t.TempDir(), "slow.sock")
+	listener := utils.WithoutErr(net.Listen("unix", path))
+	defer listener.Close()
+	conns := make(chan net.Conn, 1)
+	go func() {
+		if c, err := listener.Accept(); err == nil {
+			conns <- c
+		}
+	}()
+
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool {
+		return true
+	}
+	face := basic_engine.NewStreamFace("unix", path, true)
+	face.SetWriteQueue(4, 0)
+	timer := basic_engine.NewTimer()
+	engine := basic_engine.NewEngine(face, timer, sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(t, engine.Start())
+	defer engine.Shutdown()
+	conn := <-conns
+	defer conn.Close()
+
+	// Fill the socket buffer, and then the queue
+	pkt := enc.Wire{make([]byte, 64*1024)}
+	var err error
+	for i := 0; i < 10000 && err == nil; i++ {
+		err = face.Send(pkt)
+	}
+	require.ErrorIs(t, err, ndn.ErrWouldBlock)
+	require.Equal(t, 4, face.QueueDepth())
+	require.Equal(t, uint64(4), engine.Stats().SendQueueDepth)
+
+	// Draining the connection unblocks the sender
+	go io.Copy(io.Discard, conn)
+	require.Eventually(t, func() bool {
+		return face.QueueDepth() == 0
+	}, time.Second, time.Millisecond)
+	require.NoError(t, face.Send(pkt))
+}
+
+func TestRegisterRoute(t *testing.T) {
+	utils.SetTestingT(t)
+
+	path := filepath.Join(t.TempDir(), "nfd.sock")
+	nfd := newMockNfd(t, path)
+	defer nfd.listener.Close()
+
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool {
+		return true
+	}
+	face := basic_engine.NewStreamFace("unix", path, true)
+	timer := basic_engine.NewTimer()
+	engine := basic_engine.NewEngine(face, timer, sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(t, engine.Start())
+	defer engine.Shutdown()
+
+	prefix := utils.WithoutErr(enc.NameFromStr("/test/route"))
+	require.NoError(t, engine.RegisterRoute(prefix, ndn.RouteOptions{
+		Origin:           utils.IdPtr[uint64](0),
+		Cost:             utils.IdPtr[uint64](10),
+		Flags:            utils.IdPtr(mgmt.RouteFlagCapture),
+		ExpirationPeriod: utils.IdPtr(time.Minute),
+	}))
+	<-nfd.regs
+	args := <-nfd.cmds
+	require.True(t, prefix.Equal(args.Name))
+	require.Nil(t, args.FaceId)
+	require.Equal(t, uint64(0), *args.Origin)
+	require.Equal(t, uint64(10), *args.Cost)
+	require.Equal(t, mgmt.RouteFlagCapture, *args.Flags)
+	require.Equal(t, uint64(60000), *args.ExpirationPeriod)
+
+	require.NoError(t, engine.UnregisterRoute(prefix, ndn.RouteOptions{FaceId: utils.IdPtr[uint64](300)}))
+	args = <-nfd.cmds
+	require.True(t, prefix.Equal(args.Name))
+	require.Equal(t, uint64(300), *args.FaceId)
+	require.Nil(t, args.Cost)
+
+	nfd.status.Store(403)
+	err := engine.RegisterRoute(prefix, ndn.RouteOptions{})
+	var respErr mgmt.ErrControlResponse
+	require.ErrorAs(t, err, &respErr)
+	require.Equal(t, uint64(403), respErr.StatusCode)
+	require.Equal(t, "Mock status", respErr.StatusText)
+	args = <-nfd.cmds
+	require.Nil(t, args.Cost)
+}
+
+func TestRegisterRoutes(t *testing.T) {
+	utils.SetTestingT(t)
+
+	path := filepath.Join(t.TempDir(), "nfd.sock")
+	nfd := newMockNfd(t, path)
+	defer nfd.listener.Close()
+
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool {
+		return true
+	}
+	face := basic_engine.NewStreamFace("unix", path, true)
+	timer := basic_engine.NewTimer()
+	engine := basic_engine.NewEngine(face, timer, sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(t, engine.Start())
+	defer engine.Shutdown()
+
+	// Prefixes under another given prefix are covered by its route, wherever it is in the list
+	prefix := utils.WithoutErr(enc.NameFromStr("/test/tree"))
+	prefixes := []enc.Name{
+		utils.WithoutErr(enc.NameFromStr("/test/tree/leaf/0")),
+		prefix,
+		utils.WithoutErr(enc.NameFromStr("/test/tree/leaf/1")),
+	}
+	require.NoError(t, engine.RegisterRoutes(prefixes, ndn.RouteOptions{}))
+	<-nfd.regs
+	require.True(t, prefix.Equal((<-nfd.cmds).Name))
+	select {
+	case args := <-nfd.cmds:
+		t.Fatalf("unexpected command for %s", args.Name)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Disjoint prefixes are registered separately, and each failure is reported
+	nfd.status.Store(403)
+	prefixes = []enc.Name{
+		utils.WithoutErr(enc.NameFromStr("/test/a/x")),
+		utils.WithoutErr(enc.NameFromStr("/test/b")),
+		utils.WithoutErr(enc.NameFromStr("/test/a")),
+	}
+	err := engine.RegisterRoutes(prefixes, ndn.RouteOptions{})
+	var routesErr ndn.ErrRoutes
+	require.ErrorAs(t, err, &routesErr)
+	require.Len(t, routesErr.Errors, 3)
+	for _, itemErr := range routesErr.Errors {
+		require.ErrorIs(t, itemErr, mgmt.ErrControlResponse{StatusCode: 403, StatusText: "Mock status"})
+	}
+	names := []string{(<-nfd.cmds).Name.String(), (<-nfd.cmds).Name.String()}
+	require.ElementsMatch(t, []string{"/test/a", "/test/b"}, names)
+}
+
+func TestShutdownContext(t *testing.T) {
+	utils.SetTestingT(t)
+
+	path := filepath.Join(t.TempDir(), "nfd.sock")
+	nfd := newMockNfd(t, path)
+	defer nfd.listener.Close()
+
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool {
+		return true
+	}
+	face := basic_engine.NewStreamFace("unix", path, true)
+	timer := basic_engine.NewTimer()
+	engine := basic_engine.NewEngine(face, timer, sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(t, engine.Start())
+	defer engine.Shutdown()
+
+	prefix := utils.WithoutErr(enc.NameFromStr("/test/route"))
+	require.NoError(t, engine.RegisterRoute(prefix, ndn.RouteOptions{}))
+	<-nfd.regs
+	<-nfd.cmds
+
+	// An Interest is pending when the shutdown starts
+	name := utils.WithoutErr(enc.NameFromStr("/test/late"))
+	config := &ndn.InterestConfig{Lifetime: utils.IdPtr(5 * time.Second)}
+	wire, _, finalName, err := spec_2022.Spec{}.MakeInterest(name, config, nil, nil)
+	require.NoError(t, err)
+	results := make(chan ndn.InterestResult, 1)
+	require.NoError(t, engine.Express(finalName, config, wire,
+		func(result ndn.InterestResult, _ ndn.Data, _ enc.Wire, _ enc.Wire, _ uint64) {
+			results <- result
+		}))
+	done := make(chan error, 1)
+	go func() {
+		done <- engine.ShutdownContext(context.Background())
+	}()
+	require.Eventually(t, func() bool {
+		return errors.Is(engine.Express(finalName, config, wire, nil), ndn.ErrShuttingDown)
+	}, time.Second, time.Millisecond)
+
+	// The Data arriving during the shutdown still reaches the waiter, before the prefix is unregistered
+	select {
+	case <-nfd.cmds:
+		t.Fatal("prefix is unregistered with a pending Interest")
+	case <-done:
+		t.Fatal("engine is shut down with a pending Interest")
+	case <-time.After(50 * time.Millisecond):
+	}
+	data, _, err := spec_2022.Spec{}.MakeData(name, &ndn.DataConfig{}, enc.Wire{[]byte("late")}, sec.NewSha256Signer())
+	require.NoError(t, err)
+	utils.WithoutErr(nfd.conn.Write(data.Join()))
+	require.Equal(t, ndn.InterestResultData, <-results)
+
+	args := <-nfd.cmds
+	require.True(t, prefix.Equal(args.Name))
+	require.NoError(t, <-done)
+	require.False(t, face.IsRunning())
+
+	// The shutdown stops waiting when ctx is done, and does not send the commands to unregister prefixes
+	path = filepath.Join(t.TempDir(), "nfd.sock")
+	nfd = newMockNfd(t, path)
+	defer nfd.listener.Close()
+	face = basic_engine.NewStreamFace("unix", path, true)
+	engine = basic_engine.NewEngine(face, timer, sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(t, engine.Start())
+	require.NoError(t, engine.RegisterRoute(prefix, ndn.RouteOptions{}))
+	<-nfd.regs
+	<-nfd.cmds
+	require.NoError(t, engine.Express(finalName, config, wire, nil))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, engine.ShutdownContext(ctx), context.DeadlineExceeded)
+	require.Error(t, engine.Shutdown())
+	select {
+	case args := <-nfd.cmds:
+		t.Fatalf("unexpected command for %s", args.Name)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestCreateFace(t *testing.T) {
+	utils.SetTestingT(t)
+
+	path := filepath.Join(t.TempDir(), "nfd.sock")
+	nfd := newMockNfd(t, path)
+	defer nfd.listener.Close()
+
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool {
+		return true
+	}
+	face := basic_engine.NewStreamFace("unix", path, true)
+	timer := basic_engine.NewTimer()
+	engine := basic_engine.NewEngine(face, timer, sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(t, engine.Start())
+	defer engine.Shutdown()
+
+	uri := "udp4://192.0.2.1:6363"
+	require.Equal(t, uint64(256), utils.WithoutErr(engine.CreateFace(uri)))
+	args := <-nfd.cmds
+	require.Equal(t, uri, *args.Uri)
+	require.Nil(t, args.FaceId)
+
+	// The existing face is returned on conflict
+	nfd.status.Store(409)
+	require.Equal(t, uint64(256), utils.WithoutErr(engine.CreateFace(uri)))
+	<-nfd.cmds
+
+	nfd.status.Store(200)
+	require.NoError(t, engine.DestroyFace(300))
+	args = <-nfd.cmds
+	require.Equal(t, uint64(300), *args.FaceId)
+	require.Nil(t, args.Uri)
+
+	nfd.status.Store(400)
+	err := utils.WithErr(engine.CreateFace("invalid"))
+	require.ErrorIs(t, err, mgmt.ErrControlResponse{StatusCode: 400, StatusText: "Mock status"})
+}
+
+func TestListFaces(t *testing.T) {
+	utils.SetTestingT(t)
+
+	path := filepath.Join(t.TempDir(), "nfd.sock")
+	m := &mockNfd{
+		t:        t,
+		listener: utils.WithoutErr(net.Listen("unix", path)),
+		conns:    make(chan net.Conn, 4),
+		regs:     make(chan string, 4),
+		cmds:     make(chan *mgmt.ControlArgs, 16),
+	}
+	defer m.listener.Close()
+	// faces/list of two faces captured from NFD, split into 3 segments
+	faces := utils.WithoutErr(hex.DecodeString(
+		"8043690101720b696e7465726e616c3a2f2f810b696e7465726e616c3a2f2f840101850102860100900105910103" +
+			"970100920104930102980100940204b0950202586c0101805e690201037215756470343a2f2f3139322e302e322e" +
+			"313a363336338115756470343a2f2f3139322e302e322e323a36333633840100850100860100890222609001649101" +
+			"5a97010292017893015098010094040001000095029c406c0100"))
+	m.faces = []enc.Buffer{faces[:60], faces[60:120], faces[120:]}
+	go m.serve()
+
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool {
+		return true
+	}
+	// Datasets are fetched under /localhost even if the face is not marked local
+	face := basic_engine.NewStreamFace("unix", path, false)
+	timer := basic_engine.NewTimer()
+	engine := basic_engine.NewEngine(face, timer, sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(t, engine.Start())
+	defer engine.Shutdown()
+
+	content := utils.WithoutErr(engine.FetchStatusDataset("faces", "list"))
+	require.Equal(t, faces, content.Join())
+
+	list := utils.WithoutErr(engine.ListFaces())
+	require.Equal(t, 2, len(list))
+	require.Equal(t, uint64(1), list[0].FaceId)
+	require.Equal(t, "internal://", list[0].Uri)
+	require.Equal(t, mgmt.FaceScopeLocal, list[0].FaceScope)
+	require.Equal(t, mgmt.FacePersPermanent, list[0].FacePersistency)
+	require.Nil(t, list[0].Mtu)
+	require.Equal(t, uint64(1200), list[0].NInBytes)
+	require.Equal(t, uint64(259), list[1].FaceId)
+	require.Equal(t, "udp4://192.0.2.1:6363", list[1].Uri)
+	require.Equal(t, "udp4://192.0.2.2:6363", list[1].LocalUri)
+	require.Equal(t, uint64(8800), *list[1].Mtu)
+	require.Equal(t, uint64(90), list[1].NInData)
+	require.Equal(t, uint64(65536), list[1].NInBytes)
+	require.Equal(t, uint64(40000), list[1].NOutBytes)
+}
@@ -0,0 +1,56 @@
+package basic
+
+import (
+	"sync"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+)
+
+// validationQueueSize is the number of jobs a validation worker holds before the reader of the face blocks.
+const validationQueueSize = 64
+
+// validationPool runs validation jobs on a fixed number of workers.
+// Jobs of the same name go to the same worker, so they finish in the order they are submitted.
+type validationPool struct {
+	queues []chan func()
+	// lock prevents submitting to closed queues. Submitters hold it shared.
+	lock   sync.RWMutex
+	closed bool
+}
+
+func newValidationPool(workers int) *validationPool {
+	p := &validationPool{queues: make([]chan func(), workers)}
+	for i := range p.queues {
+		q := make(chan func(), validationQueueSize)
+		p.queues[i] = q
+		go func() {
+			for job := range q {
+				job()
+			}
+		}()
+	}
+	return p
+}
+
+// submit queues a job of name. It blocks if the worker of name is full, and drops the job if the pool is stopped.
+func (p *validationPool) submit(name enc.Name, job func()) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	if p.closed {
+		return
+	}
+	p.queues[name.Hash()%uint64(len(p.queues))] <- job
+}
+
+// stop makes the workers exit after the jobs already queued. It does not wait for them, since it may be called
+// from a job, e.g. by a callback that shuts down the engine, and waiting would deadlock that worker.
+func (p *validationPool) stop() {
+	p.lock.Lock()
+	if !p.closed {
+		p.closed = true
+		for _, q := range p.queues {
+			close(q)
+		}
+	}
+	p.lock.Unlock()
+}
@@ -5,24 +5,43 @@ package basic
 import (
 	"errors"
 	"net/url"
+	"sync"
 	"sync/atomic"
 	"syscall/js"
+	"time"
 
 	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
 	"github.com/zjkmxy/go-ndn/pkg/log"
 )
 
+// wasmIdlePacket is an NDNLP IDLE packet, i.e. an LpPacket without fragment, which forwarders drop.
+var wasmIdlePacket = []byte{0x64, 0x00}
+
+// WasmWsFace is a face over a browser WebSocket.
+// Browsers do not let scripts send WebSocket pings, so the keepalive sends NDNLP IDLE packets instead.
+// Forwarders do not answer them, so the connection is only treated as lost after an idle timeout
+// if one is set explicitly.
+// A closed connection is opened again with an increasing delay before an error is reported.
 type WasmWsFace struct {
-	network string
-	addr    string
-	local   bool
-	conn    js.Value
-	running atomic.Bool
-	onPkt   func(r enc.ParseReader) error
-	onError func(err error) error
+	network     string
+	addr        string
+	local       bool
+	lock        sync.Mutex
+	conn        js.Value
+	running     atomic.Bool
+	onPkt       func(r enc.ParseReader) error
+	onError     func(err error) error
+	onReconnect func()
+	policy      reconnectPolicy
+
+	keepAlive   time.Duration
+	idleTimeout time.Duration
+	// lastRecv is the time the last message is received, in Unix nanoseconds.
+	lastRecv atomic.Int64
 }
 
 func (f *WasmWsFace) onMessage(this js.Value, args []js.Value) any {
+	f.lastRecv.Store(time.Now().UnixNano())
 	event := args[0]
 	data := event.Get("data")
 	if !data.InstanceOf(js.Global().Get("ArrayBuffer")) {
@@ -34,8 +53,10 @@ func (f *WasmWsFace) onMessage(this js.Value, args []js.Value) any {
 	err := f.onPkt(enc.NewBufferReader(buf))
 	if err != nil {
 		f.running.Store(false)
+		f.lock.Lock()
 		f.conn.Call("close")
 		f.conn = js.Null()
+		f.lock.Unlock()
 	}
 	return nil
 }
@@ -47,40 +68,148 @@ func (f *WasmWsFace) Send(pkt enc.Wire) error {
 	l := pkt.Length()
 	arr := js.Global().Get("Uint8Array").New(int(l))
 	js.CopyBytesToJS(arr, pkt.Join())
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.conn.IsNull() {
+		return errors.New("face is not running")
+	}
 	f.conn.Call("send", arr)
 	return nil
 }
 
-func (f *WasmWsFace) Open() error {
-	if f.onError == nil || f.onPkt == nil {
-		return errors.New("face callbacks are not set")
-	}
-	if !f.conn.IsNull() {
-		return errors.New("face is already running")
-	}
+// connect opens a WebSocket and waits until it is open or closed.
+func (f *WasmWsFace) connect() (js.Value, error) {
 	u := url.URL{
 		Scheme: f.network,
 		Host:   f.addr,
 		Path:   "/",
 	}
-	ch := make(chan struct{}, 1)
+	// JS callbacks must not block, and a socket failing to open is closed after the error
+	opened := make(chan bool, 1)
 	// It seems now Go cannot handle exceptions thrown by JS
-	f.conn = js.Global().Get("WebSocket").New(u.String())
-	f.conn.Set("binaryType", "arraybuffer")
-	f.conn.Call("addEventListener", "open", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		ch <- struct{}{}
-		close(ch)
+	conn := js.Global().Get("WebSocket").New(u.String())
+	conn.Set("binaryType", "arraybuffer")
+	conn.Call("addEventListener", "open", js.FuncOf(func(this js.Value, args []js.Value) any {
+		select {
+		case opened <- true:
+		default:
+		}
 		return nil
 	}))
-	f.conn.Call("addEventListener", "message", js.FuncOf(f.onMessage))
+	conn.Call("addEventListener", "message", js.FuncOf(f.onMessage))
+	conn.Call("addEventListener", "close", js.FuncOf(func(this js.Value, args []js.Value) any {
+		select {
+		case opened <- false:
+		default:
+		}
+		f.onClose(conn)
+		return nil
+	}))
+	if !<-opened {
+		return js.Null(), errors.New("unable to open WebSocket to " + u.String())
+	}
+	f.lastRecv.Store(time.Now().UnixNano())
+	return conn, nil
+}
+
+// onClose opens the connection again if conn is the current one and the face is not closed.
+func (f *WasmWsFace) onClose(conn js.Value) {
+	f.lock.Lock()
+	current := f.running.Load() && f.conn.Equal(conn)
+	f.lock.Unlock()
+	if current {
+		go f.reconnect()
+	}
+}
+
+func (f *WasmWsFace) reconnect() {
+	logger := log.WithField("module", "WasmWsFace")
+	logger.Warn("WebSocket is closed. Reconnecting ...")
+	var c js.Value
+	err := f.policy.retry(errors.New("WebSocket is closed"), func() bool {
+		return !f.running.Load()
+	}, func() error {
+		var err error
+		c, err = f.connect()
+		if err != nil {
+			logger.Warnf("Unable to reconnect: %v", err)
+		}
+		return err
+	})
+	if err != nil {
+		f.running.Store(false)
+		f.onError(err)
+		return
+	}
+	f.lock.Lock()
+	if !f.running.Load() || c.IsUndefined() || c.IsNull() {
+		f.lock.Unlock()
+		if !c.IsUndefined() && !c.IsNull() {
+			c.Call("close")
+		}
+		return
+	}
+	f.conn = c
+	f.lock.Unlock()
+	go f.keepalive(c)
+	logger.Info("WebSocket reconnected.")
+	if f.onReconnect != nil {
+		f.onReconnect()
+	}
+}
+
+// keepalive sends IDLE packets on conn, and closes it if the idle timeout is set and nothing is received for it.
+func (f *WasmWsFace) keepalive(conn js.Value) {
+	if f.keepAlive <= 0 {
+		return
+	}
+	timeout := f.idleTimeout
+	ticker := time.NewTicker(f.keepAlive)
+	defer ticker.Stop()
+	for range ticker.C {
+		f.lock.Lock()
+		if !f.running.Load() || !f.conn.Equal(conn) {
+			f.lock.Unlock()
+			return
+		}
+		if timeout > 0 && time.Since(time.Unix(0, f.lastRecv.Load())) > timeout {
+			f.lock.Unlock()
+			log.WithField("module", "WasmWsFace").Warnf("Nothing received for %v. Closing the connection.", timeout)
+			// The close event triggers the reconnection
+			conn.Call("close")
+			return
+		}
+		arr := js.Global().Get("Uint8Array").New(len(wasmIdlePacket))
+		js.CopyBytesToJS(arr, wasmIdlePacket)
+		conn.Call("send", arr)
+		f.lock.Unlock()
+	}
+}
+
+func (f *WasmWsFace) Open() error {
+	if f.onError == nil || f.onPkt == nil {
+		return errors.New("face callbacks are not set")
+	}
+	if !f.conn.IsNull() {
+		return errors.New("face is already running")
+	}
 	log.WithField("module", "WasmWsFace").Info("Waiting for WebSocket connection ...")
-	<-ch
+	c, err := f.connect()
+	if err != nil {
+		return err
+	}
 	log.WithField("module", "WasmWsFace").Info("WebSocket connected ...")
+	f.lock.Lock()
+	f.conn = c
+	f.lock.Unlock()
 	f.running.Store(true)
+	go f.keepalive(c)
 	return nil
 }
 
 func (f *WasmWsFace) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
 	if f.conn.IsNull() {
 		return errors.New("face is not running")
 	}
@@ -104,6 +233,29 @@ func (f *WasmWsFace) SetCallback(onPkt func(r enc.ParseReader) error,
 	f.onError = onError
 }
 
+// SetKeepAlive makes the face send an IDLE packet every interval, which keeps proxies from closing an idle
+// connection. A zero interval disables the keepalive.
+// If timeout is not zero, the face also reconnects if nothing is received for timeout. Since the forwarder
+// does not answer IDLE packets, it is only safe if the application expects incoming packets more often,
+// e.g. replies to its periodic Interests. It takes effect on the next connection.
+func (f *WasmWsFace) SetKeepAlive(interval, timeout time.Duration) {
+	f.keepAlive = interval
+	f.idleTimeout = timeout
+}
+
+// SetReconnectPolicy sets how the face opens the connection again when it is closed.
+// The delay before each attempt starts at base and doubles up to max.
+// The face reports an error after maxRetries failed attempts; a negative maxRetries retries forever.
+// By default a WasmWsFace tries 5 times.
+func (f *WasmWsFace) SetReconnectPolicy(base, max time.Duration, maxRetries int) {
+	f.policy = reconnectPolicy{base: base, max: max, maxRetries: maxRetries}
+}
+
+// SetReconnectCallback sets the function called every time the connection is established again.
+func (f *WasmWsFace) SetReconnectCallback(onReconnect func()) {
+	f.onReconnect = onReconnect
+}
+
 func NewWasmWsFace(network string, addr string, local bool) *WasmWsFace {
 	return &WasmWsFace{
 		network: network,
@@ -113,5 +265,6 @@ func NewWasmWsFace(network string, addr string, local bool) *WasmWsFace {
 		onError: nil,
 		conn:    js.Null(),
 		running: atomic.Bool{},
+		policy:  defaultWsReconnectPolicy,
 	}
 }
@@ -0,0 +1,22 @@
+package dummy
+
+import "sync"
+
+// NonceSeq is a NonceSource giving consecutive Nonces, which makes the Interests of tests reproducible.
+type NonceSeq struct {
+	lock sync.Mutex
+	next uint32
+}
+
+// NewNonceSeq creates a NonceSeq starting from start.
+func NewNonceSeq(start uint32) *NonceSeq {
+	return &NonceSeq{next: start}
+}
+
+func (s *NonceSeq) NextNonce() uint32 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	ret := s.next
+	s.next++
+	return ret
+}
@@ -0,0 +1,75 @@
+package dummy
+
+import (
+	"sync"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+)
+
+// PipeFace delivers the packets sent to its peer, in order, like a point-to-point link.
+// Unlike DummyFace, it is safe for concurrent use and works with real timers.
+type PipeFace struct {
+	peer    *PipeFace
+	lock    sync.Mutex
+	queue   chan enc.Buffer
+	running bool
+	onPkt   func(r enc.ParseReader) error
+	onError func(err error) error
+}
+
+func (f *PipeFace) IsRunning() bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.running
+}
+
+func (f *PipeFace) IsLocal() bool {
+	return true
+}
+
+func (f *PipeFace) SetCallback(onPkt func(r enc.ParseReader) error, onError func(err error) error) {
+	f.onPkt = onPkt
+	f.onError = onError
+}
+
+func (f *PipeFace) Open() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.running = true
+	go func() {
+		for pkt := range f.queue {
+			f.onPkt(enc.NewBufferReader(pkt))
+		}
+	}()
+	return nil
+}
+
+func (f *PipeFace) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.running = false
+	close(f.queue)
+	return nil
+}
+
+// Send drops the packet if the peer is closed or congested.
+func (f *PipeFace) Send(pkt enc.Wire) error {
+	f.peer.lock.Lock()
+	defer f.peer.lock.Unlock()
+	if f.peer.running {
+		select {
+		case f.peer.queue <- pkt.Join():
+		default:
+		}
+	}
+	return nil
+}
+
+// NewPipeFaces creates a pair of connected faces. Each face can be opened only once.
+func NewPipeFaces() (*PipeFace, *PipeFace) {
+	a := &PipeFace{queue: make(chan enc.Buffer, 256)}
+	b := &PipeFace{queue: make(chan enc.Buffer, 256)}
+	a.peer = b
+	b.peer = a
+	return a, b
+}
@@ -9,11 +9,15 @@ import (
 type event struct {
 	t time.Time
 	f func()
+	// seq orders the events due at the same time by when they are scheduled.
+	seq uint64
 }
 
+// Timer is a timer whose clock only moves by MoveForward, so tests depending on time are deterministic.
 type Timer struct {
 	now    time.Time
 	events []event
+	seq    uint64
 	// Lock is not an very important thing because:
 	//   1. Basic engine itself is single-threaded
 	//   2. This timer is for test only, and there is a low chance for race.
@@ -32,41 +36,62 @@ func NewTimer() *Timer {
 }
 
 func (tm *Timer) Now() time.Time {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
 	return tm.now
 }
 
+// MoveForward moves the clock forward by d, and runs the events that become due in the order of their time.
+// While an event runs, the clock reads the time it is scheduled at.
+// Events scheduled by the running events are run too if they become due.
 func (tm *Timer) MoveForward(d time.Duration) {
-	events := func() []event {
-		tm.lock.Lock()
-		defer tm.lock.Unlock()
-		tm.now = tm.now.Add(d)
-		ret := make([]event, len(tm.events))
-		copy(ret, tm.events)
-		return ret
-	}()
+	tm.lock.Lock()
+	target := tm.now.Add(d)
+	tm.lock.Unlock()
 
-	// Run events
-	for i, e := range events {
-		if e.f != nil {
-			if e.t.Before(tm.now) {
-				e.f()
-				events[i].f = nil
+	// Run events one at a time, since events may schedule or cancel other events
+	for {
+		f := func() func() {
+			tm.lock.Lock()
+			defer tm.lock.Unlock()
+			next := -1
+			for i, e := range tm.events {
+				if e.f == nil || !e.t.Before(target) {
+					continue
+				}
+				if next < 0 || e.t.Before(tm.events[next].t) ||
+					(e.t.Equal(tm.events[next].t) && e.seq < tm.events[next].seq) {
+					next = i
+				}
+			}
+			if next < 0 {
+				tm.now = target
+				return nil
+			}
+			f := tm.events[next].f
+			tm.events[next].f = nil
+			if tm.events[next].t.After(tm.now) {
+				tm.now = tm.events[next].t
 			}
+			return f
+		}()
+		if f == nil {
+			return
 		}
+		f()
 	}
-
-	func() {
-		tm.lock.Lock()
-		defer tm.lock.Unlock()
-		tm.events = events
-	}()
 }
 
 func (tm *Timer) Schedule(d time.Duration, f func()) func() error {
-	t := tm.now.Add(d)
 	tm.lock.Lock()
 	defer tm.lock.Unlock()
 
+	tm.seq++
+	e := event{
+		t:   tm.now.Add(d),
+		f:   f,
+		seq: tm.seq,
+	}
 	idx := len(tm.events)
 	for i := range tm.events {
 		if tm.events[i].f == nil {
@@ -75,24 +100,18 @@ func (tm *Timer) Schedule(d time.Duration, f func()) func() error {
 		}
 	}
 	if idx == len(tm.events) {
-		tm.events = append(tm.events, event{
-			t: t,
-			f: f,
-		})
+		tm.events = append(tm.events, e)
 	} else {
-		tm.events[idx] = event{
-			t: t,
-			f: f,
-		}
+		tm.events[idx] = e
 	}
 
 	return func() error {
-		if t.Before(tm.now) {
+		tm.lock.Lock()
+		defer tm.lock.Unlock()
+		if e.t.Before(tm.now) {
 			return nil // Already past
 		}
-		if idx < len(tm.events) && tm.events[idx].t.Equal(t) && tm.events[idx].f != nil {
-			tm.lock.Lock()
-			defer tm.lock.Unlock()
+		if idx < len(tm.events) && tm.events[idx].seq == e.seq && tm.events[idx].f != nil {
 			tm.events[idx].f = nil
 			return nil
 		} else {
@@ -76,3 +76,32 @@ func TestCancel(t *testing.T) {
 	tm.MoveForward(21 * time.Second)
 	require.Equal(t, []int{1, 2, 0}, lst)
 }
+
+func TestScheduleOrder(t *testing.T) {
+	utils.SetTestingT(t)
+
+	tm := dummy.NewTimer()
+	start := tm.Now()
+	var order []int
+	var times []time.Duration
+	record := func(i int) func() {
+		return func() {
+			order = append(order, i)
+			times = append(times, tm.Now().Sub(start))
+		}
+	}
+	tm.Schedule(3*time.Second, record(3))
+	tm.Schedule(1*time.Second, record(1))
+	tm.Schedule(2*time.Second, record(2))
+	tm.Schedule(2*time.Second, record(4))
+	tm.Schedule(time.Second, func() {
+		// Due before the ones at 2s, since it is scheduled at 1s
+		tm.Schedule(500*time.Millisecond, record(5))
+	})
+	tm.MoveForward(10 * time.Second)
+	require.Equal(t, []int{1, 5, 2, 4, 3}, order)
+	require.Equal(t, []time.Duration{
+		time.Second, 1500 * time.Millisecond, 2 * time.Second, 2 * time.Second, 3 * time.Second,
+	}, times)
+	require.Equal(t, 10*time.Second, tm.Now().Sub(start))
+}
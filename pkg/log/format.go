@@ -0,0 +1,127 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Formatter formats an entry into a line of log, without the line break.
+type Formatter interface {
+	Format(e *Entry) ([]byte, error)
+}
+
+// TextFormatter formats entries as the default handler does: level, message, and then fields sorted by name.
+type TextFormatter struct{}
+
+// Format implements Formatter.
+func (TextFormatter) Format(e *Entry) ([]byte, error) {
+	var fields []field
+
+	for k, v := range e.Fields {
+		fields = append(fields, field{k, v})
+	}
+
+	sort.Sort(byName(fields))
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%5s %-25s", levelNames[e.Level], e.Message)
+
+	for _, f := range fields {
+		fmt.Fprintf(&b, " %s=%v", f.Name, f.Value)
+	}
+
+	return b.Bytes(), nil
+}
+
+// JSONFormatter formats entries as JSON objects with level, timestamp, message, and the fields at the top level.
+// A field named as one of the first three is prefixed with "fields.".
+// Values that cannot be marshalled, like functions, are formatted with fmt.
+type JSONFormatter struct{}
+
+// Format implements Formatter.
+func (JSONFormatter) Format(e *Entry) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(`{"level":"`)
+	b.WriteString(levelNames[e.Level])
+	b.WriteString(`","timestamp":"`)
+	b.WriteString(e.Timestamp.Format(time.RFC3339Nano))
+	b.WriteString(`","message":`)
+	if err := writeJSON(&b, e.Message); err != nil {
+		return nil, err
+	}
+
+	for _, name := range e.Fields.Names() {
+		key := name
+		if key == "level" || key == "timestamp" || key == "message" {
+			key = "fields." + key
+		}
+		b.WriteByte(',')
+		if err := writeJSON(&b, key); err != nil {
+			return nil, err
+		}
+		b.WriteByte(':')
+		value := e.Fields.Get(name)
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		if err := writeJSON(&b, value); err != nil {
+			if err := writeJSON(&b, fmt.Sprint(value)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// writeJSON appends the JSON encoding of v to b, or leaves b untouched on error.
+func writeJSON(b *bytes.Buffer, v interface{}) error {
+	text, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	b.Write(text)
+	return nil
+}
+
+// FormatHandler writes entries formatted by Formatter to Writer, one per line.
+type FormatHandler struct {
+	mu        sync.Mutex
+	Writer    io.Writer
+	Formatter Formatter
+}
+
+// NewFormatHandler returns a handler writing entries formatted by f to w.
+func NewFormatHandler(w io.Writer, f Formatter) Handler {
+	return &FormatHandler{
+		Writer:    w,
+		Formatter: f,
+	}
+}
+
+// HandleLog implements Handler.
+func (h *FormatHandler) HandleLog(e *Entry) error {
+	line, err := h.Formatter.Format(e)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	_, err = h.Writer.Write(append(line, '\n'))
+	return err
+}
+
+// SetFormatter sets a handler writing entries formatted by f to stderr. This is not thread-safe.
+// Unlike the default handler, the stdlib log is not used, so no date is prepended.
+func SetFormatter(f Formatter) {
+	SetHandler(NewFormatHandler(os.Stderr, f))
+}
@@ -0,0 +1,60 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONFormatter(t *testing.T) {
+	var out bytes.Buffer
+	l := &Logger{
+		Handler: NewFormatHandler(&out, JSONFormatter{}),
+		Level:   InfoLevel,
+	}
+
+	entry := l.WithField("module", "engine").WithField("count", 3)
+	entry.Infof("Interest %s sent.", "/a/b")
+	entry.WithError(fmt.Errorf("boom")).WithField("message", "shadowed").Warn("Failed \"quoted\"")
+	l.WithField("callback", func() {}).Error("unmarshallable")
+	l.Debug("dropped")
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	assert.Len(t, lines, 3)
+	objs := make([]map[string]interface{}, len(lines))
+	for i, line := range lines {
+		assert.NoError(t, json.Unmarshal([]byte(line), &objs[i]), line)
+	}
+
+	assert.Equal(t, "info", objs[0]["level"])
+	assert.Equal(t, "Interest /a/b sent.", objs[0]["message"])
+	assert.Equal(t, "engine", objs[0]["module"])
+	assert.Equal(t, float64(3), objs[0]["count"])
+	ts, err := time.Parse(time.RFC3339Nano, objs[0]["timestamp"].(string))
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), ts, time.Minute)
+
+	assert.Equal(t, "warn", objs[1]["level"])
+	assert.Equal(t, "Failed \"quoted\"", objs[1]["message"])
+	assert.Equal(t, "boom", objs[1]["error"])
+	assert.Equal(t, "shadowed", objs[1]["fields.message"])
+
+	assert.Equal(t, "error", objs[2]["level"])
+	assert.IsType(t, "", objs[2]["callback"])
+}
+
+func TestTextFormatter(t *testing.T) {
+	var out bytes.Buffer
+	l := &Logger{
+		Handler: NewFormatHandler(&out, TextFormatter{}),
+		Level:   InfoLevel,
+	}
+
+	l.WithField("module", "engine").WithField("b", 2).Info("hello")
+	assert.Equal(t, fmt.Sprintf("%5s %-25s b=2 module=engine\n", "info", "hello"), out.String())
+}
@@ -0,0 +1,87 @@
+package log
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LogEntry is the snapshot of an entry given to hooks.
+type LogEntry struct {
+	Level     Level
+	Timestamp time.Time
+	Message   string
+	Fields    Fields
+}
+
+// hook is a callback added by AddHook.
+type hook struct {
+	level Level
+	fn    func(entry LogEntry)
+}
+
+// hooks are the callbacks of a Logger.
+type hooks struct {
+	mu   sync.RWMutex
+	list []hook
+	// running has the goroutines calling hooks, whose logs do not call hooks again.
+	running sync.Map
+}
+
+// AddHook adds fn to be called with every entry of level or above, after it is handled.
+// fn is called on the goroutine logging the entry. Entries logged by fn itself do not call hooks.
+func (l *Logger) AddHook(level Level, fn func(entry LogEntry)) {
+	l.hooks.mu.Lock()
+	defer l.hooks.mu.Unlock()
+
+	l.hooks.list = append(l.hooks.list, hook{level, fn})
+}
+
+// callHooks calls the hooks interested in e.
+func (l *Logger) callHooks(e *Entry) {
+	l.hooks.mu.RLock()
+	list := l.hooks.list
+	l.hooks.mu.RUnlock()
+
+	if len(list) == 0 {
+		return
+	}
+
+	gid := goroutineID()
+	if _, loaded := l.hooks.running.LoadOrStore(gid, struct{}{}); loaded {
+		return
+	}
+	defer l.hooks.running.Delete(gid)
+
+	for _, h := range list {
+		if e.Level < h.level {
+			continue
+		}
+		// Each hook gets its own copy of the fields
+		fields := make(Fields, len(e.Fields))
+		for k, v := range e.Fields {
+			fields[k] = v
+		}
+		h.fn(LogEntry{
+			Level:     e.Level,
+			Timestamp: e.Timestamp,
+			Message:   e.Message,
+			Fields:    fields,
+		})
+	}
+}
+
+// goroutineID returns the ID of the current goroutine, from the first line of its stack trace.
+func goroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	// "goroutine 123 [running]:"
+	fields := bytes.Fields(buf[:n])
+	if len(fields) < 2 {
+		return 0
+	}
+	id, _ := strconv.ParseUint(string(fields[1]), 10, 64)
+	return id
+}
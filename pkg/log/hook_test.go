@@ -0,0 +1,70 @@
+package log
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddHook(t *testing.T) {
+	handled := []string{}
+	l := &Logger{
+		Handler: HandlerFunc(func(e *Entry) error {
+			handled = append(handled, e.Message)
+			return nil
+		}),
+		Level: InfoLevel,
+	}
+
+	entries := []LogEntry{}
+	l.AddHook(ErrorLevel, func(entry LogEntry) {
+		entries = append(entries, entry)
+		// Logs from a hook are handled, but do not call the hook again
+		l.WithField("hook", true).Error("from hook")
+	})
+
+	l.WithField("module", "engine").Info("not hooked")
+	l.WithField("module", "engine").WithField("code", 503).Error("boom")
+
+	assert.Len(t, entries, 1)
+	assert.Equal(t, ErrorLevel, entries[0].Level)
+	assert.Equal(t, "boom", entries[0].Message)
+	assert.Equal(t, Fields{"module": "engine", "code": 503}, entries[0].Fields)
+	assert.NotEmpty(t, entries[0].Timestamp)
+	assert.Equal(t, []string{"not hooked", "boom", "from hook"}, handled)
+
+	// Fatal is above error
+	l.Warn("not hooked")
+	l.log(FatalLevel, NewEntry(l), "fatal")
+	assert.Len(t, entries, 2)
+	assert.Equal(t, FatalLevel, entries[1].Level)
+}
+
+func TestAddHookConcurrent(t *testing.T) {
+	l := &Logger{
+		Handler: HandlerFunc(func(e *Entry) error { return nil }),
+		Level:   InfoLevel,
+	}
+
+	mu := sync.Mutex{}
+	count := 0
+	l.AddHook(WarnLevel, func(entry LogEntry) {
+		mu.Lock()
+		defer mu.Unlock()
+		count++
+	})
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				l.Warn("warn")
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1000, count)
+}
@@ -60,6 +60,8 @@ type Handler interface {
 type Logger struct {
 	Handler Handler
 	Level   Level
+
+	hooks hooks
 }
 
 // WithFields returns a new entry with `fields` set.
@@ -150,7 +152,9 @@ func (l *Logger) log(level Level, e *Entry, msg string) {
 		return
 	}
 
-	if err := l.Handler.HandleLog(e.finalize(level, msg)); err != nil {
+	entry := e.finalize(level, msg)
+	if err := l.Handler.HandleLog(entry); err != nil {
 		stdlog.Printf("error logging: %s", err)
 	}
+	l.callHooks(entry)
 }
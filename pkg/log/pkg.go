@@ -1,10 +1,7 @@
 package log
 
 import (
-	"bytes"
-	"fmt"
 	"log"
-	"sort"
 	"time"
 )
 
@@ -23,24 +20,8 @@ func (a byName) Less(i, j int) bool { return a[i].Name < a[j].Name }
 
 // handleStdLog outpouts to the stlib log.
 func handleStdLog(e *Entry) error {
-	level := levelNames[e.Level]
-
-	var fields []field
-
-	for k, v := range e.Fields {
-		fields = append(fields, field{k, v})
-	}
-
-	sort.Sort(byName(fields))
-
-	var b bytes.Buffer
-	fmt.Fprintf(&b, "%5s %-25s", level, e.Message)
-
-	for _, f := range fields {
-		fmt.Fprintf(&b, " %s=%v", f.Name, f.Value)
-	}
-
-	log.Println(b.String())
+	line, _ := TextFormatter{}.Format(e)
+	log.Println(string(line))
 
 	return nil
 }
@@ -73,6 +54,13 @@ func SetLevelFromString(s string) {
 	}
 }
 
+// AddHook adds fn to be called with every entry of level or above, e.g. to forward errors.
+func AddHook(level Level, fn func(entry LogEntry)) {
+	if logger, ok := Log.(*Logger); ok {
+		logger.AddHook(level, fn)
+	}
+}
+
 // WithFields returns a new entry with `fields` set.
 func WithFields(fields Fielder) *Entry {
 	return Log.WithFields(fields)
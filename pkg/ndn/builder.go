@@ -0,0 +1,191 @@
+package ndn
+
+import (
+	"crypto/sha256"
+	"time"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+)
+
+// UnsignedData is a Data built by DataBuilder, which is yet to be encoded and signed.
+type UnsignedData struct {
+	Name    enc.Name
+	Config  DataConfig
+	Content enc.Wire
+}
+
+// Encode encodes the Data with spec and signs it with signer.
+// It returns the encoded Data and the signature covered parts.
+func (d UnsignedData) Encode(spec Spec, signer Signer) (enc.Wire, enc.Wire, error) {
+	return spec.MakeData(d.Name, &d.Config, d.Content, signer)
+}
+
+// DataBuilder builds a Data without going through a schema, e.g.
+//
+//	ndn.NewData(name).Content(content).Freshness(time.Second).Build().Encode(spec, signer)
+type DataBuilder struct {
+	data UnsignedData
+}
+
+// NewData starts to build a Data of name.
+func NewData(name enc.Name) *DataBuilder {
+	return &DataBuilder{data: UnsignedData{Name: name}}
+}
+
+// Content sets the Content.
+func (b *DataBuilder) Content(content enc.Wire) *DataBuilder {
+	b.data.Content = content
+	return b
+}
+
+// Freshness sets the FreshnessPeriod.
+func (b *DataBuilder) Freshness(d time.Duration) *DataBuilder {
+	b.data.Config.Freshness = &d
+	return b
+}
+
+// FinalBlockId sets the FinalBlockId, usually the segment component of the last segment.
+func (b *DataBuilder) FinalBlockId(comp enc.Component) *DataBuilder {
+	b.data.Config.FinalBlockID = &comp
+	return b
+}
+
+// ContentType sets the ContentType.
+func (b *DataBuilder) ContentType(t ContentType) *DataBuilder {
+	b.data.Config.ContentType = &t
+	return b
+}
+
+// Build returns the Data. Further changes to the builder do not affect it.
+func (b *DataBuilder) Build() UnsignedData {
+	return b.data
+}
+
+// UnsignedInterest is an Interest built by InterestBuilder, which is yet to be encoded.
+// It implements Interest, so it can be given directly to Engine.ExpressInterestCtx.
+type UnsignedInterest struct {
+	name     enc.Name
+	config   InterestConfig
+	appParam enc.Wire
+}
+
+// Name returns the Interest name, ending with the ParametersSha256DigestComponent if there are AppParam.
+func (i UnsignedInterest) Name() enc.Name             { return i.name }
+func (i UnsignedInterest) CanBePrefix() bool          { return i.config.CanBePrefix }
+func (i UnsignedInterest) MustBeFresh() bool          { return i.config.MustBeFresh }
+func (i UnsignedInterest) ForwardingHint() []enc.Name { return i.config.ForwardingHint }
+func (i UnsignedInterest) Nonce() *uint64             { return i.config.Nonce }
+func (i UnsignedInterest) Lifetime() *time.Duration   { return i.config.Lifetime }
+func (i UnsignedInterest) HopLimit() *uint            { return i.config.HopLimit }
+func (i UnsignedInterest) AppParam() enc.Wire         { return i.appParam }
+
+// Signature returns nil, as the Interest is not signed.
+func (i UnsignedInterest) Signature() Signature { return nil }
+
+// Config returns the configuration to express the Interest.
+func (i UnsignedInterest) Config() *InterestConfig {
+	config := i.config
+	return &config
+}
+
+// Encode encodes the Interest with spec and signs it with signer if it is not nil.
+// It returns the encoded Interest, the signature covered parts, and the final name.
+func (i UnsignedInterest) Encode(spec Spec, signer Signer) (enc.Wire, enc.Wire, enc.Name, error) {
+	return spec.MakeInterest(i.name, i.Config(), i.appParam, signer)
+}
+
+// InterestBuilder builds an Interest with its selectors, e.g.
+//
+//	ndn.NewInterest(name).CanBePrefix(true).MustBeFresh(true).Lifetime(time.Second).Build()
+type InterestBuilder struct {
+	name     enc.Name
+	config   InterestConfig
+	appParam enc.Wire
+}
+
+// NewInterest starts to build an Interest of name.
+func NewInterest(name enc.Name) *InterestBuilder {
+	return &InterestBuilder{name: name}
+}
+
+// CanBePrefix sets CanBePrefix.
+func (b *InterestBuilder) CanBePrefix(v bool) *InterestBuilder {
+	b.config.CanBePrefix = v
+	return b
+}
+
+// MustBeFresh sets MustBeFresh.
+func (b *InterestBuilder) MustBeFresh(v bool) *InterestBuilder {
+	b.config.MustBeFresh = v
+	return b
+}
+
+// Lifetime sets the InterestLifetime.
+func (b *InterestBuilder) Lifetime(d time.Duration) *InterestBuilder {
+	b.config.Lifetime = &d
+	return b
+}
+
+// ForwardingHint sets the names of the ForwardingHint.
+func (b *InterestBuilder) ForwardingHint(names []enc.Name) *InterestBuilder {
+	b.config.ForwardingHint = names
+	return b
+}
+
+// HopLimit sets the HopLimit.
+func (b *InterestBuilder) HopLimit(n uint8) *InterestBuilder {
+	v := uint(n)
+	b.config.HopLimit = &v
+	return b
+}
+
+// Nonce sets the Nonce. Engines give one if it is not set.
+func (b *InterestBuilder) Nonce(nonce uint32) *InterestBuilder {
+	v := uint64(nonce)
+	b.config.Nonce = &v
+	return b
+}
+
+// AppParams sets the ApplicationParameters. The name gets a ParametersSha256DigestComponent on Build.
+func (b *InterestBuilder) AppParams(wire enc.Wire) *InterestBuilder {
+	b.appParam = wire
+	return b
+}
+
+// Build returns the Interest. Further changes to the builder do not affect it.
+func (b *InterestBuilder) Build() UnsignedInterest {
+	name := b.name
+	if n := len(name); n > 0 && name[n-1].Typ == enc.TypeParametersSha256DigestComponent {
+		name = name[:n-1]
+	}
+	if b.appParam != nil {
+		name = append(name[:len(name):len(name)], enc.Component{
+			Typ: enc.TypeParametersSha256DigestComponent,
+			Val: paramDigest(b.appParam),
+		})
+	}
+	return UnsignedInterest{
+		name:     name,
+		config:   b.config,
+		appParam: b.appParam,
+	}
+}
+
+// paramDigest returns the digest of an unsigned Interest's ApplicationParameters element.
+func paramDigest(appParam enc.Wire) []byte {
+	const typeAppParam = enc.TLNum(0x24)
+	length := 0
+	for _, buf := range appParam {
+		length += len(buf)
+	}
+	header := make(enc.Buffer, typeAppParam.EncodingLength()+enc.TLNum(length).EncodingLength())
+	pos := typeAppParam.EncodeInto(header)
+	enc.TLNum(length).EncodeInto(header[pos:])
+
+	h := sha256.New()
+	h.Write(header)
+	for _, buf := range appParam {
+		h.Write(buf)
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,86 @@
+package ndn
+
+import (
+	"errors"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+)
+
+// ErrEmptyLink is returned when a Link object has no delegation.
+var ErrEmptyLink = errors.New("Link object has no delegation name.")
+
+// EncodeLink encodes a Link object named linkName, whose content is the list of delegation names,
+// with spec and signs it with signer. The ContentType is set to Link regardless of config, which may be nil.
+// It returns the encoded Data and the signature covered parts.
+func EncodeLink(
+	names []enc.Name, linkName enc.Name, config *DataConfig, spec Spec, signer Signer,
+) (enc.Wire, enc.Wire, error) {
+	if len(names) == 0 {
+		return nil, nil, ErrEmptyLink
+	}
+	cfg := DataConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	contentType := ContentTypeLink
+	cfg.ContentType = &contentType
+
+	l := 0
+	for _, name := range names {
+		nl := name.EncodingLength()
+		l += enc.TypeName.EncodingLength() + enc.TLNum(nl).EncodingLength() + nl
+	}
+	buf := make(enc.Buffer, l)
+	pos := 0
+	for _, name := range names {
+		pos += enc.TypeName.EncodeInto(buf[pos:])
+		pos += enc.TLNum(name.EncodingLength()).EncodeInto(buf[pos:])
+		pos += name.EncodeInto(buf[pos:])
+	}
+	return spec.MakeData(linkName, &cfg, enc.Wire{buf}, signer)
+}
+
+// ParseLink returns the delegation names of a Link object.
+// Unknown non-critical elements in the content are ignored.
+func ParseLink(data Data) ([]enc.Name, error) {
+	if t := data.ContentType(); t == nil || *t != ContentTypeLink {
+		var value any = nil
+		if t != nil {
+			value = *t
+		}
+		return nil, ErrInvalidValue{Item: "ContentType", Value: value}
+	}
+	r := enc.NewWireReader(data.Content())
+	ret := make([]enc.Name, 0)
+	for r.Pos() < r.Length() {
+		typ, err := enc.ReadTLNum(r)
+		if err != nil {
+			return nil, err
+		}
+		l, err := enc.ReadTLNum(r)
+		if err != nil {
+			return nil, err
+		}
+		if int(l) > r.Length()-r.Pos() {
+			return nil, enc.ErrBufferOverflow
+		}
+		if typ != enc.TypeName {
+			if typ <= 31 || typ&1 == 1 {
+				return nil, enc.ErrUnrecognizedField{TypeNum: typ}
+			}
+			if err = r.Skip(int(l)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		name, err := enc.ReadName(r.Delegate(int(l)))
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, name)
+	}
+	if len(ret) == 0 {
+		return nil, ErrEmptyLink
+	}
+	return ret, nil
+}
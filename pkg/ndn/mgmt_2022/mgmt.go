@@ -1,10 +1,22 @@
 package mgmt_2022
 
 import (
+	"fmt"
+
 	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
 	"github.com/zjkmxy/go-ndn/pkg/ndn"
 )
 
+// ErrControlResponse is returned when the forwarder responds to a command with a status code other than 200.
+type ErrControlResponse struct {
+	StatusCode uint64
+	StatusText string
+}
+
+func (e ErrControlResponse) Error() string {
+	return fmt.Sprintf("Command failed due to error %d: %s", e.StatusCode, e.StatusText)
+}
+
 type MgmtConfig struct {
 	// local means whether NFD is of localhost
 	local bool
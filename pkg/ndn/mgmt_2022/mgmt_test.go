@@ -0,0 +1,85 @@
+package mgmt_2022_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	mgmt "github.com/zjkmxy/go-ndn/pkg/ndn/mgmt_2022"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+func TestControlParametersRoundTrip(t *testing.T) {
+	utils.SetTestingT(t)
+
+	// rib/register of /a/b with FaceId 300, Origin 255, Cost 10, Flags ChildInherit, ExpirationPeriod 60s,
+	// as encoded by ndn-cxx
+	wire := []byte("\x68\x19\x07\x06\x08\x01a\x08\x01b\x69\x02\x01\x2c\x6f\x01\xff\x6a\x01\x0a" +
+		"\x6c\x01\x01\x6d\x02\xea\x60")
+	params := utils.WithoutErr(mgmt.ParseControlParameters(enc.NewBufferReader(wire), false))
+	args := params.Val
+	require.Equal(t, "/a/b", args.Name.String())
+	require.Equal(t, uint64(300), *args.FaceId)
+	require.Equal(t, uint64(255), *args.Origin)
+	require.Equal(t, uint64(10), *args.Cost)
+	require.Equal(t, mgmt.RouteFlagChildInherit, *args.Flags)
+	require.Equal(t, uint64(60000), *args.ExpirationPeriod)
+	require.Nil(t, args.Uri)
+	require.Nil(t, args.Mask)
+	require.Nil(t, args.Strategy)
+	require.Equal(t, wire, params.Bytes())
+
+	// faces/create of udp4://192.0.2.1:6363 with Persistency permanent, Flags and Mask,
+	// as encoded by ndn-cxx
+	wire = []byte("\x68\x27\x72\x15udp4://192.0.2.1:6363\x6c\x01\x04\x70\x01\x04" +
+		"\x6b\x05\x07\x03\x08\x01s\x85\x01\x02")
+	params = utils.WithoutErr(mgmt.ParseControlParameters(enc.NewBufferReader(wire), false))
+	args = params.Val
+	require.Nil(t, args.Name)
+	require.Equal(t, "udp4://192.0.2.1:6363", *args.Uri)
+	require.Equal(t, mgmt.FaceFlagCongestionMarkingEnabled, *args.Flags)
+	require.Equal(t, mgmt.FaceFlagCongestionMarkingEnabled, *args.Mask)
+	require.Equal(t, mgmt.FacePersPermanent, *args.FacePersistency)
+	require.Equal(t, "/s", args.Strategy.Name.String())
+	require.Equal(t, wire, params.Bytes())
+}
+
+func TestControlResponseRoundTrip(t *testing.T) {
+	utils.SetTestingT(t)
+
+	// Response to rib/register of /a/b, as encoded by NFD
+	wire := []byte("\x65\x1e\x66\x01\xc8\x67\x02OK\x68\x15\x07\x06\x08\x01a\x08\x01b\x69\x02\x01\x2c" +
+		"\x6f\x01\x00\x6a\x01\x0a\x6c\x01\x01")
+	resp := utils.WithoutErr(mgmt.ParseControlResponse(enc.NewBufferReader(wire), false))
+	require.Equal(t, uint64(200), resp.Val.StatusCode)
+	require.Equal(t, "OK", resp.Val.StatusText)
+	require.Equal(t, "/a/b", resp.Val.Params.Name.String())
+	require.Equal(t, uint64(300), *resp.Val.Params.FaceId)
+	require.Equal(t, uint64(0), *resp.Val.Params.Origin)
+	require.Nil(t, resp.Val.Params.ExpirationPeriod)
+	require.Equal(t, wire, resp.Encode().Join())
+
+	// Error responses carry no body
+	wire = []byte("\x65\x0e\x66\x02\x01\x93\x67\x08Conflict")
+	resp = utils.WithoutErr(mgmt.ParseControlResponse(enc.NewBufferReader(wire), false))
+	require.Equal(t, uint64(403), resp.Val.StatusCode)
+	require.Equal(t, "Conflict", resp.Val.StatusText)
+	require.Nil(t, resp.Val.Params)
+	require.Equal(t, wire, resp.Encode().Join())
+}
+
+func TestControlParametersUnknownField(t *testing.T) {
+	utils.SetTestingT(t)
+
+	// Unknown non-critical fields are ignored
+	wire := []byte("\x68\x08\x07\x03\x08\x01a\x74\x01\x00")
+	params := utils.WithoutErr(mgmt.ParseControlParameters(enc.NewBufferReader(wire), false))
+	require.Equal(t, "/a", params.Val.Name.String())
+
+	// Unknown critical fields are errors, unless ignoreCritical is set
+	wire = []byte("\x68\x08\x07\x03\x08\x01a\x75\x01\x00")
+	err := utils.WithErr(mgmt.ParseControlParameters(enc.NewBufferReader(wire), false))
+	require.ErrorIs(t, err, enc.ErrUnrecognizedField{TypeNum: 0x75})
+	params = utils.WithoutErr(mgmt.ParseControlParameters(enc.NewBufferReader(wire), true))
+	require.Equal(t, "/a", params.Val.Name.String())
+}
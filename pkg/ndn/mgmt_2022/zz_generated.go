@@ -147,6 +147,8 @@ func (context *StrategyParsingContext) Parse(reader enc.ParseReader, ignoreCriti
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -1233,6 +1235,8 @@ func (context *ControlArgsParsingContext) Parse(reader enc.ParseReader, ignoreCr
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -1833,6 +1837,8 @@ func (context *ControlResponseValParsingContext) Parse(reader enc.ParseReader, i
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -2056,6 +2062,8 @@ func (context *ControlParametersParsingContext) Parse(reader enc.ParseReader, ig
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -2213,6 +2221,8 @@ func (context *ControlResponseParsingContext) Parse(reader enc.ParseReader, igno
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -2719,6 +2729,8 @@ func (context *FaceEventNotificationValueParsingContext) Parse(reader enc.ParseR
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -2904,6 +2916,8 @@ func (context *FaceEventNotificationParsingContext) Parse(reader enc.ParseReader
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -4362,6 +4376,8 @@ func (context *GeneralStatusParsingContext) Parse(reader enc.ParseReader, ignore
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -5561,6 +5577,8 @@ func (context *FaceStatusParsingContext) Parse(reader enc.ParseReader, ignoreCri
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -5862,6 +5880,8 @@ func (context *FaceStatusMsgParsingContext) Parse(reader enc.ParseReader, ignore
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -6364,6 +6384,8 @@ func (context *FaceQueryFilterValueParsingContext) Parse(reader enc.ParseReader,
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -6545,6 +6567,8 @@ func (context *FaceQueryFilterParsingContext) Parse(reader enc.ParseReader, igno
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -6915,6 +6939,8 @@ func (context *RouteParsingContext) Parse(reader enc.ParseReader, ignoreCritical
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -7234,6 +7260,8 @@ func (context *RibEntryParsingContext) Parse(reader enc.ParseReader, ignoreCriti
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -7467,6 +7495,8 @@ func (context *RibStatusParsingContext) Parse(reader enc.ParseReader, ignoreCrit
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -7675,6 +7705,8 @@ func (context *NextHopRecordParsingContext) Parse(reader enc.ParseReader, ignore
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -7982,6 +8014,8 @@ func (context *FibEntryParsingContext) Parse(reader enc.ParseReader, ignoreCriti
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -8215,6 +8249,8 @@ func (context *FibStatusParsingContext) Parse(reader enc.ParseReader, ignoreCrit
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -8446,6 +8482,8 @@ func (context *StrategyChoiceParsingContext) Parse(reader enc.ParseReader, ignor
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -8679,6 +8717,8 @@ func (context *StrategyChoiceMsgParsingContext) Parse(reader enc.ParseReader, ig
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -9040,6 +9080,8 @@ func (context *CsInfoParsingContext) Parse(reader enc.ParseReader, ignoreCritica
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -9213,6 +9255,8 @@ func (context *CsInfoMsgParsingContext) Parse(reader enc.ParseReader, ignoreCrit
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -5,6 +5,7 @@
 package ndn
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -35,6 +36,21 @@ const (
 	ContentTypeNack ContentType = 3
 )
 
+func (t ContentType) String() string {
+	switch t {
+	case ContentTypeBlob:
+		return "Blob"
+	case ContentTypeLink:
+		return "Link"
+	case ContentTypeKey:
+		return "Key"
+	case ContentTypeNack:
+		return "Nack"
+	default:
+		return fmt.Sprintf("ContentType(%d)", uint(t))
+	}
+}
+
 // InterestResult represents the result of Interest expression.
 // Can be Data fetched (succeeded), NetworkNack received, or Timeout.
 // Note that AppNack is considered as Data.
@@ -78,6 +94,9 @@ type Signature interface {
 	SigTime() *time.Time
 	SigSeqNum() *uint64
 	Validity() (notBefore, notAfter *time.Time)
+	// IsValidAt returns whether t is within the validity period, boundaries included.
+	// A signature without a validity period is always valid.
+	IsValidAt(t time.Time) bool
 
 	SigValue() []byte
 }
@@ -103,10 +122,28 @@ type Data interface {
 	Freshness() *time.Duration
 	FinalBlockID() *enc.Component
 	Content() enc.Wire
+	// FullName returns the name with the ImplicitSha256DigestComponent of the Data.
+	FullName() enc.Name
 
 	Signature() Signature
 }
 
+// DataContentType returns the ContentType of data, which is Blob if absent.
+func DataContentType(data Data) ContentType {
+	if t := data.ContentType(); t != nil {
+		return *t
+	}
+	return ContentTypeBlob
+}
+
+// DataFreshness returns the FreshnessPeriod of data, which is 0 if absent.
+func DataFreshness(data Data) time.Duration {
+	if f := data.Freshness(); f != nil {
+		return *f
+	}
+	return 0
+}
+
 // InterestConfig is used to create a Interest.
 type InterestConfig struct {
 	CanBePrefix    bool
@@ -115,9 +152,51 @@ type InterestConfig struct {
 	Nonce          *uint64
 	Lifetime       *time.Duration
 	HopLimit       *uint
+
+	// The following fields are not encoded, but used by the engine when expressing the Interest.
+
+	// Timeout is how long the engine waits for the first try before retransmission. Defaults to Lifetime.
+	// The wait doubles with every retransmission.
+	Timeout *time.Duration
+	// Retries is the number of retransmissions with new Nonces before the Interest times out.
+	Retries int
+}
+
+// BatchResult is the result of an Interest expressed in a batch.
+type BatchResult struct {
+	Result     InterestResult
+	Data       Data
+	NackReason uint64
+	// Err is set if the Interest is not expressed, or the batch is aborted before the Interest finishes.
+	Err error
+}
+
+// InterestToken refers to an Interest expressed by an engine.
+type InterestToken interface {
+	// Cancel removes the Interest from the PIT, so its callback is never called.
+	// It returns false if the Interest is not pending anymore, in which case the callback has been called.
+	Cancel() bool
+}
+
+// RouteOptions are the optional parameters of a route registered to the forwarder.
+// Unset fields are decided by the forwarder.
+type RouteOptions struct {
+	// FaceId is the face the route points to. Defaults to the face sending the command.
+	FaceId *uint64
+	// Origin is who announces the route, e.g. 0 for applications and 255 for static routes.
+	Origin *uint64
+	// Cost is the routing cost.
+	Cost *uint64
+	// Flags are the route inheritance flags, i.e. ChildInherit (1) and Capture (2).
+	Flags *uint64
+	// ExpirationPeriod is how long the route lasts. The route never expires if unset.
+	ExpirationPeriod *time.Duration
 }
 
 // Interest is the abstract of a received Interest packet.
+// Following NDN packet format v0.3, the only selectors are CanBePrefix and MustBeFresh.
+// The Selectors of v0.2, like MinSuffixComponents, MaxSuffixComponents and Exclude, are not supported.
+// They have a critical type number, so spec_2022 rejects an Interest carrying them.
 type Interest interface {
 	Name() enc.Name
 	CanBePrefix() bool
@@ -131,6 +210,21 @@ type Interest interface {
 	Signature() Signature
 }
 
+// DataSatisfies returns whether data satisfies interest by the name.
+// Without CanBePrefix, the names must be equal. With CanBePrefix, the Data may have any number of extra components.
+// An Interest name ending with an ImplicitSha256DigestComponent must equal the full name of the Data.
+// MustBeFresh is not checked, since it depends on how long the Data has been stored.
+func DataSatisfies(interest Interest, data Data) bool {
+	name := interest.Name()
+	if l := len(name); l > 0 && name[l-1].Typ == enc.TypeImplicitSha256DigestComponent {
+		return name.Equal(data.FullName())
+	}
+	if interest.CanBePrefix() {
+		return name.IsPrefix(data.Name())
+	}
+	return name.Equal(data.Name())
+}
+
 // Spec represents an NDN packet specification.
 type Spec interface {
 	// MakeData creates a Data packet, returns the encoded Data, signature covered parts, and error.
@@ -168,6 +262,34 @@ type InterestHandler func(
 // Create a go routine for time consuming jobs.
 type SigChecker func(name enc.Name, sigCovered enc.Wire, sig Signature) bool
 
+// Validator decides whether received packets under a namespace are trusted.
+// It is called on the thread reading the face unless the engine validates on workers, so it should not block.
+type Validator interface {
+	// ValidateData returns whether a received Data is trusted.
+	ValidateData(data Data, sigCovered enc.Wire, sig Signature) bool
+	// ValidateInterest returns whether a received Interest is trusted.
+	ValidateInterest(interest Interest, sigCovered enc.Wire, sig Signature) bool
+}
+
+// SigCheckerValidator is a Validator checking both Data and Interests with a SigChecker.
+type SigCheckerValidator SigChecker
+
+func (v SigCheckerValidator) ValidateData(data Data, sigCovered enc.Wire, sig Signature) bool {
+	return v(data.Name(), sigCovered, sig)
+}
+
+func (v SigCheckerValidator) ValidateInterest(interest Interest, sigCovered enc.Wire, sig Signature) bool {
+	return v(interest.Name(), sigCovered, sig)
+}
+
+// SignerSelector picks the signer to be used for a packet name, e.g. a key chain.
+type SignerSelector interface {
+	// SignerForName returns the signer for name, or nil if there is none.
+	SignerForName(name enc.Name) Signer
+}
+
+// Timer is the clock of an engine and the components using it, such as the schema.
+// basic_engine.NewTimer gives the real clock, while dummy.Timer only moves when a test says so.
 type Timer interface {
 	// Now returns current time.
 	Now() time.Time
@@ -180,6 +302,13 @@ type Timer interface {
 	Nonce() []byte
 }
 
+// NonceSource gives the Nonces of Interests. The default one of an engine is random,
+// while tests may use a deterministic sequence.
+type NonceSource interface {
+	// NextNonce returns the next Nonce.
+	NextNonce() uint32
+}
+
 // Engine represents a running NDN App low-level engine.
 // Used by NTSchema.
 type Engine interface {
@@ -190,19 +319,39 @@ type Engine interface {
 	// Timer returns a Timer managed by the engine.
 	Timer() Timer
 	// AttachHandler attaches an Interest handler to the namespace of prefix.
-	// Interest handlers are required to have non-overlapping namespace.
-	// That is, one handler's prefix must not be the prefix of another handler's prefix.
+	// Handlers may be attached at overlapping prefixes, e.g. /a and /a/b, and an Interest goes to the handler
+	// attached at the longest prefix of its name. Only one handler can be attached at the same prefix.
 	AttachHandler(prefix enc.Name, handler InterestHandler) error
 	// DetachHandler detaches an Interest handler from the namespace of prefix.
 	DetachHandler(prefix enc.Name) error
-	// RegisterRoute registers a route of prefix to the local forwarder.
-	RegisterRoute(prefix enc.Name) error
-	// UnregisterRoute unregisters a route of prefix to the local forwarder.
-	UnregisterRoute(prefix enc.Name) error
+	// SignerForName returns the signer that producers should use for the Data name.
+	// It returns nil if no key chain is set or no key covers the name.
+	SignerForName(name enc.Name) Signer
+	// RegisterRoute registers a route of prefix to the local forwarder, and waits for the response.
+	RegisterRoute(prefix enc.Name, opts RouteOptions) error
+	// RegisterRoutes registers the routes of prefixes with the same opts, and waits for all responses.
+	// Prefixes covered by a shorter one given are not registered separately.
+	// If any prefix fails, the error is an ErrRoutes.
+	RegisterRoutes(prefixes []enc.Name, opts RouteOptions) error
+	// UnregisterRoute unregisters a route of prefix from the local forwarder, and waits for the response.
+	// Only FaceId and Origin of opts are used to identify the route.
+	UnregisterRoute(prefix enc.Name, opts RouteOptions) error
 	// Express expresses an Interest, with callback called when there is result.
 	// To simplify the implementation, finalName needs to be the final Interest name given by MakeInterest.
 	// The callback should create go routine or channel back to another routine to avoid blocking the main thread.
+	// An Interest identical to a pending one, i.e. the same name, CanBePrefix and MustBeFresh, is not sent again
+	// if it expires no later; the pending one brings the Data back to both.
 	Express(finalName enc.Name, config *InterestConfig, rawInterest enc.Wire, callback ExpressCallbackFunc) error
+	// ExpressCancelable expresses an Interest as Express does, and returns a token to cancel it.
+	ExpressCancelable(
+		finalName enc.Name, config *InterestConfig, rawInterest enc.Wire, callback ExpressCallbackFunc,
+	) (InterestToken, error)
+	// ExpressInterestCtx expresses an Interest and blocks until the Data arrives, the Interest fails,
+	// or ctx is done. It returns ErrNack for a Nack and ErrDeadlineExceed for a timeout.
+	ExpressInterestCtx(ctx context.Context, interest Interest) (Data, error)
+	// ExpressBatch expresses interests with at most window of them pending, and waits for all of them.
+	// The results are in the order of interests. If ctx is done, the whole batch is aborted.
+	ExpressBatch(ctx context.Context, interests []Interest, window int) ([]BatchResult, error)
 }
 
 type ErrInvalidValue struct {
@@ -228,11 +377,54 @@ var ErrFailedToEncode = errors.New("Failed to encode an NDN packet.")
 // ErrWrongType is returned when the type of the packet to parse is not expected.
 var ErrWrongType = errors.New("Packet to parse is not of desired type.")
 
-// ErrPrefixPropViolation is returned when the prefix property is violated during handler registration.
-var ErrPrefixPropViolation = errors.New("A prefix or extention of the given handler prefix is already attached.")
+// ErrPrefixPropViolation is returned when a handler is already attached at the prefix to attach.
+var ErrPrefixPropViolation = errors.New("A handler is already attached at the given prefix.")
 
 // ErrDeadlineExceed is returned when the deadline of the Interest passed.
 var ErrDeadlineExceed = errors.New("Interest deadline exceeded.")
 
+// ErrNack is returned when an Interest is nacked by the network.
+type ErrNack struct {
+	Reason uint64
+}
+
+func (e ErrNack) Error() string {
+	return fmt.Sprintf("Interest is nacked with reason %d", e.Reason)
+}
+
+// ErrRoutes is returned by RegisterRoutes when some of the prefixes fail to register.
+// Errors holds the error of each prefix in the order given, which is nil for the registered ones.
+type ErrRoutes struct {
+	Errors []error
+}
+
+func (e ErrRoutes) Error() string {
+	failed := e.Unwrap()
+	if len(failed) == 0 {
+		return "no route failed"
+	}
+	return fmt.Sprintf("%d of %d routes failed to register: %v", len(failed), len(e.Errors), failed[0])
+}
+
+// Unwrap returns the errors of the failed prefixes.
+func (e ErrRoutes) Unwrap() []error {
+	ret := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		if err != nil {
+			ret = append(ret, err)
+		}
+	}
+	return ret
+}
+
+// ErrDuplicateNonce is returned when an Interest has the same name and Nonce as a pending one.
+var ErrDuplicateNonce = errors.New("An Interest with the same name and Nonce is pending.")
+
+// ErrShuttingDown is returned when the engine is shutting down and does not express new Interests.
+var ErrShuttingDown = errors.New("Engine is shutting down.")
+
 // ErrFaceDown is returned when the face is closed.
 var ErrFaceDown = errors.New("Face is down. Unable to send packet.")
+
+// ErrWouldBlock is returned when the write queue of a face is full, so the packet is not sent.
+var ErrWouldBlock = errors.New("Write queue of the face is full. Unable to send packet.")
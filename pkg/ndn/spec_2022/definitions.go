@@ -64,6 +64,8 @@ type SignatureInfo struct {
 	AdditionalDescription *CertAdditionalDescription `tlv:"0x0102"`
 }
 
+// Reason codes of Network Nacks.
+// NackReasonNone is also given to a Nack without the reason field, which means the reason is unspecified.
 const (
 	NackReasonNone       = uint64(0)
 	NackReasonCongestion = uint64(50)
@@ -150,6 +152,10 @@ type Interest struct {
 
 	//+field:rangeMarker:digestCoverStart:digestCovered
 	digestCoverEnd enc.PlaceHolder
+
+	// UnknownFields are the unrecognized non-critical fields, kept so the Interest can be forwarded as is.
+	//+field:unknown
+	UnknownFields []enc.UnknownField
 }
 
 //+tlv-model:nocopy,private
@@ -169,6 +175,15 @@ type Data struct {
 	SignatureInfo *SignatureInfo `tlv:"0x16"`
 	//+field:signature:sigCoverStart:sigCovered
 	SignatureValue enc.Wire `tlv:"0x17"`
+
+	// UnknownFields are the unrecognized non-critical fields, kept so the signature stays valid on re-encoding.
+	//+field:unknown
+	UnknownFields []enc.UnknownField
+
+	// raw is the wire of a parsed Data, used to compute the full name.
+	raw enc.Wire
+	// signedPortion is the portion of a parsed Data covered by the signature.
+	signedPortion enc.Wire
 }
 
 //+tlv-model:nocopy,private
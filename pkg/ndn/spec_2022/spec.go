@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"errors"
+	"fmt"
 	"time"
 
 	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
@@ -84,6 +85,18 @@ func (d *Data) Validity() (notBefore, notAfter *time.Time) {
 	}
 }
 
+func (d *Data) IsValidAt(t time.Time) bool {
+	if d.SignatureInfo == nil || d.SignatureInfo.ValidityPeriod == nil {
+		return true
+	}
+	// A malformed validity period is never valid
+	notBefore, notAfter := d.Validity()
+	if notBefore == nil || notAfter == nil {
+		return false
+	}
+	return !t.Before(*notBefore) && !t.After(*notAfter)
+}
+
 func (d *Data) SigValue() []byte {
 	if d.SignatureValue == nil {
 		return nil
@@ -134,6 +147,45 @@ func (d *Data) Content() enc.Wire {
 	return d.ContentV
 }
 
+// FullName returns the name of the Data with the ImplicitSha256DigestComponent,
+// which is the SHA-256 digest of the whole Data packet.
+// For a Data not parsed from a wire, the digest is computed over its encoding.
+func (d *Data) FullName() enc.Name {
+	wire := d.raw
+	if wire == nil {
+		wire = d.encode()
+	}
+	h := sha256.New()
+	for _, buf := range wire {
+		h.Write(buf)
+	}
+	ret := make(enc.Name, len(d.NameV), len(d.NameV)+1)
+	copy(ret, d.NameV)
+	return append(ret, enc.Component{Typ: enc.TypeImplicitSha256DigestComponent, Val: h.Sum(nil)})
+}
+
+// SigCovered returns the portion of the Data covered by its signature.
+// It is nil for a Data not parsed from a wire.
+func (d *Data) SigCovered() enc.Wire {
+	return d.signedPortion
+}
+
+// encode encodes the Data with its current SignatureValue.
+func (d *Data) encode() enc.Wire {
+	packet := &Packet{Data: d}
+	encoder := PacketEncoder{
+		Data_encoder: DataEncoder{
+			SignatureValue_estLen: uint(d.SignatureValue.Length()),
+		},
+	}
+	encoder.Init(packet)
+	wire := encoder.Encode(packet)
+	if idx := encoder.Data_encoder.SignatureValue_wireIdx; idx >= 0 {
+		wire[idx] = d.SignatureValue.Join()
+	}
+	return wire
+}
+
 func (t *Interest) SigType() ndn.SigType {
 	if t.SignatureInfo == nil {
 		return ndn.SignatureNone
@@ -178,6 +230,10 @@ func (t *Interest) Validity() (notBefore, notAfter *time.Time) {
 	return nil, nil
 }
 
+func (t *Interest) IsValidAt(time.Time) bool {
+	return true
+}
+
 func (t *Interest) SigValue() []byte {
 	return t.SignatureValue.Join()
 }
@@ -225,10 +281,47 @@ func (t *Interest) HopLimit() *uint {
 	}
 }
 
+// DecrementHopLimit decrements the HopLimit before the Interest is forwarded.
+// It returns true if the HopLimit is or becomes 0, in which case the Interest must be dropped.
+// An Interest without HopLimit has no limit and is left unchanged.
+// HopLimit is not covered by the signature, so the Interest can be encoded again afterwards.
+func (t *Interest) DecrementHopLimit() (dropped bool) {
+	if t.HopLimitV == nil {
+		return false
+	}
+	if *t.HopLimitV == 0 {
+		return true
+	}
+	*t.HopLimitV -= 1
+	return *t.HopLimitV == 0
+}
+
 func (t *Interest) AppParam() enc.Wire {
 	return t.ApplicationParameters
 }
 
+// fillSigValue puts sigVal into the SignatureValue placeholder of estSigLen bytes at wire[sigIdx],
+// and fixes the TLV length at the end of wire[sigIdx-1].
+// It returns how many bytes the packet shrinks.
+func fillSigValue(wire enc.Wire, sigIdx int, estSigLen int, sigVal []byte) int {
+	wire[sigIdx] = sigVal
+	if len(sigVal) == estSigLen {
+		return 0
+	}
+	buf := wire[sigIdx-1]
+	oldL := enc.TLNum(estSigLen).EncodingLength()
+	newL := enc.TLNum(len(sigVal)).EncodingLength()
+	if oldL == newL {
+		enc.TLNum(len(sigVal)).EncodeInto(buf[len(buf)-oldL:])
+	} else {
+		newBuf := make(enc.Buffer, len(buf)-oldL+newL)
+		copy(newBuf, buf[:len(buf)-oldL])
+		enc.TLNum(len(sigVal)).EncodeInto(newBuf[len(buf)-oldL:])
+		wire[sigIdx-1] = newBuf
+	}
+	return estSigLen - len(sigVal) + oldL - newL
+}
+
 func (_ Spec) MakeData(
 	name enc.Name, config *ndn.DataConfig, content enc.Wire, signer ndn.Signer,
 ) (enc.Wire, enc.Wire, error) {
@@ -335,14 +428,11 @@ func (_ Spec) MakeData(
 		if len(sigVal) > estSigLen {
 			return nil, nil, ndn.ErrNotSupported{Item: "Too long signature value is not supported"}
 		}
-		wire[encoder.Data_encoder.SignatureValue_wireIdx] = sigVal
-		// Fix SignatureValue length
-		buf := wire[encoder.Data_encoder.SignatureValue_wireIdx-1]
-		buf[len(buf)-1] = byte(len(sigVal))
-		// TODO: This needs to be fixed for estSigLen >= 253 (urgent)
-		// Fix packet length
-		shrink := estSigLen - len(sigVal)
-		wire[0] = enc.ShrinkLength(wire[0], shrink)
+		shrink := fillSigValue(wire, encoder.Data_encoder.SignatureValue_wireIdx, estSigLen, sigVal)
+		if shrink > 0 {
+			// Fix packet length
+			wire[0] = enc.ShrinkLength(wire[0], shrink)
+		}
 		// }
 	}
 	return wire, sigCovered, nil
@@ -361,6 +451,8 @@ func (Spec) ReadData(reader enc.ParseReader) (ndn.Data, enc.Wire, error) {
 	if ret.Data.NameV == nil {
 		return nil, nil, ndn.ErrInvalidValue{Item: "Data.Name", Value: nil}
 	}
+	ret.Data.raw = reader.Range(0, reader.Length())
+	ret.Data.signedPortion = context.Data_context.sigCovered
 	return ret.Data, context.Data_context.sigCovered, nil
 }
 
@@ -396,9 +488,33 @@ func (_ Spec) MakeInterest(
 		Interest: interest,
 	}
 
+	if err := checkImplicitDigest(name, config.CanBePrefix); err != nil {
+		return nil, nil, nil, err
+	}
+
 	needDigest := appParam != nil
 	estSigLen := 0
 
+	// A ParametersSha256DigestComponent can only be the last one, and is recomputed
+	for i, c := range name {
+		if c.Typ != enc.TypeParametersSha256DigestComponent {
+			continue
+		}
+		if i != len(name)-1 {
+			return nil, nil, nil, enc.ErrFormat{
+				Msg: fmt.Sprintf("ParametersSha256DigestComponent must be the last component: %s", name),
+			}
+		}
+		if !needDigest {
+			return nil, nil, nil, enc.ErrFormat{
+				Msg: fmt.Sprintf("ParametersSha256DigestComponent given without ApplicationParameters: %s", name),
+			}
+		}
+		// Drop the stale digest. Limit the capacity so that the caller's name is not overwritten.
+		name = name[:i:i]
+		interest.NameV = name
+	}
+
 	// Fill-in SignatureInfo.
 	if signer != nil {
 		sigConfig, err := signer.SigInfo()
@@ -439,9 +555,6 @@ func (_ Spec) MakeInterest(
 				interest.SignatureInfo.SignatureTime = &t
 			}
 			estSigLen = int(signer.EstimateSize())
-			if estSigLen >= 253 {
-				return nil, nil, nil, ndn.ErrNotSupported{Item: "Too long signature value is not supported"}
-			}
 		}
 	}
 
@@ -458,8 +571,8 @@ func (_ Spec) MakeInterest(
 	if wire == nil {
 		return nil, nil, nil, ndn.ErrFailedToEncode
 	}
-	sigVal := []byte(nil)
 	err := error(nil)
+	shrink := 0
 	sigCovered := enc.Wire(nil)
 	if estSigLen > 0 {
 		// Compute signature
@@ -469,19 +582,15 @@ func (_ Spec) MakeInterest(
 			return nil, nil, nil, enc.ErrUnexpected{Err: errors.New("SignatureValue is not correctly set")}
 		}
 
-		sigVal, err = signer.ComputeSigValue(sigCovered)
+		sigVal, err := signer.ComputeSigValue(sigCovered)
 		if err != nil {
 			return nil, nil, nil, err
 		}
 		if uint(len(sigVal)) > ecdr.SignatureValue_estLen {
 			return nil, nil, nil, ndn.ErrNotSupported{Item: "Too long signature value is not supported"}
 		}
-		wire[ecdr.SignatureValue_wireIdx] = sigVal
-		// Fix SignatureValue length
-		buf := wire[ecdr.SignatureValue_wireIdx-1]
-		buf[len(buf)-1] = byte(len(sigVal))
-
 		// Don't fix packet length for now, as it may cause trouble
+		shrink = fillSigValue(wire, ecdr.SignatureValue_wireIdx, estSigLen, sigVal)
 	}
 	finalName := packet.Interest.NameV
 	if needDigest {
@@ -511,19 +620,38 @@ func (_ Spec) MakeInterest(
 	}
 
 	// Fix packet length
-	shrink := estSigLen - len(sigVal)
 	if shrink > 0 {
 		wire[0] = enc.ShrinkLength(wire[0], shrink)
-	} else if shrink < 0 {
-		return nil, nil, nil, ndn.ErrNotSupported{Item: "Too long signature value is not supported"}
 	}
 	return wire, sigCovered, finalName, nil
 }
 
+// checkImplicitDigest checks that an ImplicitSha256DigestComponent in an Interest name is the last one.
+// Such an Interest asks for exactly one Data, so it cannot have CanBePrefix.
+func checkImplicitDigest(name enc.Name, canBePrefix bool) error {
+	for i, c := range name {
+		if c.Typ != enc.TypeImplicitSha256DigestComponent {
+			continue
+		}
+		if i != len(name)-1 || len(c.Val) != sha256.Size {
+			return enc.ErrFormat{
+				Msg: fmt.Sprintf("ImplicitSha256DigestComponent must be the last component of 32 bytes: %s", name),
+			}
+		}
+		if canBePrefix {
+			return ndn.ErrInvalidValue{Item: "Interest.CanBePrefix", Value: canBePrefix}
+		}
+	}
+	return nil
+}
+
 func checkInterest(val *Interest, context *InterestParsingContext) error {
 	if val.NameV == nil {
 		return ndn.ErrInvalidValue{Item: "Interest.Name", Value: nil}
 	}
+	if err := checkImplicitDigest(val.NameV, val.CanBePrefixV); err != nil {
+		return err
+	}
 	if val.SignatureValue != nil && val.ApplicationParameters == nil {
 		return enc.ErrIncorrectDigest
 	}
@@ -595,6 +723,8 @@ func ReadPacket(reader enc.ParseReader) (*Packet, *PacketParsingContext, error)
 		if ret.Data.NameV == nil {
 			return nil, nil, ndn.ErrInvalidValue{Item: "Data.Name", Value: nil}
 		}
+		ret.Data.raw = reader.Range(0, reader.Length())
+		ret.Data.signedPortion = context.Data_context.sigCovered
 	} else if ret.Interest != nil {
 		err = checkInterest(ret.Interest, &context.Interest_context)
 		if err != nil {
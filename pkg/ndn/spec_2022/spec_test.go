@@ -1,6 +1,10 @@
 package spec_2022_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/sha256"
 	"encoding/hex"
 	"testing"
@@ -85,6 +89,50 @@ func TestMakeDataBasic(t *testing.T) {
 		wire.Join())
 }
 
+func TestDataBuilder(t *testing.T) {
+	utils.SetTestingT(t)
+
+	spec := spec_2022.Spec{}
+	name := utils.WithoutErr(enc.NameFromStr("/a/b"))
+
+	builder := ndn.NewData(name).
+		Content(enc.Wire{[]byte("hi")}).
+		Freshness(time.Second).
+		FinalBlockId(enc.NewSegmentComponent(3)).
+		ContentType(ndn.ContentTypeKey)
+	wire, sigCovered, err := builder.Build().Encode(spec, nil)
+	require.NoError(t, err)
+	require.Nil(t, sigCovered)
+	require.Equal(t, []byte(
+		"\x06\x1a\x07\x06\x08\x01a\x08\x01b"+
+			"\x14\x0c\x18\x01\x02\x19\x02\x03\xe8\x1a\x03\x32\x01\x03"+
+			"\x15\x02hi"),
+		wire.Join())
+
+	data, _, err := spec.ReadData(enc.NewWireReader(wire))
+	require.NoError(t, err)
+	require.Equal(t, ndn.ContentTypeKey, *data.ContentType())
+	require.Equal(t, enc.NewSegmentComponent(3), *data.FinalBlockID())
+	require.Equal(t, time.Second, *data.Freshness())
+
+	// Same as MakeData with the same config
+	signer := security.NewSha256Signer()
+	built := ndn.NewData(name).Content(enc.Wire{[]byte("01020304")}).ContentType(ndn.ContentTypeBlob).Build()
+	wire, sigCovered, err = built.Encode(spec, signer)
+	require.NoError(t, err)
+	expWire, expCovered, err := spec.MakeData(name, &ndn.DataConfig{ContentType: utils.IdPtr(ndn.ContentTypeBlob)},
+		enc.Wire{[]byte("01020304")}, signer)
+	require.NoError(t, err)
+	require.Equal(t, expWire.Join(), wire.Join())
+	require.Equal(t, expCovered.Join(), sigCovered.Join())
+
+	// A built Data is not changed by the builder later
+	builder.ContentType(ndn.ContentTypeBlob)
+	first := builder.Build()
+	builder.ContentType(ndn.ContentTypeNack)
+	require.Equal(t, ndn.ContentTypeBlob, *first.Config.ContentType)
+}
+
 func TestMakeDataMetaInfo(t *testing.T) {
 	utils.SetTestingT(t)
 	spec := spec_2022.Spec{}
@@ -251,6 +299,116 @@ func TestReadDataMetaInfo(t *testing.T) {
 	require.Equal(t, sig, data.Signature().SigValue())
 }
 
+func TestDataMetaInfoAccessors(t *testing.T) {
+	utils.SetTestingT(t)
+	spec := spec_2022.Spec{}
+	name := utils.WithoutErr(enc.NameFromStr("/app/data/seg=3"))
+
+	for _, typ := range []ndn.ContentType{
+		ndn.ContentTypeBlob, ndn.ContentTypeLink, ndn.ContentTypeKey, ndn.ContentTypeNack, ndn.ContentType(42),
+	} {
+		wire, _, err := spec.MakeData(name, &ndn.DataConfig{
+			ContentType:  utils.IdPtr(typ),
+			Freshness:    utils.IdPtr(1500 * time.Millisecond),
+			FinalBlockID: utils.IdPtr(enc.NewSegmentComponent(9)),
+		}, enc.Wire{[]byte("content")}, security.NewSha256Signer())
+		require.NoError(t, err)
+		data, _, err := spec.ReadData(enc.NewWireReader(wire))
+		require.NoError(t, err)
+		require.Equal(t, typ, *data.ContentType())
+		require.Equal(t, typ, ndn.DataContentType(data))
+		require.Equal(t, 1500*time.Millisecond, *data.Freshness())
+		require.Equal(t, 1500*time.Millisecond, ndn.DataFreshness(data))
+		require.Equal(t, enc.NewSegmentComponent(9), *data.FinalBlockID())
+		require.Equal(t, []byte("content"), data.Content().Join())
+	}
+	require.Equal(t, "Blob", ndn.ContentTypeBlob.String())
+	require.Equal(t, "Link", ndn.ContentTypeLink.String())
+	require.Equal(t, "Key", ndn.ContentTypeKey.String())
+	require.Equal(t, "Nack", ndn.ContentTypeNack.String())
+	require.Equal(t, "ContentType(42)", ndn.ContentType(42).String())
+
+	// Absent fields take their default values
+	wire, _, err := spec.MakeData(name, &ndn.DataConfig{}, enc.Wire{[]byte("content")}, security.NewSha256Signer())
+	require.NoError(t, err)
+	data, _, err := spec.ReadData(enc.NewWireReader(wire))
+	require.NoError(t, err)
+	require.Nil(t, data.ContentType())
+	require.Equal(t, ndn.ContentTypeBlob, ndn.DataContentType(data))
+	require.Nil(t, data.Freshness())
+	require.Equal(t, time.Duration(0), ndn.DataFreshness(data))
+	require.Nil(t, data.FinalBlockID())
+}
+
+func TestLink(t *testing.T) {
+	utils.SetTestingT(t)
+	spec := spec_2022.Spec{}
+
+	linkName := utils.WithoutErr(enc.NameFromStr("/alice/LINK/v=1"))
+	names := []enc.Name{
+		utils.WithoutErr(enc.NameFromStr("/ndn/edu/ucla")),
+		utils.WithoutErr(enc.NameFromStr("/ndn/mobile")),
+		utils.WithoutErr(enc.NameFromStr("/isp/seg=7")),
+	}
+	wire, _, err := ndn.EncodeLink(names, linkName, &ndn.DataConfig{
+		ContentType: utils.IdPtr(ndn.ContentTypeKey),
+		Freshness:   utils.IdPtr(time.Minute),
+	}, spec, security.NewSha256Signer())
+	require.NoError(t, err)
+	data, _, err := spec.ReadData(enc.NewWireReader(wire))
+	require.NoError(t, err)
+	require.True(t, linkName.Equal(data.Name()))
+	require.Equal(t, ndn.ContentTypeLink, *data.ContentType())
+	require.Equal(t, time.Minute, *data.Freshness())
+	require.Equal(t, []byte(
+		"\x07\x10\x08\x03ndn\x08\x03edu\x08\x04ucla"+
+			"\x07\x0d\x08\x03ndn\x08\x06mobile"+
+			"\x07\x08\x08\x03isp\x32\x01\x07"),
+		data.Content().Join())
+
+	parsed, err := ndn.ParseLink(data)
+	require.NoError(t, err)
+	require.Len(t, parsed, len(names))
+	for i, name := range names {
+		require.True(t, name.Equal(parsed[i]))
+	}
+
+	// The delegations can be used as the ForwardingHint
+	interest, _, _, err := ndn.NewInterest(utils.WithoutErr(enc.NameFromStr("/alice/data"))).
+		ForwardingHint(parsed).
+		Nonce(1).
+		Build().
+		Encode(spec, nil)
+	require.NoError(t, err)
+	readInt, _, err := spec.ReadInterest(enc.NewWireReader(interest))
+	require.NoError(t, err)
+	require.Len(t, readInt.ForwardingHint(), len(names))
+
+	_, _, err = ndn.EncodeLink(nil, linkName, nil, spec, security.NewSha256Signer())
+	require.ErrorIs(t, err, ndn.ErrEmptyLink)
+
+	parseContent := func(content string, contentType ndn.ContentType) ([]enc.Name, error) {
+		wire, _, err := spec.MakeData(linkName, &ndn.DataConfig{ContentType: utils.IdPtr(contentType)},
+			enc.Wire{[]byte(content)}, security.NewSha256Signer())
+		require.NoError(t, err)
+		data, _, err := spec.ReadData(enc.NewWireReader(wire))
+		require.NoError(t, err)
+		return ndn.ParseLink(data)
+	}
+	// Non-critical unknown elements are skipped
+	parsed, err = parseContent("\xfd\x01\x00\x01\xff\x07\x05\x08\x03isp", ndn.ContentTypeLink)
+	require.NoError(t, err)
+	require.Equal(t, "/isp", parsed[0].String())
+	_, err = parseContent("\x07\x05\x08\x03isp", ndn.ContentTypeBlob)
+	require.Error(t, err)
+	_, err = parseContent("\x07\x05\x08\x03isp\x1f\x00", ndn.ContentTypeLink)
+	require.Error(t, err)
+	_, err = parseContent("\x07\x06\x08\x03isp", ndn.ContentTypeLink)
+	require.Error(t, err)
+	_, err = parseContent("", ndn.ContentTypeLink)
+	require.ErrorIs(t, err, ndn.ErrEmptyLink)
+}
+
 func TestMakeIntBasic(t *testing.T) {
 	utils.SetTestingT(t)
 	spec := spec_2022.Spec{}
@@ -310,6 +468,73 @@ func TestMakeIntBasic(t *testing.T) {
 		wire.Join())
 }
 
+func TestInterestBuilder(t *testing.T) {
+	utils.SetTestingT(t)
+	spec := spec_2022.Spec{}
+	name := utils.WithoutErr(enc.NameFromStr("/local/ndn/prefix"))
+
+	interest := ndn.NewInterest(name).
+		CanBePrefix(true).
+		MustBeFresh(true).
+		Lifetime(10 * time.Millisecond).
+		HopLimit(1).
+		Nonce(0).
+		Build()
+	wire, _, finalName, err := interest.Encode(spec, nil)
+	require.NoError(t, err)
+	require.Equal(t, "/local/ndn/prefix", finalName.String())
+	require.Equal(t, []byte(
+		"\x05\x26\x07\x14\x08\x05local\x08\x03ndn\x08\x06prefix"+
+			"\x21\x00\x12\x00\x0a\x04\x00\x00\x00\x00\x0c\x01\x0a\x22\x01\x01"),
+		wire.Join())
+
+	wire, _, _, err = ndn.NewInterest(name).
+		Lifetime(4*time.Second).
+		Nonce(0x01020304).
+		ForwardingHint([]enc.Name{
+			utils.WithoutErr(enc.NameFromStr("/name/A")),
+			utils.WithoutErr(enc.NameFromStr("/ndn/B")),
+			utils.WithoutErr(enc.NameFromBytes([]byte("\x07\x0d\x08\x0bshekkuenseu"))),
+		}).
+		Build().
+		Encode(spec, nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte(
+		"\x05\x46\x07\x14\x08\x05local\x08\x03ndn\x08\x06prefix\x1e\x24"+
+			"\x07\x09\x08\x04name\x08\x01A"+
+			"\x07\x08\x08\x03ndn\x08\x01B"+
+			"\x07\r\x08\x0bshekkuenseu"+
+			"\x0a\x04\x01\x02\x03\x04\x0c\x02\x0f\xa0"),
+		wire.Join())
+
+	// AppParams bring the ParametersSha256DigestComponent, the same as the one given by MakeInterest
+	appParam := enc.Wire{[]byte{0x01, 0x02}, []byte{0x03}}
+	interest = ndn.NewInterest(name).Nonce(0x01020304).AppParams(appParam).Build()
+	require.Equal(t, len(name)+1, len(interest.Name()))
+	require.Equal(t, enc.TypeParametersSha256DigestComponent, interest.Name()[len(name)].Typ)
+	require.Equal(t, "/local/ndn/prefix", name.String())
+	wire, _, finalName, err = interest.Encode(spec, nil)
+	require.NoError(t, err)
+	expWire, _, expName, err := spec.MakeInterest(name, &ndn.InterestConfig{Nonce: utils.IdPtr[uint64](0x01020304)},
+		appParam, nil)
+	require.NoError(t, err)
+	require.True(t, expName.Equal(interest.Name()))
+	require.True(t, expName.Equal(finalName))
+	require.Equal(t, expWire.Join(), wire.Join())
+	parsed, _, err := spec.ReadInterest(enc.NewWireReader(wire))
+	require.NoError(t, err)
+	require.True(t, parsed.Name().Equal(interest.Name()))
+	require.Equal(t, []byte{0x01, 0x02, 0x03}, parsed.AppParam().Join())
+
+	// Empty AppParams also need the digest, and a stale digest in the name is replaced
+	interest = ndn.NewInterest(finalName).AppParams(enc.Wire{}).Build()
+	require.Equal(t, len(name)+1, len(interest.Name()))
+	_, _, expName, err = spec.MakeInterest(name, &ndn.InterestConfig{}, enc.Wire{}, nil)
+	require.NoError(t, err)
+	require.True(t, expName.Equal(interest.Name()))
+	require.Nil(t, interest.Signature())
+}
+
 func TestMakeIntLargeAppParam(t *testing.T) {
 	utils.SetTestingT(t)
 	spec := spec_2022.Spec{}
@@ -514,6 +739,105 @@ func TestReadIntBasic(t *testing.T) {
 	require.Equal(t, sig, interest.Signature().SigValue())
 }
 
+func TestInterestForwardingHint(t *testing.T) {
+	utils.SetTestingT(t)
+	spec := spec_2022.Spec{}
+
+	hint := []enc.Name{
+		utils.WithoutErr(enc.NameFromStr("/ndn/edu/ucla")),
+		utils.WithoutErr(enc.NameFromStr("/ndn/mobile/v=3")),
+		utils.WithoutErr(enc.NameFromStr("/")),
+	}
+	wire, _, _, err := spec.MakeInterest(
+		utils.WithoutErr(enc.NameFromStr("/app/data")),
+		&ndn.InterestConfig{
+			Nonce:          utils.IdPtr[uint64](0x01020304),
+			ForwardingHint: hint,
+		},
+		nil,
+		nil,
+	)
+	require.NoError(t, err)
+	require.Equal(t, []byte(
+		"\x05\x3b\x07\x0b\x08\x03app\x08\x04data"+
+			"\x1e\x26"+
+			"\x07\x10\x08\x03ndn\x08\x03edu\x08\x04ucla"+
+			"\x07\x10\x08\x03ndn\x08\x06mobile\x36\x01\x03"+
+			"\x07\x00"+
+			"\x0a\x04\x01\x02\x03\x04"),
+		wire.Join())
+
+	interest, _, err := spec.ReadInterest(enc.NewWireReader(wire))
+	require.NoError(t, err)
+	require.Len(t, interest.ForwardingHint(), len(hint))
+	for i, name := range hint {
+		require.True(t, name.Equal(interest.ForwardingHint()[i]))
+	}
+
+	// Encoding the parsed Interest again gives the same wire
+	again, _, _, err := spec.MakeInterest(interest.Name(), &ndn.InterestConfig{
+		Nonce:          interest.Nonce(),
+		ForwardingHint: interest.ForwardingHint(),
+	}, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, wire.Join(), again.Join())
+
+	// The obsolete form with Delegations is rejected
+	_, _, err = spec.ReadInterest(enc.NewBufferReader([]byte(
+		"\x05\x1c\x07\x0b\x08\x03app\x08\x04data" +
+			"\x1e\x0d\x1f\x0b\x1e\x01\x01\x07\x06\x08\x04ucla")))
+	require.Error(t, err)
+}
+
+func TestDecrementHopLimit(t *testing.T) {
+	utils.SetTestingT(t)
+	spec := spec_2022.Spec{}
+	name := utils.WithoutErr(enc.NameFromStr("/app/data"))
+
+	wire, _, _, err := spec.MakeInterest(name, &ndn.InterestConfig{
+		Nonce:    utils.IdPtr[uint64](0x01020304),
+		HopLimit: utils.IdPtr[uint](2),
+	}, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte("\x05\x16\x07\x0b\x08\x03app\x08\x04data\x0a\x04\x01\x02\x03\x04\x22\x01\x02"), wire.Join())
+	interest, _, err := spec.ReadInterest(enc.NewWireReader(wire))
+	require.NoError(t, err)
+	pkt := interest.(*spec_2022.Interest)
+
+	require.False(t, pkt.DecrementHopLimit())
+	require.Equal(t, uint(1), *pkt.HopLimit())
+	// The decremented HopLimit is encoded when the Interest is forwarded
+	again := encodeInterest(pkt)
+	require.Equal(t, []byte("\x05\x16\x07\x0b\x08\x03app\x08\x04data\x0a\x04\x01\x02\x03\x04\x22\x01\x01"), again.Join())
+
+	require.True(t, pkt.DecrementHopLimit())
+	require.Equal(t, uint(0), *pkt.HopLimit())
+	// An Interest received with HopLimit 0 is dropped as well
+	require.True(t, pkt.DecrementHopLimit())
+	require.Equal(t, uint(0), *pkt.HopLimit())
+
+	// Without HopLimit there is no limit
+	wire, _, _, err = spec.MakeInterest(name, &ndn.InterestConfig{
+		Nonce: utils.IdPtr[uint64](0x01020304),
+	}, nil, nil)
+	require.NoError(t, err)
+	interest, _, err = spec.ReadInterest(enc.NewWireReader(wire))
+	require.NoError(t, err)
+	pkt = interest.(*spec_2022.Interest)
+	for i := 0; i < 300; i++ {
+		require.False(t, pkt.DecrementHopLimit())
+	}
+	require.Nil(t, pkt.HopLimit())
+	require.Equal(t, wire.Join(), encodeInterest(pkt).Join())
+}
+
+func encodeInterest(interest *spec_2022.Interest) enc.Wire {
+	pkt := &spec_2022.Packet{Interest: interest}
+	encoder := spec_2022.PacketEncoder{}
+	encoder.Init(pkt)
+	return encoder.Encode(pkt)
+}
+
 func TestReadIntErrors(t *testing.T) {
 	utils.SetTestingT(t)
 	spec := spec_2022.Spec{}
@@ -538,3 +862,346 @@ func TestReadIntErrors(t *testing.T) {
 	))
 	require.Error(t, err)
 }
+
+func TestDataValidityPeriod(t *testing.T) {
+	utils.SetTestingT(t)
+	spec := spec_2022.Spec{}
+	name := utils.WithoutErr(enc.NameFromStr("/local/ndn/prefix"))
+
+	notBefore := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2023, 12, 31, 23, 59, 59, 0, time.UTC)
+	wire, _, err := spec.MakeData(name, &ndn.DataConfig{},
+		enc.Wire{[]byte("content")}, security.NewValiditySigner(security.NewSha256Signer(), notBefore, notAfter))
+	require.NoError(t, err)
+	data, _, err := spec.ReadData(enc.NewWireReader(wire))
+	require.NoError(t, err)
+	sig := data.Signature()
+	require.True(t, sig.IsValidAt(notBefore))
+	require.True(t, sig.IsValidAt(notAfter))
+	require.False(t, sig.IsValidAt(notBefore.Add(-time.Second)))
+	require.False(t, sig.IsValidAt(notAfter.Add(time.Second)))
+
+	// No validity period means always valid
+	wire, _, err = spec.MakeData(name, &ndn.DataConfig{}, enc.Wire{[]byte("content")}, security.NewSha256Signer())
+	require.NoError(t, err)
+	data, _, err = spec.ReadData(enc.NewWireReader(wire))
+	require.NoError(t, err)
+	require.True(t, data.Signature().IsValidAt(time.Time{}))
+	require.True(t, data.Signature().IsValidAt(notAfter))
+}
+
+func TestMakeIntLongSignature(t *testing.T) {
+	utils.SetTestingT(t)
+	spec := spec_2022.Spec{}
+
+	key := utils.WithoutErr(rsa.GenerateKey(rand.Reader, 2048))
+	keyName := utils.WithoutErr(enc.NameFromStr("/test/KEY/rsa"))
+	signer := security.NewInterestSigner(security.NewRsaDataSigner(keyName, key), basic_engine.Timer{})
+	wire, _, finalName, err := spec.MakeInterest(
+		utils.WithoutErr(enc.NameFromStr("/local/ndn/prefix")),
+		&ndn.InterestConfig{Nonce: utils.IdPtr[uint64](0)},
+		enc.Wire{[]byte("param")},
+		signer,
+	)
+	require.NoError(t, err)
+	interest, covered, err := spec.ReadInterest(enc.NewWireReader(wire))
+	require.NoError(t, err)
+	require.True(t, finalName.Equal(interest.Name()))
+	require.Equal(t, 256, len(interest.Signature().SigValue()))
+	require.True(t, security.RsaValidate(covered, interest.Signature(), &key.PublicKey))
+}
+
+func TestMakeIntDigestComponent(t *testing.T) {
+	utils.SetTestingT(t)
+	spec := spec_2022.Spec{}
+	config := &ndn.InterestConfig{
+		Lifetime: utils.IdPtr(4 * time.Second),
+	}
+
+	// A stale digest is replaced by the correct one
+	name := utils.WithoutErr(enc.NameFromStr(
+		"/local/ndn/prefix/params-sha256=0000000000000000000000000000000000000000000000000000000000000000"))
+	wire, _, finalName, err := spec.MakeInterest(name, config, enc.Wire{[]byte{1, 2, 3, 4}}, nil)
+	require.NoError(t, err)
+	require.Equal(t,
+		"/local/ndn/prefix/params-sha256=47756f21fe0ee265149aa2be3c63c538a72378e9b0a58b39c5916367d35bda10",
+		finalName.String())
+	_, _, err = spec.ReadInterest(enc.NewWireReader(wire))
+	require.NoError(t, err)
+	// The input name is not modified
+	require.Equal(t,
+		"/local/ndn/prefix/params-sha256=0000000000000000000000000000000000000000000000000000000000000000",
+		name.String())
+
+	// A digest not at the end is rejected
+	name = utils.WithoutErr(enc.NameFromStr(
+		"/local/params-sha256=0000000000000000000000000000000000000000000000000000000000000000/prefix"))
+	_, _, _, err = spec.MakeInterest(name, config, enc.Wire{[]byte{1, 2, 3, 4}}, nil)
+	require.ErrorAs(t, err, &enc.ErrFormat{})
+
+	// A digest without ApplicationParameters is rejected
+	name = utils.WithoutErr(enc.NameFromStr(
+		"/local/ndn/prefix/params-sha256=0000000000000000000000000000000000000000000000000000000000000000"))
+	_, _, _, err = spec.MakeInterest(name, config, nil, nil)
+	require.ErrorAs(t, err, &enc.ErrFormat{})
+
+	// The digest covers the elements from ApplicationParameters to the end of the Interest
+	paramsDigest := func(wire enc.Wire) []byte {
+		buf := wire.Join()
+		r := enc.NewBufferReader(buf)
+		utils.WithoutErr(enc.ReadTLNum(r))
+		utils.WithoutErr(enc.ReadTLNum(r))
+		for {
+			start := r.Pos()
+			typ := utils.WithoutErr(enc.ReadTLNum(r))
+			l := utils.WithoutErr(enc.ReadTLNum(r))
+			if typ == 0x24 {
+				h := sha256.Sum256(buf[start:])
+				return h[:]
+			}
+			require.NoError(t, r.Skip(int(l)))
+		}
+	}
+
+	// Empty ApplicationParameters still bring a digest, which is over the empty element
+	name = utils.WithoutErr(enc.NameFromStr(
+		"/local/ndn/prefix/params-sha256=0000000000000000000000000000000000000000000000000000000000000000"))
+	wire, _, finalName, err = spec.MakeInterest(name, config, enc.Wire{}, nil)
+	require.NoError(t, err)
+	emptyDigest := sha256.Sum256([]byte{0x24, 0x00})
+	require.Equal(t, enc.TypeParametersSha256DigestComponent, finalName[len(finalName)-1].Typ)
+	require.Equal(t, emptyDigest[:], finalName[len(finalName)-1].Val)
+	require.Equal(t, paramsDigest(wire), finalName[len(finalName)-1].Val)
+	interest, _, err := spec.ReadInterest(enc.NewWireReader(wire))
+	require.NoError(t, err)
+	require.True(t, finalName.Equal(interest.Name()))
+	require.Equal(t, 0, len(interest.AppParam().Join()))
+
+	// With a signature, the digest also covers the signature elements, and a stale one is still replaced
+	wire, _, finalName, err = spec.MakeInterest(name, config, enc.Wire{[]byte{1, 2, 3, 4}},
+		security.NewSha256IntSigner(basic_engine.NewTimer()))
+	require.NoError(t, err)
+	require.Equal(t, 4, len(finalName))
+	require.Equal(t, enc.TypeParametersSha256DigestComponent, finalName[3].Typ)
+	require.Equal(t, paramsDigest(wire), finalName[3].Val)
+	require.NotEqual(t, make([]byte, 32), finalName[3].Val)
+	interest, _, err = spec.ReadInterest(enc.NewWireReader(wire))
+	require.NoError(t, err)
+	require.True(t, finalName.Equal(interest.Name()))
+	require.NotNil(t, interest.Signature().SigNonce())
+}
+
+func TestDataUnknownField(t *testing.T) {
+	utils.SetTestingT(t)
+
+	spec := spec_2022.Spec{}
+	covered := []byte{
+		0x07, 0x03, 0x08, 0x01, 'a',
+		0x14, 0x03, 0x18, 0x01, 0x00,
+		0xfc, 0x02, 0xbe, 0xef, // unknown non-critical field
+		0x15, 0x01, 'x',
+		0x16, 0x03, 0x1b, 0x01, 0x00,
+	}
+	digest := sha256.Sum256(covered)
+	wire := append([]byte{0x06, byte(len(covered) + 34)}, covered...)
+	wire = append(wire, 0x17, 0x20)
+	wire = append(wire, digest[:]...)
+
+	data, sigCovered, err := spec.ReadData(enc.NewBufferReader(wire))
+	require.NoError(t, err)
+	require.Equal(t, "/a", data.Name().String())
+	require.Equal(t, []byte("x"), data.Content().Join())
+	// The unknown field is kept and covered by the signature
+	unknown := data.(*spec_2022.Data).UnknownFields
+	require.Equal(t, 1, len(unknown))
+	require.Equal(t, enc.TLNum(0xfc), unknown[0].Typ)
+	require.Equal(t, []byte{0xbe, 0xef}, unknown[0].Val.Join())
+	require.Equal(t, covered, sigCovered.Join())
+	require.Equal(t, digest[:], data.Signature().SigValue())
+}
+
+func TestDataFullName(t *testing.T) {
+	utils.SetTestingT(t)
+
+	spec := spec_2022.Spec{}
+	name := utils.WithoutErr(enc.NameFromStr("/local/ndn/prefix"))
+	wire, _, err := spec.MakeData(name, &ndn.DataConfig{
+		ContentType: utils.IdPtr(ndn.ContentTypeBlob),
+	}, enc.Wire{[]byte("content")}, security.NewSha256Signer())
+	require.NoError(t, err)
+	digest := sha256.Sum256(wire.Join())
+
+	data, _, err := spec.ReadData(enc.NewWireReader(wire))
+	require.NoError(t, err)
+	fullName := data.FullName()
+	require.Equal(t, "/local/ndn/prefix/sha256digest="+hex.EncodeToString(digest[:]), fullName.String())
+	require.Equal(t, 3, len(data.Name()))
+
+	// A Data not parsed from a wire is encoded to compute the digest
+	d := data.(*spec_2022.Data)
+	copied := &spec_2022.Data{
+		NameV:          d.NameV,
+		MetaInfo:       d.MetaInfo,
+		ContentV:       d.ContentV,
+		SignatureInfo:  d.SignatureInfo,
+		SignatureValue: d.SignatureValue,
+	}
+	require.Equal(t, fullName, copied.FullName())
+
+	// An Interest for a full name cannot have CanBePrefix
+	_, _, _, err = spec.MakeInterest(fullName, &ndn.InterestConfig{CanBePrefix: true}, nil, nil)
+	require.Equal(t, ndn.ErrInvalidValue{Item: "Interest.CanBePrefix", Value: true}, err)
+	_, _, _, err = spec.MakeInterest(append(fullName, enc.NewStringComponent(enc.TypeGenericNameComponent, "a")),
+		&ndn.InterestConfig{}, nil, nil)
+	require.ErrorAs(t, err, &enc.ErrFormat{})
+	intWire, _, _, err := spec.MakeInterest(fullName, &ndn.InterestConfig{}, nil, nil)
+	require.NoError(t, err)
+	interest, _, err := spec.ReadInterest(enc.NewWireReader(intWire))
+	require.NoError(t, err)
+	require.Equal(t, fullName, interest.Name())
+}
+
+func TestDataSatisfies(t *testing.T) {
+	utils.SetTestingT(t)
+	spec := spec_2022.Spec{}
+
+	wire, _, err := spec.MakeData(utils.WithoutErr(enc.NameFromStr("/app/data/v=1/seg=0")), &ndn.DataConfig{},
+		enc.Wire{[]byte("content")}, security.NewSha256Signer())
+	require.NoError(t, err)
+	data, _, err := spec.ReadData(enc.NewWireReader(wire))
+	require.NoError(t, err)
+
+	makeInterest := func(name enc.Name, canBePrefix bool) ndn.Interest {
+		intWire, _, _, err := spec.MakeInterest(name, &ndn.InterestConfig{CanBePrefix: canBePrefix}, nil, nil)
+		require.NoError(t, err)
+		interest, _, err := spec.ReadInterest(enc.NewWireReader(intWire))
+		require.NoError(t, err)
+		return interest
+	}
+	prefix := utils.WithoutErr(enc.NameFromStr("/app/data"))
+	exact := utils.WithoutErr(enc.NameFromStr("/app/data/v=1/seg=0"))
+	longer := utils.WithoutErr(enc.NameFromStr("/app/data/v=1/seg=0/extra"))
+	other := utils.WithoutErr(enc.NameFromStr("/app/other"))
+
+	// A Data under the Interest name satisfies it only with CanBePrefix
+	require.False(t, ndn.DataSatisfies(makeInterest(prefix, false), data))
+	require.True(t, ndn.DataSatisfies(makeInterest(prefix, true), data))
+	require.True(t, ndn.DataSatisfies(makeInterest(exact, false), data))
+	require.True(t, ndn.DataSatisfies(makeInterest(exact, true), data))
+	require.False(t, ndn.DataSatisfies(makeInterest(longer, true), data))
+	require.False(t, ndn.DataSatisfies(makeInterest(other, true), data))
+
+	// A full name asks for the Data with the digest
+	require.True(t, ndn.DataSatisfies(makeInterest(data.FullName(), false), data))
+	wrongDigest := append(exact, enc.Component{
+		Typ: enc.TypeImplicitSha256DigestComponent, Val: make([]byte, 32),
+	})
+	require.False(t, ndn.DataSatisfies(makeInterest(wrongDigest, false), data))
+}
+
+func TestInterestLegacySelectors(t *testing.T) {
+	// Interest /a with Selectors{MinSuffixComponents=1} of v0.2, which is critical
+	wire := []byte{
+		0x05, 0x0f, 0x07, 0x03, 0x08, 0x01, 'a',
+		0x09, 0x03, 0x0d, 0x01, 0x01,
+		0x0a, 0x04, 0x01, 0x02, 0x03, 0x04,
+	}
+	_, _, err := spec_2022.ReadPacket(enc.NewBufferReader(wire))
+	require.ErrorAs(t, err, &enc.ErrUnrecognizedField{})
+}
+
+// recordingSigner records the wire given to the signer it wraps.
+type recordingSigner struct {
+	ndn.Signer
+	covered []byte
+}
+
+func (s *recordingSigner) ComputeSigValue(covered enc.Wire) ([]byte, error) {
+	s.covered = covered.Join()
+	return s.Signer.ComputeSigValue(covered)
+}
+
+// signedRange returns the part of an encoded Data from the start of Name to the end of SignatureInfo.
+func signedRange(t *testing.T, wire []byte) []byte {
+	r := enc.NewBufferReader(wire)
+	typ := utils.WithoutErr(enc.ReadTLNum(r))
+	require.Equal(t, enc.TLNum(0x06), typ)
+	utils.WithoutErr(enc.ReadTLNum(r))
+	start := r.Pos()
+	for r.Pos() < r.Length() {
+		typ := utils.WithoutErr(enc.ReadTLNum(r))
+		l := utils.WithoutErr(enc.ReadTLNum(r))
+		require.NoError(t, r.Skip(int(l)))
+		if typ == 0x16 {
+			return wire[start:r.Pos()]
+		}
+	}
+	require.Fail(t, "no SignatureInfo")
+	return nil
+}
+
+func TestDataSigCoveredRange(t *testing.T) {
+	utils.SetTestingT(t)
+	spec := spec_2022.Spec{}
+	name := utils.WithoutErr(enc.NameFromStr("/app/data"))
+	keyName := utils.WithoutErr(enc.NameFromStr("/app/KEY/1"))
+	key := utils.WithoutErr(ecdsa.GenerateKey(elliptic.P256(), rand.Reader))
+	verifier := security.NewEcdsaVerifier(&key.PublicKey)
+
+	for _, c := range []struct {
+		config  *ndn.DataConfig
+		content enc.Wire
+	}{
+		// Empty MetaInfo and no Content
+		{&ndn.DataConfig{}, nil},
+		{&ndn.DataConfig{
+			ContentType:  utils.IdPtr(ndn.ContentTypeKey),
+			Freshness:    utils.IdPtr(time.Second),
+			FinalBlockID: utils.IdPtr(enc.NewSegmentComponent(1)),
+		}, enc.Wire{[]byte("fragmented "), []byte("content")}},
+	} {
+		// The ECDSA signature is usually shorter than its estimate, so the encoded Data shrinks after signing
+		signer := &recordingSigner{Signer: security.NewEcdsaSigner(keyName, key)}
+		wire, sigCovered, err := spec.MakeData(name, c.config, c.content, signer)
+		require.NoError(t, err)
+		buf := wire.Join()
+		expected := signedRange(t, buf)
+		require.Equal(t, expected, signer.covered)
+		require.Equal(t, expected, sigCovered.Join())
+
+		data, readCovered, err := spec.ReadData(enc.NewBufferReader(buf))
+		require.NoError(t, err)
+		require.Equal(t, expected, readCovered.Join())
+		require.True(t, verifier(name, enc.Wire{expected}, data.Signature()))
+	}
+}
+
+func TestDataSigCoveredGolden(t *testing.T) {
+	utils.SetTestingT(t)
+	spec := spec_2022.Spec{}
+
+	// Assembled by hand from the NDN packet format v0.3 TLV by TLV, not produced by ndn-cxx,
+	// which cannot be run here. The digest was computed separately over the covered bytes.
+	covered := []byte("\x07\x14\x08\x05local\x08\x03ndn\x08\x06prefix" + // Name
+		"\x14\x04\x19\x02\x27\x10" + // MetaInfo: FreshnessPeriod 10000
+		"\x15\x08SUCCESS!" + // Content
+		"\x16\x03\x1b\x01\x00") // SignatureInfo: DigestSha256
+	golden := append([]byte("\x06\x4d"), covered...)
+	golden = append(golden, "\x17\x20"...)
+	golden = append(golden, utils.WithoutErr(hex.DecodeString(
+		"9c970cf45bb2e62c0692cd52aebfefbf7a392995e8bc536a827a7c2601b6dfa0"))...)
+
+	wire, sigCovered, err := spec.MakeData(
+		utils.WithoutErr(enc.NameFromStr("/local/ndn/prefix")),
+		&ndn.DataConfig{Freshness: utils.IdPtr(10 * time.Second)},
+		enc.Wire{[]byte("SUCCESS!")},
+		security.NewSha256Signer(),
+	)
+	require.NoError(t, err)
+	require.Equal(t, golden, wire.Join())
+	require.Equal(t, covered, sigCovered.Join())
+
+	_, readCovered, err := spec.ReadData(enc.NewBufferReader(golden))
+	require.NoError(t, err)
+	require.Equal(t, covered, readCovered.Join())
+}
@@ -194,6 +194,8 @@ func (context *KeyLocatorParsingContext) Parse(reader enc.ParseReader, ignoreCri
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -449,6 +451,8 @@ func (context *LinksParsingContext) Parse(reader enc.ParseReader, ignoreCritical
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -722,6 +726,8 @@ func (context *MetaInfoParsingContext) Parse(reader enc.ParseReader, ignoreCriti
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -932,6 +938,8 @@ func (context *ValidityPeriodParsingContext) Parse(reader enc.ParseReader, ignor
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -1138,6 +1146,8 @@ func (context *CertDescriptionEntryParsingContext) Parse(reader enc.ParseReader,
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -1372,6 +1382,8 @@ func (context *CertAdditionalDescriptionParsingContext) Parse(reader enc.ParseRe
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -1859,6 +1871,8 @@ func (context *SignatureInfoParsingContext) Parse(reader enc.ParseReader, ignore
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -2041,6 +2055,8 @@ func (context *NetworkNackParsingContext) Parse(reader enc.ParseReader, ignoreCr
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -2199,6 +2215,8 @@ func (context *CachePolicyParsingContext) Parse(reader enc.ParseReader, ignoreCr
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -3228,6 +3246,8 @@ func (context *LpPacketParsingContext) Parse(reader enc.ParseReader, ignoreCriti
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -3389,6 +3409,13 @@ func (encoder *InterestEncoder) Init(value *Interest) {
 	encoder.SignatureValue_wireIdx = -1
 
 	l := uint(0)
+	UnknownFields_idx := 0
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 0; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 
 	if value.NameV != nil {
 		l += 1
@@ -3405,16 +3432,34 @@ func (encoder *InterestEncoder) Init(value *Interest) {
 		l += encoder.NameV_length
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 3; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if value.CanBePrefixV {
 		l += 1
 		l += 1
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 4; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if value.MustBeFreshV {
 		l += 1
 		l += 1
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 5; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if value.ForwardingHintV != nil {
 		l += 1
 		switch x := encoder.ForwardingHintV_encoder.length; {
@@ -3430,11 +3475,23 @@ func (encoder *InterestEncoder) Init(value *Interest) {
 		l += encoder.ForwardingHintV_encoder.length
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 6; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if value.NonceV != nil {
 		l += 1
 		l += 1 + 4
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 7; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if value.InterestLifetimeV != nil {
 		l += 1
 		switch x := uint64(*value.InterestLifetimeV / time.Millisecond); {
@@ -3449,11 +3506,23 @@ func (encoder *InterestEncoder) Init(value *Interest) {
 		}
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 8; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if value.HopLimitV != nil {
 		l += 1
 		l += 1 + 1
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 9; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	encoder.sigCoverStart = int(l)
 	encoder.digestCoverStart = int(l)
 	if value.ApplicationParameters != nil {
@@ -3471,6 +3540,12 @@ func (encoder *InterestEncoder) Init(value *Interest) {
 		l += encoder.ApplicationParameters_length
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 12; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if value.SignatureInfo != nil {
 		l += 1
 		switch x := encoder.SignatureInfo_encoder.length; {
@@ -3486,6 +3561,12 @@ func (encoder *InterestEncoder) Init(value *Interest) {
 		l += encoder.SignatureInfo_encoder.length
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 13; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if encoder.SignatureValue_estLen > 0 {
 		l += 1
 		switch x := encoder.SignatureValue_estLen; {
@@ -3501,11 +3582,32 @@ func (encoder *InterestEncoder) Init(value *Interest) {
 		l += encoder.SignatureValue_estLen
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 14; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	encoder.digestCoverEnd = int(l)
+
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 16; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
+
 	encoder.length = l
 
 	wirePlan := make([]uint, 0)
 	l = uint(0)
+	UnknownFields_idx = 0
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 0; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 
 	if value.NameV != nil {
 		l += 1
@@ -3522,16 +3624,34 @@ func (encoder *InterestEncoder) Init(value *Interest) {
 		l += encoder.NameV_length
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 3; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if value.CanBePrefixV {
 		l += 1
 		l += 1
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 4; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if value.MustBeFreshV {
 		l += 1
 		l += 1
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 5; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if value.ForwardingHintV != nil {
 		l += 1
 		switch x := encoder.ForwardingHintV_encoder.length; {
@@ -3547,11 +3667,23 @@ func (encoder *InterestEncoder) Init(value *Interest) {
 		l += encoder.ForwardingHintV_encoder.length
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 6; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if value.NonceV != nil {
 		l += 1
 		l += 1 + 4
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 7; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if value.InterestLifetimeV != nil {
 		l += 1
 		switch x := uint64(*value.InterestLifetimeV / time.Millisecond); {
@@ -3566,11 +3698,24 @@ func (encoder *InterestEncoder) Init(value *Interest) {
 		}
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 8; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if value.HopLimitV != nil {
 		l += 1
 		l += 1 + 1
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 9; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
+
 	if value.ApplicationParameters != nil {
 		l += 1
 		switch x := encoder.ApplicationParameters_length; {
@@ -3591,6 +3736,12 @@ func (encoder *InterestEncoder) Init(value *Interest) {
 		}
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 12; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if value.SignatureInfo != nil {
 		l += 1
 		switch x := encoder.SignatureInfo_encoder.length; {
@@ -3606,6 +3757,12 @@ func (encoder *InterestEncoder) Init(value *Interest) {
 		l += encoder.SignatureInfo_encoder.length
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 13; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if encoder.SignatureValue_estLen > 0 {
 		l += 1
 		switch x := encoder.SignatureValue_estLen; {
@@ -3625,6 +3782,20 @@ func (encoder *InterestEncoder) Init(value *Interest) {
 		l = 0
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 14; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
+
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 16; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
+
 	if l > 0 {
 		wirePlan = append(wirePlan, l)
 	}
@@ -3646,6 +3817,15 @@ func (encoder *InterestEncoder) EncodeInto(value *Interest, wire enc.Wire) {
 	buf := wire[wireIdx]
 
 	pos := uint(0)
+	UnknownFields_idx := 0
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 0; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
 
 	if value.NameV != nil {
 		buf[pos] = byte(7)
@@ -3686,6 +3866,14 @@ func (encoder *InterestEncoder) EncodeInto(value *Interest, wire enc.Wire) {
 		encoder.sigCovered = append(encoder.sigCovered, buf[sigCoverStart:sigCoverEnd])
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 3; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
 	if value.CanBePrefixV {
 		buf[pos] = byte(33)
 		pos += 1
@@ -3693,6 +3881,14 @@ func (encoder *InterestEncoder) EncodeInto(value *Interest, wire enc.Wire) {
 		pos += 1
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 4; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
 	if value.MustBeFreshV {
 		buf[pos] = byte(18)
 		pos += 1
@@ -3700,6 +3896,14 @@ func (encoder *InterestEncoder) EncodeInto(value *Interest, wire enc.Wire) {
 		pos += 1
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 5; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
 	if value.ForwardingHintV != nil {
 		buf[pos] = byte(30)
 		pos += 1
@@ -3726,6 +3930,14 @@ func (encoder *InterestEncoder) EncodeInto(value *Interest, wire enc.Wire) {
 		}
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 6; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
 	if value.NonceV != nil {
 		buf[pos] = byte(10)
 		pos += 1
@@ -3736,6 +3948,14 @@ func (encoder *InterestEncoder) EncodeInto(value *Interest, wire enc.Wire) {
 		pos += 5
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 7; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
 	if value.InterestLifetimeV != nil {
 		buf[pos] = byte(12)
 		pos += 1
@@ -3759,6 +3979,14 @@ func (encoder *InterestEncoder) EncodeInto(value *Interest, wire enc.Wire) {
 		}
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 8; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
 	if value.HopLimitV != nil {
 		buf[pos] = byte(34)
 		pos += 1
@@ -3768,6 +3996,14 @@ func (encoder *InterestEncoder) EncodeInto(value *Interest, wire enc.Wire) {
 		pos += 2
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 9; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
 	encoder.sigCoverStart_wireIdx = int(wireIdx)
 	encoder.sigCoverStart_pos = int(pos)
 
@@ -3813,6 +4049,14 @@ func (encoder *InterestEncoder) EncodeInto(value *Interest, wire enc.Wire) {
 		}
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 12; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
 	if value.SignatureInfo != nil {
 		buf[pos] = byte(44)
 		pos += 1
@@ -3839,6 +4083,14 @@ func (encoder *InterestEncoder) EncodeInto(value *Interest, wire enc.Wire) {
 		}
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 13; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
 	if encoder.SignatureValue_estLen > 0 {
 		startPos := int(pos)
 		buf[pos] = byte(46)
@@ -3888,9 +4140,26 @@ func (encoder *InterestEncoder) EncodeInto(value *Interest, wire enc.Wire) {
 		}
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 14; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
 	encoder.digestCoverEnd_wireIdx = int(wireIdx)
 	encoder.digestCoverEnd_pos = int(pos)
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 16; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
+
 }
 
 func (encoder *InterestEncoder) Encode(value *Interest) enc.Wire {
@@ -4073,7 +4342,14 @@ func (context *InterestParsingContext) Parse(reader enc.ParseReader, ignoreCriti
 				if !ignoreCritical && ((typ <= 31) || ((typ & 1) == 1)) {
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
-				err = reader.Skip(int(l))
+				{
+					u := enc.UnknownField{Typ: typ, Pos: progress}
+					u.Val, err = reader.ReadWire(int(l))
+					value.UnknownFields = append(value.UnknownFields, u)
+				}
+
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -4109,6 +4385,8 @@ func (context *InterestParsingContext) Parse(reader enc.ParseReader, ignoreCriti
 					context.digestCoverEnd = int(startPos)
 					context.digestCovered = reader.Range(context.digestCoverStart, startPos)
 
+				case 15 - 1:
+
 				}
 			}
 			if err != nil {
@@ -4117,7 +4395,7 @@ func (context *InterestParsingContext) Parse(reader enc.ParseReader, ignoreCriti
 		}
 	}
 	startPos = reader.Pos()
-	for ; progress < 15; progress++ {
+	for ; progress < 16; progress++ {
 		switch progress {
 		case 0 - 1:
 
@@ -4151,6 +4429,8 @@ func (context *InterestParsingContext) Parse(reader enc.ParseReader, ignoreCriti
 			context.digestCoverEnd = int(startPos)
 			context.digestCovered = reader.Range(context.digestCoverStart, startPos)
 
+		case 15 - 1:
+
 		}
 	}
 	if err != nil {
@@ -4211,6 +4491,13 @@ func (encoder *DataEncoder) Init(value *Data) {
 	encoder.SignatureValue_wireIdx = -1
 
 	l := uint(0)
+	UnknownFields_idx := 0
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 0; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 
 	encoder.sigCoverStart = int(l)
 	if value.NameV != nil {
@@ -4228,6 +4515,12 @@ func (encoder *DataEncoder) Init(value *Data) {
 		l += encoder.NameV_length
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 3; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if value.MetaInfo != nil {
 		l += 1
 		switch x := encoder.MetaInfo_encoder.length; {
@@ -4243,6 +4536,12 @@ func (encoder *DataEncoder) Init(value *Data) {
 		l += encoder.MetaInfo_encoder.length
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 4; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if value.ContentV != nil {
 		l += 1
 		switch x := encoder.ContentV_length; {
@@ -4258,6 +4557,12 @@ func (encoder *DataEncoder) Init(value *Data) {
 		l += encoder.ContentV_length
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 5; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if value.SignatureInfo != nil {
 		l += 1
 		switch x := encoder.SignatureInfo_encoder.length; {
@@ -4273,6 +4578,12 @@ func (encoder *DataEncoder) Init(value *Data) {
 		l += encoder.SignatureInfo_encoder.length
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 6; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if encoder.SignatureValue_estLen > 0 {
 		l += 1
 		switch x := encoder.SignatureValue_estLen; {
@@ -4288,10 +4599,31 @@ func (encoder *DataEncoder) Init(value *Data) {
 		l += encoder.SignatureValue_estLen
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 7; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
+
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 8; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
+
 	encoder.length = l
 
 	wirePlan := make([]uint, 0)
 	l = uint(0)
+	UnknownFields_idx = 0
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 0; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 
 	if value.NameV != nil {
 		l += 1
@@ -4308,6 +4640,12 @@ func (encoder *DataEncoder) Init(value *Data) {
 		l += encoder.NameV_length
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 3; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if value.MetaInfo != nil {
 		l += 1
 		switch x := encoder.MetaInfo_encoder.length; {
@@ -4323,6 +4661,12 @@ func (encoder *DataEncoder) Init(value *Data) {
 		l += encoder.MetaInfo_encoder.length
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 4; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if value.ContentV != nil {
 		l += 1
 		switch x := encoder.ContentV_length; {
@@ -4343,6 +4687,12 @@ func (encoder *DataEncoder) Init(value *Data) {
 		}
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 5; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if value.SignatureInfo != nil {
 		l += 1
 		switch x := encoder.SignatureInfo_encoder.length; {
@@ -4358,6 +4708,12 @@ func (encoder *DataEncoder) Init(value *Data) {
 		l += encoder.SignatureInfo_encoder.length
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 6; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
 	if encoder.SignatureValue_estLen > 0 {
 		l += 1
 		switch x := encoder.SignatureValue_estLen; {
@@ -4377,6 +4733,20 @@ func (encoder *DataEncoder) Init(value *Data) {
 		l = 0
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 7; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
+
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 8; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
+
 	if l > 0 {
 		wirePlan = append(wirePlan, l)
 	}
@@ -4389,6 +4759,7 @@ func (context *DataParsingContext) Init() {
 
 	context.SignatureInfo_context.Init()
 	context.sigCovered = make(enc.Wire, 0)
+
 }
 
 func (encoder *DataEncoder) EncodeInto(value *Data, wire enc.Wire) {
@@ -4397,6 +4768,15 @@ func (encoder *DataEncoder) EncodeInto(value *Data, wire enc.Wire) {
 	buf := wire[wireIdx]
 
 	pos := uint(0)
+	UnknownFields_idx := 0
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 0; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
 
 	encoder.sigCoverStart_wireIdx = int(wireIdx)
 	encoder.sigCoverStart_pos = int(pos)
@@ -4426,6 +4806,14 @@ func (encoder *DataEncoder) EncodeInto(value *Data, wire enc.Wire) {
 		}
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 3; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
 	if value.MetaInfo != nil {
 		buf[pos] = byte(20)
 		pos += 1
@@ -4452,6 +4840,14 @@ func (encoder *DataEncoder) EncodeInto(value *Data, wire enc.Wire) {
 		}
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 4; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
 	if value.ContentV != nil {
 		buf[pos] = byte(21)
 		pos += 1
@@ -4491,6 +4887,14 @@ func (encoder *DataEncoder) EncodeInto(value *Data, wire enc.Wire) {
 		}
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 5; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
 	if value.SignatureInfo != nil {
 		buf[pos] = byte(22)
 		pos += 1
@@ -4517,6 +4921,14 @@ func (encoder *DataEncoder) EncodeInto(value *Data, wire enc.Wire) {
 		}
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 6; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
 	if encoder.SignatureValue_estLen > 0 {
 		startPos := int(pos)
 		buf[pos] = byte(23)
@@ -4566,6 +4978,24 @@ func (encoder *DataEncoder) EncodeInto(value *Data, wire enc.Wire) {
 		}
 	}
 
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 7; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
+
+	for ; UnknownFields_idx < len(value.UnknownFields) && value.UnknownFields[UnknownFields_idx].Pos < 8; UnknownFields_idx++ {
+		u := &value.UnknownFields[UnknownFields_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
+
 }
 
 func (encoder *DataEncoder) Encode(value *Data) enc.Wire {
@@ -4663,7 +5093,14 @@ func (context *DataParsingContext) Parse(reader enc.ParseReader, ignoreCritical
 				if !ignoreCritical && ((typ <= 31) || ((typ & 1) == 1)) {
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
-				err = reader.Skip(int(l))
+				{
+					u := enc.UnknownField{Typ: typ, Pos: progress}
+					u.Val, err = reader.ReadWire(int(l))
+					value.UnknownFields = append(value.UnknownFields, u)
+				}
+
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -4681,6 +5118,8 @@ func (context *DataParsingContext) Parse(reader enc.ParseReader, ignoreCritical
 					value.SignatureInfo = nil
 				case 6 - 1:
 					value.SignatureValue = nil
+				case 7 - 1:
+
 				}
 			}
 			if err != nil {
@@ -4689,7 +5128,7 @@ func (context *DataParsingContext) Parse(reader enc.ParseReader, ignoreCritical
 		}
 	}
 	startPos = reader.Pos()
-	for ; progress < 7; progress++ {
+	for ; progress < 8; progress++ {
 		switch progress {
 		case 0 - 1:
 
@@ -4705,6 +5144,8 @@ func (context *DataParsingContext) Parse(reader enc.ParseReader, ignoreCritical
 			value.SignatureInfo = nil
 		case 6 - 1:
 			value.SignatureValue = nil
+		case 7 - 1:
+
 		}
 	}
 	if err != nil {
@@ -5100,6 +5541,8 @@ func (context *PacketParsingContext) Parse(reader enc.ParseReader, ignoreCritica
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
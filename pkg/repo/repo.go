@@ -0,0 +1,170 @@
+// Package repo implements the client side of the repo insertion protocol used by repo-ng.
+package repo
+
+import (
+	"fmt"
+	"time"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+const (
+	// StatusAccepted is returned by the repo when an insert command starts.
+	StatusAccepted = uint64(100)
+	// StatusCompleted is returned by an insert check when all Data are fetched.
+	StatusCompleted = uint64(200)
+	// StatusInProgress is returned by an insert check when the repo is still fetching.
+	StatusInProgress = uint64(300)
+)
+
+const (
+	DefaultInsertTimeout = 10 * time.Second
+	DefaultCheckInterval = 200 * time.Millisecond
+	CommandLifetime      = 1 * time.Second
+)
+
+// ErrRepoResponse is returned when the repo responds a command with an error status code.
+type ErrRepoResponse struct {
+	StatusCode uint64
+}
+
+func (e ErrRepoResponse) Error() string {
+	return fmt.Sprintf("Repo command failed due to error %d", e.StatusCode)
+}
+
+// InsertOptions are the options of InsertWithOptions.
+type InsertOptions struct {
+	// Timeout is the time to wait for the repo to finish fetching.
+	Timeout time.Duration
+	// CheckInterval is the interval of insert check commands.
+	CheckInterval time.Duration
+	// Signer signs the command Interests. A DigestSha256 signer is used if nil.
+	Signer ndn.Signer
+	// StartBlockId and EndBlockId optionally gives the range of segments to insert.
+	// For a single Data packet, both should be nil.
+	StartBlockId *uint64
+	EndBlockId   *uint64
+}
+
+// Insert asks the repo at repoPrefix to fetch and store the Data named dataName, and waits until it finishes.
+// The repo fetches the Data from the network, so a producer must be serving it.
+// The signature of the replies from the repo is not validated.
+func Insert(app ndn.Engine, repoPrefix enc.Name, dataName enc.Name) error {
+	return InsertWithOptions(app, repoPrefix, dataName, &InsertOptions{})
+}
+
+// InsertWithOptions is the same as Insert but allows setting the options.
+func InsertWithOptions(app ndn.Engine, repoPrefix enc.Name, dataName enc.Name, opts *InsertOptions) error {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultInsertTimeout
+	}
+	interval := opts.CheckInterval
+	if interval <= 0 {
+		interval = DefaultCheckInterval
+	}
+	signer := opts.Signer
+	if signer == nil {
+		signer = sec.NewSha256IntSigner(app.Timer())
+	}
+	deadline := app.Timer().Now().Add(timeout)
+
+	param := &RepoCommandParameterVal{
+		Name:         dataName,
+		StartBlockId: opts.StartBlockId,
+		EndBlockId:   opts.EndBlockId,
+	}
+	resp, err := execCommand(app, repoPrefix, "insert", param, signer)
+	if err != nil {
+		return err
+	}
+	switch resp.StatusCode {
+	case StatusCompleted:
+		return nil
+	case StatusAccepted, StatusInProgress:
+	default:
+		return ErrRepoResponse{StatusCode: resp.StatusCode}
+	}
+	if resp.ProcessId == nil {
+		return ndn.ErrInvalidValue{Item: "ProcessId", Value: nil}
+	}
+
+	checkParam := &RepoCommandParameterVal{
+		Name:      dataName,
+		ProcessId: resp.ProcessId,
+	}
+	for {
+		if !app.Timer().Now().Add(interval).Before(deadline) {
+			return ndn.ErrDeadlineExceed
+		}
+		app.Timer().Sleep(interval)
+		resp, err = execCommand(app, repoPrefix, "insert check", checkParam, signer)
+		if err == ndn.ErrDeadlineExceed {
+			// A lost check command is retried at the next interval
+			continue
+		} else if err != nil {
+			return err
+		}
+		switch resp.StatusCode {
+		case StatusCompleted:
+			return nil
+		case StatusAccepted, StatusInProgress:
+		default:
+			return ErrRepoResponse{StatusCode: resp.StatusCode}
+		}
+	}
+}
+
+// execCommand sends the command Interest /<repoPrefix>/<verb>/<param> and waits for the response.
+func execCommand(
+	app ndn.Engine, repoPrefix enc.Name, verb string, param *RepoCommandParameterVal, signer ndn.Signer,
+) (*RepoCommandResponseVal, error) {
+	name := make(enc.Name, len(repoPrefix), len(repoPrefix)+2)
+	copy(name, repoPrefix)
+	name = append(name,
+		enc.NewStringComponent(enc.TypeGenericNameComponent, verb),
+		enc.NewBytesComponent(enc.TypeGenericNameComponent, (&RepoCommandParameter{Val: param}).Bytes()),
+	)
+	intCfg := &ndn.InterestConfig{
+		Lifetime: utils.IdPtr(CommandLifetime),
+		Nonce:    utils.ConvertNonce(app.Timer().Nonce()),
+	}
+	wire, _, finalName, err := app.Spec().MakeInterest(name, intCfg, enc.Wire{}, signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate command Interest: %w", err)
+	}
+
+	type result struct {
+		val *RepoCommandResponseVal
+		err error
+	}
+	ch := make(chan result, 1)
+	err = app.Express(finalName, intCfg, wire,
+		func(res ndn.InterestResult, data ndn.Data, _ enc.Wire, _ enc.Wire, nackReason uint64) {
+			switch res {
+			case ndn.InterestResultNack:
+				ch <- result{err: fmt.Errorf("nack received: %v", nackReason)}
+			case ndn.InterestResultTimeout:
+				ch <- result{err: ndn.ErrDeadlineExceed}
+			case ndn.InterestResultData:
+				ret, err := ParseRepoCommandResponse(enc.NewWireReader(data.Content()), true)
+				if err != nil {
+					ch <- result{err: err}
+				} else if ret.Val == nil {
+					ch <- result{err: fmt.Errorf("improper response")}
+				} else {
+					ch <- result{val: ret.Val}
+				}
+			default:
+				ch <- result{err: fmt.Errorf("unknown result: %v", res)}
+			}
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to express command Interest: %w", err)
+	}
+	ret := <-ch
+	return ret.val, ret.err
+}
@@ -0,0 +1,160 @@
+package repo_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	basic_engine "github.com/zjkmxy/go-ndn/pkg/engine/basic"
+	"github.com/zjkmxy/go-ndn/pkg/engine/dummy"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/repo"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+// mockRepo fetches the Data on an insert command, and reports StatusInProgress to checks until it is fetched.
+type mockRepo struct {
+	engine *basic_engine.Engine
+	// status overrides the status code of insert commands if not 0
+	status  uint64
+	fetched atomic.Bool
+	checks  atomic.Int32
+}
+
+func newEngine(t *testing.T, face basic_engine.Face) *basic_engine.Engine {
+	timer := basic_engine.NewTimer()
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool { return true }
+	engine := basic_engine.NewEngine(face, timer, sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(t, engine.Start())
+	return engine
+}
+
+func (r *mockRepo) respond(interest ndn.Interest, reply ndn.ReplyFunc, val *repo.RepoCommandResponseVal) {
+	data, _, err := r.engine.Spec().MakeData(interest.Name(), &ndn.DataConfig{
+		ContentType: utils.IdPtr(ndn.ContentTypeBlob),
+	}, (&repo.RepoCommandResponse{Val: val}).Encode(), sec.NewSha256Signer())
+	if err == nil {
+		reply(data)
+	}
+}
+
+func (r *mockRepo) onCommand(
+	interest ndn.Interest, rawInterest enc.Wire, sigCovered enc.Wire, reply ndn.ReplyFunc, deadline time.Time,
+) {
+	name := interest.Name()
+	param, err := repo.ParseRepoCommandParameter(enc.NewBufferReader(name[2].Val), true)
+	if err != nil || param.Val == nil {
+		r.respond(interest, reply, &repo.RepoCommandResponseVal{StatusCode: 403})
+		return
+	}
+	switch string(name[1].Val) {
+	case "insert":
+		if r.status != 0 {
+			r.respond(interest, reply, &repo.RepoCommandResponseVal{StatusCode: r.status})
+			return
+		}
+		dataName := param.Val.Name
+		go func() {
+			intCfg := &ndn.InterestConfig{Lifetime: utils.IdPtr(time.Second)}
+			wire, _, finalName, err := r.engine.Spec().MakeInterest(dataName, intCfg, nil, nil)
+			if err != nil {
+				return
+			}
+			r.engine.Express(finalName, intCfg, wire,
+				func(res ndn.InterestResult, data ndn.Data, _ enc.Wire, _ enc.Wire, _ uint64) {
+					if res == ndn.InterestResultData && string(data.Content().Join()) == "hello" {
+						r.fetched.Store(true)
+					}
+				})
+		}()
+		r.respond(interest, reply, &repo.RepoCommandResponseVal{
+			Name:       param.Val.Name,
+			ProcessId:  utils.IdPtr(uint64(7)),
+			StatusCode: repo.StatusAccepted,
+		})
+	case "insert check":
+		r.checks.Add(1)
+		if param.Val.ProcessId == nil || *param.Val.ProcessId != 7 {
+			r.respond(interest, reply, &repo.RepoCommandResponseVal{StatusCode: 404})
+			return
+		}
+		status := repo.StatusInProgress
+		if r.fetched.Load() {
+			status = repo.StatusCompleted
+		}
+		r.respond(interest, reply, &repo.RepoCommandResponseVal{
+			Name:       param.Val.Name,
+			ProcessId:  param.Val.ProcessId,
+			StatusCode: status,
+		})
+	}
+}
+
+func setup(t *testing.T, repoStatus uint64, serveData bool) (*basic_engine.Engine, *mockRepo, func()) {
+	faceC, faceR := dummy.NewPipeFaces()
+	client := newEngine(t, faceC)
+	r := &mockRepo{engine: newEngine(t, faceR), status: repoStatus}
+	require.NoError(t, r.engine.AttachHandler(utils.WithoutErr(enc.NameFromStr("/repo")), r.onCommand))
+	if serveData {
+		require.NoError(t, client.AttachHandler(utils.WithoutErr(enc.NameFromStr("/data")), func(
+			interest ndn.Interest, rawInterest enc.Wire, sigCovered enc.Wire, reply ndn.ReplyFunc, deadline time.Time,
+		) {
+			data, _, err := client.Spec().MakeData(interest.Name(), &ndn.DataConfig{
+				ContentType: utils.IdPtr(ndn.ContentTypeBlob),
+			}, enc.Wire{[]byte("hello")}, sec.NewSha256Signer())
+			if err == nil {
+				reply(data)
+			}
+		}))
+	}
+	return client, r, func() {
+		require.NoError(t, client.Shutdown())
+		require.NoError(t, r.engine.Shutdown())
+	}
+}
+
+func TestInsert(t *testing.T) {
+	utils.SetTestingT(t)
+
+	client, r, shutdown := setup(t, 0, true)
+	defer shutdown()
+	repoPrefix := utils.WithoutErr(enc.NameFromStr("/repo"))
+	dataName := utils.WithoutErr(enc.NameFromStr("/data/v=1"))
+	require.NoError(t, repo.InsertWithOptions(client, repoPrefix, dataName, &repo.InsertOptions{
+		Timeout:       3 * time.Second,
+		CheckInterval: 20 * time.Millisecond,
+	}))
+	require.True(t, r.fetched.Load())
+	require.GreaterOrEqual(t, r.checks.Load(), int32(1))
+}
+
+func TestInsertFailed(t *testing.T) {
+	utils.SetTestingT(t)
+
+	client, _, shutdown := setup(t, 403, true)
+	defer shutdown()
+	repoPrefix := utils.WithoutErr(enc.NameFromStr("/repo"))
+	dataName := utils.WithoutErr(enc.NameFromStr("/data/v=1"))
+	err := repo.Insert(client, repoPrefix, dataName)
+	require.Equal(t, repo.ErrRepoResponse{StatusCode: 403}, err)
+}
+
+func TestInsertTimeout(t *testing.T) {
+	utils.SetTestingT(t)
+
+	// The repo cannot fetch the Data, so the insertion never completes
+	client, r, shutdown := setup(t, 0, false)
+	defer shutdown()
+	repoPrefix := utils.WithoutErr(enc.NameFromStr("/repo"))
+	dataName := utils.WithoutErr(enc.NameFromStr("/data/v=1"))
+	err := repo.InsertWithOptions(client, repoPrefix, dataName, &repo.InsertOptions{
+		Timeout:       300 * time.Millisecond,
+		CheckInterval: 50 * time.Millisecond,
+	})
+	require.Equal(t, ndn.ErrDeadlineExceed, err)
+	require.False(t, r.fetched.Load())
+	require.GreaterOrEqual(t, r.checks.Load(), int32(2))
+}
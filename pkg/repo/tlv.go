@@ -0,0 +1,48 @@
+//go:generate gondn_tlv_gen
+package repo
+
+import (
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+)
+
+type RepoCommandParameterVal struct {
+	//+field:name
+	Name enc.Name `tlv:"0x07"`
+	//+field:natural:optional
+	StartBlockId *uint64 `tlv:"0xcc"`
+	//+field:natural:optional
+	EndBlockId *uint64 `tlv:"0xcd"`
+	//+field:natural:optional
+	ProcessId *uint64 `tlv:"0xce"`
+	//+field:natural:optional
+	InterestLifetime *uint64 `tlv:"0xd6"`
+}
+
+// RepoCommandParameter is carried as a name component of repo command Interests.
+type RepoCommandParameter struct {
+	//+field:struct:RepoCommandParameterVal
+	Val *RepoCommandParameterVal `tlv:"0xc9"`
+}
+
+type RepoCommandResponseVal struct {
+	//+field:name
+	Name enc.Name `tlv:"0x07"`
+	//+field:natural:optional
+	StartBlockId *uint64 `tlv:"0xcc"`
+	//+field:natural:optional
+	EndBlockId *uint64 `tlv:"0xcd"`
+	//+field:natural:optional
+	ProcessId *uint64 `tlv:"0xce"`
+	//+field:natural
+	StatusCode uint64 `tlv:"0xd0"`
+	//+field:natural:optional
+	InsertNum *uint64 `tlv:"0xd1"`
+	//+field:natural:optional
+	DeleteNum *uint64 `tlv:"0xd2"`
+}
+
+// RepoCommandResponse is the content of the Data replying repo command Interests.
+type RepoCommandResponse struct {
+	//+field:struct:RepoCommandResponseVal
+	Val *RepoCommandResponseVal `tlv:"0xcf"`
+}
@@ -0,0 +1,1320 @@
+// Generated by the generator, DO NOT modify manually
+package repo
+
+import (
+	"encoding/binary"
+	"io"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+)
+
+type RepoCommandParameterValEncoder struct {
+	length uint
+
+	Name_length uint
+}
+
+type RepoCommandParameterValParsingContext struct {
+}
+
+func (encoder *RepoCommandParameterValEncoder) Init(value *RepoCommandParameterVal) {
+	if value.Name != nil {
+		encoder.Name_length = 0
+		for _, c := range value.Name {
+			encoder.Name_length += uint(c.EncodingLength())
+		}
+	}
+
+	l := uint(0)
+	if value.Name != nil {
+		l += 1
+		switch x := encoder.Name_length; {
+		case x <= 0xfc:
+			l += 1
+		case x <= 0xffff:
+			l += 3
+		case x <= 0xffffffff:
+			l += 5
+		default:
+			l += 9
+		}
+		l += encoder.Name_length
+	}
+
+	if value.StartBlockId != nil {
+		l += 1
+		switch x := *value.StartBlockId; {
+		case x <= 0xff:
+			l += 2
+		case x <= 0xffff:
+			l += 3
+		case x <= 0xffffffff:
+			l += 5
+		default:
+			l += 9
+		}
+	}
+
+	if value.EndBlockId != nil {
+		l += 1
+		switch x := *value.EndBlockId; {
+		case x <= 0xff:
+			l += 2
+		case x <= 0xffff:
+			l += 3
+		case x <= 0xffffffff:
+			l += 5
+		default:
+			l += 9
+		}
+	}
+
+	if value.ProcessId != nil {
+		l += 1
+		switch x := *value.ProcessId; {
+		case x <= 0xff:
+			l += 2
+		case x <= 0xffff:
+			l += 3
+		case x <= 0xffffffff:
+			l += 5
+		default:
+			l += 9
+		}
+	}
+
+	if value.InterestLifetime != nil {
+		l += 1
+		switch x := *value.InterestLifetime; {
+		case x <= 0xff:
+			l += 2
+		case x <= 0xffff:
+			l += 3
+		case x <= 0xffffffff:
+			l += 5
+		default:
+			l += 9
+		}
+	}
+
+	encoder.length = l
+
+}
+
+func (context *RepoCommandParameterValParsingContext) Init() {
+
+}
+
+func (encoder *RepoCommandParameterValEncoder) EncodeInto(value *RepoCommandParameterVal, buf []byte) {
+
+	pos := uint(0)
+	if value.Name != nil {
+		buf[pos] = byte(7)
+		pos += 1
+		switch x := encoder.Name_length; {
+		case x <= 0xfc:
+			buf[pos] = byte(x)
+			pos += 1
+		case x <= 0xffff:
+			buf[pos] = 0xfd
+			binary.BigEndian.PutUint16(buf[pos+1:], uint16(x))
+			pos += 3
+		case x <= 0xffffffff:
+			buf[pos] = 0xfe
+			binary.BigEndian.PutUint32(buf[pos+1:], uint32(x))
+			pos += 5
+		default:
+			buf[pos] = 0xff
+			binary.BigEndian.PutUint64(buf[pos+1:], uint64(x))
+			pos += 9
+		}
+		for _, c := range value.Name {
+			pos += uint(c.EncodeInto(buf[pos:]))
+		}
+	}
+
+	if value.StartBlockId != nil {
+		buf[pos] = byte(204)
+		pos += 1
+		switch x := *value.StartBlockId; {
+		case x <= 0xff:
+			buf[pos] = 1
+			buf[pos+1] = byte(x)
+			pos += 2
+		case x <= 0xffff:
+			buf[pos] = 2
+			binary.BigEndian.PutUint16(buf[pos+1:], uint16(x))
+			pos += 3
+		case x <= 0xffffffff:
+			buf[pos] = 4
+			binary.BigEndian.PutUint32(buf[pos+1:], uint32(x))
+			pos += 5
+		default:
+			buf[pos] = 8
+			binary.BigEndian.PutUint64(buf[pos+1:], uint64(x))
+			pos += 9
+		}
+	}
+
+	if value.EndBlockId != nil {
+		buf[pos] = byte(205)
+		pos += 1
+		switch x := *value.EndBlockId; {
+		case x <= 0xff:
+			buf[pos] = 1
+			buf[pos+1] = byte(x)
+			pos += 2
+		case x <= 0xffff:
+			buf[pos] = 2
+			binary.BigEndian.PutUint16(buf[pos+1:], uint16(x))
+			pos += 3
+		case x <= 0xffffffff:
+			buf[pos] = 4
+			binary.BigEndian.PutUint32(buf[pos+1:], uint32(x))
+			pos += 5
+		default:
+			buf[pos] = 8
+			binary.BigEndian.PutUint64(buf[pos+1:], uint64(x))
+			pos += 9
+		}
+	}
+
+	if value.ProcessId != nil {
+		buf[pos] = byte(206)
+		pos += 1
+		switch x := *value.ProcessId; {
+		case x <= 0xff:
+			buf[pos] = 1
+			buf[pos+1] = byte(x)
+			pos += 2
+		case x <= 0xffff:
+			buf[pos] = 2
+			binary.BigEndian.PutUint16(buf[pos+1:], uint16(x))
+			pos += 3
+		case x <= 0xffffffff:
+			buf[pos] = 4
+			binary.BigEndian.PutUint32(buf[pos+1:], uint32(x))
+			pos += 5
+		default:
+			buf[pos] = 8
+			binary.BigEndian.PutUint64(buf[pos+1:], uint64(x))
+			pos += 9
+		}
+	}
+
+	if value.InterestLifetime != nil {
+		buf[pos] = byte(214)
+		pos += 1
+		switch x := *value.InterestLifetime; {
+		case x <= 0xff:
+			buf[pos] = 1
+			buf[pos+1] = byte(x)
+			pos += 2
+		case x <= 0xffff:
+			buf[pos] = 2
+			binary.BigEndian.PutUint16(buf[pos+1:], uint16(x))
+			pos += 3
+		case x <= 0xffffffff:
+			buf[pos] = 4
+			binary.BigEndian.PutUint32(buf[pos+1:], uint32(x))
+			pos += 5
+		default:
+			buf[pos] = 8
+			binary.BigEndian.PutUint64(buf[pos+1:], uint64(x))
+			pos += 9
+		}
+	}
+
+}
+
+func (encoder *RepoCommandParameterValEncoder) Encode(value *RepoCommandParameterVal) enc.Wire {
+
+	wire := make(enc.Wire, 1)
+	wire[0] = make([]byte, encoder.length)
+	buf := wire[0]
+	encoder.EncodeInto(value, buf)
+
+	return wire
+}
+
+func (context *RepoCommandParameterValParsingContext) Parse(reader enc.ParseReader, ignoreCritical bool) (*RepoCommandParameterVal, error) {
+	if reader == nil {
+		return nil, enc.ErrBufferOverflow
+	}
+	progress := -1
+	value := &RepoCommandParameterVal{}
+	var err error
+	var startPos int
+	for {
+		startPos = reader.Pos()
+		if startPos >= reader.Length() {
+			break
+		}
+		typ := enc.TLNum(0)
+		l := enc.TLNum(0)
+		typ, err = enc.ReadTLNum(reader)
+		if err != nil {
+			return nil, enc.ErrFailToParse{TypeNum: 0, Err: err}
+		}
+		l, err = enc.ReadTLNum(reader)
+		if err != nil {
+			return nil, enc.ErrFailToParse{TypeNum: 0, Err: err}
+		}
+		err = nil
+		for handled := false; !handled; progress++ {
+			switch typ {
+			case 7:
+				if progress+1 == 0 {
+					handled = true
+					value.Name = make(enc.Name, l/2+1)
+					startName := reader.Pos()
+					endName := startName + int(l)
+					for j := range value.Name {
+						if reader.Pos() >= endName {
+							value.Name = value.Name[:j]
+							break
+						}
+						var err1, err3 error
+						value.Name[j].Typ, err1 = enc.ReadTLNum(reader)
+						l, err2 := enc.ReadTLNum(reader)
+						value.Name[j].Val, err3 = reader.ReadBuf(int(l))
+						if err1 != nil || err2 != nil || err3 != nil {
+							err = io.ErrUnexpectedEOF
+							break
+						}
+					}
+					if err == nil && reader.Pos() != endName {
+						err = enc.ErrBufferOverflow
+					}
+
+				}
+			case 204:
+				if progress+1 == 1 {
+					handled = true
+					{
+						tempVal := uint64(0)
+						tempVal = uint64(0)
+						{
+							for i := 0; i < int(l); i++ {
+								x := byte(0)
+								x, err = reader.ReadByte()
+								if err != nil {
+									if err == io.EOF {
+										err = io.ErrUnexpectedEOF
+									}
+									break
+								}
+								tempVal = uint64(tempVal<<8) | uint64(x)
+							}
+						}
+						value.StartBlockId = &tempVal
+					}
+
+				}
+			case 205:
+				if progress+1 == 2 {
+					handled = true
+					{
+						tempVal := uint64(0)
+						tempVal = uint64(0)
+						{
+							for i := 0; i < int(l); i++ {
+								x := byte(0)
+								x, err = reader.ReadByte()
+								if err != nil {
+									if err == io.EOF {
+										err = io.ErrUnexpectedEOF
+									}
+									break
+								}
+								tempVal = uint64(tempVal<<8) | uint64(x)
+							}
+						}
+						value.EndBlockId = &tempVal
+					}
+
+				}
+			case 206:
+				if progress+1 == 3 {
+					handled = true
+					{
+						tempVal := uint64(0)
+						tempVal = uint64(0)
+						{
+							for i := 0; i < int(l); i++ {
+								x := byte(0)
+								x, err = reader.ReadByte()
+								if err != nil {
+									if err == io.EOF {
+										err = io.ErrUnexpectedEOF
+									}
+									break
+								}
+								tempVal = uint64(tempVal<<8) | uint64(x)
+							}
+						}
+						value.ProcessId = &tempVal
+					}
+
+				}
+			case 214:
+				if progress+1 == 4 {
+					handled = true
+					{
+						tempVal := uint64(0)
+						tempVal = uint64(0)
+						{
+							for i := 0; i < int(l); i++ {
+								x := byte(0)
+								x, err = reader.ReadByte()
+								if err != nil {
+									if err == io.EOF {
+										err = io.ErrUnexpectedEOF
+									}
+									break
+								}
+								tempVal = uint64(tempVal<<8) | uint64(x)
+							}
+						}
+						value.InterestLifetime = &tempVal
+					}
+
+				}
+			default:
+				handled = true
+				if !ignoreCritical && ((typ <= 31) || ((typ & 1) == 1)) {
+					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
+				}
+				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
+			}
+			if err == nil && !handled {
+				switch progress {
+				case 0 - 1:
+					value.Name = nil
+				case 1 - 1:
+					value.StartBlockId = nil
+				case 2 - 1:
+					value.EndBlockId = nil
+				case 3 - 1:
+					value.ProcessId = nil
+				case 4 - 1:
+					value.InterestLifetime = nil
+				}
+			}
+			if err != nil {
+				return nil, enc.ErrFailToParse{TypeNum: typ, Err: err}
+			}
+		}
+	}
+	startPos = reader.Pos()
+	for ; progress < 5; progress++ {
+		switch progress {
+		case 0 - 1:
+			value.Name = nil
+		case 1 - 1:
+			value.StartBlockId = nil
+		case 2 - 1:
+			value.EndBlockId = nil
+		case 3 - 1:
+			value.ProcessId = nil
+		case 4 - 1:
+			value.InterestLifetime = nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (value *RepoCommandParameterVal) Encode() enc.Wire {
+	encoder := RepoCommandParameterValEncoder{}
+	encoder.Init(value)
+	return encoder.Encode(value)
+}
+
+func (value *RepoCommandParameterVal) Bytes() []byte {
+	return value.Encode().Join()
+}
+
+func ParseRepoCommandParameterVal(reader enc.ParseReader, ignoreCritical bool) (*RepoCommandParameterVal, error) {
+	context := RepoCommandParameterValParsingContext{}
+	context.Init()
+	return context.Parse(reader, ignoreCritical)
+}
+
+type RepoCommandParameterEncoder struct {
+	length uint
+
+	Val_encoder RepoCommandParameterValEncoder
+}
+
+type RepoCommandParameterParsingContext struct {
+	Val_context RepoCommandParameterValParsingContext
+}
+
+func (encoder *RepoCommandParameterEncoder) Init(value *RepoCommandParameter) {
+	if value.Val != nil {
+		encoder.Val_encoder.Init(value.Val)
+	}
+	l := uint(0)
+	if value.Val != nil {
+		l += 1
+		switch x := encoder.Val_encoder.length; {
+		case x <= 0xfc:
+			l += 1
+		case x <= 0xffff:
+			l += 3
+		case x <= 0xffffffff:
+			l += 5
+		default:
+			l += 9
+		}
+		l += encoder.Val_encoder.length
+	}
+
+	encoder.length = l
+
+}
+
+func (context *RepoCommandParameterParsingContext) Init() {
+	context.Val_context.Init()
+}
+
+func (encoder *RepoCommandParameterEncoder) EncodeInto(value *RepoCommandParameter, buf []byte) {
+
+	pos := uint(0)
+	if value.Val != nil {
+		buf[pos] = byte(201)
+		pos += 1
+		switch x := encoder.Val_encoder.length; {
+		case x <= 0xfc:
+			buf[pos] = byte(x)
+			pos += 1
+		case x <= 0xffff:
+			buf[pos] = 0xfd
+			binary.BigEndian.PutUint16(buf[pos+1:], uint16(x))
+			pos += 3
+		case x <= 0xffffffff:
+			buf[pos] = 0xfe
+			binary.BigEndian.PutUint32(buf[pos+1:], uint32(x))
+			pos += 5
+		default:
+			buf[pos] = 0xff
+			binary.BigEndian.PutUint64(buf[pos+1:], uint64(x))
+			pos += 9
+		}
+		if encoder.Val_encoder.length > 0 {
+			encoder.Val_encoder.EncodeInto(value.Val, buf[pos:])
+			pos += encoder.Val_encoder.length
+		}
+	}
+
+}
+
+func (encoder *RepoCommandParameterEncoder) Encode(value *RepoCommandParameter) enc.Wire {
+
+	wire := make(enc.Wire, 1)
+	wire[0] = make([]byte, encoder.length)
+	buf := wire[0]
+	encoder.EncodeInto(value, buf)
+
+	return wire
+}
+
+func (context *RepoCommandParameterParsingContext) Parse(reader enc.ParseReader, ignoreCritical bool) (*RepoCommandParameter, error) {
+	if reader == nil {
+		return nil, enc.ErrBufferOverflow
+	}
+	progress := -1
+	value := &RepoCommandParameter{}
+	var err error
+	var startPos int
+	for {
+		startPos = reader.Pos()
+		if startPos >= reader.Length() {
+			break
+		}
+		typ := enc.TLNum(0)
+		l := enc.TLNum(0)
+		typ, err = enc.ReadTLNum(reader)
+		if err != nil {
+			return nil, enc.ErrFailToParse{TypeNum: 0, Err: err}
+		}
+		l, err = enc.ReadTLNum(reader)
+		if err != nil {
+			return nil, enc.ErrFailToParse{TypeNum: 0, Err: err}
+		}
+		err = nil
+		for handled := false; !handled; progress++ {
+			switch typ {
+			case 201:
+				if progress+1 == 0 {
+					handled = true
+					value.Val, err = context.Val_context.Parse(reader.Delegate(int(l)), ignoreCritical)
+				}
+			default:
+				handled = true
+				if !ignoreCritical && ((typ <= 31) || ((typ & 1) == 1)) {
+					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
+				}
+				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
+			}
+			if err == nil && !handled {
+				switch progress {
+				case 0 - 1:
+					value.Val = nil
+				}
+			}
+			if err != nil {
+				return nil, enc.ErrFailToParse{TypeNum: typ, Err: err}
+			}
+		}
+	}
+	startPos = reader.Pos()
+	for ; progress < 1; progress++ {
+		switch progress {
+		case 0 - 1:
+			value.Val = nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (value *RepoCommandParameter) Encode() enc.Wire {
+	encoder := RepoCommandParameterEncoder{}
+	encoder.Init(value)
+	return encoder.Encode(value)
+}
+
+func (value *RepoCommandParameter) Bytes() []byte {
+	return value.Encode().Join()
+}
+
+func ParseRepoCommandParameter(reader enc.ParseReader, ignoreCritical bool) (*RepoCommandParameter, error) {
+	context := RepoCommandParameterParsingContext{}
+	context.Init()
+	return context.Parse(reader, ignoreCritical)
+}
+
+type RepoCommandResponseValEncoder struct {
+	length uint
+
+	Name_length uint
+}
+
+type RepoCommandResponseValParsingContext struct {
+}
+
+func (encoder *RepoCommandResponseValEncoder) Init(value *RepoCommandResponseVal) {
+	if value.Name != nil {
+		encoder.Name_length = 0
+		for _, c := range value.Name {
+			encoder.Name_length += uint(c.EncodingLength())
+		}
+	}
+
+	l := uint(0)
+	if value.Name != nil {
+		l += 1
+		switch x := encoder.Name_length; {
+		case x <= 0xfc:
+			l += 1
+		case x <= 0xffff:
+			l += 3
+		case x <= 0xffffffff:
+			l += 5
+		default:
+			l += 9
+		}
+		l += encoder.Name_length
+	}
+
+	if value.StartBlockId != nil {
+		l += 1
+		switch x := *value.StartBlockId; {
+		case x <= 0xff:
+			l += 2
+		case x <= 0xffff:
+			l += 3
+		case x <= 0xffffffff:
+			l += 5
+		default:
+			l += 9
+		}
+	}
+
+	if value.EndBlockId != nil {
+		l += 1
+		switch x := *value.EndBlockId; {
+		case x <= 0xff:
+			l += 2
+		case x <= 0xffff:
+			l += 3
+		case x <= 0xffffffff:
+			l += 5
+		default:
+			l += 9
+		}
+	}
+
+	if value.ProcessId != nil {
+		l += 1
+		switch x := *value.ProcessId; {
+		case x <= 0xff:
+			l += 2
+		case x <= 0xffff:
+			l += 3
+		case x <= 0xffffffff:
+			l += 5
+		default:
+			l += 9
+		}
+	}
+
+	l += 1
+	switch x := value.StatusCode; {
+	case x <= 0xff:
+		l += 2
+	case x <= 0xffff:
+		l += 3
+	case x <= 0xffffffff:
+		l += 5
+	default:
+		l += 9
+	}
+
+	if value.InsertNum != nil {
+		l += 1
+		switch x := *value.InsertNum; {
+		case x <= 0xff:
+			l += 2
+		case x <= 0xffff:
+			l += 3
+		case x <= 0xffffffff:
+			l += 5
+		default:
+			l += 9
+		}
+	}
+
+	if value.DeleteNum != nil {
+		l += 1
+		switch x := *value.DeleteNum; {
+		case x <= 0xff:
+			l += 2
+		case x <= 0xffff:
+			l += 3
+		case x <= 0xffffffff:
+			l += 5
+		default:
+			l += 9
+		}
+	}
+
+	encoder.length = l
+
+}
+
+func (context *RepoCommandResponseValParsingContext) Init() {
+
+}
+
+func (encoder *RepoCommandResponseValEncoder) EncodeInto(value *RepoCommandResponseVal, buf []byte) {
+
+	pos := uint(0)
+	if value.Name != nil {
+		buf[pos] = byte(7)
+		pos += 1
+		switch x := encoder.Name_length; {
+		case x <= 0xfc:
+			buf[pos] = byte(x)
+			pos += 1
+		case x <= 0xffff:
+			buf[pos] = 0xfd
+			binary.BigEndian.PutUint16(buf[pos+1:], uint16(x))
+			pos += 3
+		case x <= 0xffffffff:
+			buf[pos] = 0xfe
+			binary.BigEndian.PutUint32(buf[pos+1:], uint32(x))
+			pos += 5
+		default:
+			buf[pos] = 0xff
+			binary.BigEndian.PutUint64(buf[pos+1:], uint64(x))
+			pos += 9
+		}
+		for _, c := range value.Name {
+			pos += uint(c.EncodeInto(buf[pos:]))
+		}
+	}
+
+	if value.StartBlockId != nil {
+		buf[pos] = byte(204)
+		pos += 1
+		switch x := *value.StartBlockId; {
+		case x <= 0xff:
+			buf[pos] = 1
+			buf[pos+1] = byte(x)
+			pos += 2
+		case x <= 0xffff:
+			buf[pos] = 2
+			binary.BigEndian.PutUint16(buf[pos+1:], uint16(x))
+			pos += 3
+		case x <= 0xffffffff:
+			buf[pos] = 4
+			binary.BigEndian.PutUint32(buf[pos+1:], uint32(x))
+			pos += 5
+		default:
+			buf[pos] = 8
+			binary.BigEndian.PutUint64(buf[pos+1:], uint64(x))
+			pos += 9
+		}
+	}
+
+	if value.EndBlockId != nil {
+		buf[pos] = byte(205)
+		pos += 1
+		switch x := *value.EndBlockId; {
+		case x <= 0xff:
+			buf[pos] = 1
+			buf[pos+1] = byte(x)
+			pos += 2
+		case x <= 0xffff:
+			buf[pos] = 2
+			binary.BigEndian.PutUint16(buf[pos+1:], uint16(x))
+			pos += 3
+		case x <= 0xffffffff:
+			buf[pos] = 4
+			binary.BigEndian.PutUint32(buf[pos+1:], uint32(x))
+			pos += 5
+		default:
+			buf[pos] = 8
+			binary.BigEndian.PutUint64(buf[pos+1:], uint64(x))
+			pos += 9
+		}
+	}
+
+	if value.ProcessId != nil {
+		buf[pos] = byte(206)
+		pos += 1
+		switch x := *value.ProcessId; {
+		case x <= 0xff:
+			buf[pos] = 1
+			buf[pos+1] = byte(x)
+			pos += 2
+		case x <= 0xffff:
+			buf[pos] = 2
+			binary.BigEndian.PutUint16(buf[pos+1:], uint16(x))
+			pos += 3
+		case x <= 0xffffffff:
+			buf[pos] = 4
+			binary.BigEndian.PutUint32(buf[pos+1:], uint32(x))
+			pos += 5
+		default:
+			buf[pos] = 8
+			binary.BigEndian.PutUint64(buf[pos+1:], uint64(x))
+			pos += 9
+		}
+	}
+
+	buf[pos] = byte(208)
+	pos += 1
+	switch x := value.StatusCode; {
+	case x <= 0xff:
+		buf[pos] = 1
+		buf[pos+1] = byte(x)
+		pos += 2
+	case x <= 0xffff:
+		buf[pos] = 2
+		binary.BigEndian.PutUint16(buf[pos+1:], uint16(x))
+		pos += 3
+	case x <= 0xffffffff:
+		buf[pos] = 4
+		binary.BigEndian.PutUint32(buf[pos+1:], uint32(x))
+		pos += 5
+	default:
+		buf[pos] = 8
+		binary.BigEndian.PutUint64(buf[pos+1:], uint64(x))
+		pos += 9
+	}
+
+	if value.InsertNum != nil {
+		buf[pos] = byte(209)
+		pos += 1
+		switch x := *value.InsertNum; {
+		case x <= 0xff:
+			buf[pos] = 1
+			buf[pos+1] = byte(x)
+			pos += 2
+		case x <= 0xffff:
+			buf[pos] = 2
+			binary.BigEndian.PutUint16(buf[pos+1:], uint16(x))
+			pos += 3
+		case x <= 0xffffffff:
+			buf[pos] = 4
+			binary.BigEndian.PutUint32(buf[pos+1:], uint32(x))
+			pos += 5
+		default:
+			buf[pos] = 8
+			binary.BigEndian.PutUint64(buf[pos+1:], uint64(x))
+			pos += 9
+		}
+	}
+
+	if value.DeleteNum != nil {
+		buf[pos] = byte(210)
+		pos += 1
+		switch x := *value.DeleteNum; {
+		case x <= 0xff:
+			buf[pos] = 1
+			buf[pos+1] = byte(x)
+			pos += 2
+		case x <= 0xffff:
+			buf[pos] = 2
+			binary.BigEndian.PutUint16(buf[pos+1:], uint16(x))
+			pos += 3
+		case x <= 0xffffffff:
+			buf[pos] = 4
+			binary.BigEndian.PutUint32(buf[pos+1:], uint32(x))
+			pos += 5
+		default:
+			buf[pos] = 8
+			binary.BigEndian.PutUint64(buf[pos+1:], uint64(x))
+			pos += 9
+		}
+	}
+
+}
+
+func (encoder *RepoCommandResponseValEncoder) Encode(value *RepoCommandResponseVal) enc.Wire {
+
+	wire := make(enc.Wire, 1)
+	wire[0] = make([]byte, encoder.length)
+	buf := wire[0]
+	encoder.EncodeInto(value, buf)
+
+	return wire
+}
+
+func (context *RepoCommandResponseValParsingContext) Parse(reader enc.ParseReader, ignoreCritical bool) (*RepoCommandResponseVal, error) {
+	if reader == nil {
+		return nil, enc.ErrBufferOverflow
+	}
+	progress := -1
+	value := &RepoCommandResponseVal{}
+	var err error
+	var startPos int
+	for {
+		startPos = reader.Pos()
+		if startPos >= reader.Length() {
+			break
+		}
+		typ := enc.TLNum(0)
+		l := enc.TLNum(0)
+		typ, err = enc.ReadTLNum(reader)
+		if err != nil {
+			return nil, enc.ErrFailToParse{TypeNum: 0, Err: err}
+		}
+		l, err = enc.ReadTLNum(reader)
+		if err != nil {
+			return nil, enc.ErrFailToParse{TypeNum: 0, Err: err}
+		}
+		err = nil
+		for handled := false; !handled; progress++ {
+			switch typ {
+			case 7:
+				if progress+1 == 0 {
+					handled = true
+					value.Name = make(enc.Name, l/2+1)
+					startName := reader.Pos()
+					endName := startName + int(l)
+					for j := range value.Name {
+						if reader.Pos() >= endName {
+							value.Name = value.Name[:j]
+							break
+						}
+						var err1, err3 error
+						value.Name[j].Typ, err1 = enc.ReadTLNum(reader)
+						l, err2 := enc.ReadTLNum(reader)
+						value.Name[j].Val, err3 = reader.ReadBuf(int(l))
+						if err1 != nil || err2 != nil || err3 != nil {
+							err = io.ErrUnexpectedEOF
+							break
+						}
+					}
+					if err == nil && reader.Pos() != endName {
+						err = enc.ErrBufferOverflow
+					}
+
+				}
+			case 204:
+				if progress+1 == 1 {
+					handled = true
+					{
+						tempVal := uint64(0)
+						tempVal = uint64(0)
+						{
+							for i := 0; i < int(l); i++ {
+								x := byte(0)
+								x, err = reader.ReadByte()
+								if err != nil {
+									if err == io.EOF {
+										err = io.ErrUnexpectedEOF
+									}
+									break
+								}
+								tempVal = uint64(tempVal<<8) | uint64(x)
+							}
+						}
+						value.StartBlockId = &tempVal
+					}
+
+				}
+			case 205:
+				if progress+1 == 2 {
+					handled = true
+					{
+						tempVal := uint64(0)
+						tempVal = uint64(0)
+						{
+							for i := 0; i < int(l); i++ {
+								x := byte(0)
+								x, err = reader.ReadByte()
+								if err != nil {
+									if err == io.EOF {
+										err = io.ErrUnexpectedEOF
+									}
+									break
+								}
+								tempVal = uint64(tempVal<<8) | uint64(x)
+							}
+						}
+						value.EndBlockId = &tempVal
+					}
+
+				}
+			case 206:
+				if progress+1 == 3 {
+					handled = true
+					{
+						tempVal := uint64(0)
+						tempVal = uint64(0)
+						{
+							for i := 0; i < int(l); i++ {
+								x := byte(0)
+								x, err = reader.ReadByte()
+								if err != nil {
+									if err == io.EOF {
+										err = io.ErrUnexpectedEOF
+									}
+									break
+								}
+								tempVal = uint64(tempVal<<8) | uint64(x)
+							}
+						}
+						value.ProcessId = &tempVal
+					}
+
+				}
+			case 208:
+				if progress+1 == 4 {
+					handled = true
+					value.StatusCode = uint64(0)
+					{
+						for i := 0; i < int(l); i++ {
+							x := byte(0)
+							x, err = reader.ReadByte()
+							if err != nil {
+								if err == io.EOF {
+									err = io.ErrUnexpectedEOF
+								}
+								break
+							}
+							value.StatusCode = uint64(value.StatusCode<<8) | uint64(x)
+						}
+					}
+				}
+			case 209:
+				if progress+1 == 5 {
+					handled = true
+					{
+						tempVal := uint64(0)
+						tempVal = uint64(0)
+						{
+							for i := 0; i < int(l); i++ {
+								x := byte(0)
+								x, err = reader.ReadByte()
+								if err != nil {
+									if err == io.EOF {
+										err = io.ErrUnexpectedEOF
+									}
+									break
+								}
+								tempVal = uint64(tempVal<<8) | uint64(x)
+							}
+						}
+						value.InsertNum = &tempVal
+					}
+
+				}
+			case 210:
+				if progress+1 == 6 {
+					handled = true
+					{
+						tempVal := uint64(0)
+						tempVal = uint64(0)
+						{
+							for i := 0; i < int(l); i++ {
+								x := byte(0)
+								x, err = reader.ReadByte()
+								if err != nil {
+									if err == io.EOF {
+										err = io.ErrUnexpectedEOF
+									}
+									break
+								}
+								tempVal = uint64(tempVal<<8) | uint64(x)
+							}
+						}
+						value.DeleteNum = &tempVal
+					}
+
+				}
+			default:
+				handled = true
+				if !ignoreCritical && ((typ <= 31) || ((typ & 1) == 1)) {
+					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
+				}
+				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
+			}
+			if err == nil && !handled {
+				switch progress {
+				case 0 - 1:
+					value.Name = nil
+				case 1 - 1:
+					value.StartBlockId = nil
+				case 2 - 1:
+					value.EndBlockId = nil
+				case 3 - 1:
+					value.ProcessId = nil
+				case 4 - 1:
+					err = enc.ErrSkipRequired{Name: "StatusCode", TypeNum: 208}
+				case 5 - 1:
+					value.InsertNum = nil
+				case 6 - 1:
+					value.DeleteNum = nil
+				}
+			}
+			if err != nil {
+				return nil, enc.ErrFailToParse{TypeNum: typ, Err: err}
+			}
+		}
+	}
+	startPos = reader.Pos()
+	for ; progress < 7; progress++ {
+		switch progress {
+		case 0 - 1:
+			value.Name = nil
+		case 1 - 1:
+			value.StartBlockId = nil
+		case 2 - 1:
+			value.EndBlockId = nil
+		case 3 - 1:
+			value.ProcessId = nil
+		case 4 - 1:
+			err = enc.ErrSkipRequired{Name: "StatusCode", TypeNum: 208}
+		case 5 - 1:
+			value.InsertNum = nil
+		case 6 - 1:
+			value.DeleteNum = nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (value *RepoCommandResponseVal) Encode() enc.Wire {
+	encoder := RepoCommandResponseValEncoder{}
+	encoder.Init(value)
+	return encoder.Encode(value)
+}
+
+func (value *RepoCommandResponseVal) Bytes() []byte {
+	return value.Encode().Join()
+}
+
+func ParseRepoCommandResponseVal(reader enc.ParseReader, ignoreCritical bool) (*RepoCommandResponseVal, error) {
+	context := RepoCommandResponseValParsingContext{}
+	context.Init()
+	return context.Parse(reader, ignoreCritical)
+}
+
+type RepoCommandResponseEncoder struct {
+	length uint
+
+	Val_encoder RepoCommandResponseValEncoder
+}
+
+type RepoCommandResponseParsingContext struct {
+	Val_context RepoCommandResponseValParsingContext
+}
+
+func (encoder *RepoCommandResponseEncoder) Init(value *RepoCommandResponse) {
+	if value.Val != nil {
+		encoder.Val_encoder.Init(value.Val)
+	}
+	l := uint(0)
+	if value.Val != nil {
+		l += 1
+		switch x := encoder.Val_encoder.length; {
+		case x <= 0xfc:
+			l += 1
+		case x <= 0xffff:
+			l += 3
+		case x <= 0xffffffff:
+			l += 5
+		default:
+			l += 9
+		}
+		l += encoder.Val_encoder.length
+	}
+
+	encoder.length = l
+
+}
+
+func (context *RepoCommandResponseParsingContext) Init() {
+	context.Val_context.Init()
+}
+
+func (encoder *RepoCommandResponseEncoder) EncodeInto(value *RepoCommandResponse, buf []byte) {
+
+	pos := uint(0)
+	if value.Val != nil {
+		buf[pos] = byte(207)
+		pos += 1
+		switch x := encoder.Val_encoder.length; {
+		case x <= 0xfc:
+			buf[pos] = byte(x)
+			pos += 1
+		case x <= 0xffff:
+			buf[pos] = 0xfd
+			binary.BigEndian.PutUint16(buf[pos+1:], uint16(x))
+			pos += 3
+		case x <= 0xffffffff:
+			buf[pos] = 0xfe
+			binary.BigEndian.PutUint32(buf[pos+1:], uint32(x))
+			pos += 5
+		default:
+			buf[pos] = 0xff
+			binary.BigEndian.PutUint64(buf[pos+1:], uint64(x))
+			pos += 9
+		}
+		if encoder.Val_encoder.length > 0 {
+			encoder.Val_encoder.EncodeInto(value.Val, buf[pos:])
+			pos += encoder.Val_encoder.length
+		}
+	}
+
+}
+
+func (encoder *RepoCommandResponseEncoder) Encode(value *RepoCommandResponse) enc.Wire {
+
+	wire := make(enc.Wire, 1)
+	wire[0] = make([]byte, encoder.length)
+	buf := wire[0]
+	encoder.EncodeInto(value, buf)
+
+	return wire
+}
+
+func (context *RepoCommandResponseParsingContext) Parse(reader enc.ParseReader, ignoreCritical bool) (*RepoCommandResponse, error) {
+	if reader == nil {
+		return nil, enc.ErrBufferOverflow
+	}
+	progress := -1
+	value := &RepoCommandResponse{}
+	var err error
+	var startPos int
+	for {
+		startPos = reader.Pos()
+		if startPos >= reader.Length() {
+			break
+		}
+		typ := enc.TLNum(0)
+		l := enc.TLNum(0)
+		typ, err = enc.ReadTLNum(reader)
+		if err != nil {
+			return nil, enc.ErrFailToParse{TypeNum: 0, Err: err}
+		}
+		l, err = enc.ReadTLNum(reader)
+		if err != nil {
+			return nil, enc.ErrFailToParse{TypeNum: 0, Err: err}
+		}
+		err = nil
+		for handled := false; !handled; progress++ {
+			switch typ {
+			case 207:
+				if progress+1 == 0 {
+					handled = true
+					value.Val, err = context.Val_context.Parse(reader.Delegate(int(l)), ignoreCritical)
+				}
+			default:
+				handled = true
+				if !ignoreCritical && ((typ <= 31) || ((typ & 1) == 1)) {
+					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
+				}
+				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
+			}
+			if err == nil && !handled {
+				switch progress {
+				case 0 - 1:
+					value.Val = nil
+				}
+			}
+			if err != nil {
+				return nil, enc.ErrFailToParse{TypeNum: typ, Err: err}
+			}
+		}
+	}
+	startPos = reader.Pos()
+	for ; progress < 1; progress++ {
+		switch progress {
+		case 0 - 1:
+			value.Val = nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (value *RepoCommandResponse) Encode() enc.Wire {
+	encoder := RepoCommandResponseEncoder{}
+	encoder.Init(value)
+	return encoder.Encode(value)
+}
+
+func (value *RepoCommandResponse) Bytes() []byte {
+	return value.Encode().Join()
+}
+
+func ParseRepoCommandResponse(reader enc.ParseReader, ignoreCritical bool) (*RepoCommandResponse, error) {
+	context := RepoCommandResponseParsingContext{}
+	context.Init()
+	return context.Parse(reader, ignoreCritical)
+}
@@ -23,9 +23,11 @@ type Node struct {
 	impl NodeImpl
 
 	// Chd holds all children.
-	// Since a schema tree typically only has <10 branches, an array should not hurt performance.
-	// Change when there found evidences showing this is the bottleneck.
+	// They are kept in the order they are added, and the first one matching a component wins.
 	chd []*Node
+	// index finds children by their edges, so matching a name does not depend on the number of children.
+	// It must be updated whenever chd changes, by addChild or reindex.
+	index *childIndex
 
 	// Log is the logger
 	log *log.Entry
@@ -39,6 +41,13 @@ type Node struct {
 	attachedPrefix enc.Name
 
 	engine ndn.Engine
+
+	// explicit is true if the node is put by Tree.PutNode, instead of created by another node.
+	explicit bool
+	// attrRefs are the names of attributes given by the environment, used by Tree.ToJson.
+	attrRefs map[PropKey]string
+	// signerInherited is true if the SignerPolicies of an ancestor have been applied to the node.
+	signerInherited bool
 }
 
 // Children of the node
@@ -48,10 +57,11 @@ func (n *Node) Children() []*Node {
 
 // Child of given edge
 func (n *Node) Child(edge enc.ComponentPattern) *Node {
-	for _, c := range n.chd {
-		if c.UpEdge().Equal(edge) {
-			return c
-		}
+	if n.index == nil {
+		return nil
+	}
+	if pos := n.index.find(n.chd, edge); pos >= 0 {
+		return n.chd[pos]
 	}
 	return nil
 }
@@ -124,15 +134,69 @@ func (n *Node) ContinueMatch(remainingName enc.Name, curMatching enc.Matching) *
 	if len(remainingName) <= 0 {
 		return n
 	}
-	for _, c := range n.chd {
-		if c.UpEdge().IsMatch(remainingName[0]) {
-			c.UpEdge().Match(remainingName[0], curMatching)
-			return c.ContinueMatch(remainingName[1:], curMatching)
-		}
+	if c := n.matchChild(remainingName[0]); c != nil {
+		c.UpEdge().Match(remainingName[0], curMatching)
+		return c.ContinueMatch(remainingName[1:], curMatching)
 	}
 	return nil
 }
 
+// MatchPrefix matches the longest prefix of an NDN name to a node.
+// Different from Match, a name longer than the pattern of the node still matches, with Name being the matched prefix
+// and Remainder the rest. For example, /ndn/aa/seg=3 matches a node at /ndn/<id> with the remainder /seg=3.
+// It returns nil only if the name is not under the attached prefix.
+func (n *Node) MatchPrefix(name enc.Name) *MatchedNode {
+	if n.engine == nil {
+		panic("called Node.MatchPrefix() before attaching to an engine")
+	}
+	subName := name
+	if len(n.attachedPrefix) > 0 {
+		if !n.attachedPrefix.IsPrefix(subName) {
+			return nil
+		}
+		subName = subName[n.dep:]
+	}
+	match := make(enc.Matching)
+	node, remainder := n.continueMatchPrefix(subName, match)
+	return &MatchedNode{
+		Node:      node,
+		Name:      name[:len(name)-len(remainder)],
+		Matching:  match,
+		Remainder: remainder,
+	}
+}
+
+// continueMatchPrefix is a sub-function used by MatchPrefix, which returns the node and the unmatched components.
+func (n *Node) continueMatchPrefix(remainingName enc.Name, curMatching enc.Matching) (*Node, enc.Name) {
+	rest := remainingName
+	var paramSha, digestSha bool
+	if len(rest) > 0 && rest[0].Typ == enc.TypeParametersSha256DigestComponent {
+		curMatching[enc.ParamShaNameConvention] = rest[0].Val
+		rest = rest[1:]
+		paramSha = true
+	}
+	if len(rest) > 0 && rest[0].Typ == enc.TypeImplicitSha256DigestComponent {
+		curMatching[enc.DigestShaNameConvention] = rest[0].Val
+		rest = rest[1:]
+		digestSha = true
+	}
+	if len(rest) <= 0 {
+		return n, nil
+	}
+	if c := n.matchChild(rest[0]); c != nil {
+		c.UpEdge().Match(rest[0], curMatching)
+		return c.continueMatchPrefix(rest[1:], curMatching)
+	}
+	// The digests are followed by unmatched components, so they are part of the remainder
+	if paramSha {
+		delete(curMatching, enc.ParamShaNameConvention)
+	}
+	if digestSha {
+		delete(curMatching, enc.DigestShaNameConvention)
+	}
+	return n, remainingName
+}
+
 // RootNode returns the root node in a tree
 func (n *Node) RootNode() *Node {
 	if n.par == nil {
@@ -214,6 +278,7 @@ func (n *Node) OnAttach(path enc.NamePattern, engine ndn.Engine) error {
 	n.log = log.WithField("module", "schema").WithField("path", path.String())
 	n.path = make(enc.NamePattern, len(path))
 	copy(n.path, path)
+	n.reindex()
 
 	for _, c := range n.chd {
 		nxtPath := append(path, c.UpEdge())
@@ -279,10 +344,8 @@ func (n *Node) At(path enc.NamePattern) *Node {
 	if len(path) <= 0 {
 		return n
 	}
-	for _, c := range n.chd {
-		if c.UpEdge().Equal(path[0]) {
-			return c.At(path[1:])
-		}
+	if c := n.Child(path[0]); c != nil {
+		return c.At(path[1:])
 	}
 	return nil
 }
@@ -298,10 +361,8 @@ func (n *Node) PutNode(path enc.NamePattern, desc *NodeImplDesc) *Node {
 	if len(path) <= 0 {
 		panic("schema node already exists")
 	}
-	for _, c := range n.chd {
-		if c.UpEdge().Equal(path[0]) {
-			return c.PutNode(path[1:], desc)
-		}
+	if c := n.Child(path[0]); c != nil {
+		return c.PutNode(path[1:], desc)
 	}
 	nxtChd := &Node{
 		dep:  0,
@@ -313,12 +374,12 @@ func (n *Node) PutNode(path enc.NamePattern, desc *NodeImplDesc) *Node {
 		// In this case, node is not our direct child
 		nxtChd.desc = BaseNodeDesc
 		nxtChd.impl = CreateBaseNode(nxtChd)
-		n.chd = append(n.chd, nxtChd)
+		n.addChild(nxtChd)
 		return nxtChd.PutNode(path[1:], desc)
 	} else {
 		nxtChd.desc = desc
 		nxtChd.impl = desc.Create(nxtChd)
-		n.chd = append(n.chd, nxtChd)
+		n.addChild(nxtChd)
 		return nxtChd
 	}
 }
@@ -1,7 +1,6 @@
 package schema
 
 import (
-	"bytes"
 	"fmt"
 	"sync"
 	"time"
@@ -19,6 +18,79 @@ type RegisterPolicy struct {
 	// It is map[string]any in json
 	// but the any can be a string
 	Patterns enc.Matching
+	// RetryInterval is how long to wait before registering again after a failure, which doubles with every
+	// failure up to MaxRetryInterval. The registration is not retried if it is 0.
+	RetryInterval    time.Duration
+	MaxRetryInterval time.Duration
+	// OnRegistered is triggered when the prefix is registered.
+	OnRegistered *EventTarget
+	// OnRegisterFailed is triggered when an attempt to register fails, with the error in Event.Error.
+	// If the forwarder rejects the command, the error is a mgmt_2022.ErrControlResponse with the status.
+	OnRegisterFailed *EventTarget
+
+	// lock protects the state of the registration, which is retried on the timer.
+	lock        sync.Mutex
+	attached    bool
+	registered  bool
+	cancelRetry func() error
+	// batch collects the registration instead of sending it, while the tree is being attached.
+	batch *registerBatch
+	// route is the route shared with the rest of the batch that covers the prefix, if registered by flush.
+	route *sharedRoute
+}
+
+// registerBatch is the registrations of a tree being attached, sent together by flush.
+type registerBatch struct {
+	policies []*RegisterPolicy
+	nodes    []*MatchedNode
+}
+
+// sharedRoute is a route registered for the common prefix of a batch, unregistered with its last policy.
+type sharedRoute struct {
+	lock sync.Mutex
+	name enc.Name
+	refs int
+}
+
+// release drops a reference to the route, and returns true if it was the last one.
+func (r *sharedRoute) release() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.refs--
+	return r.refs == 0
+}
+
+// commonPrefix returns the longest prefix shared by all names.
+func commonPrefix(names []enc.Name) enc.Name {
+	prefix := names[0]
+	for _, name := range names[1:] {
+		i := 0
+		for i < len(prefix) && i < len(name) && prefix[i].Equal(name[i]) {
+			i++
+		}
+		prefix = prefix[:i]
+	}
+	return prefix
+}
+
+// flush registers the common prefix of the batch with one command, and reports the result to each policy.
+// If it fails, every policy retries its own prefix.
+func (b *registerBatch) flush(engine ndn.Engine) {
+	if len(b.nodes) == 0 {
+		return
+	}
+	names := make([]enc.Name, len(b.nodes))
+	for i, mNode := range b.nodes {
+		names[i] = mNode.Name
+	}
+	route := &sharedRoute{name: commonPrefix(names), refs: len(b.policies)}
+	err := engine.RegisterRoute(route.name, ndn.RouteOptions{})
+	if err != nil {
+		route = nil
+	}
+	for i, p := range b.policies {
+		p.onRegisterResult(engine, b.nodes[i], err, p.RetryInterval, route)
+	}
 }
 
 func (p *RegisterPolicy) PolicyTrait() Policy {
@@ -31,38 +103,176 @@ func (p *RegisterPolicy) onAttach(event *Event) any {
 	if mNode == nil {
 		panic("cannot initialize the name prefix to register")
 	}
-	err := node.Engine().RegisterRoute(mNode.Name)
-	if err != nil {
-		panic(fmt.Errorf("prefix registration failed: %+v", err))
+	p.lock.Lock()
+	p.attached = true
+	p.lock.Unlock()
+	if p.batch != nil {
+		p.batch.policies = append(p.batch.policies, p)
+		p.batch.nodes = append(p.batch.nodes, mNode)
+		return nil
+	}
+	p.register(mNode, p.RetryInterval)
+	return nil
+}
+
+// register registers the prefix of mNode, and schedules the next attempt after wait if it fails.
+func (p *RegisterPolicy) register(mNode *MatchedNode, wait time.Duration) {
+	engine := mNode.Node.Engine()
+	if engine == nil {
+		// Detached before the retry
+		return
+	}
+	err := engine.RegisterRoute(mNode.Name, ndn.RouteOptions{})
+	p.onRegisterResult(engine, mNode, err, wait, nil)
+}
+
+// onRegisterResult records the result of registering the prefix of mNode to engine, retrying after wait if it failed.
+// route is the shared route that covers the prefix, or nil if the prefix was registered on its own.
+func (p *RegisterPolicy) onRegisterResult(
+	engine ndn.Engine, mNode *MatchedNode, err error, wait time.Duration, route *sharedRoute,
+) {
+	p.lock.Lock()
+	if !p.attached {
+		// Detached during the registration, so the route it added is no longer wanted
+		p.lock.Unlock()
+		if err == nil {
+			p.unregister(engine, mNode.Node, mNode.Name, route)
+		}
+		return
+	}
+	p.cancelRetry = nil
+	if err == nil {
+		p.registered = true
+		p.route = route
+		p.lock.Unlock()
+		p.OnRegistered.Dispatch(&Event{TargetNode: mNode.Node, Target: mNode})
+		return
+	}
+	if wait > 0 {
+		next := min(wait*2, max(p.MaxRetryInterval, wait))
+		p.cancelRetry = engine.Timer().Schedule(wait, func() {
+			p.register(mNode, next)
+		})
+	}
+	p.lock.Unlock()
+
+	if wait > 0 {
+		mNode.Logger("RegisterPolicy").Warnf("Prefix registration failed, retrying in %v: %+v", wait, err)
+	} else {
+		mNode.Logger("RegisterPolicy").Errorf("Prefix registration failed: %+v", err)
+	}
+	p.OnRegisterFailed.Dispatch(&Event{TargetNode: mNode.Node, Target: mNode, Error: err})
+}
+
+// onDetach unregisters the prefix, so a subtree removed at runtime no longer attracts Interests.
+// A shared route is only unregistered when the last policy it covers is detached.
+func (p *RegisterPolicy) onDetach(event *Event) any {
+	p.lock.Lock()
+	registered := p.registered
+	route := p.route
+	if p.cancelRetry != nil {
+		p.cancelRetry()
+		p.cancelRetry = nil
+	}
+	p.attached = false
+	p.registered = false
+	p.route = nil
+	p.lock.Unlock()
+	if !registered {
+		return nil
+	}
+
+	node := event.TargetNode
+	if route != nil {
+		p.unregister(node.Engine(), node, nil, route)
+		return nil
+	}
+	mNode := node.Apply(p.Patterns)
+	if mNode != nil {
+		p.unregister(node.Engine(), node, mNode.Name, nil)
 	}
 	return nil
 }
 
+// unregister removes the route registered to engine for name, or releases the shared route if it is not nil.
+func (p *RegisterPolicy) unregister(engine ndn.Engine, node *Node, name enc.Name, route *sharedRoute) {
+	if route != nil {
+		if !route.release() {
+			return
+		}
+		name = route.name
+	}
+	err := engine.UnregisterRoute(name, ndn.RouteOptions{})
+	if err != nil {
+		node.Log().WithField("name", name.String()).Warnf("Unable to unregister prefix: %+v", err)
+	}
+}
+
 func (p *RegisterPolicy) Apply(node *Node) {
 	if p.RegisterIf {
 		var callback Callback = p.onAttach
 		node.AddEventListener(PropOnAttach, &callback)
+		var detachCallback Callback = p.onDetach
+		node.AddEventListener(PropOnDetach, &detachCallback)
 	}
 }
 
 func NewRegisterPolicy() Policy {
 	return &RegisterPolicy{
-		RegisterIf: true,
+		RegisterIf:       true,
+		RetryInterval:    time.Second,
+		MaxRetryInterval: time.Minute,
+		OnRegistered:     &EventTarget{},
+		OnRegisterFailed: &EventTarget{},
+	}
+}
+
+// checkValidity fails signatures whose validity period does not cover the current time.
+func checkValidity(event *Event) bool {
+	return event.Signature.IsValidAt(event.TargetNode.Engine().Timer().Now())
+}
+
+// withValidity makes the signer carry the validity period [validFrom, validTo] if either is given.
+// A missing bound is treated as unbounded.
+func withValidity(signer ndn.Signer, validFrom *time.Time, validTo *time.Time) ndn.Signer {
+	if validFrom == nil && validTo == nil {
+		return signer
+	}
+	notBefore := time.Unix(0, 0)
+	notAfter := time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)
+	if validFrom != nil {
+		notBefore = *validFrom
+	}
+	if validTo != nil {
+		notAfter = *validTo
 	}
+	return sec.NewValiditySigner(signer, notBefore, notAfter)
 }
 
-type Sha256SignerPolicy struct{}
+// Sha256SignerPolicy signs Data with DigestSha256, i.e. SignatureType 0 whose value is the SHA-256 digest
+// of the signed portion, and accepts Data only if the digest matches.
+// It involves no key, so it only checks integrity and does not tell who produced the Data.
+// It is registered as both "Sha256Signer" and "DigestSha256Signer".
+type Sha256SignerPolicy struct {
+	// ValidFrom and ValidTo is the optional validity period of produced Data.
+	ValidFrom *time.Time
+	ValidTo   *time.Time
+}
 
 func (p *Sha256SignerPolicy) PolicyTrait() Policy {
 	return p
 }
 
+func (p *Sha256SignerPolicy) SignerTrait() SignerPolicy {
+	return p
+}
+
 func NewSha256SignerPolicy() Policy {
 	return &Sha256SignerPolicy{}
 }
 
 func (p *Sha256SignerPolicy) onGetDataSigner(*Event) any {
-	return sec.NewSha256Signer()
+	return withValidity(sec.NewSha256Signer(), p.ValidFrom, p.ValidTo)
 }
 
 func (p *Sha256SignerPolicy) onValidateData(event *Event) any {
@@ -71,8 +281,7 @@ func (p *Sha256SignerPolicy) onValidateData(event *Event) any {
 	if sigCovered == nil || signature == nil || signature.SigType() != ndn.SignatureDigestSha256 {
 		return VrSilence
 	}
-	val, _ := sec.NewSha256Signer().ComputeSigValue(sigCovered)
-	if bytes.Equal(signature.SigValue(), val) {
+	if sec.Sha256Validate(sigCovered, signature) && checkValidity(event) {
 		return VrPass
 	} else {
 		return VrFail
@@ -85,11 +294,12 @@ func (p *Sha256SignerPolicy) Apply(node *Node) {
 	if evt != nil {
 		evt.Add(utils.IdPtr(p.onGetDataSigner))
 	}
-	// PropOnValidateData must exist. Otherwise it is at an invalid path.
+	// PropOnValidateData must exist, unless the descendants inherit the policy.
+	// Otherwise it is at an invalid path.
 	evt = node.GetEvent(PropOnValidateData)
 	if evt != nil {
 		evt.Add(utils.IdPtr(p.onValidateData))
-	} else {
+	} else if len(node.Children()) == 0 {
 		panic("attaching Sha256SignerPolicy to a node that does not need to validate Data. What is the use?")
 	}
 }
@@ -97,6 +307,10 @@ func (p *Sha256SignerPolicy) Apply(node *Node) {
 type CacheEntry struct {
 	RawData  enc.Wire
 	Validity time.Time
+	// ProducedAt is when the Data is stored, and Freshness is its FreshnessPeriod.
+	// A MustBeFresh Interest is not satisfied after ProducedAt+Freshness.
+	ProducedAt time.Time
+	Freshness  time.Duration
 }
 
 // MemStoragePolicy is a policy that stored data in a memory storage.
@@ -113,24 +327,38 @@ func (p *MemStoragePolicy) PolicyTrait() Policy {
 	return p
 }
 
+func (p *MemStoragePolicy) SubtreeTrait() SubtreePolicy {
+	return p
+}
+
+func (p *MemStoragePolicy) now() time.Time {
+	if p.timer != nil {
+		return p.timer.Now()
+	}
+	return time.Now()
+}
+
+// Get returns a stored Data named name, or one under name if canBePrefix.
+// If mustBeFresh, the Data must be within both its storage validity and its FreshnessPeriod.
 func (p *MemStoragePolicy) Get(name enc.Name, canBePrefix bool, mustBeFresh bool) enc.Wire {
 	p.lock.RLock()
 	defer p.lock.RUnlock()
 
 	node := p.tree.ExactMatch(name)
-	now := time.Time{}
-	if p.timer != nil {
-		now = p.timer.Now()
-	}
 	if node == nil {
 		return nil
 	}
+	now := p.now()
 	freshTest := func(entry CacheEntry) bool {
-		return len(entry.RawData) > 0 && (!mustBeFresh || entry.Validity.After(now))
+		return len(entry.RawData) > 0 && (!mustBeFresh ||
+			(entry.Validity.After(now) && entry.ProducedAt.Add(entry.Freshness).After(now)))
 	}
 	if freshTest(node.Value()) {
 		return node.Value().RawData
 	}
+	if !canBePrefix {
+		return nil
+	}
 	dataNode := node.FirstNodeIf(freshTest)
 	if dataNode != nil {
 		return dataNode.Value().RawData
@@ -139,18 +367,24 @@ func (p *MemStoragePolicy) Get(name enc.Name, canBePrefix bool, mustBeFresh bool
 	}
 }
 
-func (p *MemStoragePolicy) Put(name enc.Name, rawData enc.Wire, validity time.Time) {
+// Put stores a Data, which is served until validity and fresh for freshness from now.
+func (p *MemStoragePolicy) Put(name enc.Name, rawData enc.Wire, validity time.Time, freshness time.Duration) {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
 	node := p.tree.MatchAlways(name)
 	node.SetValue(CacheEntry{
-		RawData:  rawData,
-		Validity: validity,
+		RawData:    rawData,
+		Validity:   validity,
+		ProducedAt: p.now(),
+		Freshness:  freshness,
 	})
 }
 
 func (p *MemStoragePolicy) onAttach(event *Event) any {
+	// Nodes added by Tree.AddNode are attached while others are serving Interests
+	p.lock.Lock()
+	defer p.lock.Unlock()
 	p.timer = event.TargetNode.Engine().Timer()
 	return nil
 }
@@ -161,8 +395,21 @@ func (p *MemStoragePolicy) onSearch(event *Event) any {
 }
 
 func (p *MemStoragePolicy) onSave(event *Event) any {
-	validity := p.timer.Now().Add(*event.ValidDuration)
-	p.Put(event.Target.Name, event.RawPacket, validity)
+	p.lock.RLock()
+	now := p.now()
+	p.lock.RUnlock()
+	validity := now
+	if event.ValidDuration != nil {
+		validity = now.Add(*event.ValidDuration)
+	}
+	// Fetched Data carry their FreshnessPeriod, while produced ones have it in the config
+	freshness := time.Duration(0)
+	if event.DataConfig != nil && event.DataConfig.Freshness != nil {
+		freshness = *event.DataConfig.Freshness
+	} else if event.Data != nil && event.Data.Freshness() != nil {
+		freshness = *event.Data.Freshness()
+	}
+	p.Put(event.Target.Name, event.RawPacket, validity, freshness)
 	return nil
 }
 
@@ -191,16 +438,30 @@ func NewMemStoragePolicy() Policy {
 }
 
 type FixedHmacSignerPolicy struct {
-	Key         string
+	Key string
+	// KeyFile and KeyEnv are the file and the environment variable to load Key from, if Key is not given.
+	KeyFile     string
+	KeyEnv      string
 	KeyName     enc.Name
 	SignForCert bool
 	ExpireTime  time.Duration
+	// ValidFrom and ValidTo is the optional validity period of produced Data.
+	// They override the one given by SignForCert and ExpireTime.
+	ValidFrom *time.Time
+	ValidTo   *time.Time
+
+	// key is the loaded key, which is not a property so that it is not described by ToJson
+	key string
 }
 
 func (p *FixedHmacSignerPolicy) PolicyTrait() Policy {
 	return p
 }
 
+func (p *FixedHmacSignerPolicy) SignerTrait() SignerPolicy {
+	return p
+}
+
 func NewFixedHmacSignerPolicy() Policy {
 	return &FixedHmacSignerPolicy{
 		SignForCert: false,
@@ -208,7 +469,8 @@ func NewFixedHmacSignerPolicy() Policy {
 }
 
 func (p *FixedHmacSignerPolicy) onGetDataSigner(*Event) any {
-	return sec.NewHmacSigner(p.KeyName, []byte(p.Key), p.SignForCert, p.ExpireTime)
+	signer := sec.NewHmacSigner(p.KeyName, []byte(p.key), p.SignForCert, p.ExpireTime)
+	return withValidity(signer, p.ValidFrom, p.ValidTo)
 }
 
 func (p *FixedHmacSignerPolicy) onValidateData(event *Event) any {
@@ -217,7 +479,7 @@ func (p *FixedHmacSignerPolicy) onValidateData(event *Event) any {
 	if sigCovered == nil || signature == nil || signature.SigType() != ndn.SignatureHmacWithSha256 {
 		return VrSilence
 	}
-	if sec.CheckHmacSig(sigCovered, signature.SigValue(), []byte(p.Key)) {
+	if sec.CheckHmacSig(sigCovered, signature.SigValue(), []byte(p.key)) && checkValidity(event) {
 		return schema.VrPass
 	} else {
 		return schema.VrFail
@@ -225,8 +487,13 @@ func (p *FixedHmacSignerPolicy) onValidateData(event *Event) any {
 }
 
 func (p *FixedHmacSignerPolicy) Apply(node *Node) {
+	key, err := loadKey(p.Key, p.KeyFile, p.KeyEnv)
+	if err != nil {
+		panic(fmt.Errorf("FixedHmacSignerPolicy is unable to load the key: %v", err))
+	}
+	p.key = key
 	// key must present
-	if len(p.Key) == 0 {
+	if len(p.key) == 0 {
 		panic("FixedHmacSignerPolicy requires key to present before apply.")
 	}
 	// IdPtr must be used
@@ -234,17 +501,24 @@ func (p *FixedHmacSignerPolicy) Apply(node *Node) {
 	if evt != nil {
 		evt.Add(utils.IdPtr(p.onGetDataSigner))
 	}
-	// PropOnValidateData must exist. Otherwise it is at an invalid path.
+	// PropOnValidateData must exist, unless the descendants inherit the policy.
+	// Otherwise it is at an invalid path.
 	evt = node.GetEvent(PropOnValidateData)
 	if evt != nil {
 		evt.Add(utils.IdPtr(p.onValidateData))
-	} else {
+	} else if len(node.Children()) == 0 {
 		panic("applying FixedHmacSignerPolicy to a node that does not need to validate Data. What is the use?")
 	}
 }
 
 type FixedHmacIntSignerPolicy struct {
-	Key    string
+	Key string
+	// KeyFile and KeyEnv are the file and the environment variable to load Key from, if Key is not given.
+	KeyFile string
+	KeyEnv  string
+
+	// key is the loaded key, which is not a property so that it is not described by ToJson
+	key    string
 	signer ndn.Signer
 }
 
@@ -266,7 +540,7 @@ func (p *FixedHmacIntSignerPolicy) onValidateInt(event *Event) any {
 	if sigCovered == nil || signature == nil || signature.SigType() != ndn.SignatureHmacWithSha256 {
 		return VrSilence
 	}
-	if sec.CheckHmacSig(sigCovered, signature.SigValue(), []byte(p.Key)) {
+	if sec.CheckHmacSig(sigCovered, signature.SigValue(), []byte(p.key)) {
 		return schema.VrPass
 	} else {
 		return schema.VrFail
@@ -274,13 +548,18 @@ func (p *FixedHmacIntSignerPolicy) onValidateInt(event *Event) any {
 }
 
 func (p *FixedHmacIntSignerPolicy) onAttach(event *Event) any {
-	p.signer = sec.NewHmacIntSigner([]byte(p.Key), event.TargetNode.Engine().Timer())
+	p.signer = sec.NewHmacIntSigner([]byte(p.key), event.TargetNode.Engine().Timer())
 	return nil
 }
 
 func (p *FixedHmacIntSignerPolicy) Apply(node *Node) {
+	key, err := loadKey(p.Key, p.KeyFile, p.KeyEnv)
+	if err != nil {
+		panic(fmt.Errorf("FixedHmacIntSignerPolicy is unable to load the key: %v", err))
+	}
+	p.key = key
 	// key must present
-	if len(p.Key) == 0 {
+	if len(p.key) == 0 {
 		panic("FixedHmacSignerPolicy requires key to present before apply.")
 	}
 	// IdPtr must be used
@@ -303,31 +582,70 @@ func initPolicies() {
 	registerPolicyDesc := &PolicyImplDesc{
 		ClassName: "RegisterPolicy",
 		Properties: map[PropKey]PropertyDesc{
-			"RegisterIf": DefaultPropertyDesc("RegisterIf"),
-			"Patterns":   MatchingPropertyDesc("Patterns"),
+			"RegisterIf":       DefaultPropertyDesc("RegisterIf"),
+			"Patterns":         MatchingPropertyDesc("Patterns"),
+			"RetryInterval":    TimePropertyDesc("RetryInterval"),
+			"MaxRetryInterval": TimePropertyDesc("MaxRetryInterval"),
+		},
+		Events: map[PropKey]EventGetter{
+			"OnRegistered":     DefaultEventTarget("OnRegistered"),
+			"OnRegisterFailed": DefaultEventTarget("OnRegisterFailed"),
 		},
 		Create: NewRegisterPolicy,
 	}
 	sha256SignerPolicyDesc := &PolicyImplDesc{
 		ClassName: "Sha256Signer",
 		Create:    NewSha256SignerPolicy,
+		Properties: map[PropKey]PropertyDesc{
+			"ValidFrom": TimestampPropertyDesc("ValidFrom"),
+			"ValidTo":   TimestampPropertyDesc("ValidTo"),
+		},
 	}
+	digestSha256SignerPolicyDesc := *sha256SignerPolicyDesc
+	digestSha256SignerPolicyDesc.ClassName = "DigestSha256Signer"
 	RegisterPolicyImpl(registerPolicyDesc)
 	RegisterPolicyImpl(sha256SignerPolicyDesc)
+	RegisterPolicyImpl(&digestSha256SignerPolicyDesc)
 	memoryStoragePolicyDesc := &PolicyImplDesc{
 		ClassName: "MemStorage",
 		Create:    NewMemStoragePolicy,
 	}
 	RegisterPolicyImpl(memoryStoragePolicyDesc)
+	diskStoragePolicyDesc := &PolicyImplDesc{
+		ClassName: "DiskStorage",
+		Create:    NewDiskStoragePolicy,
+		Properties: map[PropKey]PropertyDesc{
+			"FilePath": DefaultPropertyDesc("FilePath"),
+			"MaxSize":  DefaultPropertyDesc("MaxSize"),
+		},
+	}
+	RegisterPolicyImpl(diskStoragePolicyDesc)
+	trustSchemaPolicyDesc := &PolicyImplDesc{
+		ClassName: "TrustSchemaValidator",
+		Create:    NewTrustSchemaPolicy,
+		Properties: map[PropKey]PropertyDesc{
+			"Rules":           TrustRulesPropertyDesc("Rules"),
+			"TrustAnchor":     DefaultPropertyDesc("TrustAnchor"),
+			"MaxDepth":        DefaultPropertyDesc("MaxDepth"),
+			"Lifetime":        TimePropertyDesc("Lifetime"),
+			"CertCacheSize":   DefaultPropertyDesc("CertCacheSize"),
+			"VerifyCacheSize": DefaultPropertyDesc("VerifyCacheSize"),
+		},
+	}
+	RegisterPolicyImpl(trustSchemaPolicyDesc)
 
 	fixedHmacSignerPolicyDesc := &PolicyImplDesc{
 		ClassName: "FixedHmacSigner",
 		Create:    NewFixedHmacSignerPolicy,
 		Properties: map[PropKey]PropertyDesc{
 			"KeyValue":    DefaultPropertyDesc("Key"),
+			"KeyFile":     DefaultPropertyDesc("KeyFile"),
+			"KeyEnv":      DefaultPropertyDesc("KeyEnv"),
 			"KeyName":     NamePropertyDesc("KeyName"),
 			"SignForCert": DefaultPropertyDesc("SignForCert"),
 			"ExpireTime":  TimePropertyDesc("ExpireTime"),
+			"ValidFrom":   TimestampPropertyDesc("ValidFrom"),
+			"ValidTo":     TimestampPropertyDesc("ValidTo"),
 		},
 	}
 	RegisterPolicyImpl(fixedHmacSignerPolicyDesc)
@@ -337,6 +655,8 @@ func initPolicies() {
 		Create:    NewFixedHmacIntSignerPolicy,
 		Properties: map[PropKey]PropertyDesc{
 			"KeyValue": DefaultPropertyDesc("Key"),
+			"KeyFile":  DefaultPropertyDesc("KeyFile"),
+			"KeyEnv":   DefaultPropertyDesc("KeyEnv"),
 		},
 	}
 	RegisterPolicyImpl(fixedHmacIntSignerPolicyDesc)
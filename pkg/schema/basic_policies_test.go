@@ -0,0 +1,254 @@
+package schema_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	basic_engine "github.com/zjkmxy/go-ndn/pkg/engine/basic"
+	"github.com/zjkmxy/go-ndn/pkg/engine/dummy"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/ndn/mgmt_2022"
+	"github.com/zjkmxy/go-ndn/pkg/ndn/spec_2022"
+	"github.com/zjkmxy/go-ndn/pkg/schema"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+func TestMemStorageFreshness(t *testing.T) {
+	utils.SetTestingT(t)
+
+	p := schema.NewMemStoragePolicy().(*schema.MemStoragePolicy)
+	name := func(s string) enc.Name {
+		return utils.WithoutErr(enc.NameFromStr(s))
+	}
+	fresh := enc.Wire{[]byte("fresh")}
+	stale := enc.Wire{[]byte("stale")}
+	expired := enc.Wire{[]byte("expired")}
+	valid := time.Now().Add(time.Hour)
+	p.Put(name("/a/b/1"), fresh, valid, time.Hour)
+	p.Put(name("/a/c/1"), stale, valid, 0)
+	p.Put(name("/a/d/1"), expired, time.Now().Add(-time.Second), time.Hour)
+
+	// A stale entry is skipped for a MustBeFresh Interest, but served for a non-fresh one
+	require.Equal(t, fresh, p.Get(name("/a/b/1"), false, true))
+	require.Nil(t, p.Get(name("/a/c/1"), false, true))
+	require.Equal(t, stale, p.Get(name("/a/c/1"), false, false))
+	// So is an entry past its storage validity
+	require.Nil(t, p.Get(name("/a/d/1"), false, true))
+	require.Equal(t, expired, p.Get(name("/a/d/1"), false, false))
+
+	// Names under the requested one are only searched with CanBePrefix
+	require.Nil(t, p.Get(name("/a/b"), false, false))
+	require.Equal(t, fresh, p.Get(name("/a/b"), true, false))
+	require.Nil(t, p.Get(name("/a/c"), true, true))
+	require.Equal(t, stale, p.Get(name("/a/c"), true, false))
+	require.Equal(t, fresh, p.Get(name("/a"), true, true))
+	require.Nil(t, p.Get(name("/a/e"), true, false))
+}
+
+func TestMemStorageFetchedFreshness(t *testing.T) {
+	const treeJson = `{
+		"nodes": {
+			"/obj/<v=time>": {"type": "LeafNode", "attrs": {"Lifetime": 1000, "MustBeFresh": true}}
+		},
+		"policies": [
+			{"type": "Sha256Signer", "path": "/"},
+			{"type": "MemStorage", "path": "/", "attrs": {}}
+		]
+	}`
+	executeSchemaTest(t, treeJson, func(env *schemaTestEnv) {
+		mNode := env.tree.Match(utils.WithoutErr(enc.NameFromStr("/p/obj/v=1")))
+		need := func() chan schema.NeedResult {
+			return mNode.Call("NeedChan").(chan schema.NeedResult)
+		}
+
+		// A fetched Data is stored with its FreshnessPeriod
+		ch := need()
+		pkt, _, err := spec_2022.ReadPacket(enc.NewBufferReader(utils.WithoutErr(env.face.Consume())))
+		require.NoError(t, err)
+		require.NotNil(t, pkt.Interest)
+		data, _, err := spec_2022.Spec{}.MakeData(pkt.Interest.NameV, &ndn.DataConfig{
+			ContentType: utils.IdPtr(ndn.ContentTypeBlob),
+			Freshness:   utils.IdPtr(time.Second),
+		}, enc.Wire{[]byte("hello")}, sec.NewSha256Signer())
+		require.NoError(t, err)
+		require.NoError(t, env.face.FeedPacket(data.Join()))
+		require.Equal(t, ndn.InterestResultData, (<-ch).Status)
+
+		// Served from the storage while fresh, without expressing an Interest
+		result := <-need()
+		require.Equal(t, ndn.InterestResultData, result.Status)
+		require.Equal(t, schema.VrCachedData, *result.ValidResult)
+		_, err = env.face.Consume()
+		require.Error(t, err)
+
+		// Expressed again once stale
+		env.timer.MoveForward(1500 * time.Millisecond)
+		ch = need()
+		utils.WithoutErr(env.face.Consume())
+		env.timer.MoveForward(1500 * time.Millisecond)
+		require.Equal(t, ndn.InterestResultTimeout, (<-ch).Status)
+	})
+}
+
+func TestRegisterPolicyRetry(t *testing.T) {
+	utils.SetTestingT(t)
+
+	var failed []error
+	registered := 0
+	tree := schema.CreateFromJson(`{
+		"nodes": {
+			"/obj/<v=time>": {"type": "LeafNode", "attrs": {}}
+		},
+		"policies": [
+			{"type": "RegisterPolicy", "path": "/", "attrs": {"RetryInterval": 1000, "MaxRetryInterval": 2000},
+				"events": {"OnRegistered": ["$onRegistered"], "OnRegisterFailed": ["$onRegisterFailed"]}}
+		]
+	}`, map[string]any{
+		"$onRegistered": schema.Callback(func(event *schema.Event) any {
+			registered++
+			return nil
+		}),
+		"$onRegisterFailed": schema.Callback(func(event *schema.Event) any {
+			failed = append(failed, event.Error)
+			return nil
+		}),
+	})
+
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool { return true }
+	timer := dummy.NewTimer()
+	engine := basic_engine.NewEngine(dummy.NewDummyFace(), timer, sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(t, engine.Start())
+	defer engine.Shutdown()
+	// The forwarder rejects the first three commands
+	routes := &routeEngine{Engine: engine, routes: map[string]struct{}{}, failures: 3}
+	require.NoError(t, tree.Attach(utils.WithoutErr(enc.NameFromStr("/p")), routes))
+
+	// The status of the rejection is given to OnRegisterFailed
+	require.Equal(t, 1, len(failed))
+	var ctrlErr mgmt_2022.ErrControlResponse
+	require.ErrorAs(t, failed[0], &ctrlErr)
+	require.Equal(t, uint64(403), ctrlErr.StatusCode)
+	require.Equal(t, 0, registered)
+	require.Empty(t, routes.Routes())
+
+	// Retried after 1s, then after 2s twice as the interval is capped by MaxRetryInterval
+	timer.MoveForward(900 * time.Millisecond)
+	require.Equal(t, 1, len(routes.commands))
+	timer.MoveForward(200 * time.Millisecond)
+	require.Equal(t, 2, len(routes.commands))
+	require.Equal(t, 2, len(failed))
+	timer.MoveForward(2100 * time.Millisecond)
+	require.Equal(t, 3, len(failed))
+	timer.MoveForward(2000 * time.Millisecond)
+	require.Equal(t, 4, len(routes.commands))
+	require.Equal(t, 3, len(failed))
+	require.Equal(t, 1, registered)
+	require.Equal(t, []string{"/p"}, routes.Routes())
+
+	// Not retried once registered, and unregistered on detach
+	timer.MoveForward(time.Minute)
+	require.Equal(t, 4, len(routes.commands))
+	tree.Detach()
+	require.Empty(t, routes.Routes())
+}
+
+func TestRegisterPolicyRetryDetached(t *testing.T) {
+	utils.SetTestingT(t)
+
+	tree := schema.CreateFromJson(`{
+		"nodes": {
+			"/obj/<v=time>": {"type": "LeafNode", "attrs": {}}
+		},
+		"policies": [
+			{"type": "RegisterPolicy", "path": "/", "attrs": {"RetryInterval": 1000}}
+		]
+	}`, nil)
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool { return true }
+	timer := dummy.NewTimer()
+	engine := basic_engine.NewEngine(dummy.NewDummyFace(), timer, sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(t, engine.Start())
+	defer engine.Shutdown()
+	routes := &routeEngine{Engine: engine, routes: map[string]struct{}{}, failures: 1}
+	require.NoError(t, tree.Attach(utils.WithoutErr(enc.NameFromStr("/p")), routes))
+	require.Equal(t, 1, len(routes.commands))
+
+	// The pending retry is cancelled when the tree is detached
+	tree.Detach()
+	timer.MoveForward(time.Minute)
+	require.Equal(t, 1, len(routes.commands))
+	require.Empty(t, routes.Routes())
+}
+
+func TestRegisterPolicyCommonPrefix(t *testing.T) {
+	const count = 100
+	nodes := make([]string, count)
+	policies := make([]string, count)
+	for i := range count {
+		nodes[i] = fmt.Sprintf(`"/app/u%d/<v=time>": {"type": "LeafNode", "attrs": {}}`, i)
+		policies[i] = fmt.Sprintf(`{"type": "RegisterPolicy", "path": "/app/u%d", "attrs": {}}`, i)
+	}
+	treeJson := fmt.Sprintf(`{"nodes": {%s}, "policies": [%s]}`,
+		strings.Join(nodes, ","), strings.Join(policies, ","))
+
+	executeSchemaTest(t, treeJson, func(env *schemaTestEnv) {
+		// The prefixes of all leaves are registered with one command for their common prefix
+		require.Equal(t, [][]string{{"/p/app"}}, env.routes.commands)
+		require.Equal(t, []string{"/p/app"}, env.routes.Routes())
+
+		// The route is kept until the last leaf it covers is removed
+		for i := range count - 1 {
+			require.NoError(t, env.tree.RemoveNode(mustPattern(t, fmt.Sprintf("/app/u%d", i))))
+		}
+		require.Equal(t, []string{"/p/app"}, env.routes.Routes())
+		require.NoError(t, env.tree.RemoveNode(mustPattern(t, fmt.Sprintf("/app/u%d", count-1))))
+		require.Empty(t, env.routes.Routes())
+		require.Equal(t, 1, len(env.routes.commands))
+	})
+}
+
+// detachingEngine detaches the tree while a registration command is in flight.
+type detachingEngine struct {
+	*routeEngine
+	detach func()
+}
+
+func (e *detachingEngine) RegisterRoute(prefix enc.Name, opts ndn.RouteOptions) error {
+	if e.detach != nil {
+		e.detach()
+		e.detach = nil
+	}
+	return e.routeEngine.RegisterRoute(prefix, opts)
+}
+
+func TestRegisterPolicyDetachedInFlight(t *testing.T) {
+	utils.SetTestingT(t)
+
+	tree := schema.CreateFromJson(`{
+		"nodes": {
+			"/obj/<v=time>": {"type": "LeafNode", "attrs": {}}
+		},
+		"policies": [
+			{"type": "RegisterPolicy", "path": "/", "attrs": {"RetryInterval": 1000}}
+		]
+	}`, nil)
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool { return true }
+	timer := dummy.NewTimer()
+	engine := basic_engine.NewEngine(dummy.NewDummyFace(), timer, sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(t, engine.Start())
+	defer engine.Shutdown()
+	routes := &detachingEngine{
+		routeEngine: &routeEngine{Engine: engine, routes: map[string]struct{}{}, failures: 1},
+	}
+	require.NoError(t, tree.Attach(utils.WithoutErr(enc.NameFromStr("/p")), routes))
+
+	// The tree is detached while the retry is waiting for the forwarder, which then accepts it
+	routes.detach = tree.Detach
+	timer.MoveForward(1100 * time.Millisecond)
+	require.Equal(t, 2, len(routes.commands))
+	require.Empty(t, routes.Routes())
+}
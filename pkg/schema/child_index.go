@@ -0,0 +1,122 @@
+package schema
+
+import (
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+)
+
+// childIndex finds the child of a node whose edge matches a name component, without testing every child.
+// Children whose edge is a component are looked up by its type and value.
+// Those whose edge is a pattern are tested in order, which is cheap since a node seldom has many variables.
+// The result is always the first child in chd that matches, the same as testing children one by one.
+type childIndex struct {
+	// literals are the positions in chd of the children with a component edge, indexed by type and value.
+	literals map[enc.TLNum]map[string]int
+	// patterns are the positions in chd of the other children, in ascending order.
+	patterns []int
+}
+
+func newChildIndex(chd []*Node) *childIndex {
+	idx := &childIndex{literals: make(map[enc.TLNum]map[string]int)}
+	for i, c := range chd {
+		idx.add(i, c.edge)
+	}
+	return idx
+}
+
+func literalEdge(edge enc.ComponentPattern) (enc.Component, bool) {
+	switch e := edge.(type) {
+	case enc.Component:
+		return e, true
+	case *enc.Component:
+		return *e, true
+	default:
+		return enc.Component{}, false
+	}
+}
+
+// add indexes the child at pos in chd, which must be after all indexed ones.
+func (idx *childIndex) add(pos int, edge enc.ComponentPattern) {
+	c, ok := literalEdge(edge)
+	if !ok {
+		idx.patterns = append(idx.patterns, pos)
+		return
+	}
+	vals := idx.literals[c.Typ]
+	if vals == nil {
+		vals = make(map[string]int)
+		idx.literals[c.Typ] = vals
+	}
+	if _, ok := vals[string(c.Val)]; !ok {
+		vals[string(c.Val)] = pos
+	}
+}
+
+// match returns the position of the first child in chd matching comp, or -1 if there is none.
+func (idx *childIndex) match(chd []*Node, comp enc.Component) int {
+	ret := -1
+	if pos, ok := idx.literals[comp.Typ][string(comp.Val)]; ok {
+		ret = pos
+	}
+	for _, pos := range idx.patterns {
+		if ret >= 0 && pos > ret {
+			break
+		}
+		if chd[pos].edge.IsMatch(comp) {
+			return pos
+		}
+	}
+	return ret
+}
+
+// find returns the position of the first child in chd whose edge equals edge, or -1 if there is none.
+func (idx *childIndex) find(chd []*Node, edge enc.ComponentPattern) int {
+	if c, ok := literalEdge(edge); ok {
+		if pos, ok := idx.literals[c.Typ][string(c.Val)]; ok {
+			return pos
+		}
+		return -1
+	}
+	for _, pos := range idx.patterns {
+		if chd[pos].edge.Equal(edge) {
+			return pos
+		}
+	}
+	return -1
+}
+
+// addChild appends c to the children of n.
+func (n *Node) addChild(c *Node) {
+	n.chd = append(n.chd, c)
+	if n.index == nil {
+		n.index = newChildIndex(n.chd)
+	} else {
+		n.index.add(len(n.chd)-1, c.edge)
+	}
+}
+
+// removeChild removes the child c, if it is a child of n.
+func (n *Node) removeChild(c *Node) {
+	for i, chd := range n.chd {
+		if chd == c {
+			n.chd = append(n.chd[:i:i], n.chd[i+1:]...)
+			n.reindex()
+			return
+		}
+	}
+}
+
+// reindex rebuilds the index of children, after they are changed other than by addChild or removeChild.
+func (n *Node) reindex() {
+	n.index = newChildIndex(n.chd)
+}
+
+// matchChild returns the child whose edge matches comp, or nil if there is none.
+func (n *Node) matchChild(comp enc.Component) *Node {
+	if n.index == nil {
+		return nil
+	}
+	if pos := n.index.match(n.chd, comp); pos >= 0 {
+		return n.chd[pos]
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package schema_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	basic_engine "github.com/zjkmxy/go-ndn/pkg/engine/basic"
+	"github.com/zjkmxy/go-ndn/pkg/engine/dummy"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/schema"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+// linearMatch matches name to the subtree of node by testing children one by one,
+// which is what Node.ContinueMatch did before children were indexed.
+func linearMatch(node *schema.Node, name enc.Name, m enc.Matching) *schema.Node {
+	if len(name) > 0 && name[0].Typ == enc.TypeParametersSha256DigestComponent {
+		m[enc.ParamShaNameConvention] = name[0].Val
+		name = name[1:]
+	}
+	if len(name) > 0 && name[0].Typ == enc.TypeImplicitSha256DigestComponent {
+		m[enc.DigestShaNameConvention] = name[0].Val
+		name = name[1:]
+	}
+	if len(name) == 0 {
+		return node
+	}
+	for _, c := range node.Children() {
+		if c.UpEdge().IsMatch(name[0]) {
+			c.UpEdge().Match(name[0], m)
+			return linearMatch(c, name[1:], m)
+		}
+	}
+	return nil
+}
+
+// newSiblingTree attaches to /pre a tree of count siblings /app/n<i>/<id>/<seq=s>, with literal and pattern edges
+// mixed under /app. It returns the names of the siblings and names of corner cases.
+func newSiblingTree(tb testing.TB, count int) (*schema.Tree, []enc.Name) {
+	tree := &schema.Tree{}
+	tree.PutNode(enc.NamePattern{}, schema.BaseNodeDesc)
+	names := make([]enc.Name, 0, count)
+	for i := 0; i < count; i++ {
+		tree.PutNode(mustPattern(tb, fmt.Sprintf("/app/n%d/<id>/<seq=s>", i)), schema.LeafNodeDesc)
+		names = append(names, mustName(tb, fmt.Sprintf("/pre/app/n%d/x/seq=%d", i, i)))
+	}
+	// A literal child after a pattern child matching the same component is never reached
+	tree.PutNode(mustPattern(tb, "/app/<any>/lit"), schema.LeafNodeDesc)
+	tree.PutNode(mustPattern(tb, "/app/zzz/lit"), schema.LeafNodeDesc)
+	tree.PutNode(mustPattern(tb, "/app/<v=ver>"), schema.LeafNodeDesc)
+	for _, s := range []string{
+		"/pre/app/zzz/lit", "/pre/app/abc/lit", "/pre/app/v=3", "/pre/app/n5/x", "/pre/app/n5/x/seq=1/extra",
+		"/pre/app/n5/x/sha256digest=0102", "/pre/app/n7/y/seq=1/params-sha256=00", "/pre/app/n1/x/58=abc",
+		"/pre/app/58=abc", "/pre/other", "/pre", "/other",
+	} {
+		names = append(names, mustName(tb, s))
+	}
+
+	face := dummy.NewDummyFace()
+	timer := dummy.NewTimer()
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool { return true }
+	engine := basic_engine.NewEngine(face, timer, sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(tb, engine.Start())
+	tb.Cleanup(func() { engine.Shutdown() })
+	require.NoError(tb, tree.Attach(mustName(tb, "/pre"), engine))
+	return tree, names
+}
+
+func requireSameMatch(t *testing.T, tree *schema.Tree, names []enc.Name) {
+	for _, name := range names {
+		m := enc.Matching{}
+		var want *schema.Node
+		if len(name) > 0 && name[0].Equal(enc.NewStringComponent(enc.TypeGenericNameComponent, "pre")) {
+			want = linearMatch(tree.Root(), name[1:], m)
+		}
+		got := tree.Match(name)
+		if want == nil {
+			require.Nil(t, got, name.String())
+			continue
+		}
+		require.NotNil(t, got, name.String())
+		require.True(t, want == got.Node, name.String())
+		require.Equal(t, m, got.Matching, name.String())
+	}
+}
+
+func TestTreeMatchIndexed(t *testing.T) {
+	utils.SetTestingT(t)
+
+	tree, names := newSiblingTree(t, 2000)
+	requireSameMatch(t, tree, names)
+	require.True(t, tree.At(utils.WithoutErr(enc.NamePatternFromStr("/app/<any>/lit"))) == tree.Match(names[2000]).Node)
+
+	// The index follows runtime changes
+	require.NoError(t, tree.RemoveNode(utils.WithoutErr(enc.NamePatternFromStr("/app/<any>"))))
+	require.NoError(t, tree.AddNode(utils.WithoutErr(enc.NamePatternFromStr("/app/<q>/lit")), "LeafNode", nil))
+	require.NoError(t, tree.RemoveNode(utils.WithoutErr(enc.NamePatternFromStr("/app/n5"))))
+	requireSameMatch(t, tree, names)
+	require.True(t, tree.At(utils.WithoutErr(enc.NamePatternFromStr("/app/zzz/lit"))) == tree.Match(names[2000]).Node)
+	require.Nil(t, tree.Match(names[5]))
+}
+
+func BenchmarkTreeMatch(b *testing.B) {
+	const count = 10000
+	tree, names := newSiblingTree(b, count)
+	b.Run("Indexed", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tree.Match(names[i%count])
+		}
+	})
+	b.Run("Linear", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			linearMatch(tree.Root(), names[i%count][1:], enc.Matching{})
+		}
+	})
+}
@@ -5,7 +5,10 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
 	"github.com/zjkmxy/go-ndn/pkg/ndn"
@@ -17,9 +20,30 @@ type ContentKey struct {
 	keybits []byte
 }
 
-// ContentKeyNode handles the generation and fetching of content key, as a proof of concept demo
+// ContentKeyNode handles the generation and fetching of content key, as a proof of concept demo.
+// It encrypts content with AES-CBC, which is not authenticated; AesGcmContentKeyNode should be used instead.
 type ContentKeyNode struct {
 	schema.BaseNodeImpl
+
+	// RotationInterval is the lifetime of a key returned by CurrentKey. 0 means the key never rotates.
+	RotationInterval time.Duration
+	// GraceWindow is how long a key can still decrypt after it is replaced by RotateKey.
+	GraceWindow time.Duration
+
+	// rotateLock serializes the rotations, so concurrent CurrentKey calls do not rotate the key twice.
+	rotateLock sync.Mutex
+	lock       sync.Mutex
+	current    *keyState
+	// keys are the keys generated by this node, indexed by hex ckid
+	keys map[string]*keyState
+}
+
+// keyState is a content key generated locally.
+type keyState struct {
+	ck      ContentKey
+	created time.Time
+	// expiry is when the key stops decrypting. Zero means never.
+	expiry time.Time
 }
 
 func (n *ContentKeyNode) NodeImplTrait() schema.NodeImpl {
@@ -44,14 +68,66 @@ func CreateContentKeyNode(node *schema.Node) schema.NodeImpl {
 			OnAttachEvt: &schema.EventTarget{},
 			OnDetachEvt: &schema.EventTarget{},
 		},
+		keys: map[string]*keyState{},
 	}
 	path, _ := enc.NamePatternFromStr("<contentKeyID>")
-	leaf := node.PutNode(path, schema.LeafNodeDesc).Impl().(*schema.LeafNode)
+	leaf := node.PutNode(path, contentKeyLeafDesc).Impl().(*contentKeyLeaf)
 	leaf.ContentType = ndn.ContentTypeKey
 	leaf.MustBeFresh = false
+	leaf.owner = ret
 	return ret
 }
 
+// contentKeyLeaf is the LeafNode publishing the keys of a ContentKeyNode.
+// It drops Interests for expired keys before the cache and storage are searched.
+type contentKeyLeaf struct {
+	*schema.LeafNode
+
+	owner *ContentKeyNode
+}
+
+func (n *contentKeyLeaf) NodeImplTrait() schema.NodeImpl {
+	return n
+}
+
+func (n *contentKeyLeaf) CastTo(ptr any) any {
+	switch ptr.(type) {
+	case (*contentKeyLeaf):
+		return n
+	default:
+		return n.LeafNode.CastTo(ptr)
+	}
+}
+
+func (n *contentKeyLeaf) OnInterest(
+	interest ndn.Interest, rawInterest enc.Wire, sigCovered enc.Wire,
+	reply ndn.ReplyFunc, deadline time.Time, matching enc.Matching,
+) {
+	if n.owner.expired(matching["contentKeyID"]) {
+		mNode := schema.MatchedNode{Node: n.Node, Matching: matching, Name: interest.Name()}
+		mNode.Logger("ContentKeyNode").Warn("Interest for an expired content key. Drop.")
+		return
+	}
+	n.LeafNode.OnInterest(interest, rawInterest, sigCovered, reply, deadline, matching)
+}
+
+func createContentKeyLeaf(node *schema.Node) schema.NodeImpl {
+	return &contentKeyLeaf{LeafNode: schema.CreateLeafNode(node).(*schema.LeafNode)}
+}
+
+// expired returns true if ckid is a key generated here that has expired.
+func (n *ContentKeyNode) expired(ckid []byte) bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	state, ok := n.keys[hex.EncodeToString(ckid)]
+	return ok && state.isExpired(n.Node.Engine().Timer().Now())
+}
+
+// isExpired returns true if the key does not decrypt at now.
+func (s *keyState) isExpired(now time.Time) bool {
+	return !s.expiry.IsZero() && !now.Before(s.expiry)
+}
+
 func (n *ContentKeyNode) GenKey(mNode schema.MatchedNode) ContentKey {
 	keybits := make([]byte, 32)
 	rand.Read(keybits) // Should always succeed
@@ -65,7 +141,63 @@ func (n *ContentKeyNode) GenKey(mNode schema.MatchedNode) ContentKey {
 	ckMNode := mNode.Refine(ckName)
 	ckMNode.Call("Provide", enc.Wire{keybits})
 
-	return ContentKey{ckid, keybits}
+	ck := ContentKey{ckid, keybits}
+	n.lock.Lock()
+	n.keys[hex.EncodeToString(ckid)] = &keyState{ck: ck, created: n.Node.Engine().Timer().Now()}
+	n.lock.Unlock()
+	return ck
+}
+
+// RotateKey generates and publishes a new content key under a new contentKeyID, which is returned by CurrentKey
+// from now on. The replaced key keeps decrypting for GraceWindow, so that content encrypted before the rotation
+// stays readable for a while.
+func (n *ContentKeyNode) RotateKey(mNode schema.MatchedNode) ContentKey {
+	n.rotateLock.Lock()
+	defer n.rotateLock.Unlock()
+	return n.rotateKey(mNode)
+}
+
+// rotateKey is RotateKey, called with rotateLock held.
+func (n *ContentKeyNode) rotateKey(mNode schema.MatchedNode) ContentKey {
+	ck := n.GenKey(mNode)
+	now := n.Node.Engine().Timer().Now()
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if n.current != nil {
+		n.current.expiry = now.Add(n.GraceWindow)
+	}
+	n.current = n.keys[hex.EncodeToString(ck.ckid)]
+	return ck
+}
+
+// CurrentKey returns the key to encrypt new content, and rotates it first if it is older than RotationInterval.
+func (n *ContentKeyNode) CurrentKey(mNode schema.MatchedNode) ContentKey {
+	n.rotateLock.Lock()
+	defer n.rotateLock.Unlock()
+	now := n.Node.Engine().Timer().Now()
+	n.lock.Lock()
+	cur := n.current
+	n.lock.Unlock()
+	if cur == nil || (n.RotationInterval > 0 && now.Sub(cur.created) >= n.RotationInterval) {
+		return n.rotateKey(mNode)
+	}
+	return cur.ck
+}
+
+// localKey returns the key bits of a locally generated key.
+// The returned error is not nil if the key is generated here but has expired.
+func (n *ContentKeyNode) localKey(ckid []byte) ([]byte, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	state, ok := n.keys[hex.EncodeToString(ckid)]
+	if !ok {
+		return nil, nil
+	}
+	if state.isExpired(n.Node.Engine().Timer().Now()) {
+		return nil, fmt.Errorf("content key %s has expired", hex.EncodeToString(ckid))
+	}
+	return state.ck.keybits, nil
 }
 
 func (n *ContentKeyNode) Encrypt(mNode schema.MatchedNode, ck ContentKey, content enc.Wire) enc.Wire {
@@ -75,31 +207,11 @@ func (n *ContentKeyNode) Encrypt(mNode schema.MatchedNode, ck ContentKey, conten
 		return nil
 	}
 
-	aescis, err := aes.NewCipher(ck.keybits)
+	encContent, err := aesEncrypt(ck.ckid, ck.keybits, content)
 	if err != nil {
-		logger.Errorf("unable to create cipher: %+v", err)
+		logger.Errorf("unable to encrypt: %+v", err)
 		return nil
 	}
-	iv := make([]byte, aescis.BlockSize())
-	rand.Read(iv)
-	cip := cipher.NewCBCEncrypter(aescis, iv)
-	bs := cip.BlockSize()
-	l := content.Length()
-	blkn := (int(l) + bs - 1) / bs
-	totalLen := blkn * bs
-	outbuf := make([]byte, totalLen)
-	inbuf := make([]byte, blkn*bs)
-	bp := 0
-	for _, v := range content {
-		bp += copy(inbuf[bp:], v)
-	}
-	encContent := &EncryptedContent{
-		KeyId:         ck.ckid,
-		Iv:            iv,
-		ContentLength: l,
-		CipherText:    enc.Wire{outbuf},
-	}
-	cip.CryptBlocks(outbuf, inbuf)
 	return encContent.Encode()
 }
 
@@ -113,10 +225,35 @@ func (n *ContentKeyNode) Decrypt(mNode schema.MatchedNode, encryptedContent enc.
 		return nil
 	}
 
+	keybits := n.findKey(mNode, encContent.KeyId)
+	if keybits == nil {
+		return nil
+	}
+
+	plainText, err := aesDecrypt(keybits, encContent)
+	if err != nil {
+		logger.Errorf("unable to decrypt with key %s: %+v", hex.EncodeToString(encContent.KeyId), err)
+		return nil
+	}
+	return enc.Wire{plainText}
+}
+
+// findKey returns the key bits of ckid, generated here or fetched under mNode. It returns nil on failure.
+func (n *ContentKeyNode) findKey(mNode schema.MatchedNode, ckid []byte) []byte {
+	logger := mNode.Logger("ContentKeyNode")
+	// Keys generated here are not fetched, so expired ones are refused
+	keybits, err := n.localKey(ckid)
+	if err != nil {
+		logger.Warnf("unable to decrypt: %v", err)
+		return nil
+	}
+	if keybits != nil {
+		return keybits
+	}
 	nameLen := len(mNode.Name)
 	ckName := make(enc.Name, nameLen+1)
 	copy(ckName, mNode.Name) // Note this does not actually copies the component values
-	ckName[nameLen] = enc.Component{Typ: enc.TypeGenericNameComponent, Val: encContent.KeyId}
+	ckName[nameLen] = enc.Component{Typ: enc.TypeGenericNameComponent, Val: ckid}
 	ckMNode := mNode.Refine(ckName)
 
 	ckResult := <-ckMNode.Call("NeedChan").(chan schema.NeedResult)
@@ -124,35 +261,86 @@ func (n *ContentKeyNode) Decrypt(mNode schema.MatchedNode, encryptedContent enc.
 		logger.Warnf("unable to fetch content key: %s", ckName.String())
 		return nil
 	}
+	return ckResult.Content.Join()
+}
 
-	aescis, err := aes.NewCipher(ckResult.Content.Join())
+// aesEncrypt encrypts content with AES-CBC under keybits, which is identified by keyId.
+func aesEncrypt(keyId []byte, keybits []byte, content enc.Wire) (*EncryptedContent, error) {
+	aescis, err := aes.NewCipher(keybits)
 	if err != nil {
-		logger.Errorf("unable to create AES cipher for key: %s", ckResult.Data.Name().String())
-		return nil
+		return nil, err
+	}
+	iv := make([]byte, aescis.BlockSize())
+	rand.Read(iv)
+	cip := cipher.NewCBCEncrypter(aescis, iv)
+	bs := cip.BlockSize()
+	l := content.Length()
+	blkn := (int(l) + bs - 1) / bs
+	totalLen := blkn * bs
+	outbuf := make([]byte, totalLen)
+	inbuf := make([]byte, blkn*bs)
+	bp := 0
+	for _, v := range content {
+		bp += copy(inbuf[bp:], v)
 	}
+	cip.CryptBlocks(outbuf, inbuf)
+	return &EncryptedContent{
+		KeyId:         keyId,
+		Iv:            iv,
+		ContentLength: l,
+		CipherText:    enc.Wire{outbuf},
+	}, nil
+}
 
+// aesDecrypt decrypts an EncryptedContent given by aesEncrypt.
+func aesDecrypt(keybits []byte, encContent *EncryptedContent) ([]byte, error) {
+	aescis, err := aes.NewCipher(keybits)
+	if err != nil {
+		return nil, err
+	}
 	iv := encContent.Iv
 	inbuf := encContent.CipherText.Join()
+	if len(iv) != aescis.BlockSize() {
+		return nil, errors.New("invalid IV length")
+	}
 	cip := cipher.NewCBCDecrypter(aescis, iv)
-	if len(inbuf)%cip.BlockSize() != 0 {
-		logger.Errorf("input AES buf has a wrong length")
-		return nil
+	if len(inbuf)%cip.BlockSize() != 0 || encContent.ContentLength > uint64(len(inbuf)) {
+		return nil, errors.New("input AES buf has a wrong length")
 	}
-	cip.CryptBlocks(inbuf, inbuf)
-	return enc.Wire{inbuf[:encContent.ContentLength]}
+	// The cipher text may share the buffer with encryptedContent, which can be decrypted again
+	outbuf := make([]byte, len(inbuf))
+	cip.CryptBlocks(outbuf, inbuf)
+	return outbuf[:encContent.ContentLength], nil
 }
 
 var (
 	ContentKeyNodeDesc *schema.NodeImplDesc
+	// contentKeyLeafDesc is LeafNodeDesc creating a contentKeyLeaf. It is not registered, as it is only
+	// created by ContentKeyNode.
+	contentKeyLeafDesc *schema.NodeImplDesc
 )
 
 func init() {
+	contentKeyLeafDesc = &schema.NodeImplDesc{
+		ClassName:  schema.LeafNodeDesc.ClassName,
+		Properties: schema.LeafNodeDesc.Properties,
+		Events:     schema.LeafNodeDesc.Events,
+		Functions:  schema.LeafNodeDesc.Functions,
+		Create:     createContentKeyLeaf,
+		Validate: func(owner any) error {
+			return schema.LeafNodeDesc.Validate(owner.(*contentKeyLeaf).LeafNode)
+		},
+	}
+
 	ContentKeyNodeDesc = &schema.NodeImplDesc{
 		ClassName: "ContentKeyNode",
 		Properties: map[schema.PropKey]schema.PropertyDesc{
 			"Lifetime":      schema.SubNodePropertyDesc("<contentKeyID>", "Lifetime"),
 			"Freshness":     schema.SubNodePropertyDesc("<contentKeyID>", "Freshness"),
 			"ValidDuration": schema.SubNodePropertyDesc("<contentKeyID>", "ValidDuration"),
+
+			"RotationInterval": schema.TimePropertyDesc("RotationInterval"),
+			"GraceWindow":      schema.TimePropertyDesc("GraceWindow"),
 		},
 		Events: map[schema.PropKey]schema.EventGetter{
 			schema.PropOnAttach: schema.DefaultEventTarget(schema.PropOnAttach), // Inherited from base
@@ -167,6 +355,22 @@ func init() {
 				}
 				return schema.QueryInterface[*ContentKeyNode](mNode.Node).GenKey(mNode)
 			},
+			"RotateKey": func(mNode schema.MatchedNode, args ...any) any {
+				if len(args) > 0 {
+					err := fmt.Errorf("ContentKeyNode.RotateKey requires 0 arguments but got %d", len(args))
+					mNode.Logger("ContentKeyNode").Error(err.Error())
+					return err
+				}
+				return schema.QueryInterface[*ContentKeyNode](mNode.Node).RotateKey(mNode)
+			},
+			"CurrentKey": func(mNode schema.MatchedNode, args ...any) any {
+				if len(args) > 0 {
+					err := fmt.Errorf("ContentKeyNode.CurrentKey requires 0 arguments but got %d", len(args))
+					mNode.Logger("ContentKeyNode").Error(err.Error())
+					return err
+				}
+				return schema.QueryInterface[*ContentKeyNode](mNode.Node).CurrentKey(mNode)
+			},
 			"Encrypt": func(mNode schema.MatchedNode, args ...any) any {
 				if len(args) != 2 {
 					err := fmt.Errorf("ContentKeyNode.Encrypt requires 2 arguments but got %d", len(args))
@@ -210,4 +414,5 @@ func init() {
 		Create: CreateContentKeyNode,
 	}
 	schema.RegisterNodeImpl(ContentKeyNodeDesc)
+	initAesGcmContentKeyNodeDesc()
 }
@@ -0,0 +1,141 @@
+package demosec_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/engine/dummy"
+	"github.com/zjkmxy/go-ndn/pkg/ndn/spec_2022"
+	"github.com/zjkmxy/go-ndn/pkg/schema"
+	"github.com/zjkmxy/go-ndn/pkg/schema/demosec"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+func TestContentKeyRollover(t *testing.T) {
+	for _, nodeType := range []string{"ContentKeyNode", "AesGcmContentKeyNode"} {
+		treeJson := `{
+			"nodes": {
+				"/ck": {"type": "` + nodeType + `", "attrs": {"RotationInterval": 10000, "GraceWindow": 5000}}
+			},
+			"policies": [
+				{"type": "Sha256Signer", "path": "/ck/<contentKeyID>"}
+			]
+		}`
+		executeTest(t, treeJson, func(tree *schema.Tree, timer *dummy.Timer) {
+			ckNode := tree.Match(utils.WithoutErr(enc.NameFromStr("/p/ck")))
+			// decrypt returns nil if the content cannot be decrypted
+			decrypt := func(encContent enc.Wire) []byte {
+				plainText, _ := ckNode.Call("Decrypt", encContent).(enc.Wire)
+				if plainText == nil {
+					return nil
+				}
+				return plainText.Join()
+			}
+
+			ck1 := ckNode.Call("CurrentKey").(demosec.ContentKey)
+			old := ckNode.Call("Encrypt", ck1, enc.Wire{[]byte("old")}).(enc.Wire)
+			timer.MoveForward(9 * time.Second)
+			require.Equal(t, ck1, ckNode.Call("CurrentKey"))
+
+			// The key rotates after RotationInterval
+			timer.MoveForward(1 * time.Second)
+			ck2 := ckNode.Call("CurrentKey").(demosec.ContentKey)
+			require.NotEqual(t, ck1, ck2)
+			require.Equal(t, ck2, ckNode.Call("CurrentKey"))
+			cur := ckNode.Call("Encrypt", ck2, enc.Wire{[]byte("new")}).(enc.Wire)
+
+			// The replaced key still decrypts within GraceWindow
+			require.Equal(t, []byte("old"), decrypt(old))
+			timer.MoveForward(4900 * time.Millisecond)
+			require.Equal(t, []byte("old"), decrypt(old))
+			require.Equal(t, []byte("new"), decrypt(cur))
+
+			// But not after it
+			timer.MoveForward(100 * time.Millisecond)
+			require.Nil(t, decrypt(old))
+			require.Equal(t, []byte("new"), decrypt(cur))
+
+			// RotateKey rotates at once
+			ck3 := ckNode.Call("RotateKey").(demosec.ContentKey)
+			require.NotEqual(t, ck2, ck3)
+			require.Equal(t, ck3, ckNode.Call("CurrentKey"))
+			require.Equal(t, []byte("new"), decrypt(cur))
+			timer.MoveForward(5 * time.Second)
+			require.Nil(t, decrypt(cur))
+			require.Nil(t, decrypt(old))
+		})
+	}
+}
+
+func TestContentKeyExpiredNotServed(t *testing.T) {
+	const treeJson = `{
+		"nodes": {
+			"/ck": {"type": "ContentKeyNode", "attrs": {"GraceWindow": 5000}}
+		},
+		"policies": [
+			{"type": "Sha256Signer", "path": "/ck/<contentKeyID>"},
+			{"type": "MemStorage", "path": "/", "attrs": {}}
+		]
+	}`
+	executeTest(t, treeJson, func(tree *schema.Tree, timer *dummy.Timer) {
+		ckNode := tree.Match(utils.WithoutErr(enc.NameFromStr("/p/ck")))
+		ck := ckNode.Call("CurrentKey").(demosec.ContentKey)
+		encContent := ckNode.Call("Encrypt", ck, enc.Wire{[]byte("content")}).(enc.Wire)
+		ckid := utils.WithoutErr(demosec.ParseEncryptedContent(enc.NewWireReader(encContent), true)).KeyId
+		ckName := append(utils.WithoutErr(enc.NameFromStr("/p/ck")), enc.NewBytesComponent(enc.TypeGenericNameComponent, ckid))
+
+		// serve returns if the Interest for the key is replied
+		serve := func() bool {
+			mNode := tree.Match(ckName)
+			replied := false
+			mNode.Node.OnInterest(&spec_2022.Interest{NameV: ckName}, nil, nil, func(enc.Wire) error {
+				replied = true
+				return nil
+			}, timer.Now().Add(time.Second), mNode.Matching)
+			return replied
+		}
+
+		require.True(t, serve())
+		ckNode.Call("RotateKey")
+		timer.MoveForward(4900 * time.Millisecond)
+		require.True(t, serve())
+
+		// Neither the cache of the leaf nor the storage serves the key after the grace window
+		timer.MoveForward(100 * time.Millisecond)
+		require.False(t, serve())
+	})
+}
+
+func TestContentKeyCurrentKeyConcurrent(t *testing.T) {
+	const treeJson = `{
+		"nodes": {
+			"/ck": {"type": "ContentKeyNode", "attrs": {"RotationInterval": 10000}}
+		},
+		"policies": [
+			{"type": "Sha256Signer", "path": "/ck/<contentKeyID>"}
+		]
+	}`
+	executeTest(t, treeJson, func(tree *schema.Tree, timer *dummy.Timer) {
+		ckNode := tree.Match(utils.WithoutErr(enc.NameFromStr("/p/ck")))
+		// All callers of an expired key get the same new key
+		for range 3 {
+			keys := make([]demosec.ContentKey, 10)
+			var wg sync.WaitGroup
+			for i := range keys {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					keys[i] = ckNode.Call("CurrentKey").(demosec.ContentKey)
+				}()
+			}
+			wg.Wait()
+			for _, ck := range keys {
+				require.Equal(t, keys[0], ck)
+			}
+			timer.MoveForward(10 * time.Second)
+		}
+	})
+}
@@ -0,0 +1,261 @@
+package demosec
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/schema"
+)
+
+// gcmMaxMessages is the number of messages a key may encrypt with random nonces, by NIST SP 800-38D.
+const gcmMaxMessages = 1 << 32
+
+// AesGcmContentKeyNode is a ContentKeyNode encrypting content with AES-256-GCM, which also authenticates it.
+// Keys are generated, rotated and fetched the same way, and Encrypt/Decrypt take the same arguments,
+// so a schema can use either class. The encrypted content is an EncryptedContent whose Iv is the 96-bit nonce
+// and CipherText ends with the 128-bit tag. The ckid is authenticated as additional data.
+// Every message takes a random nonce, and a key refuses to encrypt more than 2^32 messages,
+// so nonces of a key do not repeat with practical probability. RotationInterval should rotate keys before that.
+type AesGcmContentKeyNode struct {
+	*ContentKeyNode
+
+	// uses counts the messages encrypted by each key, indexed by hex ckid
+	uses map[string]uint64
+}
+
+func (n *AesGcmContentKeyNode) NodeImplTrait() schema.NodeImpl {
+	return n
+}
+
+func (n *AesGcmContentKeyNode) CastTo(ptr any) any {
+	switch ptr.(type) {
+	case (*AesGcmContentKeyNode):
+		return n
+	case (*ContentKeyNode):
+		return n.ContentKeyNode
+	case (*schema.BaseNodeImpl):
+		return &(n.BaseNodeImpl)
+	default:
+		return nil
+	}
+}
+
+func CreateAesGcmContentKeyNode(node *schema.Node) schema.NodeImpl {
+	return &AesGcmContentKeyNode{
+		ContentKeyNode: CreateContentKeyNode(node).(*ContentKeyNode),
+		uses:           map[string]uint64{},
+	}
+}
+
+// useKey counts a message encrypted by ckid, and returns false if the key has encrypted too many.
+func (n *AesGcmContentKeyNode) useKey(ckid []byte) bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	id := hex.EncodeToString(ckid)
+	if n.uses[id] >= gcmMaxMessages {
+		return false
+	}
+	n.uses[id]++
+	return true
+}
+
+func (n *AesGcmContentKeyNode) Encrypt(mNode schema.MatchedNode, ck ContentKey, content enc.Wire) enc.Wire {
+	logger := mNode.Logger("AesGcmContentKeyNode")
+	if len(ck.ckid) != 8 || len(ck.keybits) != 32 {
+		logger.Errorf("invalid content key: %v", hex.EncodeToString(ck.ckid))
+		return nil
+	}
+	if !n.useKey(ck.ckid) {
+		logger.Errorf("content key %s has encrypted too many messages", hex.EncodeToString(ck.ckid))
+		return nil
+	}
+
+	encContent, err := gcmEncrypt(ck.ckid, ck.keybits, content)
+	if err != nil {
+		logger.Errorf("unable to encrypt: %+v", err)
+		return nil
+	}
+	return encContent.Encode()
+}
+
+// Decrypt returns nil if the content is malformed, the key is unavailable, or the content is not authentic.
+func (n *AesGcmContentKeyNode) Decrypt(mNode schema.MatchedNode, encryptedContent enc.Wire) enc.Wire {
+	logger := mNode.Logger("AesGcmContentKeyNode")
+
+	encContent, err := ParseEncryptedContent(enc.NewWireReader(encryptedContent), true)
+	if err != nil {
+		logger.Errorf("malformed encrypted packet")
+		return nil
+	}
+	keybits := n.findKey(mNode, encContent.KeyId)
+	if keybits == nil {
+		return nil
+	}
+
+	plainText, err := gcmDecrypt(keybits, encContent)
+	if err != nil {
+		logger.Errorf("unable to decrypt with key %s: %+v", hex.EncodeToString(encContent.KeyId), err)
+		return nil
+	}
+	return enc.Wire{plainText}
+}
+
+// gcmEncrypt encrypts content with AES-GCM under keybits, which is identified by keyId.
+func gcmEncrypt(keyId []byte, keybits []byte, content enc.Wire) (*EncryptedContent, error) {
+	aead, err := newGcm(keybits)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &EncryptedContent{
+		KeyId:         keyId,
+		Iv:            nonce,
+		ContentLength: content.Length(),
+		CipherText:    enc.Wire{aead.Seal(nil, nonce, content.Join(), keyId)},
+	}, nil
+}
+
+// gcmDecrypt decrypts and authenticates an EncryptedContent given by gcmEncrypt.
+func gcmDecrypt(keybits []byte, encContent *EncryptedContent) ([]byte, error) {
+	aead, err := newGcm(keybits)
+	if err != nil {
+		return nil, err
+	}
+	if len(encContent.Iv) != aead.NonceSize() {
+		return nil, errors.New("invalid nonce length")
+	}
+	plainText, err := aead.Open(nil, encContent.Iv, encContent.CipherText.Join(), encContent.KeyId)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(plainText)) != encContent.ContentLength {
+		return nil, errors.New("content length mismatch")
+	}
+	return plainText, nil
+}
+
+func newGcm(keybits []byte) (cipher.AEAD, error) {
+	if len(keybits) != 32 {
+		return nil, errors.New("AES-256-GCM requires a 256-bit key")
+	}
+	block, err := aes.NewCipher(keybits)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+var (
+	AesGcmContentKeyNodeDesc *schema.NodeImplDesc
+)
+
+// initAesGcmContentKeyNodeDesc registers AesGcmContentKeyNode, which has the properties and functions
+// of ContentKeyNode, with Encrypt and Decrypt replaced, and EncryptSegments and DecryptSegment added.
+func initAesGcmContentKeyNodeDesc() {
+	AesGcmContentKeyNodeDesc = &schema.NodeImplDesc{
+		ClassName:  "AesGcmContentKeyNode",
+		Properties: make(map[schema.PropKey]schema.PropertyDesc, len(ContentKeyNodeDesc.Properties)),
+		Events:     make(map[schema.PropKey]schema.EventGetter, len(ContentKeyNodeDesc.Events)),
+		Functions:  make(map[string]schema.NodeFunc, len(ContentKeyNodeDesc.Functions)),
+		Create:     CreateAesGcmContentKeyNode,
+	}
+	for k, v := range ContentKeyNodeDesc.Properties {
+		AesGcmContentKeyNodeDesc.Properties[k] = v
+	}
+	for k, v := range ContentKeyNodeDesc.Events {
+		AesGcmContentKeyNodeDesc.Events[k] = v
+	}
+	for k, v := range ContentKeyNodeDesc.Functions {
+		AesGcmContentKeyNodeDesc.Functions[k] = v
+	}
+	AesGcmContentKeyNodeDesc.Functions["Encrypt"] = func(mNode schema.MatchedNode, args ...any) any {
+		if len(args) != 2 {
+			err := fmt.Errorf("AesGcmContentKeyNode.Encrypt requires 2 arguments but got %d", len(args))
+			mNode.Logger("AesGcmContentKeyNode").Error(err.Error())
+			return err
+		}
+		ck, ok := args[0].(ContentKey)
+		if !ok && args[0] != nil {
+			err := ndn.ErrInvalidValue{Item: "ck", Value: args[0]}
+			mNode.Logger("AesGcmContentKeyNode").Error(err.Error())
+			return err
+		}
+		content, ok := args[1].(enc.Wire)
+		if !ok && args[1] != nil {
+			err := ndn.ErrInvalidValue{Item: "content", Value: args[1]}
+			mNode.Logger("AesGcmContentKeyNode").Error(err.Error())
+			return err
+		}
+		return schema.QueryInterface[*AesGcmContentKeyNode](mNode.Node).Encrypt(mNode, ck, content)
+	}
+	AesGcmContentKeyNodeDesc.Functions["Decrypt"] = func(mNode schema.MatchedNode, args ...any) any {
+		if len(args) != 1 {
+			err := fmt.Errorf("AesGcmContentKeyNode.Decrypt requires 1 arguments but got %d", len(args))
+			mNode.Logger("AesGcmContentKeyNode").Error(err.Error())
+			return err
+		}
+		encryptedContent, ok := args[0].(enc.Wire)
+		if !ok && args[0] != nil {
+			err := ndn.ErrInvalidValue{Item: "encryptedContent", Value: args[0]}
+			mNode.Logger("AesGcmContentKeyNode").Error(err.Error())
+			return err
+		}
+		return schema.QueryInterface[*AesGcmContentKeyNode](mNode.Node).Decrypt(mNode, encryptedContent)
+	}
+	AesGcmContentKeyNodeDesc.Functions["EncryptSegments"] = func(mNode schema.MatchedNode, args ...any) any {
+		if len(args) != 3 {
+			err := fmt.Errorf("AesGcmContentKeyNode.EncryptSegments requires 3 arguments but got %d", len(args))
+			mNode.Logger("AesGcmContentKeyNode").Error(err.Error())
+			return err
+		}
+		ck, ok := args[0].(ContentKey)
+		if !ok && args[0] != nil {
+			err := ndn.ErrInvalidValue{Item: "ck", Value: args[0]}
+			mNode.Logger("AesGcmContentKeyNode").Error(err.Error())
+			return err
+		}
+		content, ok := args[1].(enc.Wire)
+		if !ok && args[1] != nil {
+			err := ndn.ErrInvalidValue{Item: "content", Value: args[1]}
+			mNode.Logger("AesGcmContentKeyNode").Error(err.Error())
+			return err
+		}
+		segSize, ok := args[2].(uint64)
+		if !ok {
+			err := ndn.ErrInvalidValue{Item: "segSize", Value: args[2]}
+			mNode.Logger("AesGcmContentKeyNode").Error(err.Error())
+			return err
+		}
+		return schema.QueryInterface[*AesGcmContentKeyNode](mNode.Node).EncryptSegments(mNode, ck, content, segSize)
+	}
+	AesGcmContentKeyNodeDesc.Functions["DecryptSegment"] = func(mNode schema.MatchedNode, args ...any) any {
+		if len(args) != 2 {
+			err := fmt.Errorf("AesGcmContentKeyNode.DecryptSegment requires 2 arguments but got %d", len(args))
+			mNode.Logger("AesGcmContentKeyNode").Error(err.Error())
+			return err
+		}
+		encryptedSegment, ok := args[0].(enc.Wire)
+		if !ok && args[0] != nil {
+			err := ndn.ErrInvalidValue{Item: "encryptedSegment", Value: args[0]}
+			mNode.Logger("AesGcmContentKeyNode").Error(err.Error())
+			return err
+		}
+		segNo, ok := args[1].(uint64)
+		if !ok {
+			err := ndn.ErrInvalidValue{Item: "segNo", Value: args[1]}
+			mNode.Logger("AesGcmContentKeyNode").Error(err.Error())
+			return err
+		}
+		return schema.QueryInterface[*AesGcmContentKeyNode](mNode.Node).DecryptSegment(mNode, encryptedSegment, segNo)
+	}
+	schema.RegisterNodeImpl(AesGcmContentKeyNodeDesc)
+}
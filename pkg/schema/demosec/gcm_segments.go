@@ -0,0 +1,128 @@
+package demosec
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/schema"
+)
+
+// gcmObjectSaltSize is the size of the random salt deriving the key of a segmented object.
+const gcmObjectSaltSize = 16
+
+// EncryptSegments splits content into segments of at most segSize bytes and encrypts each of them on its own,
+// so they can be served by SegmentedNode.ProvideSegments and decrypted in any order by DecryptSegment.
+// An empty content is given one empty segment.
+//
+// Segments are encrypted under a key derived from ck and a random salt of the object,
+// HMAC-SHA256(ck, salt), with the segment number in big endian as the nonce.
+// Nonces never repeat under a derived key since segment numbers are distinct, and derived keys of
+// different objects do not repeat since salts are 128-bit random. Thus the ck may encrypt any number of
+// objects without counting towards the limit of Encrypt.
+// Every segment is an EncryptedContent whose Iv is the salt. With the tag and TLV headers,
+// a segment is at most 58 bytes longer than its plaintext, which segSize should leave room for.
+func (n *AesGcmContentKeyNode) EncryptSegments(
+	mNode schema.MatchedNode, ck ContentKey, content enc.Wire, segSize uint64,
+) []enc.Wire {
+	logger := mNode.Logger("AesGcmContentKeyNode")
+	if len(ck.ckid) != 8 || len(ck.keybits) != 32 {
+		logger.Errorf("invalid content key: %v", hex.EncodeToString(ck.ckid))
+		return nil
+	}
+	if segSize == 0 {
+		logger.Errorf("invalid segment size: 0")
+		return nil
+	}
+
+	salt := make([]byte, gcmObjectSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		logger.Errorf("unable to generate salt: %+v", err)
+		return nil
+	}
+	objKey := gcmObjectKey(ck.keybits, salt)
+
+	plainText := content.Join()
+	segCnt := max((uint64(len(plainText))+segSize-1)/segSize, 1)
+	ret := make([]enc.Wire, segCnt)
+	for i := uint64(0); i < segCnt; i++ {
+		start := i * segSize
+		end := min(start+segSize, uint64(len(plainText)))
+		encContent, err := gcmEncryptSegment(ck.ckid, objKey, salt, i, plainText[start:end])
+		if err != nil {
+			logger.Errorf("unable to encrypt segment %d: %+v", i, err)
+			return nil
+		}
+		ret[i] = encContent.Encode()
+	}
+	return ret
+}
+
+// DecryptSegment decrypts the segment segNo of an object given by EncryptSegments.
+// It returns nil if the content is malformed, the key is unavailable,
+// or the content is not authentic, including when it is not the segment segNo.
+func (n *AesGcmContentKeyNode) DecryptSegment(
+	mNode schema.MatchedNode, encryptedSegment enc.Wire, segNo uint64,
+) enc.Wire {
+	logger := mNode.Logger("AesGcmContentKeyNode")
+
+	encContent, err := ParseEncryptedContent(enc.NewWireReader(encryptedSegment), true)
+	if err != nil {
+		logger.Errorf("malformed encrypted packet")
+		return nil
+	}
+	if len(encContent.Iv) != gcmObjectSaltSize {
+		logger.Errorf("invalid salt length: %d", len(encContent.Iv))
+		return nil
+	}
+	keybits := n.findKey(mNode, encContent.KeyId)
+	if keybits == nil {
+		return nil
+	}
+
+	aead, err := newGcm(gcmObjectKey(keybits, encContent.Iv))
+	if err != nil {
+		logger.Errorf("unable to decrypt segment %d: %+v", segNo, err)
+		return nil
+	}
+	plainText, err := aead.Open(nil, gcmSegmentNonce(segNo), encContent.CipherText.Join(), encContent.KeyId)
+	if err == nil && uint64(len(plainText)) != encContent.ContentLength {
+		err = errors.New("content length mismatch")
+	}
+	if err != nil {
+		logger.Errorf("unable to decrypt segment %d with key %s: %+v", segNo, hex.EncodeToString(encContent.KeyId), err)
+		return nil
+	}
+	return enc.Wire{plainText}
+}
+
+// gcmObjectKey derives the key encrypting segments of an object from the content key and the salt of the object.
+func gcmObjectKey(keybits []byte, salt []byte) []byte {
+	mac := hmac.New(sha256.New, keybits)
+	mac.Write(salt)
+	return mac.Sum(nil)
+}
+
+// gcmSegmentNonce returns the 96-bit nonce of segment segNo, which is the segment number in big endian.
+func gcmSegmentNonce(segNo uint64) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[4:], segNo)
+	return nonce
+}
+
+func gcmEncryptSegment(keyId, objKey, salt []byte, segNo uint64, plainText []byte) (*EncryptedContent, error) {
+	aead, err := newGcm(objKey)
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptedContent{
+		KeyId:         keyId,
+		Iv:            salt,
+		ContentLength: uint64(len(plainText)),
+		CipherText:    enc.Wire{aead.Seal(nil, gcmSegmentNonce(segNo), plainText, keyId)},
+	}, nil
+}
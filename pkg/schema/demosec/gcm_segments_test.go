@@ -0,0 +1,135 @@
+package demosec_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	basic_engine "github.com/zjkmxy/go-ndn/pkg/engine/basic"
+	"github.com/zjkmxy/go-ndn/pkg/engine/dummy"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/schema"
+	"github.com/zjkmxy/go-ndn/pkg/schema/demosec"
+	_ "github.com/zjkmxy/go-ndn/pkg/schema/rdr"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+// executeTest attaches the schema tree described by treeJson to /p of a dummy engine.
+func executeTest(t *testing.T, treeJson string, main func(tree *schema.Tree, timer *dummy.Timer)) {
+	utils.SetTestingT(t)
+
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool {
+		return true
+	}
+
+	face := dummy.NewDummyFace()
+	timer := dummy.NewTimer()
+	engine := basic_engine.NewEngine(face, timer, sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(t, engine.Start())
+
+	tree := schema.CreateFromJson(treeJson, nil)
+	require.NoError(t, tree.Attach(utils.WithoutErr(enc.NameFromStr("/p")), engine))
+
+	main(tree, timer)
+
+	tree.Detach()
+	require.NoError(t, engine.Shutdown())
+}
+
+const gcmSegmentsTreeJson = `{
+	"nodes": {
+		"/ck": {"type": "AesGcmContentKeyNode", "attrs": {}},
+		"/obj": {"type": "SegmentedNode", "attrs": {}}
+	},
+	"policies": [
+		{"type": "Sha256Signer", "path": "/ck/<contentKeyID>"},
+		{"type": "Sha256Signer", "path": "/obj/<seg=segmentNumber>"}
+	]
+}`
+
+func TestGcmSegments(t *testing.T) {
+	executeTest(t, gcmSegmentsTreeJson, func(tree *schema.Tree, timer *dummy.Timer) {
+		ckNode := tree.Match(utils.WithoutErr(enc.NameFromStr("/p/ck")))
+		objNode := tree.Match(utils.WithoutErr(enc.NameFromStr("/p/obj")))
+		ck := ckNode.Call("GenKey").(demosec.ContentKey)
+
+		content := make([]byte, 1000)
+		rand.Read(content)
+		segments := ckNode.Call("EncryptSegments", ck, enc.Wire{content}, uint64(100)).([]enc.Wire)
+		require.Equal(t, 10, len(segments))
+		for _, seg := range segments {
+			require.LessOrEqual(t, seg.Length(), uint64(100+58))
+		}
+		require.Equal(t, uint64(10), objNode.Call("ProvideSegments", segments, false))
+
+		// Fetch and decrypt segments one by one out of order, without the others
+		fetchSegment := func(segNo uint64) enc.Wire {
+			name := utils.WithoutErr(enc.NameFromStr(fmt.Sprintf("/p/obj/seg=%d", segNo)))
+			result := <-tree.Match(name).Call("NeedChan").(chan schema.NeedResult)
+			require.Equal(t, ndn.InterestResultData, result.Status)
+			return result.Content
+		}
+		for _, segNo := range []uint64{7, 2, 9, 0} {
+			plainText := ckNode.Call("DecryptSegment", fetchSegment(segNo), segNo).(enc.Wire)
+			require.Equal(t, content[segNo*100:(segNo+1)*100], plainText.Join())
+		}
+
+		// Reassemble the whole object
+		plainText := bytes.Buffer{}
+		for segNo := uint64(0); segNo < 10; segNo++ {
+			plainText.Write(ckNode.Call("DecryptSegment", segments[segNo], segNo).(enc.Wire).Join())
+		}
+		require.Equal(t, content, plainText.Bytes())
+
+		// A segment is accepted only at its own position
+		require.Nil(t, ckNode.Call("DecryptSegment", segments[3], uint64(4)))
+		require.Nil(t, ckNode.Call("DecryptSegment", segments[3], uint64(1<<40|3)))
+
+		// Swapping segments is detected
+		swapped := append([]enc.Wire{}, segments...)
+		swapped[1], swapped[5] = swapped[5], swapped[1]
+		require.Nil(t, ckNode.Call("DecryptSegment", swapped[1], uint64(1)))
+		require.Nil(t, ckNode.Call("DecryptSegment", swapped[5], uint64(5)))
+
+		// The last segment may be shorter
+		segments = ckNode.Call("EncryptSegments", ck, enc.Wire{content[:250]}, uint64(100)).([]enc.Wire)
+		require.Equal(t, 3, len(segments))
+		require.Equal(t, content[200:250], ckNode.Call("DecryptSegment", segments[2], uint64(2)).(enc.Wire).Join())
+
+		// Empty content is given one empty segment
+		segments = ckNode.Call("EncryptSegments", ck, enc.Wire{}, uint64(100)).([]enc.Wire)
+		require.Equal(t, 1, len(segments))
+		require.Equal(t, 0, len(ckNode.Call("DecryptSegment", segments[0], uint64(0)).(enc.Wire).Join()))
+	})
+}
+
+func TestGcmSegmentsTampered(t *testing.T) {
+	executeTest(t, gcmSegmentsTreeJson, func(tree *schema.Tree, timer *dummy.Timer) {
+		ckNode := tree.Match(utils.WithoutErr(enc.NameFromStr("/p/ck")))
+		ck := ckNode.Call("GenKey").(demosec.ContentKey)
+		otherCk := ckNode.Call("GenKey").(demosec.ContentKey)
+
+		segments := ckNode.Call("EncryptSegments", ck, enc.Wire{[]byte("hello, world")}, uint64(100)).([]enc.Wire)
+		require.Equal(t, 1, len(segments))
+		encSeg := segments[0].Join()
+
+		// Flipping a bit anywhere after the KeyId invalidates the segment.
+		// A changed KeyId would make the node fetch the key from the network instead.
+		for i := 10; i < len(encSeg); i++ {
+			tampered := append([]byte{}, encSeg...)
+			tampered[i] ^= 0x01
+			require.Nil(t, ckNode.Call("DecryptSegment", enc.Wire{tampered}, uint64(0)))
+		}
+
+		// A segment of another key is not mistaken for the segment of this key
+		otherSegs := ckNode.Call("EncryptSegments", otherCk, enc.Wire{[]byte("hello, world")}, uint64(100)).([]enc.Wire)
+		require.NotEqual(t, encSeg, otherSegs[0].Join())
+		require.Equal(t, []byte("hello, world"), ckNode.Call("DecryptSegment", otherSegs[0], uint64(0)).(enc.Wire).Join())
+
+		require.Nil(t, ckNode.Call("EncryptSegments", ck, enc.Wire{[]byte("hello")}, uint64(0)))
+	})
+}
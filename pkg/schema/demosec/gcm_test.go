@@ -0,0 +1,124 @@
+package demosec
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+func newTestKey() ([]byte, []byte) {
+	ckid := make([]byte, 8)
+	keybits := make([]byte, 32)
+	rand.Read(ckid)
+	rand.Read(keybits)
+	return ckid, keybits
+}
+
+// reparse returns a copy of encContent which does not share buffers with it.
+func reparse(t *testing.T, encContent *EncryptedContent) *EncryptedContent {
+	ret, err := ParseEncryptedContent(enc.NewBufferReader(encContent.Encode().Join()), true)
+	require.NoError(t, err)
+	return ret
+}
+
+func TestGcmRoundTrip(t *testing.T) {
+	utils.SetTestingT(t)
+
+	ckid, keybits := newTestKey()
+	content := enc.Wire{[]byte("hello, "), []byte("world")}
+	encContent := utils.WithoutErr(gcmEncrypt(ckid, keybits, content))
+	require.Equal(t, 12, len(encContent.Iv))
+	require.Equal(t, uint64(12), encContent.ContentLength)
+	// 16 bytes of tag
+	require.Equal(t, uint64(12+16), encContent.CipherText.Length())
+
+	plainText := utils.WithoutErr(gcmDecrypt(keybits, reparse(t, encContent)))
+	require.Equal(t, []byte("hello, world"), plainText)
+
+	// Nonces are not reused
+	encContent2 := utils.WithoutErr(gcmEncrypt(ckid, keybits, content))
+	require.False(t, bytes.Equal(encContent.Iv, encContent2.Iv))
+	require.NotEqual(t, encContent.CipherText.Join(), encContent2.CipherText.Join())
+}
+
+func TestGcmTampered(t *testing.T) {
+	utils.SetTestingT(t)
+
+	ckid, keybits := newTestKey()
+	encContent := utils.WithoutErr(gcmEncrypt(ckid, keybits, enc.Wire{[]byte("hello, world")}))
+
+	// Every flipped bit of the ciphertext or the tag is detected
+	for i := 0; i < len(encContent.CipherText.Join()); i++ {
+		tampered := reparse(t, encContent)
+		tampered.CipherText[0][i] ^= 0x01
+		_, err := gcmDecrypt(keybits, tampered)
+		require.Error(t, err)
+	}
+
+	tampered := reparse(t, encContent)
+	tampered.Iv[0] ^= 0x01
+	_, err := gcmDecrypt(keybits, tampered)
+	require.Error(t, err)
+
+	tampered = reparse(t, encContent)
+	tampered.Iv = tampered.Iv[:8]
+	_, err = gcmDecrypt(keybits, tampered)
+	require.Error(t, err)
+
+	tampered = reparse(t, encContent)
+	tampered.ContentLength++
+	_, err = gcmDecrypt(keybits, tampered)
+	require.Error(t, err)
+}
+
+func TestGcmChangedKeyId(t *testing.T) {
+	utils.SetTestingT(t)
+
+	ckid, keybits := newTestKey()
+	encContent := utils.WithoutErr(gcmEncrypt(ckid, keybits, enc.Wire{[]byte("hello, world")}))
+
+	// The ckid is authenticated, so a ciphertext cannot be relabeled as another key's
+	changed := reparse(t, encContent)
+	changed.KeyId[7] ^= 0x01
+	_, err := gcmDecrypt(keybits, changed)
+	require.Error(t, err)
+}
+
+func TestGcmWrongKey(t *testing.T) {
+	utils.SetTestingT(t)
+
+	ckid, keybits := newTestKey()
+	_, otherKeybits := newTestKey()
+	encContent := utils.WithoutErr(gcmEncrypt(ckid, keybits, enc.Wire{[]byte("hello, world")}))
+
+	_, err := gcmDecrypt(otherKeybits, reparse(t, encContent))
+	require.Error(t, err)
+	_, err = gcmDecrypt(keybits[:16], reparse(t, encContent))
+	require.Error(t, err)
+}
+
+func TestGcmKeyUseLimit(t *testing.T) {
+	utils.SetTestingT(t)
+
+	n := &AesGcmContentKeyNode{
+		ContentKeyNode: &ContentKeyNode{},
+		uses:           map[string]uint64{},
+	}
+	ckid, _ := newTestKey()
+	otherCkid, _ := newTestKey()
+
+	require.True(t, n.useKey(ckid))
+	require.Equal(t, uint64(1), n.uses[hex.EncodeToString(ckid)])
+
+	n.uses[hex.EncodeToString(ckid)] = gcmMaxMessages - 1
+	require.True(t, n.useKey(ckid))
+	require.False(t, n.useKey(ckid))
+	require.False(t, n.useKey(ckid))
+	// Other keys are not affected
+	require.True(t, n.useKey(otherCkid))
+}
@@ -0,0 +1,559 @@
+package demosec
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/schema"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+// The NAC (Name-based Access Control) nodes, as a proof of concept demo.
+//
+// An access manager owns a key pair of KEK (key-encryption key, the public key) and KDK (key-decryption key,
+// the private key). It publishes the KEK at /<AccessManager>/KEK, and the KDK encrypted under the public key
+// of a consumer at /<AccessManager>/KDK/<consumer> when the consumer is granted.
+// A producer encrypts its content under a content key (CK), and publishes the CK encrypted under the KEK at
+// /<producer node>/CK/<ckID>. A consumer fetches the CK and its KDK to decrypt the content.
+//
+// Note: KEK and KDK are fetched from outside the tree, so they are not validated by the tree's policies.
+// Instead, the encrypters and decrypters validate them by their certificate chains up to TrustAnchor,
+// and refuse to use a key that does not validate.
+// RSA-OAEP with SHA-256 is used for the public key encryption, and AES-CBC for the rest.
+
+// NacAccessManagerNode publishes the KEK and KDKs of an access manager.
+type NacAccessManagerNode struct {
+	schema.BaseNodeImpl
+
+	lock sync.Mutex
+	kdk  *rsa.PrivateKey
+}
+
+func (n *NacAccessManagerNode) NodeImplTrait() schema.NodeImpl {
+	return n
+}
+
+func (n *NacAccessManagerNode) CastTo(ptr any) any {
+	switch ptr.(type) {
+	case (*NacAccessManagerNode):
+		return n
+	case (*schema.BaseNodeImpl):
+		return &(n.BaseNodeImpl)
+	default:
+		return nil
+	}
+}
+
+func CreateNacAccessManagerNode(node *schema.Node) schema.NodeImpl {
+	ret := &NacAccessManagerNode{
+		BaseNodeImpl: schema.BaseNodeImpl{
+			Node:        node,
+			OnAttachEvt: &schema.EventTarget{},
+			OnDetachEvt: &schema.EventTarget{},
+		},
+	}
+	for _, pathStr := range []string{"KEK", "KDK/<consumer>"} {
+		path, _ := enc.NamePatternFromStr(pathStr)
+		leaf := node.PutNode(path, schema.LeafNodeDesc).Impl().(*schema.LeafNode)
+		leaf.ContentType = ndn.ContentTypeKey
+		leaf.MustBeFresh = false
+	}
+	return ret
+}
+
+// Grant publishes the KDK encrypted for a consumer, whose public key is given in PKIX DER.
+// The KEK is generated and published at the first grant.
+func (n *NacAccessManagerNode) Grant(mNode schema.MatchedNode, consumer string, pubKey []byte) error {
+	pub, err := parseRsaPublicKey(pubKey)
+	if err != nil {
+		return err
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if n.kdk == nil {
+		kdk, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return err
+		}
+		kek, err := x509.MarshalPKIXPublicKey(&kdk.PublicKey)
+		if err != nil {
+			return err
+		}
+		kekName := appendName(mNode.Name, enc.NewStringComponent(enc.TypeGenericNameComponent, "KEK"))
+		if !provide(mNode, kekName, enc.Wire{kek}) {
+			return errors.New("unable to publish KEK")
+		}
+		n.kdk = kdk
+	}
+
+	encKdk, err := rsaEncrypt(pub, []byte(consumer), x509.MarshalPKCS1PrivateKey(n.kdk))
+	if err != nil {
+		return err
+	}
+	kdkName := appendName(mNode.Name,
+		enc.NewStringComponent(enc.TypeGenericNameComponent, "KDK"),
+		enc.NewStringComponent(enc.TypeGenericNameComponent, consumer))
+	if !provide(mNode, kdkName, encKdk.Encode()) {
+		return errors.New("unable to publish KDK")
+	}
+	return nil
+}
+
+// NacEncrypterNode encrypts content under content keys, and publishes the content keys encrypted under the KEK.
+type NacEncrypterNode struct {
+	schema.BaseNodeImpl
+
+	// AccessManager is the name of the access manager node.
+	AccessManager enc.Name
+	// KeyLifetime is the Interest lifetime to fetch the KEK.
+	KeyLifetime time.Duration
+	// TrustAnchor is the encoded Data packet of the certificate that the signer of the KEK must chain to.
+	TrustAnchor []byte
+
+	lock      sync.Mutex
+	validator *sec.ChainValidator
+	kek       *rsa.PublicKey
+	ck        *ContentKey
+}
+
+func (n *NacEncrypterNode) NodeImplTrait() schema.NodeImpl {
+	return n
+}
+
+func (n *NacEncrypterNode) CastTo(ptr any) any {
+	switch ptr.(type) {
+	case (*NacEncrypterNode):
+		return n
+	case (*schema.BaseNodeImpl):
+		return &(n.BaseNodeImpl)
+	default:
+		return nil
+	}
+}
+
+func CreateNacEncrypterNode(node *schema.Node) schema.NodeImpl {
+	ret := &NacEncrypterNode{
+		BaseNodeImpl: schema.BaseNodeImpl{
+			Node:        node,
+			OnAttachEvt: &schema.EventTarget{},
+			OnDetachEvt: &schema.EventTarget{},
+		},
+		KeyLifetime: 4 * time.Second,
+	}
+	path, _ := enc.NamePatternFromStr("CK/<ckID>")
+	leaf := node.PutNode(path, schema.LeafNodeDesc).Impl().(*schema.LeafNode)
+	leaf.ContentType = ndn.ContentTypeKey
+	leaf.MustBeFresh = false
+	return ret
+}
+
+// Encrypt encrypts content under the content key. The KEK is fetched and the content key is published
+// at the first call.
+func (n *NacEncrypterNode) Encrypt(mNode schema.MatchedNode, content enc.Wire) (enc.Wire, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.kek == nil {
+		if n.validator == nil {
+			validator, err := keyValidator(n.Node.Engine(), n.TrustAnchor, n.KeyLifetime)
+			if err != nil {
+				return nil, err
+			}
+			n.validator = validator
+		}
+		kekName := appendName(n.AccessManager, enc.NewStringComponent(enc.TypeGenericNameComponent, "KEK"))
+		wire, err := fetchOutside(n.Node.Engine(), kekName, n.KeyLifetime, n.validator)
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch KEK %s: %w", kekName.String(), err)
+		}
+		n.kek, err = parseRsaPublicKey(wire.Join())
+		if err != nil {
+			return nil, fmt.Errorf("invalid KEK %s: %w", kekName.String(), err)
+		}
+	}
+	if n.ck == nil {
+		ck := ContentKey{ckid: make([]byte, 8), keybits: make([]byte, 32)}
+		rand.Read(ck.ckid)
+		rand.Read(ck.keybits)
+		encCk, err := rsaEncrypt(n.kek, ck.ckid, ck.keybits)
+		if err != nil {
+			return nil, err
+		}
+		ckName := appendName(mNode.Name,
+			enc.NewStringComponent(enc.TypeGenericNameComponent, "CK"),
+			enc.Component{Typ: enc.TypeGenericNameComponent, Val: ck.ckid})
+		if !provide(mNode, ckName, encCk.Encode()) {
+			return nil, errors.New("unable to publish content key")
+		}
+		n.ck = &ck
+	}
+
+	encContent, err := aesEncrypt(n.ck.ckid, n.ck.keybits, content)
+	if err != nil {
+		return nil, err
+	}
+	return encContent.Encode(), nil
+}
+
+// NacDecrypterNode decrypts content given by a NacEncrypterNode at the same path.
+type NacDecrypterNode struct {
+	schema.BaseNodeImpl
+
+	// AccessManager is the name of the access manager node.
+	AccessManager enc.Name
+	// Consumer is the name of this consumer granted by the access manager.
+	Consumer string
+	// PrivateKey is the private key of this consumer, in PKCS #1 DER.
+	PrivateKey []byte
+	// KeyLifetime is the Interest lifetime to fetch the KDK.
+	KeyLifetime time.Duration
+	// TrustAnchor is the encoded Data packet of the certificate that the signer of the KDK must chain to.
+	TrustAnchor []byte
+
+	lock sync.Mutex
+	kdk  *rsa.PrivateKey
+}
+
+func (n *NacDecrypterNode) NodeImplTrait() schema.NodeImpl {
+	return n
+}
+
+func (n *NacDecrypterNode) CastTo(ptr any) any {
+	switch ptr.(type) {
+	case (*NacDecrypterNode):
+		return n
+	case (*schema.BaseNodeImpl):
+		return &(n.BaseNodeImpl)
+	default:
+		return nil
+	}
+}
+
+func CreateNacDecrypterNode(node *schema.Node) schema.NodeImpl {
+	ret := &NacDecrypterNode{
+		BaseNodeImpl: schema.BaseNodeImpl{
+			Node:        node,
+			OnAttachEvt: &schema.EventTarget{},
+			OnDetachEvt: &schema.EventTarget{},
+		},
+		KeyLifetime: 4 * time.Second,
+	}
+	path, _ := enc.NamePatternFromStr("CK/<ckID>")
+	node.PutNode(path, schema.ExpressPointDesc).Impl().(*schema.ExpressPoint).MustBeFresh = false
+	return ret
+}
+
+// Decrypt decrypts content by fetching its content key, and the KDK of this consumer at the first call.
+// It fails if this consumer is not granted by the access manager.
+func (n *NacDecrypterNode) Decrypt(mNode schema.MatchedNode, encryptedContent enc.Wire) (enc.Wire, error) {
+	encContent, err := ParseEncryptedContent(enc.NewWireReader(encryptedContent), true)
+	if err != nil {
+		return nil, err
+	}
+	kdk, err := n.getKdk()
+	if err != nil {
+		return nil, err
+	}
+
+	ckName := appendName(mNode.Name,
+		enc.NewStringComponent(enc.TypeGenericNameComponent, "CK"),
+		enc.Component{Typ: enc.TypeGenericNameComponent, Val: encContent.KeyId})
+	ckResult := <-mNode.Refine(ckName).Call("NeedChan").(chan schema.NeedResult)
+	if ckResult.Status != ndn.InterestResultData {
+		return nil, fmt.Errorf("unable to fetch content key %s: %v", ckName.String(), ckResult.Status)
+	}
+	encCk, err := ParseEncryptedContent(enc.NewWireReader(ckResult.Content), true)
+	if err != nil {
+		return nil, err
+	}
+	ck, err := rsaDecrypt(kdk, encCk)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt content key %s: %w", ckName.String(), err)
+	}
+
+	plainText, err := aesDecrypt(ck, encContent)
+	if err != nil {
+		return nil, err
+	}
+	return enc.Wire{plainText}, nil
+}
+
+func (n *NacDecrypterNode) getKdk() (*rsa.PrivateKey, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if n.kdk != nil {
+		return n.kdk, nil
+	}
+
+	prvKey, err := x509.ParsePKCS1PrivateKey(n.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	validator, err := keyValidator(n.Node.Engine(), n.TrustAnchor, n.KeyLifetime)
+	if err != nil {
+		return nil, err
+	}
+	kdkName := appendName(n.AccessManager,
+		enc.NewStringComponent(enc.TypeGenericNameComponent, "KDK"),
+		enc.NewStringComponent(enc.TypeGenericNameComponent, n.Consumer))
+	wire, err := fetchOutside(n.Node.Engine(), kdkName, n.KeyLifetime, validator)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch KDK %s: %w", kdkName.String(), err)
+	}
+	encKdk, err := ParseEncryptedContent(enc.NewWireReader(wire), true)
+	if err != nil {
+		return nil, err
+	}
+	kdkBits, err := rsaDecrypt(prvKey, encKdk)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt KDK %s: %w", kdkName.String(), err)
+	}
+	n.kdk, err = x509.ParsePKCS1PrivateKey(kdkBits)
+	if err != nil {
+		return nil, fmt.Errorf("invalid KDK %s: %w", kdkName.String(), err)
+	}
+	return n.kdk, nil
+}
+
+func appendName(name enc.Name, comps ...enc.Component) enc.Name {
+	ret := make(enc.Name, 0, len(name)+len(comps))
+	ret = append(ret, name...)
+	return append(ret, comps...)
+}
+
+// provide publishes content at name in the subtree of mNode.
+func provide(mNode schema.MatchedNode, name enc.Name, content enc.Wire) bool {
+	wire, _ := mNode.Refine(name).Call("Provide", content).(enc.Wire)
+	return wire != nil
+}
+
+func parseRsaPublicKey(der []byte) (*rsa.PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("not an RSA public key")
+	}
+	return pub, nil
+}
+
+// rsaEncrypt encrypts plainText under an AES key, which is encrypted under pub.
+func rsaEncrypt(pub *rsa.PublicKey, keyId []byte, plainText []byte) (*EncryptedContent, error) {
+	aesKey := make([]byte, 32)
+	rand.Read(aesKey)
+	ret, err := aesEncrypt(keyId, aesKey, enc.Wire{plainText})
+	if err != nil {
+		return nil, err
+	}
+	ret.EncryptedKey, err = rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, aesKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// rsaDecrypt decrypts an EncryptedContent given by rsaEncrypt.
+func rsaDecrypt(prv *rsa.PrivateKey, encContent *EncryptedContent) ([]byte, error) {
+	aesKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, prv, encContent.EncryptedKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	return aesDecrypt(aesKey, encContent)
+}
+
+// keyValidator returns a validator of the keys fetched from outside the tree, which trusts trustAnchor.
+func keyValidator(engine ndn.Engine, trustAnchor []byte, lifetime time.Duration) (*sec.ChainValidator, error) {
+	if len(trustAnchor) == 0 {
+		return nil, errors.New("TrustAnchor is required to validate the keys of the access manager")
+	}
+	data, _, err := engine.Spec().ReadData(enc.NewBufferReader(trustAnchor))
+	if err != nil {
+		return nil, fmt.Errorf("TrustAnchor is not a valid Data packet: %w", err)
+	}
+	anchor, err := sec.ParseCertificate(data)
+	if err != nil {
+		return nil, fmt.Errorf("TrustAnchor is not a valid certificate: %w", err)
+	}
+	validator := sec.NewChainValidator([]*sec.Certificate{anchor})
+	validator.Lifetime = lifetime
+	return validator, nil
+}
+
+// fetchOutside fetches the content of a Data packet with a name not in the tree, and validates it by validator.
+func fetchOutside(
+	engine ndn.Engine, name enc.Name, lifetime time.Duration, validator *sec.ChainValidator,
+) (enc.Wire, error) {
+	intCfg := &ndn.InterestConfig{
+		Lifetime: utils.IdPtr(lifetime),
+		Nonce:    utils.ConvertNonce(engine.Timer().Nonce()),
+	}
+	wire, _, finalName, err := engine.Spec().MakeInterest(name, intCfg, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	type result struct {
+		data       ndn.Data
+		sigCovered enc.Wire
+		err        error
+	}
+	ch := make(chan result, 1)
+	err = engine.Express(finalName, intCfg, wire,
+		func(res ndn.InterestResult, data ndn.Data, _, sigCovered enc.Wire, nackReason uint64) {
+			switch res {
+			case ndn.InterestResultData:
+				ch <- result{data: data, sigCovered: sigCovered}
+			case ndn.InterestResultNack:
+				ch <- result{err: fmt.Errorf("nack received: %v", nackReason)}
+			case ndn.InterestResultTimeout:
+				ch <- result{err: ndn.ErrDeadlineExceed}
+			default:
+				ch <- result{err: fmt.Errorf("unable to fetch: %v", res)}
+			}
+		})
+	if err != nil {
+		return nil, err
+	}
+	ret := <-ch
+	if ret.err != nil {
+		return nil, ret.err
+	}
+	// Validated after the callback returns, as fetching the certificate chain blocks
+	err = validator.Validate(engine, ret.data.Name(), ret.sigCovered, ret.data.Signature())
+	if err != nil {
+		return nil, fmt.Errorf("untrusted key: %w", err)
+	}
+	return ret.data.Content(), nil
+}
+
+var (
+	NacAccessManagerNodeDesc *schema.NodeImplDesc
+	NacEncrypterNodeDesc     *schema.NodeImplDesc
+	NacDecrypterNodeDesc     *schema.NodeImplDesc
+)
+
+func init() {
+	NacAccessManagerNodeDesc = &schema.NodeImplDesc{
+		ClassName: "NacAccessManagerNode",
+		Properties: map[schema.PropKey]schema.PropertyDesc{
+			"Freshness":     schema.SubNodePropertyDesc("KEK", "Freshness"),
+			"ValidDuration": schema.SubNodePropertyDesc("KEK", "ValidDuration"),
+		},
+		Events: map[schema.PropKey]schema.EventGetter{
+			schema.PropOnAttach: schema.DefaultEventTarget(schema.PropOnAttach), // Inherited from base
+			schema.PropOnDetach: schema.DefaultEventTarget(schema.PropOnDetach), // Inherited from base
+		},
+		Functions: map[string]schema.NodeFunc{
+			"Grant": func(mNode schema.MatchedNode, args ...any) any {
+				if len(args) != 2 {
+					err := fmt.Errorf("NacAccessManagerNode.Grant requires 2 arguments but got %d", len(args))
+					mNode.Logger("NacAccessManagerNode").Error(err.Error())
+					return err
+				}
+				consumer, ok := args[0].(string)
+				if !ok {
+					err := ndn.ErrInvalidValue{Item: "consumer", Value: args[0]}
+					mNode.Logger("NacAccessManagerNode").Error(err.Error())
+					return err
+				}
+				pubKey, ok := args[1].([]byte)
+				if !ok {
+					err := ndn.ErrInvalidValue{Item: "pubKey", Value: args[1]}
+					mNode.Logger("NacAccessManagerNode").Error(err.Error())
+					return err
+				}
+				return schema.QueryInterface[*NacAccessManagerNode](mNode.Node).Grant(mNode, consumer, pubKey)
+			},
+		},
+		Create: CreateNacAccessManagerNode,
+	}
+	schema.RegisterNodeImpl(NacAccessManagerNodeDesc)
+
+	NacEncrypterNodeDesc = &schema.NodeImplDesc{
+		ClassName: "NacEncrypterNode",
+		Properties: map[schema.PropKey]schema.PropertyDesc{
+			"AccessManager": schema.NamePropertyDesc("AccessManager"),
+			"KeyLifetime":   schema.TimePropertyDesc("KeyLifetime"),
+			"TrustAnchor":   schema.DefaultPropertyDesc("TrustAnchor"),
+			"Freshness":     schema.SubNodePropertyDesc("CK/<ckID>", "Freshness"),
+			"ValidDuration": schema.SubNodePropertyDesc("CK/<ckID>", "ValidDuration"),
+		},
+		Events: map[schema.PropKey]schema.EventGetter{
+			schema.PropOnAttach: schema.DefaultEventTarget(schema.PropOnAttach), // Inherited from base
+			schema.PropOnDetach: schema.DefaultEventTarget(schema.PropOnDetach), // Inherited from base
+		},
+		Functions: map[string]schema.NodeFunc{
+			"Encrypt": func(mNode schema.MatchedNode, args ...any) any {
+				if len(args) != 1 {
+					err := fmt.Errorf("NacEncrypterNode.Encrypt requires 1 arguments but got %d", len(args))
+					mNode.Logger("NacEncrypterNode").Error(err.Error())
+					return err
+				}
+				content, ok := args[0].(enc.Wire)
+				if !ok && args[0] != nil {
+					err := ndn.ErrInvalidValue{Item: "content", Value: args[0]}
+					mNode.Logger("NacEncrypterNode").Error(err.Error())
+					return err
+				}
+				ret, err := schema.QueryInterface[*NacEncrypterNode](mNode.Node).Encrypt(mNode, content)
+				if err != nil {
+					mNode.Logger("NacEncrypterNode").Error(err.Error())
+					return err
+				}
+				return ret
+			},
+		},
+		Create: CreateNacEncrypterNode,
+	}
+	schema.RegisterNodeImpl(NacEncrypterNodeDesc)
+
+	NacDecrypterNodeDesc = &schema.NodeImplDesc{
+		ClassName: "NacDecrypterNode",
+		Properties: map[schema.PropKey]schema.PropertyDesc{
+			"AccessManager": schema.NamePropertyDesc("AccessManager"),
+			"Consumer":      schema.DefaultPropertyDesc("Consumer"),
+			"PrivateKey":    schema.DefaultPropertyDesc("PrivateKey"),
+			"KeyLifetime":   schema.TimePropertyDesc("KeyLifetime"),
+			"TrustAnchor":   schema.DefaultPropertyDesc("TrustAnchor"),
+			"Lifetime":      schema.SubNodePropertyDesc("CK/<ckID>", "Lifetime"),
+		},
+		Events: map[schema.PropKey]schema.EventGetter{
+			schema.PropOnAttach: schema.DefaultEventTarget(schema.PropOnAttach), // Inherited from base
+			schema.PropOnDetach: schema.DefaultEventTarget(schema.PropOnDetach), // Inherited from base
+		},
+		Functions: map[string]schema.NodeFunc{
+			"Decrypt": func(mNode schema.MatchedNode, args ...any) any {
+				if len(args) != 1 {
+					err := fmt.Errorf("NacDecrypterNode.Decrypt requires 1 arguments but got %d", len(args))
+					mNode.Logger("NacDecrypterNode").Error(err.Error())
+					return err
+				}
+				encryptedContent, ok := args[0].(enc.Wire)
+				if !ok && args[0] != nil {
+					err := ndn.ErrInvalidValue{Item: "encryptedContent", Value: args[0]}
+					mNode.Logger("NacDecrypterNode").Error(err.Error())
+					return err
+				}
+				ret, err := schema.QueryInterface[*NacDecrypterNode](mNode.Node).Decrypt(mNode, encryptedContent)
+				if err != nil {
+					mNode.Logger("NacDecrypterNode").Error(err.Error())
+					return err
+				}
+				return ret
+			},
+		},
+		Create: CreateNacDecrypterNode,
+	}
+	schema.RegisterNodeImpl(NacDecrypterNodeDesc)
+}
@@ -0,0 +1,248 @@
+package demosec_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	basic_engine "github.com/zjkmxy/go-ndn/pkg/engine/basic"
+	"github.com/zjkmxy/go-ndn/pkg/engine/dummy"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/schema"
+	"github.com/zjkmxy/go-ndn/pkg/schema/demosec"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+// nacHub connects engines like a broadcast link, so every party can fetch from all others.
+type nacHub struct {
+	ports []*dummy.PipeFace
+}
+
+// newFace returns a face connected to the hub.
+func (h *nacHub) newFace() *dummy.PipeFace {
+	face, port := dummy.NewPipeFaces()
+	idx := len(h.ports)
+	h.ports = append(h.ports, port)
+	port.SetCallback(func(r enc.ParseReader) error {
+		buf, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		for i, p := range h.ports {
+			if i != idx {
+				p.Send(enc.Wire{buf})
+			}
+		}
+		return nil
+	}, func(err error) error { return nil })
+	port.Open()
+	return face
+}
+
+// attach starts an engine on the hub and attaches the schema tree described by treeJson to it at prefix.
+func (h *nacHub) attach(
+	t *testing.T, prefix string, treeJson string, env map[string]any,
+) (*schema.Tree, *basic_engine.Engine) {
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool {
+		return true
+	}
+	timer := basic_engine.NewTimer()
+	engine := basic_engine.NewEngine(h.newFace(), timer, sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(t, engine.Start())
+	tree := schema.CreateFromJson(treeJson, env)
+	require.NoError(t, tree.Attach(utils.WithoutErr(enc.NameFromStr(prefix)), engine))
+	return tree, engine
+}
+
+// nacAnchor is a self-signed trust anchor, which signs the keys of the access manager.
+type nacAnchor struct {
+	keyName enc.Name
+	signer  ndn.Signer
+	cert    []byte
+}
+
+func newNacAnchor(t *testing.T) nacAnchor {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keyName := sec.MakeKeyName(utils.WithoutErr(enc.NameFromStr("/org")),
+		enc.NewStringComponent(enc.TypeGenericNameComponent, "root"))
+	signer := sec.NewEd25519Signer(keyName, priv)
+	now := time.Now()
+	cert, err := sec.EncodeCertificate(keyName, enc.NewStringComponent(enc.TypeGenericNameComponent, "self"), 1,
+		pub, now.Add(-time.Hour), now.Add(time.Hour), signer)
+	require.NoError(t, err)
+	return nacAnchor{keyName: keyName, signer: signer, cert: cert.Join()}
+}
+
+// attachAccessManager attaches an access manager at /org/am, whose keys are signed by signer.
+func (h *nacHub) attachAccessManager(t *testing.T, signer ndn.Signer) (*schema.Tree, *basic_engine.Engine) {
+	tree, engine := h.attach(t, "/org", `{
+		"nodes": {
+			"/am": {"type": "NacAccessManagerNode", "attrs": {}}
+		},
+		"policies": []
+	}`, nil)
+	kc := sec.NewKeyChain()
+	kc.AddSigner(utils.WithoutErr(enc.NameFromStr("/org/am")), signer)
+	engine.SetKeyChain(kc)
+	return tree, engine
+}
+
+// attachEncrypter attaches an encrypter at /prod/data, trusting anchor for the KEK.
+func (h *nacHub) attachEncrypter(t *testing.T, anchor nacAnchor) (*schema.Tree, *basic_engine.Engine) {
+	return h.attach(t, "/prod", `{
+		"nodes": {
+			"/data": {"type": "NacEncrypterNode", "attrs": {
+				"AccessManager": "/org/am", "KeyLifetime": 500, "TrustAnchor": "$anchor"
+			}}
+		},
+		"policies": [
+			{"type": "Sha256Signer", "path": "/data/CK/<ckID>"}
+		]
+	}`, map[string]any{"$anchor": anchor.cert})
+}
+
+func TestNacAccessControl(t *testing.T) {
+	utils.SetTestingT(t)
+
+	hub := &nacHub{}
+	anchor := newNacAnchor(t)
+	amTree, amEngine := hub.attachAccessManager(t, anchor.signer)
+	prodTree, prodEngine := hub.attachEncrypter(t, anchor)
+	// newConsumer attaches a decrypter claiming to be consumer, which holds prvKey
+	newConsumer := func(consumer string, prvKey *rsa.PrivateKey) (*schema.MatchedNode, func()) {
+		tree, engine := hub.attach(t, "/prod", `{
+			"nodes": {
+				"/data": {"type": "NacDecrypterNode", "attrs": {
+					"AccessManager": "/org/am", "Consumer": "`+consumer+`", "KeyLifetime": 500, "Lifetime": 500,
+					"TrustAnchor": "$anchor"
+				}}
+			},
+			"policies": [
+				{"type": "Sha256Signer", "path": "/data/CK/<ckID>"}
+			]
+		}`, map[string]any{"$anchor": anchor.cert})
+		path := utils.WithoutErr(enc.NamePatternFromStr("/data"))
+		require.NoError(t, tree.At(path).Set("PrivateKey", x509.MarshalPKCS1PrivateKey(prvKey)))
+		return tree.Match(utils.WithoutErr(enc.NameFromStr("/prod/data"))), func() {
+			tree.Detach()
+			require.NoError(t, engine.Shutdown())
+		}
+	}
+
+	aliceKey := utils.WithoutErr(rsa.GenerateKey(rand.Reader, 2048))
+	eveKey := utils.WithoutErr(rsa.GenerateKey(rand.Reader, 2048))
+	alicePub := utils.WithoutErr(x509.MarshalPKIXPublicKey(&aliceKey.PublicKey))
+	amNode := amTree.Match(utils.WithoutErr(enc.NameFromStr("/org/am")))
+	require.Nil(t, amNode.Call("Grant", "alice", alicePub))
+
+	prodNode := prodTree.Match(utils.WithoutErr(enc.NameFromStr("/prod/data")))
+	encContent, ok := prodNode.Call("Encrypt", enc.Wire{[]byte("secret")}).(enc.Wire)
+	require.True(t, ok)
+
+	// The granted consumer fetches its KDK and the content key, and decrypts
+	alice, closeAlice := newConsumer("alice", aliceKey)
+	defer closeAlice()
+	plainText, ok := alice.Call("Decrypt", encContent).(enc.Wire)
+	require.True(t, ok)
+	require.Equal(t, []byte("secret"), plainText.Join())
+	// The content key is published under the KEK, so later content is decrypted as well
+	encContent2 := prodNode.Call("Encrypt", enc.Wire{[]byte("more")}).(enc.Wire)
+	plainText, ok = alice.Call("Decrypt", encContent2).(enc.Wire)
+	require.True(t, ok)
+	require.Equal(t, []byte("more"), plainText.Join())
+
+	// A consumer that is not granted has no KDK to fetch
+	mallory, closeMallory := newConsumer("mallory", eveKey)
+	defer closeMallory()
+	err, ok := mallory.Call("Decrypt", encContent).(error)
+	require.True(t, ok)
+	require.ErrorContains(t, err, "unable to fetch KDK")
+
+	// A consumer using the name of a granted one cannot decrypt its KDK
+	eve, closeEve := newConsumer("alice", eveKey)
+	defer closeEve()
+	err, ok = eve.Call("Decrypt", encContent).(error)
+	require.True(t, ok)
+	require.ErrorContains(t, err, "unable to decrypt KDK")
+
+	// Malformed content is refused
+	_, ok = alice.Call("Decrypt", enc.Wire{[]byte{0x01, 0x02}}).(error)
+	require.True(t, ok)
+
+	prodTree.Detach()
+	amTree.Detach()
+	require.NoError(t, prodEngine.Shutdown())
+	require.NoError(t, amEngine.Shutdown())
+}
+
+func TestNacForgedKeys(t *testing.T) {
+	utils.SetTestingT(t)
+
+	// The access manager signs its keys with a key named as the anchor key, but not the anchor key itself
+	hub := &nacHub{}
+	anchor := newNacAnchor(t)
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	amTree, amEngine := hub.attachAccessManager(t, sec.NewEd25519Signer(anchor.keyName, priv))
+	prodTree, prodEngine := hub.attachEncrypter(t, anchor)
+
+	consumerKey := utils.WithoutErr(rsa.GenerateKey(rand.Reader, 2048))
+	consumerPub := utils.WithoutErr(x509.MarshalPKIXPublicKey(&consumerKey.PublicKey))
+	amNode := amTree.Match(utils.WithoutErr(enc.NameFromStr("/org/am")))
+	require.Nil(t, amNode.Call("Grant", "alice", consumerPub))
+
+	// The forged KEK is refused, so no content key is wrapped to it
+	prodNode := prodTree.Match(utils.WithoutErr(enc.NameFromStr("/prod/data")))
+	err, ok := prodNode.Call("Encrypt", enc.Wire{[]byte("secret")}).(error)
+	require.True(t, ok)
+	require.ErrorContains(t, err, "unable to fetch KEK")
+	require.ErrorContains(t, err, "untrusted key")
+
+	// So is the forged KDK
+	tree, engine := hub.attach(t, "/prod", `{
+		"nodes": {
+			"/data": {"type": "NacDecrypterNode", "attrs": {
+				"AccessManager": "/org/am", "Consumer": "alice", "KeyLifetime": 500, "TrustAnchor": "$anchor"
+			}}
+		},
+		"policies": []
+	}`, map[string]any{"$anchor": anchor.cert})
+	path := utils.WithoutErr(enc.NamePatternFromStr("/data"))
+	require.NoError(t, tree.At(path).Set("PrivateKey", x509.MarshalPKCS1PrivateKey(consumerKey)))
+	encContent := (&demosec.EncryptedContent{
+		KeyId:      []byte("ck"),
+		Iv:         make([]byte, 16),
+		CipherText: enc.Wire{make([]byte, 16)},
+	}).Encode()
+	err, ok = tree.Match(utils.WithoutErr(enc.NameFromStr("/prod/data"))).Call("Decrypt", encContent).(error)
+	require.True(t, ok)
+	require.ErrorContains(t, err, "unable to fetch KDK")
+	require.ErrorContains(t, err, "untrusted key")
+
+	// Keys are not used without an anchor to validate them
+	noAnchorTree, noAnchorEngine := hub.attach(t, "/noanchor", `{
+		"nodes": {
+			"/data": {"type": "NacEncrypterNode", "attrs": {"AccessManager": "/org/am"}}
+		},
+		"policies": []
+	}`, nil)
+	err, ok = noAnchorTree.Match(utils.WithoutErr(enc.NameFromStr("/noanchor/data"))).
+		Call("Encrypt", enc.Wire{[]byte("secret")}).(error)
+	require.True(t, ok)
+	require.ErrorContains(t, err, "TrustAnchor is required")
+
+	for _, tr := range []*schema.Tree{noAnchorTree, tree, prodTree, amTree} {
+		tr.Detach()
+	}
+	for _, e := range []*basic_engine.Engine{noAnchorEngine, engine, prodEngine, amEngine} {
+		require.NoError(t, e.Shutdown())
+	}
+}
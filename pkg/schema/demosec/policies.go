@@ -21,6 +21,10 @@ func (p *KeyStoragePolicy) PolicyTrait() schema.Policy {
 	return p
 }
 
+func (p *KeyStoragePolicy) SubtreeTrait() schema.SubtreePolicy {
+	return p
+}
+
 func (p *KeyStoragePolicy) onSearch(event *schema.Event) any {
 	p.lock.RLock()
 	defer p.lock.RUnlock()
@@ -169,15 +173,20 @@ func (p *SignedByPolicy) Apply(node *schema.Node) {
 	if evt != nil {
 		evt.Add(utils.IdPtr(p.onGetDataSigner))
 	}
-	// PropOnValidateData must exist. Otherwise it is at an invalid path.
+	// PropOnValidateData must exist, unless the descendants inherit the policy.
+	// Otherwise it is at an invalid path.
 	evt = node.GetEvent(schema.PropOnValidateData)
 	if evt != nil {
 		evt.Add(utils.IdPtr(p.onValidateData))
-	} else {
+	} else if len(node.Children()) == 0 {
 		panic("attaching SignedByPolicy to a node that does not need to validate Data. What is the use?")
 	}
 }
 
+func (p *SignedByPolicy) SignerTrait() schema.SignerPolicy {
+	return p
+}
+
 func NewSignedByPolicy() schema.Policy {
 	return &SignedByPolicy{}
 }
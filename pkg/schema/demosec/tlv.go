@@ -13,4 +13,7 @@ type EncryptedContent struct {
 	ContentLength uint64 `tlv:"0x86"`
 	//+field:wire
 	CipherText enc.Wire `tlv:"0x88"`
+	// EncryptedKey is the AES key encrypted under a public key, if the content is encrypted for that public key.
+	//+field:binary
+	EncryptedKey []byte `tlv:"0x8a"`
 }
@@ -86,6 +86,21 @@ func (encoder *EncryptedContentEncoder) Init(value *EncryptedContent) {
 		l += encoder.CipherText_length
 	}
 
+	if value.EncryptedKey != nil {
+		l += 1
+		switch x := len(value.EncryptedKey); {
+		case x <= 0xfc:
+			l += 1
+		case x <= 0xffff:
+			l += 3
+		case x <= 0xffffffff:
+			l += 5
+		default:
+			l += 9
+		}
+		l += uint(len(value.EncryptedKey))
+	}
+
 	encoder.length = l
 
 	wirePlan := make([]uint, 0)
@@ -152,6 +167,21 @@ func (encoder *EncryptedContentEncoder) Init(value *EncryptedContent) {
 		}
 	}
 
+	if value.EncryptedKey != nil {
+		l += 1
+		switch x := len(value.EncryptedKey); {
+		case x <= 0xfc:
+			l += 1
+		case x <= 0xffff:
+			l += 3
+		case x <= 0xffffffff:
+			l += 5
+		default:
+			l += 9
+		}
+		l += uint(len(value.EncryptedKey))
+	}
+
 	if l > 0 {
 		wirePlan = append(wirePlan, l)
 	}
@@ -276,6 +306,30 @@ func (encoder *EncryptedContentEncoder) EncodeInto(value *EncryptedContent, wire
 		}
 	}
 
+	if value.EncryptedKey != nil {
+		buf[pos] = byte(138)
+		pos += 1
+		switch x := len(value.EncryptedKey); {
+		case x <= 0xfc:
+			buf[pos] = byte(x)
+			pos += 1
+		case x <= 0xffff:
+			buf[pos] = 0xfd
+			binary.BigEndian.PutUint16(buf[pos+1:], uint16(x))
+			pos += 3
+		case x <= 0xffffffff:
+			buf[pos] = 0xfe
+			binary.BigEndian.PutUint32(buf[pos+1:], uint32(x))
+			pos += 5
+		default:
+			buf[pos] = 0xff
+			binary.BigEndian.PutUint64(buf[pos+1:], uint64(x))
+			pos += 9
+		}
+		copy(buf[pos:], value.EncryptedKey)
+		pos += uint(len(value.EncryptedKey))
+	}
+
 }
 
 func (encoder *EncryptedContentEncoder) Encode(value *EncryptedContent) enc.Wire {
@@ -354,6 +408,13 @@ func (context *EncryptedContentParsingContext) Parse(reader enc.ParseReader, ign
 					handled = true
 					value.CipherText, err = reader.ReadWire(int(l))
 
+				}
+			case 138:
+				if progress+1 == 4 {
+					handled = true
+					value.EncryptedKey = make([]byte, l)
+					_, err = io.ReadFull(reader, value.EncryptedKey)
+
 				}
 			default:
 				handled = true
@@ -361,6 +422,8 @@ func (context *EncryptedContentParsingContext) Parse(reader enc.ParseReader, ign
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -372,6 +435,8 @@ func (context *EncryptedContentParsingContext) Parse(reader enc.ParseReader, ign
 					err = enc.ErrSkipRequired{Name: "ContentLength", TypeNum: 134}
 				case 3 - 1:
 					value.CipherText = nil
+				case 4 - 1:
+					value.EncryptedKey = nil
 				}
 			}
 			if err != nil {
@@ -380,7 +445,7 @@ func (context *EncryptedContentParsingContext) Parse(reader enc.ParseReader, ign
 		}
 	}
 	startPos = reader.Pos()
-	for ; progress < 4; progress++ {
+	for ; progress < 5; progress++ {
 		switch progress {
 		case 0 - 1:
 			value.KeyId = nil
@@ -390,6 +455,8 @@ func (context *EncryptedContentParsingContext) Parse(reader enc.ParseReader, ign
 			err = enc.ErrSkipRequired{Name: "ContentLength", TypeNum: 134}
 		case 3 - 1:
 			value.CipherText = nil
+		case 4 - 1:
+			value.EncryptedKey = nil
 		}
 	}
 	if err != nil {
@@ -0,0 +1,196 @@
+package schema
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/log"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+const diskStorageSchema = `CREATE TABLE IF NOT EXISTS data (
+	name BLOB PRIMARY KEY,
+	wire BLOB NOT NULL,
+	validity INTEGER NOT NULL,
+	size INTEGER NOT NULL
+)`
+
+// DiskStoragePolicy is a policy that stores data in a file, so they survive restarts.
+// It is a drop-in replacement of MemStoragePolicy, and applies to all children in a subtree.
+// Data are keyed by their names. When the total size of wires exceeds MaxSize,
+// the earliest inserted Data are removed.
+type DiskStoragePolicy struct {
+	// FilePath is the path to the database file. It is created if not existing.
+	FilePath string
+	// MaxSize is the maximum total size of stored Data in bytes. 0 means unlimited.
+	MaxSize uint64
+
+	timer ndn.Timer
+	lock  sync.Mutex
+	db    *sql.DB
+	// refs is the number of attached nodes using the database
+	refs int
+}
+
+func (p *DiskStoragePolicy) PolicyTrait() Policy {
+	return p
+}
+
+func (p *DiskStoragePolicy) SubtreeTrait() SubtreePolicy {
+	return p
+}
+
+// nameKey is the TLV-VALUE of name, so the key of a prefix is a prefix of the key.
+func nameKey(name enc.Name) []byte {
+	buf := make([]byte, name.EncodingLength())
+	name.EncodeInto(buf)
+	return buf
+}
+
+func (p *DiskStoragePolicy) now() time.Time {
+	if p.timer != nil {
+		return p.timer.Now()
+	}
+	return time.Time{}
+}
+
+func (p *DiskStoragePolicy) Get(name enc.Name, canBePrefix bool, mustBeFresh bool) enc.Wire {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.db == nil {
+		return nil
+	}
+	key := nameKey(name)
+	minValidity := int64(-1 << 63)
+	if mustBeFresh {
+		minValidity = p.now().UnixNano()
+	}
+	var wire []byte
+	var err error
+	if canBePrefix {
+		err = p.db.QueryRow(
+			"SELECT wire FROM data WHERE substr(name, 1, ?)=? AND validity>? ORDER BY name LIMIT 1",
+			len(key), key, minValidity,
+		).Scan(&wire)
+	} else {
+		err = p.db.QueryRow("SELECT wire FROM data WHERE name=? AND validity>?", key, minValidity).Scan(&wire)
+	}
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.WithField("module", "DiskStorage").Errorf("Unable to search %s: %+v", name.String(), err)
+		}
+		return nil
+	}
+	return enc.Wire{wire}
+}
+
+func (p *DiskStoragePolicy) Put(name enc.Name, rawData enc.Wire, validity time.Time) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.db == nil {
+		return
+	}
+	logger := log.WithField("module", "DiskStorage").WithField("name", name.String())
+	wire := rawData.Join()
+	// REPLACE deletes the old row, so the Data is counted as newly inserted
+	_, err := p.db.Exec("INSERT OR REPLACE INTO data (name, wire, validity, size) VALUES (?, ?, ?, ?)",
+		nameKey(name), wire, validity.UnixNano(), len(wire))
+	if err != nil {
+		logger.Errorf("Unable to save Data: %+v", err)
+		return
+	}
+	if p.MaxSize == 0 {
+		return
+	}
+	for {
+		var total uint64
+		if err = p.db.QueryRow("SELECT COALESCE(SUM(size), 0) FROM data").Scan(&total); err != nil {
+			logger.Errorf("Unable to compute the storage size: %+v", err)
+			return
+		}
+		if total <= p.MaxSize {
+			return
+		}
+		_, err = p.db.Exec("DELETE FROM data WHERE rowid=(SELECT MIN(rowid) FROM data)")
+		if err != nil {
+			logger.Errorf("Unable to evict Data: %+v", err)
+			return
+		}
+	}
+}
+
+func (p *DiskStoragePolicy) onAttach(event *Event) any {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.timer = event.TargetNode.Engine().Timer()
+	p.refs++
+	if p.db != nil {
+		return nil
+	}
+	db, err := sql.Open("sqlite3", p.FilePath)
+	if err == nil {
+		_, err = db.Exec(diskStorageSchema)
+	}
+	if err != nil {
+		panic(ndn.ErrInvalidValue{Item: "FilePath", Value: p.FilePath})
+	}
+	p.db = db
+	return nil
+}
+
+func (p *DiskStoragePolicy) onDetach(event *Event) any {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// The database is closed when the last node is detached
+	p.refs--
+	if p.refs <= 0 && p.db != nil {
+		p.db.Close()
+		p.db = nil
+		p.refs = 0
+	}
+	return nil
+}
+
+func (p *DiskStoragePolicy) onSearch(event *Event) any {
+	// event.IntConfig is always valid for onSearch, no matter if there is an Interest.
+	return p.Get(event.Target.Name, event.IntConfig.CanBePrefix, event.IntConfig.MustBeFresh)
+}
+
+func (p *DiskStoragePolicy) onSave(event *Event) any {
+	validity := p.now().Add(*event.ValidDuration)
+	p.Put(event.Target.Name, event.RawPacket, validity)
+	return nil
+}
+
+func (p *DiskStoragePolicy) Apply(node *Node) {
+	if len(p.FilePath) == 0 {
+		panic("DiskStoragePolicy requires FilePath to present before apply.")
+	}
+	if event := node.GetEvent(PropOnAttach); event != nil {
+		event.Add(utils.IdPtr(p.onAttach))
+	}
+	if event := node.GetEvent(PropOnDetach); event != nil {
+		event.Add(utils.IdPtr(p.onDetach))
+	}
+	if event := node.GetEvent(PropOnSearchStorage); event != nil {
+		event.Add(utils.IdPtr(p.onSearch))
+	}
+	if event := node.GetEvent(PropOnSaveStorage); event != nil {
+		event.Add(utils.IdPtr(p.onSave))
+	}
+	for _, c := range node.Children() {
+		p.Apply(c)
+	}
+}
+
+func NewDiskStoragePolicy() Policy {
+	return &DiskStoragePolicy{}
+}
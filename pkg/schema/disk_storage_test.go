@@ -0,0 +1,80 @@
+package schema_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/schema"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+func diskStorageTreeJson(path string, maxSize int) string {
+	return fmt.Sprintf(`{
+		"nodes": {
+			"/obj/<v=time>": {"type": "LeafNode", "attrs": {}}
+		},
+		"policies": [
+			{"type": "Sha256Signer", "path": "/"},
+			{"type": "DiskStorage", "path": "/", "attrs": {"FilePath": %q, "MaxSize": %d}}
+		]
+	}`, path, maxSize)
+}
+
+func TestDiskStorageRestart(t *testing.T) {
+	treeJson := diskStorageTreeJson(filepath.Join(t.TempDir(), "data.db"), 0)
+	var wire1, wire2 enc.Buffer
+	executeSchemaTest(t, treeJson, func(env *schemaTestEnv) {
+		mNode := env.tree.Match(utils.WithoutErr(enc.NameFromStr("/p/obj/v=1")))
+		wire1 = mNode.Call("Provide", enc.Wire{[]byte("hello")}).(enc.Wire).Join()
+		mNode = env.tree.Match(utils.WithoutErr(enc.NameFromStr("/p/obj/v=2")))
+		wire2 = mNode.Call("Provide", enc.Wire{[]byte("world")}).(enc.Wire).Join()
+	})
+
+	// A fresh tree on the same file serves the Data saved before
+	executeSchemaTest(t, treeJson, func(env *schemaTestEnv) {
+		require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/obj/v=1", nil)))
+		require.Equal(t, wire1, utils.WithoutErr(env.face.Consume()))
+		require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/obj/v=2", nil)))
+		require.Equal(t, wire2, utils.WithoutErr(env.face.Consume()))
+		require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/obj", &ndn.InterestConfig{
+			CanBePrefix: true,
+			Lifetime:    utils.IdPtr(4 * time.Second),
+		})))
+		require.Equal(t, wire1, utils.WithoutErr(env.face.Consume()))
+
+		// Need finds the Data in the storage without expressing an Interest
+		mNode := env.tree.Match(utils.WithoutErr(enc.NameFromStr("/p/obj/v=2")))
+		result := <-mNode.Call("NeedChan").(chan schema.NeedResult)
+		require.Equal(t, ndn.InterestResultData, result.Status)
+		require.Equal(t, []byte("world"), result.Content.Join())
+	})
+}
+
+func TestDiskStorageMaxSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.db")
+	content := enc.Wire{[]byte(strings.Repeat("x", 100))}
+	var wire3 enc.Buffer
+	// Every Data is larger than 100 bytes, so only one fits
+	executeSchemaTest(t, diskStorageTreeJson(path, 200), func(env *schemaTestEnv) {
+		for i := 1; i <= 3; i++ {
+			mNode := env.tree.Match(utils.WithoutErr(enc.NameFromStr(fmt.Sprintf("/p/obj/v=%d", i))))
+			wire3 = mNode.Call("Provide", content).(enc.Wire).Join()
+		}
+	})
+
+	executeSchemaTest(t, diskStorageTreeJson(path, 200), func(env *schemaTestEnv) {
+		for i := 1; i <= 2; i++ {
+			require.NoError(t, env.face.FeedPacket(makeInterest(t, env, fmt.Sprintf("/p/obj/v=%d", i), nil)))
+			_, err := env.face.Consume()
+			require.Error(t, err)
+		}
+		require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/obj/v=3", nil)))
+		require.Equal(t, wire3, utils.WithoutErr(env.face.Consume()))
+	})
+}
@@ -1,6 +1,7 @@
 package schema
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -34,16 +35,21 @@ type ExpressPoint struct {
 	BaseNodeImpl
 
 	OnInt           *EventTarget
+	OnNack          *EventTarget
+	OnData          *EventTarget
+	OnTimeout       *EventTarget
 	OnValidateInt   *EventTarget
 	OnValidateData  *EventTarget
 	OnSearchStorage *EventTarget
 	OnSaveStorage   *EventTarget
 	OnGetIntSigner  *EventTarget
 
-	CanBePrefix bool
-	MustBeFresh bool
-	Lifetime    time.Duration
-	SupressInt  bool
+	CanBePrefix    bool
+	MustBeFresh    bool
+	Lifetime       time.Duration
+	Retries        int
+	SupressInt     bool
+	ForwardingHint []enc.Name
 }
 
 func (n *ExpressPoint) NodeImplTrait() NodeImpl {
@@ -165,6 +171,14 @@ func (n *ExpressPoint) OnInterest(
 func (n *ExpressPoint) NeedCallback(
 	mNode MatchedNode, callback Callback, appParam enc.Wire, intConfig *ndn.InterestConfig, supress bool,
 ) error {
+	_, err := n.need(mNode, callback, appParam, intConfig, supress)
+	return err
+}
+
+// need is NeedCallback returning the token of the Interest expressed, or nil if none is expressed.
+func (n *ExpressPoint) need(
+	mNode MatchedNode, callback Callback, appParam enc.Wire, intConfig *ndn.InterestConfig, supress bool,
+) (ndn.InterestToken, error) {
 	if mNode.Node != n.Node {
 		panic("NTSchema tree compromised.")
 	}
@@ -177,10 +191,11 @@ func (n *ExpressPoint) NeedCallback(
 		intConfig = &ndn.InterestConfig{
 			CanBePrefix:    n.CanBePrefix,
 			MustBeFresh:    n.MustBeFresh,
-			Lifetime:       utils.IdPtr(n.Lifetime),
+			Lifetime:       utils.IdPtr(n.interestLifetime()),
 			Nonce:          utils.ConvertNonce(engine.Timer().Nonce()),
 			HopLimit:       nil,
-			ForwardingHint: nil,
+			ForwardingHint: n.ForwardingHint,
+			Retries:        n.Retries,
 		}
 	}
 	event := &Event{
@@ -216,11 +231,14 @@ func (n *ExpressPoint) NeedCallback(
 					NeedStatus:   utils.IdPtr(ndn.InterestResultData),
 					SelfProduced: utils.IdPtr(true),
 				}
-				go callback(cbEvt)
+				go func() {
+					n.OnData.Dispatch(cbEvt)
+					callback(cbEvt)
+				}()
 				// ret <- NeedResult{ndn.InterestResultData, data.Content(), data, cachedData, VrCachedData}
 				// close(ret)
 				// return ret
-				return nil
+				return nil, nil
 			} else {
 				logger.Error("The storage returned an invalid data")
 			}
@@ -236,7 +254,7 @@ func (n *ExpressPoint) NeedCallback(
 			TargetNode: node,
 			NeedStatus: utils.IdPtr(ndn.InterestResultNone),
 		})
-		return errors.New("unable to construct Interest")
+		return nil, errors.New("unable to construct Interest")
 	}
 
 	// We may search the storage if not yet
@@ -248,7 +266,7 @@ func (n *ExpressPoint) NeedCallback(
 			TargetNode: node,
 			NeedStatus: utils.IdPtr(ndn.InterestResultNack),
 		})
-		return nil
+		return nil, nil
 	}
 
 	// Set the deadline
@@ -269,13 +287,25 @@ func (n *ExpressPoint) NeedCallback(
 	// Express the Interest
 	// Note that this function runs on a different go routine than the callback.
 	// To avoid clogging the engine, the callback needs to return ASAP, so an inner goroutine is created.
-	err = engine.Express(finalName, intConfig, wire,
+	token, err := engine.ExpressCancelable(finalName, intConfig, wire,
 		func(result ndn.InterestResult, data ndn.Data, rawData, sigCovered enc.Wire, nackReason uint64) {
 			if result != ndn.InterestResultData {
+				cbEvt.NeedStatus = utils.IdPtr(result)
 				if result == ndn.InterestResultNack {
 					cbEvt.NackReason = &nackReason
+					go func() {
+						n.OnNack.Dispatch(cbEvt)
+						callback(cbEvt)
+					}()
+					return
+				}
+				if result == ndn.InterestResultTimeout {
+					go func() {
+						n.OnTimeout.Dispatch(cbEvt)
+						callback(cbEvt)
+					}()
+					return
 				}
-				cbEvt.NeedStatus = utils.IdPtr(result)
 				go callback(cbEvt)
 				return
 			}
@@ -321,6 +351,7 @@ func (n *ExpressPoint) NeedCallback(
 				// Save (cache) the data in the storage
 				cbEvt.ValidDuration = data.Freshness()
 				n.OnSaveStorage.Dispatch(cbEvt)
+				n.OnData.Dispatch(cbEvt)
 
 				// Return the result
 				callback(cbEvt)
@@ -333,13 +364,21 @@ func (n *ExpressPoint) NeedCallback(
 			NeedStatus: utils.IdPtr(ndn.InterestResultNone),
 		})
 	}
-	return err
+	return token, err
 }
 
 // NeedChan is the channel version of Need()
 func (n *ExpressPoint) NeedChan(
 	mNode MatchedNode, appParam enc.Wire, intConfig *ndn.InterestConfig, supress bool,
 ) chan NeedResult {
+	ret, _ := n.needChan(mNode, appParam, intConfig, supress)
+	return ret
+}
+
+// needChan is NeedChan also returning the token of the Interest expressed, or nil if none is expressed.
+func (n *ExpressPoint) needChan(
+	mNode MatchedNode, appParam enc.Wire, intConfig *ndn.InterestConfig, supress bool,
+) (chan NeedResult, ndn.InterestToken) {
 	ret := make(chan NeedResult, 1)
 	callback := func(event *Event) any {
 		result := NeedResult{
@@ -353,8 +392,89 @@ func (n *ExpressPoint) NeedChan(
 		close(ret)
 		return nil
 	}
-	n.NeedCallback(mNode, callback, appParam, intConfig, supress)
-	return ret
+	token, _ := n.need(mNode, callback, appParam, intConfig, supress)
+	return ret, token
+}
+
+// NeedCtx is the blocking version of Need().
+// It returns the content of the fetched Data, or an error if the Data is not obtained or ctx is done first.
+func (n *ExpressPoint) NeedCtx(
+	ctx context.Context, mNode MatchedNode, appParam enc.Wire, intConfig *ndn.InterestConfig,
+) (enc.Wire, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ch, token := n.needChan(mNode, appParam, intConfig, false)
+	select {
+	case <-ctx.Done():
+		// The pending Interest is removed from the PIT. A result already coming is dropped into the buffered ch
+		if token != nil {
+			token.Cancel()
+		}
+		return nil, ctx.Err()
+	case result := <-ch:
+		if result.Status != ndn.InterestResultData {
+			return nil, ErrNeedFailed{Status: result.Status, NackReason: result.NackReason}
+		}
+		return result.Content, nil
+	}
+}
+
+// Need fetches the Data named by mNode with the default Interest config of the node, and returns its content.
+// The node must be an ExpressPoint or a LeafNode.
+func (mNode MatchedNode) Need(ctx context.Context) (enc.Wire, error) {
+	n := QueryInterface[*ExpressPoint](mNode.Node)
+	if n == nil {
+		return nil, ndn.ErrNotSupported{Item: mNode.Node.desc.ClassName + ".Need"}
+	}
+	return n.NeedCtx(ctx, mNode, nil, nil)
+}
+
+// ErrNeedFailed is returned by NeedCtx when no verified Data is obtained.
+type ErrNeedFailed struct {
+	Status     ndn.InterestResult
+	NackReason *uint64
+}
+
+func (e ErrNeedFailed) Error() string {
+	switch e.Status {
+	case ndn.InterestResultNack:
+		if e.NackReason != nil {
+			return fmt.Sprintf("Interest is nacked with reason %d", *e.NackReason)
+		}
+		return "Interest is nacked"
+	case ndn.InterestResultTimeout:
+		return "Interest timed out"
+	case ndn.InterestCancelled:
+		return "Interest is cancelled"
+	case ndn.InterestResultUnverified:
+		return "Data cannot be verified"
+	default:
+		return fmt.Sprintf("Need failed with result %d", e.Status)
+	}
+}
+
+// interestLifetime returns Lifetime, or the default lifetime of the engine if it is not set.
+// Engines without a configurable default take 4 seconds, the default of the forwarders.
+func (n *ExpressPoint) interestLifetime() time.Duration {
+	if n.Lifetime > 0 {
+		return n.Lifetime
+	}
+	if e, ok := n.Node.engine.(interface{ DefaultInterestLifetime() time.Duration }); ok {
+		return e.DefaultInterestLifetime()
+	}
+	return 4 * time.Second
+}
+
+// validateAttrs checks the ranges of the attributes of Interests.
+func (n *ExpressPoint) validateAttrs() error {
+	if n.Lifetime < 0 {
+		return ndn.ErrInvalidValue{Item: string(PropLifetime), Value: n.Lifetime}
+	}
+	if n.Retries < 0 {
+		return ndn.ErrInvalidValue{Item: string(PropRetries), Value: n.Retries}
+	}
+	return nil
 }
 
 func CreateExpressPoint(node *Node) NodeImpl {
@@ -366,9 +486,12 @@ func CreateExpressPoint(node *Node) NodeImpl {
 		},
 		CanBePrefix:     true,
 		MustBeFresh:     true,
-		Lifetime:        4 * time.Second,
+		Lifetime:        0,
 		SupressInt:      false,
 		OnInt:           &EventTarget{},
+		OnNack:          &EventTarget{},
+		OnData:          &EventTarget{},
+		OnTimeout:       &EventTarget{},
 		OnValidateInt:   &EventTarget{},
 		OnValidateData:  &EventTarget{},
 		OnSearchStorage: &EventTarget{},
@@ -383,15 +506,20 @@ func initExpressPointDesc() {
 	ExpressPointDesc = &NodeImplDesc{
 		ClassName: "ExpressPoint",
 		Properties: map[PropKey]PropertyDesc{
-			PropCanBePrefix: DefaultPropertyDesc(PropCanBePrefix),
-			PropMustBeFresh: DefaultPropertyDesc(PropMustBeFresh),
-			PropLifetime:    TimePropertyDesc(PropLifetime),
-			PropSuppressInt: DefaultPropertyDesc(PropSuppressInt),
+			PropCanBePrefix:    DefaultPropertyDesc(PropCanBePrefix),
+			PropMustBeFresh:    DefaultPropertyDesc(PropMustBeFresh),
+			PropLifetime:       TimePropertyDesc(PropLifetime),
+			PropRetries:        DefaultPropertyDesc(PropRetries),
+			PropSuppressInt:    DefaultPropertyDesc(PropSuppressInt),
+			PropForwardingHint: NamesPropertyDesc(PropForwardingHint),
 		},
 		Events: map[PropKey]EventGetter{
 			PropOnAttach:        DefaultEventTarget(PropOnAttach),   // Inherited from base
 			PropOnDetach:        DefaultEventTarget(PropOnDetach),   // Inherited from base
 			"OnInterest":        DefaultEventTarget(PropOnInterest), // This has a name conflict problem
+			PropOnNack:          DefaultEventTarget(PropOnNack),
+			PropOnData:          DefaultEventTarget(PropOnData),
+			PropOnTimeout:       DefaultEventTarget(PropOnTimeout),
 			PropOnValidateInt:   DefaultEventTarget(PropOnValidateInt),
 			PropOnValidateData:  DefaultEventTarget(PropOnValidateData),
 			PropOnSearchStorage: DefaultEventTarget(PropOnSearchStorage),
@@ -477,7 +605,8 @@ func initExpressPointDesc() {
 				return QueryInterface[*ExpressPoint](mNode.Node).NeedChan(mNode, appParam, intConfig, supress)
 			},
 		},
-		Create: CreateExpressPoint,
+		Create:   CreateExpressPoint,
+		Validate: func(owner any) error { return owner.(*ExpressPoint).validateAttrs() },
 	}
 	RegisterNodeImpl(ExpressPointDesc)
 }
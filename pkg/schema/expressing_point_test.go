@@ -0,0 +1,219 @@
+package schema_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	basic_engine "github.com/zjkmxy/go-ndn/pkg/engine/basic"
+	"github.com/zjkmxy/go-ndn/pkg/engine/dummy"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/ndn/spec_2022"
+	"github.com/zjkmxy/go-ndn/pkg/schema"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+// consumedInterest returns the Interest expressed by the engine.
+func consumedInterest(t *testing.T, env *schemaTestEnv) *spec_2022.Interest {
+	pkt, _, err := spec_2022.ReadPacket(enc.NewBufferReader(utils.WithoutErr(env.face.Consume())))
+	require.NoError(t, err)
+	require.NotNil(t, pkt.Interest)
+	return pkt.Interest
+}
+
+// nackInterest feeds a Nack of interest with reason to the engine.
+func nackInterest(t *testing.T, env *schemaTestEnv, interest *spec_2022.Interest, reason uint64) {
+	wire, _, _, err := spec_2022.Spec{}.MakeInterest(interest.NameV, &ndn.InterestConfig{
+		CanBePrefix: interest.CanBePrefix(),
+		MustBeFresh: interest.MustBeFresh(),
+		Nonce:       interest.Nonce(),
+		Lifetime:    interest.Lifetime(),
+	}, nil, nil)
+	require.NoError(t, err)
+	lpPkt := &spec_2022.Packet{
+		LpPacket: &spec_2022.LpPacket{
+			Nack:     &spec_2022.NetworkNack{Reason: reason},
+			Fragment: wire,
+		},
+	}
+	encoder := spec_2022.PacketEncoder{}
+	encoder.Init(lpPkt)
+	require.NoError(t, env.face.FeedPacket(encoder.Encode(lpPkt).Join()))
+}
+
+func TestExpressPointResultEvents(t *testing.T) {
+	const treeJson = `{
+		"nodes": {
+			"/obj/<v=time>": {
+				"type": "LeafNode",
+				"attrs": {"Lifetime": 1000, "MustBeFresh": false},
+				"events": {"OnData": ["$onData"], "OnNack": ["$onNack"], "OnTimeout": ["$onTimeout"]}
+			}
+		},
+		"policies": [
+			{"type": "Sha256Signer", "path": "/"}
+		]
+	}`
+	// The events run before the result of Need is delivered, so they are received in order
+	fired := make(chan string, 8)
+	logEvent := func(kind string) schema.Callback {
+		return func(event *schema.Event) any {
+			switch kind {
+			case "OnData":
+				fired <- kind + " " + event.Target.Name.String() + " " + string(event.Content.Join())
+			case "OnNack":
+				fired <- fmt.Sprintf("%s %s %d", kind, event.Target.Name.String(), *event.NackReason)
+			default:
+				fired <- kind + " " + event.Target.Name.String()
+			}
+			return nil
+		}
+	}
+	environment := map[string]any{
+		"$onData":    logEvent("OnData"),
+		"$onNack":    logEvent("OnNack"),
+		"$onTimeout": logEvent("OnTimeout"),
+	}
+	executeSchemaTestEnv(t, treeJson, environment, func(env *schemaTestEnv) {
+		need := func(name string) chan schema.NeedResult {
+			return env.tree.Match(utils.WithoutErr(enc.NameFromStr(name))).Call("NeedChan").(chan schema.NeedResult)
+		}
+		requireFired := func(expected string) {
+			select {
+			case e := <-fired:
+				require.Equal(t, expected, e)
+			default:
+				require.Fail(t, "no event is fired", expected)
+			}
+			require.Empty(t, fired)
+		}
+
+		// OnData
+		ch := need("/p/obj/v=1")
+		interest := consumedInterest(t, env)
+		data, _, err := spec_2022.Spec{}.MakeData(interest.NameV, &ndn.DataConfig{
+			ContentType: utils.IdPtr(ndn.ContentTypeBlob),
+		}, enc.Wire{[]byte("hello")}, sec.NewSha256Signer())
+		require.NoError(t, err)
+		require.NoError(t, env.face.FeedPacket(data.Join()))
+		require.Equal(t, ndn.InterestResultData, (<-ch).Status)
+		requireFired("OnData /p/obj/v=1 hello")
+
+		// OnNack
+		ch = need("/p/obj/v=2")
+		nackInterest(t, env, consumedInterest(t, env), spec_2022.NackReasonNoRoute)
+		require.Equal(t, ndn.InterestResultNack, (<-ch).Status)
+		requireFired(fmt.Sprintf("OnNack /p/obj/v=2 %d", spec_2022.NackReasonNoRoute))
+
+		// OnTimeout
+		ch = need("/p/obj/v=3")
+		consumedInterest(t, env)
+		env.timer.MoveForward(1500 * time.Millisecond)
+		require.Equal(t, ndn.InterestResultTimeout, (<-ch).Status)
+		requireFired("OnTimeout /p/obj/v=3")
+
+		// A Data failing validation fires none of them
+		ch = need("/p/obj/v=4")
+		interest = consumedInterest(t, env)
+		data, _, err = spec_2022.Spec{}.MakeData(interest.NameV, &ndn.DataConfig{
+			ContentType: utils.IdPtr(ndn.ContentTypeBlob),
+		}, enc.Wire{[]byte("hello")}, sec.NewHmacSigner(utils.WithoutErr(enc.NameFromStr("/key")), []byte("k"), false, 0))
+		require.NoError(t, err)
+		require.NoError(t, env.face.FeedPacket(data.Join()))
+		require.Equal(t, ndn.InterestResultUnverified, (<-ch).Status)
+		require.Empty(t, fired)
+	})
+}
+
+// singlePacketJson is the schema of the single-packet example.
+// Both sides are connected by a pipe, so no prefix is registered.
+const singlePacketJson = `{
+	"nodes": {
+		"/randomData/<v=time>": {
+			"type": "LeafNode",
+			"attrs": {"CanBePrefix": false, "MustBeFresh": true, "Lifetime": 6000},
+			"events": {"OnInterest": ["$onInterest"]}
+		}
+	},
+	"policies": [
+		{"type": "RegisterPolicy", "path": "/", "attrs": {"RegisterIf": "$isProducer"}},
+		{"type": "Sha256Signer", "path": "/randomData/<v=time>", "attrs": {}}
+	]
+}`
+
+func TestExpressPointNeedCtx(t *testing.T) {
+	utils.SetTestingT(t)
+
+	// The producer replies t=1 as the example does, ignores t=2, and replies t=3 with a Data of a wrong signature
+	onInterest := schema.Callback(func(event *schema.Event) any {
+		mNode := event.Target
+		timestamp, _ := enc.ParseNat(mNode.Matching["time"])
+		var wire enc.Wire
+		switch timestamp {
+		case 1:
+			data := mNode.Call("ProvideEx", enc.Wire{[]byte("Hello, world!")}).(*schema.ProvidedData)
+			wire = data.Wire
+		case 3:
+			wire, _, _ = spec_2022.Spec{}.MakeData(mNode.Name, &ndn.DataConfig{
+				ContentType: utils.IdPtr(ndn.ContentTypeBlob),
+			}, enc.Wire{[]byte("forged")}, sec.NewHmacSigner(mNode.Name, []byte("key"), false, 0))
+		default:
+			return true
+		}
+		require.NoError(t, event.Reply(wire))
+		return true
+	})
+	noop := schema.Callback(func(event *schema.Event) any { return nil })
+
+	consFace, prodFace := dummy.NewPipeFaces()
+	timer := basic_engine.NewTimer()
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool { return true }
+	prefix := utils.WithoutErr(enc.NameFromStr("/example/testApp"))
+	prod := basic_engine.NewEngine(prodFace, timer, sec.NewSha256IntSigner(timer), passAll)
+	cons := basic_engine.NewEngine(consFace, timer, sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(t, prod.Start())
+	require.NoError(t, cons.Start())
+	defer prod.Shutdown()
+	defer cons.Shutdown()
+	prodTree := schema.CreateFromJson(singlePacketJson, map[string]any{"$onInterest": onInterest, "$isProducer": false})
+	consTree := schema.CreateFromJson(singlePacketJson, map[string]any{"$onInterest": noop, "$isProducer": false})
+	require.NoError(t, prodTree.Attach(prefix, prod))
+	defer prodTree.Detach()
+	require.NoError(t, consTree.Attach(prefix, cons))
+	defer consTree.Detach()
+
+	match := func(timestamp uint64) *schema.MatchedNode {
+		return consTree.At(utils.WithoutErr(enc.NamePatternFromStr("/randomData/<v=time>"))).Apply(enc.Matching{
+			"time": enc.Nat(timestamp).Bytes(),
+		})
+	}
+
+	// The content is returned once the Data is fetched and verified
+	content, err := match(1).Need(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []byte("Hello, world!"), content.Join())
+
+	// The context ends the wait before the Interest times out
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = match(2).Need(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	// And removes the Interest from the PIT
+	require.Empty(t, cons.PendingInterests())
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = match(1).Need(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// A Data failing validation is an error
+	_, err = match(3).Need(context.Background())
+	require.Equal(t, schema.ErrNeedFailed{Status: ndn.InterestResultUnverified}, err)
+
+	// Only ExpressPoints can Need
+	_, err = consTree.Match(prefix).Need(context.Background())
+	require.Error(t, err)
+}
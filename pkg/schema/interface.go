@@ -2,7 +2,9 @@ package schema
 
 import (
 	"fmt"
+	"math"
 	"reflect"
+	"strconv"
 	"time"
 
 	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
@@ -25,6 +27,8 @@ type NodeImplDesc struct {
 	Events     map[PropKey]EventGetter
 	Create     func(*Node) NodeImpl
 	Functions  map[string]NodeFunc
+	// Validate checks the attributes of a node after they are set, such as the ranges of values. Optional.
+	Validate func(owner any) error
 }
 
 type PolicyImplDesc struct {
@@ -62,6 +66,14 @@ type NodeImpl interface {
 	TreeNode() *Node
 }
 
+// PrefixInterestHandler is implemented by a NodeImpl that serves names beyond its pattern.
+// An Interest whose name matches no node fully is given to the deepest node matching its prefix if the node
+// implements it, with the unmatched components in mNode.Remainder.
+type PrefixInterestHandler interface {
+	OnPrefixInterest(interest ndn.Interest, rawInterest enc.Wire, sigCovered enc.Wire,
+		reply ndn.ReplyFunc, deadline time.Time, mNode *MatchedNode)
+}
+
 // NTPolicy represents a policy, which is a modifier that sets properties and registers events
 // during the initialization of a schema tree.
 // The execution order is: construct the tree -> apply policies & env setup -> attach to engine (see Tree)
@@ -84,6 +96,26 @@ type Policy interface {
 	Apply(node *Node)
 }
 
+// SubtreePolicy is a policy applied to every node of the subtree, such as a storage.
+// When a node is added by Tree.AddNode after the policy is applied,
+// the policy is also applied to the new node.
+type SubtreePolicy interface {
+	Policy
+
+	// SubtreeTrait is the type trait of SubtreePolicy
+	SubtreeTrait() SubtreePolicy
+}
+
+// SignerPolicy is a policy giving the signer of Data, such as Sha256SignerPolicy.
+// When it is applied at an inner node, the descendants without a SignerPolicy of their own
+// inherit it on Tree.Attach, so the most specific one wins.
+type SignerPolicy interface {
+	Policy
+
+	// SignerTrait is the type trait of SignerPolicy
+	SignerTrait() SignerPolicy
+}
+
 // PropKey is the type of properties of a node.
 // A property of a node gives the default setting of some procedure as well as event callbacks.
 // We design in this way to support DSL and WASM in future.
@@ -94,6 +126,8 @@ type MatchedNode struct {
 	Node     *Node
 	Matching enc.Matching
 	Name     enc.Name
+	// Remainder is the trailing components of a name beyond the node, given by MatchPrefix.
+	Remainder enc.Name
 }
 
 // ValidRes is the result of data/interest signature validation, given by one validator.
@@ -119,6 +153,18 @@ const (
 	// The event called when an ExpressingPoint or LeafNode receives an Interest. [NodeOnIntEvent]
 	PropOnInterest PropKey = "OnInt"
 
+	// The event called when an Interest expressed by an ExpressingPoint or LeafNode is Nacked by the forwarder.
+	// Event.NackReason gives the reason. It is called before the callback of Need. [NodeOnNackEvent]
+	PropOnNack PropKey = "OnNack"
+
+	// The event called when an ExpressingPoint or LeafNode obtains a verified Data on Need(),
+	// either from the network or the storage. It is called before the callback of Need. [NodeOnDataEvent]
+	PropOnData PropKey = "OnData"
+
+	// The event called when an Interest expressed by an ExpressingPoint or LeafNode times out.
+	// It is called before the callback of Need. [NodeOnTimeoutEvent]
+	PropOnTimeout PropKey = "OnTimeout"
+
 	// The event called when an ExpressingPoint or LeafNode verifies the signature of an Interest. [NodeValidateEvent]
 	PropOnValidateInt PropKey = "OnValidateInt"
 
@@ -144,8 +190,12 @@ const (
 	PropCanBePrefix PropKey = "CanBePrefix"
 	// Default MustBeFresh for outgoing Interest. [bool]
 	PropMustBeFresh PropKey = "MustBeFresh"
-	// Default Lifetime for outgoing Interest. [time.Duration]
+	// Default Lifetime for outgoing Interest. 0 means the default of the engine. [time.Duration]
 	PropLifetime PropKey = "Lifetime"
+	// Default number of retransmissions of outgoing Interest on timeout. [int]
+	PropRetries PropKey = "Retries"
+	// Default ForwardingHint of outgoing Interest, i.e. the names to reach the producer. [[]enc.Name]
+	PropForwardingHint PropKey = "ForwardingHint"
 	// If true, only local storage is searched. No Interest will be expressed.
 	// Note: may be overwritten by the Context.
 	// [bool]
@@ -163,9 +213,117 @@ const (
 	// For long durable data stored in databases, etc., the users should use the ways
 	// specified by the storage developers to express the lifetime.
 	PropValidDuration PropKey = "ValidDur"
+	// If true, a LeafNode replies Interests with the Data it produced last for the name, without calling OnInterest.
+	// Set it to false if the Data must be produced again for every Interest. [bool]
+	PropCacheData PropKey = "CacheData"
+	// The number of names whose Data is kept by a LeafNode with CacheData set. [int]
+	PropCacheSize PropKey = "CacheSize"
+	// How a LeafNode handles Interests for a Data scheduled by ProvideAt but not produced yet. [string]
+	// "Hold" keeps them until the Data is produced, or they expire. "Nack" replies them with an AppNack.
+	PropEarlyInterest PropKey = "EarlyInterest"
 )
 
+// ErrAttrType is returned when an attribute is given a value that cannot be converted to the type of the property.
+type ErrAttrType struct {
+	Item     string
+	Value    any
+	Expected string
+}
+
+func (e ErrAttrType) Error() string {
+	return fmt.Sprintf("%s expects %s, not %v (%T)", e.Item, e.Expected, e.Value, e.Value)
+}
+
+// convertAttr converts the value of an attribute to typ.
+// Numbers in JSON are float64, while the environment may give numbers of any type,
+// so a number is accepted by any numeric type it fits in without losing its fraction.
+// Strings, like the ones loaded from files or environment variables, are parsed for numbers and booleans.
+func convertAttr(value any, typ reflect.Type) (reflect.Value, bool) {
+	ret := reflect.New(typ).Elem()
+	if value == nil {
+		switch typ.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Slice, reflect.Map, reflect.Func, reflect.Chan:
+			return ret, true
+		}
+		return ret, false
+	}
+	val := reflect.ValueOf(value)
+	if val.Type().AssignableTo(typ) {
+		return val, true
+	}
+	if str, ok := value.(string); ok {
+		switch typ.Kind() {
+		case reflect.String:
+			ret.SetString(str)
+			return ret, true
+		case reflect.Bool:
+			b, err := strconv.ParseBool(str)
+			if err != nil {
+				return ret, false
+			}
+			ret.SetBool(b)
+			return ret, true
+		case reflect.Slice:
+			if typ.Elem().Kind() != reflect.Uint8 {
+				return ret, false
+			}
+			ret.SetBytes([]byte(str))
+			return ret, true
+		}
+		f, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return ret, false
+		}
+		val = reflect.ValueOf(f)
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		switch {
+		case val.CanInt():
+			i = val.Int()
+		case val.CanUint() && val.Uint() <= math.MaxInt64:
+			i = int64(val.Uint())
+		case val.CanFloat() && val.Float() == math.Trunc(val.Float()) &&
+			val.Float() >= math.MinInt64 && val.Float() < math.MaxInt64:
+			i = int64(val.Float())
+		default:
+			return ret, false
+		}
+		if ret.OverflowInt(i) {
+			return ret, false
+		}
+		ret.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		switch {
+		case val.CanUint():
+			u = val.Uint()
+		case val.CanInt() && val.Int() >= 0:
+			u = uint64(val.Int())
+		case val.CanFloat() && val.Float() == math.Trunc(val.Float()) &&
+			val.Float() >= 0 && val.Float() < math.MaxUint64:
+			u = uint64(val.Float())
+		default:
+			return ret, false
+		}
+		if ret.OverflowUint(u) {
+			return ret, false
+		}
+		ret.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		if !val.CanInt() && !val.CanUint() && !val.CanFloat() {
+			return ret, false
+		}
+		ret.Set(val.Convert(typ))
+	default:
+		return ret, false
+	}
+	return ret, true
+}
+
 // DefaultPropertyDesc returns the default property descriptor of given property name.
+// The value is converted to the type of the field by the rules of convertAttr.
 func DefaultPropertyDesc(prop PropKey) PropertyDesc {
 	return PropertyDesc{
 		Get: func(owner any) any {
@@ -177,31 +335,16 @@ func DefaultPropertyDesc(prop PropKey) PropertyDesc {
 			ret = ndn.ErrInvalidValue{Item: string(prop), Value: value}
 			defer func() { recover() }() // Return error
 			objval := reflect.ValueOf(owner)
-			// Need to handle a special case: if field is uint64, then it must accept float64 value
 			field := objval.Elem().FieldByName(string(prop))
 			if !field.CanSet() {
-				ret = ndn.ErrNotSupported{Item: string(prop)}
+				return ndn.ErrNotSupported{Item: string(prop)}
 			}
-			if field.Kind() == reflect.Uint64 {
-				switch v := value.(type) {
-				case float64:
-					field.SetUint(uint64(v))
-				default:
-					field.Set(reflect.ValueOf(value))
-				}
-			} else if field.Type() == reflect.TypeOf([]byte{}) {
-				// Cast string to bytes
-				switch v := value.(type) {
-				case string:
-					field.Set(reflect.ValueOf([]byte(v)))
-				default:
-					field.Set(reflect.ValueOf(value))
-				}
-			} else {
-				field.Set(reflect.ValueOf(value))
+			val, ok := convertAttr(value, field.Type())
+			if !ok {
+				return ErrAttrType{Item: string(prop), Value: value, Expected: field.Type().String()}
 			}
-			ret = nil
-			return
+			field.Set(val)
+			return nil
 		},
 	}
 }
@@ -216,6 +359,7 @@ func DefaultEventTarget(event PropKey) EventGetter {
 }
 
 // TimePropertyDesc returns the descriptor of a time property, which gives numbers&strings in milliseconds.
+// A time.Duration or a string like "1.5s" is also accepted.
 // Note: Get/Set functions are less used by the go program, as Go can access the field directly.
 func TimePropertyDesc(prop PropKey) PropertyDesc {
 	return PropertyDesc{
@@ -230,26 +374,71 @@ func TimePropertyDesc(prop PropKey) PropertyDesc {
 			defer func() { recover() }() // Return error
 			objval := reflect.ValueOf(owner)
 			field := objval.Elem().FieldByName(string(prop))
+			dur, ok := value.(time.Duration)
+			if str, isStr := value.(string); isStr {
+				d, err := time.ParseDuration(str)
+				dur, ok = d, err == nil
+			}
+			if !ok {
+				// Integers are converted exactly, while fractions of milliseconds are allowed
+				if ms, isInt := convertAttr(value, reflect.TypeOf(int64(0))); isInt {
+					dur, ok = time.Duration(ms.Int())*time.Millisecond, true
+				} else if ms, isNum := convertAttr(value, reflect.TypeOf(float64(0))); isNum {
+					dur, ok = time.Duration(ms.Float()*float64(time.Millisecond)), true
+				}
+			}
+			if !ok || dur < 0 {
+				return ErrAttrType{Item: string(prop), Value: value, Expected: "a non-negative duration in milliseconds"}
+			}
+			field.Set(reflect.ValueOf(dur))
+			return nil
+		},
+	}
+}
+
+// TimestampPropertyDesc returns the descriptor of an optional point of time property of type `*time.Time`.
+// It is an RFC 3339 string or a number of milliseconds since the Unix epoch in JSON.
+func TimestampPropertyDesc(prop PropKey) PropertyDesc {
+	return PropertyDesc{
+		Get: func(owner any) any {
+			defer func() { recover() }() // Return nil for not existing field
+			objval := reflect.ValueOf(owner)
+			val := objval.Elem().FieldByName(string(prop)).Interface().(*time.Time)
+			if val == nil {
+				return nil
+			}
+			return val.Format(time.RFC3339)
+		},
+		Set: func(owner any, value any) (ret error) {
+			ret = ndn.ErrInvalidValue{Item: string(prop), Value: value}
+			defer func() { recover() }() // Return error
+			objval := reflect.ValueOf(owner)
+			field := objval.Elem().FieldByName(string(prop))
+			var t time.Time
 			switch v := value.(type) {
-			case float32:
-				field.Set(reflect.ValueOf(time.Duration(v * float32(time.Millisecond))))
+			case nil:
+				field.Set(reflect.ValueOf((*time.Time)(nil)))
 				ret = nil
+				return
+			case string:
+				var err error
+				t, err = time.Parse(time.RFC3339, v)
+				if err != nil {
+					return
+				}
 			case float64:
-				field.Set(reflect.ValueOf(time.Duration(v * float64(time.Millisecond))))
-				ret = nil
-			case int64:
-				field.Set(reflect.ValueOf(time.Duration(v) * time.Millisecond))
-				ret = nil
-			case uint64:
-				field.Set(reflect.ValueOf(time.Duration(v) * time.Millisecond))
-				ret = nil
-			case int:
-				field.Set(reflect.ValueOf(time.Duration(v) * time.Millisecond))
-				ret = nil
-			case uint:
-				field.Set(reflect.ValueOf(time.Duration(v) * time.Millisecond))
+				t = time.UnixMilli(int64(v))
+			case time.Time:
+				t = v
+			case *time.Time:
+				field.Set(reflect.ValueOf(v))
 				ret = nil
+				return
+			default:
+				return
 			}
+			field.Set(reflect.ValueOf(&t))
+			ret = nil
 			return
 		},
 	}
@@ -335,7 +524,7 @@ func NamePropertyDesc(prop PropKey) PropertyDesc {
 				ret = nil
 			case string:
 				name, err := enc.NameFromStr(v)
-				if err != nil {
+				if err == nil {
 					field.Set(reflect.ValueOf(name))
 					ret = nil
 				}
@@ -345,6 +534,62 @@ func NamePropertyDesc(prop PropKey) PropertyDesc {
 	}
 }
 
+// NamesPropertyDesc returns the descriptor of a []enc.Name property, which gives a list of NDN Names in string.
+func NamesPropertyDesc(prop PropKey) PropertyDesc {
+	return PropertyDesc{
+		Get: func(owner any) any {
+			defer func() { recover() }() // Return nil for not existing field
+			objval := reflect.ValueOf(owner)
+			val := objval.Elem().FieldByName(string(prop)).Interface().([]enc.Name)
+			ret := make([]string, len(val))
+			for i, name := range val {
+				ret[i] = name.String()
+			}
+			return ret
+		},
+		Set: func(owner any, value any) (ret error) {
+			ret = ndn.ErrInvalidValue{Item: string(prop), Value: value}
+			defer func() { recover() }() // Return error
+			objval := reflect.ValueOf(owner)
+			field := objval.Elem().FieldByName(string(prop))
+			var names []enc.Name
+			switch v := value.(type) {
+			case nil:
+			case []enc.Name:
+				names = v
+			case []string:
+				names = make([]enc.Name, len(v))
+				for i, str := range v {
+					name, err := enc.NameFromStr(str)
+					if err != nil {
+						return
+					}
+					names[i] = name
+				}
+			case []any:
+				// Given by json
+				names = make([]enc.Name, len(v))
+				for i, item := range v {
+					str, ok := item.(string)
+					if !ok {
+						return
+					}
+					name, err := enc.NameFromStr(str)
+					if err != nil {
+						return
+					}
+					names[i] = name
+				}
+			default:
+				return
+			}
+			field.Set(reflect.ValueOf(names))
+			ret = nil
+			return
+		},
+	}
+}
+
 // Call calls the specified function provided by the node with give agruments.
 func (mNode MatchedNode) Call(funcName string, args ...any) any {
 	f, ok := mNode.Node.desc.Functions[funcName]
@@ -1,7 +1,9 @@
 package schema
 
 import (
+	"container/list"
 	"fmt"
+	"sync"
 	"time"
 
 	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
@@ -15,9 +17,54 @@ type LeafNode struct {
 
 	OnGetDataSigner *EventTarget
 
-	ContentType ndn.ContentType
-	Freshness   time.Duration
-	ValidDur    time.Duration
+	ContentType   ndn.ContentType
+	Freshness     time.Duration
+	ValidDur      time.Duration
+	CacheData     bool
+	CacheSize     int
+	EarlyInterest string
+
+	// The Data produced recently, keyed by name, with the least recently used at the back.
+	cacheLock  sync.Mutex
+	cache      map[string]*list.Element
+	cacheOrder list.List
+
+	// The Data scheduled by ProvideAt, keyed by name.
+	scheduleLock sync.Mutex
+	scheduled    map[string]*scheduledData
+}
+
+// scheduledData is a Data to be produced by ProvideAt, with the Interests held for it.
+type scheduledData struct {
+	when   time.Time
+	cancel func() error
+	held   []heldInterest
+}
+
+// heldInterest is an Interest waiting for a scheduled Data.
+type heldInterest struct {
+	reply    ndn.ReplyFunc
+	deadline time.Time
+}
+
+// producedData is a Data produced by a LeafNode.
+type producedData struct {
+	name     string
+	wire     enc.Wire
+	freshEnd time.Time
+	validEnd time.Time
+}
+
+// ProvidedData describes a Data produced by LeafNode.ProvideEx.
+type ProvidedData struct {
+	// Wire is the encoded Data, the same as the one returned by Provide.
+	Wire enc.Wire
+	// Name is the name of the Data.
+	Name enc.Name
+	// FullName is Name followed by the implicit SHA-256 digest of Wire.
+	FullName enc.Name
+	// Size is the length of Wire in bytes.
+	Size uint64
 }
 
 func (n *LeafNode) NodeImplTrait() NodeImpl {
@@ -54,14 +101,7 @@ func (n *LeafNode) Provide(
 		Content:    content,
 	}
 
-	// Get a signer for Data.
-	evtRet := n.OnGetDataSigner.DispatchUntil(event, func(a any) bool {
-		ret, ok := a.(ndn.Signer)
-		return ok && ret != nil
-	})
-	signer, _ := evtRet.(ndn.Signer)
-
-	wire, _, err := spec.MakeData(mNode.Name, dataCfg, content, signer)
+	wire, _, err := spec.MakeData(mNode.Name, dataCfg, content, n.dataSigner(event))
 	if err != nil {
 		logger.Errorf("Unable to encode Data in Provide(): %+v", err)
 		return nil
@@ -73,30 +113,285 @@ func (n *LeafNode) Provide(
 	event.ValidDuration = &validDur
 	event.Deadline = utils.IdPtr(engine.Timer().Now().Add(validDur))
 	n.OnSaveStorage.Dispatch(event)
+	if n.CacheData {
+		freshness := time.Duration(0)
+		if dataCfg.Freshness != nil {
+			freshness = *dataCfg.Freshness
+		}
+		now := engine.Timer().Now()
+		n.putCache(&producedData{
+			name:     mNode.Name.String(),
+			wire:     wire,
+			freshEnd: now.Add(freshness),
+			validEnd: now.Add(validDur),
+		})
+	}
 
 	// Return encoded data
 	return wire
 }
 
+// ProvideEx is the same as Provide, but also returns the name, full name and size of the Data, e.g. for logging.
+// It returns nil if the Data cannot be produced.
+func (n *LeafNode) ProvideEx(
+	mNode MatchedNode, content enc.Wire, dataCfg *ndn.DataConfig,
+) *ProvidedData {
+	wire := n.Provide(mNode, content, dataCfg)
+	if wire == nil {
+		return nil
+	}
+	name := make(enc.Name, len(mNode.Name))
+	copy(name, mNode.Name)
+	return &ProvidedData{
+		Wire:     wire,
+		Name:     name,
+		FullName: name.ToFullName(wire),
+		Size:     wire.Length(),
+	}
+}
+
+// dataSigner returns the signer given by the first OnGetDataSigner handler that gives one,
+// or the signer the engine key chain selects for the target name if no handler does.
+func (n *LeafNode) dataSigner(event *Event) ndn.Signer {
+	evtRet := n.OnGetDataSigner.DispatchUntil(event, func(a any) bool {
+		ret, ok := a.(ndn.Signer)
+		return ok && ret != nil
+	})
+	signer, _ := evtRet.(ndn.Signer)
+	if signer == nil && event.Target != nil {
+		// No signer policy applies; fall back to the key chain of the engine
+		signer = n.Node.engine.SignerForName(event.Target.Name)
+	}
+	return signer
+}
+
+// ProvideAt schedules the Data produced by Provide at the time when.
+// Interests for the name arriving earlier are held or nacked according to EarlyInterest.
+// The Data is provided at once if when is not in the future. Scheduling the same name again replaces the schedule.
+// The schedule is cancelled if the node is detached before when, e.g. by Tree.Detach or Tree.RemoveNode.
+func (n *LeafNode) ProvideAt(
+	mNode MatchedNode, when time.Time, content enc.Wire, dataCfg *ndn.DataConfig,
+) {
+	if mNode.Node != n.Node {
+		panic("NTSchema tree compromised.")
+	}
+	timer := n.Node.engine.Timer()
+	key := mNode.Name.String()
+	delay := when.Sub(timer.Now())
+
+	n.scheduleLock.Lock()
+	if n.scheduled == nil {
+		n.scheduled = make(map[string]*scheduledData)
+	}
+	sched, ok := n.scheduled[key]
+	if ok {
+		sched.cancel()
+	} else {
+		sched = &scheduledData{}
+	}
+	if delay <= 0 {
+		delete(n.scheduled, key)
+		n.provideScheduled(mNode, sched, content, dataCfg)
+		return
+	}
+	sched.when = when
+	sched.cancel = timer.Schedule(delay, func() {
+		n.scheduleLock.Lock()
+		if n.scheduled[key] != sched || sched.when != when {
+			// Replaced by a later ProvideAt, or cancelled by OnDetach
+			n.scheduleLock.Unlock()
+			return
+		}
+		n.provideScheduled(mNode, sched, content, dataCfg)
+	})
+	n.scheduled[key] = sched
+	n.scheduleLock.Unlock()
+}
+
+// provideScheduled produces a scheduled Data and replies the Interests held for it.
+// It must be called with scheduleLock held, which it releases. The Data is produced under the lock,
+// so that OnDetach waits for it and the node is attached while producing.
+func (n *LeafNode) provideScheduled(
+	mNode MatchedNode, sched *scheduledData, content enc.Wire, dataCfg *ndn.DataConfig,
+) {
+	wire := n.Provide(mNode, content, dataCfg)
+	// Interests arriving before the removal are still held, so none is missed.
+	if n.scheduled[mNode.Name.String()] == sched {
+		delete(n.scheduled, mNode.Name.String())
+	}
+	held := sched.held
+	sched.held = nil
+	now := n.Node.engine.Timer().Now()
+	n.scheduleLock.Unlock()
+	if wire == nil {
+		return
+	}
+	for _, h := range held {
+		if now.After(h.deadline) {
+			continue
+		}
+		if err := h.reply(wire); err != nil {
+			mNode.Logger("LeafNode").Errorf("Unable to reply Interest. Drop: %+v", err)
+		}
+	}
+}
+
+// OnDetach cancels the Data scheduled by ProvideAt, and drops the Interests held for them.
+func (n *LeafNode) OnDetach() {
+	n.scheduleLock.Lock()
+	for _, sched := range n.scheduled {
+		sched.cancel()
+		sched.held = nil
+	}
+	n.scheduled = nil
+	n.scheduleLock.Unlock()
+	n.ExpressPoint.OnDetach()
+}
+
+// onEarlyInterest handles an Interest for a Data not produced yet. It returns false if nothing is scheduled.
+func (n *LeafNode) onEarlyInterest(
+	name enc.Name, reply ndn.ReplyFunc, deadline time.Time, matching enc.Matching,
+) bool {
+	n.scheduleLock.Lock()
+	sched, ok := n.scheduled[name.String()]
+	if !ok {
+		n.scheduleLock.Unlock()
+		return false
+	}
+	if n.EarlyInterest != "Nack" {
+		sched.held = append(sched.held, heldInterest{reply: reply, deadline: deadline})
+		n.scheduleLock.Unlock()
+		return true
+	}
+	when := sched.when
+	n.scheduleLock.Unlock()
+
+	// The AppNack is only fresh until the Data is produced.
+	mNode := MatchedNode{Node: n.Node, Matching: matching, Name: name}
+	logger := mNode.Logger("LeafNode")
+	dataCfg := &ndn.DataConfig{
+		ContentType: utils.IdPtr(ndn.ContentTypeNack),
+		Freshness:   utils.IdPtr(max(when.Sub(n.Node.engine.Timer().Now()), 0)),
+	}
+	event := &Event{TargetNode: n.Node, Target: &mNode, DataConfig: dataCfg}
+	wire, _, err := n.Node.engine.Spec().MakeData(name, dataCfg, nil, n.dataSigner(event))
+	if err != nil {
+		logger.Errorf("Unable to encode AppNack: %+v", err)
+		return true
+	}
+	if err = reply(wire); err != nil {
+		logger.Errorf("Unable to reply Interest. Drop: %+v", err)
+	}
+	return true
+}
+
+// putCache replaces the cached Data of the same name with data.
+func (n *LeafNode) putCache(data *producedData) {
+	n.cacheLock.Lock()
+	defer n.cacheLock.Unlock()
+	if n.cache == nil {
+		n.cache = make(map[string]*list.Element)
+	}
+	if elem, ok := n.cache[data.name]; ok {
+		n.cacheOrder.Remove(elem)
+	}
+	n.cache[data.name] = n.cacheOrder.PushFront(data)
+	for len(n.cache) > max(n.CacheSize, 1) {
+		back := n.cacheOrder.Back()
+		n.cacheOrder.Remove(back)
+		delete(n.cache, back.Value.(*producedData).name)
+	}
+}
+
+// getCache returns the Data produced for name if it is still valid, and fresh if mustBeFresh.
+func (n *LeafNode) getCache(name enc.Name, mustBeFresh bool) enc.Wire {
+	n.cacheLock.Lock()
+	defer n.cacheLock.Unlock()
+	elem, ok := n.cache[name.String()]
+	if !ok {
+		return nil
+	}
+	data := elem.Value.(*producedData)
+	now := n.Node.engine.Timer().Now()
+	if !now.Before(data.validEnd) {
+		n.cacheOrder.Remove(elem)
+		delete(n.cache, data.name)
+		return nil
+	}
+	if mustBeFresh && !now.Before(data.freshEnd) {
+		return nil
+	}
+	n.cacheOrder.MoveToFront(elem)
+	return data.wire
+}
+
+// OnInterest replies the Interest with the Data produced before if CacheData is set.
+// Otherwise, it is handled by the ExpressPoint.
+func (n *LeafNode) OnInterest(
+	interest ndn.Interest, rawInterest enc.Wire, sigCovered enc.Wire,
+	reply ndn.ReplyFunc, deadline time.Time, matching enc.Matching,
+) {
+	name := interest.Name()
+	// The cache and scheduled Data are not searched by digest
+	if name[len(name)-1].Typ != enc.TypeImplicitSha256DigestComponent {
+		if n.CacheData {
+			if wire := n.getCache(name, interest.MustBeFresh()); wire != nil {
+				err := reply(wire)
+				if err != nil {
+					mNode := MatchedNode{Node: n.Node, Matching: matching, Name: name}
+					mNode.Logger("LeafNode").Errorf("Unable to reply Interest. Drop: %+v", err)
+				}
+				return
+			}
+		}
+		if n.onEarlyInterest(name, reply, deadline, matching) {
+			return
+		}
+	}
+	n.ExpressPoint.OnInterest(interest, rawInterest, sigCovered, reply, deadline, matching)
+}
+
 func CreateLeafNode(node *Node) NodeImpl {
 	return &LeafNode{
 		ExpressPoint:    *CreateExpressPoint(node).(*ExpressPoint),
 		ContentType:     ndn.ContentTypeBlob,
 		Freshness:       1 * time.Minute,
 		ValidDur:        876000 * time.Hour,
+		CacheData:       true,
+		CacheSize:       64,
+		EarlyInterest:   "Hold",
 		OnGetDataSigner: &EventTarget{},
 	}
 }
 
+// validateAttrs checks the ranges of the attributes of Interests and produced Data.
+// A Data is not served after its ValidDuration, so a longer Freshness would be meaningless.
+func (n *LeafNode) validateAttrs() error {
+	if err := n.ExpressPoint.validateAttrs(); err != nil {
+		return err
+	}
+	if n.CacheSize < 0 {
+		return ndn.ErrInvalidValue{Item: string(PropCacheSize), Value: n.CacheSize}
+	}
+	if n.Freshness > n.ValidDur {
+		return fmt.Errorf("Freshness %v exceeds ValidDuration %v", n.Freshness, n.ValidDur)
+	}
+	if n.EarlyInterest != "Hold" && n.EarlyInterest != "Nack" {
+		return ndn.ErrInvalidValue{Item: string(PropEarlyInterest), Value: n.EarlyInterest}
+	}
+	return nil
+}
+
 var LeafNodeDesc *NodeImplDesc
 
 func initLeafNodeDesc() {
 	LeafNodeDesc = &NodeImplDesc{
 		ClassName:  "LeafNode",
-		Properties: make(map[PropKey]PropertyDesc, len(ExpressPointDesc.Properties)+3),
+		Properties: make(map[PropKey]PropertyDesc, len(ExpressPointDesc.Properties)+6),
 		Events:     make(map[PropKey]EventGetter, len(ExpressPointDesc.Events)+1),
-		Functions:  make(map[string]NodeFunc, len(ExpressPointDesc.Functions)+1),
+		Functions:  make(map[string]NodeFunc, len(ExpressPointDesc.Functions)+2),
 		Create:     CreateLeafNode,
+		Validate:   func(owner any) error { return owner.(*LeafNode).validateAttrs() },
 	}
 	for k, v := range ExpressPointDesc.Properties {
 		LeafNodeDesc.Properties[k] = v
@@ -104,6 +399,9 @@ func initLeafNodeDesc() {
 	LeafNodeDesc.Properties[PropContentType] = DefaultPropertyDesc(PropContentType)
 	LeafNodeDesc.Properties[PropFreshness] = TimePropertyDesc(PropFreshness)
 	LeafNodeDesc.Properties["ValidDuration"] = TimePropertyDesc(PropValidDuration)
+	LeafNodeDesc.Properties[PropCacheData] = DefaultPropertyDesc(PropCacheData)
+	LeafNodeDesc.Properties[PropCacheSize] = DefaultPropertyDesc(PropCacheSize)
+	LeafNodeDesc.Properties[PropEarlyInterest] = DefaultPropertyDesc(PropEarlyInterest)
 	for k, v := range ExpressPointDesc.Events {
 		LeafNodeDesc.Events[k] = v
 	}
@@ -135,6 +433,66 @@ func initLeafNodeDesc() {
 		}
 		return QueryInterface[*LeafNode](mNode.Node).Provide(mNode, content, dataCfg)
 	}
+	LeafNodeDesc.Functions["ProvideEx"] = func(mNode MatchedNode, args ...any) any {
+		if len(args) < 1 || len(args) > 2 {
+			err := fmt.Errorf("LeafNode.ProvideEx requires 1~2 arguments but got %d", len(args))
+			mNode.Logger("LeafNode").Error(err.Error())
+			return err
+		}
+		// content enc.Wire, dataCfg *ndn.DataConfig,
+		content, ok := args[0].(enc.Wire)
+		if !ok && args[0] != nil {
+			err := ndn.ErrInvalidValue{Item: "content", Value: args[0]}
+			mNode.Logger("LeafNode").Error(err.Error())
+			return err
+		}
+		var dataCfg *ndn.DataConfig
+		if len(args) >= 2 {
+			dataCfg, ok = args[1].(*ndn.DataConfig)
+			if !ok && args[1] != nil {
+				err := ndn.ErrInvalidValue{Item: "dataCfg", Value: args[1]}
+				mNode.Logger("LeafNode").Error(err.Error())
+				return err
+			}
+		}
+		return QueryInterface[*LeafNode](mNode.Node).ProvideEx(mNode, content, dataCfg)
+	}
+	LeafNodeDesc.Functions["ProvideAt"] = func(mNode MatchedNode, args ...any) any {
+		if len(args) < 2 || len(args) > 3 {
+			err := fmt.Errorf("LeafNode.ProvideAt requires 2~3 arguments but got %d", len(args))
+			mNode.Logger("LeafNode").Error(err.Error())
+			return err
+		}
+		// when time.Time or delay time.Duration, content enc.Wire, dataCfg *ndn.DataConfig,
+		var when time.Time
+		switch v := args[0].(type) {
+		case time.Time:
+			when = v
+		case time.Duration:
+			when = mNode.Node.engine.Timer().Now().Add(v)
+		default:
+			err := ndn.ErrInvalidValue{Item: "when", Value: args[0]}
+			mNode.Logger("LeafNode").Error(err.Error())
+			return err
+		}
+		content, ok := args[1].(enc.Wire)
+		if !ok && args[1] != nil {
+			err := ndn.ErrInvalidValue{Item: "content", Value: args[1]}
+			mNode.Logger("LeafNode").Error(err.Error())
+			return err
+		}
+		var dataCfg *ndn.DataConfig
+		if len(args) >= 3 {
+			dataCfg, ok = args[2].(*ndn.DataConfig)
+			if !ok && args[2] != nil {
+				err := ndn.ErrInvalidValue{Item: "dataCfg", Value: args[2]}
+				mNode.Logger("LeafNode").Error(err.Error())
+				return err
+			}
+		}
+		QueryInterface[*LeafNode](mNode.Node).ProvideAt(mNode, when, content, dataCfg)
+		return nil
+	}
 	RegisterNodeImpl(LeafNodeDesc)
 }
 
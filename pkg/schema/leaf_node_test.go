@@ -0,0 +1,342 @@
+package schema_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	basic_engine "github.com/zjkmxy/go-ndn/pkg/engine/basic"
+	"github.com/zjkmxy/go-ndn/pkg/engine/dummy"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/ndn/spec_2022"
+	"github.com/zjkmxy/go-ndn/pkg/schema"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+func readData(t *testing.T, wire enc.Wire) *spec_2022.Data {
+	pkt, _, err := spec_2022.ReadPacket(enc.NewWireReader(wire))
+	require.NoError(t, err)
+	require.NotNil(t, pkt.Data)
+	return pkt.Data
+}
+
+func TestLeafNodeKeyChainSigner(t *testing.T) {
+	const treeJson = `{
+		"nodes": {
+			"/signed/<v=time>": {"type": "LeafNode", "attrs": {}},
+			"/other/<v=time>": {"type": "LeafNode", "attrs": {}}
+		},
+		"policies": [
+			{"type": "Sha256Signer", "path": "/signed/<v=time>"}
+		]
+	}`
+	executeSchemaTest(t, treeJson, func(env *schemaTestEnv) {
+		keyName := utils.WithoutErr(enc.NameFromStr("/p/other/KEY/k1"))
+		kc := sec.NewKeyChain()
+		kc.AddSigner(utils.WithoutErr(enc.NameFromStr("/p/other")), sec.NewHmacSigner(keyName, []byte("secret"), false, 0))
+		env.engine.SetKeyChain(kc)
+
+		// No signer policy: the key chain gives the signer
+		name := utils.WithoutErr(enc.NameFromStr("/p/other/v=1"))
+		wire := env.tree.Match(name).Call("Provide", enc.Wire{[]byte("hello")}).(enc.Wire)
+		data := readData(t, wire)
+		require.Equal(t, ndn.SignatureHmacWithSha256, data.Signature().SigType())
+		require.True(t, keyName.Equal(data.Signature().KeyName()))
+
+		// A signer policy takes precedence over the key chain
+		name = utils.WithoutErr(enc.NameFromStr("/p/signed/v=1"))
+		wire = env.tree.Match(name).Call("Provide", enc.Wire{[]byte("hello")}).(enc.Wire)
+		data = readData(t, wire)
+		require.Equal(t, ndn.SignatureDigestSha256, data.Signature().SigType())
+	})
+}
+
+func TestLeafNodeProvideEx(t *testing.T) {
+	const treeJson = `{
+		"nodes": {
+			"/<v=time>": {"type": "LeafNode", "attrs": {}}
+		},
+		"policies": [
+			{"type": "Sha256Signer", "path": "/<v=time>"},
+			{"type": "MemStorage", "path": "/", "attrs": {}}
+		]
+	}`
+	executeSchemaTest(t, treeJson, func(env *schemaTestEnv) {
+		name := utils.WithoutErr(enc.NameFromStr("/p/v=1"))
+		ret := env.tree.Match(name).Call("ProvideEx", enc.Wire{[]byte("hello")}).(*schema.ProvidedData)
+
+		digest := sha256.Sum256(ret.Wire.Join())
+		require.True(t, name.Equal(ret.Name))
+		require.Equal(t, 3, len(ret.FullName))
+		require.True(t, name.IsPrefix(ret.FullName))
+		require.Equal(t, enc.TypeImplicitSha256DigestComponent, ret.FullName[2].Typ)
+		require.Equal(t, digest[:], ret.FullName[2].Val)
+		require.Equal(t, uint64(len(ret.Wire.Join())), ret.Size)
+		require.Equal(t, "hello", string(readData(t, ret.Wire).Content().Join()))
+
+		// The Data is served from the storage for an Interest of the full name
+		interest, _, _, err := env.engine.Spec().MakeInterest(ret.FullName, &ndn.InterestConfig{
+			Lifetime: utils.IdPtr(4 * time.Second),
+		}, nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, env.face.FeedPacket(interest.Join()))
+		env.timer.MoveForward(10 * time.Millisecond)
+		require.Equal(t, enc.Buffer(ret.Wire.Join()), utils.WithoutErr(env.face.Consume()))
+	})
+}
+
+// producerEnv is a LeafNode /p/obj/<v=time> signed with ECDSA, which produces the Data of every Interest
+// it is asked for.
+type producerEnv struct {
+	*schemaTestEnv
+	// produced is the number of Data produced for Interests
+	produced atomic.Int32
+	done     chan struct{}
+}
+
+func newProducerEnv(tb testing.TB, cacheData bool) *producerEnv {
+	face := dummy.NewDummyFace()
+	timer := dummy.NewTimer()
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool { return true }
+	engine := basic_engine.NewEngine(face, timer, sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(tb, engine.Start())
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(tb, err)
+	prefix := mustName(tb, "/p/obj")
+	kc := sec.NewKeyChain()
+	kc.AddSigner(prefix, sec.NewEcdsaSigner(sec.MakeKeyName(prefix, enc.NewStringComponent(enc.TypeGenericNameComponent, "k1")), key))
+	engine.SetKeyChain(kc)
+
+	tree := schema.CreateFromJson(fmt.Sprintf(`{
+		"nodes": {
+			"/obj/<v=time>": {"type": "LeafNode", "attrs": {"Freshness": 1000, "CacheData": %t}}
+		},
+		"policies": []
+	}`, cacheData), nil)
+	require.NoError(tb, tree.Attach(prefix[:1], engine))
+	tb.Cleanup(func() {
+		tree.Detach()
+		engine.Shutdown()
+	})
+
+	env := &producerEnv{
+		schemaTestEnv: &schemaTestEnv{face: face, timer: timer, engine: engine, tree: tree},
+		done:          make(chan struct{}, 1),
+	}
+	onInterest := schema.Callback(func(event *schema.Event) any {
+		wire := event.Target.Call("Provide", enc.Wire{[]byte("hello")}).(enc.Wire)
+		require.NoError(tb, event.Reply(wire))
+		env.produced.Add(1)
+		env.done <- struct{}{}
+		return true
+	})
+	tree.At(mustPattern(tb, "/obj/<v=time>")).AddEventListener(schema.PropOnInterest, &onInterest)
+	return env
+}
+
+// fetch feeds an Interest to the engine and returns the Data replied.
+// If cached, the Data must be replied from the cache; otherwise, it must be produced by the OnInterest handler.
+func (env *producerEnv) fetch(tb testing.TB, interest enc.Buffer, cached bool) enc.Buffer {
+	produced := env.produced.Load()
+	require.NoError(tb, env.face.FeedPacket(interest))
+	if !cached {
+		select {
+		case <-env.done:
+		case <-time.After(time.Second):
+			tb.Fatal("no Data is produced")
+		}
+		produced++
+	}
+	require.Equal(tb, produced, env.produced.Load())
+	wire, err := env.face.Consume()
+	require.NoError(tb, err)
+	return wire
+}
+
+func TestLeafNodeCache(t *testing.T) {
+	utils.SetTestingT(t)
+	env := newProducerEnv(t, true)
+	interest := makeInterest(t, env.schemaTestEnv, "/p/obj/v=1", nil)
+
+	// The Data provided before is served byte by byte, without being signed again
+	wire := env.tree.Match(utils.WithoutErr(enc.NameFromStr("/p/obj/v=1"))).Call("Provide", enc.Wire{[]byte("hello")}).(enc.Wire)
+	require.Equal(t, enc.Buffer(wire.Join()), env.fetch(t, interest, true))
+	require.Equal(t, enc.Buffer(wire.Join()), env.fetch(t, interest, true))
+
+	// The Data produced for the first Interest serves the following ones
+	interest2 := makeInterest(t, env.schemaTestEnv, "/p/obj/v=2", nil)
+	wire2 := env.fetch(t, interest2, false)
+	require.Equal(t, wire2, env.fetch(t, interest2, true))
+
+	// A new Provide replaces the cached Data. ECDSA signatures are randomized, so it differs from the old one
+	newWire := env.tree.Match(utils.WithoutErr(enc.NameFromStr("/p/obj/v=1"))).Call("Provide", enc.Wire{[]byte("hello")}).(enc.Wire)
+	require.NotEqual(t, wire.Join(), newWire.Join())
+	require.Equal(t, enc.Buffer(newWire.Join()), env.fetch(t, interest, true))
+
+	// A stale Data is not served to MustBeFresh Interests, but still to others
+	env.timer.MoveForward(2 * time.Second)
+	require.Equal(t, enc.Buffer(newWire.Join()), env.fetch(t, interest, true))
+	freshWire := env.fetch(t, makeInterest(t, env.schemaTestEnv, "/p/obj/v=1", &ndn.InterestConfig{
+		MustBeFresh: true,
+		Lifetime:    utils.IdPtr(4 * time.Second),
+	}), false)
+	require.NotEqual(t, enc.Buffer(newWire.Join()), freshWire)
+	require.Equal(t, readData(t, newWire).Content().Join(), readData(t, enc.Wire{freshWire}).Content().Join())
+}
+
+func TestLeafNodeCacheDisabled(t *testing.T) {
+	utils.SetTestingT(t)
+	env := newProducerEnv(t, false)
+	interest := makeInterest(t, env.schemaTestEnv, "/p/obj/v=1", nil)
+
+	// Every Interest is handled by OnInterest, even if the Data is provided before
+	env.tree.Match(utils.WithoutErr(enc.NameFromStr("/p/obj/v=1"))).Call("Provide", enc.Wire{[]byte("hello")})
+	wire := env.fetch(t, interest, false)
+	require.NotEqual(t, wire, env.fetch(t, interest, false))
+}
+
+// BenchmarkLeafNodeCache measures serving repeated Interests of a Data signed with ECDSA.
+func BenchmarkLeafNodeCache(b *testing.B) {
+	for _, cacheData := range []bool{true, false} {
+		b.Run(fmt.Sprintf("CacheData=%t", cacheData), func(b *testing.B) {
+			env := newProducerEnv(b, cacheData)
+			wire, _, _, err := env.engine.Spec().MakeInterest(mustName(b, "/p/obj/v=1"),
+				&ndn.InterestConfig{Lifetime: utils.IdPtr(4 * time.Second)}, nil, nil)
+			require.NoError(b, err)
+			interest := wire.Join()
+			// The first Interest is always handled by OnInterest
+			env.fetch(b, interest, false)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				env.fetch(b, interest, cacheData)
+			}
+		})
+	}
+}
+
+func TestLeafNodeProvideAt(t *testing.T) {
+	const treeJson = `{
+		"nodes": {
+			"/obj/<v=time>": {"type": "LeafNode", "attrs": {"EarlyInterest": "Hold"}}
+		},
+		"policies": [
+			{"type": "Sha256Signer", "path": "/"}
+		]
+	}`
+	executeSchemaTest(t, treeJson, func(env *schemaTestEnv) {
+		noReply := func() {
+			_, err := env.face.Consume()
+			require.Error(t, err)
+		}
+		mNode := env.tree.Match(utils.WithoutErr(enc.NameFromStr("/p/obj/v=1")))
+		require.Nil(t, mNode.Call("ProvideAt", env.timer.Now().Add(time.Second), enc.Wire{[]byte("hello")}))
+
+		// An early Interest is held until the Data is produced
+		require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/obj/v=1", nil)))
+		noReply()
+		env.timer.MoveForward(500 * time.Millisecond)
+		noReply()
+		env.timer.MoveForward(600 * time.Millisecond)
+		wire := utils.WithoutErr(env.face.Consume())
+		require.Equal(t, []byte("hello"), readData(t, enc.Wire{wire}).Content().Join())
+		noReply()
+		// Later Interests are served at once
+		require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/obj/v=1", nil)))
+		require.Equal(t, wire, utils.WithoutErr(env.face.Consume()))
+
+		// A held Interest expiring before the Data is produced is dropped
+		mNode = env.tree.Match(utils.WithoutErr(enc.NameFromStr("/p/obj/v=2")))
+		require.Nil(t, mNode.Call("ProvideAt", 5*time.Second, enc.Wire{[]byte("late")}))
+		require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/obj/v=2", &ndn.InterestConfig{
+			Lifetime: utils.IdPtr(time.Second),
+		})))
+		env.timer.MoveForward(6 * time.Second)
+		noReply()
+		require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/obj/v=2", nil)))
+		require.Equal(t, []byte("late"), readData(t, enc.Wire{utils.WithoutErr(env.face.Consume())}).Content().Join())
+
+		// Scheduling the same name again replaces the schedule
+		mNode = env.tree.Match(utils.WithoutErr(enc.NameFromStr("/p/obj/v=3")))
+		require.Nil(t, mNode.Call("ProvideAt", time.Second, enc.Wire{[]byte("first")}))
+		require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/obj/v=3", nil)))
+		require.Nil(t, mNode.Call("ProvideAt", 2*time.Second, enc.Wire{[]byte("second")}))
+		env.timer.MoveForward(1500 * time.Millisecond)
+		noReply()
+		env.timer.MoveForward(time.Second)
+		require.Equal(t, []byte("second"), readData(t, enc.Wire{utils.WithoutErr(env.face.Consume())}).Content().Join())
+		noReply()
+
+		_, ok := mNode.Call("ProvideAt", "now", enc.Wire{}).(error)
+		require.True(t, ok)
+	})
+}
+
+func TestLeafNodeProvideAtNack(t *testing.T) {
+	const treeJson = `{
+		"nodes": {
+			"/obj/<v=time>": {"type": "LeafNode", "attrs": {"EarlyInterest": "Nack"}}
+		},
+		"policies": [
+			{"type": "Sha256Signer", "path": "/"}
+		]
+	}`
+	executeSchemaTest(t, treeJson, func(env *schemaTestEnv) {
+		mNode := env.tree.Match(utils.WithoutErr(enc.NameFromStr("/p/obj/v=1")))
+		require.Nil(t, mNode.Call("ProvideAt", 2*time.Second, enc.Wire{[]byte("hello")}))
+
+		// An early Interest is nacked, and the AppNack is fresh until the Data is produced
+		env.timer.MoveForward(500 * time.Millisecond)
+		require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/obj/v=1", nil)))
+		nack := readData(t, enc.Wire{utils.WithoutErr(env.face.Consume())})
+		require.Equal(t, ndn.ContentTypeNack, *nack.ContentType())
+		require.Equal(t, 1500*time.Millisecond, *nack.Freshness())
+
+		env.timer.MoveForward(2 * time.Second)
+		require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/obj/v=1", nil)))
+		data := readData(t, enc.Wire{utils.WithoutErr(env.face.Consume())})
+		require.Equal(t, ndn.ContentTypeBlob, *data.ContentType())
+		require.Equal(t, []byte("hello"), data.Content().Join())
+	})
+}
+
+func TestLeafNodeProvideAtDetach(t *testing.T) {
+	const treeJson = `{
+		"nodes": {
+			"/obj/<v=time>": {"type": "LeafNode", "attrs": {"EarlyInterest": "Hold"}},
+			"/other/<v=time>": {"type": "LeafNode", "attrs": {"EarlyInterest": "Hold"}}
+		},
+		"policies": [
+			{"type": "Sha256Signer", "path": "/"}
+		]
+	}`
+	executeSchemaTest(t, treeJson, func(env *schemaTestEnv) {
+		noReply := func() {
+			_, err := env.face.Consume()
+			require.Error(t, err)
+		}
+
+		// The schedule of a removed node is cancelled, and the Interest held for it dropped
+		mNode := env.tree.Match(utils.WithoutErr(enc.NameFromStr("/p/other/v=1")))
+		require.Nil(t, mNode.Call("ProvideAt", time.Second, enc.Wire{[]byte("removed")}))
+		require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/other/v=1", nil)))
+		require.NoError(t, env.tree.RemoveNode(utils.WithoutErr(enc.NamePatternFromStr("/other"))))
+		env.timer.MoveForward(2 * time.Second)
+		noReply()
+
+		// So is the schedule of a detached tree
+		mNode = env.tree.Match(utils.WithoutErr(enc.NameFromStr("/p/obj/v=1")))
+		require.Nil(t, mNode.Call("ProvideAt", time.Second, enc.Wire{[]byte("detached")}))
+		require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/obj/v=1", nil)))
+		env.tree.Detach()
+		env.timer.MoveForward(2 * time.Second)
+		noReply()
+	})
+}
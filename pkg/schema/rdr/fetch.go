@@ -0,0 +1,100 @@
+package rdr
+
+import (
+	"fmt"
+	"math"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	basic_engine "github.com/zjkmxy/go-ndn/pkg/engine/basic"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+// FetchWindow is the initial window of the pipeline used by FetchObject.
+const FetchWindow = 4
+
+// fetchMetadata discovers the latest version of the object name by RDR.
+func fetchMetadata(app ndn.Engine, name enc.Name) (*MetaData, error) {
+	metaName := make(enc.Name, len(name)+1)
+	copy(metaName, name)
+	metaName[len(name)] = enc.NewStringComponent(32, "metadata")
+	intCfg := &ndn.InterestConfig{
+		CanBePrefix: true,
+		MustBeFresh: true,
+		Lifetime:    utils.IdPtr(basic_engine.DefaultInterestLife),
+		Nonce:       utils.ConvertNonce(app.Timer().Nonce()),
+		Retries:     2,
+	}
+	wire, _, finalName, err := app.Spec().MakeInterest(metaName, intCfg, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	type result struct {
+		content enc.Wire
+		err     error
+	}
+	ch := make(chan result, 1)
+	err = app.Express(finalName, intCfg, wire,
+		func(res ndn.InterestResult, data ndn.Data, _ enc.Wire, _ enc.Wire, nackReason uint64) {
+			switch res {
+			case ndn.InterestResultData:
+				ch <- result{content: data.Content()}
+			case ndn.InterestResultNack:
+				ch <- result{err: fmt.Errorf("metadata is nacked for %d", nackReason)}
+			case ndn.InterestResultTimeout:
+				ch <- result{err: ndn.ErrDeadlineExceed}
+			default:
+				ch <- result{err: fmt.Errorf("unknown result: %v", res)}
+			}
+		})
+	if err != nil {
+		return nil, err
+	}
+	ret := <-ch
+	if ret.err != nil {
+		return nil, ret.err
+	}
+	metadata, err := ParseMetaData(enc.NewWireReader(ret.content), true)
+	if err != nil {
+		return nil, err
+	}
+	if len(metadata.Name) <= len(name) || !name.IsPrefix(metadata.Name) {
+		return nil, ndn.ErrInvalidValue{Item: "metadata name", Value: metadata.Name}
+	}
+	return metadata, nil
+}
+
+// FetchObject fetches the latest version of the segmented object name, e.g. produced by an RdrNode.
+// The version is discovered from the RDR metadata at /<name>/32=metadata,
+// then the segments are fetched by a ConsumerPipeline until the FinalBlockId.
+// It blocks until the object is fetched. Signatures are not validated.
+func FetchObject(app ndn.Engine, name enc.Name) (enc.Wire, error) {
+	metadata, err := fetchMetadata(app, name)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch the metadata: %w", err)
+	}
+	// Without FinalBlockId in the metadata, the pipeline learns it from the segments.
+	var last uint64 = math.MaxUint64
+	if len(metadata.FinalBlockID) > 0 {
+		finalBlockId, err := enc.ComponentFromBytes(metadata.FinalBlockID)
+		if err != nil || finalBlockId.Typ != enc.TypeSegmentNameComponent {
+			return nil, ndn.ErrInvalidValue{Item: "FinalBlockId", Value: metadata.FinalBlockID}
+		}
+		last = finalBlockId.NumberVal()
+	}
+
+	type result struct {
+		content enc.Wire
+		err     error
+	}
+	ch := make(chan result, 1)
+	pipeline := basic_engine.NewConsumerPipeline(app, FetchWindow)
+	err = pipeline.Fetch(metadata.Name, 0, last, func(content enc.Wire, err error) {
+		ch <- result{content: content, err: err}
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret := <-ch
+	return ret.content, ret.err
+}
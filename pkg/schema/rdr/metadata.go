@@ -0,0 +1,255 @@
+package rdr
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/schema"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+// RdrMetadataNode answers RDR discovery Interests /<name>/32=metadata with the latest version of <name>.
+// It is supposed to be put at 32=metadata under the object node.
+// When attached, it listens to the Data produced under its versioned siblings, e.g. <v=versionNumber>,
+// so the latest version is updated whenever a new version is provided.
+// The metadata packet is produced in real time by the child <v=versionNumber>/seg=0.
+type RdrMetadataNode struct {
+	schema.ExpressPoint
+
+	MetaFreshness time.Duration
+
+	// latest are the latest versions, indexed by the object name in TLV
+	lock   sync.Mutex
+	latest map[string]*metadataEntry
+
+	onVersionSaved *schema.Callback
+	versionNodes   []*schema.Node
+}
+
+type metadataEntry struct {
+	metadata *MetaData
+	version  uint64
+	// wire is the last produced metadata packet, reused until it becomes stale
+	wire       enc.Wire
+	freshUntil time.Time
+}
+
+func (n *RdrMetadataNode) NodeImplTrait() schema.NodeImpl {
+	return n
+}
+
+func CreateRdrMetadataNode(node *schema.Node) schema.NodeImpl {
+	ret := &RdrMetadataNode{
+		ExpressPoint:  *schema.CreateExpressPoint(node).(*schema.ExpressPoint),
+		MetaFreshness: 10 * time.Millisecond,
+		latest:        make(map[string]*metadataEntry),
+	}
+	ret.CanBePrefix = true
+	ret.MustBeFresh = true
+	ret.onVersionSaved = utils.IdPtr(ret.onSaveVersion)
+	path, _ := enc.NamePatternFromStr("<v=versionNumber>/seg=0")
+	node.PutNode(path, schema.LeafNodeDesc)
+	ret.OnInt.Add(utils.IdPtr(ret.onMetadataInt))
+	ret.OnAttachEvt.Add(utils.IdPtr(ret.onAttach))
+	ret.OnDetachEvt.Add(utils.IdPtr(ret.onDetach))
+	return ret
+}
+
+// onAttach listens to the storage of all nodes under the versioned siblings
+func (n *RdrMetadataNode) onAttach(event *schema.Event) any {
+	parent := n.Node.Parent()
+	if parent == nil {
+		return nil
+	}
+	var listen func(node *schema.Node)
+	listen = func(node *schema.Node) {
+		if target := node.GetEvent(schema.PropOnSaveStorage); target != nil {
+			target.Add(n.onVersionSaved)
+			n.versionNodes = append(n.versionNodes, node)
+		}
+		for _, c := range node.Children() {
+			listen(c)
+		}
+	}
+	for _, c := range parent.Children() {
+		if pat, ok := c.UpEdge().(enc.Pattern); ok && pat.Typ == enc.TypeVersionNameComponent {
+			listen(c)
+		}
+	}
+	return nil
+}
+
+func (n *RdrMetadataNode) onDetach(event *schema.Event) any {
+	for _, node := range n.versionNodes {
+		node.RemoveEventListener(schema.PropOnSaveStorage, n.onVersionSaved)
+	}
+	n.versionNodes = nil
+	return nil
+}
+
+// onSaveVersion updates the latest version when a Data is produced under a versioned sibling
+func (n *RdrMetadataNode) onSaveVersion(event *schema.Event) any {
+	if event.SelfProduced == nil || !*event.SelfProduced {
+		return nil
+	}
+	verNode := event.TargetNode
+	for verNode != nil && verNode.Parent() != n.Node.Parent() {
+		verNode = verNode.Parent()
+	}
+	if verNode == nil {
+		return nil
+	}
+	name := event.Target.Name
+	verIdx := int(verNode.Depth()) - 1
+	if verIdx < 0 || verIdx >= len(name) || name[verIdx].Typ != enc.TypeVersionNameComponent {
+		return nil
+	}
+	metadata := &MetaData{
+		Name: make(enc.Name, verIdx+1),
+	}
+	copy(metadata.Name, name)
+	if event.DataConfig != nil && event.DataConfig.FinalBlockID != nil {
+		metadata.FinalBlockID = event.DataConfig.FinalBlockID.Bytes()
+	}
+	n.Update(metadata)
+	return nil
+}
+
+// Update sets the latest version of an object, given by metadata.Name as /<name>/<version>.
+// An older version than the current one is ignored.
+func (n *RdrMetadataNode) Update(metadata *MetaData) error {
+	nameLen := len(metadata.Name)
+	if nameLen == 0 || metadata.Name[nameLen-1].Typ != enc.TypeVersionNameComponent {
+		return ndn.ErrInvalidValue{Item: "metadata name", Value: metadata.Name}
+	}
+	version := metadata.Name[nameLen-1].NumberVal()
+	key := metadata.Name[:nameLen-1].TlvStr()
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if cur, ok := n.latest[key]; ok && cur.version > version {
+		return nil
+	}
+	n.latest[key] = &metadataEntry{
+		metadata: metadata,
+		version:  version,
+	}
+	return nil
+}
+
+// Latest returns the metadata of the latest version of the object matched by mNode's parent.
+// Returns nil if no version is known.
+func (n *RdrMetadataNode) Latest(mNode schema.MatchedNode) *MetaData {
+	if mNode.Node != n.Node {
+		panic("NTSchema tree compromised.")
+	}
+	nameLen := len(mNode.Name)
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if entry, ok := n.latest[mNode.Name[:nameLen-1].TlvStr()]; ok {
+		return entry.metadata
+	}
+	return nil
+}
+
+// onMetadataInt replies to the discovery Interests with the latest version
+func (n *RdrMetadataNode) onMetadataInt(event *schema.Event) any {
+	mNode := event.Target
+	logger := mNode.Logger("RdrMetadataNode")
+	nameLen := len(mNode.Name)
+	now := n.Node.Engine().Timer().Now()
+
+	n.lock.Lock()
+	entry, ok := n.latest[mNode.Name[:nameLen-1].TlvStr()]
+	if !ok {
+		n.lock.Unlock()
+		logger.Debug("No version is provided yet.")
+		return nil
+	}
+	wire := entry.wire
+	if wire == nil || !entry.freshUntil.After(now) {
+		metaName := make(enc.Name, nameLen+2)
+		copy(metaName, mNode.Name)
+		metaName[nameLen] = enc.NewVersionComponent(utils.MakeTimestamp(now))
+		metaName[nameLen+1] = enc.NewSegmentComponent(0)
+		metaDataCfg := &ndn.DataConfig{
+			ContentType:  utils.IdPtr(ndn.ContentTypeBlob),
+			Freshness:    utils.IdPtr(n.MetaFreshness),
+			FinalBlockID: utils.IdPtr(enc.NewSegmentComponent(0)),
+		}
+		wire, _ = mNode.Refine(metaName).Call("Provide", entry.metadata.Encode(), metaDataCfg).(enc.Wire)
+		entry.wire = wire
+		entry.freshUntil = now.Add(n.MetaFreshness)
+	}
+	n.lock.Unlock()
+
+	if wire == nil {
+		logger.Error("Unable to produce the metadata packet.")
+		return true
+	}
+	if err := event.Reply(wire); err != nil {
+		logger.Errorf("Unable to reply Interest. Drop: %+v", err)
+	}
+	return true
+}
+
+func (n *RdrMetadataNode) CastTo(ptr any) any {
+	switch ptr.(type) {
+	case (*RdrMetadataNode):
+		return n
+	case (*schema.ExpressPoint):
+		return &(n.ExpressPoint)
+	case (*schema.BaseNodeImpl):
+		return &(n.BaseNodeImpl)
+	default:
+		return nil
+	}
+}
+
+var RdrMetadataNodeDesc *schema.NodeImplDesc
+
+func initRdrMetadataNodeDesc() {
+	RdrMetadataNodeDesc = &schema.NodeImplDesc{
+		ClassName:  "RdrMetadataNode",
+		Properties: make(map[schema.PropKey]schema.PropertyDesc, len(schema.ExpressPointDesc.Properties)+1),
+		Events:     make(map[schema.PropKey]schema.EventGetter, len(schema.ExpressPointDesc.Events)),
+		Functions:  make(map[string]schema.NodeFunc, len(schema.ExpressPointDesc.Functions)+2),
+		Create:     CreateRdrMetadataNode,
+	}
+	for k, v := range schema.ExpressPointDesc.Properties {
+		RdrMetadataNodeDesc.Properties[k] = v
+	}
+	RdrMetadataNodeDesc.Properties["MetaFreshness"] = schema.TimePropertyDesc("MetaFreshness")
+	for k, v := range schema.ExpressPointDesc.Events {
+		RdrMetadataNodeDesc.Events[k] = v
+	}
+	for k, v := range schema.ExpressPointDesc.Functions {
+		RdrMetadataNodeDesc.Functions[k] = v
+	}
+	RdrMetadataNodeDesc.Functions["Update"] = func(mNode schema.MatchedNode, args ...any) any {
+		if len(args) != 1 {
+			err := fmt.Errorf("RdrMetadataNode.Update requires 1 arguments but got %d", len(args))
+			mNode.Logger("RdrMetadataNode").Error(err.Error())
+			return err
+		}
+		metadata, ok := args[0].(*MetaData)
+		if !ok || metadata == nil {
+			err := ndn.ErrInvalidValue{Item: "metadata", Value: args[0]}
+			mNode.Logger("RdrMetadataNode").Error(err.Error())
+			return err
+		}
+		return schema.QueryInterface[*RdrMetadataNode](mNode.Node).Update(metadata)
+	}
+	RdrMetadataNodeDesc.Functions["Latest"] = func(mNode schema.MatchedNode, args ...any) any {
+		if len(args) != 0 {
+			err := fmt.Errorf("RdrMetadataNode.Latest requires 0 arguments but got %d", len(args))
+			mNode.Logger("RdrMetadataNode").Error(err.Error())
+			return err
+		}
+		return schema.QueryInterface[*RdrMetadataNode](mNode.Node).Latest(mNode)
+	}
+	schema.RegisterNodeImpl(RdrMetadataNodeDesc)
+}
@@ -0,0 +1,63 @@
+package rdr_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/schema/rdr"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+func TestRdrMetadataNodeLatest(t *testing.T) {
+	const treeJson = `{
+		"nodes": {
+			"/obj/<v=versionNumber>": {"type": "LeafNode", "attrs": {}},
+			"/obj/32=metadata": {"type": "RdrMetadataNode", "attrs": {"MetaFreshness": 10}}
+		},
+		"policies": [
+			{"type": "Sha256Signer", "path": "/"}
+		]
+	}`
+	executeTest(t, treeJson, func(env *testEnv) {
+		metaName := utils.WithoutErr(enc.NameFromStr("/p/obj/32=metadata"))
+		discover := func() enc.Name {
+			data := env.express(t, metaName, &ndn.InterestConfig{
+				CanBePrefix: true,
+				MustBeFresh: true,
+				Lifetime:    utils.IdPtr(4 * time.Second),
+			})
+			if data == nil {
+				return nil
+			}
+			require.True(t, metaName.IsPrefix(data.Name()))
+			metadata, err := rdr.ParseMetaData(enc.NewWireReader(data.Content()), true)
+			require.NoError(t, err)
+			return metadata.Name
+		}
+		provide := func(name string) {
+			mNode := env.tree.Match(utils.WithoutErr(enc.NameFromStr(name)))
+			require.NotNil(t, mNode.Call("Provide", enc.Wire{[]byte(name)}))
+		}
+
+		// Nothing is replied before any version is provided
+		require.Nil(t, discover())
+
+		// The metadata names the newest version after two Provide calls
+		provide("/p/obj/v=1")
+		provide("/p/obj/v=2")
+		require.Equal(t, "/p/obj/v=2", discover().String())
+		latest := env.tree.Match(metaName).Call("Latest").(*rdr.MetaData)
+		require.Equal(t, "/p/obj/v=2", latest.Name.String())
+
+		// An older version does not replace it, a newer one does once the metadata packet becomes stale
+		provide("/p/obj/v=1")
+		env.timer.MoveForward(20 * time.Millisecond)
+		require.Equal(t, "/p/obj/v=2", discover().String())
+		provide("/p/obj/v=3")
+		env.timer.MoveForward(20 * time.Millisecond)
+		require.Equal(t, "/p/obj/v=3", discover().String())
+	})
+}
@@ -3,6 +3,8 @@ package rdr
 import (
 	"crypto/sha256"
 	"fmt"
+	"slices"
+	"sync"
 	"time"
 
 	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
@@ -12,6 +14,11 @@ import (
 )
 
 // SegmentedNode handles the segmentation and reassembly
+// Provided segments are kept by the node to serve Interests, so no storage policy is required.
+// Only the latest version of an object is kept: providing an object drops the segments of the objects
+// whose names differ from it only in version components, as well as the ones provided before under the same name.
+// An object may also be named beyond the pattern of the node, by providing it with a MatchedNode given by
+// Tree.MatchPrefix. Its segments are then served to Interests that the tree matches to the node with a Remainder.
 type SegmentedNode struct {
 	schema.BaseNodeImpl
 
@@ -23,6 +30,19 @@ type SegmentedNode struct {
 	SegmentSize         uint64
 	MaxRetriesOnFailure uint64
 	Pipeline            string
+
+	// objects are the produced objects, indexed by the name without version components in TLV
+	segLock sync.RWMutex
+	objects map[string]segmentedObject
+	// segNode is the child node of the segments
+	segNode *schema.Node
+}
+
+type segmentedObject struct {
+	// name is the name of the object in TLV
+	name       string
+	segments   []enc.Wire
+	freshUntil time.Time
 }
 
 func (n *SegmentedNode) NodeImplTrait() schema.NodeImpl {
@@ -44,35 +64,83 @@ func CreateSegmentedNode(node *schema.Node) schema.NodeImpl {
 		SegmentSize:         8000,
 		MaxRetriesOnFailure: 15,
 		Pipeline:            "SinglePacket",
+		objects:             make(map[string]segmentedObject),
 	}
 	path, _ := enc.NamePatternFromStr("<seg=segmentNumber>")
-	node.PutNode(path, schema.LeafNodeDesc)
+	ret.segNode = node.PutNode(path, schema.LeafNodeDesc)
+	ret.segNode.AddEventListener(schema.PropOnSearchStorage, utils.IdPtr(ret.onSearchSegment))
 	return ret
 }
 
-func (n *SegmentedNode) Provide(mNode schema.MatchedNode, content enc.Wire, needManifest bool) any {
-	if mNode.Node != n.Node {
-		panic("NTSchema tree compromised.")
+// objectKey returns the key of the object name in objects, which is the name without version components.
+func objectKey(name enc.Name) string {
+	key := make(enc.Name, 0, len(name))
+	for _, c := range name {
+		if c.Typ != enc.TypeVersionNameComponent {
+			key = append(key, c)
+		}
 	}
+	return key.TlvStr()
+}
 
-	var wireIdx, bufferIdx int = 0, 0
-	var ret []enc.Buffer = nil
-	// Segmentation
-	segCnt := (content.Length() + n.SegmentSize - 1) / n.SegmentSize
-	if needManifest {
-		ret = make([]enc.Buffer, segCnt)
+// onSearchSegment serves the segments produced by Provide
+func (n *SegmentedNode) onSearchSegment(event *schema.Event) any {
+	name := event.Target.Name
+	if len(name) == 0 {
+		return nil
 	}
-	newName := make(enc.Name, len(mNode.Name)+1)
-	copy(newName, mNode.Name)
+	return n.segment(name[:len(name)-1], name[len(name)-1].NumberVal(), event.IntConfig.MustBeFresh)
+}
 
-	dataCfg := &ndn.DataConfig{
-		ContentType:  utils.IdPtr(n.ContentType),
-		Freshness:    utils.IdPtr(n.Freshness),
-		FinalBlockID: utils.IdPtr(enc.NewSegmentComponent(segCnt - 1)),
+// segment returns the segment segNo of the object objName, or nil if it is not provided or not fresh enough.
+func (n *SegmentedNode) segment(objName enc.Name, segNo uint64, mustBeFresh bool) enc.Wire {
+	n.segLock.RLock()
+	defer n.segLock.RUnlock()
+	obj, ok := n.objects[objectKey(objName)]
+	if !ok || obj.name != objName.TlvStr() || segNo >= uint64(len(obj.segments)) || obj.segments[segNo] == nil {
+		return nil
+	}
+	if mustBeFresh && !obj.freshUntil.After(n.Node.Engine().Timer().Now()) {
+		return nil
+	}
+	return obj.segments[segNo]
+}
+
+// OnPrefixInterest serves the segments of an object named beyond the pattern of the node.
+// The Remainder of mNode is the rest of the object name followed by the segment number.
+// A CanBePrefix Interest may also name the object itself, which is replied with its first segment.
+func (n *SegmentedNode) OnPrefixInterest(
+	interest ndn.Interest, rawInterest enc.Wire, sigCovered enc.Wire,
+	reply ndn.ReplyFunc, deadline time.Time, mNode *schema.MatchedNode,
+) {
+	objName := append(slices.Clone(mNode.Name), mNode.Remainder...)
+	segNo := uint64(0)
+	if last := objName[len(objName)-1]; last.Typ == enc.TypeSegmentNameComponent {
+		objName = objName[:len(objName)-1]
+		segNo = last.NumberVal()
+	} else if !interest.CanBePrefix() {
+		mNode.Logger("SegmentedNode").Warn("Unexpected Interest. Drop.")
+		return
+	}
+	wire := n.segment(objName, segNo, interest.MustBeFresh())
+	if wire == nil {
+		return
+	}
+	if err := reply(wire); err != nil {
+		mNode.Logger("SegmentedNode").Errorf("Unable to reply Interest. Drop: %+v", err)
+	}
+}
+
+func (n *SegmentedNode) Provide(mNode schema.MatchedNode, content enc.Wire, needManifest bool) any {
+	if mNode.Node != n.Node {
+		panic("NTSchema tree compromised.")
 	}
 
+	var wireIdx, bufferIdx int = 0, 0
+	// Segmentation. Empty content is still given one segment.
+	segCnt := max((content.Length()+n.SegmentSize-1)/n.SegmentSize, 1)
+	segments := make([]enc.Wire, segCnt)
 	for i := uint64(0); i < segCnt; i++ {
-		newName[len(mNode.Name)] = enc.NewSegmentComponent(i)
 		pktContent := enc.Wire{}
 		remSize := n.SegmentSize
 		for remSize > 0 && wireIdx < len(content) && bufferIdx < len(content[wireIdx]) {
@@ -85,9 +153,60 @@ func (n *SegmentedNode) Provide(mNode schema.MatchedNode, content enc.Wire, need
 				bufferIdx = 0
 			}
 		}
+		segments[i] = pktContent
+	}
+	return n.provideSegments(mNode, segments, needManifest)
+}
+
+// ProvideSegments produces one segment for each element of segments, which is used as the content as is.
+// It allows the producer to prepare every segment on its own, e.g. encrypting them one by one
+// so they can be decrypted independently. SegmentSize is not applied.
+// The return value is the same as Provide.
+func (n *SegmentedNode) ProvideSegments(mNode schema.MatchedNode, segments []enc.Wire, needManifest bool) any {
+	if mNode.Node != n.Node {
+		panic("NTSchema tree compromised.")
+	}
+	if len(segments) == 0 {
+		segments = []enc.Wire{{}}
+	}
+	return n.provideSegments(mNode, segments, needManifest)
+}
+
+func (n *SegmentedNode) provideSegments(mNode schema.MatchedNode, segments []enc.Wire, needManifest bool) any {
+	var ret []enc.Buffer = nil
+	segCnt := uint64(len(segments))
+	if needManifest {
+		ret = make([]enc.Buffer, segCnt)
+	}
+	// The object name includes the components beyond the node given by MatchPrefix
+	objName := append(slices.Clone(mNode.Name), mNode.Remainder...)
+	newName := make(enc.Name, len(objName)+1)
+	copy(newName, objName)
+
+	dataCfg := &ndn.DataConfig{
+		ContentType:  utils.IdPtr(n.ContentType),
+		Freshness:    utils.IdPtr(n.Freshness),
+		FinalBlockID: utils.IdPtr(enc.NewSegmentComponent(segCnt - 1)),
+	}
+
+	obj := segmentedObject{
+		name:     objName.TlvStr(),
+		segments: make([]enc.Wire, segCnt),
+	}
+	for i, pktContent := range segments {
+		newName[len(objName)] = enc.NewSegmentComponent(uint64(i))
 		// generate the data packet
 		newMNode := mNode.Refine(newName)
-		dataWire := newMNode.Call("Provide", pktContent, dataCfg).(enc.Wire)
+		if len(mNode.Remainder) > 0 {
+			// The name does not match the pattern of the segment node, which still produces the Data
+			newMNode = &schema.MatchedNode{Node: n.segNode, Name: newName, Matching: mNode.Matching}
+		}
+		dataWire, ok := newMNode.Call("Provide", pktContent, dataCfg).(enc.Wire)
+		if !ok || dataWire == nil {
+			mNode.Logger("SegmentedNode").Errorf("Unable to produce segment %d", i)
+			continue
+		}
+		obj.segments[i] = dataWire
 
 		// compute implicit sha256 for manifest if needed
 		if needManifest {
@@ -98,6 +217,11 @@ func (n *SegmentedNode) Provide(mNode schema.MatchedNode, content enc.Wire, need
 			ret[i] = h.Sum(nil)
 		}
 	}
+	// The object replaces its older versions at once
+	obj.freshUntil = n.Node.Engine().Timer().Now().Add(n.Freshness)
+	n.segLock.Lock()
+	n.objects[objectKey(objName)] = obj
+	n.segLock.Unlock()
 	mNode.Logger("SegmentedNode").Debugf("Segmented into %d segments \n", segCnt)
 	if needManifest {
 		return ret
@@ -220,7 +344,6 @@ func (n *SegmentedNode) CastTo(ptr any) any {
 type RdrNode struct {
 	schema.BaseNodeImpl
 
-	MetaFreshness     time.Duration
 	MaxRetriesForMeta uint64
 }
 
@@ -235,15 +358,12 @@ func CreateRdrNode(node *schema.Node) schema.NodeImpl {
 			OnAttachEvt: &schema.EventTarget{},
 			OnDetachEvt: &schema.EventTarget{},
 		},
-		MetaFreshness:     10 * time.Millisecond,
 		MaxRetriesForMeta: 15,
 	}
 	path, _ := enc.NamePatternFromStr("<v=versionNumber>")
 	node.PutNode(path, SegmentedNodeDesc)
 	path, _ = enc.NamePatternFromStr("32=metadata")
-	node.PutNode(path, schema.ExpressPointDesc)
-	path, _ = enc.NamePatternFromStr("32=metadata/<v=versionNumber>/seg=0")
-	node.PutNode(path, schema.LeafNodeDesc)
+	node.PutNode(path, RdrMetadataNodeDesc)
 	return ret
 }
 
@@ -252,19 +372,12 @@ func (n *RdrNode) Provide(mNode schema.MatchedNode, content enc.Wire) uint64 {
 		panic("NTSchema tree compromised.")
 	}
 
-	// NOTE: This version of RDR node puts the metadata into storage (same as python-ndn's cmd_serve_rdrcontent).
-	// It is possible to serve metadata packet in real time, but needs special handling for matching
-	// There are two ways:
-	// 1. Ask the storage to provide a function (via Node's event) to search with version
-	// 2. Have a mapping between matching and version
 	timer := mNode.Node.Engine().Timer()
 	ver := utils.MakeTimestamp(timer.Now())
 	nameLen := len(mNode.Name)
-	metaName := make(enc.Name, nameLen+3)
+	metaName := make(enc.Name, nameLen+1)
 	copy(metaName, mNode.Name) // Note this does not actually copies the component values
 	metaName[nameLen] = enc.NewStringComponent(32, "metadata")
-	metaName[nameLen+1] = enc.NewVersionComponent(ver)
-	metaName[nameLen+2] = enc.NewSegmentComponent(0)
 	metaMNode := mNode.Refine(metaName)
 
 	dataName := make(enc.Name, nameLen+1)
@@ -275,18 +388,13 @@ func (n *RdrNode) Provide(mNode schema.MatchedNode, content enc.Wire) uint64 {
 	// generate segmented data
 	segCnt := dataMNode.Call("Provide", content).(uint64)
 
-	// generate metadata
-	metaDataCfg := &ndn.DataConfig{
-		ContentType:  utils.IdPtr(ndn.ContentTypeBlob),
-		Freshness:    utils.IdPtr(n.MetaFreshness),
-		FinalBlockID: utils.IdPtr(enc.NewSegmentComponent(0)),
-	}
+	// update metadata, which is served by the metadata node in real time
 	metaData := &MetaData{
 		Name:         dataName,
 		FinalBlockID: enc.NewSegmentComponent(segCnt - 1).Bytes(),
 		Size:         utils.IdPtr(content.Length()),
 	}
-	metaMNode.Call("Provide", metaData.Encode(), metaDataCfg)
+	metaMNode.Call("Update", metaData)
 
 	return ver
 }
@@ -585,6 +693,7 @@ func initRdrNodes() {
 			"ValidDuration":       schema.TimePropertyDesc("ValidDur"),
 			"MustBeFresh":         schema.DefaultPropertyDesc("MustBeFresh"),
 			"SegmentSize":         schema.DefaultPropertyDesc("SegmentSize"),
+			"MaxSegmentSize":      schema.DefaultPropertyDesc("SegmentSize"),
 			"MaxRetriesOnFailure": schema.DefaultPropertyDesc("MaxRetriesOnFailure"),
 			"Pipeline":            schema.DefaultPropertyDesc("Pipeline"),
 		},
@@ -616,6 +725,29 @@ func initRdrNodes() {
 				}
 				return schema.QueryInterface[*SegmentedNode](mNode.Node).Provide(mNode, content, needManifest)
 			},
+			"ProvideSegments": func(mNode schema.MatchedNode, args ...any) any {
+				if len(args) < 1 || len(args) > 2 {
+					err := fmt.Errorf("SegmentedNode.ProvideSegments requires 1~2 arguments but got %d", len(args))
+					mNode.Logger("SegmentedNode").Error(err.Error())
+					return err
+				}
+				segments, ok := args[0].([]enc.Wire)
+				if !ok && args[0] != nil {
+					err := ndn.ErrInvalidValue{Item: "segments", Value: args[0]}
+					mNode.Logger("SegmentedNode").Error(err.Error())
+					return err
+				}
+				var needManifest bool = false
+				if len(args) >= 2 {
+					needManifest, ok = args[1].(bool)
+					if !ok && args[1] != nil {
+						err := ndn.ErrInvalidValue{Item: "needManifest", Value: args[1]}
+						mNode.Logger("SegmentedNode").Error(err.Error())
+						return err
+					}
+				}
+				return schema.QueryInterface[*SegmentedNode](mNode.Node).ProvideSegments(mNode, segments, needManifest)
+			},
 			"Need": func(mNode schema.MatchedNode, args ...any) any {
 				if len(args) < 1 || len(args) > 2 {
 					err := fmt.Errorf("SegmentedNode.Need requires 1~2 arguments but got %d", len(args))
@@ -665,7 +797,7 @@ func initRdrNodes() {
 	RdrNodeDesc = &schema.NodeImplDesc{
 		ClassName: "RdrNode",
 		Properties: map[schema.PropKey]schema.PropertyDesc{
-			"MetaFreshness":       schema.TimePropertyDesc("MetaFreshness"),
+			"MetaFreshness":       schema.SubNodePropertyDesc("32=metadata", "MetaFreshness"),
 			"MaxRetriesForMeta":   schema.DefaultPropertyDesc("MaxRetriesForMeta"),
 			"MetaLifetime":        schema.SubNodePropertyDesc("32=metadata", schema.PropLifetime),
 			"ContentType":         schema.SubNodePropertyDesc("<v=versionNumber>", "ContentType"),
@@ -674,6 +806,7 @@ func initRdrNodes() {
 			"ValidDuration":       schema.SubNodePropertyDesc("<v=versionNumber>", "ValidDuration"),
 			"MustBeFresh":         schema.SubNodePropertyDesc("<v=versionNumber>", "MustBeFresh"),
 			"SegmentSize":         schema.SubNodePropertyDesc("<v=versionNumber>", "SegmentSize"),
+			"MaxSegmentSize":      schema.SubNodePropertyDesc("<v=versionNumber>", "SegmentSize"),
 			"MaxRetriesOnFailure": schema.SubNodePropertyDesc("<v=versionNumber>", "MaxRetriesOnFailure"),
 			"Pipeline":            schema.SubNodePropertyDesc("<v=versionNumber>", "Pipeline"),
 		},
@@ -758,6 +891,7 @@ func initRdrNodes() {
 			"ValidDuration":         schema.SubNodePropertyDesc("32=data", "ValidDuration"),
 			"MustBeFresh":           schema.SubNodePropertyDesc("32=data", "MustBeFresh"),
 			"SegmentSize":           schema.SubNodePropertyDesc("32=data", "SegmentSize"),
+			"MaxSegmentSize":        schema.SubNodePropertyDesc("32=data", "SegmentSize"),
 			"MaxRetriesOnFailure":   schema.SubNodePropertyDesc("32=data", "MaxRetriesOnFailure"),
 			"Pipeline":              schema.SubNodePropertyDesc("32=data", "Pipeline"),
 		},
@@ -810,5 +944,6 @@ func initRdrNodes() {
 }
 
 func init() {
+	initRdrMetadataNodeDesc()
 	initRdrNodes()
 }
@@ -0,0 +1,207 @@
+package rdr_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	basic_engine "github.com/zjkmxy/go-ndn/pkg/engine/basic"
+	"github.com/zjkmxy/go-ndn/pkg/engine/dummy"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/ndn/spec_2022"
+	"github.com/zjkmxy/go-ndn/pkg/schema"
+	_ "github.com/zjkmxy/go-ndn/pkg/schema/rdr"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+// notifyFace is a dummy face notifying every packet sent, which may be sent by another goroutine.
+type notifyFace struct {
+	*dummy.DummyFace
+	sent chan struct{}
+}
+
+func (f *notifyFace) Send(pkt enc.Wire) error {
+	err := f.DummyFace.Send(pkt)
+	f.sent <- struct{}{}
+	return err
+}
+
+type testEnv struct {
+	face   *notifyFace
+	timer  *dummy.Timer
+	engine *basic_engine.Engine
+	tree   *schema.Tree
+}
+
+// executeTest attaches the schema tree described by treeJson to /p of a dummy engine.
+func executeTest(t *testing.T, treeJson string, main func(env *testEnv)) {
+	utils.SetTestingT(t)
+
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool {
+		return true
+	}
+
+	face := &notifyFace{DummyFace: dummy.NewDummyFace(), sent: make(chan struct{}, 16)}
+	timer := dummy.NewTimer()
+	engine := basic_engine.NewEngine(face, timer, sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(t, engine.Start())
+
+	tree := schema.CreateFromJson(treeJson, nil)
+	require.NoError(t, tree.Attach(utils.WithoutErr(enc.NameFromStr("/p")), engine))
+
+	main(&testEnv{face: face, timer: timer, engine: engine, tree: tree})
+
+	tree.Detach()
+	require.NoError(t, engine.Shutdown())
+}
+
+// express feeds an Interest of name to the engine and returns the Data replied, or nil if there is none.
+func (env *testEnv) express(t *testing.T, name enc.Name, config *ndn.InterestConfig) *spec_2022.Data {
+	if config == nil {
+		config = &ndn.InterestConfig{Lifetime: utils.IdPtr(4 * time.Second)}
+	}
+	wire, _, _, err := env.engine.Spec().MakeInterest(name, config, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, env.face.FeedPacket(wire.Join()))
+	// The reply may be sent by an OnInterest handler in another goroutine
+	select {
+	case <-env.face.sent:
+	case <-time.After(100 * time.Millisecond):
+		return nil
+	}
+	buf, err := env.face.Consume()
+	require.NoError(t, err)
+	pkt, _, err := spec_2022.ReadPacket(enc.NewBufferReader(buf))
+	require.NoError(t, err)
+	require.NotNil(t, pkt.Data)
+	return pkt.Data
+}
+
+func TestSegmentedNodeProvide(t *testing.T) {
+	const treeJson = `{
+		"nodes": {
+			"/obj": {"type": "SegmentedNode", "attrs": {"MaxSegmentSize": 500}}
+		},
+		"policies": [
+			{"type": "Sha256Signer", "path": "/obj/<seg=segmentNumber>"}
+		]
+	}`
+	executeTest(t, treeJson, func(env *testEnv) {
+		content := make([]byte, 50000)
+		rand.Read(content)
+		objName := utils.WithoutErr(enc.NameFromStr("/p/obj"))
+		require.Equal(t, uint64(100), env.tree.Match(objName).Call("Provide", enc.Wire{content}))
+
+		// Every segment is served, including the ones not cached by the segment node
+		received := bytes.Buffer{}
+		for i := uint64(0); i < 100; i++ {
+			name := append(objName[:2:2], enc.NewSegmentComponent(i))
+			data := env.express(t, name, nil)
+			require.NotNil(t, data)
+			require.True(t, name.Equal(data.Name()))
+			require.Equal(t, enc.NewSegmentComponent(99), *data.FinalBlockID())
+			require.Equal(t, 500, len(data.Content().Join()))
+			received.Write(data.Content().Join())
+		}
+		require.Equal(t, content, received.Bytes())
+		require.Nil(t, env.express(t, append(objName[:2:2], enc.NewSegmentComponent(100)), nil))
+
+		// The object is reassembled by Need
+		result := <-env.tree.Match(objName).Call("NeedChan").(chan schema.NeedResult)
+		require.Equal(t, ndn.InterestResultData, result.Status)
+		require.Equal(t, content, result.Content.Join())
+
+		// Stale segments are not served to MustBeFresh Interests
+		env.timer.MoveForward(11 * time.Second)
+		require.Nil(t, env.express(t, append(objName[:2:2], enc.NewSegmentComponent(0)), &ndn.InterestConfig{
+			MustBeFresh: true,
+			Lifetime:    utils.IdPtr(4 * time.Second),
+		}))
+	})
+}
+
+func TestSegmentedNodeRemainder(t *testing.T) {
+	const treeJson = `{
+		"nodes": {
+			"/files": {"type": "SegmentedNode", "attrs": {"MaxSegmentSize": 100}}
+		},
+		"policies": [
+			{"type": "Sha256Signer", "path": "/files/<seg=segmentNumber>"}
+		]
+	}`
+	executeTest(t, treeJson, func(env *testEnv) {
+		// The object is named beyond the pattern of the node
+		objName := utils.WithoutErr(enc.NameFromStr("/p/files/docs/a.txt"))
+		mNode := env.tree.MatchPrefix(objName)
+		require.Equal(t, "/docs/a.txt", mNode.Remainder.String())
+		content := make([]byte, 250)
+		rand.Read(content)
+		require.Equal(t, uint64(3), mNode.Call("Provide", enc.Wire{content}))
+
+		// Its segments are served to the node with the remainder of their names
+		received := bytes.Buffer{}
+		for i := uint64(0); i < 3; i++ {
+			name := append(objName[:4:4], enc.NewSegmentComponent(i))
+			data := env.express(t, name, nil)
+			require.NotNil(t, data)
+			require.True(t, name.Equal(data.Name()))
+			require.Equal(t, enc.NewSegmentComponent(2), *data.FinalBlockID())
+			received.Write(data.Content().Join())
+		}
+		require.Equal(t, content, received.Bytes())
+		require.Nil(t, env.express(t, append(objName[:4:4], enc.NewSegmentComponent(3)), nil))
+		other := utils.WithoutErr(enc.NameFromStr("/p/files/docs/b.txt/seg=0"))
+		require.Nil(t, env.express(t, other, nil))
+
+		// A CanBePrefix Interest for the object gets its first segment
+		data := env.express(t, objName, &ndn.InterestConfig{
+			CanBePrefix: true,
+			Lifetime:    utils.IdPtr(4 * time.Second),
+		})
+		require.NotNil(t, data)
+		require.True(t, append(objName[:4:4], enc.NewSegmentComponent(0)).Equal(data.Name()))
+		require.Nil(t, env.express(t, objName, nil))
+	})
+}
+
+func TestSegmentedNodeDropOldVersions(t *testing.T) {
+	const treeJson = `{
+		"nodes": {
+			"/obj/<v=versionNumber>": {"type": "SegmentedNode", "attrs": {"MaxSegmentSize": 100}}
+		},
+		"policies": [
+			{"type": "Sha256Signer", "path": "/obj/<v=versionNumber>/<seg=segmentNumber>"}
+		]
+	}`
+	executeTest(t, treeJson, func(env *testEnv) {
+		segName := func(ver uint64, seg uint64) enc.Name {
+			return utils.WithoutErr(enc.NameFromStr(fmt.Sprintf("/p/obj/v=%d/seg=%d", ver, seg)))
+		}
+		content := make([]byte, 10000)
+		rand.Read(content)
+		for ver := uint64(1); ver <= 2; ver++ {
+			verName := utils.WithoutErr(enc.NameFromStr(fmt.Sprintf("/p/obj/v=%d", ver)))
+			require.Equal(t, uint64(100), env.tree.Match(verName).Call("Provide", enc.Wire{content}))
+		}
+
+		// The new version replaces the old one. Its 100 segments also pushed the old ones out of the cache
+		require.Nil(t, env.express(t, segName(1, 0), nil))
+		require.Nil(t, env.express(t, segName(1, 99), nil))
+		for i := uint64(0); i < 100; i++ {
+			require.NotNil(t, env.express(t, segName(2, i), nil))
+		}
+
+		// Providing the same version again replaces its segments
+		verName := utils.WithoutErr(enc.NameFromStr("/p/obj/v=2"))
+		require.Equal(t, uint64(1), env.tree.Match(verName).Call("Provide", enc.Wire{[]byte("short")}))
+		data := env.express(t, segName(2, 0), nil)
+		require.NotNil(t, data)
+		require.Equal(t, []byte("short"), data.Content().Join())
+		require.Equal(t, enc.NewSegmentComponent(0), *data.FinalBlockID())
+	})
+}
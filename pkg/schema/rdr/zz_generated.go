@@ -171,6 +171,8 @@ func (context *ManifestDigestParsingContext) Parse(reader enc.ParseReader, ignor
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -404,6 +406,8 @@ func (context *ManifestDataParsingContext) Parse(reader enc.ParseReader, ignoreC
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -1109,6 +1113,8 @@ func (context *MetaDataParsingContext) Parse(reader enc.ParseReader, ignoreCriti
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
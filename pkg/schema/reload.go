@@ -0,0 +1,427 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/log"
+)
+
+// Reload updates the tree to the json description text, as if the tree were created by CreateFromJson.
+// Only the changed parts of the tree are updated:
+//   - A node whose attributes or events change are updated in place.
+//   - A subtree whose nodes are added, removed, replaced by another type, or applied with different policies,
+//     is detached and rebuilt. Prefixes registered by a RegisterPolicy in the subtree are unregistered,
+//     and the ones in the new subtree are registered.
+//   - If the root node or its policies change, the whole tree is detached and attached again.
+//
+// Interests being handled by the old nodes are replied as usual.
+// The new description is checked before any change, so an invalid description leaves the tree unchanged.
+// All new subtrees are built and attached before they are swapped in together under the tree lock,
+// so a subtree failing to attach also leaves the tree unchanged. The same holds for the whole tree,
+// which is attached again as it was if the new one fails.
+func (t *Tree) Reload(text string, environment map[string]any) (err error) {
+	sd := &SchemaDesc{}
+	if err := json.Unmarshal([]byte(text), sd); err != nil {
+		return fmt.Errorf("unable to parse json: %v", err)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("unable to reload schema tree: %v", r)
+		}
+	}()
+	// The new description is instantiated to check and normalize it
+	newTree := sd.Instantiate(environment)
+	newSd, err := newTree.describe()
+	if err != nil {
+		return err
+	}
+	t.lock.RLock()
+	oldSd, err := t.describe()
+	t.lock.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	dirty, inPlace, err := diffSchemaDesc(oldSd, newSd)
+	if err != nil {
+		return err
+	}
+	for _, path := range dirty {
+		if len(path) == 0 {
+			return t.replaceWith(newTree)
+		}
+	}
+	steps := make([]*reloadStep, 0, len(dirty))
+	for _, path := range t.reloadSlots(dirty) {
+		step, err := t.buildSubtree(path, sd, environment)
+		if err != nil {
+			return err
+		}
+		steps = append(steps, step)
+	}
+	if err := t.swapSubtrees(steps); err != nil {
+		return err
+	}
+	if len(inPlace) == 0 {
+		return nil
+	}
+	paths, err := sd.nodePaths()
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if dropped, ok := inPlace[patternStr(path.pattern)]; ok {
+			if err := t.updateNode(path.pattern, sd.Nodes[path.str], dropped, environment); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// diffSchemaDesc compares two normalized descriptions. It returns the topmost paths of subtrees to rebuild,
+// and the nodes that can be updated in place, with the attributes to reset to the default values.
+func diffSchemaDesc(oldSd, newSd *SchemaDesc) ([]enc.NamePattern, map[string][]PropKey, error) {
+	dirtyStrs := make(map[string]struct{})
+	inPlace := make(map[string][]PropKey)
+	for pathStr, n := range newSd.Nodes {
+		o, ok := oldSd.Nodes[pathStr]
+		if !ok || o.Type != n.Type {
+			dirtyStrs[pathStr] = struct{}{}
+		} else if !jsonEqual(o, n) {
+			dropped := []PropKey{}
+			for k := range o.Attrs {
+				if _, ok := n.Attrs[k]; !ok {
+					dropped = append(dropped, PropKey(k))
+				}
+			}
+			inPlace[pathStr] = dropped
+		}
+	}
+	for pathStr := range oldSd.Nodes {
+		if _, ok := newSd.Nodes[pathStr]; !ok {
+			dirtyStrs[pathStr] = struct{}{}
+		}
+	}
+	// Policies are compared as multisets, since the same policy may be applied more than once
+	cnt := make(map[string]int)
+	paths := make(map[string]string)
+	for i, lst := range [][]PolicyDesc{oldSd.Policies, newSd.Policies} {
+		for _, p := range lst {
+			key, err := json.Marshal(p)
+			if err != nil {
+				return nil, nil, err
+			}
+			cnt[string(key)] += 2*i - 1
+			paths[string(key)] = p.Path
+		}
+	}
+	for key, c := range cnt {
+		if c != 0 {
+			dirtyStrs[paths[key]] = struct{}{}
+		}
+	}
+
+	// Only the topmost subtrees are rebuilt
+	dirty := make([]enc.NamePattern, 0, len(dirtyStrs))
+	for pathStr := range dirtyStrs {
+		path, err := enc.NamePatternFromStr(pathStr)
+		if err != nil {
+			return nil, nil, err
+		}
+		dirty = append(dirty, path)
+	}
+	sort.Slice(dirty, func(i, j int) bool {
+		return dirty[i].Compare(dirty[j]) < 0
+	})
+	ret := make([]enc.NamePattern, 0, len(dirty))
+	for _, path := range dirty {
+		if len(ret) == 0 || !ret[len(ret)-1].IsPrefix(path) {
+			ret = append(ret, path)
+		}
+	}
+	for pathStr := range inPlace {
+		path, _ := enc.NamePatternFromStr(pathStr)
+		for _, p := range ret {
+			if p.IsPrefix(path) {
+				delete(inPlace, pathStr)
+				break
+			}
+		}
+	}
+	return ret, inPlace, nil
+}
+
+func jsonEqual(a, b any) bool {
+	x, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	y, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(x) == string(y)
+}
+
+// replaceWith detaches the tree and attaches newTree in place of it.
+// If newTree fails to attach, the old tree is attached again.
+func (t *Tree) replaceWith(newTree *Tree) error {
+	engine := t.Engine()
+	var prefix enc.Name
+	if engine != nil {
+		prefix = t.root.AttachedPrefix()
+		t.Detach()
+	}
+	t.lock.Lock()
+	oldRoot, oldPolicies, oldNames := t.root, t.policies, t.callbackNames
+	t.root = newTree.root
+	t.policies = newTree.policies
+	t.callbackNames = newTree.callbackNames
+	t.engine = nil
+	t.lock.Unlock()
+	if engine == nil {
+		return nil
+	}
+	err := catchPanic(func() error { return t.Attach(prefix, engine) })
+	if err == nil {
+		return nil
+	}
+	// The new root may be attached partly
+	newTree.root.OnDetach()
+	t.lock.Lock()
+	t.root, t.policies, t.callbackNames = oldRoot, oldPolicies, oldNames
+	t.engine = nil
+	t.lock.Unlock()
+	if attachErr := t.Attach(prefix, engine); attachErr != nil {
+		log.WithField("module", "schema").Errorf("Unable to attach the tree again: %+v", attachErr)
+	}
+	return err
+}
+
+// reloadStep is a subtree to swap in by Reload.
+type reloadStep struct {
+	path enc.NamePattern
+	// par is the parent in the tree, and old is the node replaced, if any.
+	par *Node
+	old *Node
+	// sub is the new subtree, which is nil if the subtree is removed.
+	sub      *subtree
+	policies []appliedPolicy
+	// batch collects the registrations of the new subtree, which are sent after the old one is detached,
+	// in case they register the same prefixes.
+	batch *registerBatch
+}
+
+// reloadSlots returns the paths to put the subtrees of dirty at. A subtree whose ancestors do not exist
+// is put at the topmost missing one, which may be shared by other subtrees.
+func (t *Tree) reloadSlots(dirty []enc.NamePattern) []enc.NamePattern {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	ret := make([]enc.NamePattern, 0, len(dirty))
+	for _, path := range dirty {
+		node := t.root
+		depth := 0
+		for node != nil && depth < len(path) {
+			node = node.Child(path[depth])
+			depth++
+		}
+		slot := path[:depth]
+		if !slices.ContainsFunc(ret, slot.Equal) {
+			ret = append(ret, slot)
+		}
+	}
+	return ret
+}
+
+// buildSubtree builds the subtree at path in the description sd off the tree, to replace the one at path.
+func (t *Tree) buildSubtree(path enc.NamePattern, sd *SchemaDesc, environment map[string]any) (*reloadStep, error) {
+	t.lock.RLock()
+	step := &reloadStep{path: path, par: t.root.At(path[:len(path)-1]), batch: &registerBatch{}}
+	if step.par != nil {
+		step.old = step.par.Child(path[len(path)-1])
+	}
+	t.lock.RUnlock()
+	if step.par == nil {
+		return nil, fmt.Errorf("parent of '%s' does not exist", path)
+	}
+
+	paths, err := sd.nodePaths()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range paths {
+		if !path.IsPrefix(p.pattern) {
+			continue
+		}
+		node := sd.Nodes[p.str]
+		desc := NodeRegister[node.Type]
+		var treeNode *Node
+		if step.sub == nil {
+			// The node at path is always the first one if it is in sd
+			if len(p.pattern) == len(path) {
+				step.sub, err = t.newSubtree(path, desc, true)
+			} else {
+				step.sub, err = t.newSubtree(path, BaseNodeDesc, true)
+				if err == nil {
+					step.sub.node.explicit = false
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(p.pattern) == len(path) {
+			treeNode = step.sub.node
+		} else {
+			treeNode = step.sub.node.PutNode(p.pattern[len(path):], desc)
+			treeNode.explicit = true
+		}
+		if err := t.setupNode(treeNode, node, environment); err != nil {
+			return nil, err
+		}
+	}
+	if step.sub == nil {
+		// The subtree is removed
+		return step, nil
+	}
+	for _, policy := range sd.Policies {
+		p, err := enc.NamePatternFromStr(policy.Path)
+		if err != nil || !path.IsPrefix(p) {
+			continue
+		}
+		policyDesc, inst, err := t.newPolicy(policy, environment)
+		if err != nil {
+			return nil, err
+		}
+		node := step.sub.node.At(p[len(path):])
+		if node == nil {
+			return nil, fmt.Errorf("not existing path '%s' to attach policy", policy.Path)
+		}
+		inst.Apply(node)
+		step.policies = append(step.policies, appliedPolicy{
+			path:     p,
+			desc:     policyDesc,
+			inst:     inst,
+			attrRefs: attrRefs(policy.Attrs),
+		})
+	}
+	return step, nil
+}
+
+// swapSubtrees replaces the subtrees of steps together.
+// The new subtrees are attached first, so Interests only reach attached nodes, and the old ones are detached
+// after all are swapped in. If any fails to attach, or the tree is changed meanwhile, the new subtrees are
+// detached and the tree is left unchanged.
+func (t *Tree) swapSubtrees(steps []*reloadStep) (err error) {
+	engine := t.Engine()
+	attached := 0
+	defer func() {
+		if err == nil || engine == nil {
+			return
+		}
+		for _, step := range steps[:attached] {
+			if step.sub != nil {
+				step.sub.top.OnDetach()
+			}
+		}
+	}()
+	if engine != nil {
+		for _, step := range steps {
+			attached++
+			if step.sub == nil {
+				continue
+			}
+			setRegisterBatch(step.policies, step.batch)
+			err = catchPanic(func() error {
+				return step.sub.top.OnAttach(t.attachPath(step.path), engine)
+			})
+			setRegisterBatch(step.policies, nil)
+			if err != nil {
+				return fmt.Errorf("unable to attach '%s': %v", step.path, err)
+			}
+		}
+	}
+
+	t.lock.Lock()
+	for _, step := range steps {
+		if step.par.Child(step.path[len(step.path)-1]) != step.old {
+			t.lock.Unlock()
+			return fmt.Errorf("schema node '%s' is changed during the reload", step.path)
+		}
+	}
+	for _, step := range steps {
+		if step.old != nil {
+			step.par.removeChild(step.old)
+		}
+		if step.sub != nil {
+			step.par.addChild(step.sub.top)
+		}
+		policies := make([]appliedPolicy, 0, len(t.policies)+len(step.policies))
+		for _, p := range t.policies {
+			if !step.path.IsPrefix(p.path) {
+				policies = append(policies, p)
+			}
+		}
+		t.policies = append(policies, step.policies...)
+	}
+	t.lock.Unlock()
+
+	if engine == nil {
+		return nil
+	}
+	for _, step := range steps {
+		if step.old != nil {
+			step.old.OnDetach()
+		}
+	}
+	for _, step := range steps {
+		step.batch.flush(engine)
+	}
+	return nil
+}
+
+// catchPanic calls f, and returns the panic raised by f as an error.
+func catchPanic(f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return f()
+}
+
+// updateNode updates the attributes and events of a node to its description in place.
+// Attributes in dropped are reset to their default values. Callbacks not from the environment are kept.
+func (t *Tree) updateNode(path enc.NamePattern, nd NodeDesc, dropped []PropKey, environment map[string]any) error {
+	t.lock.Lock()
+	node := t.root.At(path)
+	if node == nil {
+		t.lock.Unlock()
+		return errors.New("schema node does not exist")
+	}
+	dft := node.desc.Create(&Node{desc: node.desc})
+	for _, k := range dropped {
+		prop := node.desc.Properties[k]
+		if err := prop.Set(node.impl, prop.Get(dft)); err != nil {
+			t.lock.Unlock()
+			return fmt.Errorf("unable to reset attribute '%s': %v", k, err)
+		}
+	}
+	for _, getter := range node.desc.Events {
+		target := getter(node.impl)
+		for _, cb := range target.Val() {
+			if _, ok := t.callbackNames[cb]; ok {
+				target.Remove(cb)
+				delete(t.callbackNames, cb)
+			}
+		}
+	}
+	t.lock.Unlock()
+	return t.setupNode(node, nd, environment)
+}
@@ -0,0 +1,163 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+const reloadTreeJson = `{
+	"nodes": {
+		"/a/<v=time>": {"type": "LeafNode", "attrs": {"Freshness": 1000}},
+		"/b/<v=time>": {"type": "LeafNode", "attrs": {}}
+	},
+	"policies": [
+		{"type": "Sha256Signer", "path": "/"},
+		{"type": "RegisterPolicy", "path": "/a", "attrs": {}},
+		{"type": "RegisterPolicy", "path": "/b", "attrs": {}}
+	]
+}`
+
+func TestTreeReloadRoutes(t *testing.T) {
+	executeSchemaTest(t, reloadTreeJson, func(env *schemaTestEnv) {
+		// /a and /b are registered with their common prefix
+		require.Equal(t, []string{"/p"}, env.routes.Routes())
+		aPath := utils.WithoutErr(enc.NamePatternFromStr("/a/<v=time>"))
+		aNode := env.tree.At(aPath)
+		registered := len(env.routes.commands)
+
+		// /b is removed, /c is added, and /a only changes its attributes
+		require.NoError(t, env.tree.Reload(`{
+			"nodes": {
+				"/a/<v=time>": {"type": "LeafNode", "attrs": {"Freshness": 2000}},
+				"/c/<v=time>": {"type": "LeafNode", "attrs": {}}
+			},
+			"policies": [
+				{"type": "Sha256Signer", "path": "/"},
+				{"type": "RegisterPolicy", "path": "/a", "attrs": {}},
+				{"type": "RegisterPolicy", "path": "/c", "attrs": {}}
+			]
+		}`, nil))
+		// /p is kept for /a, and only /p/c is registered again
+		require.Equal(t, []string{"/p", "/p/c"}, env.routes.Routes())
+		require.Equal(t, [][]string{{"/p/c"}}, env.routes.commands[registered:])
+		require.True(t, aNode == env.tree.At(aPath))
+		require.Equal(t, uint64(2000), aNode.Get("Freshness"))
+		require.Nil(t, env.tree.Match(utils.WithoutErr(enc.NameFromStr("/p/b/v=1"))))
+		require.NotNil(t, env.tree.Match(utils.WithoutErr(enc.NameFromStr("/p/c/v=1"))))
+
+		// Reloading the same description changes nothing
+		registered = len(env.routes.commands)
+		require.NoError(t, env.tree.Reload(string(utils.WithoutErr(env.tree.ToJson())), nil))
+		require.Equal(t, []string{"/p", "/p/c"}, env.routes.Routes())
+		require.Equal(t, registered, len(env.routes.commands))
+		require.True(t, aNode == env.tree.At(aPath))
+
+		// Moving a RegisterPolicy rebuilds the subtrees of both paths, leaving others as they are
+		cNode := env.tree.At(utils.WithoutErr(enc.NamePatternFromStr("/c")))
+		require.NoError(t, env.tree.Reload(`{
+			"nodes": {
+				"/a/<v=time>": {"type": "LeafNode", "attrs": {"Freshness": 2000}},
+				"/c/<v=time>": {"type": "LeafNode", "attrs": {}},
+				"/d/<v=time>": {"type": "LeafNode", "attrs": {}}
+			},
+			"policies": [
+				{"type": "Sha256Signer", "path": "/"},
+				{"type": "RegisterPolicy", "path": "/a", "attrs": {}},
+				{"type": "RegisterPolicy", "path": "/d", "attrs": {}}
+			]
+		}`, nil))
+		require.Equal(t, []string{"/p", "/p/d"}, env.routes.Routes())
+		require.True(t, aNode == env.tree.At(aPath))
+		require.False(t, cNode == env.tree.At(utils.WithoutErr(enc.NamePatternFromStr("/c"))))
+	})
+}
+
+func TestTreeReloadInvalid(t *testing.T) {
+	executeSchemaTest(t, reloadTreeJson, func(env *schemaTestEnv) {
+		aNode := env.tree.At(utils.WithoutErr(enc.NamePatternFromStr("/a/<v=time>")))
+
+		require.Error(t, env.tree.Reload(`{"nodes": `, nil))
+		require.Error(t, env.tree.Reload(`{
+			"nodes": {
+				"/a/<v=time>": {"type": "NoSuchNode", "attrs": {}}
+			},
+			"policies": []
+		}`, nil))
+		require.Error(t, env.tree.Reload(`{
+			"nodes": {
+				"/a/<v=time>": {"type": "LeafNode", "attrs": {"NoSuchAttr": 1}}
+			},
+			"policies": []
+		}`, nil))
+
+		// The tree is left unchanged
+		require.Equal(t, []string{"/p"}, env.routes.Routes())
+		require.True(t, aNode == env.tree.At(utils.WithoutErr(enc.NamePatternFromStr("/a/<v=time>"))))
+		require.Equal(t, uint64(1000), aNode.Get("Freshness"))
+	})
+}
+
+func TestTreeReloadRollback(t *testing.T) {
+	executeSchemaTest(t, reloadTreeJson, func(env *schemaTestEnv) {
+		bNode := env.tree.At(utils.WithoutErr(enc.NamePatternFromStr("/b")))
+		registered := len(env.routes.commands)
+
+		// /b is removed, but /c cannot be attached as its prefix to register has a variable
+		require.Error(t, env.tree.Reload(`{
+			"nodes": {
+				"/a/<v=time>": {"type": "LeafNode", "attrs": {"Freshness": 1000}},
+				"/c/<v=time>": {"type": "LeafNode", "attrs": {}}
+			},
+			"policies": [
+				{"type": "Sha256Signer", "path": "/"},
+				{"type": "RegisterPolicy", "path": "/a", "attrs": {}},
+				{"type": "RegisterPolicy", "path": "/c/<v=time>", "attrs": {}}
+			]
+		}`, nil))
+
+		// Neither subtree is swapped
+		require.True(t, bNode == env.tree.At(utils.WithoutErr(enc.NamePatternFromStr("/b"))))
+		require.Nil(t, env.tree.At(utils.WithoutErr(enc.NamePatternFromStr("/c"))))
+		require.NotNil(t, env.tree.Match(utils.WithoutErr(enc.NameFromStr("/p/b/v=1"))))
+		require.Equal(t, []string{"/p"}, env.routes.Routes())
+		require.Equal(t, registered, len(env.routes.commands))
+		desc := string(utils.WithoutErr(env.tree.ToJson()))
+		require.Contains(t, desc, `"/b"`)
+		require.NotContains(t, desc, `/c`)
+	})
+}
+
+func TestTreeReloadSubtrees(t *testing.T) {
+	const treeJson = `{
+		"nodes": {
+			"/c/<v=time>": {"type": "LeafNode", "attrs": {}}
+		},
+		"policies": [
+			{"type": "Sha256Signer", "path": "/"},
+			{"type": "RegisterPolicy", "path": "/c", "attrs": {}}
+		]
+	}`
+	executeSchemaTest(t, treeJson, func(env *schemaTestEnv) {
+		require.Equal(t, []string{"/p/c"}, env.routes.Routes())
+
+		// The rebuilt /c registers its prefix again after the old one is unregistered,
+		// and the new nodes under the missing /x are put together
+		require.NoError(t, env.tree.Reload(`{
+			"nodes": {
+				"/c/<v=time>": {"type": "LeafNode", "attrs": {}},
+				"/x/a/<v=time>": {"type": "LeafNode", "attrs": {}},
+				"/x/b/<v=time>": {"type": "LeafNode", "attrs": {}}
+			},
+			"policies": [
+				{"type": "Sha256Signer", "path": "/"},
+				{"type": "RegisterPolicy", "path": "/c", "attrs": {"RetryInterval": 2000}}
+			]
+		}`, nil))
+		require.Equal(t, []string{"/p/c"}, env.routes.Routes())
+		require.NotNil(t, env.tree.Match(utils.WithoutErr(enc.NameFromStr("/p/x/a/v=1"))))
+		require.NotNil(t, env.tree.Match(utils.WithoutErr(enc.NameFromStr("/p/x/b/v=1"))))
+	})
+}
@@ -1,8 +1,12 @@
 package schema
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 
 	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
 )
@@ -67,14 +71,23 @@ func instantiateAttrs(attrs map[string]any, env map[string]any) map[string]any {
 	handleVal := func(val any) any {
 		switch v := val.(type) {
 		case string:
-			if len(v) == 0 || v[0] != '$' {
-				return v
+			if len(v) > 0 && v[0] == '$' {
+				attr, ok := env[v]
+				if !ok {
+					panic(fmt.Errorf("missing attributes %s in the environment", v))
+				}
+				str, isStr := attr.(string)
+				if !isStr {
+					return attr
+				}
+				v = str
 			}
-			if attr, ok := env[v]; ok {
-				return attr
-			} else {
-				panic(fmt.Errorf("missing attributes %s in the environment", v))
+			// "@file:" and "@env:" are loaded, either written in the json or given by the environment
+			value, _, err := LoadSecret(v)
+			if err != nil {
+				panic(fmt.Errorf("unable to load attribute %s: %v", v, err))
 			}
+			return value
 			// Recursive if ret is a list or another map
 		case map[string]any:
 			return handleMap(v)
@@ -102,39 +115,43 @@ func instantiateAttrs(attrs map[string]any, env map[string]any) map[string]any {
 	return handleMap(attrs)
 }
 
+// attrRefs returns the attributes given by names in the environment, or loaded from files or environment variables.
+// ToJson describes them by the references, so loaded secrets are not written out.
+func attrRefs(attrs map[string]any) map[PropKey]string {
+	ret := make(map[PropKey]string)
+	for k, v := range attrs {
+		name, ok := v.(string)
+		if !ok {
+			continue
+		}
+		if (len(name) > 0 && name[0] == '$') || strings.HasPrefix(name, FileRefPrefix) ||
+			strings.HasPrefix(name, EnvRefPrefix) {
+			ret[PropKey(k)] = name
+		}
+	}
+	return ret
+}
+
 func (sd *SchemaDesc) Instantiate(environment map[string]any) *Tree {
 	// Events must be Callbacks
 	// Attrs has nested maps that needs to be handled
 	tree := &Tree{}
 	// Handle nodes
-	for pathStr, node := range sd.Nodes {
-		path, err := enc.NamePatternFromStr(pathStr)
-		if err != nil {
-			panic(fmt.Errorf("unable to instantiate schema tree: invalid path '%s': %v", pathStr, err))
-		}
+	// Parents are put before children, so they are not created as base nodes
+	paths, err := sd.nodePaths()
+	if err != nil {
+		panic(fmt.Errorf("unable to instantiate schema tree: %v", err))
+	}
+	for _, path := range paths {
+		node := sd.Nodes[path.str]
 		// Create nodes
 		nodeDesc, ok := NodeRegister[node.Type]
 		if !ok {
 			panic(fmt.Errorf("unable to instantiate schema tree: invalid node type '%s'", node.Type))
 		}
-		impl := tree.PutNode(path, nodeDesc).Impl()
-		// Set attributes
-		attrs := instantiateAttrs(node.Attrs, environment)
-		for k, v := range attrs {
-			// If there is a #, then it's for sub child
-			err := nodeDesc.Properties[PropKey(k)].Set(impl, v)
-			if err != nil {
-				panic(fmt.Errorf("unable to instantiate schema tree: invalid attribute '%s'=%v: %v", k, v, err))
-			}
-		}
-		// Set events
-		events := instantiateEvents(node.Events, environment)
-		for k, lst := range events {
-			evtTgt := nodeDesc.Events[PropKey(k)](impl)
-			for _, cb := range lst {
-				v := cb // Capture the value
-				evtTgt.Add(&v)
-			}
+		treeNode := tree.PutNode(path.pattern, nodeDesc)
+		if err := tree.setupNode(treeNode, node, environment); err != nil {
+			panic(fmt.Errorf("unable to instantiate schema tree: node '%s': %v", path.str, err))
 		}
 	}
 	// Handle policies
@@ -144,40 +161,122 @@ func (sd *SchemaDesc) Instantiate(environment map[string]any) *Tree {
 		if err != nil {
 			panic(fmt.Errorf("unable to instantiate schema tree: invalid path '%s': %v", pathStr, err))
 		}
-		node := tree.At(path)
-		if node == nil {
-			panic(fmt.Errorf("unable to instantiate schema tree: not existing path '%s' to attach policy", pathStr))
+		policyDesc, inst, err := tree.newPolicy(policy, environment)
+		if err != nil {
+			panic(fmt.Errorf("unable to instantiate schema tree: policy %s at '%s': %v", policy.Type, pathStr, err))
 		}
-		// Create policies
-		policyDesc, ok := PolicyRegister[policy.Type]
+		// Apply policy
+		err = tree.applyPolicy(path, policyDesc, inst, attrRefs(policy.Attrs))
+		if err != nil {
+			panic(fmt.Errorf("unable to instantiate schema tree: %v", err))
+		}
+	}
+	return tree
+}
+
+// descPath is a path of a node in a SchemaDesc.
+type descPath struct {
+	str     string
+	pattern enc.NamePattern
+}
+
+// nodePaths returns the paths of nodes, sorted so that parents go before children.
+func (sd *SchemaDesc) nodePaths() ([]descPath, error) {
+	ret := make([]descPath, 0, len(sd.Nodes))
+	for pathStr := range sd.Nodes {
+		path, err := enc.NamePatternFromStr(pathStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path '%s': %v", pathStr, err)
+		}
+		ret = append(ret, descPath{str: pathStr, pattern: path})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].pattern.Compare(ret[j].pattern) < 0
+	})
+	return ret, nil
+}
+
+// setupNode sets the attributes and events of a node put in the tree by its description.
+func (t *Tree) setupNode(treeNode *Node, node NodeDesc, environment map[string]any) error {
+	nodeDesc := treeNode.desc
+	impl := treeNode.Impl()
+	treeNode.attrRefs = attrRefs(node.Attrs)
+	// Set attributes
+	attrs := instantiateAttrs(node.Attrs, environment)
+	for k, v := range attrs {
+		// If there is a #, then it's for sub child
+		prop, ok := nodeDesc.Properties[PropKey(k)]
 		if !ok {
-			panic(fmt.Errorf("unable to instantiate schema tree: invalid policy type '%s'", policy.Type))
+			return fmt.Errorf("unknown attribute '%s' of %s", k, nodeDesc.ClassName)
 		}
-		inst := policyDesc.Create()
-		// Set attributes
-		attrs := instantiateAttrs(policy.Attrs, environment)
-		for k, v := range attrs {
-			err := policyDesc.Properties[PropKey(k)].Set(inst, v)
-			if err != nil {
-				panic(fmt.Errorf("unable to instantiate schema tree: invalid attribute '%s'=%v: %v", k, v, err))
-			}
+		err := prop.Set(impl, v)
+		if err != nil {
+			return fmt.Errorf("invalid attribute '%s'=%v: %v", k, v, err)
 		}
-		// Set events
-		events := instantiateEvents(policy.Events, environment)
-		for k, lst := range events {
-			evtTgt := policyDesc.Events[PropKey(k)](inst)
-			for _, cb := range lst {
-				v := cb // Capture the value
-				evtTgt.Add(&v)
-			}
+	}
+	if nodeDesc.Validate != nil {
+		if err := nodeDesc.Validate(impl); err != nil {
+			return fmt.Errorf("invalid attributes: %v", err)
 		}
-		// Apply policy
-		inst.Apply(node)
 	}
-	return tree
+	// Set events
+	events := instantiateEvents(node.Events, environment)
+	for k, lst := range events {
+		evtTgt := nodeDesc.Events[PropKey(k)](impl)
+		for i, cb := range lst {
+			v := cb // Capture the value
+			evtTgt.Add(&v)
+			t.nameCallback(&v, node.Events[k][i])
+		}
+	}
+	return nil
 }
 
-// CreateFromJson creates a schema tree from json description and a given environment
+// newPolicy creates a policy by its description. The policy is not applied.
+func (t *Tree) newPolicy(policy PolicyDesc, environment map[string]any) (*PolicyImplDesc, Policy, error) {
+	// Create policies
+	policyDesc, ok := PolicyRegister[policy.Type]
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid policy type '%s'", policy.Type)
+	}
+	inst := policyDesc.Create()
+	// Set attributes
+	attrs := instantiateAttrs(policy.Attrs, environment)
+	for k, v := range attrs {
+		prop, ok := policyDesc.Properties[PropKey(k)]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown attribute '%s' of %s", k, policyDesc.ClassName)
+		}
+		err := prop.Set(inst, v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid attribute '%s'=%v: %v", k, v, err)
+		}
+	}
+	// Set events
+	events := instantiateEvents(policy.Events, environment)
+	for k, lst := range events {
+		evtTgt := policyDesc.Events[PropKey(k)](inst)
+		for i, cb := range lst {
+			v := cb // Capture the value
+			evtTgt.Add(&v)
+			t.nameCallback(&v, policy.Events[k][i])
+		}
+	}
+	return policyDesc, inst, nil
+}
+
+// CreateFromJson creates a schema tree from json description and a given environment.
+// An attribute, either written in the json or given by the environment as "$name", is converted to the type of
+// its property, which the property constants like PropLifetime give:
+//   - Numbers, like Retries and CacheSize of a LeafNode, accept numbers of any Go type and numeric strings,
+//     as long as the value fits in the type without losing its fraction.
+//   - Booleans, like CanBePrefix, MustBeFresh and CacheData, accept bools and strings like "true".
+//   - Durations, like Lifetime, Freshness and ValidDuration, accept numbers of milliseconds,
+//     strings like "1.5s" and time.Duration values.
+//   - Names, like ForwardingHint of an ExpressPoint, are strings in the URI format.
+//
+// It panics with an error naming the node or the policy if an attribute is unknown to its class,
+// a value does not convert, or the values are out of range, like a Freshness above the ValidDuration of a LeafNode.
 func CreateFromJson(text string, environment map[string]any) *Tree {
 	schemaDesc := &SchemaDesc{}
 	err := json.Unmarshal([]byte(text), schemaDesc)
@@ -186,3 +285,139 @@ func CreateFromJson(text string, environment map[string]any) *Tree {
 	}
 	return schemaDesc.Instantiate(environment)
 }
+
+// ToJson serializes the tree into a json description that CreateFromJson accepts.
+// Only nodes put by PutNode and policies applied by ApplyPolicy or CreateFromJson are described,
+// since the rest are created by them. Attributes taking their default values are omitted.
+// A callback or an attribute given by the environment is described by its $name in the environment,
+// and callbacks not from the environment, such as the ones added by policies, are omitted.
+func (t *Tree) ToJson() ([]byte, error) {
+	t.lock.RLock()
+	sd, err := t.describe()
+	t.lock.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// Name patterns contain '<' and '>', which are kept as they are
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(sd); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// describe returns the description of the tree. The tree must be locked.
+func (t *Tree) describe() (*SchemaDesc, error) {
+	sd := &SchemaDesc{
+		Nodes:    map[string]NodeDesc{},
+		Policies: make([]PolicyDesc, 0, len(t.policies)),
+	}
+	if t.root != nil {
+		if err := t.describeNode(t.root, enc.NamePattern{}, sd); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range t.policies {
+		attrs, err := describeAttrs(p.desc.Properties, p.inst, p.desc.Create(), p.attrRefs)
+		if err != nil {
+			return nil, fmt.Errorf("unable to describe policy %s at '%s': %v", p.desc.ClassName, patternStr(p.path), err)
+		}
+		sd.Policies = append(sd.Policies, PolicyDesc{
+			Type:   p.desc.ClassName,
+			Path:   patternStr(p.path),
+			Attrs:  attrs,
+			Events: t.describeEvents(p.desc.Events, p.inst),
+		})
+	}
+	return sd, nil
+}
+
+func (t *Tree) describeNode(node *Node, path enc.NamePattern, sd *SchemaDesc) error {
+	if node.explicit {
+		// The default values are given by a node that is not in the tree
+		dft := node.desc.Create(&Node{desc: node.desc})
+		attrs, err := describeAttrs(node.desc.Properties, node.impl, dft, node.attrRefs)
+		if err != nil {
+			return fmt.Errorf("unable to describe node at '%s': %v", patternStr(path), err)
+		}
+		sd.Nodes[patternStr(path)] = NodeDesc{
+			Type:   node.desc.ClassName,
+			Attrs:  attrs,
+			Events: t.describeEvents(node.desc.Events, node.impl),
+		}
+	}
+	for _, c := range node.chd {
+		chdPath := append(append(enc.NamePattern{}, path...), c.edge)
+		if err := t.describeNode(c, chdPath, sd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Tree) describeEvents(events map[PropKey]EventGetter, owner any) map[string]ListenerList {
+	ret := make(map[string]ListenerList)
+	for k, getter := range events {
+		lst := ListenerList{}
+		for _, cb := range getter(owner).Val() {
+			if name, ok := t.callbackNames[cb]; ok {
+				lst = append(lst, name)
+			}
+		}
+		if len(lst) > 0 {
+			ret[string(k)] = lst
+		}
+	}
+	if len(ret) == 0 {
+		return nil
+	}
+	return ret
+}
+
+func describeAttrs(props map[PropKey]PropertyDesc, owner any, dft any, refs map[PropKey]string) (map[string]any, error) {
+	ret := make(map[string]any)
+	for k, prop := range props {
+		if name, ok := refs[k]; ok {
+			ret[string(k)] = name
+			continue
+		}
+		val := jsonAttr(prop.Get(owner))
+		if val == nil || reflect.DeepEqual(val, jsonAttr(prop.Get(dft))) {
+			continue
+		}
+		if _, err := json.Marshal(val); err != nil {
+			return nil, fmt.Errorf("attribute %s is not serializable: %v", k, err)
+		}
+		ret[string(k)] = val
+	}
+	if len(ret) == 0 {
+		return nil, nil
+	}
+	return ret, nil
+}
+
+// jsonAttr converts an attribute value into the form accepted by its PropertyDesc in json.
+func jsonAttr(val any) any {
+	switch v := val.(type) {
+	case []byte:
+		return string(v)
+	case enc.Matching:
+		ret := make(map[string]any, len(v))
+		for name, comp := range v {
+			ret[name] = enc.Component{Typ: enc.TypeGenericNameComponent, Val: comp}.String()
+		}
+		return ret
+	default:
+		return v
+	}
+}
+
+func patternStr(path enc.NamePattern) string {
+	if len(path) == 0 {
+		return "/"
+	}
+	return path.String()
+}
@@ -0,0 +1,211 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/schema"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+func parseSchemaDesc(t *testing.T, text []byte) *schema.SchemaDesc {
+	sd := &schema.SchemaDesc{}
+	require.NoError(t, json.Unmarshal(text, sd))
+	return sd
+}
+
+func TestTreeToJsonRoundTrip(t *testing.T) {
+	utils.SetTestingT(t)
+
+	// Only attributes different from the defaults are written, as ToJson omits the others
+	const treeJson = `{
+		"nodes": {
+			"/data/<v=time>": {
+				"type": "LeafNode",
+				"attrs": {"Freshness": 2000, "CanBePrefix": false, "Lifetime": "$lifetime"},
+				"events": {"OnInterest": ["$onInterest"]}
+			},
+			"/data/<v=time>/<seg=segmentNumber>": {"type": "ExpressPoint", "attrs": {"Retries": 3}},
+			"/cmd": {"type": "ExpressPoint"}
+		},
+		"policies": [
+			{"type": "Sha256Signer", "path": "/data"},
+			{"type": "RegisterPolicy", "path": "/", "attrs": {"RegisterIf": "$isProducer", "Patterns": {"id": "alice"}}},
+			{"type": "MemStorage", "path": "/data"}
+		]
+	}`
+	onInterest := schema.Callback(func(event *schema.Event) any { return nil })
+	env := map[string]any{
+		"$lifetime":   1500,
+		"$onInterest": onInterest,
+		"$isProducer": true,
+	}
+	tree := schema.CreateFromJson(treeJson, env)
+	dumped := utils.WithoutErr(tree.ToJson())
+	require.Equal(t, parseSchemaDesc(t, []byte(treeJson)), parseSchemaDesc(t, dumped))
+
+	// The tree created from the dump is the same, and dumps the same json
+	tree2 := schema.CreateFromJson(string(dumped), env)
+	require.Equal(t, string(dumped), string(utils.WithoutErr(tree2.ToJson())))
+	path := utils.WithoutErr(enc.NamePatternFromStr("/data/<v=time>"))
+	node, node2 := tree.At(path), tree2.At(path)
+	for _, attr := range []schema.PropKey{"Freshness", "CanBePrefix", "Lifetime", "MustBeFresh"} {
+		require.Equal(t, node.Get(attr), node2.Get(attr))
+	}
+	require.Equal(t, uint64(1500), node2.Get("Lifetime"))
+	require.Equal(t, 1, len(node2.GetEvent(schema.PropOnInterest).Val()))
+	segPath := utils.WithoutErr(enc.NamePatternFromStr("/data/<v=time>/<seg=segmentNumber>"))
+	require.Equal(t, 3, tree2.At(segPath).Get("Retries"))
+}
+
+func TestTreeToJsonProgrammatic(t *testing.T) {
+	utils.SetTestingT(t)
+
+	tree := &schema.Tree{}
+	path := utils.WithoutErr(enc.NamePatternFromStr("/data/<v=time>"))
+	node := tree.PutNode(path, schema.LeafNodeDesc)
+	require.NoError(t, node.Set("Freshness", 3*time.Second))
+	signer := schema.PolicyRegister["Sha256Signer"]
+	require.NoError(t, tree.ApplyPolicy(path, signer, signer.Create()))
+	require.Error(t, tree.ApplyPolicy(utils.WithoutErr(enc.NamePatternFromStr("/none")), signer, signer.Create()))
+
+	// The root is created as a base node, and the child of <v=time> is not put by PutNode
+	dumped := utils.WithoutErr(tree.ToJson())
+	require.Equal(t, &schema.SchemaDesc{
+		Nodes: map[string]schema.NodeDesc{
+			"/data/<v=time>": {Type: "LeafNode", Attrs: map[string]any{"Freshness": float64(3000)}},
+		},
+		Policies: []schema.PolicyDesc{
+			{Type: "Sha256Signer", Path: "/data/<v=time>"},
+		},
+	}, parseSchemaDesc(t, dumped))
+	tree2 := schema.CreateFromJson(string(dumped), nil)
+	require.Equal(t, uint64(3000), tree2.At(path).Get("Freshness"))
+}
+
+// createFromJsonErr returns the error CreateFromJson panics with, or nil if it succeeds.
+func createFromJsonErr(text string, environment map[string]any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = r.(error)
+		}
+	}()
+	schema.CreateFromJson(text, environment)
+	return nil
+}
+
+func TestCreateFromJsonAttrTypes(t *testing.T) {
+	utils.SetTestingT(t)
+
+	const treeJson = `{
+		"nodes": {
+			"/data": {"type": "ExpressPoint", "attrs": {
+				"Lifetime": "$lifetime", "Retries": "$retries", "CanBePrefix": "$canBePrefix"
+			}}
+		},
+		"policies": []
+	}`
+	path := utils.WithoutErr(enc.NamePatternFromStr("/data"))
+	create := func(lifetime, retries, canBePrefix any) *schema.Node {
+		tree := schema.CreateFromJson(treeJson, map[string]any{
+			"$lifetime":    lifetime,
+			"$retries":     retries,
+			"$canBePrefix": canBePrefix,
+		})
+		return tree.At(path)
+	}
+
+	// Values of other Go types are converted to the types of the properties
+	node := create(1500, uint8(3), "false")
+	require.Equal(t, uint64(1500), node.Get("Lifetime"))
+	require.Equal(t, 3, node.Get("Retries"))
+	require.Equal(t, false, node.Get("CanBePrefix"))
+	node = create("2.5s", "4", true)
+	require.Equal(t, uint64(2500), node.Get("Lifetime"))
+	require.Equal(t, 4, node.Get("Retries"))
+	require.Equal(t, true, node.Get("CanBePrefix"))
+	node = create(2*time.Second, float64(5), "true")
+	require.Equal(t, uint64(2000), node.Get("Lifetime"))
+	require.Equal(t, 5, node.Get("Retries"))
+	node = create(float32(0.5), int64(0), false)
+	require.Equal(t, time.Duration(500*time.Microsecond), node.Impl().(*schema.ExpressPoint).Lifetime)
+
+	// A mismatch names the attribute and the expected type
+	for _, c := range []struct {
+		lifetime, retries, canBePrefix any
+		msg                            string
+	}{
+		{true, 1, true, "Lifetime expects a non-negative duration in milliseconds, not true (bool)"},
+		{"soon", 1, true, "Lifetime expects a non-negative duration in milliseconds, not soon (string)"},
+		{1000, 1.5, true, "Retries expects int, not 1.5 (float64)"},
+		{1000, uint64(math.MaxUint64), true, "Retries expects int"},
+		{1000, "three", true, "Retries expects int, not three (string)"},
+		{1000, 1, 1, "CanBePrefix expects bool, not 1 (int)"},
+		{1000, 1, "yes", "CanBePrefix expects bool, not yes (string)"},
+	} {
+		err := createFromJsonErr(treeJson, map[string]any{
+			"$lifetime":    c.lifetime,
+			"$retries":     c.retries,
+			"$canBePrefix": c.canBePrefix,
+		})
+		require.ErrorContains(t, err, "node '/data'")
+		require.ErrorContains(t, err, c.msg)
+	}
+
+	// Setting the property at runtime gives the same error
+	err := node.Set("Retries", "many")
+	var attrErr schema.ErrAttrType
+	require.ErrorAs(t, err, &attrErr)
+	require.Equal(t, "Retries", attrErr.Item)
+	require.Equal(t, "int", attrErr.Expected)
+	require.Equal(t, "many", attrErr.Value)
+	require.Equal(t, 0, node.Get("Retries"))
+}
+
+func TestCreateFromJsonValidation(t *testing.T) {
+	utils.SetTestingT(t)
+
+	tree := func(attrs string) string {
+		return `{
+			"nodes": {
+				"/data/<v=time>": {"type": "LeafNode", "attrs": ` + attrs + `}
+			},
+			"policies": [
+				{"type": "Sha256Signer", "path": "/data"}
+			]
+		}`
+	}
+	require.NoError(t, createFromJsonErr(tree(`{"Freshness": 2000, "ValidDuration": 2000, "Retries": 0}`), nil))
+
+	// The error names the node and the attribute
+	for attrs, msg := range map[string]string{
+		`{"Freshnes": 2000}`:                         "node '/data/<v=time>': unknown attribute 'Freshnes' of LeafNode",
+		`{"Lifetime": -1}`:                           "node '/data/<v=time>': invalid attribute 'Lifetime'=-1",
+		`{"Freshness": "-2s"}`:                       "Freshness expects a non-negative duration",
+		`{"Retries": -1}`:                            "node '/data/<v=time>': invalid attributes",
+		`{"CacheSize": -5}`:                          "CacheSize",
+		`{"Freshness": 3000, "ValidDuration": 2000}`: "Freshness 3s exceeds ValidDuration 2s",
+		`{"ValidDuration": 1000}`:                    "Freshness 1m0s exceeds ValidDuration 1s",
+	} {
+		require.ErrorContains(t, createFromJsonErr(tree(attrs), nil), msg, attrs)
+	}
+	err := createFromJsonErr(`{
+		"nodes": {"/data": {"type": "LeafNode", "attrs": {}}},
+		"policies": [{"type": "RegisterPolicy", "path": "/data", "attrs": {"RegisterIff": true}}]
+	}`, nil)
+	require.ErrorContains(t, err, "policy RegisterPolicy at '/data': unknown attribute 'RegisterIff' of RegisterPolicy")
+
+	// Nodes added at runtime are checked the same way, and not added if they fail
+	created := schema.CreateFromJson(tree(`{}`), nil)
+	path := utils.WithoutErr(enc.NamePatternFromStr("/data/<v=time>/meta"))
+	require.ErrorContains(t, created.AddNode(path, "LeafNode", map[string]any{
+		"Freshness": 3000, "ValidDuration": 2000,
+	}), "Freshness 3s exceeds ValidDuration 2s")
+	require.Nil(t, created.At(path))
+	require.NoError(t, created.AddNode(path, "LeafNode", map[string]any{"Freshness": 1000, "ValidDuration": 2000}))
+	require.NotNil(t, created.At(path))
+}
@@ -0,0 +1,55 @@
+package schema
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Prefixes of attribute values that are loaded from a file or an environment variable,
+// so secrets like keys need not be written in the schema json.
+const (
+	FileRefPrefix = "@file:"
+	EnvRefPrefix  = "@env:"
+)
+
+// LoadSecret returns the value referred to by ref: the content of a file for "@file:<path>",
+// or an environment variable for "@env:<name>". A trailing newline of the file is removed.
+// ok is false if ref is not a reference, in which case the value is ref itself.
+func LoadSecret(ref string) (value string, ok bool, err error) {
+	switch {
+	case strings.HasPrefix(ref, FileRefPrefix):
+		path := ref[len(FileRefPrefix):]
+		text, err := os.ReadFile(path)
+		if err != nil {
+			return "", true, fmt.Errorf("unable to read %s: %v", path, err)
+		}
+		value = strings.TrimSuffix(string(text), "\n")
+		return strings.TrimSuffix(value, "\r"), true, nil
+	case strings.HasPrefix(ref, EnvRefPrefix):
+		name := ref[len(EnvRefPrefix):]
+		value, found := os.LookupEnv(name)
+		if !found {
+			return "", true, fmt.Errorf("environment variable %s is not set", name)
+		}
+		return value, true, nil
+	default:
+		return ref, false, nil
+	}
+}
+
+// loadKey returns key, or the one loaded from keyFile or keyEnv if key is empty.
+func loadKey(key string, keyFile string, keyEnv string) (string, error) {
+	if len(key) > 0 {
+		return key, nil
+	}
+	if len(keyFile) > 0 {
+		value, _, err := LoadSecret(FileRefPrefix + keyFile)
+		return value, err
+	}
+	if len(keyEnv) > 0 {
+		value, _, err := LoadSecret(EnvRefPrefix + keyEnv)
+		return value, err
+	}
+	return "", nil
+}
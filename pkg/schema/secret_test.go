@@ -0,0 +1,132 @@
+package schema_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/schema"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+func TestLoadSecret(t *testing.T) {
+	utils.SetTestingT(t)
+
+	path := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0600))
+	t.Setenv("GONDN_TEST_KEY", "from-env")
+
+	value, ok, err := schema.LoadSecret(schema.FileRefPrefix + path)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "from-file", value)
+	value, ok, err = schema.LoadSecret(schema.EnvRefPrefix + "GONDN_TEST_KEY")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "from-env", value)
+	value, ok, err = schema.LoadSecret("inline")
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Equal(t, "inline", value)
+
+	_, _, err = schema.LoadSecret(schema.FileRefPrefix + filepath.Join(t.TempDir(), "none"))
+	require.Error(t, err)
+	_, _, err = schema.LoadSecret(schema.EnvRefPrefix + "GONDN_TEST_NO_SUCH_KEY")
+	require.Error(t, err)
+}
+
+func TestFixedHmacSignerKeySources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(path, []byte("Hello, World!\n"), 0600))
+	t.Setenv("GONDN_TEST_HMAC_KEY", "Hello, World!")
+
+	treeJson := func(keyAttrs string) string {
+		return fmt.Sprintf(`{
+			"nodes": {
+				"/obj/<v=time>": {"type": "LeafNode", "attrs": {}}
+			},
+			"policies": [
+				{"type": "FixedHmacSigner", "path": "/obj/<v=time>", "attrs": {%s, "KeyName": "/p/KEY/hmac"}}
+			]
+		}`, keyAttrs)
+	}
+	provide := func(keyAttrs string, environment map[string]any) enc.Buffer {
+		var wire enc.Buffer
+		executeSchemaTestEnv(t, treeJson(keyAttrs), environment, func(env *schemaTestEnv) {
+			mNode := env.tree.Match(utils.WithoutErr(enc.NameFromStr("/p/obj/v=1")))
+			wire = mNode.Call("Provide", enc.Wire{[]byte("hello")}).(enc.Wire).Join()
+		})
+		return wire
+	}
+
+	// Keys loaded from a file or an environment variable give the same signature as the inline key
+	inline := provide(`"KeyValue": "Hello, World!"`, nil)
+	require.Equal(t, ndn.SignatureHmacWithSha256, readData(t, enc.Wire{inline}).Signature().SigType())
+	require.Equal(t, inline, provide(fmt.Sprintf(`"KeyFile": %q`, path), nil))
+	require.Equal(t, inline, provide(`"KeyEnv": "GONDN_TEST_HMAC_KEY"`, nil))
+	require.Equal(t, inline, provide(fmt.Sprintf(`"KeyValue": %q`, schema.FileRefPrefix+path), nil))
+	require.Equal(t, inline, provide(`"KeyValue": "$hmacKey"`, map[string]any{
+		"$hmacKey": schema.EnvRefPrefix + "GONDN_TEST_HMAC_KEY",
+	}))
+	require.NotEqual(t, inline, provide(`"KeyValue": "Hello, World"`, nil))
+
+	// A key that cannot be loaded is refused when the schema is created
+	require.Panics(t, func() { schema.CreateFromJson(treeJson(`"KeyEnv": "GONDN_TEST_NO_SUCH_KEY"`), nil) })
+	require.Panics(t, func() { schema.CreateFromJson(treeJson(`"KeyValue": "@file:/no/such/file"`), nil) })
+}
+
+func TestFixedHmacSignerToJson(t *testing.T) {
+	utils.SetTestingT(t)
+
+	path := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(path, []byte("supersecret\n"), 0600))
+	t.Setenv("GONDN_TEST_HMAC_KEY", "supersecret")
+
+	for _, keyAttr := range []string{
+		fmt.Sprintf(`"KeyFile": %q`, path),
+		`"KeyEnv": "GONDN_TEST_HMAC_KEY"`,
+		fmt.Sprintf(`"KeyValue": %q`, schema.FileRefPrefix+path),
+		`"KeyValue": "@env:GONDN_TEST_HMAC_KEY"`,
+	} {
+		treeJson := fmt.Sprintf(`{
+			"nodes": {
+				"/obj/<v=time>": {"type": "LeafNode", "attrs": {}}
+			},
+			"policies": [
+				{"type": "FixedHmacSigner", "path": "/obj/<v=time>", "attrs": {%s, "KeyName": "/p/KEY/hmac"}},
+				{"type": "FixedHmacIntSigner", "path": "/obj/<v=time>", "attrs": {%s}}
+			]
+		}`, keyAttr, keyAttr)
+		tree := schema.CreateFromJson(treeJson, nil)
+
+		// The loaded key is not written out, but the reference to it is
+		dumped := string(utils.WithoutErr(tree.ToJson()))
+		require.NotContains(t, dumped, "supersecret", keyAttr)
+		tree2 := schema.CreateFromJson(dumped, nil)
+		require.Equal(t, dumped, string(utils.WithoutErr(tree2.ToJson())), keyAttr)
+	}
+
+	// A tree from the dump signs with the same key
+	treeJson := fmt.Sprintf(`{
+		"nodes": {
+			"/obj/<v=time>": {"type": "LeafNode", "attrs": {}}
+		},
+		"policies": [
+			{"type": "FixedHmacSigner", "path": "/obj/<v=time>", "attrs": {"KeyFile": %q, "KeyName": "/p/KEY/hmac"}}
+		]
+	}`, path)
+	dumped := string(utils.WithoutErr(schema.CreateFromJson(treeJson, nil).ToJson()))
+	provide := func(treeJson string) enc.Buffer {
+		var wire enc.Buffer
+		executeSchemaTest(t, treeJson, func(env *schemaTestEnv) {
+			mNode := env.tree.Match(utils.WithoutErr(enc.NameFromStr("/p/obj/v=1")))
+			wire = mNode.Call("Provide", enc.Wire{[]byte("hello")}).(enc.Wire).Join()
+		})
+		return wire
+	}
+	require.Equal(t, provide(treeJson), provide(dumped))
+}
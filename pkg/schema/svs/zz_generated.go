@@ -170,6 +170,8 @@ func (context *StateVecEntryParsingContext) Parse(reader enc.ParseReader, ignore
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -403,6 +405,8 @@ func (context *StateVecParsingContext) Parse(reader enc.ParseReader, ignoreCriti
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -560,6 +564,8 @@ func (context *StateVecAppParamParsingContext) Parse(reader enc.ParseReader, ign
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
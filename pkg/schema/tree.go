@@ -2,6 +2,7 @@ package schema
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -18,6 +19,18 @@ type Tree struct {
 	lock sync.RWMutex
 
 	engine ndn.Engine
+
+	// policies and callbackNames are used to obtain the description of the tree.
+	policies      []appliedPolicy
+	callbackNames map[*Callback]string
+}
+
+// appliedPolicy is a policy applied at path by Tree.ApplyPolicy.
+type appliedPolicy struct {
+	path     enc.NamePattern
+	desc     *PolicyImplDesc
+	inst     Policy
+	attrRefs map[PropKey]string
 }
 
 func (t *Tree) Engine() ndn.Engine {
@@ -36,35 +49,56 @@ func (t *Tree) Attach(prefix enc.Name, engine ndn.Engine) error {
 	if t.root == nil {
 		return errors.New("cannot attach an empty tree")
 	}
+	if t.engine != nil {
+		return errors.New("the tree is already attached")
+	}
 	t.root.SetAttachedPrefix(prefix)
+	t.inheritSigners(t.root)
 	path := make(enc.NamePattern, len(prefix))
 	for i, c := range prefix {
 		path[i] = c
 	}
+	// Prefixes are registered together after all nodes are attached, so their common prefix takes one command
+	batch := &registerBatch{}
+	setRegisterBatch(t.policies, batch)
 	err := t.root.OnAttach(path, engine)
+	setRegisterBatch(t.policies, nil)
 	if err != nil {
 		return err
 	}
+	// Other trees may be attached to the same engine. Interests go to the one attached at the longest prefix
 	err = engine.AttachHandler(prefix, t.intHandler)
 	if err != nil {
+		t.root.OnDetach()
 		return err
 	}
 	log.WithField("module", "schema").Info("Attached to engine.")
 	t.engine = engine
+	batch.flush(engine)
 	return nil
 }
 
+// setRegisterBatch makes the RegisterPolicies in policies collect their registrations in batch.
+func setRegisterBatch(policies []appliedPolicy, batch *registerBatch) {
+	for _, p := range policies {
+		if rp, ok := p.inst.(*RegisterPolicy); ok {
+			rp.batch = batch
+		}
+	}
+}
+
 // Detach the schema tree from the engine
 func (t *Tree) Detach() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
 	if t.engine == nil {
 		return
 	}
-	t.lock.Lock()
-	defer t.lock.Unlock()
 
 	log.WithField("module", "schema").Info("Detached from engine")
 	t.engine.DetachHandler(t.root.AttachedPrefix())
 	t.root.OnDetach()
+	t.engine = nil
 }
 
 // Match an NDN name to a (variable) matching
@@ -72,6 +106,11 @@ func (t *Tree) Match(name enc.Name) *MatchedNode {
 	return t.root.Match(name)
 }
 
+// MatchPrefix matches the longest prefix of an NDN name to a node, returning the unmatched suffix in Remainder.
+func (t *Tree) MatchPrefix(name enc.Name) *MatchedNode {
+	return t.root.MatchPrefix(name)
+}
+
 // intHandler is the callback called by the engine that handles an incoming Interest.
 func (t *Tree) intHandler(
 	interest ndn.Interest, rawInterest enc.Wire, sigCovered enc.Wire,
@@ -83,12 +122,56 @@ func (t *Tree) intHandler(
 	matchName := interest.Name()
 	mNode := t.root.Match(matchName)
 	if mNode == nil {
+		// The deepest node matching a prefix of the name may serve the rest of it
+		if pNode := t.root.MatchPrefix(matchName); pNode != nil {
+			if h, ok := pNode.Node.Impl().(PrefixInterestHandler); ok {
+				h.OnPrefixInterest(interest, rawInterest, sigCovered, reply, deadline, pNode)
+				return
+			}
+		}
 		log.WithField("module", "schema").WithField("name", interest.Name().String()).Warn("Unexpected Interest. Drop.")
 		return
 	}
+	// A CanBePrefix Interest may name a node that does not serve Interests itself, like /<v> of /<v>/<seg>.
+	// Then the Interest is satisfied by any Data under the name stored by its descendants.
+	// The storage decides whether a Data is fresh for a MustBeFresh Interest.
+	if interest.CanBePrefix() && QueryInterface[*ExpressPoint](mNode.Node) == nil {
+		if wire := searchDescendants(mNode, interest, deadline); wire != nil {
+			err := reply(wire)
+			if err != nil {
+				mNode.Logger("schema").Errorf("Unable to reply Interest. Drop: %+v", err)
+			}
+			return
+		}
+	}
 	mNode.Node.OnInterest(interest, rawInterest, sigCovered, reply, deadline, mNode.Matching)
 }
 
+// searchDescendants searches the storages of the descendants of mNode for a Data under its name.
+func searchDescendants(mNode *MatchedNode, interest ndn.Interest, deadline time.Time) enc.Wire {
+	var search func(node *Node) enc.Wire
+	search = func(node *Node) enc.Wire {
+		for _, c := range node.Children() {
+			if ep := QueryInterface[*ExpressPoint](c); ep != nil {
+				wire := ep.SearchCache(&Event{
+					TargetNode: c,
+					Target:     mNode,
+					Interest:   interest,
+					Deadline:   &deadline,
+				})
+				if len(wire) > 0 {
+					return wire
+				}
+			}
+			if wire := search(c); wire != nil {
+				return wire
+			}
+		}
+		return nil
+	}
+	return search(mNode.Node)
+}
+
 // At the path return the node. Path does not include the attached prefix.
 func (t *Tree) At(path enc.NamePattern) *Node {
 	return t.root.At(path)
@@ -104,6 +187,7 @@ func (t *Tree) PutNode(path enc.NamePattern, desc *NodeImplDesc) *Node {
 			t.root = &Node{}
 			t.root.desc = desc
 			t.root.impl = desc.Create(t.root)
+			t.root.explicit = true
 			return t.root
 		} else {
 			panic("schema node already exists")
@@ -114,8 +198,258 @@ func (t *Tree) PutNode(path enc.NamePattern, desc *NodeImplDesc) *Node {
 			t.root.desc = BaseNodeDesc
 			t.root.impl = CreateBaseNode(t.root)
 		}
-		return t.root.PutNode(path, desc)
+		ret := t.root.PutNode(path, desc)
+		ret.explicit = true
+		return ret
+	}
+}
+
+// ApplyPolicy applies a policy of class desc at the node of path. The path does not include the attached prefix.
+// Policies applied in this way are kept in the description given by ToJson.
+func (t *Tree) ApplyPolicy(path enc.NamePattern, desc *PolicyImplDesc, policy Policy) error {
+	return t.applyPolicy(path, desc, policy, nil)
+}
+
+func (t *Tree) applyPolicy(path enc.NamePattern, desc *PolicyImplDesc, policy Policy, attrRefs map[PropKey]string) error {
+	var node *Node
+	if t.root != nil {
+		node = t.At(path)
+	}
+	if node == nil {
+		return fmt.Errorf("not existing path '%s' to attach policy", path)
+	}
+	engine := t.Engine()
+	if engine != nil {
+		// The subtree is attached again so the events added by the policy take effect
+		node.OnDetach()
+		policy.Apply(node)
+		if err := node.OnAttach(t.attachPath(path), engine); err != nil {
+			return err
+		}
+	} else {
+		policy.Apply(node)
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.policies = append(t.policies, appliedPolicy{
+		path:     path,
+		desc:     desc,
+		inst:     policy,
+		attrRefs: attrRefs,
+	})
+	return nil
+}
+
+// attachPath returns the path of a node including the attached prefix.
+func (t *Tree) attachPath(path enc.NamePattern) enc.NamePattern {
+	prefix := t.root.AttachedPrefix()
+	ret := make(enc.NamePattern, 0, len(prefix)+len(path))
+	for _, c := range prefix {
+		ret = append(ret, c)
+	}
+	return append(ret, path...)
+}
+
+// AddNode adds a node of class nodeType at path, which must not exist, and sets its attributes.
+// It can be called after the tree is attached, when the new node is attached before it receives Interests.
+// Subtree policies applied at the ancestors, such as storages, are applied to the new node.
+// Prefixes registered for the ancestors also cover the new node. If the new node needs its own prefix,
+// apply a RegisterPolicy with ApplyPolicy after adding it.
+func (t *Tree) AddNode(path enc.NamePattern, nodeType string, attrs map[string]any) error {
+	desc, ok := NodeRegister[nodeType]
+	if !ok {
+		return ndn.ErrInvalidValue{Item: "nodeType", Value: nodeType}
+	}
+	sub, err := t.newSubtree(path, desc, false)
+	if err != nil {
+		return err
+	}
+	for k, v := range attrs {
+		prop, ok := desc.Properties[PropKey(k)]
+		if !ok {
+			return ndn.ErrNotSupported{Item: k}
+		}
+		if err := prop.Set(sub.node.impl, v); err != nil {
+			return err
+		}
+	}
+	if desc.Validate != nil {
+		if err := desc.Validate(sub.node.impl); err != nil {
+			return err
+		}
+	}
+	return t.linkSubtree(sub)
+}
+
+// subtree is a subtree built off the tree, which is linked to the tree after attached.
+type subtree struct {
+	// path is the path of top
+	path enc.NamePattern
+	// top is the root of the subtree, whose parent is in the tree
+	top *Node
+	// node is the node put at the path given to newSubtree
+	node *Node
+}
+
+// newSubtree creates the node of desc at path, and its ancestors that do not exist, off the tree.
+// Subtree policies applied at the ancestors are applied to the new nodes.
+// If replace, the node is created to replace the one at path, whose policies are not applied.
+func (t *Tree) newSubtree(path enc.NamePattern, desc *NodeImplDesc, replace bool) (*subtree, error) {
+	if len(path) == 0 {
+		return nil, errors.New("cannot add a node as the root")
+	}
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	par := t.root
+	depth := 0
+	for par != nil && depth < len(path) && !(replace && depth == len(path)-1) {
+		c := par.Child(path[depth])
+		if c == nil {
+			break
+		}
+		par = c
+		depth++
+	}
+	if par == nil {
+		return nil, errors.New("cannot add a node to an empty tree")
+	}
+	if depth == len(path) {
+		return nil, errors.New("schema node already exists")
+	}
+
+	ret := &subtree{path: path[:depth+1]}
+	for i := depth; i < len(path); i++ {
+		nxtChd := &Node{
+			par:  par,
+			edge: path[i],
+			desc: BaseNodeDesc,
+		}
+		if i == len(path)-1 {
+			nxtChd.desc = desc
+			nxtChd.explicit = true
+		}
+		nxtChd.impl = nxtChd.desc.Create(nxtChd)
+		if ret.top == nil {
+			ret.top = nxtChd
+		} else {
+			par.addChild(nxtChd)
+		}
+		par = nxtChd
+	}
+	ret.node = par
+	for _, p := range t.policies {
+		if sp, ok := p.inst.(SubtreePolicy); ok && p.path.IsPrefix(path) && (!replace || len(p.path) < len(path)) {
+			sp.Apply(ret.top)
+		}
+	}
+	if t.engine != nil {
+		t.inheritSigners(ret.top)
+	}
+	return ret, nil
+}
+
+// inheritSigners applies SignerPolicies to the nodes in the subtree of top that validate Data
+// but have no SignerPolicy of their own. Each node inherits those of its nearest ancestor that has any.
+// A node inherits only once, so policies applied after that do not change its signer.
+func (t *Tree) inheritSigners(top *Node) {
+	own := make(map[*Node][]SignerPolicy)
+	for _, p := range t.policies {
+		if sp, ok := p.inst.(SignerPolicy); ok {
+			if node := t.root.At(p.path); node != nil {
+				own[node] = append(own[node], sp)
+			}
+		}
+	}
+	if len(own) == 0 {
+		return
+	}
+	var inherited []SignerPolicy
+	for n := top.par; n != nil && inherited == nil; n = n.par {
+		inherited = own[n]
+	}
+	var walk func(node *Node, inherited []SignerPolicy)
+	walk = func(node *Node, inherited []SignerPolicy) {
+		if sps, ok := own[node]; ok {
+			inherited = sps
+		} else if len(inherited) > 0 && !node.signerInherited && node.GetEvent(PropOnValidateData) != nil {
+			for _, sp := range inherited {
+				sp.Apply(node)
+			}
+			node.signerInherited = true
+		}
+		for _, c := range node.chd {
+			walk(c, inherited)
+		}
+	}
+	walk(top, inherited)
+}
+
+// linkSubtree attaches a subtree given by newSubtree if the tree is attached, and then links it to the tree.
+// In this way, incoming Interests never reach unattached nodes.
+func (t *Tree) linkSubtree(sub *subtree) error {
+	engine := t.Engine()
+	if engine != nil {
+		if err := sub.top.OnAttach(t.attachPath(sub.path), engine); err != nil {
+			return err
+		}
+	}
+
+	t.lock.Lock()
+	par := sub.top.par
+	if par.Child(sub.top.edge) != nil {
+		// Another node is added at the same place concurrently
+		t.lock.Unlock()
+		if engine != nil {
+			sub.top.OnDetach()
+		}
+		return errors.New("schema node already exists")
+	}
+	par.addChild(sub.top)
+	t.lock.Unlock()
+	return nil
+}
+
+// RemoveNode removes the node at path with its subtree, and detaches them from the engine if attached.
+// The policies applied in the subtree are also removed.
+func (t *Tree) RemoveNode(path enc.NamePattern) error {
+	if len(path) == 0 {
+		return errors.New("cannot remove the root node")
+	}
+	t.lock.Lock()
+	var node *Node
+	if t.root != nil {
+		node = t.root.At(path)
+	}
+	if node == nil {
+		t.lock.Unlock()
+		return errors.New("schema node does not exist")
+	}
+	node.par.removeChild(node)
+	policies := make([]appliedPolicy, 0, len(t.policies))
+	for _, p := range t.policies {
+		if !path.IsPrefix(p.path) {
+			policies = append(policies, p)
+		}
+	}
+	t.policies = policies
+	engine := t.engine
+	t.lock.Unlock()
+
+	if engine != nil {
+		node.OnDetach()
+	}
+	return nil
+}
+
+// nameCallback records the name of a callback in the environment.
+func (t *Tree) nameCallback(callback *Callback, name string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.callbackNames == nil {
+		t.callbackNames = make(map[*Callback]string)
 	}
+	t.callbackNames[callback] = name
 }
 
 // RLock locks the tree for read use
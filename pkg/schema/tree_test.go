@@ -0,0 +1,372 @@
+package schema_test
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	basic_engine "github.com/zjkmxy/go-ndn/pkg/engine/basic"
+	"github.com/zjkmxy/go-ndn/pkg/engine/dummy"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/ndn/mgmt_2022"
+	"github.com/zjkmxy/go-ndn/pkg/schema"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+// routeEngine records the routes registered to the forwarder instead of sending the commands.
+type routeEngine struct {
+	*basic_engine.Engine
+
+	lock   sync.Mutex
+	routes map[string]struct{}
+	// commands are the prefixes of every RegisterRoute(s) call
+	commands [][]string
+	// failures is the number of following calls the forwarder rejects
+	failures int
+}
+
+func (e *routeEngine) RegisterRoute(prefix enc.Name, opts ndn.RouteOptions) error {
+	return e.RegisterRoutes([]enc.Name{prefix}, opts)
+}
+
+func (e *routeEngine) RegisterRoutes(prefixes []enc.Name, opts ndn.RouteOptions) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	cmd := make([]string, len(prefixes))
+	for i, prefix := range prefixes {
+		cmd[i] = prefix.String()
+	}
+	e.commands = append(e.commands, cmd)
+	if e.failures > 0 {
+		e.failures--
+		return mgmt_2022.ErrControlResponse{StatusCode: 403, StatusText: "authorization rejected"}
+	}
+	for _, r := range cmd {
+		e.routes[r] = struct{}{}
+	}
+	return nil
+}
+
+func (e *routeEngine) UnregisterRoute(prefix enc.Name, opts ndn.RouteOptions) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	delete(e.routes, prefix.String())
+	return nil
+}
+
+// Routes returns the registered prefixes in order.
+func (e *routeEngine) Routes() []string {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	ret := make([]string, 0, len(e.routes))
+	for r := range e.routes {
+		ret = append(ret, r)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+type schemaTestEnv struct {
+	face   *dummy.DummyFace
+	timer  *dummy.Timer
+	engine *basic_engine.Engine
+	routes *routeEngine
+	tree   *schema.Tree
+}
+
+// executeSchemaTest attaches the schema tree described by treeJson to /p of a dummy engine.
+func executeSchemaTest(t *testing.T, treeJson string, main func(env *schemaTestEnv)) {
+	executeSchemaTestEnv(t, treeJson, nil, main)
+}
+
+// executeSchemaTestEnv is executeSchemaTest with the environment given to CreateFromJson.
+func executeSchemaTestEnv(t *testing.T, treeJson string, environment map[string]any, main func(env *schemaTestEnv)) {
+	utils.SetTestingT(t)
+
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool {
+		return true
+	}
+
+	face := dummy.NewDummyFace()
+	timer := dummy.NewTimer()
+	engine := basic_engine.NewEngine(face, timer, sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(t, engine.Start())
+	routes := &routeEngine{Engine: engine, routes: map[string]struct{}{}}
+
+	tree := schema.CreateFromJson(treeJson, environment)
+	require.NoError(t, tree.Attach(utils.WithoutErr(enc.NameFromStr("/p")), routes))
+
+	main(&schemaTestEnv{face: face, timer: timer, engine: engine, routes: routes, tree: tree})
+
+	tree.Detach()
+	require.NoError(t, engine.Shutdown())
+}
+
+// mustName parses a name in helpers shared with benchmarks, which cannot use utils.WithoutErr.
+func mustName(tb testing.TB, s string) enc.Name {
+	name, err := enc.NameFromStr(s)
+	require.NoError(tb, err)
+	return name
+}
+
+// mustPattern parses a name pattern in helpers shared with benchmarks.
+func mustPattern(tb testing.TB, s string) enc.NamePattern {
+	pattern, err := enc.NamePatternFromStr(s)
+	require.NoError(tb, err)
+	return pattern
+}
+
+func makeInterest(t *testing.T, env *schemaTestEnv, name string, config *ndn.InterestConfig) enc.Buffer {
+	if config == nil {
+		config = &ndn.InterestConfig{Lifetime: utils.IdPtr(4 * time.Second)}
+	}
+	wire, _, _, err := env.engine.Spec().MakeInterest(utils.WithoutErr(enc.NameFromStr(name)), config, nil, nil)
+	require.NoError(t, err)
+	return wire.Join()
+}
+
+func TestTreeAddRemoveNodeConcurrent(t *testing.T) {
+	const treeJson = `{
+		"nodes": {
+			"/static/<v=time>": {"type": "LeafNode", "attrs": {}}
+		},
+		"policies": [
+			{"type": "Sha256Signer", "path": "/"},
+			{"type": "MemStorage", "path": "/", "attrs": {}}
+		]
+	}`
+	executeSchemaTest(t, treeJson, func(env *schemaTestEnv) {
+		const count = 100
+		staticName := utils.WithoutErr(enc.NameFromStr("/p/static/v=1"))
+		staticData := env.tree.Match(staticName).Call("Provide", enc.Wire{[]byte("static")}).(enc.Wire)
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		// Nodes are added and removed while Interests are dispatched to the tree
+		go func() {
+			defer wg.Done()
+			for i := 0; i < count; i++ {
+				path := utils.WithoutErr(enc.NamePatternFromStr(fmt.Sprintf("/u%d/<v=time>", i)))
+				require.NoError(t, env.tree.AddNode(path, "LeafNode", map[string]any{"Freshness": 1000}))
+				name := utils.WithoutErr(enc.NameFromStr(fmt.Sprintf("/p/u%d/v=1", i)))
+				require.NotNil(t, env.tree.Match(name).Call("Provide", enc.Wire{[]byte("dynamic")}))
+				if i%2 == 0 {
+					require.NoError(t, env.tree.RemoveNode(path[:1]))
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < count; i++ {
+				require.NoError(t, env.face.FeedPacket(makeInterest(t, env, fmt.Sprintf("/p/u%d/v=1", i), nil)))
+				require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/static/v=1", nil)))
+			}
+		}()
+		wg.Wait()
+
+		for i := 0; i < count; i++ {
+			name := utils.WithoutErr(enc.NameFromStr(fmt.Sprintf("/p/u%d/v=1", i)))
+			if i%2 == 0 {
+				require.Nil(t, env.tree.Match(name))
+			} else {
+				require.NotNil(t, env.tree.Match(name))
+			}
+		}
+
+		// Drop the replies of the concurrent Interests
+		for _, err := env.face.Consume(); err == nil; _, err = env.face.Consume() {
+		}
+		require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/static/v=1", nil)))
+		require.Equal(t, enc.Buffer(staticData.Join()), utils.WithoutErr(env.face.Consume()))
+		require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/u1/v=1", nil)))
+		data := readData(t, enc.Wire{utils.WithoutErr(env.face.Consume())})
+		require.Equal(t, "dynamic", string(data.Content().Join()))
+		require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/u0/v=1", nil)))
+		_, err := env.face.Consume()
+		require.Error(t, err)
+	})
+}
+
+func TestTreeRemoveNodePolicies(t *testing.T) {
+	const treeJson = `{
+		"nodes": {
+			"/static/<v=time>": {"type": "LeafNode", "attrs": {}}
+		},
+		"policies": [
+			{"type": "Sha256Signer", "path": "/"},
+			{"type": "RegisterPolicy", "path": "/", "attrs": {}},
+			{"type": "MemStorage", "path": "/", "attrs": {}}
+		]
+	}`
+	executeSchemaTest(t, treeJson, func(env *schemaTestEnv) {
+		require.Equal(t, []string{"/p"}, env.routes.Routes())
+
+		userPath := utils.WithoutErr(enc.NamePatternFromStr("/user/alice"))
+		leafPath := utils.WithoutErr(enc.NamePatternFromStr("/user/alice/<v=time>"))
+		require.NoError(t, env.tree.AddNode(leafPath, "LeafNode", nil))
+		// The new node is covered by the prefix of the root, so nothing is registered
+		require.Equal(t, []string{"/p"}, env.routes.Routes())
+
+		require.NoError(t, env.tree.ApplyPolicy(userPath, schema.PolicyRegister["RegisterPolicy"], schema.NewRegisterPolicy()))
+		require.Equal(t, []string{"/p", "/p/user/alice"}, env.routes.Routes())
+		desc := string(utils.WithoutErr(env.tree.ToJson()))
+		require.Contains(t, desc, `"/user/alice"`)
+
+		require.NoError(t, env.tree.RemoveNode(userPath[:1]))
+		require.Equal(t, []string{"/p"}, env.routes.Routes())
+		require.Nil(t, env.tree.At(userPath))
+		desc = string(utils.WithoutErr(env.tree.ToJson()))
+		require.NotContains(t, desc, `/user`)
+
+		// The removed policy does not come back with a node at the same path
+		require.NoError(t, env.tree.AddNode(leafPath, "LeafNode", nil))
+		require.Equal(t, []string{"/p"}, env.routes.Routes())
+		require.NoError(t, env.tree.RemoveNode(userPath))
+		require.NotNil(t, env.tree.At(userPath[:1]))
+
+		require.Error(t, env.tree.RemoveNode(userPath))
+		require.Error(t, env.tree.AddNode(utils.WithoutErr(enc.NamePatternFromStr("/static/<v=time>")), "LeafNode", nil))
+	})
+}
+
+func TestTreeCanBePrefixDescendants(t *testing.T) {
+	const treeJson = `{
+		"nodes": {
+			"/obj/<v=versionNumber>/<seg=segmentNumber>": {"type": "LeafNode", "attrs": {"Freshness": 1000}}
+		},
+		"policies": [
+			{"type": "Sha256Signer", "path": "/"},
+			{"type": "MemStorage", "path": "/", "attrs": {}}
+		]
+	}`
+	executeSchemaTest(t, treeJson, func(env *schemaTestEnv) {
+		segName := utils.WithoutErr(enc.NameFromStr("/p/obj/v=2/seg=3"))
+		wire := env.tree.Match(segName).Call("Provide", enc.Wire{[]byte("segment")}).(enc.Wire)
+		canBePrefix := func(mustBeFresh bool) *ndn.InterestConfig {
+			return &ndn.InterestConfig{
+				CanBePrefix: true,
+				MustBeFresh: mustBeFresh,
+				Lifetime:    utils.IdPtr(4 * time.Second),
+			}
+		}
+		noReply := func() {
+			_, err := env.face.Consume()
+			require.Error(t, err)
+		}
+
+		// /<v> does not handle Interests, so the Data is searched under it
+		require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/obj/v=2", canBePrefix(false))))
+		require.Equal(t, enc.Buffer(wire.Join()), utils.WithoutErr(env.face.Consume()))
+		require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/obj/v=2", nil)))
+		noReply()
+		require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/obj/v=1", canBePrefix(false))))
+		noReply()
+
+		// A fresh Data is served to MustBeFresh Interests until it becomes stale
+		require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/obj/v=2", canBePrefix(true))))
+		require.Equal(t, enc.Buffer(wire.Join()), utils.WithoutErr(env.face.Consume()))
+		env.timer.MoveForward(2 * time.Second)
+		require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/obj/v=2", canBePrefix(true))))
+		noReply()
+		require.NoError(t, env.face.FeedPacket(makeInterest(t, env, "/p/obj/v=2", canBePrefix(false))))
+		require.Equal(t, enc.Buffer(wire.Join()), utils.WithoutErr(env.face.Consume()))
+	})
+}
+
+func TestTreeInheritSigners(t *testing.T) {
+	const treeJson = `{
+		"nodes": {
+			"/a/<v=time>": {"type": "LeafNode", "attrs": {}},
+			"/b/<v=time>": {"type": "LeafNode", "attrs": {}},
+			"/c/<id>/<v=time>": {"type": "LeafNode", "attrs": {}}
+		},
+		"policies": [
+			{"type": "Sha256Signer", "path": "/"},
+			{"type": "FixedHmacSigner", "path": "/b/<v=time>", "attrs": {"KeyValue": "b-key", "KeyName": "/p/b/KEY/hmac"}},
+			{"type": "FixedHmacSigner", "path": "/c", "attrs": {"KeyValue": "c-key", "KeyName": "/p/c/KEY/hmac"}}
+		]
+	}`
+	executeSchemaTest(t, treeJson, func(env *schemaTestEnv) {
+		signature := func(name string) ndn.Signature {
+			mNode := env.tree.Match(utils.WithoutErr(enc.NameFromStr(name)))
+			require.NotNil(t, mNode)
+			return readData(t, mNode.Call("Provide", enc.Wire{[]byte("hello")}).(enc.Wire)).Signature()
+		}
+
+		// The root signer applies to the leaves without a signer of their own
+		require.Equal(t, ndn.SignatureDigestSha256, signature("/p/a/v=1").SigType())
+		// The most specific signer wins
+		sig := signature("/p/b/v=1")
+		require.Equal(t, ndn.SignatureHmacWithSha256, sig.SigType())
+		require.Equal(t, "/p/b/KEY/hmac", sig.KeyName().String())
+		sig = signature("/p/c/x/v=1")
+		require.Equal(t, ndn.SignatureHmacWithSha256, sig.SigType())
+		require.Equal(t, "/p/c/KEY/hmac", sig.KeyName().String())
+
+		// Nodes added after attaching inherit as well
+		require.NoError(t, env.tree.AddNode(utils.WithoutErr(enc.NamePatternFromStr("/c/<id>/<v=time>/<seg=segmentNumber>")), "LeafNode", nil))
+		require.NoError(t, env.tree.AddNode(utils.WithoutErr(enc.NamePatternFromStr("/d/<v=time>")), "LeafNode", nil))
+		require.Equal(t, "/p/c/KEY/hmac", signature("/p/c/x/v=1/seg=0").KeyName().String())
+		require.Equal(t, ndn.SignatureDigestSha256, signature("/p/d/v=1").SigType())
+	})
+}
+
+func TestTreeMatchPrefix(t *testing.T) {
+	const treeJson = `{
+		"nodes": {
+			"/obj/<id>": {"type": "LeafNode", "attrs": {}},
+			"/obj/<id>/meta": {"type": "LeafNode", "attrs": {}}
+		},
+		"policies": [
+			{"type": "Sha256Signer", "path": "/"}
+		]
+	}`
+	executeSchemaTest(t, treeJson, func(env *schemaTestEnv) {
+		objNode := env.tree.At(utils.WithoutErr(enc.NamePatternFromStr("/obj/<id>")))
+		metaNode := env.tree.At(utils.WithoutErr(enc.NamePatternFromStr("/obj/<id>/meta")))
+		matchPrefix := func(name string) *schema.MatchedNode {
+			return env.tree.MatchPrefix(utils.WithoutErr(enc.NameFromStr(name)))
+		}
+
+		// The name extends past the pattern
+		mNode := matchPrefix("/p/obj/aa/seg=3")
+		require.True(t, objNode == mNode.Node)
+		require.Equal(t, "/p/obj/aa", mNode.Name.String())
+		require.Equal(t, "/seg=3", mNode.Remainder.String())
+		require.Equal(t, enc.Matching{"id": []byte("aa")}, mNode.Matching)
+		mNode = matchPrefix("/p/obj/aa/meta/x/y")
+		require.True(t, metaNode == mNode.Node)
+		require.Equal(t, "/p/obj/aa/meta", mNode.Name.String())
+		require.Equal(t, "/x/y", mNode.Remainder.String())
+
+		// A name matching the whole pattern has no remainder, the same as Match
+		mNode = matchPrefix("/p/obj/aa")
+		require.True(t, objNode == mNode.Node)
+		require.Equal(t, 0, len(mNode.Remainder))
+		require.Equal(t, env.tree.Match(utils.WithoutErr(enc.NameFromStr("/p/obj/aa"))).Matching, mNode.Matching)
+
+		// A digest is matched only at the end of the name, otherwise it is part of the remainder
+		mNode = matchPrefix("/p/obj/aa/sha256digest=0102")
+		require.True(t, objNode == mNode.Node)
+		require.Equal(t, 0, len(mNode.Remainder))
+		require.Equal(t, []byte{0x01, 0x02}, mNode.Matching[enc.DigestShaNameConvention])
+		mNode = matchPrefix("/p/obj/aa/sha256digest=0102/seg=1")
+		require.True(t, objNode == mNode.Node)
+		require.Equal(t, "/p/obj/aa", mNode.Name.String())
+		require.Equal(t, 2, len(mNode.Remainder))
+		require.Equal(t, enc.TypeImplicitSha256DigestComponent, mNode.Remainder[0].Typ)
+		require.NotContains(t, mNode.Matching, enc.DigestShaNameConvention)
+
+		// Names not matching any child stop at the root, and names outside the prefix do not match
+		mNode = matchPrefix("/p/other/x")
+		require.True(t, env.tree.Root() == mNode.Node)
+		require.Equal(t, "/p", mNode.Name.String())
+		require.Equal(t, "/other/x", mNode.Remainder.String())
+		require.Nil(t, matchPrefix("/q/obj/aa"))
+	})
+}
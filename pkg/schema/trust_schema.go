@@ -0,0 +1,244 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+var keyComponent = enc.NewStringComponent(enc.TypeGenericNameComponent, "KEY")
+
+// TrustRule is a rule of a trust schema.
+// A packet whose name starts with Data can be signed by a key of an identity matching Signer.
+// Both are name patterns of full names, i.e. including the prefix the tree is attached to.
+// A variable in Signer takes the value matched in Data, if it appears there.
+type TrustRule struct {
+	Data   string `json:"data"`
+	Signer string `json:"signer"`
+}
+
+type trustRule struct {
+	data   enc.NamePattern
+	signer enc.NamePattern
+}
+
+// TrustSchemaPolicy validates signatures of Data and Interests by a trust schema.
+// A signature passes if its signer is allowed by one of the rules, and the signing certificate is
+// a trust anchor, or chains to a trust anchor by certificates each allowed by the rules.
+// It will iteratively applies to all children in a subtree.
+//
+// Certificates not known yet are fetched from the network when validating Data.
+// Since Interests are validated on the receiving goroutine, they are only validated by known certificates,
+// i.e. the trust anchor and the certificates fetched to validate Data before.
+type TrustSchemaPolicy struct {
+	// Rules are the rules of the trust schema.
+	Rules []TrustRule
+	// TrustAnchor is the encoded Data packet of the trust anchor certificate.
+	TrustAnchor []byte
+	// MaxDepth is the maximum number of certificates to fetch for a chain.
+	MaxDepth int
+	// Lifetime is the Interest lifetime to fetch certificates.
+	Lifetime time.Duration
+	// CertCacheSize is the maximum number of validated certificates to cache. 0 means unlimited.
+	CertCacheSize int
+	// VerifyCacheSize is the maximum number of verified signatures to cache, so that a Data delivered again
+	// is not verified again. 0 disables the cache.
+	VerifyCacheSize int
+
+	lock      sync.Mutex
+	engine    ndn.Engine
+	rules     []trustRule
+	validator *sec.ChainValidator
+}
+
+func (p *TrustSchemaPolicy) PolicyTrait() Policy {
+	return p
+}
+
+func (p *TrustSchemaPolicy) SubtreeTrait() SubtreePolicy {
+	return p
+}
+
+func NewTrustSchemaPolicy() Policy {
+	return &TrustSchemaPolicy{
+		MaxDepth: 8,
+		Lifetime: 4 * time.Second,
+	}
+}
+
+func (p *TrustSchemaPolicy) onAttach(event *Event) any {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.engine = event.TargetNode.Engine()
+	if p.validator != nil {
+		return nil
+	}
+
+	p.rules = make([]trustRule, 0, len(p.Rules))
+	for _, r := range p.Rules {
+		data, err := enc.NamePatternFromStr(r.Data)
+		if err != nil {
+			panic(fmt.Errorf("invalid trust rule data pattern %s: %w", r.Data, err))
+		}
+		signer, err := enc.NamePatternFromStr(r.Signer)
+		if err != nil {
+			panic(fmt.Errorf("invalid trust rule signer pattern %s: %w", r.Signer, err))
+		}
+		p.rules = append(p.rules, trustRule{data: data, signer: signer})
+	}
+	data, _, err := p.engine.Spec().ReadData(enc.NewBufferReader(p.TrustAnchor))
+	if err != nil {
+		panic(fmt.Errorf("TrustAnchor is not a valid Data packet: %w", err))
+	}
+	anchor, err := sec.ParseCertificate(data)
+	if err != nil {
+		panic(fmt.Errorf("TrustAnchor is not a valid certificate: %w", err))
+	}
+	p.validator = sec.NewChainValidator([]*sec.Certificate{anchor})
+	p.validator.MaxDepth = p.MaxDepth
+	p.validator.Lifetime = p.Lifetime
+	p.validator.CheckSigner = p.checkSigner
+	p.validator.MaxCerts = p.CertCacheSize
+	if p.VerifyCacheSize > 0 {
+		p.validator.VerifyCache = sec.NewVerifyCache(p.VerifyCacheSize)
+	}
+	return nil
+}
+
+// signerIdentity returns the identity of a key locator name /<identity>/KEY/<keyId>[/<issuerId>/<version>].
+func signerIdentity(keyName enc.Name) enc.Name {
+	for i := len(keyName) - 1; i >= 0; i-- {
+		if keyName[i].Equal(keyComponent) {
+			return keyName[:i]
+		}
+	}
+	return keyName
+}
+
+// matchPattern matches a pattern to name, or a prefix of name if prefix is set.
+// Variables already in m must take the same values.
+func matchPattern(pat enc.NamePattern, name enc.Name, prefix bool, m enc.Matching) bool {
+	if len(pat) > len(name) || (!prefix && len(pat) != len(name)) {
+		return false
+	}
+	for i, c := range pat {
+		if !c.IsMatch(name[i]) {
+			return false
+		}
+		if v, ok := c.(enc.Pattern); ok {
+			if val, ok := m[v.Tag]; ok {
+				if !bytes.Equal(val, name[i].Val) {
+					return false
+				}
+			} else {
+				v.Match(name[i], m)
+			}
+		}
+	}
+	return true
+}
+
+// checkSigner checks whether the rules allow a packet of name to be signed by keyName.
+func (p *TrustSchemaPolicy) checkSigner(name enc.Name, keyName enc.Name) error {
+	signer := signerIdentity(keyName)
+	for _, r := range p.rules {
+		m := enc.Matching{}
+		if matchPattern(r.data, name, true, m) && matchPattern(r.signer, signer, false, m) {
+			return nil
+		}
+	}
+	return fmt.Errorf("signer %s is not allowed", keyName.String())
+}
+
+func (p *TrustSchemaPolicy) validate(event *Event, fetch bool) any {
+	if event.Signature == nil {
+		return VrSilence
+	}
+	switch event.Signature.SigType() {
+	case ndn.SignatureSha256WithRsa, ndn.SignatureSha256WithEcdsa, ndn.SignatureEd25519:
+	default:
+		// Signatures without certificates are left to other validators
+		return VrSilence
+	}
+	p.lock.Lock()
+	engine, validator := p.engine, p.validator
+	p.lock.Unlock()
+	var err error
+	if fetch {
+		err = validator.Validate(engine, event.Target.Name, event.SigCovered, event.Signature)
+	} else {
+		err = validator.ValidateKnown(engine.Timer(), event.Target.Name, event.SigCovered, event.Signature)
+	}
+	if err != nil {
+		event.Target.Logger("TrustSchemaPolicy").Warnf("Validation failed: %v", err)
+		return VrFail
+	}
+	return VrPass
+}
+
+// Invalidate removes the cached certificates whose names start with name, and the results verified by them.
+func (p *TrustSchemaPolicy) Invalidate(name enc.Name) {
+	p.lock.Lock()
+	validator := p.validator
+	p.lock.Unlock()
+	if validator != nil {
+		validator.Invalidate(name)
+	}
+}
+
+func (p *TrustSchemaPolicy) onValidateData(event *Event) any {
+	return p.validate(event, true)
+}
+
+func (p *TrustSchemaPolicy) onValidateInt(event *Event) any {
+	return p.validate(event, false)
+}
+
+func (p *TrustSchemaPolicy) Apply(node *Node) {
+	if event := node.GetEvent(PropOnAttach); event != nil {
+		event.Add(utils.IdPtr(p.onAttach))
+	}
+	if event := node.GetEvent(PropOnValidateData); event != nil {
+		event.Add(utils.IdPtr(p.onValidateData))
+	}
+	if event := node.GetEvent(PropOnValidateInt); event != nil {
+		event.Add(utils.IdPtr(p.onValidateInt))
+	}
+	for _, c := range node.Children() {
+		p.Apply(c)
+	}
+}
+
+// TrustRulesPropertyDesc returns the descriptor of a []TrustRule property,
+// which can be given as a json string or a list of objects.
+func TrustRulesPropertyDesc(prop PropKey) PropertyDesc {
+	return PropertyDesc{
+		Get: DefaultPropertyDesc(prop).Get,
+		Set: func(owner any, value any) error {
+			var text []byte
+			switch v := value.(type) {
+			case []TrustRule:
+				return DefaultPropertyDesc(prop).Set(owner, v)
+			case string:
+				text = []byte(v)
+			default:
+				var err error
+				if text, err = json.Marshal(v); err != nil {
+					return ndn.ErrInvalidValue{Item: string(prop), Value: value}
+				}
+			}
+			rules := []TrustRule{}
+			if err := json.Unmarshal(text, &rules); err != nil {
+				return ndn.ErrInvalidValue{Item: string(prop), Value: value}
+			}
+			return DefaultPropertyDesc(prop).Set(owner, rules)
+		},
+	}
+}
@@ -0,0 +1,121 @@
+package schema_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	basic_engine "github.com/zjkmxy/go-ndn/pkg/engine/basic"
+	"github.com/zjkmxy/go-ndn/pkg/engine/dummy"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/ndn/spec_2022"
+	"github.com/zjkmxy/go-ndn/pkg/schema"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+type trustKey struct {
+	name enc.Name
+	pub  ed25519.PublicKey
+	priv ed25519.PrivateKey
+}
+
+func newTrustKey(t *testing.T, identity string) trustKey {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return trustKey{
+		name: sec.MakeKeyName(utils.WithoutErr(enc.NameFromStr(identity)),
+			enc.NewBytesComponent(enc.TypeGenericNameComponent, pub[:8])),
+		pub:  pub,
+		priv: priv,
+	}
+}
+
+func (k trustKey) signer() ndn.Signer {
+	return sec.NewEd25519Signer(k.name, k.priv)
+}
+
+// certificate makes the certificate of k signed by issuer.
+func (k trustKey) certificate(issuer trustKey) enc.Wire {
+	now := time.Now()
+	return utils.WithoutErr(sec.EncodeCertificate(
+		k.name, enc.NewStringComponent(enc.TypeGenericNameComponent, "issuer"), 1, k.pub,
+		now.Add(-time.Hour), now.Add(time.Hour), issuer.signer()))
+}
+
+func TestTrustSchemaPolicy(t *testing.T) {
+	utils.SetTestingT(t)
+
+	root := newTrustKey(t, "/test")
+	alice := newTrustKey(t, "/test/alice")
+	bob := newTrustKey(t, "/test/bob")
+	rogue := newTrustKey(t, "/test")
+	carol := newTrustKey(t, "/test/carol")
+	served := map[string]enc.Wire{
+		alice.name.String(): alice.certificate(root),
+		bob.name.String():   bob.certificate(root),
+		// The certificate of carol is not issued by the trust anchor
+		carol.name.String(): carol.certificate(rogue),
+	}
+	publish := func(name string, signer trustKey) {
+		wire, _, err := spec_2022.Spec{}.MakeData(utils.WithoutErr(enc.NameFromStr(name)), &ndn.DataConfig{
+			ContentType: utils.IdPtr(ndn.ContentTypeBlob),
+		}, enc.Wire{[]byte("hello")}, signer.signer())
+		require.NoError(t, err)
+		served[name] = wire
+	}
+	publish("/test/blog/alice/1", alice)
+	// Signed by bob in the namespace of alice
+	publish("/test/blog/alice/2", bob)
+	publish("/test/blog/carol/1", carol)
+
+	consFace, prodFace := dummy.NewPipeFaces()
+	timer := basic_engine.NewTimer()
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool { return true }
+	prod := basic_engine.NewEngine(prodFace, timer, sec.NewSha256IntSigner(timer), passAll)
+	cons := basic_engine.NewEngine(consFace, timer, sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(t, prod.Start())
+	require.NoError(t, cons.Start())
+	defer prod.Shutdown()
+	defer cons.Shutdown()
+	require.NoError(t, prod.AttachHandler(utils.WithoutErr(enc.NameFromStr("/test")), func(
+		interest ndn.Interest, _ enc.Wire, _ enc.Wire, reply ndn.ReplyFunc, _ time.Time,
+	) {
+		if wire, ok := served[interest.Name().String()]; ok {
+			reply(wire)
+		}
+	}))
+
+	tree := schema.CreateFromJson(`{
+		"nodes": {
+			"/blog/<author>/<post>": {"type": "LeafNode", "attrs": {"Lifetime": 500, "MustBeFresh": false}}
+		},
+		"policies": [
+			{"type": "TrustSchemaValidator", "path": "/", "attrs": {
+				"Rules": [
+					{"data": "/test/blog/<author>", "signer": "/test/<author>"},
+					{"data": "/test/<user>/KEY", "signer": "/test"}
+				],
+				"TrustAnchor": "$anchor",
+				"Lifetime": 500
+			}}
+		]
+	}`, map[string]any{"$anchor": root.certificate(root).Join()})
+	require.NoError(t, tree.Attach(utils.WithoutErr(enc.NameFromStr("/test")), cons))
+	defer tree.Detach()
+
+	need := func(name string) ndn.InterestResult {
+		mNode := tree.Match(utils.WithoutErr(enc.NameFromStr(name)))
+		require.NotNil(t, mNode)
+		return (<-mNode.Call("NeedChan").(chan schema.NeedResult)).Status
+	}
+	// The signer is allowed by the rules and chains to the anchor
+	require.Equal(t, ndn.InterestResultData, need("/test/blog/alice/1"))
+	// The signer is not allowed for the namespace, even though its certificate is valid
+	require.Equal(t, ndn.InterestResultUnverified, need("/test/blog/alice/2"))
+	// The signer is allowed, but its certificate does not chain to the anchor
+	require.Equal(t, ndn.InterestResultUnverified, need("/test/blog/carol/1"))
+}
@@ -421,6 +421,8 @@ func (context *CaProfileParsingContext) Parse(reader enc.ParseReader, ignoreCrit
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -636,6 +638,8 @@ func (context *ParamParsingContext) Parse(reader enc.ParseReader, ignoreCritical
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -869,6 +873,8 @@ func (context *ProbeIntParsingContext) Parse(reader enc.ParseReader, ignoreCriti
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -1085,6 +1091,8 @@ func (context *ProbeResParsingContext) Parse(reader enc.ParseReader, ignoreCriti
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -1287,6 +1295,8 @@ func (context *CmdNewIntParsingContext) Parse(reader enc.ParseReader, ignoreCrit
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -1655,6 +1665,8 @@ func (context *CmdNewDataParsingContext) Parse(reader enc.ParseReader, ignoreCri
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -1911,6 +1923,8 @@ func (context *CipherMsgParsingContext) Parse(reader enc.ParseReader, ignoreCrit
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -2197,6 +2211,8 @@ func (context *ChallengeIntPlainParsingContext) Parse(reader enc.ParseReader, ig
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -2809,6 +2825,8 @@ func (context *ChallengeDataPlainParsingContext) Parse(reader enc.ParseReader, i
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
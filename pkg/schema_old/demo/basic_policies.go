@@ -34,7 +34,7 @@ func (*RegisterPolicy) onAttach(path enc.NamePattern, engine ndn.Engine) error {
 		}
 		prefix[i] = c
 	}
-	return engine.RegisterRoute(prefix)
+	return engine.RegisterRoute(prefix, ndn.RouteOptions{})
 }
 
 func (p *RegisterPolicy) Apply(node schema.NTNode) error {
@@ -64,7 +64,7 @@ func (p *RegisterPolicy2) onAttach(path enc.NamePattern, engine ndn.Engine) erro
 		}
 		prefix[i] = *comp
 	}
-	return engine.RegisterRoute(prefix)
+	return engine.RegisterRoute(prefix, ndn.RouteOptions{})
 }
 
 func (p *RegisterPolicy2) Apply(node schema.NTNode) error {
@@ -0,0 +1,140 @@
+package security
+
+import (
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"time"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	spec "github.com/zjkmxy/go-ndn/pkg/ndn/spec_2022"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+// CertFreshness is the FreshnessPeriod of certificates made by EncodeCertificate.
+const CertFreshness = time.Hour
+
+var keyComponent = enc.NewStringComponent(enc.TypeGenericNameComponent, "KEY")
+
+// ErrNotCertificate is returned when a Data packet does not follow the certificate v2 format.
+var ErrNotCertificate = errors.New("The Data packet is not a valid NDN certificate.")
+
+// Certificate is a parsed NDN certificate v2.
+// Its name is in the form of /<identity>/KEY/<keyId>/<issuerId>/<version>.
+type Certificate struct {
+	// Name is the full name of the certificate.
+	Name enc.Name
+	// Identity is the identity name of the certified key.
+	Identity enc.Name
+	// KeyName is the name of the certified key, i.e. /<identity>/KEY/<keyId>.
+	KeyName enc.Name
+	// IssuerId is the issuer component of the certificate name.
+	IssuerId enc.Component
+	// Version is the version number of the certificate.
+	Version uint64
+	// PublicKey is the certified public key, parsed from the DER SubjectPublicKeyInfo content.
+	PublicKey crypto.PublicKey
+	// PublicKeyBits is the DER encoded SubjectPublicKeyInfo.
+	PublicKeyBits []byte
+	// NotBefore and NotAfter is the validity period of the certificate.
+	NotBefore time.Time
+	NotAfter  time.Time
+	// SigType is the type of the signature of the certificate.
+	SigType ndn.SigType
+	// KeyLocator is the name of the key signing this certificate.
+	KeyLocator enc.Name
+}
+
+// IsValidAt returns whether t is within the validity period of the certificate.
+func (c *Certificate) IsValidAt(t time.Time) bool {
+	return !t.Before(c.NotBefore) && !t.After(c.NotAfter)
+}
+
+// IsCertName returns whether name is in the form of a certificate name.
+func IsCertName(name enc.Name) bool {
+	return len(name) >= 4 && name[len(name)-4].Equal(keyComponent)
+}
+
+// MakeKeyName makes the key name /<identity>/KEY/<keyId>.
+func MakeKeyName(identity enc.Name, keyId enc.Component) enc.Name {
+	ret := make(enc.Name, len(identity), len(identity)+2)
+	copy(ret, identity)
+	return append(ret, keyComponent, keyId)
+}
+
+// EncodeCertificate makes a certificate of pubKey, named /<keyName>/<issuerId>/v=<version>,
+// valid from notBefore to notAfter and signed by signer.
+// keyName must be in the form of /<identity>/KEY/<keyId>.
+// For a self-signed certificate, signer should use the private key of pubKey with a KeyLocator of keyName.
+func EncodeCertificate(
+	keyName enc.Name, issuerId enc.Component, version uint64, pubKey crypto.PublicKey,
+	notBefore time.Time, notAfter time.Time, signer ndn.Signer,
+) (enc.Wire, error) {
+	if len(keyName) < 2 || !keyName[len(keyName)-2].Equal(keyComponent) {
+		return nil, ndn.ErrInvalidValue{Item: "keyName", Value: keyName}
+	}
+	if signer == nil {
+		return nil, ndn.ErrInvalidValue{Item: "signer", Value: nil}
+	}
+	if notAfter.Before(notBefore) {
+		return nil, ndn.ErrInvalidValue{Item: "notAfter", Value: notAfter}
+	}
+	keyBits, err := x509.MarshalPKIXPublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	certName := make(enc.Name, len(keyName), len(keyName)+2)
+	copy(certName, keyName)
+	certName = append(certName, issuerId, enc.NewVersionComponent(version))
+	wire, _, err := spec.Spec{}.MakeData(certName, &ndn.DataConfig{
+		ContentType: utils.IdPtr(ndn.ContentTypeKey),
+		Freshness:   utils.IdPtr(CertFreshness),
+	}, enc.Wire{keyBits}, NewValiditySigner(signer, notBefore, notAfter))
+	if err != nil {
+		return nil, err
+	}
+	return wire, nil
+}
+
+// ParseCertificate extracts the certificate information from a Data packet.
+// It does not verify the signature, nor check the validity period against the current time.
+func ParseCertificate(data ndn.Data) (*Certificate, error) {
+	name := data.Name()
+	if !IsCertName(name) {
+		return nil, ErrNotCertificate
+	}
+	if ct := data.ContentType(); ct == nil || *ct != ndn.ContentTypeKey {
+		return nil, ErrNotCertificate
+	}
+	if name[len(name)-1].Typ != enc.TypeVersionNameComponent {
+		return nil, ErrNotCertificate
+	}
+	sig := data.Signature()
+	if sig == nil || sig.SigType() == ndn.SignatureNone {
+		return nil, ErrNotCertificate
+	}
+	notBefore, notAfter := sig.Validity()
+	if notBefore == nil || notAfter == nil {
+		return nil, ErrNotCertificate
+	}
+	keyBits := data.Content().Join()
+	pubKey, err := x509.ParsePKIXPublicKey(keyBits)
+	if err != nil {
+		return nil, err
+	}
+	return &Certificate{
+		Name:          name,
+		Identity:      name[:len(name)-4],
+		KeyName:       name[:len(name)-2],
+		IssuerId:      name[len(name)-2],
+		Version:       name[len(name)-1].NumberVal(),
+		PublicKey:     pubKey,
+		PublicKeyBits: keyBits,
+		NotBefore:     *notBefore,
+		NotAfter:      *notAfter,
+		SigType:       sig.SigType(),
+		KeyLocator:    sig.KeyName(),
+	}, nil
+}
@@ -0,0 +1,75 @@
+package security_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/ndn/spec_2022"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+func TestSelfSignedCertificate(t *testing.T) {
+	utils.SetTestingT(t)
+
+	identity := utils.WithoutErr(enc.NameFromStr("/example/alice"))
+	keyName := sec.MakeKeyName(identity, enc.NewStringComponent(enc.TypeGenericNameComponent, "k1"))
+	issuer := enc.NewStringComponent(enc.TypeGenericNameComponent, "self")
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	notBefore := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	wire := utils.WithoutErr(sec.EncodeCertificate(
+		keyName, issuer, 1, pub, notBefore, notAfter, sec.NewEd25519Signer(keyName, priv)))
+
+	data, sigCovered, err := spec_2022.Spec{}.ReadData(enc.NewWireReader(wire))
+	require.NoError(t, err)
+	cert := utils.WithoutErr(sec.ParseCertificate(data))
+	require.Equal(t, "/example/alice/KEY/k1/self/v=1", cert.Name.String())
+	require.True(t, identity.Equal(cert.Identity))
+	require.True(t, keyName.Equal(cert.KeyName))
+	require.True(t, keyName.Equal(cert.KeyLocator))
+	require.Equal(t, uint64(1), cert.Version)
+	require.Equal(t, ndn.SignatureEd25519, cert.SigType)
+	require.Equal(t, pub, cert.PublicKey)
+	require.True(t, notBefore.Equal(cert.NotBefore))
+	require.True(t, notAfter.Equal(cert.NotAfter))
+
+	// Self-signed: verifiable with the key it contains
+	require.True(t, sec.EddsaValidate(sigCovered, data.Signature(), cert.PublicKey.(ed25519.PublicKey)))
+
+	require.True(t, cert.IsValidAt(time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)))
+	require.True(t, cert.IsValidAt(notAfter))
+	require.False(t, cert.IsValidAt(time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC)))
+	require.False(t, cert.IsValidAt(time.Now()))
+}
+
+func TestParseCertificateRejects(t *testing.T) {
+	utils.SetTestingT(t)
+
+	// A plain Data packet is not a certificate
+	wire, _, err := spec_2022.Spec{}.MakeData(
+		utils.WithoutErr(enc.NameFromStr("/example/alice/KEY/k1/self/v=1")),
+		&ndn.DataConfig{ContentType: utils.IdPtr(ndn.ContentTypeBlob)},
+		enc.Wire{[]byte("data")},
+		sec.NewSha256Signer(),
+	)
+	require.NoError(t, err)
+	data, _, err := spec_2022.Spec{}.ReadData(enc.NewWireReader(wire))
+	require.NoError(t, err)
+	utils.WithErr(sec.ParseCertificate(data))
+
+	// Key name must contain KEY
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	badKeyName := utils.WithoutErr(enc.NameFromStr("/example/alice/k1"))
+	utils.WithErr(sec.EncodeCertificate(
+		badKeyName, enc.NewStringComponent(enc.TypeGenericNameComponent, "self"), 1, pub,
+		time.Now(), time.Now().Add(time.Hour), sec.NewEd25519Signer(badKeyName, priv)))
+}
@@ -0,0 +1,331 @@
+package security
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+// ErrChainTooLong is returned when a certificate chain does not reach a trust anchor within MaxDepth certificates.
+var ErrChainTooLong = errors.New("certificate chain is too long")
+
+// ChainValidator resolves the certificate of a key locator by fetching it, and recursively the certificates of
+// their signers, until reaching a trust anchor. Every certificate in the chain must be unexpired and correctly signed.
+// Validated certificates are cached until any certificate in their chains expires, so later chains stop at them.
+type ChainValidator struct {
+	// Anchors are the trusted certificates.
+	Anchors []*Certificate
+	// MaxDepth is the maximum number of certificates to fetch for a chain.
+	MaxDepth int
+	// Lifetime is the Interest lifetime to fetch certificates.
+	Lifetime time.Duration
+	// CheckSigner, if set, is called for every signature in the chain with the name of the signed packet
+	// and the key locator. A non-nil error rejects the signature, e.g. when a trust schema disallows the signer.
+	CheckSigner func(name enc.Name, keyName enc.Name) error
+	// MaxCerts is the maximum number of validated certificates to cache. 0 means unlimited.
+	MaxCerts int
+	// VerifyCache, if set, caches the signatures verified.
+	VerifyCache *VerifyCache
+
+	lock sync.Mutex
+	// cache maps key locator names to validated certificates, with the most recently used at the front of lru
+	cache map[string]*list.Element
+	lru   *list.List
+}
+
+type chainEntry struct {
+	keyName string
+	cert    *Certificate
+	// anchor is the name of the trust anchor the certificate chains to
+	anchor enc.Name
+	// notAfter is the earliest expiry of the certificates in the chain
+	notAfter time.Time
+}
+
+// NewChainValidator creates a ChainValidator trusting anchors.
+func NewChainValidator(anchors []*Certificate) *ChainValidator {
+	return &ChainValidator{
+		Anchors:  anchors,
+		MaxDepth: 8,
+		Lifetime: 4 * time.Second,
+	}
+}
+
+// defaultChainValidator keeps the cache for ValidateChain.
+var defaultChainValidator = NewChainValidator(nil)
+
+// ValidateChain validates the signature of data by the certificate of its key locator, and the certificate chain
+// up to one of anchors. Certificates are fetched by app, and cached for later calls.
+// data must be parsed from a wire, like the ones given by ReadData or the engine, so that its signed portion is known.
+func ValidateChain(app ndn.Engine, data ndn.Data, anchors []*Certificate) error {
+	covered, ok := data.(interface{ SigCovered() enc.Wire })
+	if !ok || covered.SigCovered() == nil {
+		return errors.New("data is not parsed from a wire, so its signature cannot be verified")
+	}
+	sig := data.Signature()
+	if sig == nil || sig.KeyName() == nil {
+		return errors.New("data has no key locator")
+	}
+	return defaultChainValidator.validate(app, app.Timer(), data.Name(), covered.SigCovered(), sig, anchors, nil)
+}
+
+// Certificate returns the validated certificate of keyName, fetching the chain by app if needed.
+func (v *ChainValidator) Certificate(app ndn.Engine, keyName enc.Name) (*Certificate, error) {
+	return v.resolve(app, app.Timer(), keyName, v.Anchors, nil)
+}
+
+// Validate validates the signature of a packet named name by its certificate chain, fetched by app if needed.
+func (v *ChainValidator) Validate(app ndn.Engine, name enc.Name, sigCovered enc.Wire, sig ndn.Signature) error {
+	return v.validate(app, app.Timer(), name, sigCovered, sig, v.Anchors, nil)
+}
+
+// ValidateKnown is Validate without fetching, which only uses the anchors and cached certificates.
+// It never blocks, so it can be called on the goroutine receiving packets.
+func (v *ChainValidator) ValidateKnown(timer ndn.Timer, name enc.Name, sigCovered enc.Wire, sig ndn.Signature) error {
+	return v.validate(nil, timer, name, sigCovered, sig, v.Anchors, nil)
+}
+
+// validate and resolve fetch certificates by app unless it is nil.
+func (v *ChainValidator) validate(
+	app ndn.Engine, timer ndn.Timer, name enc.Name, sigCovered enc.Wire, sig ndn.Signature,
+	anchors []*Certificate, visited []*Certificate,
+) error {
+	if sig == nil || sigCovered == nil {
+		return errors.New("packet is not signed")
+	}
+	keyName := sig.KeyName()
+	if keyName == nil {
+		return errors.New("signature has no key locator")
+	}
+	if v.CheckSigner != nil {
+		if err := v.CheckSigner(name, keyName); err != nil {
+			return err
+		}
+	}
+	cert, err := v.resolve(app, timer, keyName, anchors, visited)
+	if err != nil {
+		return err
+	}
+	if !sig.IsValidAt(timer.Now()) {
+		return errors.New("signature is out of its validity period")
+	}
+	var valid bool
+	if v.VerifyCache != nil {
+		valid = v.VerifyCache.Verify(sigCovered, sig, cert, timer.Now())
+	} else {
+		valid = PublicKeyValidate(sigCovered, sig, cert.PublicKey)
+	}
+	if !valid {
+		return fmt.Errorf("wrong signature by %s", cert.Name.String())
+	}
+	return nil
+}
+
+// resolve returns the certificate of keyName validated up to one of anchors.
+// visited are the certificates in the chain being validated.
+func (v *ChainValidator) resolve(
+	app ndn.Engine, timer ndn.Timer, keyName enc.Name, anchors []*Certificate, visited []*Certificate,
+) (*Certificate, error) {
+	now := timer.Now()
+	for _, anchor := range anchors {
+		if anchor.Name.Equal(keyName) || anchor.KeyName.Equal(keyName) {
+			if !anchor.IsValidAt(now) {
+				return nil, fmt.Errorf("trust anchor %s has expired", anchor.Name.String())
+			}
+			return anchor, nil
+		}
+	}
+	if cert := v.cached(keyName, anchors, now); cert != nil {
+		return cert, nil
+	}
+	if app == nil {
+		return nil, fmt.Errorf("certificate %s is not known", keyName.String())
+	}
+	if len(visited) >= v.MaxDepth {
+		return nil, ErrChainTooLong
+	}
+
+	data, sigCovered, err := v.fetch(app, keyName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch certificate %s: %w", keyName.String(), err)
+	}
+	cert, err := ParseCertificate(data)
+	if err != nil {
+		return nil, err
+	}
+	if !keyName.IsPrefix(cert.Name) {
+		return nil, fmt.Errorf("certificate %s does not match key locator %s", cert.Name.String(), keyName.String())
+	}
+	for _, c := range visited {
+		if c.Name.Equal(cert.Name) {
+			return nil, fmt.Errorf("certificate %s is in a loop", cert.Name.String())
+		}
+	}
+	if !cert.IsValidAt(now) {
+		return nil, fmt.Errorf("certificate %s has expired", cert.Name.String())
+	}
+	chain := append(visited[:len(visited):len(visited)], cert)
+	if err := v.validate(app, timer, cert.Name, sigCovered, data.Signature(), anchors, chain); err != nil {
+		return nil, fmt.Errorf("certificate %s is not trusted: %w", cert.Name.String(), err)
+	}
+
+	anchor, notAfter := v.issuerOf(cert.KeyLocator, anchors)
+	if cert.NotAfter.Before(notAfter) {
+		notAfter = cert.NotAfter
+	}
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if v.cache == nil {
+		v.cache = make(map[string]*list.Element)
+		v.lru = list.New()
+	}
+	if elem, ok := v.cache[keyName.String()]; ok {
+		v.remove(elem)
+	}
+	v.cache[keyName.String()] = v.lru.PushFront(&chainEntry{
+		keyName:  keyName.String(),
+		cert:     cert,
+		anchor:   anchor,
+		notAfter: notAfter,
+	})
+	for v.MaxCerts > 0 && v.lru.Len() > v.MaxCerts {
+		v.remove(v.lru.Back())
+	}
+	return cert, nil
+}
+
+// issuerOf returns the anchor name that the certificate of keyName chains to, and the earliest expiry in the chain.
+// The chain must be validated.
+func (v *ChainValidator) issuerOf(keyName enc.Name, anchors []*Certificate) (enc.Name, time.Time) {
+	for _, anchor := range anchors {
+		if anchor.Name.Equal(keyName) || anchor.KeyName.Equal(keyName) {
+			return anchor.Name, anchor.NotAfter
+		}
+	}
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if elem, ok := v.cache[keyName.String()]; ok {
+		entry := elem.Value.(*chainEntry)
+		return entry.anchor, entry.notAfter
+	}
+	return nil, time.Time{}
+}
+
+// cached returns a cached certificate of keyName that is unexpired and chains to one of anchors.
+func (v *ChainValidator) cached(keyName enc.Name, anchors []*Certificate, now time.Time) *Certificate {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	elem, ok := v.cache[keyName.String()]
+	if !ok {
+		return nil
+	}
+	entry := elem.Value.(*chainEntry)
+	if !entry.cert.IsValidAt(now) || now.After(entry.notAfter) {
+		v.remove(elem)
+		return nil
+	}
+	for _, anchor := range anchors {
+		if anchor.Name.Equal(entry.anchor) && anchor.IsValidAt(now) {
+			v.lru.MoveToFront(elem)
+			return entry.cert
+		}
+	}
+	return nil
+}
+
+// Invalidate removes the cached certificates whose names start with name, and the ones chaining to them.
+// The signatures verified by them are removed from the VerifyCache too.
+func (v *ChainValidator) Invalidate(name enc.Name) {
+	v.lock.Lock()
+	removed := []enc.Name{}
+	for changed := v.lru != nil; changed; {
+		changed = false
+		for elem := v.lru.Front(); elem != nil; {
+			next := elem.Next()
+			cert := elem.Value.(*chainEntry).cert
+			revoked := name.IsPrefix(cert.Name)
+			for _, r := range removed {
+				revoked = revoked || cert.KeyLocator.IsPrefix(r)
+			}
+			if revoked {
+				v.remove(elem)
+				removed = append(removed, cert.Name)
+				changed = true
+			}
+			elem = next
+		}
+	}
+	v.lock.Unlock()
+	if v.VerifyCache != nil {
+		v.VerifyCache.Invalidate(name)
+		for _, r := range removed {
+			v.VerifyCache.Invalidate(r)
+		}
+	}
+}
+
+// InvalidateAll removes all cached certificates and verified signatures.
+func (v *ChainValidator) InvalidateAll() {
+	v.lock.Lock()
+	v.cache = nil
+	v.lru = nil
+	v.lock.Unlock()
+	if v.VerifyCache != nil {
+		v.VerifyCache.InvalidateAll()
+	}
+}
+
+// CachedCerts returns the number of certificates cached.
+func (v *ChainValidator) CachedCerts() int {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	return len(v.cache)
+}
+
+// remove must be called with the lock held.
+func (v *ChainValidator) remove(elem *list.Element) {
+	entry := v.lru.Remove(elem).(*chainEntry)
+	delete(v.cache, entry.keyName)
+}
+
+func (v *ChainValidator) fetch(app ndn.Engine, keyName enc.Name) (ndn.Data, enc.Wire, error) {
+	intCfg := &ndn.InterestConfig{
+		CanBePrefix: !IsCertName(keyName),
+		Lifetime:    utils.IdPtr(v.Lifetime),
+		Nonce:       utils.ConvertNonce(app.Timer().Nonce()),
+	}
+	wire, _, finalName, err := app.Spec().MakeInterest(keyName, intCfg, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	type result struct {
+		data       ndn.Data
+		sigCovered enc.Wire
+		err        error
+	}
+	ch := make(chan result, 1)
+	err = app.Express(finalName, intCfg, wire,
+		func(res ndn.InterestResult, data ndn.Data, _, sigCovered enc.Wire, nackReason uint64) {
+			switch res {
+			case ndn.InterestResultData:
+				ch <- result{data: data, sigCovered: sigCovered}
+			case ndn.InterestResultNack:
+				ch <- result{err: fmt.Errorf("nack received: %v", nackReason)}
+			case ndn.InterestResultTimeout:
+				ch <- result{err: ndn.ErrDeadlineExceed}
+			default:
+				ch <- result{err: fmt.Errorf("unable to fetch: %v", res)}
+			}
+		})
+	if err != nil {
+		return nil, nil, err
+	}
+	ret := <-ch
+	return ret.data, ret.sigCovered, ret.err
+}
@@ -0,0 +1,182 @@
+package security_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	basic_engine "github.com/zjkmxy/go-ndn/pkg/engine/basic"
+	"github.com/zjkmxy/go-ndn/pkg/engine/dummy"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/ndn/spec_2022"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+type testKey struct {
+	name enc.Name
+	pub  ed25519.PublicKey
+	priv ed25519.PrivateKey
+}
+
+func newTestKey(t testing.TB, identity string) testKey {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	id, err := enc.NameFromStr(identity)
+	require.NoError(t, err)
+	return testKey{
+		name: sec.MakeKeyName(id, enc.NewBytesComponent(enc.TypeGenericNameComponent, pub[:8])),
+		pub:  pub,
+		priv: priv,
+	}
+}
+
+func (k testKey) signer() ndn.Signer {
+	return sec.NewEd25519Signer(k.name, k.priv)
+}
+
+// certificate makes the certificate of k signed by issuer, valid from now-1h to now+validFor.
+func (k testKey) certificate(t testing.TB, issuer testKey, validFor time.Duration) enc.Wire {
+	now := time.Now()
+	wire, err := sec.EncodeCertificate(
+		k.name, enc.NewStringComponent(enc.TypeGenericNameComponent, "issuer"), 1, k.pub,
+		now.Add(-time.Hour), now.Add(validFor), issuer.signer())
+	require.NoError(t, err)
+	return wire
+}
+
+func parseCert(t testing.TB, wire enc.Wire) *sec.Certificate {
+	data, _, err := spec_2022.Spec{}.ReadData(enc.NewWireReader(wire))
+	require.NoError(t, err)
+	cert, err := sec.ParseCertificate(data)
+	require.NoError(t, err)
+	return cert
+}
+
+// serveChain starts a consumer engine connected to a producer engine serving certs under /test.
+func serveChain(t *testing.T, certs map[string]enc.Wire) *basic_engine.Engine {
+	consFace, prodFace := dummy.NewPipeFaces()
+	timer := basic_engine.NewTimer()
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool { return true }
+	prod := basic_engine.NewEngine(prodFace, timer, sec.NewSha256IntSigner(timer), passAll)
+	cons := basic_engine.NewEngine(consFace, timer, sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(t, prod.Start())
+	require.NoError(t, cons.Start())
+	t.Cleanup(func() {
+		cons.Shutdown()
+		prod.Shutdown()
+	})
+
+	prefix := utils.WithoutErr(enc.NameFromStr("/test"))
+	require.NoError(t, prod.AttachHandler(prefix, func(
+		interest ndn.Interest, _ enc.Wire, _ enc.Wire, reply ndn.ReplyFunc, _ time.Time,
+	) {
+		if wire, ok := certs[interest.Name().String()]; ok {
+			reply(wire)
+		}
+	}))
+	return cons
+}
+
+func TestValidateChain(t *testing.T) {
+	utils.SetTestingT(t)
+
+	root := newTestKey(t, "/test")
+	site := newTestKey(t, "/test/site")
+	user := newTestKey(t, "/test/site/user")
+	anchor := parseCert(t, root.certificate(t, root, time.Hour))
+	certs := map[string]enc.Wire{
+		site.name.String(): site.certificate(t, root, time.Hour),
+		user.name.String(): user.certificate(t, site, time.Hour),
+	}
+	app := serveChain(t, certs)
+
+	data, sigCovered := makeSignedData(t, "/test/site/user/data", "hello", user.signer())
+
+	// Two-level chain: user <- site <- root
+	require.NoError(t, sec.ValidateChain(app, data, []*sec.Certificate{anchor}))
+	// The Data itself must be signed by the key of its key locator
+	wire, _, err := spec_2022.Spec{}.MakeData(data.Name(),
+		&ndn.DataConfig{ContentType: utils.IdPtr(ndn.ContentTypeBlob)}, enc.Wire{[]byte("hello")}, user.signer())
+	require.NoError(t, err)
+	tampered := wire.Join()
+	tampered[bytes.Index(tampered, []byte("hello"))] = 'j'
+	tamperedData, _, err := spec_2022.Spec{}.ReadData(enc.NewBufferReader(tampered))
+	require.NoError(t, err)
+	require.ErrorContains(t, sec.ValidateChain(app, tamperedData, []*sec.Certificate{anchor}), "wrong signature")
+	forger := newTestKey(t, "/test/site/user")
+	forger.name = user.name
+	forged, _ := makeSignedData(t, "/test/site/user/data", "hello", forger.signer())
+	require.ErrorContains(t, sec.ValidateChain(app, forged, []*sec.Certificate{anchor}), "wrong signature")
+	// A Data not parsed from a wire has no signed portion to verify
+	require.Error(t, sec.ValidateChain(app, &spec_2022.Data{NameV: data.Name()}, []*sec.Certificate{anchor}))
+	v := sec.NewChainValidator([]*sec.Certificate{anchor})
+	require.NoError(t, v.Validate(app, data.Name(), sigCovered, data.Signature()))
+	// The chain is cached after validated
+	require.NoError(t, v.ValidateKnown(app.Timer(), data.Name(), sigCovered, data.Signature()))
+
+	// Another anchor does not trust the chain
+	other := newTestKey(t, "/other")
+	otherAnchor := parseCert(t, other.certificate(t, other, time.Hour))
+	v = sec.NewChainValidator([]*sec.Certificate{otherAnchor})
+	v.Lifetime = 100 * time.Millisecond
+	require.Error(t, v.Validate(app, data.Name(), sigCovered, data.Signature()))
+
+	// Depth cap
+	v = sec.NewChainValidator([]*sec.Certificate{anchor})
+	v.MaxDepth = 1
+	require.ErrorIs(t, v.Validate(app, data.Name(), sigCovered, data.Signature()), sec.ErrChainTooLong)
+	// Nothing is fetched without an engine
+	require.Error(t, v.ValidateKnown(app.Timer(), data.Name(), sigCovered, data.Signature()))
+}
+
+func TestValidateChainBroken(t *testing.T) {
+	utils.SetTestingT(t)
+
+	root := newTestKey(t, "/test")
+	site := newTestKey(t, "/test/site")
+	user := newTestKey(t, "/test/site/user")
+	rogue := newTestKey(t, "/test")
+	rogue.name = root.name
+	anchor := parseCert(t, root.certificate(t, root, time.Hour))
+
+	makeData := func() ndn.Data {
+		data, _ := makeSignedData(t, "/test/site/user/data", "hello", user.signer())
+		return data
+	}
+
+	// The intermediate is signed by a key with the root name but not the root key
+	app := serveChain(t, map[string]enc.Wire{
+		site.name.String(): site.certificate(t, rogue, time.Hour),
+		user.name.String(): user.certificate(t, site, time.Hour),
+	})
+	require.Error(t, sec.ValidateChain(app, makeData(), []*sec.Certificate{anchor}))
+
+	// The intermediate has expired
+	app = serveChain(t, map[string]enc.Wire{
+		site.name.String(): site.certificate(t, root, -time.Minute),
+		user.name.String(): user.certificate(t, site, time.Hour),
+	})
+	require.Error(t, sec.ValidateChain(app, makeData(), []*sec.Certificate{anchor}))
+
+	// The intermediate is missing
+	app = serveChain(t, map[string]enc.Wire{
+		user.name.String(): user.certificate(t, site, time.Hour),
+	})
+	v := sec.NewChainValidator([]*sec.Certificate{anchor})
+	v.Lifetime = 100 * time.Millisecond
+	_, err := v.Certificate(app, user.name)
+	require.Error(t, err)
+
+	// Certificates signing each other never reach the anchor
+	app = serveChain(t, map[string]enc.Wire{
+		site.name.String(): site.certificate(t, user, time.Hour),
+		user.name.String(): user.certificate(t, site, time.Hour),
+	})
+	_, err = v.Certificate(app, user.name)
+	require.ErrorContains(t, err, "loop")
+}
@@ -77,3 +77,17 @@ func NewEccSigner(
 		certExpireTime: expireTime,
 	}
 }
+
+// NewEcdsaSigner creates a Data signer using ECDSA key, with the KeyLocator set to name.
+// The signature value is an ASN.1 DER encoded SEQUENCE of R and S, as ndn-cxx expects.
+// Its length varies per signature, so EstimateSize gives the maximum and the encoder shrinks the TLV after signing.
+func NewEcdsaSigner(name enc.Name, key *ecdsa.PrivateKey) ndn.Signer {
+	return NewEccSigner(false, false, 0, key, name)
+}
+
+// NewEcdsaVerifier creates a SigChecker that accepts packets signed by the given ECDSA public key.
+func NewEcdsaVerifier(pubKey *ecdsa.PublicKey) ndn.SigChecker {
+	return func(_ enc.Name, sigCovered enc.Wire, sig ndn.Signature) bool {
+		return EcdsaValidate(sigCovered, sig, pubKey)
+	}
+}
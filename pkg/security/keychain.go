@@ -0,0 +1,93 @@
+package security
+
+import (
+	"sync"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+)
+
+type keyChainEntry struct {
+	identity enc.Name
+	signer   ndn.Signer
+}
+
+// KeyChain holds signers of multiple identities and selects one for a given Data name.
+// It implements ndn.SignerSelector and is safe for concurrent use.
+type KeyChain struct {
+	lock          sync.RWMutex
+	entries       []keyChainEntry
+	defaultSigner ndn.Signer
+}
+
+// AddSigner adds the signer of an identity. An existing signer of the same identity is replaced.
+func (kc *KeyChain) AddSigner(identity enc.Name, signer ndn.Signer) {
+	kc.lock.Lock()
+	defer kc.lock.Unlock()
+
+	for i, e := range kc.entries {
+		if e.identity.Equal(identity) {
+			kc.entries[i].signer = signer
+			return
+		}
+	}
+	kc.entries = append(kc.entries, keyChainEntry{identity: identity, signer: signer})
+}
+
+// RemoveSigner removes the signer of an identity, if any.
+func (kc *KeyChain) RemoveSigner(identity enc.Name) {
+	kc.lock.Lock()
+	defer kc.lock.Unlock()
+
+	for i, e := range kc.entries {
+		if e.identity.Equal(identity) {
+			kc.entries = append(kc.entries[:i], kc.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetDefaultSigner sets the signer used when no identity is a prefix of the Data name.
+func (kc *KeyChain) SetDefaultSigner(signer ndn.Signer) {
+	kc.lock.Lock()
+	defer kc.lock.Unlock()
+
+	kc.defaultSigner = signer
+}
+
+// Signer returns the signer of exactly the given identity, or nil.
+func (kc *KeyChain) Signer(identity enc.Name) ndn.Signer {
+	kc.lock.RLock()
+	defer kc.lock.RUnlock()
+
+	for _, e := range kc.entries {
+		if e.identity.Equal(identity) {
+			return e.signer
+		}
+	}
+	return nil
+}
+
+// SignerForName returns the signer of the longest identity that is a prefix of dataName.
+// If there is no such identity, the default signer is returned, which may be nil.
+func (kc *KeyChain) SignerForName(dataName enc.Name) ndn.Signer {
+	kc.lock.RLock()
+	defer kc.lock.RUnlock()
+
+	ret := kc.defaultSigner
+	bestLen := -1
+	for _, e := range kc.entries {
+		if len(e.identity) > bestLen && e.identity.IsPrefix(dataName) {
+			ret = e.signer
+			bestLen = len(e.identity)
+		}
+	}
+	return ret
+}
+
+// NewKeyChain creates an empty KeyChain.
+func NewKeyChain() *KeyChain {
+	return &KeyChain{
+		entries: make([]keyChainEntry, 0),
+	}
+}
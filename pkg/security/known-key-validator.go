@@ -12,7 +12,8 @@ import (
 	"github.com/zjkmxy/go-ndn/pkg/ndn"
 )
 
-// HmacValidate verifies the sha256 digest.
+// Sha256Validate verifies a DigestSha256 signature, i.e. the SHA-256 digest of sigCovered.
+// It only checks the integrity of a packet, since no key is involved.
 func Sha256Validate(sigCovered enc.Wire, sig ndn.Signature) bool {
 	if sig.SigType() != ndn.SignatureDigestSha256 {
 		return false
@@ -78,3 +79,18 @@ func EddsaValidate(sigCovered enc.Wire, sig ndn.Signature, pubKey ed25519.Public
 	}
 	return ed25519.Verify(pubKey, sigCovered.Join(), sig.SigValue())
 }
+
+// PublicKeyValidate verifies the signature with a known public key of any supported type,
+// e.g. the PublicKey of a Certificate.
+func PublicKeyValidate(sigCovered enc.Wire, sig ndn.Signature, pubKey crypto.PublicKey) bool {
+	switch key := pubKey.(type) {
+	case *ecdsa.PublicKey:
+		return EcdsaValidate(sigCovered, sig, key)
+	case *rsa.PublicKey:
+		return RsaValidate(sigCovered, sig, key)
+	case ed25519.PublicKey:
+		return EddsaValidate(sigCovered, sig, key)
+	default:
+		return false
+	}
+}
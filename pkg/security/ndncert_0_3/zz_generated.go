@@ -421,6 +421,8 @@ func (context *CaProfileParsingContext) Parse(reader enc.ParseReader, ignoreCrit
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -724,6 +726,8 @@ func (context *ProbeIntAppParamParsingContext) Parse(reader enc.ParseReader, ign
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -940,6 +944,8 @@ func (context *ProbeResParsingContext) Parse(reader enc.ParseReader, ignoreCriti
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -1173,6 +1179,8 @@ func (context *ProbeResContentParsingContext) Parse(reader enc.ParseReader, igno
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -1371,6 +1379,8 @@ func (context *CmdNewIntParsingContext) Parse(reader enc.ParseReader, ignoreCrit
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -1739,6 +1749,8 @@ func (context *CmdNewDataParsingContext) Parse(reader enc.ParseReader, ignoreCri
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -1995,6 +2007,8 @@ func (context *CipherMsgParsingContext) Parse(reader enc.ParseReader, ignoreCrit
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -2338,6 +2352,8 @@ func (context *ChallengeIntPlainParsingContext) Parse(reader enc.ParseReader, ig
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -2999,6 +3015,8 @@ func (context *ChallengeDataPlainParsingContext) Parse(reader enc.ParseReader, i
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -3227,6 +3245,8 @@ func (context *ErrorMsgDataParsingContext) Parse(reader enc.ParseReader, ignoreC
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
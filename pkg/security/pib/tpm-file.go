@@ -48,14 +48,14 @@ func (tpm *FileTpm) GetSigner(keyName enc.Name, keyLocatorName enc.Name) ndn.Sig
 	if err == nil {
 		// ECC Key
 		// TODO: Handle for Interest
-		return sec.NewEccSigner(false, false, 0, eckbits, keyLocatorName)
+		return sec.NewEcdsaSigner(keyLocatorName, eckbits)
 	}
 
 	rsabits, err := x509.ParsePKCS1PrivateKey(block)
 	if err == nil {
 		// RSA Key
 		// TODO: Handle for Interest
-		return sec.NewRsaSigner(false, false, 0, rsabits, keyLocatorName)
+		return sec.NewRsaDataSigner(keyLocatorName, rsabits)
 	}
 
 	log.WithField("module", "FileTpm").Errorf("unrecognized private key format: %s", fileName)
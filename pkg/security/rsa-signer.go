@@ -4,6 +4,10 @@ import (
 	"crypto"
 	"crypto/rsa"
 	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
 	"time"
 
 	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
@@ -12,7 +16,7 @@ import (
 	"github.com/zjkmxy/go-ndn/pkg/utils"
 )
 
-// rsaSigner is a signer that uses ECC key to sign packets.
+// rsaSigner is a signer that uses RSA key to sign packets.
 type rsaSigner struct {
 	timer ndn.Timer
 	seq   uint64
@@ -27,7 +31,7 @@ type rsaSigner struct {
 
 func (s *rsaSigner) SigInfo() (*ndn.SigConfig, error) {
 	ret := &ndn.SigConfig{
-		Type:    ndn.SignatureSha256WithEcdsa,
+		Type:    ndn.SignatureSha256WithRsa,
 		KeyName: s.keyLocatorName,
 	}
 	if s.forCert {
@@ -59,8 +63,8 @@ func (s *rsaSigner) ComputeSigValue(covered enc.Wire) ([]byte, error) {
 	return rsa.SignPKCS1v15(nil, s.key, crypto.SHA256, digest)
 }
 
-// NewRsaSigner creates a signer using RSA key
-func NewRsaSigner(
+// NewRsaSignerEx creates a signer using RSA key, optionally for certificates or Interests.
+func NewRsaSignerEx(
 	forCert bool, forInt bool, expireTime time.Duration, key *rsa.PrivateKey,
 	keyLocatorName enc.Name,
 ) ndn.Signer {
@@ -76,3 +80,43 @@ func NewRsaSigner(
 		certExpireTime: expireTime,
 	}
 }
+
+// NewRsaSigner creates a signer using RSA key
+//
+// Deprecated: use NewRsaDataSigner for Data, or NewRsaSignerEx, which takes the same arguments.
+func NewRsaSigner(
+	forCert bool, forInt bool, expireTime time.Duration, key *rsa.PrivateKey,
+	keyLocatorName enc.Name,
+) ndn.Signer {
+	return NewRsaSignerEx(forCert, forInt, expireTime, key, keyLocatorName)
+}
+
+// NewRsaDataSigner creates a Data signer using RSA key, with the KeyLocator set to name.
+// RSA signatures always have the length of the modulus, which EstimateSize reports.
+func NewRsaDataSigner(name enc.Name, key *rsa.PrivateKey) ndn.Signer {
+	return NewRsaSignerEx(false, false, 0, key, name)
+}
+
+// RsaSignerFromPem creates an RSA Data signer from a PKCS#8 PEM block.
+func RsaSignerFromPem(name enc.Name, pemData []byte) (ndn.Signer, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, errors.New("no PEM block is found")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, ndn.ErrInvalidValue{Item: "key type", Value: fmt.Sprintf("%T", key)}
+	}
+	return NewRsaDataSigner(name, rsaKey), nil
+}
+
+// NewRsaVerifier creates a SigChecker that accepts packets signed by the given RSA public key.
+func NewRsaVerifier(pubKey *rsa.PublicKey) ndn.SigChecker {
+	return func(_ enc.Name, sigCovered enc.Wire, sig ndn.Signature) bool {
+		return RsaValidate(sigCovered, sig, pubKey)
+	}
+}
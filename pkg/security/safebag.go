@@ -0,0 +1,326 @@
+package security
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	spec "github.com/zjkmxy/go-ndn/pkg/ndn/spec_2022"
+)
+
+// TLV types of the SafeBag format of ndn-cxx.
+const (
+	TypeSafeBag         enc.TLNum = 0x80
+	TypeEncryptedKeyBag enc.TLNum = 0x81
+)
+
+// SafeBagIterations is the PBKDF2 iteration count used by ExportSafeBag, the same as OpenSSL's default.
+const SafeBagIterations = 2048
+
+var (
+	oidPbes2        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPbkdf2       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHmacWithSha1 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHmacWithSha2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidDesEde3Cbc   = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+	oidAes128Cbc    = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAes256Cbc    = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+// encryptedPrivateKeyInfo is the PKCS#8 EncryptedPrivateKeyInfo.
+type encryptedPrivateKeyInfo struct {
+	Algo          pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	Prf            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// ParseSafeBag parses a SafeBag exported by ndnsec or ExportSafeBag, which contains a certificate and its private key
+// encrypted by passphrase. It returns a Data signer of the key with the KeyLocator set to the key name.
+// RSA, ECDSA and Ed25519 keys are supported.
+func ParseSafeBag(wire enc.Wire, passphrase []byte) (signer ndn.Signer, cert *Certificate, err error) {
+	r := enc.NewWireReader(wire)
+	typ, err := enc.ReadTLNum(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if typ != TypeSafeBag {
+		return nil, nil, ndn.ErrInvalidValue{Item: "SafeBag.Type", Value: typ}
+	}
+	l, err := enc.ReadTLNum(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := r.ReadWire(int(l))
+	if err != nil {
+		return nil, nil, err
+	}
+	r = enc.NewWireReader(body)
+
+	// ReadData takes the whole reader, so the certificate TLV is read first
+	if _, err = enc.ReadTLNum(r); err != nil {
+		return nil, nil, err
+	}
+	if l, err = enc.ReadTLNum(r); err != nil {
+		return nil, nil, err
+	}
+	if err = r.Skip(int(l)); err != nil {
+		return nil, nil, err
+	}
+	data, _, err := spec.Spec{}.ReadData(enc.NewWireReader(r.Range(0, r.Pos())))
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to parse the certificate: %w", err)
+	}
+	cert, err = ParseCertificate(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	typ, err = enc.ReadTLNum(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if typ != TypeEncryptedKeyBag {
+		return nil, nil, ndn.ErrInvalidValue{Item: "EncryptedKeyBag.Type", Value: typ}
+	}
+	l, err = enc.ReadTLNum(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyBag, err := r.ReadBuf(int(l))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	der, err := decryptPkcs8(keyBag, passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !publicKeyEqual(key, cert.PublicKey) {
+		return nil, nil, errors.New("the private key does not match the certificate")
+	}
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		signer = NewRsaDataSigner(cert.KeyName, key)
+	case *ecdsa.PrivateKey:
+		signer = NewEcdsaSigner(cert.KeyName, key)
+	case ed25519.PrivateKey:
+		signer = NewEd25519Signer(cert.KeyName, key)
+	default:
+		return nil, nil, ndn.ErrNotSupported{Item: fmt.Sprintf("%T", key)}
+	}
+	return signer, cert, nil
+}
+
+// ExportSafeBag makes a SafeBag of the encoded certificate and its private key, which can be imported by ndnsec.
+// The key is encrypted in PKCS#8 by passphrase with PBES2, using PBKDF2 and DES-EDE3-CBC as ndn-cxx does.
+func ExportSafeBag(certWire enc.Wire, key crypto.PrivateKey, passphrase []byte) (enc.Wire, error) {
+	data, _, err := spec.Spec{}.ReadData(enc.NewWireReader(certWire))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the certificate: %w", err)
+	}
+	cert, err := ParseCertificate(data)
+	if err != nil {
+		return nil, err
+	}
+	if !publicKeyEqual(key, cert.PublicKey) {
+		return nil, errors.New("the private key does not match the certificate")
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	keyBag, err := encryptPkcs8(der, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	certLen := int(certWire.Length())
+	keyBagLen := TypeEncryptedKeyBag.EncodingLength() + enc.TLNum(len(keyBag)).EncodingLength() + len(keyBag)
+	l := enc.TLNum(certLen + keyBagLen)
+	buf := make(enc.Buffer, TypeSafeBag.EncodingLength()+l.EncodingLength()+int(l))
+	pos := TypeSafeBag.EncodeInto(buf)
+	pos += l.EncodeInto(buf[pos:])
+	pos += copy(buf[pos:], certWire.Join())
+	pos += TypeEncryptedKeyBag.EncodeInto(buf[pos:])
+	pos += enc.TLNum(len(keyBag)).EncodeInto(buf[pos:])
+	copy(buf[pos:], keyBag)
+	return enc.Wire{buf}, nil
+}
+
+func publicKeyEqual(key crypto.PrivateKey, pub crypto.PublicKey) bool {
+	priv, ok := key.(interface{ Public() crypto.PublicKey })
+	if !ok {
+		return false
+	}
+	cmp, ok := priv.Public().(interface{ Equal(crypto.PublicKey) bool })
+	return ok && cmp.Equal(pub)
+}
+
+// decryptPkcs8 decrypts a DER encoded PKCS#8 EncryptedPrivateKeyInfo into a PrivateKeyInfo.
+func decryptPkcs8(der []byte, passphrase []byte) ([]byte, error) {
+	info := encryptedPrivateKeyInfo{}
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, fmt.Errorf("unable to parse the encrypted key: %w", err)
+	}
+	if !info.Algo.Algorithm.Equal(oidPbes2) {
+		return nil, ndn.ErrNotSupported{Item: "key encryption " + info.Algo.Algorithm.String()}
+	}
+	params := pbes2Params{}
+	if _, err := asn1.Unmarshal(info.Algo.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("unable to parse PBES2 parameters: %w", err)
+	}
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPbkdf2) {
+		return nil, ndn.ErrNotSupported{Item: "key derivation " + params.KeyDerivationFunc.Algorithm.String()}
+	}
+	kdf := pbkdf2Params{}
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+		return nil, fmt.Errorf("unable to parse PBKDF2 parameters: %w", err)
+	}
+	var prf func() hash.Hash
+	switch {
+	case kdf.Prf.Algorithm == nil || kdf.Prf.Algorithm.Equal(oidHmacWithSha1):
+		prf = sha1.New
+	case kdf.Prf.Algorithm.Equal(oidHmacWithSha2):
+		prf = sha256.New
+	default:
+		return nil, ndn.ErrNotSupported{Item: "PBKDF2 PRF " + kdf.Prf.Algorithm.String()}
+	}
+
+	var newCipher func([]byte) (cipher.Block, error)
+	var keyLen int
+	switch scheme := params.EncryptionScheme.Algorithm; {
+	case scheme.Equal(oidDesEde3Cbc):
+		newCipher, keyLen = des.NewTripleDESCipher, 24
+	case scheme.Equal(oidAes128Cbc):
+		newCipher, keyLen = aes.NewCipher, 16
+	case scheme.Equal(oidAes256Cbc):
+		newCipher, keyLen = aes.NewCipher, 32
+	default:
+		return nil, ndn.ErrNotSupported{Item: "key encryption scheme " + scheme.String()}
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, fmt.Errorf("unable to parse the IV: %w", err)
+	}
+
+	block, err := newCipher(pbkdf2Key(passphrase, kdf.Salt, kdf.IterationCount, keyLen, prf))
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != block.BlockSize() || len(info.EncryptedData)%block.BlockSize() != 0 || len(info.EncryptedData) == 0 {
+		return nil, errors.New("malformed encrypted key")
+	}
+	plain := make([]byte, len(info.EncryptedData))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, info.EncryptedData)
+	// A wrong passphrase is usually detected by the padding
+	pad := int(plain[len(plain)-1])
+	if pad == 0 || pad > block.BlockSize() || !bytes.Equal(plain[len(plain)-pad:], bytes.Repeat([]byte{byte(pad)}, pad)) {
+		return nil, errors.New("unable to decrypt the key: wrong passphrase")
+	}
+	return plain[:len(plain)-pad], nil
+}
+
+// encryptPkcs8 encrypts a DER encoded PrivateKeyInfo into an EncryptedPrivateKeyInfo.
+func encryptPkcs8(der []byte, passphrase []byte) ([]byte, error) {
+	salt := make([]byte, 8)
+	iv := make([]byte, des.BlockSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	block, err := des.NewTripleDESCipher(pbkdf2Key(passphrase, salt, SafeBagIterations, 24, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+	pad := block.BlockSize() - len(der)%block.BlockSize()
+	plain := append(bytes.Clone(der), bytes.Repeat([]byte{byte(pad)}, pad)...)
+	encrypted := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, plain)
+
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt:           salt,
+		IterationCount: SafeBagIterations,
+		Prf:            pkix.AlgorithmIdentifier{Algorithm: oidHmacWithSha2, Parameters: asn1.NullRawValue},
+	})
+	if err != nil {
+		return nil, err
+	}
+	ivParam, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+	params, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{Algorithm: oidPbkdf2, Parameters: asn1.RawValue{FullBytes: kdfParams}},
+		EncryptionScheme:  pkix.AlgorithmIdentifier{Algorithm: oidDesEde3Cbc, Parameters: asn1.RawValue{FullBytes: ivParam}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		Algo:          pkix.AlgorithmIdentifier{Algorithm: oidPbes2, Parameters: asn1.RawValue{FullBytes: params}},
+		EncryptedData: encrypted,
+	})
+}
+
+// pbkdf2Key derives a key from password by PBKDF2 defined in RFC 8018.
+func pbkdf2Key(password, salt []byte, iter int, keyLen int, h func() hash.Hash) []byte {
+	prf := hmac.New(h, password)
+	hashLen := prf.Size()
+	numBlocks := (keyLen + hashLen - 1) / hashLen
+	dk := make([]byte, 0, numBlocks*hashLen)
+	u := make([]byte, hashLen)
+	var idx [4]byte
+	for i := 1; i <= numBlocks; i++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(idx[:], uint32(i))
+		prf.Write(idx[:])
+		dk = prf.Sum(dk)
+		t := dk[len(dk)-hashLen:]
+		copy(u, t)
+		for n := 2; n <= iter; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for x := range u {
+				t[x] ^= u[x]
+			}
+		}
+	}
+	return dk[:keyLen]
+}
@@ -0,0 +1,72 @@
+package security_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+func TestParseSafeBag(t *testing.T) {
+	utils.SetTestingT(t)
+
+	// An ECDSA P-256 identity in the SafeBag format of `ndnsec export`, with the key encrypted by
+	// PBES2 (PBKDF2 with HMAC-SHA1, DES-EDE3-CBC) using the passphrase "alice-pass".
+	// It is not exported by ndnsec itself; see testdata/README.md for how it is made.
+	text, err := os.ReadFile("testdata/alice.safebag")
+	require.NoError(t, err)
+	wire, err := base64.StdEncoding.DecodeString(string(text))
+	require.NoError(t, err)
+
+	signer, cert, err := sec.ParseSafeBag(enc.Wire{wire}, []byte("alice-pass"))
+	require.NoError(t, err)
+	require.Equal(t, "/ndn/test/alice/KEY/%1Fp%B2%8A%91%DD%E3%99", cert.KeyName.String())
+	require.Equal(t, "self", cert.IssuerId.String())
+
+	data, sigCovered := makeSignedData(t, "/ndn/test/alice/data", "hello", signer)
+	require.Equal(t, ndn.SignatureSha256WithEcdsa, data.Signature().SigType())
+	require.True(t, cert.KeyName.Equal(data.Signature().KeyName()))
+	require.True(t, sec.PublicKeyValidate(sigCovered, data.Signature(), cert.PublicKey))
+
+	_, _, err = sec.ParseSafeBag(enc.Wire{wire}, []byte("wrong-pass"))
+	require.Error(t, err)
+	_, _, err = sec.ParseSafeBag(enc.Wire{wire[:len(wire)-1]}, []byte("alice-pass"))
+	require.Error(t, err)
+}
+
+func TestExportSafeBag(t *testing.T) {
+	utils.SetTestingT(t)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	keyName := utils.WithoutErr(enc.NameFromStr("/ndn/test/bob/KEY/k1"))
+	now := time.Now()
+	certWire := utils.WithoutErr(sec.EncodeCertificate(
+		keyName, enc.NewStringComponent(enc.TypeGenericNameComponent, "self"), 1, &key.PublicKey,
+		now, now.Add(time.Hour), sec.NewEcdsaSigner(keyName, key)))
+
+	bag, err := sec.ExportSafeBag(certWire, key, []byte("bob-pass"))
+	require.NoError(t, err)
+	signer, cert, err := sec.ParseSafeBag(bag, []byte("bob-pass"))
+	require.NoError(t, err)
+	require.True(t, keyName.Equal(cert.KeyName))
+	data, sigCovered := makeSignedData(t, "/ndn/test/bob/data", "hello", signer)
+	require.True(t, sec.PublicKeyValidate(sigCovered, data.Signature(), cert.PublicKey))
+
+	_, _, err = sec.ParseSafeBag(bag, []byte("alice-pass"))
+	require.Error(t, err)
+
+	// The key must match the certificate
+	other := newTestKey(t, "/ndn/test/bob")
+	_, err = sec.ExportSafeBag(certWire, other.priv, []byte("bob-pass"))
+	require.Error(t, err)
+}
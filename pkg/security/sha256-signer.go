@@ -33,11 +33,20 @@ func (sha256Signer) ComputeSigValue(covered enc.Wire) ([]byte, error) {
 	return h.Sum(nil), nil
 }
 
-// NewSha256Signer creates a Data signer that uses DigestSha256.
+// NewSha256Signer creates a Data signer that uses DigestSha256, i.e. SignatureType 0.
+// The signature value is the SHA-256 digest of the signed portion and there is no KeyLocator,
+// so anyone can produce it and it only protects the integrity of the Data.
 func NewSha256Signer() ndn.Signer {
 	return sha256Signer{}
 }
 
+// NewSha256Verifier creates a SigChecker that accepts packets with a correct DigestSha256 signature.
+func NewSha256Verifier() ndn.SigChecker {
+	return func(_ enc.Name, sigCovered enc.Wire, sig ndn.Signature) bool {
+		return Sha256Validate(sigCovered, sig)
+	}
+}
+
 // sha256Signer is an Interest signer that uses DigestSha256.
 type sha256IntSigner struct {
 	timer ndn.Timer
@@ -0,0 +1,202 @@
+package security_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/ndn/spec_2022"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+// signAndRead makes a Data packet signed by signer and parses it back.
+func signAndRead(t *testing.T, signer ndn.Signer) (ndn.Data, enc.Wire) {
+	spec := spec_2022.Spec{}
+	wire, _, err := spec.MakeData(
+		utils.WithoutErr(enc.NameFromStr("/test/data")),
+		&ndn.DataConfig{
+			ContentType: utils.IdPtr(ndn.ContentTypeBlob),
+		},
+		enc.Wire{[]byte("hello"), []byte(", "), []byte("world")},
+		signer,
+	)
+	require.NoError(t, err)
+	data, sigCovered, err := spec.ReadData(enc.NewWireReader(wire))
+	require.NoError(t, err)
+	return data, sigCovered
+}
+
+func TestEd25519SignerRoundTrip(t *testing.T) {
+	utils.SetTestingT(t)
+
+	keyName := utils.WithoutErr(enc.NameFromStr("/test/KEY/ed25519"))
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	data, sigCovered := signAndRead(t, sec.NewEd25519Signer(keyName, priv))
+
+	require.Equal(t, ndn.SignatureEd25519, data.Signature().SigType())
+	require.True(t, keyName.Equal(data.Signature().KeyName()))
+	require.Equal(t, ed25519.SignatureSize, len(data.Signature().SigValue()))
+
+	verifier := sec.NewEd25519Verifier(pub)
+	require.True(t, verifier(data.Name(), sigCovered, data.Signature()))
+
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	require.False(t, sec.NewEd25519Verifier(otherPub)(data.Name(), sigCovered, data.Signature()))
+}
+
+func TestEd25519SignerLoaders(t *testing.T) {
+	utils.SetTestingT(t)
+
+	keyName := utils.WithoutErr(enc.NameFromStr("/test/KEY/ed25519"))
+	seed := make([]byte, ed25519.SeedSize)
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	priv := ed25519.NewKeyFromSeed(seed)
+	verifier := sec.NewEd25519Verifier(priv.Public().(ed25519.PublicKey))
+
+	signer := utils.WithoutErr(sec.Ed25519SignerFromSeed(keyName, seed))
+	data, sigCovered := signAndRead(t, signer)
+	require.True(t, verifier(data.Name(), sigCovered, data.Signature()))
+
+	der := utils.WithoutErr(x509.MarshalPKCS8PrivateKey(priv))
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	signer = utils.WithoutErr(sec.Ed25519SignerFromPem(keyName, pemData))
+	data, sigCovered = signAndRead(t, signer)
+	require.True(t, verifier(data.Name(), sigCovered, data.Signature()))
+
+	utils.WithErr(sec.Ed25519SignerFromSeed(keyName, seed[:31]))
+	utils.WithErr(sec.Ed25519SignerFromPem(keyName, []byte("not a pem")))
+}
+
+func TestEcdsaSignerDerLength(t *testing.T) {
+	utils.SetTestingT(t)
+
+	keyName := utils.WithoutErr(enc.NameFromStr("/test/KEY/ecdsa"))
+	sigLens := make(map[int]bool)
+	for i := 0; i < 64; i++ {
+		key := utils.WithoutErr(ecdsa.GenerateKey(elliptic.P256(), rand.Reader))
+		data, sigCovered := signAndRead(t, sec.NewEcdsaSigner(keyName, key))
+
+		require.Equal(t, ndn.SignatureSha256WithEcdsa, data.Signature().SigType())
+		require.True(t, keyName.Equal(data.Signature().KeyName()))
+		sigVal := data.Signature().SigValue()
+		require.Equal(t, byte(0x30), sigVal[0])
+		require.Equal(t, len(sigVal)-2, int(sigVal[1]))
+		sigLens[len(sigVal)] = true
+
+		require.True(t, sec.NewEcdsaVerifier(&key.PublicKey)(data.Name(), sigCovered, data.Signature()))
+		other := utils.WithoutErr(ecdsa.GenerateKey(elliptic.P256(), rand.Reader))
+		require.False(t, sec.NewEcdsaVerifier(&other.PublicKey)(data.Name(), sigCovered, data.Signature()))
+	}
+	// With 64 random signatures, a shorter DER encoding is all but certain to show up.
+	require.Greater(t, len(sigLens), 1)
+}
+
+func TestRsaSigner(t *testing.T) {
+	utils.SetTestingT(t)
+
+	keyName := utils.WithoutErr(enc.NameFromStr("/test/KEY/rsa"))
+	for _, bits := range []int{2048, 3072} {
+		key := utils.WithoutErr(rsa.GenerateKey(rand.Reader, bits))
+		signer := sec.NewRsaDataSigner(keyName, key)
+		require.Equal(t, uint(bits/8), signer.EstimateSize())
+
+		data, sigCovered := signAndRead(t, signer)
+		require.Equal(t, ndn.SignatureSha256WithRsa, data.Signature().SigType())
+		require.True(t, keyName.Equal(data.Signature().KeyName()))
+		require.Equal(t, bits/8, len(data.Signature().SigValue()))
+
+		// ndn-cxx verifies PKCS#1 v1.5 over the SHA-256 digest of the covered part
+		digest := sha256.Sum256(sigCovered.Join())
+		require.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], data.Signature().SigValue()))
+		require.True(t, sec.NewRsaVerifier(&key.PublicKey)(data.Name(), sigCovered, data.Signature()))
+
+		der := utils.WithoutErr(x509.MarshalPKCS8PrivateKey(key))
+		pemData := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+		signer = utils.WithoutErr(sec.RsaSignerFromPem(keyName, pemData))
+		data, sigCovered = signAndRead(t, signer)
+		require.True(t, sec.NewRsaVerifier(&key.PublicKey)(data.Name(), sigCovered, data.Signature()))
+
+		// The deprecated constructor keeps its arguments
+		signer = sec.NewRsaSigner(false, false, 0, key, keyName)
+		data, sigCovered = signAndRead(t, signer)
+		require.Equal(t, ndn.SignatureSha256WithRsa, data.Signature().SigType())
+		require.True(t, sec.NewRsaVerifier(&key.PublicKey)(data.Name(), sigCovered, data.Signature()))
+	}
+}
+
+func TestSha256Signer(t *testing.T) {
+	utils.SetTestingT(t)
+	spec := spec_2022.Spec{}
+
+	wire, _, err := spec.MakeData(
+		utils.WithoutErr(enc.NameFromStr("/test/data")),
+		&ndn.DataConfig{},
+		enc.Wire{[]byte("hello, world")},
+		sec.NewSha256Signer(),
+	)
+	require.NoError(t, err)
+	raw := wire.Join()
+	data, sigCovered, err := spec.ReadData(enc.NewBufferReader(raw))
+	require.NoError(t, err)
+
+	// DigestSha256 has no KeyLocator, and its value is the digest of the covered part
+	require.Equal(t, ndn.SignatureDigestSha256, data.Signature().SigType())
+	require.Nil(t, data.Signature().KeyName())
+	digest := sha256.Sum256(sigCovered.Join())
+	require.Equal(t, digest[:], data.Signature().SigValue())
+	verifier := sec.NewSha256Verifier()
+	require.True(t, verifier(data.Name(), sigCovered, data.Signature()))
+
+	// A tampered Content byte breaks the digest
+	tampered := append([]byte(nil), raw...)
+	i := bytes.Index(tampered, []byte("hello"))
+	tampered[i] ^= 0x01
+	data, sigCovered, err = spec.ReadData(enc.NewBufferReader(tampered))
+	require.NoError(t, err)
+	require.False(t, verifier(data.Name(), sigCovered, data.Signature()))
+
+	// Other signature types are not accepted even if the value is the digest
+	data, sigCovered = signAndRead(t, sec.NewHmacSigner(nil, []byte("key"), false, 0))
+	require.False(t, verifier(data.Name(), sigCovered, data.Signature()))
+}
+
+func TestKeyChainSelection(t *testing.T) {
+	utils.SetTestingT(t)
+
+	kc := sec.NewKeyChain()
+	require.Nil(t, kc.SignerForName(utils.WithoutErr(enc.NameFromStr("/a/b"))))
+
+	defSigner := sec.NewSha256Signer()
+	aSigner := sec.NewHmacSigner(utils.WithoutErr(enc.NameFromStr("/a/KEY/1")), []byte("a"), false, 0)
+	abSigner := sec.NewHmacSigner(utils.WithoutErr(enc.NameFromStr("/a/b/KEY/1")), []byte("ab"), false, 0)
+	kc.AddSigner(utils.WithoutErr(enc.NameFromStr("/a/b")), abSigner)
+	kc.AddSigner(utils.WithoutErr(enc.NameFromStr("/a")), aSigner)
+	kc.SetDefaultSigner(defSigner)
+
+	require.Equal(t, abSigner, kc.SignerForName(utils.WithoutErr(enc.NameFromStr("/a/b/c"))))
+	require.Equal(t, abSigner, kc.SignerForName(utils.WithoutErr(enc.NameFromStr("/a/b"))))
+	require.Equal(t, aSigner, kc.SignerForName(utils.WithoutErr(enc.NameFromStr("/a/bc"))))
+	require.Equal(t, aSigner, kc.SignerForName(utils.WithoutErr(enc.NameFromStr("/a"))))
+	require.Equal(t, defSigner, kc.SignerForName(utils.WithoutErr(enc.NameFromStr("/b/a"))))
+	require.Equal(t, aSigner, kc.Signer(utils.WithoutErr(enc.NameFromStr("/a"))))
+	require.Nil(t, kc.Signer(utils.WithoutErr(enc.NameFromStr("/a/b/c"))))
+
+	kc.RemoveSigner(utils.WithoutErr(enc.NameFromStr("/a/b")))
+	require.Equal(t, aSigner, kc.SignerForName(utils.WithoutErr(enc.NameFromStr("/a/b/c"))))
+}
@@ -0,0 +1,38 @@
+package security
+
+import (
+	"time"
+
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+// validitySigner overrides the validity period given by the inner signer.
+type validitySigner struct {
+	ndn.Signer
+	notBefore time.Time
+	notAfter  time.Time
+}
+
+func (s validitySigner) SigInfo() (*ndn.SigConfig, error) {
+	ret, err := s.Signer.SigInfo()
+	if err != nil {
+		return nil, err
+	}
+	if ret == nil || ret.Type == ndn.SignatureNone {
+		return nil, ndn.ErrInvalidValue{Item: "signer", Value: "no signature to carry validity period"}
+	}
+	cfg := *ret
+	cfg.NotBefore = utils.IdPtr(s.notBefore)
+	cfg.NotAfter = utils.IdPtr(s.notAfter)
+	return &cfg, nil
+}
+
+// NewValiditySigner wraps a Data signer so that its signatures carry the validity period [notBefore, notAfter].
+func NewValiditySigner(signer ndn.Signer, notBefore time.Time, notAfter time.Time) ndn.Signer {
+	return validitySigner{
+		Signer:    signer,
+		notBefore: notBefore,
+		notAfter:  notAfter,
+	}
+}
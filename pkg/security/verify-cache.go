@@ -0,0 +1,142 @@
+package security
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"sync"
+	"time"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+)
+
+// VerifyCache caches successful signature verifications, so that a packet delivered again
+// is not verified again by the public key.
+// An entry is keyed by the signed portion, the signature value and the name of the certificate,
+// and expires with the certificate. At most maxEntries entries are kept, evicting the least recently used first.
+// Failed verifications are not cached.
+type VerifyCache struct {
+	maxEntries int
+
+	lock sync.Mutex
+	// lru has the most recently used entry at the front
+	lru     *list.List
+	entries map[[sha256.Size]byte]*list.Element
+	hits    uint64
+	misses  uint64
+}
+
+type verifyEntry struct {
+	key      [sha256.Size]byte
+	certName enc.Name
+	notAfter time.Time
+}
+
+func writeLength(h hash.Hash, length uint64) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], length)
+	h.Write(buf[:])
+}
+
+// NewVerifyCache creates a VerifyCache holding at most maxEntries verifications. A limit of 0 means unlimited.
+func NewVerifyCache(maxEntries int) *VerifyCache {
+	return &VerifyCache{
+		maxEntries: maxEntries,
+		lru:        list.New(),
+		entries:    make(map[[sha256.Size]byte]*list.Element),
+	}
+}
+
+// Verify returns whether sig over sigCovered is valid by the public key of cert, using the cached result if any.
+func (c *VerifyCache) Verify(sigCovered enc.Wire, sig ndn.Signature, cert *Certificate, now time.Time) bool {
+	// Every field is prefixed by its length, so bytes moved between fields give another key
+	h := sha256.New()
+	writeLength(h, sigCovered.Length())
+	for _, buf := range sigCovered {
+		h.Write(buf)
+	}
+	sigValue := sig.SigValue()
+	writeLength(h, uint64(len(sigValue)))
+	h.Write(sigValue)
+	certName := cert.Name.Bytes()
+	writeLength(h, uint64(len(certName)))
+	h.Write(certName)
+	var key [sha256.Size]byte
+	h.Sum(key[:0])
+
+	c.lock.Lock()
+	if elem, ok := c.entries[key]; ok {
+		if !now.After(elem.Value.(*verifyEntry).notAfter) {
+			c.lru.MoveToFront(elem)
+			c.hits++
+			c.lock.Unlock()
+			return true
+		}
+		c.remove(elem)
+	}
+	c.misses++
+	c.lock.Unlock()
+
+	if !PublicKeyValidate(sigCovered, sig, cert.PublicKey) {
+		return false
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.entries[key]; ok {
+		return true
+	}
+	c.entries[key] = c.lru.PushFront(&verifyEntry{
+		key:      key,
+		certName: cert.Name,
+		notAfter: cert.NotAfter,
+	})
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+	return true
+}
+
+// Invalidate removes the verifications by the certificates whose names start with name,
+// e.g. all certificates of a key or an identity.
+func (c *VerifyCache) Invalidate(name enc.Name) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for elem := c.lru.Front(); elem != nil; {
+		next := elem.Next()
+		if name.IsPrefix(elem.Value.(*verifyEntry).certName) {
+			c.remove(elem)
+		}
+		elem = next
+	}
+}
+
+// InvalidateAll removes all cached verifications.
+func (c *VerifyCache) InvalidateAll() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.lru.Init()
+	c.entries = make(map[[sha256.Size]byte]*list.Element)
+}
+
+// Len returns the number of verifications cached.
+func (c *VerifyCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lru.Len()
+}
+
+// Stats returns the number of verifications found in the cache, and the ones verified by the public key.
+func (c *VerifyCache) Stats() (hits uint64, misses uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.hits, c.misses
+}
+
+// remove must be called with the lock held.
+func (c *VerifyCache) remove(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*verifyEntry)
+	delete(c.entries, entry.key)
+}
@@ -0,0 +1,165 @@
+package security_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	basic_engine "github.com/zjkmxy/go-ndn/pkg/engine/basic"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/ndn/spec_2022"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+// fixedTimer is a timer whose clock is fixed at now.
+type fixedTimer struct {
+	ndn.Timer
+	now time.Time
+}
+
+func (t fixedTimer) Now() time.Time {
+	return t.now
+}
+
+func makeSignedData(t testing.TB, name string, content string, signer ndn.Signer) (ndn.Data, enc.Wire) {
+	dataName, err := enc.NameFromStr(name)
+	require.NoError(t, err)
+	wire, _, err := spec_2022.Spec{}.MakeData(
+		dataName,
+		&ndn.DataConfig{ContentType: utils.IdPtr(ndn.ContentTypeBlob)},
+		enc.Wire{[]byte(content)}, signer)
+	require.NoError(t, err)
+	data, sigCovered, err := spec_2022.Spec{}.ReadData(enc.NewWireReader(wire))
+	require.NoError(t, err)
+	return data, sigCovered
+}
+
+// shiftedSig is a signature with its value replaced.
+type shiftedSig struct {
+	ndn.Signature
+	value []byte
+}
+
+func (s shiftedSig) SigValue() []byte {
+	return s.value
+}
+
+func TestVerifyCache(t *testing.T) {
+	utils.SetTestingT(t)
+
+	root := newTestKey(t, "/test")
+	anchor := parseCert(t, root.certificate(t, root, time.Hour))
+	data, sigCovered := makeSignedData(t, "/test/data", "hello", root.signer())
+	timer := basic_engine.NewTimer()
+
+	cache := sec.NewVerifyCache(2)
+	v := sec.NewChainValidator([]*sec.Certificate{anchor})
+	v.VerifyCache = cache
+	for i := 0; i < 3; i++ {
+		require.NoError(t, v.ValidateKnown(timer, data.Name(), sigCovered, data.Signature()))
+	}
+	hits, misses := cache.Stats()
+	require.Equal(t, uint64(2), hits)
+	require.Equal(t, uint64(1), misses)
+
+	// The same signature value over another content is verified and not cached
+	_, forgedCovered := makeSignedData(t, "/test/data", "forged", root.signer())
+	require.False(t, cache.Verify(forgedCovered, data.Signature(), anchor, timer.Now()))
+	require.Equal(t, 1, cache.Len())
+
+	// Bytes moved from the signature value to the signed portion do not hit the cached verification
+	sigValue := data.Signature().SigValue()
+	shifted := shiftedSig{Signature: data.Signature(), value: sigValue[1:]}
+	shiftedCovered := enc.Wire{sigCovered.Join(), sigValue[:1]}
+	require.False(t, cache.Verify(shiftedCovered, shifted, anchor, timer.Now()))
+	require.Equal(t, 1, cache.Len())
+
+	// Size limit
+	for _, content := range []string{"a", "b", "c"} {
+		d, covered := makeSignedData(t, "/test/data", content, root.signer())
+		require.True(t, cache.Verify(covered, d.Signature(), anchor, timer.Now()))
+	}
+	require.Equal(t, 2, cache.Len())
+
+	// Manual invalidation
+	cache.Invalidate(utils.WithoutErr(enc.NameFromStr("/other")))
+	require.Equal(t, 2, cache.Len())
+	cache.Invalidate(anchor.KeyName)
+	require.Equal(t, 0, cache.Len())
+
+	// An entry expires with the certificate
+	require.True(t, cache.Verify(sigCovered, data.Signature(), anchor, timer.Now()))
+	_, misses = cache.Stats()
+	require.True(t, cache.Verify(sigCovered, data.Signature(), anchor, anchor.NotAfter.Add(time.Second)))
+	_, misses2 := cache.Stats()
+	require.Equal(t, misses+1, misses2)
+}
+
+func TestChainCacheExpiry(t *testing.T) {
+	utils.SetTestingT(t)
+
+	root := newTestKey(t, "/test")
+	site := newTestKey(t, "/test/site")
+	user := newTestKey(t, "/test/site/user")
+	anchor := parseCert(t, root.certificate(t, root, 24*time.Hour))
+	app := serveChain(t, map[string]enc.Wire{
+		site.name.String(): site.certificate(t, root, time.Hour),
+		user.name.String(): user.certificate(t, site, 3*time.Hour),
+	})
+	data, sigCovered := makeSignedData(t, "/test/site/user/data", "hello", user.signer())
+
+	v := sec.NewChainValidator([]*sec.Certificate{anchor})
+	v.VerifyCache = sec.NewVerifyCache(0)
+	require.NoError(t, v.Validate(app, data.Name(), sigCovered, data.Signature()))
+	require.Equal(t, 2, v.CachedCerts())
+	require.NoError(t, v.ValidateKnown(app.Timer(), data.Name(), sigCovered, data.Signature()))
+
+	// The user certificate is still valid, but the site certificate in its chain has expired
+	later := fixedTimer{Timer: app.Timer(), now: time.Now().Add(2 * time.Hour)}
+	require.Error(t, v.ValidateKnown(later, data.Name(), sigCovered, data.Signature()))
+	require.Equal(t, 1, v.CachedCerts())
+
+	// Invalidating the site certificate removes the user certificate chaining to it
+	require.NoError(t, v.Validate(app, data.Name(), sigCovered, data.Signature()))
+	require.Equal(t, 2, v.CachedCerts())
+	v.Invalidate(site.name)
+	require.Equal(t, 0, v.CachedCerts())
+	// Only the verification of the site certificate by the anchor is kept
+	require.Equal(t, 1, v.VerifyCache.Len())
+	require.Error(t, v.ValidateKnown(app.Timer(), data.Name(), sigCovered, data.Signature()))
+
+	// Cache size limit
+	v.MaxCerts = 1
+	require.NoError(t, v.Validate(app, data.Name(), sigCovered, data.Signature()))
+	require.Equal(t, 1, v.CachedCerts())
+}
+
+func BenchmarkVerifyCache(b *testing.B) {
+	root := newTestKey(b, "/test")
+	anchor := parseCert(b, root.certificate(b, root, time.Hour))
+	data, sigCovered := makeSignedData(b, "/test/data", "hello", root.signer())
+	timer := basic_engine.NewTimer()
+
+	b.Run("NoCache", func(b *testing.B) {
+		v := sec.NewChainValidator([]*sec.Certificate{anchor})
+		for i := 0; i < b.N; i++ {
+			if v.ValidateKnown(timer, data.Name(), sigCovered, data.Signature()) != nil {
+				b.Fatal("validation failed")
+			}
+		}
+		b.ReportMetric(1, "verifies/op")
+	})
+	b.Run("Cache", func(b *testing.B) {
+		v := sec.NewChainValidator([]*sec.Certificate{anchor})
+		v.VerifyCache = sec.NewVerifyCache(1024)
+		for i := 0; i < b.N; i++ {
+			if v.ValidateKnown(timer, data.Name(), sigCovered, data.Signature()) != nil {
+				b.Fatal("validation failed")
+			}
+		}
+		_, misses := v.VerifyCache.Stats()
+		b.ReportMetric(float64(misses)/float64(b.N), "verifies/op")
+	})
+}
@@ -0,0 +1,89 @@
+package sync
+
+import (
+	"math"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+)
+
+// bloomSalts are the seeds of the hash functions, the first ones of the table used by PSync.
+var bloomSalts = []uint32{
+	0xAAAAAAAA, 0x55555555, 0x33333333, 0xCCCCCCCC, 0x66666666, 0x99999999, 0xB5B5B5B5, 0x4B4B4B4B,
+	0xAA55AA55, 0x55335533, 0x33CC33CC, 0xCC66CC66, 0x66996699, 0x99B599B5, 0xB54BB54B, 0x4BAA4BAA,
+	0xAA33AA33, 0x55CC55CC, 0x33663366, 0xCC99CC99, 0x66B566B5, 0x994B994B, 0xB5AAB5AA, 0x4B554B55,
+}
+
+// BloomFilter is the Bloom filter of prefixes that a PSync consumer subscribes to.
+type BloomFilter struct {
+	count    uint64
+	fpp      float64
+	numSalts int
+	table    []byte
+}
+
+// NewBloomFilter creates a Bloom filter of count elements with the false positive probability fpp,
+// which should be in 0.001 units to be encoded in a name.
+func NewBloomFilter(count uint64, fpp float64) *BloomFilter {
+	minM, minK := math.Inf(1), 0.0
+	for k := 1.0; k < 1000.0; k++ {
+		m := -k * float64(count) / math.Log(1.0-math.Pow(fpp, 1.0/k))
+		if m < minM {
+			minM, minK = m, k
+		}
+	}
+	size := uint64(minM)
+	if size%8 != 0 {
+		size += 8 - size%8
+	}
+	return &BloomFilter{
+		count:    count,
+		fpp:      fpp,
+		numSalts: max(min(int(minK), len(bloomSalts)), 1),
+		table:    make([]byte, max(size/8, 1)),
+	}
+}
+
+// ParseBloomFilter parses a Bloom filter from the 3 name components given by Components.
+func ParseBloomFilter(comps enc.Name) (*BloomFilter, error) {
+	if len(comps) != 3 {
+		return nil, ndn.ErrInvalidValue{Item: "Bloom filter", Value: comps}
+	}
+	ret := NewBloomFilter(comps[0].NumberVal(), float64(comps[1].NumberVal())/1000)
+	if len(comps[2].Val) != len(ret.table) {
+		return nil, ndn.ErrInvalidValue{Item: "Bloom filter size", Value: len(comps[2].Val)}
+	}
+	copy(ret.table, comps[2].Val)
+	return ret, nil
+}
+
+// Components encodes the Bloom filter into the element count, the false positive probability
+// in 0.001 units, and the bit table.
+func (bf *BloomFilter) Components() enc.Name {
+	return enc.Name{
+		enc.NewNumberComponent(enc.TypeGenericNameComponent, bf.count),
+		enc.NewNumberComponent(enc.TypeGenericNameComponent, uint64(math.Round(bf.fpp*1000))),
+		enc.NewBytesComponent(enc.TypeGenericNameComponent, append([]byte(nil), bf.table...)),
+	}
+}
+
+// Insert adds name into the filter.
+func (bf *BloomFilter) Insert(name enc.Name) {
+	bitSize := uint32(len(bf.table) * 8)
+	for _, salt := range bloomSalts[:bf.numSalts] {
+		idx := nameHash(salt, name) % bitSize
+		bf.table[idx/8] |= 1 << (idx % 8)
+	}
+}
+
+// Contains tests if name may have been added into the filter.
+func (bf *BloomFilter) Contains(name enc.Name) bool {
+	bitSize := uint32(len(bf.table) * 8)
+	for _, salt := range bloomSalts[:bf.numSalts] {
+		idx := nameHash(salt, name) % bitSize
+		if bf.table[idx/8]&(1<<(idx%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
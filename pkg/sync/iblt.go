@@ -0,0 +1,208 @@
+package sync
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"math/bits"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+)
+
+const (
+	// ibltHashes is the number of cells a key is put into.
+	ibltHashes = 3
+	// ibltHashCheckSeed is the seed of the hash checking a pure cell, also used to hash names into keys.
+	ibltHashCheckSeed = 11
+	// ibltCellSize is the encoded size of a cell: count, keySum and keyCheck as 32-bit big endian.
+	ibltCellSize = 12
+)
+
+type ibltCell struct {
+	count    int32
+	keySum   uint32
+	keyCheck uint32
+}
+
+func (c *ibltCell) isPure() bool {
+	return (c.count == 1 || c.count == -1) && c.keyCheck == murmurHash3(ibltHashCheckSeed, uint32Bytes(c.keySum))
+}
+
+func (c *ibltCell) isEmpty() bool {
+	return c.count == 0 && c.keySum == 0 && c.keyCheck == 0
+}
+
+// IBLT is an Invertible Bloom Lookup Table of 32-bit keys, in the layout of PSync.
+// The difference of two IBLTs can be listed if it is small enough, so two parties can
+// reconcile their sets by exchanging only the tables.
+type IBLT struct {
+	cells []ibltCell
+}
+
+// NewIBLT creates an IBLT able to list a difference of about expectedNumEntries keys.
+func NewIBLT(expectedNumEntries int) *IBLT {
+	n := expectedNumEntries + expectedNumEntries/2
+	if rem := n % ibltHashes; rem != 0 {
+		n += ibltHashes - rem
+	}
+	return &IBLT{cells: make([]ibltCell, n)}
+}
+
+// ParseIBLT parses an IBLT of expectedNumEntries from a name component given by Component.
+func ParseIBLT(expectedNumEntries int, c enc.Component) (*IBLT, error) {
+	ret := NewIBLT(expectedNumEntries)
+	r, err := zlib.NewReader(bytes.NewReader(c.Val))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	table, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(table) != len(ret.cells)*ibltCellSize {
+		return nil, ndn.ErrInvalidValue{Item: "IBLT size", Value: len(table)}
+	}
+	for i := range ret.cells {
+		cell := table[i*ibltCellSize:]
+		ret.cells[i] = ibltCell{
+			count:    int32(binary.BigEndian.Uint32(cell)),
+			keySum:   binary.BigEndian.Uint32(cell[4:]),
+			keyCheck: binary.BigEndian.Uint32(cell[8:]),
+		}
+	}
+	return ret, nil
+}
+
+// Component encodes the IBLT into a generic name component, compressed by zlib.
+func (t *IBLT) Component() enc.Component {
+	table := make([]byte, len(t.cells)*ibltCellSize)
+	for i, c := range t.cells {
+		cell := table[i*ibltCellSize:]
+		binary.BigEndian.PutUint32(cell, uint32(c.count))
+		binary.BigEndian.PutUint32(cell[4:], c.keySum)
+		binary.BigEndian.PutUint32(cell[8:], c.keyCheck)
+	}
+	buf := bytes.Buffer{}
+	w := zlib.NewWriter(&buf)
+	w.Write(table)
+	w.Close()
+	return enc.NewBytesComponent(enc.TypeGenericNameComponent, buf.Bytes())
+}
+
+func (t *IBLT) update(plusOrMinus int32, key uint32) {
+	perHash := uint32(len(t.cells) / ibltHashes)
+	check := murmurHash3(ibltHashCheckSeed, uint32Bytes(key))
+	for i := uint32(0); i < ibltHashes; i++ {
+		cell := &t.cells[i*perHash+murmurHash3(i, uint32Bytes(key))%perHash]
+		cell.count += plusOrMinus
+		cell.keySum ^= key
+		cell.keyCheck ^= check
+	}
+}
+
+// Insert adds a key.
+func (t *IBLT) Insert(key uint32) {
+	t.update(1, key)
+}
+
+// Erase removes a key.
+func (t *IBLT) Erase(key uint32) {
+	t.update(-1, key)
+}
+
+// Sub returns the difference t - other. Both tables must be of the same size.
+func (t *IBLT) Sub(other *IBLT) *IBLT {
+	ret := &IBLT{cells: make([]ibltCell, len(t.cells))}
+	for i := range t.cells {
+		ret.cells[i] = ibltCell{
+			count:    t.cells[i].count - other.cells[i].count,
+			keySum:   t.cells[i].keySum ^ other.cells[i].keySum,
+			keyCheck: t.cells[i].keyCheck ^ other.cells[i].keyCheck,
+		}
+	}
+	return ret
+}
+
+// List lists the keys inserted (positive) and erased (negative) more than the other,
+// if t is a difference given by Sub.
+// ok is false if the difference is too large to be listed completely.
+func (t *IBLT) List() (positive []uint32, negative []uint32, ok bool) {
+	peeled := &IBLT{cells: make([]ibltCell, len(t.cells))}
+	copy(peeled.cells, t.cells)
+	for found := true; found; {
+		found = false
+		for i := range peeled.cells {
+			cell := peeled.cells[i]
+			if !cell.isPure() {
+				continue
+			}
+			if cell.count == 1 {
+				positive = append(positive, cell.keySum)
+			} else {
+				negative = append(negative, cell.keySum)
+			}
+			peeled.update(-cell.count, cell.keySum)
+			found = true
+		}
+	}
+	for i := range peeled.cells {
+		if !peeled.cells[i].isEmpty() {
+			return positive, negative, false
+		}
+	}
+	return positive, negative, true
+}
+
+func uint32Bytes(v uint32) []byte {
+	// PSync hashes the keys in the little endian order of x86
+	return binary.LittleEndian.AppendUint32(nil, v)
+}
+
+// murmurHash3 is MurmurHash3_x86_32.
+func murmurHash3(seed uint32, data []byte) uint32 {
+	const c1, c2 = 0xcc9e2d51, 0x1b873593
+	h := seed
+	n := len(data) / 4
+	for i := 0; i < n; i++ {
+		k := binary.LittleEndian.Uint32(data[i*4:])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+	var k uint32
+	tail := data[n*4:]
+	switch len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+	}
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
+
+// nameHash hashes the TLV-VALUE of name, as PSync does for prefixes and Bloom filters.
+func nameHash(seed uint32, name enc.Name) uint32 {
+	buf := make([]byte, name.EncodingLength())
+	name.EncodeInto(buf)
+	return murmurHash3(seed, buf)
+}
@@ -0,0 +1,508 @@
+package sync
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/log"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+// PSyncInterestLifetime is the lifetime of PSync hello and sync Interests.
+const PSyncInterestLifetime = 1 * time.Second
+
+var (
+	helloComponent = enc.NewStringComponent(enc.TypeGenericNameComponent, "hello")
+	syncComponent  = enc.NewStringComponent(enc.TypeGenericNameComponent, "sync")
+)
+
+// PrefixSeq is a prefix with its latest sequence number.
+type PrefixSeq struct {
+	Prefix enc.Name
+	Seq    uint64
+}
+
+// SeqUpdate tells the sequence numbers LowSeq to HighSeq of Prefix are new.
+type SeqUpdate struct {
+	Prefix  enc.Name
+	LowSeq  uint64
+	HighSeq uint64
+}
+
+// withSeq returns prefix with the sequence number appended as a NonNegativeInteger generic component,
+// which is the member of the PSync set.
+func withSeq(prefix enc.Name, seq uint64) enc.Name {
+	ret := make(enc.Name, len(prefix), len(prefix)+1)
+	copy(ret, prefix)
+	return append(ret, enc.NewNumberComponent(enc.TypeGenericNameComponent, seq))
+}
+
+func encodeState(names []enc.Name) enc.Wire {
+	return (&PSyncContent{State: &PSyncState{Names: names}}).Encode()
+}
+
+func parseState(content enc.Wire) ([]PrefixSeq, error) {
+	c, err := ParsePSyncContent(enc.NewWireReader(content), true)
+	if err != nil {
+		return nil, err
+	}
+	if c.State == nil {
+		return nil, nil
+	}
+	ret := make([]PrefixSeq, 0, len(c.State.Names))
+	for _, name := range c.State.Names {
+		if len(name) == 0 {
+			return nil, ndn.ErrInvalidValue{Item: "PSync name", Value: name}
+		}
+		ret = append(ret, PrefixSeq{Prefix: name[:len(name)-1], Seq: name[len(name)-1].NumberVal()})
+	}
+	return ret, nil
+}
+
+type pendingSyncInt struct {
+	name     enc.Name
+	bf       *BloomFilter
+	reply    ndn.ReplyFunc
+	deadline time.Time
+}
+
+// PSyncProducer is the producer of PSync partial sync.
+// It keeps the latest sequence numbers of its prefixes in an IBLT.
+// Consumers learn the prefixes by hello Interests /<sync>/hello, and subscribe to some of them by
+// sync Interests /<sync>/sync/<BloomFilter>/<IBLT>, which are replied with the subscribed prefixes
+// that are newer than the consumer's IBLT, or kept pending until one of them is updated.
+//
+// The route of the sync prefix is not registered by PSyncProducer.
+type PSyncProducer struct {
+	app                ndn.Engine
+	syncPrefix         enc.Name
+	expectedNumEntries int
+	log                *log.Entry
+
+	helloFreshness time.Duration
+	syncFreshness  time.Duration
+
+	lock     sync.Mutex
+	running  bool
+	prefixes map[string]*PrefixSeq
+	// hashes are the members of the IBLT, i.e. prefixes with the latest sequence numbers
+	hashes  map[uint32]enc.Name
+	iblt    *IBLT
+	pending map[string]*pendingSyncInt
+}
+
+// NewPSyncProducer creates a producer of syncPrefix, whose IBLT is for expectedNumEntries differences.
+// Consumers and the producer must agree on expectedNumEntries.
+func NewPSyncProducer(app ndn.Engine, syncPrefix enc.Name, expectedNumEntries int) *PSyncProducer {
+	return &PSyncProducer{
+		app:                app,
+		syncPrefix:         syncPrefix,
+		expectedNumEntries: expectedNumEntries,
+		log:                log.WithField("module", "psync").WithField("sync", syncPrefix.String()),
+		helloFreshness:     1 * time.Second,
+		syncFreshness:      1 * time.Second,
+		prefixes:           make(map[string]*PrefixSeq),
+		hashes:             make(map[uint32]enc.Name),
+		iblt:               NewIBLT(expectedNumEntries),
+		pending:            make(map[string]*pendingSyncInt),
+	}
+}
+
+// Start attaches the Interest handler of the sync prefix.
+func (p *PSyncProducer) Start() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.running {
+		return errors.New("the producer is already started")
+	}
+	if err := p.app.AttachHandler(p.syncPrefix, p.onInterest); err != nil {
+		return err
+	}
+	p.running = true
+	return nil
+}
+
+// Stop detaches the Interest handler. Pending sync Interests are dropped.
+func (p *PSyncProducer) Stop() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if !p.running {
+		return ErrSyncNotStarted
+	}
+	p.running = false
+	p.pending = make(map[string]*pendingSyncInt)
+	return p.app.DetachHandler(p.syncPrefix)
+}
+
+// AddUserNode adds prefix to the set with sequence number 0. It is a no-op if prefix exists.
+func (p *PSyncProducer) AddUserNode(prefix enc.Name) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if _, ok := p.prefixes[prefix.TlvStr()]; !ok {
+		p.prefixes[prefix.TlvStr()] = &PrefixSeq{Prefix: prefix, Seq: 0}
+	}
+}
+
+// RemoveUserNode removes prefix from the set.
+func (p *PSyncProducer) RemoveUserNode(prefix enc.Name) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	key := prefix.TlvStr()
+	if state, ok := p.prefixes[key]; ok {
+		p.updateIblt(state, 0)
+		delete(p.prefixes, key)
+	}
+}
+
+// SeqNo returns the sequence number of prefix. ok is false if prefix is not added.
+func (p *PSyncProducer) SeqNo(prefix enc.Name) (seq uint64, ok bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	state, ok := p.prefixes[prefix.TlvStr()]
+	if !ok {
+		return 0, false
+	}
+	return state.Seq, true
+}
+
+// PublishName increases the sequence number of prefix, and satisfies the pending sync Interests
+// subscribing it. It returns the new sequence number.
+func (p *PSyncProducer) PublishName(prefix enc.Name) (uint64, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	state, ok := p.prefixes[prefix.TlvStr()]
+	if !ok {
+		return 0, ndn.ErrInvalidValue{Item: "prefix", Value: prefix}
+	}
+	p.updateIblt(state, state.Seq+1)
+	p.satisfyPending(state)
+	return state.Seq, nil
+}
+
+// updateIblt replaces the sequence number of state in the IBLT. seq 0 removes it.
+func (p *PSyncProducer) updateIblt(state *PrefixSeq, seq uint64) {
+	if state.Seq > 0 {
+		old := nameHash(ibltHashCheckSeed, withSeq(state.Prefix, state.Seq))
+		p.iblt.Erase(old)
+		delete(p.hashes, old)
+	}
+	state.Seq = seq
+	if seq > 0 {
+		name := withSeq(state.Prefix, seq)
+		hash := nameHash(ibltHashCheckSeed, name)
+		p.iblt.Insert(hash)
+		p.hashes[hash] = name
+	}
+}
+
+func (p *PSyncProducer) satisfyPending(state *PrefixSeq) {
+	now := p.app.Timer().Now()
+	for key, entry := range p.pending {
+		if entry.deadline.Before(now) {
+			delete(p.pending, key)
+			continue
+		}
+		if entry.bf.Contains(state.Prefix) {
+			delete(p.pending, key)
+			p.replySync(entry.name, entry.reply, []enc.Name{withSeq(state.Prefix, state.Seq)})
+		}
+	}
+}
+
+func (p *PSyncProducer) onInterest(
+	interest ndn.Interest, _ enc.Wire, _ enc.Wire, reply ndn.ReplyFunc, deadline time.Time,
+) {
+	name := interest.Name()
+	if len(name) <= len(p.syncPrefix) {
+		return
+	}
+	switch op := name[len(p.syncPrefix)]; {
+	case op.Equal(helloComponent):
+		p.onHello(name, reply)
+	case op.Equal(syncComponent):
+		p.onSync(name, reply, deadline)
+	}
+}
+
+func (p *PSyncProducer) onHello(name enc.Name, reply ndn.ReplyFunc) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	names := make([]enc.Name, 0, len(p.prefixes))
+	for _, state := range p.prefixes {
+		names = append(names, withSeq(state.Prefix, state.Seq))
+	}
+
+	// /<sync>/hello/<IBLT>/<version>/<segment>
+	dataName := make(enc.Name, len(p.syncPrefix)+1, len(p.syncPrefix)+4)
+	copy(dataName, name)
+	dataName = append(dataName, p.iblt.Component(),
+		enc.NewVersionComponent(utils.MakeTimestamp(p.app.Timer().Now())), enc.NewSegmentComponent(0))
+	p.replyData(dataName, p.helloFreshness, names, reply)
+}
+
+func (p *PSyncProducer) onSync(name enc.Name, reply ndn.ReplyFunc, deadline time.Time) {
+	// /<sync>/sync/<BF count>/<BF fpp>/<BF table>/<IBLT>
+	pos := len(p.syncPrefix) + 1
+	if len(name) < pos+4 {
+		p.log.Warn("Malformed sync Interest. Drop.")
+		return
+	}
+	bf, err := ParseBloomFilter(name[pos : pos+3])
+	if err != nil {
+		p.log.Warnf("Unable to parse the Bloom filter. Drop: %+v", err)
+		return
+	}
+	theirs, err := ParseIBLT(p.expectedNumEntries, name[pos+3])
+	if err != nil {
+		p.log.Warnf("Unable to parse the IBLT. Drop: %+v", err)
+		return
+	}
+	intName := name[:pos+4]
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if !p.running {
+		return
+	}
+	positive, _, ok := p.iblt.Sub(theirs).List()
+	names := make([]enc.Name, 0)
+	if ok {
+		for _, hash := range positive {
+			if member, ok := p.hashes[hash]; ok && bf.Contains(member[:len(member)-1]) {
+				names = append(names, member)
+			}
+		}
+	} else {
+		// The difference is too large to be listed, so the consumer gets all it subscribes
+		for _, state := range p.prefixes {
+			if state.Seq > 0 && bf.Contains(state.Prefix) {
+				names = append(names, withSeq(state.Prefix, state.Seq))
+			}
+		}
+	}
+	if len(names) > 0 {
+		p.replySync(intName, reply, names)
+		return
+	}
+	p.pending[intName.TlvStr()] = &pendingSyncInt{
+		name:     intName,
+		bf:       bf,
+		reply:    reply,
+		deadline: deadline,
+	}
+}
+
+// replySync replies to a sync Interest with names, as /<sync Interest name>/<IBLT>/<version>/<segment>.
+func (p *PSyncProducer) replySync(intName enc.Name, reply ndn.ReplyFunc, names []enc.Name) {
+	dataName := make(enc.Name, len(intName), len(intName)+3)
+	copy(dataName, intName)
+	dataName = append(dataName, p.iblt.Component(),
+		enc.NewVersionComponent(utils.MakeTimestamp(p.app.Timer().Now())), enc.NewSegmentComponent(0))
+	p.replyData(dataName, p.syncFreshness, names, reply)
+}
+
+func (p *PSyncProducer) replyData(dataName enc.Name, freshness time.Duration, names []enc.Name, reply ndn.ReplyFunc) {
+	dataCfg := &ndn.DataConfig{
+		ContentType:  utils.IdPtr(ndn.ContentTypeBlob),
+		Freshness:    utils.IdPtr(freshness),
+		FinalBlockID: utils.IdPtr(enc.NewSegmentComponent(0)),
+	}
+	wire, _, err := p.app.Spec().MakeData(dataName, dataCfg, encodeState(names), p.app.SignerForName(dataName))
+	if err != nil {
+		p.log.Errorf("Unable to encode Data: %+v", err)
+		return
+	}
+	if err := reply(wire); err != nil {
+		p.log.WithField("name", dataName.String()).Errorf("Unable to reply Interest. Drop: %+v", err)
+	}
+}
+
+// PSyncConsumer is the consumer of PSync partial sync.
+// It learns the prefixes of a producer by SendHelloInterest, and after AddSubscription,
+// keeps a sync Interest pending at the producer to learn the updates of the subscribed prefixes.
+type PSyncConsumer struct {
+	app        ndn.Engine
+	syncPrefix enc.Name
+	onHello    func(available []PrefixSeq)
+	onUpdate   func(updates []SeqUpdate)
+	log        *log.Entry
+
+	lock          sync.Mutex
+	running       bool
+	bf            *BloomFilter
+	iblt          *enc.Component
+	subscriptions map[string]*PrefixSeq
+}
+
+// NewPSyncConsumer creates a consumer of syncPrefix, which subscribes up to count prefixes with
+// a Bloom filter of the false positive probability fpp.
+// onHello is called with the prefixes available at the producer; onUpdate is called with the updates of
+// the subscribed prefixes. Both are called on new goroutines.
+func NewPSyncConsumer(
+	app ndn.Engine, syncPrefix enc.Name,
+	onHello func(available []PrefixSeq), onUpdate func(updates []SeqUpdate),
+	count uint64, fpp float64,
+) *PSyncConsumer {
+	return &PSyncConsumer{
+		app:           app,
+		syncPrefix:    syncPrefix,
+		onHello:       onHello,
+		onUpdate:      onUpdate,
+		log:           log.WithField("module", "psync").WithField("sync", syncPrefix.String()),
+		bf:            NewBloomFilter(count, fpp),
+		subscriptions: make(map[string]*PrefixSeq),
+	}
+}
+
+// AddSubscription subscribes prefix, whose sequence number seq is known.
+// It takes effect from the next sync Interest.
+func (c *PSyncConsumer) AddSubscription(prefix enc.Name, seq uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	key := prefix.TlvStr()
+	if _, ok := c.subscriptions[key]; !ok {
+		c.bf.Insert(prefix)
+	}
+	c.subscriptions[key] = &PrefixSeq{Prefix: prefix, Seq: seq}
+}
+
+// SeqNo returns the known sequence number of the subscribed prefix.
+func (c *PSyncConsumer) SeqNo(prefix enc.Name) (seq uint64, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	state, ok := c.subscriptions[prefix.TlvStr()]
+	if !ok {
+		return 0, false
+	}
+	return state.Seq, true
+}
+
+// SendHelloInterest asks the producer for its prefixes, which also gives the IBLT to start syncing.
+func (c *PSyncConsumer) SendHelloInterest() error {
+	name := make(enc.Name, len(c.syncPrefix), len(c.syncPrefix)+1)
+	copy(name, c.syncPrefix)
+	return c.express(append(name, helloComponent), func(data ndn.Data) {
+		// /<sync>/hello/<IBLT>/<version>/<segment>
+		dataName := data.Name()
+		pos := len(c.syncPrefix) + 1
+		available, err := parseState(data.Content())
+		if err != nil || len(dataName) <= pos {
+			c.log.Warnf("Malformed hello Data. Drop: %+v", err)
+			return
+		}
+		c.lock.Lock()
+		c.iblt = &dataName[pos]
+		c.lock.Unlock()
+		c.onHello(available)
+	})
+}
+
+// SendSyncInterest starts syncing the subscribed prefixes, after the hello Data is received.
+// A new sync Interest is sent whenever the last one is replied or timed out, until Stop is called.
+func (c *PSyncConsumer) SendSyncInterest() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.iblt == nil {
+		return errors.New("the IBLT is unknown before the hello Data")
+	}
+	c.running = true
+	return c.sendSyncInterest()
+}
+
+// Stop stops sending sync Interests.
+func (c *PSyncConsumer) Stop() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.running = false
+}
+
+// sendSyncInterest must be called with the lock held.
+func (c *PSyncConsumer) sendSyncInterest() error {
+	name := make(enc.Name, len(c.syncPrefix), len(c.syncPrefix)+5)
+	copy(name, c.syncPrefix)
+	name = append(name, syncComponent)
+	name = append(name, c.bf.Components()...)
+	name = append(name, *c.iblt)
+	return c.express(name, c.onSyncData)
+}
+
+func (c *PSyncConsumer) onSyncData(data ndn.Data) {
+	// /<sync>/sync/<BF count>/<BF fpp>/<BF table>/<old IBLT>/<new IBLT>/<version>/<segment>
+	dataName := data.Name()
+	pos := len(c.syncPrefix) + 5
+	states, err := parseState(data.Content())
+	if err != nil || len(dataName) <= pos {
+		c.log.Warnf("Malformed sync Data. Drop: %+v", err)
+		return
+	}
+
+	c.lock.Lock()
+	c.iblt = &dataName[pos]
+	updates := make([]SeqUpdate, 0)
+	for _, state := range states {
+		sub, ok := c.subscriptions[state.Prefix.TlvStr()]
+		if ok && sub.Seq < state.Seq {
+			updates = append(updates, SeqUpdate{Prefix: state.Prefix, LowSeq: sub.Seq + 1, HighSeq: state.Seq})
+			sub.Seq = state.Seq
+		}
+	}
+	c.lock.Unlock()
+
+	if len(updates) > 0 {
+		c.onUpdate(updates)
+	}
+}
+
+// express sends an Interest, and calls onData on a new goroutine.
+// Sync Interests are sent again after a result when running.
+func (c *PSyncConsumer) express(name enc.Name, onData func(data ndn.Data)) error {
+	isSync := name[len(c.syncPrefix)].Equal(syncComponent)
+	intCfg := &ndn.InterestConfig{
+		CanBePrefix: true,
+		MustBeFresh: true,
+		Lifetime:    utils.IdPtr(PSyncInterestLifetime),
+		Nonce:       utils.ConvertNonce(c.app.Timer().Nonce()),
+	}
+	wire, _, finalName, err := c.app.Spec().MakeInterest(name, intCfg, nil, nil)
+	if err != nil {
+		return err
+	}
+	return c.app.Express(finalName, intCfg, wire,
+		func(result ndn.InterestResult, data ndn.Data, _ enc.Wire, _ enc.Wire, nackReason uint64) {
+			delay := time.Duration(0)
+			switch result {
+			case ndn.InterestResultData:
+			case ndn.InterestResultTimeout:
+				c.log.WithField("name", name.String()).Debug("Interest timed out.")
+			case ndn.InterestResultNack:
+				c.log.WithField("name", name.String()).Warnf("Interest nacked for %d.", nackReason)
+				delay = PSyncInterestLifetime
+			default:
+				c.log.WithField("name", name.String()).Errorf("Unknown result: %v", result)
+			}
+			go func() {
+				if result == ndn.InterestResultData {
+					onData(data)
+				}
+				if !isSync {
+					return
+				}
+				c.lock.Lock()
+				defer c.lock.Unlock()
+				if c.running {
+					c.app.Timer().Schedule(delay, func() {
+						c.lock.Lock()
+						defer c.lock.Unlock()
+						if c.running {
+							if err := c.sendSyncInterest(); err != nil {
+								c.log.Errorf("Unable to send sync Interest: %+v", err)
+							}
+						}
+					})
+				}
+			}()
+		})
+}
@@ -0,0 +1,146 @@
+package sync_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	basic_engine "github.com/zjkmxy/go-ndn/pkg/engine/basic"
+	"github.com/zjkmxy/go-ndn/pkg/engine/dummy"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/sync"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+func TestIBLT(t *testing.T) {
+	utils.SetTestingT(t)
+
+	a := sync.NewIBLT(10)
+	b := sync.NewIBLT(10)
+	for _, key := range []uint32{1, 2, 3, 0xdeadbeef} {
+		a.Insert(key)
+		b.Insert(key)
+	}
+	a.Insert(100)
+	a.Insert(200)
+	b.Insert(300)
+
+	positive, negative, ok := a.Sub(b).List()
+	require.True(t, ok)
+	require.ElementsMatch(t, []uint32{100, 200}, positive)
+	require.ElementsMatch(t, []uint32{300}, negative)
+
+	a.Erase(100)
+	positive, _, ok = a.Sub(b).List()
+	require.True(t, ok)
+	require.Equal(t, []uint32{200}, positive)
+
+	// Encoded into a name component
+	c := a.Component()
+	require.Equal(t, enc.TypeGenericNameComponent, c.Typ)
+	parsed := utils.WithoutErr(sync.ParseIBLT(10, c))
+	positive, negative, ok = parsed.Sub(a).List()
+	require.True(t, ok)
+	require.Empty(t, positive)
+	require.Empty(t, negative)
+	require.Error(t, utils.WithErr(sync.ParseIBLT(20, c)))
+
+	// A difference larger than the table cannot be listed
+	big := sync.NewIBLT(10)
+	for key := uint32(0); key < 100; key++ {
+		big.Insert(key)
+	}
+	_, _, ok = big.Sub(sync.NewIBLT(10)).List()
+	require.False(t, ok)
+}
+
+func TestBloomFilter(t *testing.T) {
+	utils.SetTestingT(t)
+
+	bf := sync.NewBloomFilter(100, 0.001)
+	bf.Insert(utils.WithoutErr(enc.NameFromStr("/a/b")))
+	require.True(t, bf.Contains(utils.WithoutErr(enc.NameFromStr("/a/b"))))
+	require.False(t, bf.Contains(utils.WithoutErr(enc.NameFromStr("/a/c"))))
+
+	comps := bf.Components()
+	require.Equal(t, uint64(100), comps[0].NumberVal())
+	require.Equal(t, uint64(1), comps[1].NumberVal())
+	parsed := utils.WithoutErr(sync.ParseBloomFilter(comps))
+	require.True(t, parsed.Contains(utils.WithoutErr(enc.NameFromStr("/a/b"))))
+}
+
+func TestPSyncPartial(t *testing.T) {
+	utils.SetTestingT(t)
+
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool { return true }
+	faceP, faceC := dummy.NewPipeFaces()
+	timerP := basic_engine.NewTimer()
+	engineP := basic_engine.NewEngine(faceP, timerP, sec.NewSha256IntSigner(timerP), passAll)
+	require.NoError(t, engineP.Start())
+	timerC := basic_engine.NewTimer()
+	engineC := basic_engine.NewEngine(faceC, timerC, sec.NewSha256IntSigner(timerC), passAll)
+	require.NoError(t, engineC.Start())
+
+	syncPrefix := utils.WithoutErr(enc.NameFromStr("/psync"))
+	userA := utils.WithoutErr(enc.NameFromStr("/user/a"))
+	userB := utils.WithoutErr(enc.NameFromStr("/user/b"))
+	producer := sync.NewPSyncProducer(engineP, syncPrefix, 40)
+	producer.AddUserNode(userA)
+	producer.AddUserNode(userB)
+	require.Equal(t, uint64(1), utils.WithoutErr(producer.PublishName(userA)))
+	require.NoError(t, producer.Start())
+
+	helloCh := make(chan []sync.PrefixSeq, 1)
+	updateCh := make(chan []sync.SeqUpdate, 4)
+	consumer := sync.NewPSyncConsumer(engineC, syncPrefix,
+		func(available []sync.PrefixSeq) { helloCh <- available },
+		func(updates []sync.SeqUpdate) { updateCh <- updates },
+		10, 0.001)
+	require.NoError(t, consumer.SendHelloInterest())
+	var available []sync.PrefixSeq
+	select {
+	case available = <-helloCh:
+	case <-time.After(2 * time.Second):
+		require.Fail(t, "no hello Data")
+	}
+	require.Len(t, available, 2)
+	for _, state := range available {
+		if state.Prefix.Equal(userA) {
+			require.Equal(t, uint64(1), state.Seq)
+		} else {
+			require.True(t, state.Prefix.Equal(userB))
+			require.Equal(t, uint64(0), state.Seq)
+		}
+	}
+
+	// Subscribe to b only, so the update of a is not received
+	consumer.AddSubscription(userB, 0)
+	require.NoError(t, consumer.SendSyncInterest())
+	time.Sleep(50 * time.Millisecond)
+	utils.WithoutErr(producer.PublishName(userA))
+	utils.WithoutErr(producer.PublishName(userB))
+	select {
+	case updates := <-updateCh:
+		require.Len(t, updates, 1)
+		require.True(t, updates[0].Prefix.Equal(userB))
+		require.Equal(t, uint64(1), updates[0].LowSeq)
+		require.Equal(t, uint64(1), updates[0].HighSeq)
+	case <-time.After(2 * time.Second):
+		require.Fail(t, "no sync Data")
+	}
+
+	// The next sync Interest carries the new IBLT
+	utils.WithoutErr(producer.PublishName(userB))
+	utils.WithoutErr(producer.PublishName(userB))
+	require.Eventually(t, func() bool {
+		seq, _ := consumer.SeqNo(userB)
+		return seq == 3
+	}, 2*time.Second, 10*time.Millisecond)
+
+	consumer.Stop()
+	require.NoError(t, producer.Stop())
+	require.NoError(t, engineC.Shutdown())
+	require.NoError(t, engineP.Shutdown())
+}
@@ -0,0 +1,420 @@
+// Package sync implements the StateVectorSync (SVS) protocol on the low-level engine.
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	"github.com/zjkmxy/go-ndn/pkg/log"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+const (
+	// DefaultSyncInterval is the period of sync Interests in the steady state.
+	DefaultSyncInterval = 30 * time.Second
+	// DefaultSuppressionInterval is the max delay of a sync Interest in the suppression state.
+	DefaultSuppressionInterval = 200 * time.Millisecond
+	// SyncInterestLifetime is the lifetime of sync Interests, which are never replied.
+	SyncInterestLifetime = 1 * time.Second
+)
+
+// ErrSyncNotStarted is returned when an SVSync is used before Start or after Stop.
+var ErrSyncNotStarted = errors.New("The sync instance is not started.")
+
+type syncState int
+
+const (
+	syncSteady syncState = iota
+	syncSuppression
+)
+
+type syncUpdate struct {
+	producer enc.Name
+	seq      uint64
+}
+
+// SVSync is an instance of StateVectorSync in a sync group.
+// The state vector maps each producer (node ID) to the latest sequence number it published.
+// Sync Interests /<group>/<digest> carrying the state vector are sent every sync interval, and
+// right after a publication. When a sync Interest shows that others miss our newer state, the node
+// turns into the suppression state, aggregates the incoming vectors for a random delay,
+// and sends its vector only if the aggregation is still outdated.
+//
+// Data are named /<producer>/<group>/seq=<seqNo> and served by the producer from memory.
+// The routes are not registered by SVSync: the group prefix should be multicast,
+// and the data prefix /<nodeId>/<group> should be reachable by others.
+type SVSync struct {
+	app         ndn.Engine
+	groupPrefix enc.Name
+	onUpdate    func(producer enc.Name, seq uint64)
+	log         *log.Entry
+
+	syncInterval        time.Duration
+	suppressionInterval time.Duration
+	dataFreshness       time.Duration
+
+	lock            sync.Mutex
+	running         bool
+	nodeId          enc.Name
+	dataPrefix      enc.Name
+	localSv         map[string]*StateVectorEntry
+	aggSv           map[string]uint64
+	state           syncState
+	cancelSyncTimer func() error
+	published       map[uint64]enc.Wire
+	// pending updates are delivered to onUpdate by updateRoutine
+	pending  []syncUpdate
+	notifyCh chan struct{}
+	stopCh   chan struct{}
+}
+
+// NewSVSync creates a sync instance for groupPrefix, which calls onUpdate when a producer is found to
+// publish new Data, with the latest sequence number of that producer.
+// onUpdate is called from one goroutine, in the order of updates; it may call FetchData to fetch the Data.
+// Start must be called to join the group.
+func NewSVSync(app ndn.Engine, groupPrefix enc.Name, onUpdate func(producer enc.Name, seq uint64)) *SVSync {
+	return &SVSync{
+		app:                 app,
+		groupPrefix:         groupPrefix,
+		onUpdate:            onUpdate,
+		log:                 log.WithField("module", "svs").WithField("group", groupPrefix.String()),
+		syncInterval:        DefaultSyncInterval,
+		suppressionInterval: DefaultSuppressionInterval,
+		dataFreshness:       10 * time.Second,
+	}
+}
+
+// SetSyncInterval sets the period of sync Interests in the steady state.
+func (s *SVSync) SetSyncInterval(interval time.Duration) {
+	s.syncInterval = interval
+}
+
+// SetSuppressionInterval sets the max delay of sync Interests in the suppression state.
+func (s *SVSync) SetSuppressionInterval(interval time.Duration) {
+	s.suppressionInterval = interval
+}
+
+// SetDataFreshness sets the FreshnessPeriod of published Data.
+func (s *SVSync) SetDataFreshness(freshness time.Duration) {
+	s.dataFreshness = freshness
+}
+
+// Start joins the sync group as the producer nodeId, starting from sequence number 0.
+// It attaches the Interest handlers of the group prefix and /<nodeId>/<group>.
+func (s *SVSync) Start(nodeId enc.Name) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.running {
+		return errors.New("the sync instance is already started")
+	}
+	if len(nodeId) == 0 {
+		return ndn.ErrInvalidValue{Item: "nodeId", Value: nodeId}
+	}
+	if s.syncInterval <= 0 || s.suppressionInterval <= 0 {
+		return ndn.ErrInvalidValue{Item: "interval", Value: s.syncInterval}
+	}
+
+	s.nodeId = nodeId
+	s.dataPrefix = make(enc.Name, 0, len(nodeId)+len(s.groupPrefix))
+	s.dataPrefix = append(s.dataPrefix, nodeId...)
+	s.dataPrefix = append(s.dataPrefix, s.groupPrefix...)
+	if err := s.app.AttachHandler(s.groupPrefix, s.onSyncInterest); err != nil {
+		return err
+	}
+	if err := s.app.AttachHandler(s.dataPrefix, s.onDataInterest); err != nil {
+		s.app.DetachHandler(s.groupPrefix)
+		return err
+	}
+
+	s.localSv = map[string]*StateVectorEntry{
+		nodeId.TlvStr(): {NodeId: nodeId, SeqNo: 0},
+	}
+	s.aggSv = nil
+	s.state = syncSteady
+	s.published = make(map[uint64]enc.Wire)
+	s.pending = nil
+	s.notifyCh = make(chan struct{}, 1)
+	s.stopCh = make(chan struct{})
+	s.running = true
+	go s.updateRoutine(s.notifyCh, s.stopCh)
+	// The first sync Interest is sent soon to learn the state of the group
+	s.cancelSyncTimer = s.app.Timer().Schedule(min(s.syncIntv(), 100*time.Millisecond), s.onSyncTimer)
+	return nil
+}
+
+// Stop leaves the sync group. The published Data are no longer served.
+func (s *SVSync) Stop() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.running {
+		return ErrSyncNotStarted
+	}
+	s.running = false
+	s.cancelSyncTimer()
+	close(s.stopCh)
+	s.app.DetachHandler(s.groupPrefix)
+	s.app.DetachHandler(s.dataPrefix)
+	return nil
+}
+
+// PublishData publishes content as the next Data of this node, notifies the group,
+// and returns the sequence number, starting from 1.
+// It returns 0 if the Data cannot be produced.
+func (s *SVSync) PublishData(content enc.Wire) uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.running {
+		s.log.Error(ErrSyncNotStarted.Error())
+		return 0
+	}
+
+	self := s.localSv[s.nodeId.TlvStr()]
+	seq := self.SeqNo + 1
+	name := s.DataName(s.nodeId, seq)
+	dataCfg := &ndn.DataConfig{
+		ContentType: utils.IdPtr(ndn.ContentTypeBlob),
+		Freshness:   utils.IdPtr(s.dataFreshness),
+	}
+	wire, _, err := s.app.Spec().MakeData(name, dataCfg, content, s.app.SignerForName(name))
+	if err != nil {
+		s.log.Errorf("Unable to encode Data of seq=%d: %+v", seq, err)
+		return 0
+	}
+	s.published[seq] = wire
+	self.SeqNo = seq
+
+	// A new publication is sent at once, no matter what the state is
+	s.state = syncSteady
+	s.expressStateVector()
+	s.resetSyncTimer(s.syncIntv())
+	return seq
+}
+
+// SeqNo returns the latest known sequence number of producer, or 0 if it is unknown.
+func (s *SVSync) SeqNo(producer enc.Name) uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if entry, ok := s.localSv[producer.TlvStr()]; ok {
+		return entry.SeqNo
+	}
+	return 0
+}
+
+// DataName returns the name of the Data of producer with sequence number seq.
+func (s *SVSync) DataName(producer enc.Name, seq uint64) enc.Name {
+	ret := make(enc.Name, 0, len(producer)+len(s.groupPrefix)+1)
+	ret = append(ret, producer...)
+	ret = append(ret, s.groupPrefix...)
+	return append(ret, enc.NewSequenceNumComponent(seq))
+}
+
+// FetchData fetches the Data of producer with sequence number seq, and calls callback with its content.
+// callback is called on a new goroutine. The signature is not validated.
+func (s *SVSync) FetchData(producer enc.Name, seq uint64, callback func(content enc.Wire, err error)) error {
+	intCfg := &ndn.InterestConfig{
+		Lifetime: utils.IdPtr(4 * time.Second),
+		Nonce:    utils.ConvertNonce(s.app.Timer().Nonce()),
+		Retries:  3,
+	}
+	wire, _, finalName, err := s.app.Spec().MakeInterest(s.DataName(producer, seq), intCfg, nil, nil)
+	if err != nil {
+		return err
+	}
+	return s.app.Express(finalName, intCfg, wire,
+		func(result ndn.InterestResult, data ndn.Data, _ enc.Wire, _ enc.Wire, nackReason uint64) {
+			switch result {
+			case ndn.InterestResultData:
+				go callback(data.Content(), nil)
+			case ndn.InterestResultNack:
+				go callback(nil, fmt.Errorf("seq=%d is nacked for %d", seq, nackReason))
+			case ndn.InterestResultTimeout:
+				go callback(nil, ndn.ErrDeadlineExceed)
+			default:
+				go callback(nil, fmt.Errorf("unknown result: %v", result))
+			}
+		})
+}
+
+func (s *SVSync) onDataInterest(
+	interest ndn.Interest, _ enc.Wire, _ enc.Wire, reply ndn.ReplyFunc, _ time.Time,
+) {
+	name := interest.Name()
+	if len(name) != len(s.dataPrefix)+1 || name[len(name)-1].Typ != enc.TypeSequenceNumNameComponent {
+		return
+	}
+	s.lock.Lock()
+	wire, ok := s.published[name[len(name)-1].NumberVal()]
+	s.lock.Unlock()
+	if !ok {
+		return
+	}
+	if err := reply(wire); err != nil {
+		s.log.WithField("name", name.String()).Errorf("Unable to reply Interest. Drop: %+v", err)
+	}
+}
+
+func (s *SVSync) onSyncInterest(
+	interest ndn.Interest, _ enc.Wire, _ enc.Wire, _ ndn.ReplyFunc, _ time.Time,
+) {
+	if interest.AppParam() == nil {
+		return
+	}
+	param, err := ParseStateVectorAppParam(enc.NewWireReader(interest.AppParam()), true)
+	if err != nil || param.StateVector == nil {
+		s.log.Warnf("Unable to parse the state vector. Drop: %+v", err)
+		return
+	}
+	remoteSv := param.StateVector
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.running {
+		return
+	}
+
+	// Merge the newer entries, and check if the remote misses any of ours
+	var updates []syncUpdate
+	remoteSeqs := make(map[string]uint64, len(remoteSv.Entries))
+	for _, cur := range remoteSv.Entries {
+		key := cur.NodeId.TlvStr()
+		remoteSeqs[key] = cur.SeqNo
+		local, ok := s.localSv[key]
+		if !ok {
+			local = &StateVectorEntry{NodeId: cur.NodeId, SeqNo: 0}
+			s.localSv[key] = local
+		}
+		if local.SeqNo < cur.SeqNo {
+			local.SeqNo = cur.SeqNo
+			updates = append(updates, syncUpdate{producer: cur.NodeId, seq: cur.SeqNo})
+		}
+	}
+	outdated := false
+	for key, local := range s.localSv {
+		if remote, ok := remoteSeqs[key]; (!ok && local.SeqNo > 0) || remote < local.SeqNo {
+			outdated = true
+			break
+		}
+	}
+	if len(updates) > 0 {
+		s.pending = append(s.pending, updates...)
+		select {
+		case s.notifyCh <- struct{}{}:
+		default:
+		}
+	}
+
+	switch {
+	case s.state == syncSuppression:
+		// Only send at the end of suppression the state that nobody else has sent
+		s.aggregate(remoteSv)
+	case outdated:
+		s.state = syncSuppression
+		s.aggSv = make(map[string]uint64, len(remoteSv.Entries))
+		s.aggregate(remoteSv)
+		s.resetSyncTimer(s.suppressionIntv())
+	default:
+		// The group knows at least what we know, so our periodic Interest is delayed
+		s.resetSyncTimer(s.syncIntv())
+	}
+}
+
+func (s *SVSync) aggregate(remoteSv *StateVector) {
+	for _, cur := range remoteSv.Entries {
+		key := cur.NodeId.TlvStr()
+		s.aggSv[key] = max(s.aggSv[key], cur.SeqNo)
+	}
+}
+
+func (s *SVSync) onSyncTimer() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.running {
+		return
+	}
+	necessary := true
+	if s.state == syncSuppression {
+		s.state = syncSteady
+		necessary = false
+		for key, local := range s.localSv {
+			if s.aggSv[key] < local.SeqNo {
+				necessary = true
+				break
+			}
+		}
+		s.aggSv = nil
+	}
+	if necessary {
+		s.expressStateVector()
+	}
+	s.resetSyncTimer(s.syncIntv())
+}
+
+// resetSyncTimer must be called with the lock held.
+func (s *SVSync) resetSyncTimer(d time.Duration) {
+	if s.cancelSyncTimer != nil {
+		s.cancelSyncTimer()
+	}
+	s.cancelSyncTimer = s.app.Timer().Schedule(d, s.onSyncTimer)
+}
+
+// expressStateVector sends a sync Interest. It must be called with the lock held.
+func (s *SVSync) expressStateVector() {
+	sv := &StateVector{Entries: make([]*StateVectorEntry, 0, len(s.localSv))}
+	for _, entry := range s.localSv {
+		sv.Entries = append(sv.Entries, &StateVectorEntry{NodeId: entry.NodeId, SeqNo: entry.SeqNo})
+	}
+	appParam := (&StateVectorAppParam{StateVector: sv}).Encode()
+	intCfg := &ndn.InterestConfig{
+		MustBeFresh: true,
+		Lifetime:    utils.IdPtr(SyncInterestLifetime),
+		Nonce:       utils.ConvertNonce(s.app.Timer().Nonce()),
+	}
+	wire, _, finalName, err := s.app.Spec().MakeInterest(s.groupPrefix, intCfg, appParam, nil)
+	if err != nil {
+		s.log.Errorf("Unable to encode the sync Interest: %+v", err)
+		return
+	}
+	// Sync Interests are not replied, so the results are ignored
+	err = s.app.Express(finalName, intCfg, wire,
+		func(ndn.InterestResult, ndn.Data, enc.Wire, enc.Wire, uint64) {})
+	if err != nil {
+		s.log.Errorf("Unable to express the sync Interest: %+v", err)
+	}
+}
+
+// syncIntv is the sync interval with a jitter of ±10%.
+func (s *SVSync) syncIntv() time.Duration {
+	jitter := rand.Int63n(int64(s.syncInterval)/5+1) - int64(s.syncInterval)/10
+	return s.syncInterval + time.Duration(jitter)
+}
+
+// suppressionIntv is a random delay up to the suppression interval, following an exponential decay
+// so that most nodes wait longer and are likely to be suppressed by the earliest one.
+func (s *SVSync) suppressionIntv() time.Duration {
+	c := float64(s.suppressionInterval)
+	v := rand.Float64() * c
+	return time.Duration(c * (1 - math.Exp((v-c)/(c/10))))
+}
+
+func (s *SVSync) updateRoutine(notifyCh chan struct{}, stopCh chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-notifyCh:
+		}
+		s.lock.Lock()
+		batch := s.pending
+		s.pending = nil
+		s.lock.Unlock()
+		for _, u := range batch {
+			s.onUpdate(u.producer, u.seq)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package sync_test
+
+import (
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+	basic_engine "github.com/zjkmxy/go-ndn/pkg/engine/basic"
+	"github.com/zjkmxy/go-ndn/pkg/engine/dummy"
+	"github.com/zjkmxy/go-ndn/pkg/ndn"
+	sec "github.com/zjkmxy/go-ndn/pkg/security"
+	"github.com/zjkmxy/go-ndn/pkg/sync"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+type svsNode struct {
+	engine *basic_engine.Engine
+	svs    *sync.SVSync
+	lock   gosync.Mutex
+	// received is the fetched content indexed by producer and seq
+	received map[string]map[uint64]string
+}
+
+func newSvsNode(t *testing.T, face basic_engine.Face, group enc.Name, nodeId string) *svsNode {
+	timer := basic_engine.NewTimer()
+	passAll := func(enc.Name, enc.Wire, ndn.Signature) bool { return true }
+	engine := basic_engine.NewEngine(face, timer, sec.NewSha256IntSigner(timer), passAll)
+	require.NoError(t, engine.Start())
+
+	node := &svsNode{
+		engine:   engine,
+		received: make(map[string]map[uint64]string),
+	}
+	fetched := make(map[string]uint64)
+	node.svs = sync.NewSVSync(engine, group, func(producer enc.Name, seq uint64) {
+		for i := fetched[producer.String()] + 1; i <= seq; i++ {
+			require.NoError(t, node.svs.FetchData(producer, i, func(content enc.Wire, err error) {
+				if err != nil {
+					return
+				}
+				node.lock.Lock()
+				defer node.lock.Unlock()
+				if node.received[producer.String()] == nil {
+					node.received[producer.String()] = make(map[uint64]string)
+				}
+				node.received[producer.String()][i] = string(content.Join())
+			}))
+		}
+		fetched[producer.String()] = seq
+	})
+	node.svs.SetSyncInterval(time.Second)
+	node.svs.SetSuppressionInterval(20 * time.Millisecond)
+	require.NoError(t, node.svs.Start(utils.WithoutErr(enc.NameFromStr(nodeId))))
+	return node
+}
+
+func (n *svsNode) content(producer string, seq uint64) string {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.received[producer][seq]
+}
+
+func TestSVSyncConverge(t *testing.T) {
+	utils.SetTestingT(t)
+
+	group := utils.WithoutErr(enc.NameFromStr("/test/svs"))
+	faceA, faceB := dummy.NewPipeFaces()
+	a := newSvsNode(t, faceA, group, "/a")
+	b := newSvsNode(t, faceB, group, "/b")
+
+	a.svs.PublishData(enc.Wire{[]byte("a1")})
+	require.Equal(t, uint64(1), a.svs.SeqNo(utils.WithoutErr(enc.NameFromStr("/a"))))
+	require.Equal(t, uint64(2), a.svs.PublishData(enc.Wire{[]byte("a2")}))
+	require.Equal(t, uint64(1), b.svs.PublishData(enc.Wire{[]byte("b1")}))
+
+	require.Eventually(t, func() bool {
+		return b.content("/a", 1) == "a1" && b.content("/a", 2) == "a2" && a.content("/b", 1) == "b1"
+	}, 3*time.Second, 10*time.Millisecond)
+	nameA := utils.WithoutErr(enc.NameFromStr("/a"))
+	nameB := utils.WithoutErr(enc.NameFromStr("/b"))
+	require.Equal(t, uint64(2), b.svs.SeqNo(nameA))
+	require.Equal(t, uint64(1), a.svs.SeqNo(nameB))
+	require.Equal(t, uint64(0), a.svs.SeqNo(utils.WithoutErr(enc.NameFromStr("/c"))))
+
+	require.NoError(t, a.svs.Stop())
+	require.NoError(t, b.svs.Stop())
+	require.Error(t, a.svs.Stop())
+	require.NoError(t, a.engine.Shutdown())
+	require.NoError(t, b.engine.Shutdown())
+}
+
+func TestSVSyncRecover(t *testing.T) {
+	utils.SetTestingT(t)
+
+	// b joins after a has published, and learns the state from the periodic sync Interests
+	group := utils.WithoutErr(enc.NameFromStr("/test/svs"))
+	faceA, faceB := dummy.NewPipeFaces()
+	a := newSvsNode(t, faceA, group, "/a")
+	for i := 0; i < 3; i++ {
+		a.svs.PublishData(enc.Wire{[]byte("a")})
+	}
+	b := newSvsNode(t, faceB, group, "/b")
+
+	nameA := utils.WithoutErr(enc.NameFromStr("/a"))
+	require.Eventually(t, func() bool {
+		return b.svs.SeqNo(nameA) == 3 && b.content("/a", 3) == "a"
+	}, 3*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, a.svs.Stop())
+	require.NoError(t, b.svs.Stop())
+	require.NoError(t, a.engine.Shutdown())
+	require.NoError(t, b.engine.Shutdown())
+}
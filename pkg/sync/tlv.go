@@ -0,0 +1,33 @@
+//go:generate gondn_tlv_gen
+package sync
+
+import enc "github.com/zjkmxy/go-ndn/pkg/encoding"
+
+type StateVectorEntry struct {
+	//+field:name
+	NodeId enc.Name `tlv:"0x07"`
+	//+field:natural
+	SeqNo uint64 `tlv:"0xcc"`
+}
+
+type StateVector struct {
+	//+field:sequence:*StateVectorEntry:struct:StateVectorEntry
+	Entries []*StateVectorEntry `tlv:"0xca"`
+}
+
+// StateVectorAppParam is the ApplicationParameters of a sync Interest
+type StateVectorAppParam struct {
+	//+field:struct:StateVector
+	StateVector *StateVector `tlv:"0xc9"`
+}
+
+type PSyncState struct {
+	//+field:sequence:enc.Name:name
+	Names []enc.Name `tlv:"0x07"`
+}
+
+// PSyncContent is the content of PSync hello and sync replies
+type PSyncContent struct {
+	//+field:struct:PSyncState
+	State *PSyncState `tlv:"0x80"`
+}
@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/exp/constraints"
@@ -39,6 +42,38 @@ func MakeTimestamp(t time.Time) uint64 {
 	return uint64(t.UnixNano() / int64(time.Millisecond))
 }
 
+// ParseTimestamp is the inverse of MakeTimestamp, i.e. it converts milliseconds since the Unix epoch to a time.
+func ParseTimestamp(nat uint64) time.Time {
+	return time.UnixMilli(int64(nat))
+}
+
+// RandomNonce returns a non-zero random Nonce from crypto/rand.
+// Zero is avoided since it is commonly taken as "no Nonce".
+func RandomNonce() uint32 {
+	var buf [4]byte
+	for {
+		rand.Read(buf[:]) // Should always succeed
+		if nonce := binary.BigEndian.Uint32(buf[:]); nonce != 0 {
+			return nonce
+		}
+	}
+}
+
+var lastSeq atomic.Uint64
+
+// MonotonicSeq returns a sequence number larger than all previous ones in this process.
+// It is at least the current timestamp given by MakeTimestamp, so the numbers keep increasing after a restart,
+// unless more than one number per millisecond is taken on average.
+func MonotonicSeq() uint64 {
+	for {
+		last := lastSeq.Load()
+		next := max(last+1, MakeTimestamp(time.Now()))
+		if lastSeq.CompareAndSwap(last, next) {
+			return next
+		}
+	}
+}
+
 func ConvertNonce(nonce []byte) *uint64 {
 	ret := uint64(0)
 	for _, v := range nonce {
@@ -0,0 +1,58 @@
+package utils_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zjkmxy/go-ndn/pkg/utils"
+)
+
+func TestTimestamp(t *testing.T) {
+	now := time.Now()
+	ts := utils.MakeTimestamp(now)
+	require.Equal(t, now.Truncate(time.Millisecond).UnixNano(), utils.ParseTimestamp(ts).UnixNano())
+	require.Equal(t, ts, utils.MakeTimestamp(utils.ParseTimestamp(ts)))
+
+	require.True(t, utils.ParseTimestamp(0).Equal(time.Unix(0, 0)))
+	require.Equal(t, uint64(1700000000123), utils.MakeTimestamp(utils.ParseTimestamp(1700000000123)))
+}
+
+func TestRandomNonce(t *testing.T) {
+	seen := make(map[uint32]bool)
+	for i := 0; i < 10000; i++ {
+		nonce := utils.RandomNonce()
+		require.NotEqual(t, uint32(0), nonce)
+		seen[nonce] = true
+	}
+	// Collisions are very unlikely among 10000 random 32-bit numbers
+	require.Greater(t, len(seen), 9900)
+}
+
+func TestMonotonicSeq(t *testing.T) {
+	start := utils.MakeTimestamp(time.Now())
+	lock := sync.Mutex{}
+	seen := make(map[uint64]bool)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			last := uint64(0)
+			for j := 0; j < 1000; j++ {
+				seq := utils.MonotonicSeq()
+				if seq <= last {
+					t.Errorf("sequence number %d is not larger than %d", seq, last)
+				}
+				last = seq
+				lock.Lock()
+				seen[seq] = true
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	require.Len(t, seen, 8000)
+	require.GreaterOrEqual(t, utils.MonotonicSeq(), start)
+}
@@ -255,6 +255,8 @@ func (context *StringMapParsingContext) Parse(reader enc.ParseReader, ignoreCrit
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -412,6 +414,8 @@ func (context *InnerParsingContext) Parse(reader enc.ParseReader, ignoreCritical
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -709,6 +713,8 @@ func (context *IntStructMapParsingContext) Parse(reader enc.ParseReader, ignoreC
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
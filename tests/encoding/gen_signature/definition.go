@@ -152,3 +152,47 @@ func ReadT2(reader enc.ParseReader, digestRequired bool) (*T2, enc.Wire, error)
 
 	return ret, context.sigCovered, nil
 }
+
+//+tlv-model:nocopy,private
+type T3 struct {
+	//+field:natural
+	H1 uint64 `tlv:"0x01"`
+	//+field:offsetMarker
+	sigCoverStart enc.PlaceHolder
+	//+field:natural:optional
+	H2 *uint64 `tlv:"0x02"`
+	//+field:wire
+	C enc.Wire `tlv:"0x03"`
+	//+field:signature:sigCoverStart:sigCovered
+	Sig enc.Wire `tlv:"0x04"`
+
+	//+field:procedureArgument:enc.Wire
+	sigCovered enc.PlaceHolder
+	//+field:unknown
+	Unknown []enc.UnknownField
+}
+
+func (v *T3) Encode(estLen uint, value []byte) (enc.Wire, enc.Wire) {
+	encoder := T3Encoder{
+		Sig_estLen: estLen,
+	}
+	encoder.Init(v)
+	wire := encoder.Encode(v)
+	if encoder.Sig_wireIdx >= 0 {
+		wire[encoder.Sig_wireIdx] = value
+		buf := wire[encoder.Sig_wireIdx-1]
+		buf[len(buf)-1] = byte(len(value))
+	}
+
+	return wire, encoder.sigCovered
+}
+
+func ReadT3(reader enc.ParseReader) (*T3, enc.Wire, error) {
+	context := T3ParsingContext{}
+	context.Init()
+	ret, err := context.Parse(reader, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ret, context.sigCovered, nil
+}
@@ -282,3 +282,54 @@ func TestT2(t *testing.T) {
 	_, _, err = def.ReadT2(enc.NewBufferReader(buf), false)
 	require.NoError(t, err)
 }
+
+func TestUnknownField(t *testing.T) {
+	utils.SetTestingT(t)
+
+	buf := []byte{
+		0x20, 0x01, 0xaa,
+		0x01, 0x01, 0x01,
+		0x22, 0x00,
+		0x02, 0x01, 0x02,
+		0xfd, 0x01, 0x00, 0x02, 0xbb, 0xcc,
+		0x03, 0x03, 0x01, 0x02, 0x03,
+		0x04, 0x01, 0x07,
+		0x30, 0x01, 0xdd,
+	}
+	f, cov, err := def.ReadT3(enc.NewBufferReader(buf))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), f.H1)
+	require.Equal(t, uint64(2), *f.H2)
+	require.Equal(t, []byte{0x01, 0x02, 0x03}, f.C.Join())
+	require.Equal(t, []byte{0x07}, f.Sig.Join())
+	require.Equal(t, 4, len(f.Unknown))
+	require.Equal(t, enc.TLNum(0x20), f.Unknown[0].Typ)
+	require.Equal(t, []byte{0xaa}, f.Unknown[0].Val.Join())
+	require.Equal(t, enc.TLNum(0x22), f.Unknown[1].Typ)
+	require.Equal(t, []byte{}, f.Unknown[1].Val.Join())
+	require.Equal(t, enc.TLNum(0x100), f.Unknown[2].Typ)
+	require.Equal(t, []byte{0xbb, 0xcc}, f.Unknown[2].Val.Join())
+	require.Equal(t, enc.TLNum(0x30), f.Unknown[3].Typ)
+	require.Equal(t, []byte{
+		0x02, 0x01, 0x02,
+		0xfd, 0x01, 0x00, 0x02, 0xbb, 0xcc,
+		0x03, 0x03, 0x01, 0x02, 0x03,
+	}, cov.Join())
+
+	// Unknown fields are put back to their original positions
+	wire, cov2 := f.Encode(1, []byte{0x07})
+	require.Equal(t, buf, wire.Join())
+	require.Equal(t, cov.Join(), cov2.Join())
+
+	// Without unknown fields, the result is the same as T1
+	f.Unknown = nil
+	wire, _ = f.Encode(1, []byte{0x07})
+	f1 := &def.T1{H1: 1, H2: utils.IdPtr[uint64](2), C: enc.Wire{[]byte{0x01, 0x02, 0x03}}}
+	wire1, _ := f1.Encode(1, []byte{0x07})
+	require.Equal(t, wire1.Join(), wire.Join())
+
+	// Critical unknown fields are still rejected
+	buf[6] = 0x23
+	_, _, err = def.ReadT3(enc.NewBufferReader(buf))
+	require.Error(t, err)
+}
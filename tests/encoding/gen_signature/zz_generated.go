@@ -425,6 +425,8 @@ func (context *T1ParsingContext) Parse(reader enc.ParseReader, ignoreCritical bo
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -899,6 +901,8 @@ func (context *T2ParsingContext) Parse(reader enc.ParseReader, ignoreCritical bo
 					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
 				}
 				err = reader.Skip(int(l))
+				// An unknown field does not take the place of the next field
+				progress--
 			}
 			if err == nil && !handled {
 				switch progress {
@@ -947,3 +951,606 @@ func (context *T2ParsingContext) Parse(reader enc.ParseReader, ignoreCritical bo
 	}
 	return value, nil
 }
+
+type T3Encoder struct {
+	length uint
+
+	wirePlan []uint
+
+	sigCoverStart         int
+	sigCoverStart_wireIdx int
+	sigCoverStart_pos     int
+
+	C_length    uint
+	Sig_wireIdx int
+	Sig_estLen  uint
+
+	sigCovered enc.Wire
+}
+
+type T3ParsingContext struct {
+	sigCoverStart int
+
+	sigCovered enc.Wire
+}
+
+func (encoder *T3Encoder) Init(value *T3) {
+
+	if value.C != nil {
+		encoder.C_length = 0
+		for _, c := range value.C {
+			encoder.C_length += uint(len(c))
+		}
+	}
+
+	encoder.Sig_wireIdx = -1
+
+	l := uint(0)
+	Unknown_idx := 0
+	for ; Unknown_idx < len(value.Unknown) && value.Unknown[Unknown_idx].Pos < 0; Unknown_idx++ {
+		u := &value.Unknown[Unknown_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
+	l += 1
+	switch x := value.H1; {
+	case x <= 0xff:
+		l += 2
+	case x <= 0xffff:
+		l += 3
+	case x <= 0xffffffff:
+		l += 5
+	default:
+		l += 9
+	}
+
+	for ; Unknown_idx < len(value.Unknown) && value.Unknown[Unknown_idx].Pos < 1; Unknown_idx++ {
+		u := &value.Unknown[Unknown_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
+	encoder.sigCoverStart = int(l)
+	if value.H2 != nil {
+		l += 1
+		switch x := *value.H2; {
+		case x <= 0xff:
+			l += 2
+		case x <= 0xffff:
+			l += 3
+		case x <= 0xffffffff:
+			l += 5
+		default:
+			l += 9
+		}
+	}
+
+	for ; Unknown_idx < len(value.Unknown) && value.Unknown[Unknown_idx].Pos < 3; Unknown_idx++ {
+		u := &value.Unknown[Unknown_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
+	if value.C != nil {
+		l += 1
+		switch x := encoder.C_length; {
+		case x <= 0xfc:
+			l += 1
+		case x <= 0xffff:
+			l += 3
+		case x <= 0xffffffff:
+			l += 5
+		default:
+			l += 9
+		}
+		l += encoder.C_length
+	}
+
+	for ; Unknown_idx < len(value.Unknown) && value.Unknown[Unknown_idx].Pos < 4; Unknown_idx++ {
+		u := &value.Unknown[Unknown_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
+	if encoder.Sig_estLen > 0 {
+		l += 1
+		switch x := encoder.Sig_estLen; {
+		case x <= 0xfc:
+			l += 1
+		case x <= 0xffff:
+			l += 3
+		case x <= 0xffffffff:
+			l += 5
+		default:
+			l += 9
+		}
+		l += encoder.Sig_estLen
+	}
+
+	for ; Unknown_idx < len(value.Unknown) && value.Unknown[Unknown_idx].Pos < 5; Unknown_idx++ {
+		u := &value.Unknown[Unknown_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
+
+	for ; Unknown_idx < len(value.Unknown) && value.Unknown[Unknown_idx].Pos < 7; Unknown_idx++ {
+		u := &value.Unknown[Unknown_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
+
+	encoder.length = l
+
+	wirePlan := make([]uint, 0)
+	l = uint(0)
+	Unknown_idx = 0
+	for ; Unknown_idx < len(value.Unknown) && value.Unknown[Unknown_idx].Pos < 0; Unknown_idx++ {
+		u := &value.Unknown[Unknown_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
+	l += 1
+	switch x := value.H1; {
+	case x <= 0xff:
+		l += 2
+	case x <= 0xffff:
+		l += 3
+	case x <= 0xffffffff:
+		l += 5
+	default:
+		l += 9
+	}
+
+	for ; Unknown_idx < len(value.Unknown) && value.Unknown[Unknown_idx].Pos < 1; Unknown_idx++ {
+		u := &value.Unknown[Unknown_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
+
+	if value.H2 != nil {
+		l += 1
+		switch x := *value.H2; {
+		case x <= 0xff:
+			l += 2
+		case x <= 0xffff:
+			l += 3
+		case x <= 0xffffffff:
+			l += 5
+		default:
+			l += 9
+		}
+	}
+
+	for ; Unknown_idx < len(value.Unknown) && value.Unknown[Unknown_idx].Pos < 3; Unknown_idx++ {
+		u := &value.Unknown[Unknown_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
+	if value.C != nil {
+		l += 1
+		switch x := encoder.C_length; {
+		case x <= 0xfc:
+			l += 1
+		case x <= 0xffff:
+			l += 3
+		case x <= 0xffffffff:
+			l += 5
+		default:
+			l += 9
+		}
+		wirePlan = append(wirePlan, l)
+		l = 0
+		for range value.C {
+			wirePlan = append(wirePlan, l)
+			l = 0
+		}
+	}
+
+	for ; Unknown_idx < len(value.Unknown) && value.Unknown[Unknown_idx].Pos < 4; Unknown_idx++ {
+		u := &value.Unknown[Unknown_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
+	if encoder.Sig_estLen > 0 {
+		l += 1
+		switch x := encoder.Sig_estLen; {
+		case x <= 0xfc:
+			l += 1
+		case x <= 0xffff:
+			l += 3
+		case x <= 0xffffffff:
+			l += 5
+		default:
+			l += 9
+		}
+		wirePlan = append(wirePlan, l)
+		l = 0
+		encoder.Sig_wireIdx = len(wirePlan)
+		wirePlan = append(wirePlan, l)
+		l = 0
+	}
+
+	for ; Unknown_idx < len(value.Unknown) && value.Unknown[Unknown_idx].Pos < 5; Unknown_idx++ {
+		u := &value.Unknown[Unknown_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
+
+	for ; Unknown_idx < len(value.Unknown) && value.Unknown[Unknown_idx].Pos < 7; Unknown_idx++ {
+		u := &value.Unknown[Unknown_idx]
+		l += uint(u.Typ.EncodingLength())
+		l += uint(enc.TLNum(u.Val.Length()).EncodingLength())
+		l += uint(u.Val.Length())
+	}
+
+	if l > 0 {
+		wirePlan = append(wirePlan, l)
+	}
+	encoder.wirePlan = wirePlan
+}
+
+func (context *T3ParsingContext) Init() {
+
+	context.sigCovered = make(enc.Wire, 0)
+
+}
+
+func (encoder *T3Encoder) EncodeInto(value *T3, wire enc.Wire) {
+
+	wireIdx := 0
+	buf := wire[wireIdx]
+
+	pos := uint(0)
+	Unknown_idx := 0
+	for ; Unknown_idx < len(value.Unknown) && value.Unknown[Unknown_idx].Pos < 0; Unknown_idx++ {
+		u := &value.Unknown[Unknown_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
+	buf[pos] = byte(1)
+	pos += 1
+	switch x := value.H1; {
+	case x <= 0xff:
+		buf[pos] = 1
+		buf[pos+1] = byte(x)
+		pos += 2
+	case x <= 0xffff:
+		buf[pos] = 2
+		binary.BigEndian.PutUint16(buf[pos+1:], uint16(x))
+		pos += 3
+	case x <= 0xffffffff:
+		buf[pos] = 4
+		binary.BigEndian.PutUint32(buf[pos+1:], uint32(x))
+		pos += 5
+	default:
+		buf[pos] = 8
+		binary.BigEndian.PutUint64(buf[pos+1:], uint64(x))
+		pos += 9
+	}
+
+	for ; Unknown_idx < len(value.Unknown) && value.Unknown[Unknown_idx].Pos < 1; Unknown_idx++ {
+		u := &value.Unknown[Unknown_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
+	encoder.sigCoverStart_wireIdx = int(wireIdx)
+	encoder.sigCoverStart_pos = int(pos)
+
+	if value.H2 != nil {
+		buf[pos] = byte(2)
+		pos += 1
+		switch x := *value.H2; {
+		case x <= 0xff:
+			buf[pos] = 1
+			buf[pos+1] = byte(x)
+			pos += 2
+		case x <= 0xffff:
+			buf[pos] = 2
+			binary.BigEndian.PutUint16(buf[pos+1:], uint16(x))
+			pos += 3
+		case x <= 0xffffffff:
+			buf[pos] = 4
+			binary.BigEndian.PutUint32(buf[pos+1:], uint32(x))
+			pos += 5
+		default:
+			buf[pos] = 8
+			binary.BigEndian.PutUint64(buf[pos+1:], uint64(x))
+			pos += 9
+		}
+	}
+
+	for ; Unknown_idx < len(value.Unknown) && value.Unknown[Unknown_idx].Pos < 3; Unknown_idx++ {
+		u := &value.Unknown[Unknown_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
+	if value.C != nil {
+		buf[pos] = byte(3)
+		pos += 1
+		switch x := encoder.C_length; {
+		case x <= 0xfc:
+			buf[pos] = byte(x)
+			pos += 1
+		case x <= 0xffff:
+			buf[pos] = 0xfd
+			binary.BigEndian.PutUint16(buf[pos+1:], uint16(x))
+			pos += 3
+		case x <= 0xffffffff:
+			buf[pos] = 0xfe
+			binary.BigEndian.PutUint32(buf[pos+1:], uint32(x))
+			pos += 5
+		default:
+			buf[pos] = 0xff
+			binary.BigEndian.PutUint64(buf[pos+1:], uint64(x))
+			pos += 9
+		}
+		wireIdx++
+		pos = 0
+		if wireIdx < len(wire) {
+			buf = wire[wireIdx]
+		} else {
+			buf = nil
+		}
+		for _, w := range value.C {
+			wire[wireIdx] = w
+			wireIdx++
+			pos = 0
+			if wireIdx < len(wire) {
+				buf = wire[wireIdx]
+			} else {
+				buf = nil
+			}
+		}
+	}
+
+	for ; Unknown_idx < len(value.Unknown) && value.Unknown[Unknown_idx].Pos < 4; Unknown_idx++ {
+		u := &value.Unknown[Unknown_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
+	if encoder.Sig_estLen > 0 {
+		startPos := int(pos)
+		buf[pos] = byte(4)
+		pos += 1
+		switch x := encoder.Sig_estLen; {
+		case x <= 0xfc:
+			buf[pos] = byte(x)
+			pos += 1
+		case x <= 0xffff:
+			buf[pos] = 0xfd
+			binary.BigEndian.PutUint16(buf[pos+1:], uint16(x))
+			pos += 3
+		case x <= 0xffffffff:
+			buf[pos] = 0xfe
+			binary.BigEndian.PutUint32(buf[pos+1:], uint32(x))
+			pos += 5
+		default:
+			buf[pos] = 0xff
+			binary.BigEndian.PutUint64(buf[pos+1:], uint64(x))
+			pos += 9
+		}
+		if encoder.sigCoverStart_wireIdx == int(wireIdx) {
+			coveredPart := buf[encoder.sigCoverStart:startPos]
+			encoder.sigCovered = append(encoder.sigCovered, coveredPart)
+		} else {
+			coverStart := wire[encoder.sigCoverStart_wireIdx][encoder.sigCoverStart:]
+			encoder.sigCovered = append(encoder.sigCovered, coverStart)
+			for i := encoder.sigCoverStart_wireIdx + 1; i < int(wireIdx); i++ {
+				encoder.sigCovered = append(encoder.sigCovered, wire[i])
+			}
+			coverEnd := buf[:startPos]
+			encoder.sigCovered = append(encoder.sigCovered, coverEnd)
+		}
+		wireIdx++
+		pos = 0
+		if wireIdx < len(wire) {
+			buf = wire[wireIdx]
+		} else {
+			buf = nil
+		}
+		wireIdx++
+		pos = 0
+		if wireIdx < len(wire) {
+			buf = wire[wireIdx]
+		} else {
+			buf = nil
+		}
+	}
+
+	for ; Unknown_idx < len(value.Unknown) && value.Unknown[Unknown_idx].Pos < 5; Unknown_idx++ {
+		u := &value.Unknown[Unknown_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
+
+	for ; Unknown_idx < len(value.Unknown) && value.Unknown[Unknown_idx].Pos < 7; Unknown_idx++ {
+		u := &value.Unknown[Unknown_idx]
+		pos += uint(u.Typ.EncodeInto(buf[pos:]))
+		pos += uint(enc.TLNum(u.Val.Length()).EncodeInto(buf[pos:]))
+		for _, b := range u.Val {
+			pos += uint(copy(buf[pos:], b))
+		}
+	}
+
+}
+
+func (encoder *T3Encoder) Encode(value *T3) enc.Wire {
+
+	wire := make(enc.Wire, len(encoder.wirePlan))
+	for i, l := range encoder.wirePlan {
+		if l > 0 {
+			wire[i] = make([]byte, l)
+		}
+	}
+	encoder.EncodeInto(value, wire)
+
+	return wire
+}
+
+func (context *T3ParsingContext) Parse(reader enc.ParseReader, ignoreCritical bool) (*T3, error) {
+	if reader == nil {
+		return nil, enc.ErrBufferOverflow
+	}
+	progress := -1
+	value := &T3{}
+	var err error
+	var startPos int
+	for {
+		startPos = reader.Pos()
+		if startPos >= reader.Length() {
+			break
+		}
+		typ := enc.TLNum(0)
+		l := enc.TLNum(0)
+		typ, err = enc.ReadTLNum(reader)
+		if err != nil {
+			return nil, enc.ErrFailToParse{TypeNum: 0, Err: err}
+		}
+		l, err = enc.ReadTLNum(reader)
+		if err != nil {
+			return nil, enc.ErrFailToParse{TypeNum: 0, Err: err}
+		}
+		err = nil
+		for handled := false; !handled; progress++ {
+			switch typ {
+			case 1:
+				if progress+1 == 0 {
+					handled = true
+					value.H1 = uint64(0)
+					{
+						for i := 0; i < int(l); i++ {
+							x := byte(0)
+							x, err = reader.ReadByte()
+							if err != nil {
+								if err == io.EOF {
+									err = io.ErrUnexpectedEOF
+								}
+								break
+							}
+							value.H1 = uint64(value.H1<<8) | uint64(x)
+						}
+					}
+				}
+			case 2:
+				if progress+1 == 2 {
+					handled = true
+					{
+						tempVal := uint64(0)
+						tempVal = uint64(0)
+						{
+							for i := 0; i < int(l); i++ {
+								x := byte(0)
+								x, err = reader.ReadByte()
+								if err != nil {
+									if err == io.EOF {
+										err = io.ErrUnexpectedEOF
+									}
+									break
+								}
+								tempVal = uint64(tempVal<<8) | uint64(x)
+							}
+						}
+						value.H2 = &tempVal
+					}
+
+				}
+			case 3:
+				if progress+1 == 3 {
+					handled = true
+					value.C, err = reader.ReadWire(int(l))
+
+				}
+			case 4:
+				if progress+1 == 4 {
+					handled = true
+					value.Sig, err = reader.ReadWire(int(l))
+					if err == nil {
+						coveredPart := reader.Range(context.sigCoverStart, startPos)
+						context.sigCovered = append(context.sigCovered, coveredPart...)
+					}
+
+				}
+			default:
+				handled = true
+				if !ignoreCritical && ((typ <= 31) || ((typ & 1) == 1)) {
+					return nil, enc.ErrUnrecognizedField{TypeNum: typ}
+				}
+				{
+					u := enc.UnknownField{Typ: typ, Pos: progress}
+					u.Val, err = reader.ReadWire(int(l))
+					value.Unknown = append(value.Unknown, u)
+				}
+
+				// An unknown field does not take the place of the next field
+				progress--
+			}
+			if err == nil && !handled {
+				switch progress {
+				case 0 - 1:
+					err = enc.ErrSkipRequired{Name: "H1", TypeNum: 1}
+				case 1 - 1:
+					context.sigCoverStart = int(startPos)
+				case 2 - 1:
+					value.H2 = nil
+				case 3 - 1:
+					value.C = nil
+				case 4 - 1:
+					value.Sig = nil
+				case 5 - 1:
+
+				case 6 - 1:
+
+				}
+			}
+			if err != nil {
+				return nil, enc.ErrFailToParse{TypeNum: typ, Err: err}
+			}
+		}
+	}
+	startPos = reader.Pos()
+	for ; progress < 7; progress++ {
+		switch progress {
+		case 0 - 1:
+			err = enc.ErrSkipRequired{Name: "H1", TypeNum: 1}
+		case 1 - 1:
+			context.sigCoverStart = int(startPos)
+		case 2 - 1:
+			value.H2 = nil
+		case 3 - 1:
+			value.C = nil
+		case 4 - 1:
+			value.Sig = nil
+		case 5 - 1:
+
+		case 6 - 1:
+
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}